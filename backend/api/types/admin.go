@@ -0,0 +1,181 @@
+package types
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type UserCounts struct {
+	Total  int            `json:"total"`
+	ByType map[string]int `json:"by_type"`
+}
+
+type DailyTaskCount struct {
+	Day       time.Time `json:"day"`
+	Created   int       `json:"created"`
+	Completed int       `json:"completed"`
+}
+
+type TeamActivity struct {
+	TeamID       uuid.UUID `json:"team_id"`
+	Name         string    `json:"name"`
+	TasksCreated int       `json:"tasks_created"`
+	TasksUpdated int       `json:"tasks_updated"`
+}
+
+// JobBacklog counts work waiting for background jobs to pick it up
+type JobBacklog struct {
+	RemindersPending     int `json:"reminders_pending"`
+	ExpiredRefreshTokens int `json:"expired_refresh_tokens"`
+}
+
+type BreakerState string
+
+type BreakerStats struct {
+	State       BreakerState `json:"state"`
+	Failures    int          `json:"consecutive_failures"`
+	Trips       int64        `json:"trips"`
+	OpenedAt    *time.Time   `json:"opened_at,omitempty"`
+	LastFailure string       `json:"last_failure,omitempty"`
+}
+
+// PoolStats is the pool's own counters along with what the watch saw.
+// Durations are in milliseconds.
+type PoolStats struct {
+	MaxConns          int32 `json:"max_conns"`
+	TotalConns        int32 `json:"total_conns"`
+	AcquiredConns     int32 `json:"acquired_conns"`
+	IdleConns         int32 `json:"idle_conns"`
+	ConstructingConns int32 `json:"constructing_conns"`
+	AcquireCount      int64 `json:"acquire_count"`
+	// WaitedCount is how many acquires found no idle connection
+	WaitedCount   int64 `json:"waited_count"`
+	CanceledCount int64 `json:"canceled_count"`
+	// WaitMS is the total time spent waiting for a connection
+	WaitMS        int64      `json:"wait_ms"`
+	AvgAcquireMS  float64    `json:"avg_acquire_ms"`
+	Waiting       int        `json:"waiting"`
+	SlowCount     int64      `json:"slow_count"`
+	SlowThreshold int64      `json:"slow_threshold_ms"`
+	MaxWaitMS     int64      `json:"max_wait_ms"`
+	LastSlowAt    *time.Time `json:"last_slow_at,omitempty"`
+}
+
+// RequestOutcomes counts failed requests by cause since the process
+// started, so client disconnects do not inflate the server error rate.
+type RequestOutcomes struct {
+	ClientCanceled int64 `json:"client_canceled"`
+	TimedOut       int64 `json:"timed_out"`
+	ServerErrors   int64 `json:"server_errors"`
+}
+
+type MetricsSummary struct {
+	Users          *UserCounts     `json:"users"`
+	ActiveSessions int             `json:"active_sessions"`
+	JobBacklog     *JobBacklog     `json:"job_backlog"`
+	DBBreaker      BreakerStats    `json:"db_breaker"`
+	DBPool         PoolStats       `json:"db_pool"`
+	Requests       RequestOutcomes `json:"requests"`
+	GeneratedAt    time.Time       `json:"generated_at"`
+}
+
+type TasksPerDayResponse struct {
+	Days   int              `json:"days"`
+	Counts []DailyTaskCount `json:"counts"`
+}
+
+type TopTeamsResponse struct {
+	Days  int            `json:"days"`
+	Teams []TeamActivity `json:"teams"`
+}
+
+// Readiness is the body of /readyz, sent with 503 when Ready is false.
+type Readiness struct {
+	Ready     bool         `json:"ready"`
+	DBBreaker BreakerStats `json:"db_breaker"`
+}
+
+// AdminTaskListResponse is one page of every team's tasks. NextCursor is
+// empty on the last page.
+type AdminTaskListResponse struct {
+	Tasks      []Task `json:"tasks"`
+	NextCursor string `json:"next_cursor"`
+}
+
+type AdminTrashListResponse struct {
+	Tasks      []TrashedTask `json:"tasks"`
+	NextCursor string        `json:"next_cursor"`
+}
+
+type IPAllowlistEntry struct {
+	ID        uuid.UUID  `json:"id"`
+	CIDR      string     `json:"cidr"`
+	Note      string     `json:"note"`
+	CreatedBy *uuid.UUID `json:"created_by,omitempty"`
+	CreatedAt time.Time  `json:"created_at"`
+}
+
+type IPAllowlistResponse struct {
+	Entries []IPAllowlistEntry `json:"entries"`
+}
+
+// UserUsage is one user's API use for the admin console. LastSeenAt is the
+// last request ever recorded, nil if there is none.
+type UserUsage struct {
+	UserID     uuid.UUID  `json:"user_id"`
+	Email      string     `json:"email"`
+	UserType   string     `json:"user_type"`
+	Requests   int64      `json:"requests"`
+	LastSeenAt *time.Time `json:"last_seen_at"`
+	CreatedAt  time.Time  `json:"created_at"`
+}
+
+// UsageResponse is one page of UserUsage over the last Days days.
+// NextOffset is nil on the last page.
+type UsageResponse struct {
+	Days       int         `json:"days"`
+	Users      []UserUsage `json:"users"`
+	NextOffset *int        `json:"next_offset"`
+}
+
+// LegalHold freezes a whole team (TaskID nil) or a single task. While it
+// exists the database refuses to delete the held rows.
+type LegalHold struct {
+	ID       uuid.UUID  `json:"id"`
+	TeamID   uuid.UUID  `json:"team_id"`
+	TaskID   *uuid.UUID `json:"task_id,omitempty"`
+	Reason   string     `json:"reason"`
+	PlacedBy *uuid.UUID `json:"placed_by,omitempty"`
+	PlacedAt time.Time  `json:"placed_at"`
+}
+
+type LegalHoldListResponse struct {
+	Holds []LegalHold `json:"holds"`
+}
+
+type BackupStatus string
+
+// BackupExport tracks one requested backup. TeamID is nil for a full
+// export.
+type BackupExport struct {
+	ID          uuid.UUID    `json:"id"`
+	TeamID      *uuid.UUID   `json:"team_id"`
+	Status      BackupStatus `json:"status"`
+	ObjectKey   *string      `json:"object_key,omitempty"`
+	SizeBytes   *int64       `json:"size_bytes,omitempty"`
+	RowCount    *int64       `json:"row_count,omitempty"`
+	Error       *string      `json:"error,omitempty"`
+	RequestedBy *uuid.UUID   `json:"requested_by,omitempty"`
+	CreatedAt   time.Time    `json:"created_at"`
+	StartedAt   *time.Time   `json:"started_at,omitempty"`
+	FinishedAt  *time.Time   `json:"finished_at,omitempty"`
+}
+
+// BackupListResponse says whether backups are configured at all, so the
+// console can explain an empty list.
+type BackupListResponse struct {
+	Enabled    bool           `json:"enabled"`
+	Exports    []BackupExport `json:"exports"`
+	NextOffset *int           `json:"next_offset"`
+}
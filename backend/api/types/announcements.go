@@ -0,0 +1,60 @@
+package types
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// CreateAnnouncementRequest is the task every team gets. Each copy is
+// reported by the admin and assigned to the team's owner.
+type CreateAnnouncementRequest struct {
+	Title       string        `json:"title"`
+	Description *string       `json:"description"`
+	DueAt       time.Time     `json:"due_at"`
+	Priority    *TaskPriority `json:"priority"`
+}
+
+// AnnouncementProgress counts the teams' copies by where they stand.
+// Deleted is the teams whose copy no longer exists.
+type AnnouncementProgress struct {
+	Open     int `json:"open"`
+	Overdue  int `json:"overdue"`
+	Done     int `json:"done"`
+	Canceled int `json:"canceled"`
+	Deleted  int `json:"deleted"`
+}
+
+type Announcement struct {
+	ID          uuid.UUID    `json:"id"`
+	Title       string       `json:"title"`
+	Description *string      `json:"description,omitempty"`
+	DueAt       time.Time    `json:"due_at"`
+	Priority    TaskPriority `json:"priority"`
+	CreatedBy   *uuid.UUID   `json:"created_by"`
+	CreatedAt   time.Time    `json:"created_at"`
+	// Teams is how many teams got a task
+	Teams    int                  `json:"teams"`
+	Progress AnnouncementProgress `json:"progress"`
+}
+
+// AnnouncementTeam is one team's copy. Archived copies are still counted.
+type AnnouncementTeam struct {
+	TeamID     uuid.UUID  `json:"team_id"`
+	TeamName   string     `json:"team_name"`
+	TaskID     uuid.UUID  `json:"task_id"`
+	AssigneeID uuid.UUID  `json:"assignee_id"`
+	Status     TaskStatus `json:"status"`
+	Overdue    bool       `json:"overdue"`
+	UpdatedAt  time.Time  `json:"updated_at"`
+}
+
+type AnnouncementResponse struct {
+	Announcement *Announcement      `json:"announcement"`
+	Teams        []AnnouncementTeam `json:"teams"`
+}
+
+type AnnouncementListResponse struct {
+	Announcements []Announcement `json:"announcements"`
+	NextOffset    *int           `json:"next_offset"`
+}
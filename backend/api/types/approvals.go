@@ -0,0 +1,32 @@
+package types
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type ApprovalStatus string
+
+type Approval struct {
+	ID          uuid.UUID      `json:"id"`
+	TaskID      uuid.UUID      `json:"task_id"`
+	RequestedBy uuid.UUID      `json:"requested_by"`
+	Status      ApprovalStatus `json:"status"`
+	DecidedBy   *uuid.UUID     `json:"decided_by,omitempty"`
+	DecidedAt   *time.Time     `json:"decided_at,omitempty"`
+	Reason      *string        `json:"reason,omitempty"`
+	CreatedAt   time.Time      `json:"created_at"`
+}
+
+// TaskApprovalResponse answers a status change that needs approval (202,
+// the task unchanged) and an approval decision.
+type TaskApprovalResponse struct {
+	Task     *Task     `json:"task"`
+	Approval *Approval `json:"approval"`
+}
+
+type ApprovalListResponse struct {
+	TaskID    uuid.UUID  `json:"task_id"`
+	Approvals []Approval `json:"approvals"`
+}
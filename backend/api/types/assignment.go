@@ -0,0 +1,28 @@
+package types
+
+import "github.com/google/uuid"
+
+// AssignmentRoute sends tasks carrying Label to AssigneeID when the team
+// auto-assigns by label_routing.
+type AssignmentRoute struct {
+	LabelID    uuid.UUID `json:"label_id"`
+	Label      string    `json:"label"`
+	AssigneeID uuid.UUID `json:"assignee_id"`
+}
+
+// PutAssignmentRoutesRequest is the body of PUT
+// /teams/{team_id}/assignment-routes. Routes are tried in order; labels
+// are created if missing.
+type PutAssignmentRoutesRequest struct {
+	Routes []struct {
+		Label      string    `json:"label"`
+		AssigneeID uuid.UUID `json:"assignee_id"`
+	} `json:"routes"`
+}
+
+// AssignmentRouteListResponse is the body of GET and PUT
+// /teams/{team_id}/assignment-routes.
+type AssignmentRouteListResponse struct {
+	TeamID uuid.UUID         `json:"team_id"`
+	Routes []AssignmentRoute `json:"routes"`
+}
@@ -0,0 +1,35 @@
+package types
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Attachment is a file on a task. ContentType is what the server detected
+// from the bytes, not what the uploader claimed.
+type Attachment struct {
+	ID          uuid.UUID  `json:"id"`
+	TaskID      uuid.UUID  `json:"task_id"`
+	TeamID      uuid.UUID  `json:"team_id"`
+	UploaderID  *uuid.UUID `json:"uploader_id"`
+	Filename    string     `json:"filename"`
+	ContentType string     `json:"content_type"`
+	SizeBytes   int64      `json:"size_bytes"`
+	SHA256      string     `json:"sha256"`
+	CreatedAt   time.Time  `json:"created_at"`
+	// StorageKey is where the bytes are kept; never sent to clients
+	StorageKey string `json:"-"`
+}
+
+type AttachmentListResponse struct {
+	TaskID      uuid.UUID    `json:"task_id"`
+	Attachments []Attachment `json:"attachments"`
+}
+
+// AttachmentLink is a signed download URL that needs no Authorization
+// header, for links and <img> tags.
+type AttachmentLink struct {
+	URL       string    `json:"url"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
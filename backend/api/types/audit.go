@@ -0,0 +1,27 @@
+package types
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type AuditAction string
+
+type AuditTargetType string
+
+type AuditEntry struct {
+	ID         uuid.UUID       `json:"id"`
+	ActorID    *uuid.UUID      `json:"actor_id,omitempty"`
+	Action     AuditAction     `json:"action"`
+	TargetType AuditTargetType `json:"target_type"`
+	TargetID   *uuid.UUID      `json:"target_id,omitempty"`
+	TeamID     *uuid.UUID      `json:"team_id,omitempty"`
+	Metadata   map[string]any  `json:"metadata,omitempty"`
+	IP         string          `json:"ip,omitempty"`
+	CreatedAt  time.Time       `json:"created_at"`
+}
+
+type AuditLogResponse struct {
+	Entries []AuditEntry `json:"entries"`
+}
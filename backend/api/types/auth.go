@@ -0,0 +1,89 @@
+package types
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type UserType string
+
+const (
+	UserTypeEmployee    UserType = "employee"
+	UserTypeAdmin       UserType = "admin"
+	UserTypeTaskManager UserType = "task_manager"
+)
+
+// RegisterRequest is the body of POST /auth/register.
+type RegisterRequest struct {
+	Email    string `json:"email"`
+	Password string `json:"password"`
+}
+
+type RegisterResponse struct {
+	UserID    uuid.UUID `json:"user_id"`
+	Email     string    `json:"email"`
+	UserType  UserType  `json:"user_type"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// LoginRequest is the body of POST /auth/login.
+type LoginRequest struct {
+	Email    string `json:"email"`
+	Password string `json:"password"`
+}
+
+// TokenResponse is returned by login and refresh. The refresh token is set
+// as an HttpOnly cookie, never in the body.
+type TokenResponse struct {
+	AccessToken string      `json:"access_token"`
+	TokenType   string      `json:"token_type"`
+	ExpiresIn   int         `json:"expires_in"`
+	User        SessionUser `json:"user"`
+}
+
+type SessionUser struct {
+	ID    uuid.UUID `json:"id"`
+	Email string    `json:"email"`
+	Type  UserType  `json:"type"`
+}
+
+// UpdateUserTypeRequest is the body of PATCH /auth/{user_id}/update-usertype.
+type UpdateUserTypeRequest struct {
+	UserType UserType `json:"user_type"`
+}
+
+type UpdateUserTypeResponse struct {
+	Message string `json:"message"`
+	User    User   `json:"user"`
+}
+
+type User struct {
+	ID       uuid.UUID `json:"id"`
+	Email    string    `json:"email"`
+	UserType UserType  `json:"user_type"`
+	// Timezone is only set on the caller's own profile, GET /auth/me
+	Timezone  string    `json:"timezone,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// UpdateProfileRequest is the body of PATCH /auth/me; nil fields are left
+// unchanged.
+type UpdateProfileRequest struct {
+	// Timezone is an IANA zone name such as "Europe/Berlin"
+	Timezone *string `json:"timezone"`
+}
+
+// UserSummary is one entry of GET /users.
+type UserSummary struct {
+	ID       uuid.UUID `json:"id"`
+	Email    string    `json:"email"`
+	UserType UserType  `json:"user_type"`
+}
+
+type UserListResponse struct {
+	Users []UserSummary `json:"users"`
+	// NextOffset is nil on the last page
+	NextOffset *int `json:"next_offset"`
+}
@@ -0,0 +1,59 @@
+package types
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// DueDatePolicy is what happens to a due date on a non-working day: off,
+// warn (keep it, add a notice) or shift (move it to the next working day).
+type DueDatePolicy string
+
+const (
+	DueDatePolicyOff   DueDatePolicy = "off"
+	DueDatePolicyWarn  DueDatePolicy = "warn"
+	DueDatePolicyShift DueDatePolicy = "shift"
+)
+
+// TeamCalendar is the body of GET /teams/{team_id}/calendar. WeekendDays
+// are time.Weekday numbers, 0 for Sunday. UpdatedAt is nil until the team
+// changes the defaults.
+type TeamCalendar struct {
+	TeamID        uuid.UUID     `json:"team_id"`
+	Timezone      string        `json:"timezone"`
+	WeekendDays   []int         `json:"weekend_days"`
+	DueDatePolicy DueDatePolicy `json:"due_date_policy"`
+	Holidays      []Holiday     `json:"holidays"`
+	UpdatedAt     *time.Time    `json:"updated_at"`
+}
+
+// Holiday.Day is a date like 2026-12-25 in the team's timezone.
+type Holiday struct {
+	Day  string `json:"day"`
+	Name string `json:"name"`
+}
+
+// PutCalendarRequest is the body of PUT /teams/{team_id}/calendar.
+type PutCalendarRequest struct {
+	Timezone      string        `json:"timezone"`
+	WeekendDays   []int         `json:"weekend_days"`
+	DueDatePolicy DueDatePolicy `json:"due_date_policy"`
+}
+
+// DueDateCheckResponse is the body of GET
+// /teams/{team_id}/calendar/check?due_at=...
+type DueDateCheckResponse struct {
+	DueAt          time.Time `json:"due_at"`
+	WorkingDay     bool      `json:"working_day"`
+	Reason         string    `json:"reason,omitempty"`
+	NextWorkingDay time.Time `json:"next_working_day"`
+}
+
+// CalendarFeed is the caller's iCalendar subscription to their assigned
+// tasks. URL carries the feed token; anyone with it can read the feed
+// until it is replaced or turned off.
+type CalendarFeed struct {
+	URL       string    `json:"url"`
+	CreatedAt time.Time `json:"created_at"`
+}
@@ -0,0 +1,51 @@
+package types
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Comment is a note on a task. AuthorID is nil and RecipeID set when an
+// automation recipe posted it. Edited is true once the author has changed
+// the body; earlier bodies are in the comment's history.
+type Comment struct {
+	ID        uuid.UUID  `json:"id"`
+	TaskID    uuid.UUID  `json:"task_id"`
+	TeamID    uuid.UUID  `json:"team_id"`
+	AuthorID  *uuid.UUID `json:"author_id"`
+	RecipeID  *uuid.UUID `json:"recipe_id,omitempty"`
+	Body      string     `json:"body"`
+	Edited    bool       `json:"edited"`
+	EditedAt  *time.Time `json:"edited_at,omitempty"`
+	CreatedAt time.Time  `json:"created_at"`
+}
+
+type CreateCommentRequest struct {
+	Body string `json:"body"`
+}
+
+type EditCommentRequest struct {
+	Body string `json:"body"`
+}
+
+type CommentListResponse struct {
+	TaskID   uuid.UUID `json:"task_id"`
+	Comments []Comment `json:"comments"`
+}
+
+// CommentRevision is a body a comment had before an edit.
+type CommentRevision struct {
+	ID         uuid.UUID  `json:"id"`
+	Body       string     `json:"body"`
+	WrittenAt  time.Time  `json:"written_at"`
+	ReplacedAt time.Time  `json:"replaced_at"`
+	ReplacedBy *uuid.UUID `json:"replaced_by"`
+}
+
+// CommentHistoryResponse holds the current comment and its earlier bodies,
+// oldest first.
+type CommentHistoryResponse struct {
+	Comment   Comment           `json:"comment"`
+	Revisions []CommentRevision `json:"revisions"`
+}
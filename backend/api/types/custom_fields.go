@@ -0,0 +1,58 @@
+package types
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type CustomFieldType string
+
+const (
+	CustomFieldText   CustomFieldType = "text"
+	CustomFieldNumber CustomFieldType = "number"
+	CustomFieldDate   CustomFieldType = "date"
+	CustomFieldSelect CustomFieldType = "select"
+)
+
+// CustomField is a typed task attribute defined by a team. Task values are
+// kept under the field's Key in Task.CustomFields: text and select values
+// are strings, numbers are JSON numbers and dates are YYYY-MM-DD strings.
+type CustomField struct {
+	ID      uuid.UUID       `json:"id"`
+	TeamID  uuid.UUID       `json:"team_id"`
+	Key     string          `json:"key"`
+	Label   string          `json:"label"`
+	Type    CustomFieldType `json:"type"`
+	Options []string        `json:"options,omitempty"`
+	// Required fields must be given when a task is created through the
+	// API and cannot be cleared afterwards
+	Required  bool       `json:"required"`
+	CreatedBy *uuid.UUID `json:"created_by,omitempty"`
+	CreatedAt time.Time  `json:"created_at"`
+	UpdatedAt time.Time  `json:"updated_at"`
+}
+
+// CreateCustomFieldRequest is the body of POST /teams/{team_id}/custom-fields.
+// Options are required for select fields and refused for the others.
+type CreateCustomFieldRequest struct {
+	Key      string          `json:"key"`
+	Label    string          `json:"label"`
+	Type     CustomFieldType `json:"type"`
+	Options  []string        `json:"options"`
+	Required bool            `json:"required"`
+}
+
+// UpdateCustomFieldRequest is the body of PATCH
+// /teams/{team_id}/custom-fields/{field_id}; nil fields are left unchanged.
+// The key and type cannot change, as tasks already hold values for them.
+type UpdateCustomFieldRequest struct {
+	Label    *string  `json:"label"`
+	Options  []string `json:"options"`
+	Required *bool    `json:"required"`
+}
+
+type CustomFieldListResponse struct {
+	TeamID uuid.UUID     `json:"team_id"`
+	Fields []CustomField `json:"fields"`
+}
@@ -0,0 +1,38 @@
+package types
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Delegation hands the delegator's powers over the tasks they created in a
+// team (approve, reject, assign) to the delegate from StartsAt until
+// EndsAt. The delegator keeps them too.
+type Delegation struct {
+	ID          uuid.UUID `json:"id"`
+	TeamID      uuid.UUID `json:"team_id"`
+	DelegatorID uuid.UUID `json:"delegator_id"`
+	DelegateID  uuid.UUID `json:"delegate_id"`
+	StartsAt    time.Time `json:"starts_at"`
+	EndsAt      time.Time `json:"ends_at"`
+	Reason      *string   `json:"reason,omitempty"`
+	CreatedAt   time.Time `json:"created_at"`
+	// Active is true between StartsAt and EndsAt
+	Active bool `json:"active"`
+}
+
+// CreateDelegationRequest is the body of POST /teams/{team_id}/delegations.
+// StartsAt defaults to now. It replaces the caller's delegation in the
+// team, if any.
+type CreateDelegationRequest struct {
+	DelegateID uuid.UUID  `json:"delegate_id"`
+	StartsAt   *time.Time `json:"starts_at"`
+	EndsAt     time.Time  `json:"ends_at"`
+	Reason     *string    `json:"reason"`
+}
+
+type DelegationListResponse struct {
+	TeamID      uuid.UUID    `json:"team_id"`
+	Delegations []Delegation `json:"delegations"`
+}
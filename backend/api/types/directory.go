@@ -0,0 +1,41 @@
+package types
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// DirectoryLink ties a team's membership to a group in an external
+// directory. The Last* fields describe the most recent sync.
+type DirectoryLink struct {
+	TeamID        uuid.UUID  `json:"team_id"`
+	Provider      string     `json:"provider"`
+	GroupID       string     `json:"group_id"`
+	Role          TeamRole   `json:"role"`
+	CreatedBy     *uuid.UUID `json:"created_by"`
+	CreatedAt     time.Time  `json:"created_at"`
+	UpdatedAt     time.Time  `json:"updated_at"`
+	LastSyncedAt  *time.Time `json:"last_synced_at"`
+	LastError     *string    `json:"last_error"`
+	LastAdded     int        `json:"last_added"`
+	LastRemoved   int        `json:"last_removed"`
+	LastUnmatched int        `json:"last_unmatched"`
+}
+
+// PutDirectoryLinkRequest is the body of PUT /teams/{team_id}/directory-sync.
+// Role is given to members the sync adds and defaults to member.
+type PutDirectoryLinkRequest struct {
+	Provider string   `json:"provider"`
+	GroupID  string   `json:"group_id"`
+	Role     TeamRole `json:"role,omitempty"`
+}
+
+// DirectorySyncResult counts what one sync changed. Unmatched are group
+// members without an account here; they are added once they register and
+// the next sync runs.
+type DirectorySyncResult struct {
+	Added     int `json:"added"`
+	Removed   int `json:"removed"`
+	Unmatched int `json:"unmatched"`
+}
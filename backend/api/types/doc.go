@@ -0,0 +1,7 @@
+// Package types holds the request and response bodies of the HTTP API. It
+// is shared by the handlers and by API clients, so it must not import
+// anything under internal/.
+//
+// Every successful response wraps its body in Envelope; failures use
+// ErrorResponse.
+package types
@@ -0,0 +1,38 @@
+package types
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// EditLock says who is editing a task's description. It is advisory:
+// others are warned, not stopped, and it lapses at ExpiresAt unless the
+// holder renews it.
+type EditLock struct {
+	TaskID     uuid.UUID `json:"task_id"`
+	UserID     uuid.UUID `json:"user_id"`
+	AcquiredAt time.Time `json:"acquired_at"`
+	ExpiresAt  time.Time `json:"expires_at"`
+}
+
+// EditLockResponse answers acquire and heartbeat calls. LockID is set
+// only for the holder, who sends it back to renew or release the lock;
+// when Acquired is false Lock is someone else's.
+type EditLockResponse struct {
+	Acquired bool       `json:"acquired"`
+	LockID   *uuid.UUID `json:"lock_id,omitempty"`
+	Lock     EditLock   `json:"lock"`
+}
+
+// EditLockStatus is the body of GET /tasks/{id}/edit-lock; Lock is null
+// when nobody is editing.
+type EditLockStatus struct {
+	TaskID uuid.UUID `json:"task_id"`
+	Lock   *EditLock `json:"lock"`
+}
+
+// EditLockRequest is the body of heartbeat and release.
+type EditLockRequest struct {
+	LockID uuid.UUID `json:"lock_id"`
+}
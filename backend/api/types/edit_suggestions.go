@@ -0,0 +1,50 @@
+package types
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type EditSuggestionStatus string
+
+const (
+	EditSuggestionPending  EditSuggestionStatus = "pending"
+	EditSuggestionAccepted EditSuggestionStatus = "accepted"
+	EditSuggestionRejected EditSuggestionStatus = "rejected"
+)
+
+// EditSuggestion is a change to a task's title or description proposed by
+// someone other than the reporter, who accepts or rejects it. Nil fields
+// are not part of the suggestion.
+type EditSuggestion struct {
+	ID          uuid.UUID            `json:"id"`
+	TaskID      uuid.UUID            `json:"task_id"`
+	TeamID      uuid.UUID            `json:"team_id"`
+	AuthorID    uuid.UUID            `json:"author_id"`
+	Title       *string              `json:"title,omitempty"`
+	Description *string              `json:"description,omitempty"`
+	Status      EditSuggestionStatus `json:"status"`
+	ResolvedBy  *uuid.UUID           `json:"resolved_by,omitempty"`
+	ResolvedAt  *time.Time           `json:"resolved_at,omitempty"`
+	CreatedAt   time.Time            `json:"created_at"`
+}
+
+// SuggestEditRequest is the body of POST /tasks/{id}/suggestions; at least
+// one field is required.
+type SuggestEditRequest struct {
+	Title       *string `json:"title"`
+	Description *string `json:"description"`
+}
+
+type EditSuggestionListResponse struct {
+	TaskID      uuid.UUID        `json:"task_id"`
+	Suggestions []EditSuggestion `json:"suggestions"`
+}
+
+// EditSuggestionDecision answers accept and reject; Task is the updated
+// task after an accept.
+type EditSuggestionDecision struct {
+	Suggestion EditSuggestion `json:"suggestion"`
+	Task       *Task          `json:"task,omitempty"`
+}
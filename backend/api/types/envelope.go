@@ -0,0 +1,24 @@
+package types
+
+import "time"
+
+// Envelope wraps every successful response. Data is absent for
+// message-only replies such as logout.
+type Envelope[T any] struct {
+	Data          T         `json:"data,omitempty"`
+	Message       string    `json:"message,omitempty"`
+	CorrelationID string    `json:"correlation_id,omitempty"`
+	Timestamp     time.Time `json:"timestamp"`
+}
+
+type ErrorResponse struct {
+	Error ErrorBody `json:"error"`
+}
+
+type ErrorBody struct {
+	Code    string `json:"code"`
+	Message string `json:"message"`
+	// NOTE: the wire name has always been "CorrelationID"; clients depend on it
+	CorrelationID string    `json:",omitempty"`
+	Timestamp     time.Time `json:"timestamp"`
+}
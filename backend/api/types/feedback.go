@@ -0,0 +1,58 @@
+package types
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// FeedbackLink is a team's anonymous feedback link. The token itself is
+// only shown when the link is made; the server keeps its sha256.
+type FeedbackLink struct {
+	TeamID    uuid.UUID  `json:"team_id"`
+	TeamName  string     `json:"team_name"`
+	Prompt    *string    `json:"prompt,omitempty"`
+	CreatedBy *uuid.UUID `json:"created_by,omitempty"`
+	CreatedAt time.Time  `json:"created_at"`
+}
+
+// FeedbackLinkStatus is the body of GET /teams/{team_id}/feedback-link;
+// Link is null when the team has none.
+type FeedbackLinkStatus struct {
+	TeamID  uuid.UUID     `json:"team_id"`
+	Enabled bool          `json:"enabled"`
+	Link    *FeedbackLink `json:"link"`
+}
+
+// CreateFeedbackLinkRequest is the optional body of POST
+// /teams/{team_id}/feedback-link.
+type CreateFeedbackLinkRequest struct {
+	Prompt *string `json:"prompt"`
+}
+
+// FeedbackLinkCreated carries the new link's token, the only time it is
+// shown. Path is where the public form lives, under the API version.
+type FeedbackLinkCreated struct {
+	Link  FeedbackLink `json:"link"`
+	Token string       `json:"token"`
+	Path  string       `json:"path"`
+}
+
+// PublicFeedback is what GET /feedback/{token} shows whoever has the link.
+type PublicFeedback struct {
+	TeamName string  `json:"team_name"`
+	Prompt   *string `json:"prompt"`
+}
+
+// FeedbackSubmission is the body of POST /feedback/{token}. Without a
+// title one is made from the message.
+type FeedbackSubmission struct {
+	Title   string `json:"title"`
+	Message string `json:"message"`
+}
+
+// FeedbackReceipt answers a submission with 202 whether it was kept or
+// dropped as spam, so senders cannot probe the filter.
+type FeedbackReceipt struct {
+	Status string `json:"status"`
+}
@@ -0,0 +1,66 @@
+package types
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Invitation is a team membership waiting for someone to register with
+// Email.
+type Invitation struct {
+	ID        uuid.UUID  `json:"id"`
+	TeamID    uuid.UUID  `json:"team_id"`
+	Email     string     `json:"email"`
+	Role      TeamRole   `json:"role"`
+	InvitedBy *uuid.UUID `json:"invited_by"`
+	CreatedAt time.Time  `json:"created_at"`
+	ExpiresAt time.Time  `json:"expires_at"`
+}
+
+type InvitationListResponse struct {
+	TeamID      uuid.UUID    `json:"team_id"`
+	Invitations []Invitation `json:"invitations"`
+}
+
+// MemberImportEntry is one person to add; Role defaults to member.
+type MemberImportEntry struct {
+	Email string   `json:"email"`
+	Role  TeamRole `json:"role,omitempty"`
+}
+
+// MemberImportRequest is the JSON body of POST
+// /teams/{team_id}/members/import.
+type MemberImportRequest struct {
+	Members []MemberImportEntry `json:"members"`
+}
+
+type MemberImportStatus string
+
+const (
+	ImportAdded         MemberImportStatus = "added"
+	ImportInvited       MemberImportStatus = "invited"
+	ImportAlreadyMember MemberImportStatus = "already_member"
+	ImportFailed        MemberImportStatus = "failed"
+)
+
+// MemberImportRow reports what happened to one entry. Row counts from 1,
+// not counting a CSV header.
+type MemberImportRow struct {
+	Row          int                `json:"row"`
+	Email        string             `json:"email"`
+	Role         TeamRole           `json:"role,omitempty"`
+	Status       MemberImportStatus `json:"status"`
+	Error        string             `json:"error,omitempty"`
+	UserID       *uuid.UUID         `json:"user_id,omitempty"`
+	InvitationID *uuid.UUID         `json:"invitation_id,omitempty"`
+}
+
+type MemberImportResponse struct {
+	TeamID        uuid.UUID         `json:"team_id"`
+	Added         int               `json:"added"`
+	Invited       int               `json:"invited"`
+	AlreadyMember int               `json:"already_member"`
+	Failed        int               `json:"failed"`
+	Rows          []MemberImportRow `json:"rows"`
+}
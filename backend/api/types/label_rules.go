@@ -0,0 +1,77 @@
+package types
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// LabelRuleField is the text a label rule looks at.
+type LabelRuleField string
+
+const (
+	LabelRuleFieldTitle       LabelRuleField = "title"
+	LabelRuleFieldDescription LabelRuleField = "description"
+	LabelRuleFieldAny         LabelRuleField = "any"
+)
+
+// LabelRule adds AddLabels and sets SetPriority on tasks whose Field
+// contains Contains, ignoring case.
+type LabelRule struct {
+	ID          uuid.UUID      `json:"id"`
+	TeamID      uuid.UUID      `json:"team_id"`
+	Name        string         `json:"name"`
+	Field       LabelRuleField `json:"field"`
+	Contains    string         `json:"contains"`
+	AddLabels   []string       `json:"add_labels"`
+	SetPriority *TaskPriority  `json:"set_priority"`
+	Enabled     bool           `json:"enabled"`
+	Position    int            `json:"position"`
+	CreatedBy   *uuid.UUID     `json:"created_by,omitempty"`
+	CreatedAt   time.Time      `json:"created_at"`
+	UpdatedAt   time.Time      `json:"updated_at"`
+}
+
+// LabelRuleRequest is the body of POST /teams/{team_id}/label-rules and
+// PUT /teams/{team_id}/label-rules/{rule_id}. Field defaults to title and
+// Enabled to true.
+type LabelRuleRequest struct {
+	Name        string         `json:"name"`
+	Field       LabelRuleField `json:"field"`
+	Contains    string         `json:"contains"`
+	AddLabels   []string       `json:"add_labels"`
+	SetPriority *TaskPriority  `json:"set_priority"`
+	Enabled     *bool          `json:"enabled"`
+}
+
+type LabelRuleListResponse struct {
+	TeamID uuid.UUID   `json:"team_id"`
+	Rules  []LabelRule `json:"rules"`
+}
+
+// LabelRuleDryRunRequest is the body of POST
+// /teams/{team_id}/label-rules/dry-run. Rule previews a draft instead of
+// the saved rules. With Title set the sample text is checked; otherwise the
+// team's most recent tasks are.
+type LabelRuleDryRunRequest struct {
+	Rule        *LabelRuleRequest `json:"rule"`
+	Title       *string           `json:"title"`
+	Description *string           `json:"description"`
+	Limit       int               `json:"limit"`
+}
+
+// LabelRuleMatch is what the rules would do to one task. TaskID is nil for
+// a sample.
+type LabelRuleMatch struct {
+	TaskID    *uuid.UUID    `json:"task_id,omitempty"`
+	Title     string        `json:"title"`
+	Rules     []string      `json:"rules"`
+	AddLabels []string      `json:"add_labels"`
+	Priority  *TaskPriority `json:"priority,omitempty"`
+}
+
+type LabelRuleDryRunResponse struct {
+	TeamID  uuid.UUID        `json:"team_id"`
+	Scanned int              `json:"scanned"`
+	Matches []LabelRuleMatch `json:"matches"`
+}
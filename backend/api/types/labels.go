@@ -0,0 +1,36 @@
+package types
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Label is a team-scoped tag on tasks. Names are unique per team, ignoring
+// case.
+type Label struct {
+	ID        uuid.UUID `json:"id"`
+	TeamID    uuid.UUID `json:"team_id"`
+	Name      string    `json:"name"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type LabelRequest struct {
+	Name string `json:"name"`
+}
+
+type LabelListResponse struct {
+	TeamID uuid.UUID `json:"team_id"`
+	Labels []Label   `json:"labels"`
+}
+
+// TaskLabelsRequest names labels to put on a task. Labels the team does not
+// have yet are created.
+type TaskLabelsRequest struct {
+	Labels []string `json:"labels"`
+}
+
+type TaskLabelsResponse struct {
+	TaskID uuid.UUID `json:"task_id"`
+	Labels []Label   `json:"labels"`
+}
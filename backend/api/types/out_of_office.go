@@ -0,0 +1,30 @@
+package types
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// OutOfOffice is a user's absence from StartsAt until EndsAt. Assigning
+// them a task in that time needs confirming, and BackupID is suggested
+// instead.
+type OutOfOffice struct {
+	UserID    uuid.UUID  `json:"user_id"`
+	StartsAt  time.Time  `json:"starts_at"`
+	EndsAt    time.Time  `json:"ends_at"`
+	BackupID  *uuid.UUID `json:"backup_id,omitempty"`
+	Note      *string    `json:"note,omitempty"`
+	UpdatedAt time.Time  `json:"updated_at"`
+	// Active is true between StartsAt and EndsAt
+	Active bool `json:"active"`
+}
+
+// SetOutOfOfficeRequest is the body of PUT /tasks/assignee/out-of-office.
+// StartsAt defaults to now. It replaces the caller's window, if any.
+type SetOutOfOfficeRequest struct {
+	StartsAt *time.Time `json:"starts_at"`
+	EndsAt   time.Time  `json:"ends_at"`
+	BackupID *uuid.UUID `json:"backup_id"`
+	Note     *string    `json:"note"`
+}
@@ -0,0 +1,38 @@
+package types
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Project groups tasks inside a team. Names are unique per team, ignoring
+// case.
+type Project struct {
+	ID          uuid.UUID  `json:"id"`
+	TeamID      uuid.UUID  `json:"team_id"`
+	Name        string     `json:"name"`
+	Description *string    `json:"description,omitempty"`
+	CreatedBy   *uuid.UUID `json:"created_by,omitempty"`
+	CreatedAt   time.Time  `json:"created_at"`
+	UpdatedAt   time.Time  `json:"updated_at"`
+	// OpenTasks counts the project's open and in-progress tasks
+	OpenTasks int `json:"open_tasks"`
+}
+
+type CreateProjectRequest struct {
+	Name        string  `json:"name"`
+	Description *string `json:"description"`
+}
+
+// UpdateProjectRequest is the body of PATCH /teams/{team_id}/projects/{id};
+// nil fields are left unchanged and an empty description removes it.
+type UpdateProjectRequest struct {
+	Name        *string `json:"name"`
+	Description *string `json:"description"`
+}
+
+type ProjectListResponse struct {
+	TeamID   uuid.UUID `json:"team_id"`
+	Projects []Project `json:"projects"`
+}
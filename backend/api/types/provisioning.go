@@ -0,0 +1,43 @@
+package types
+
+// PutUserRequest is the body of PUT /admin/provision/users/{external_id}.
+// Password is only used when the user is created; later PUTs leave the
+// password alone, so a definition can be applied again and again.
+type PutUserRequest struct {
+	Email    string   `json:"email"`
+	UserType UserType `json:"user_type"`
+	Password *string  `json:"password,omitempty"`
+}
+
+type ProvisionedUser struct {
+	ExternalID string `json:"external_id"`
+	User       User   `json:"user"`
+	// Created is false when the PUT found the user, by external_id or by
+	// email, and brought it in line
+	Created bool `json:"created"`
+}
+
+// ProvisionedMember is a team member named by their user's external_id.
+type ProvisionedMember struct {
+	ExternalID string   `json:"external_id"`
+	Role       TeamRole `json:"role"`
+}
+
+// PutTeamRequest is the body of PUT /admin/provision/teams/{external_id}.
+// Owner and Members are external ids of provisioned users; together they
+// are the whole membership, and anyone else is removed from the team.
+type PutTeamRequest struct {
+	Name    string              `json:"name"`
+	Owner   string              `json:"owner"`
+	Members []ProvisionedMember `json:"members"`
+}
+
+type ProvisionedTeam struct {
+	ExternalID string              `json:"external_id"`
+	Team       Team                `json:"team"`
+	Owner      string              `json:"owner"`
+	Members    []ProvisionedMember `json:"members"`
+	Created    bool                `json:"created"`
+	// Removed counts members dropped because the definition left them out
+	Removed int `json:"removed"`
+}
@@ -0,0 +1,36 @@
+package types
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// RealtimeEvent is the data of one event on GET /teams/{team_id}/events.
+// The SSE event name is Type as well.
+type RealtimeEvent struct {
+	Type    string     `json:"type"`
+	TeamID  uuid.UUID  `json:"team_id"`
+	TaskID  uuid.UUID  `json:"task_id"`
+	ActorID *uuid.UUID `json:"actor_id"`
+	// Status and From are set on task.status_changed
+	Status string `json:"status,omitempty"`
+	From   string `json:"from,omitempty"`
+	// Label is set on task.label_added
+	Label string `json:"label,omitempty"`
+	// Until is set on comment.typing, where ActorID is typing a comment,
+	// and task.edit_locked, where they are editing the description: show
+	// it until then unless another one comes
+	Until *time.Time `json:"until,omitempty"`
+	// DueAt is set on task.reminder, sent to the assignee alone
+	DueAt *time.Time `json:"due_at,omitempty"`
+	At    time.Time  `json:"at"`
+}
+
+// RealtimeShutdown is the data of the shutdown event sent before the
+// server closes a stream; reconnect after RetryMS with Last-Event-ID set
+// to LastEventID.
+type RealtimeShutdown struct {
+	LastEventID string `json:"last_event_id"`
+	RetryMS     int    `json:"retry_ms"`
+}
@@ -0,0 +1,43 @@
+package types
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ReminderChannel is how a reminder reaches the assignee.
+type ReminderChannel string
+
+const (
+	// ReminderEmail goes out through the configured notifier
+	ReminderEmail ReminderChannel = "email"
+	// ReminderInApp is a task.reminder event on the assignee's realtime
+	// streams, kept for replay like other team events
+	ReminderInApp ReminderChannel = "in_app"
+)
+
+// TaskReminder reminds the task's assignee OffsetMinutes before its due
+// date. Each one is sent once; moving the due date makes it due again.
+type TaskReminder struct {
+	ID            uuid.UUID       `json:"id"`
+	TaskID        uuid.UUID       `json:"task_id"`
+	OffsetMinutes int             `json:"offset_minutes"`
+	Channel       ReminderChannel `json:"channel"`
+	// RemindAt is the task's due date less the offset
+	RemindAt  time.Time  `json:"remind_at"`
+	SentAt    *time.Time `json:"sent_at"`
+	CreatedBy *uuid.UUID `json:"created_by,omitempty"`
+	CreatedAt time.Time  `json:"created_at"`
+}
+
+// CreateReminderRequest is the body of POST /tasks/{id}/reminders.
+type CreateReminderRequest struct {
+	OffsetMinutes int             `json:"offset_minutes"`
+	Channel       ReminderChannel `json:"channel"`
+}
+
+type TaskReminderListResponse struct {
+	TaskID    uuid.UUID      `json:"task_id"`
+	Reminders []TaskReminder `json:"reminders"`
+}
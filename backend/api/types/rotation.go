@@ -0,0 +1,61 @@
+package types
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// RotationCadence is how often a rotation hands off to its next member.
+type RotationCadence string
+
+const (
+	RotationDaily  RotationCadence = "daily"
+	RotationWeekly RotationCadence = "weekly"
+)
+
+// Rotation is a team's on-call schedule: MemberIDs take turns, in order,
+// each for one Cadence. Tasks created without an assignee that carry one of
+// Labels go to OnCallID.
+type Rotation struct {
+	ID        uuid.UUID       `json:"id"`
+	TeamID    uuid.UUID       `json:"team_id"`
+	Name      string          `json:"name"`
+	Cadence   RotationCadence `json:"cadence"`
+	MemberIDs []uuid.UUID     `json:"member_ids"`
+	Labels    []string        `json:"labels"`
+	// OnCallID is nil once the rotation has no members left
+	OnCallID *uuid.UUID `json:"on_call_id"`
+	// HandoffAt is when the next member takes over
+	HandoffAt time.Time  `json:"handoff_at"`
+	CreatedBy *uuid.UUID `json:"created_by,omitempty"`
+	CreatedAt time.Time  `json:"created_at"`
+	UpdatedAt time.Time  `json:"updated_at"`
+}
+
+// CreateRotationRequest is the body of POST /teams/{team_id}/rotations. The
+// first member is on call right away; HandoffAt defaults to one cadence
+// from now.
+type CreateRotationRequest struct {
+	Name      string          `json:"name"`
+	Cadence   RotationCadence `json:"cadence"`
+	MemberIDs []uuid.UUID     `json:"member_ids"`
+	Labels    []string        `json:"labels"`
+	HandoffAt *time.Time      `json:"handoff_at"`
+}
+
+// UpdateRotationRequest is the body of PATCH
+// /teams/{team_id}/rotations/{rotation_id}; nil fields are left unchanged
+// and an empty labels list removes them.
+type UpdateRotationRequest struct {
+	Name      *string          `json:"name"`
+	Cadence   *RotationCadence `json:"cadence"`
+	MemberIDs []uuid.UUID      `json:"member_ids"`
+	Labels    []string         `json:"labels"`
+	HandoffAt *time.Time       `json:"handoff_at"`
+}
+
+type RotationListResponse struct {
+	TeamID    uuid.UUID  `json:"team_id"`
+	Rotations []Rotation `json:"rotations"`
+}
@@ -0,0 +1,36 @@
+package types
+
+import "time"
+
+// SetupStatus is GET /setup. Required stays true until the first-run setup
+// has created an admin; after that the setup endpoint is gone.
+type SetupStatus struct {
+	Required bool    `json:"required"`
+	OrgName  *string `json:"org_name,omitempty"`
+	// Demo is set on installs running in demo mode
+	Demo *DemoInfo `json:"demo,omitempty"`
+}
+
+// DemoInfo is what a demo banner shows: the accounts visitors can log in
+// with and when their changes are wiped.
+type DemoInfo struct {
+	Accounts []string  `json:"accounts"`
+	Password string    `json:"password"`
+	ResetAt  time.Time `json:"reset_at"`
+}
+
+// SetupRequest is the body of POST /setup on a fresh install.
+type SetupRequest struct {
+	OrgName  string `json:"org_name"`
+	Email    string `json:"email"`
+	Password string `json:"password"`
+	TeamName string `json:"team_name"`
+}
+
+// SetupResponse names what the setup created. The admin logs in with
+// POST /auth/login as usual.
+type SetupResponse struct {
+	OrgName string `json:"org_name"`
+	Admin   User   `json:"admin"`
+	Team    Team   `json:"team"`
+}
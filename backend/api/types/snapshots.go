@@ -0,0 +1,32 @@
+package types
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Snapshot describes a stored copy of a team's tasks. The copy itself is
+// only read back by a restore.
+type Snapshot struct {
+	ID        uuid.UUID  `json:"id"`
+	TeamID    uuid.UUID  `json:"team_id"`
+	Name      string     `json:"name"`
+	TaskCount int        `json:"task_count"`
+	CreatedBy *uuid.UUID `json:"created_by"`
+	CreatedAt time.Time  `json:"created_at"`
+}
+
+type SnapshotListResponse struct {
+	TeamID    uuid.UUID  `json:"team_id"`
+	Snapshots []Snapshot `json:"snapshots"`
+}
+
+// SnapshotRestored is the team a snapshot was restored into.
+type SnapshotRestored struct {
+	TeamID  uuid.UUID `json:"team_id"`
+	Name    string    `json:"name"`
+	Tasks   int       `json:"tasks"`
+	Members int       `json:"members"`
+	Labels  int       `json:"labels"`
+}
@@ -0,0 +1,23 @@
+package types
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// StatusReportSubscription is the caller's weekly status report setting.
+// The times are only set while Subscribed.
+type StatusReportSubscription struct {
+	UserID       uuid.UUID  `json:"user_id"`
+	Subscribed   bool       `json:"subscribed"`
+	LastSentAt   *time.Time `json:"last_sent_at,omitempty"`
+	NextReportAt *time.Time `json:"next_report_at,omitempty"`
+	CreatedAt    *time.Time `json:"created_at,omitempty"`
+}
+
+// StatusReportUnsubscribeInfo answers GET on a report's unsubscribe link,
+// which changes nothing.
+type StatusReportUnsubscribeInfo struct {
+	Action string `json:"action"`
+}
@@ -0,0 +1,24 @@
+package types
+
+import "github.com/google/uuid"
+
+// BurnUpDay is one day of a burn-up chart. Every figure is cumulative:
+// Scope is the work created up to the end of the day, Completed the part
+// of it done by then. Unestimated tasks count in the task totals only.
+type BurnUpDay struct {
+	Day            string  `json:"day"`
+	ScopeHours     float64 `json:"scope_hours"`
+	CompletedHours float64 `json:"completed_hours"`
+	ScopeTasks     int     `json:"scope_tasks"`
+	CompletedTasks int     `json:"completed_tasks"`
+}
+
+// BurnUpResponse covers From to To inclusive, days in Timezone. Work
+// created before From is part of the first day.
+type BurnUpResponse struct {
+	TeamID   uuid.UUID   `json:"team_id"`
+	From     string      `json:"from"`
+	To       string      `json:"to"`
+	Timezone string      `json:"timezone"`
+	Days     []BurnUpDay `json:"days"`
+}
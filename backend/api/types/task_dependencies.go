@@ -0,0 +1,53 @@
+package types
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type AddDependencyRequest struct {
+	BlockerID uuid.UUID `json:"blocker_id"`
+}
+
+// TaskDependency says TaskID is blocked by BlockerID. The two tasks may be
+// in different teams.
+type TaskDependency struct {
+	TaskID    uuid.UUID  `json:"task_id"`
+	BlockerID uuid.UUID  `json:"blocker_id"`
+	CreatedBy *uuid.UUID `json:"created_by"`
+	CreatedAt time.Time  `json:"created_at"`
+}
+
+// DependencyTask is a task in a dependency view, with the name of its
+// team. Depth is how many links away it is from the task the chain was
+// read for, 1 for direct blockers and blocked tasks.
+type DependencyTask struct {
+	Task
+	TeamName string `json:"team_name"`
+	Depth    int    `json:"depth,omitempty"`
+}
+
+// TaskDependenciesResponse is the chain around one task: what blocks it,
+// directly or further up, and what it blocks. Links are followed only
+// through tasks the user can see; Hidden counts the tasks that stopped the
+// walk because they are in a team the user is not in, or private.
+type TaskDependenciesResponse struct {
+	TaskID    uuid.UUID        `json:"task_id"`
+	BlockedBy []DependencyTask `json:"blocked_by"`
+	Blocking  []DependencyTask `json:"blocking"`
+	Links     []TaskDependency `json:"links"`
+	Hidden    int              `json:"hidden"`
+}
+
+// CrossTeamDependency is a link between tasks of two different teams.
+type CrossTeamDependency struct {
+	Task      DependencyTask `json:"task"`
+	Blocker   DependencyTask `json:"blocker"`
+	CreatedBy *uuid.UUID     `json:"created_by"`
+	CreatedAt time.Time      `json:"created_at"`
+}
+
+type CrossTeamDependenciesResponse struct {
+	Dependencies []CrossTeamDependency `json:"dependencies"`
+}
@@ -0,0 +1,46 @@
+package types
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type TaskEventKind string
+
+const (
+	TaskEventCreated           TaskEventKind = "created"
+	TaskEventAssigned          TaskEventKind = "assigned"
+	TaskEventStatusChanged     TaskEventKind = "status_changed"
+	TaskEventUpdated           TaskEventKind = "updated"
+	TaskEventVisibilityChanged TaskEventKind = "visibility_changed"
+	TaskEventDeleted           TaskEventKind = "deleted"
+	TaskEventRestored          TaskEventKind = "restored"
+	TaskEventArchived          TaskEventKind = "archived"
+	TaskEventUnarchived        TaskEventKind = "unarchived"
+)
+
+// FieldChange is a task field before and after a change. From is null on
+// created and restored events and To on deleted ones. Descriptions are
+// recorded with both null: the history only tells that they changed.
+type FieldChange struct {
+	From any `json:"from"`
+	To   any `json:"to"`
+}
+
+// TaskEvent is one change to a task. ActorID is null for changes made by
+// automations and for users who were deleted since.
+type TaskEvent struct {
+	ID        uuid.UUID              `json:"id"`
+	TaskID    uuid.UUID              `json:"task_id"`
+	TeamID    uuid.UUID              `json:"team_id"`
+	ActorID   *uuid.UUID             `json:"actor_id"`
+	Kind      TaskEventKind          `json:"kind"`
+	Changes   map[string]FieldChange `json:"changes"`
+	CreatedAt time.Time              `json:"created_at"`
+}
+
+type TaskHistoryResponse struct {
+	TaskID uuid.UUID   `json:"task_id"`
+	Events []TaskEvent `json:"events"`
+}
@@ -0,0 +1,28 @@
+package types
+
+import "github.com/google/uuid"
+
+type TaskImportStatus string
+
+const (
+	TaskImportCreated  TaskImportStatus = "created"
+	TaskImportRejected TaskImportStatus = "rejected"
+)
+
+// TaskImportRow reports what happened to one CSV row. Row counts from 1,
+// not counting the header.
+type TaskImportRow struct {
+	Row    int              `json:"row"`
+	Title  string           `json:"title,omitempty"`
+	Status TaskImportStatus `json:"status"`
+	Error  string           `json:"error,omitempty"`
+	TaskID *uuid.UUID       `json:"task_id,omitempty"`
+}
+
+// TaskImportResponse is the report of POST /teams/{team_id}/tasks/import.
+type TaskImportResponse struct {
+	TeamID   uuid.UUID       `json:"team_id"`
+	Created  int             `json:"created"`
+	Rejected int             `json:"rejected"`
+	Rows     []TaskImportRow `json:"rows"`
+}
@@ -0,0 +1,27 @@
+package types
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// TaskVisibility is whether a task is private and which team members
+// were added as its viewers.
+type TaskVisibility struct {
+	TaskID    uuid.UUID   `json:"task_id"`
+	Private   bool        `json:"private"`
+	ViewerIDs []uuid.UUID `json:"viewer_ids"`
+}
+
+// TaskView is one recorded look at a task, read from the audit log.
+type TaskView struct {
+	ViewerID *uuid.UUID `json:"viewer_id"`
+	IP       string     `json:"ip,omitempty"`
+	ViewedAt time.Time  `json:"viewed_at"`
+}
+
+type TaskViewsResponse struct {
+	TaskID uuid.UUID  `json:"task_id"`
+	Views  []TaskView `json:"views"`
+}
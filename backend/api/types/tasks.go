@@ -0,0 +1,324 @@
+package types
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type TaskStatus string
+
+const (
+	TaskStatusOpen       TaskStatus = "open"
+	TaskStatusInProgress TaskStatus = "in_progress"
+	TaskStatusDone       TaskStatus = "done"
+	TaskStatusCanceled   TaskStatus = "canceled"
+)
+
+type TaskPriority string
+
+const (
+	TaskPriorityLow    TaskPriority = "low"
+	TaskPriorityNormal TaskPriority = "normal"
+	TaskPriorityHigh   TaskPriority = "high"
+	TaskPriorityUrgent TaskPriority = "urgent"
+)
+
+// Task is the wire form of a task. The task store reads straight into it.
+type Task struct {
+	ID          uuid.UUID `json:"id"`
+	TeamID      uuid.UUID `json:"team_id"`
+	Title       string    `json:"title"`
+	Description *string   `json:"description,omitempty"`
+	ReporterID  uuid.UUID `json:"reporter_id"`
+	AssigneeID  uuid.UUID `json:"assignee_id"`
+	// StartAt is when work is planned to begin, before DueAt; null when
+	// only the due date is known
+	StartAt  *time.Time   `json:"start_at"`
+	DueAt    time.Time    `json:"due_at"`
+	Status   TaskStatus   `json:"status"`
+	Priority TaskPriority `json:"priority"`
+	// EstimateHours is the work the task is expected to take, if known
+	EstimateHours  *float64   `json:"estimate_hours"`
+	WorkflowState  *string    `json:"workflow_state,omitempty"`
+	Private        bool       `json:"private"`
+	AssignedAt     time.Time  `json:"assigned_at"`
+	AcknowledgedAt *time.Time `json:"acknowledged_at"`
+	// AnnouncementID is set on tasks an org admin sent to every team
+	AnnouncementID *uuid.UUID `json:"announcement_id,omitempty"`
+	CreatedAt      time.Time  `json:"created_at"`
+	UpdatedAt      time.Time  `json:"updated_at"`
+	// Version goes up with every change; send it back as If-Match or
+	// "version" to refuse the update if someone else changed the task first
+	Version int64 `json:"version"`
+	// CompletedAt and CanceledAt are when the task last moved to done or
+	// canceled; both are cleared when it is reopened
+	CompletedAt *time.Time `json:"completed_at"`
+	CanceledAt  *time.Time `json:"canceled_at"`
+	// ArchivedAt is set while the task is archived; archived tasks are
+	// left out of lists unless ?include_archived=true
+	ArchivedAt *time.Time `json:"archived_at"`
+	// Position orders the task within its status column on a board,
+	// lowest first; see PATCH /tasks/{id}/position
+	Position float64 `json:"position"`
+	// VoteCount is the number of team members who upvoted the task
+	VoteCount int `json:"vote_count"`
+	// ProjectID is the team project the task belongs to, if any
+	ProjectID *uuid.UUID `json:"project_id"`
+	// CustomFields holds the values of the team's custom fields, by key;
+	// see CustomField
+	CustomFields map[string]any `json:"custom_fields"`
+	// Labels is set on task list, search and get responses
+	Labels []Label `json:"labels,omitempty"`
+	// Archived is set on tasks read from tasks_archive, the cold storage
+	// of long-finished tasks; it is unrelated to ArchivedAt
+	Archived bool `json:"archived,omitempty"`
+	// DueDateNotice is set on create and edit responses when due_at fell on
+	// one of the team's non-working days
+	DueDateNotice *DueDateNotice `json:"due_date_notice,omitempty"`
+	// AssignmentNotice is set on create responses when auto-assignment
+	// passed over a member who is out of office
+	AssignmentNotice *AssignmentNotice `json:"assignment_notice,omitempty"`
+	// PossibleDuplicates is set on create responses when semantic search
+	// is enabled and similar tasks exist in the team
+	PossibleDuplicates []SimilarTask `json:"possible_duplicates,omitempty"`
+}
+
+// SimilarTask is a task found by semantic search. Score is the cosine
+// similarity, 1 for identical text.
+type SimilarTask struct {
+	Task
+	Score float64 `json:"score"`
+}
+
+// TrashedTask is a deleted task that can still be restored until PurgeAt.
+type TrashedTask struct {
+	Task
+	DeletedAt time.Time  `json:"deleted_at"`
+	DeletedBy *uuid.UUID `json:"deleted_by"`
+	PurgeAt   time.Time  `json:"purge_at"`
+}
+
+// ExportedTask is a task in a team export, with its Labels set and the
+// emails of its reporter and assignee. Archived tasks have no labels.
+type ExportedTask struct {
+	Task
+	ReporterEmail string `json:"reporter_email"`
+	AssigneeEmail string `json:"assignee_email"`
+}
+
+type TrashListResponse struct {
+	TeamID uuid.UUID     `json:"team_id"`
+	Tasks  []TrashedTask `json:"tasks"`
+}
+
+type SimilarTaskListResponse struct {
+	TeamID uuid.UUID     `json:"team_id"`
+	Query  string        `json:"query"`
+	Tasks  []SimilarTask `json:"tasks"`
+}
+
+// TaskSearchResult is a task found by full-text search. Rank only orders
+// the results of one search; it has no fixed scale.
+type TaskSearchResult struct {
+	Task
+	Rank float64 `json:"rank"`
+}
+
+type TaskSearchResponse struct {
+	TeamID uuid.UUID          `json:"team_id"`
+	Query  string             `json:"query"`
+	Tasks  []TaskSearchResult `json:"tasks"`
+}
+
+// DueDateNotice explains a due date on a non-working day. Under the shift
+// policy DueAt has already been moved and Requested is what was asked for.
+type DueDateNotice struct {
+	Requested time.Time `json:"requested"`
+	Reason    string    `json:"reason"`
+	Shifted   bool      `json:"shifted"`
+}
+
+// AssignmentNotice explains why a task went to someone other than the
+// on-call member or label route: Skipped is out of office until Until.
+// BackupID is set when their backup took the task instead.
+type AssignmentNotice struct {
+	Skipped  uuid.UUID  `json:"skipped"`
+	Until    time.Time  `json:"until"`
+	BackupID *uuid.UUID `json:"backup_id,omitempty"`
+	Reason   string     `json:"reason"`
+}
+
+// AssigneeWorkload is a user's open and in-progress tasks across all
+// their teams.
+type AssigneeWorkload struct {
+	OpenTasks        int     `json:"open_tasks"`
+	EstimatedHours   float64 `json:"estimated_hours"`
+	UnestimatedTasks int     `json:"unestimated_tasks"`
+	Overdue          int     `json:"overdue"`
+}
+
+// DueDateSuggestion is advisory: nothing is saved, and the team's due date
+// policy still applies to whatever date the task is created with.
+type DueDateSuggestion struct {
+	TeamID         uuid.UUID        `json:"team_id"`
+	AssigneeID     uuid.UUID        `json:"assignee_id"`
+	SuggestedDueAt time.Time        `json:"suggested_due_at"`
+	WorkingDays    int              `json:"working_days"`
+	EstimateHours  float64          `json:"estimate_hours"`
+	QueuedHours    float64          `json:"queued_hours"`
+	Workload       AssigneeWorkload `json:"workload"`
+	// the assumptions behind the numbers, so the UI can show them
+	HoursPerDay          float64 `json:"hours_per_day"`
+	DefaultEstimateHours float64 `json:"default_estimate_hours"`
+}
+
+// CreateTaskRequest is the body of POST /tasks. Without AssigneeID the
+// team's auto_assign strategy picks one, or the caller gets the task.
+// Labels are created in the team if missing. Priority defaults to what the
+// team's label rules set, or normal.
+type CreateTaskRequest struct {
+	TeamID      uuid.UUID     `json:"team_id"`
+	Title       string        `json:"title"`
+	Description *string       `json:"description"`
+	AssigneeID  *uuid.UUID    `json:"assignee_id"`
+	StartAt     *time.Time    `json:"start_at"`
+	DueAt       time.Time     `json:"due_at"`
+	Private     bool          `json:"private"`
+	ViewerIDs   []uuid.UUID   `json:"viewer_ids"`
+	Labels      []string      `json:"labels"`
+	Priority    *TaskPriority `json:"priority"`
+	// EstimateHours feeds due date suggestions for the assignee
+	EstimateHours *float64 `json:"estimate_hours"`
+	// ProjectID must be a project of the task's team
+	ProjectID *uuid.UUID `json:"project_id"`
+	// CustomFields are checked against the team's custom fields; every
+	// required one must be given
+	CustomFields map[string]any `json:"custom_fields"`
+	// AllowOutOfOffice assigns AssigneeID even while they are out of office
+	AllowOutOfOffice bool `json:"allow_out_of_office,omitempty"`
+	// DueLocal is the due date as "2006-01-02 15:04" in the caller's
+	// timezone; it cannot be set with DueAt
+	DueLocal *string `json:"due_local,omitempty"`
+}
+
+// PatchTaskRequest is the body of PATCH /tasks/{id}/update-details; nil
+// fields are left unchanged.
+type PatchTaskRequest struct {
+	Title         *string       `json:"title"`
+	Description   *string       `json:"description"`
+	StartAt       *time.Time    `json:"start_at"`
+	DueAt         *time.Time    `json:"due_at"`
+	Priority      *TaskPriority `json:"priority"`
+	EstimateHours *float64      `json:"estimate_hours"`
+	// DueLocal is DueAt as a wall clock time in the caller's timezone;
+	// it cannot be set with DueAt
+	DueLocal *string `json:"due_local,omitempty"`
+	// ClearStartAt removes the start date; it cannot be set with StartAt
+	ClearStartAt bool `json:"clear_start_at,omitempty"`
+	// ProjectID moves the task to another project of its team;
+	// ClearProject takes it out of its project
+	ProjectID    *uuid.UUID `json:"project_id"`
+	ClearProject bool       `json:"clear_project,omitempty"`
+	// CustomFields sets the given values and leaves the others; a null
+	// value clears the field
+	CustomFields map[string]any `json:"custom_fields,omitempty"`
+	// Version is the task version the edit was made against; see Task
+	Version *int64 `json:"version,omitempty"`
+}
+
+type AssignTaskRequest struct {
+	AssigneeID uuid.UUID `json:"assignee_id"`
+	Version    *int64    `json:"version,omitempty"`
+	// AllowOutOfOffice assigns even while the assignee is out of office
+	AllowOutOfOffice bool `json:"allow_out_of_office,omitempty"`
+}
+
+// MoveTaskRequest is the body of PATCH /tasks/{id}/position: the cards
+// the task was dropped between in its status column. Either may be left
+// out at the ends of the column.
+type MoveTaskRequest struct {
+	AfterID  *uuid.UUID `json:"after_id"`
+	BeforeID *uuid.UUID `json:"before_id"`
+}
+
+type BulkAssignRequest struct {
+	TaskIDs    []uuid.UUID `json:"task_ids"`
+	AssigneeID uuid.UUID   `json:"assignee_id"`
+	// AllowOutOfOffice assigns even while the assignee is out of office
+	AllowOutOfOffice bool `json:"allow_out_of_office,omitempty"`
+}
+
+// BulkAssignResponse lists the tasks that changed hands; Unchanged counts
+// those the assignee already had.
+type BulkAssignResponse struct {
+	AssigneeID uuid.UUID `json:"assignee_id"`
+	Tasks      []Task    `json:"tasks"`
+	Unchanged  int       `json:"unchanged"`
+}
+
+type UpdateStatusRequest struct {
+	Status  TaskStatus `json:"status"`
+	Version *int64     `json:"version,omitempty"`
+}
+
+type GetTaskResponse struct {
+	UserID uuid.UUID `json:"user_id"`
+	Task   *Task     `json:"task"`
+}
+
+// TaskListMeta describes which list a TaskListResponse holds.
+type TaskListMeta struct {
+	UserID     uuid.UUID  `json:"user_id"`
+	TeamID     *uuid.UUID `json:"team_id,omitempty"`
+	AsReporter *bool      `json:"as_reporter,omitempty"`
+}
+
+// Pagination tells which page of a list a response holds. Total counts the
+// whole list, so the last page is ceil(total / per_page); it is null for
+// pages read with a cursor, which have no page number either. NextCursor
+// reads on from the end of this page and is empty on the last one.
+type Pagination struct {
+	Page       int    `json:"page,omitempty"`
+	PerPage    int    `json:"per_page"`
+	Total      *int   `json:"total"`
+	NextCursor string `json:"next_cursor,omitempty"`
+}
+
+type TaskListResponse struct {
+	TaskListMeta
+	Pagination
+	Tasks []Task `json:"tasks"`
+}
+
+// PartialTaskListResponse is returned instead of TaskListResponse when
+// ?fields= is set: each task has only the requested keys. Clients can
+// decode it into TaskListResponse.
+type PartialTaskListResponse struct {
+	TaskListMeta
+	Pagination
+	Tasks []map[string]any `json:"tasks"`
+}
+
+// StaleAssignee groups one assignee's stale tasks.
+type StaleAssignee struct {
+	AssigneeID uuid.UUID `json:"assignee_id"`
+	Count      int       `json:"count"`
+	Tasks      []Task    `json:"tasks"`
+}
+
+// StaleTasksResponse lists the team's active tasks untouched since
+// StaleSince, by assignee.
+type StaleTasksResponse struct {
+	TeamID     uuid.UUID       `json:"team_id"`
+	Days       int             `json:"days"`
+	StaleSince time.Time       `json:"stale_since"`
+	Total      int             `json:"total"`
+	Assignees  []StaleAssignee `json:"assignees"`
+}
+
+// ExportLink is a signed export URL that needs no Authorization header.
+type ExportLink struct {
+	URL       string    `json:"url"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
@@ -0,0 +1,63 @@
+package types
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type TeamRole string
+
+const (
+	TeamRoleOwner  TeamRole = "owner"
+	TeamRoleAdmin  TeamRole = "admin"
+	TeamRoleMember TeamRole = "member"
+)
+
+type Team struct {
+	ID        uuid.UUID `json:"id"`
+	Name      string    `json:"name"`
+	OwnerID   uuid.UUID `json:"owner_id"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+type TeamMember struct {
+	TeamID    uuid.UUID `json:"team_id"`
+	UserID    uuid.UUID `json:"user_id"`
+	Role      TeamRole  `json:"role"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// CreateTeamRequest is the body of POST /teams.
+type CreateTeamRequest struct {
+	Name string `json:"name"`
+}
+
+type TeamListResponse struct {
+	UserID uuid.UUID `json:"user_id"`
+	Teams  []Team    `json:"teams"`
+}
+
+type MemberListResponse struct {
+	TeamID  uuid.UUID    `json:"team_id"`
+	Members []TeamMember `json:"members"`
+}
+
+// AddMemberRequest is the body of POST /teams/{team_id}/members.
+type AddMemberRequest struct {
+	UserID uuid.UUID `json:"user_id"`
+	Role   TeamRole  `json:"role"`
+}
+
+type AddMemberResponse struct {
+	// NOTE: camelCase on the wire for historical reasons
+	TeamID uuid.UUID `json:"teamID"`
+	Member User      `json:"member"`
+}
+
+type RemoveMemberResponse struct {
+	Message string    `json:"message"`
+	TeamID  uuid.UUID `json:"team_id"`
+	UserID  uuid.UUID `json:"user_id"`
+}
@@ -0,0 +1,44 @@
+package types
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type TriageStatus string
+
+// TriageSource says how an item reached the inbox.
+type TriageSource string
+
+// TriageItem is a submission waiting for a manager to schedule and assign
+// it. It only becomes a task once accepted.
+type TriageItem struct {
+	ID          uuid.UUID  `json:"id"`
+	TeamID      uuid.UUID  `json:"team_id"`
+	FormID      *uuid.UUID `json:"form_id,omitempty"`
+	Title       string     `json:"title"`
+	Description *string    `json:"description,omitempty"`
+	SubmittedBy *uuid.UUID `json:"submitted_by,omitempty"`
+	// Source is feedback for anonymous feedback, which has no form and
+	// never a submitter
+	Source    TriageSource `json:"source"`
+	Status    TriageStatus `json:"status"`
+	TaskID    *uuid.UUID   `json:"task_id,omitempty"`
+	DecidedBy *uuid.UUID   `json:"decided_by,omitempty"`
+	DecidedAt *time.Time   `json:"decided_at,omitempty"`
+	Reason    *string      `json:"reason,omitempty"`
+	CreatedAt time.Time    `json:"created_at"`
+}
+
+type TriageListResponse struct {
+	TeamID uuid.UUID    `json:"team_id"`
+	Status TriageStatus `json:"status"`
+	Items  []TriageItem `json:"items"`
+}
+
+// TriageAcceptResponse is the accepted item along with the task it became.
+type TriageAcceptResponse struct {
+	Item *TriageItem `json:"item"`
+	Task *Task       `json:"task"`
+}
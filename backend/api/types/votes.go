@@ -0,0 +1,22 @@
+package types
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// TaskVoter is a team member who upvoted a task.
+type TaskVoter struct {
+	UserID  uuid.UUID `json:"user_id"`
+	VotedAt time.Time `json:"voted_at"`
+}
+
+// TaskVotesResponse answers the vote endpoints. Voted says whether the
+// caller has voted; Voters is only listed by GET /tasks/{id}/votes.
+type TaskVotesResponse struct {
+	TaskID    uuid.UUID   `json:"task_id"`
+	VoteCount int         `json:"vote_count"`
+	Voted     bool        `json:"voted"`
+	Voters    []TaskVoter `json:"voters,omitempty"`
+}
@@ -0,0 +1,7 @@
+package types
+
+// WebhookDuplicate answers a delivery that was already handled, so the
+// provider stops retrying it.
+type WebhookDuplicate struct {
+	Duplicate bool `json:"duplicate"`
+}
@@ -0,0 +1,62 @@
+package client
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/diagnosis/interactive-todo/api/types"
+)
+
+// Register creates an employee account. It needs no token source.
+func (c *Client) Register(ctx context.Context, in types.RegisterRequest) (*types.RegisterResponse, error) {
+	var out types.RegisterResponse
+	if _, err := c.do(ctx, http.MethodPost, "/auth/register", nil, in, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// SetupStatus reports whether the install still needs its first-run setup.
+// It needs no token source.
+func (c *Client) SetupStatus(ctx context.Context) (*types.SetupStatus, error) {
+	var out types.SetupStatus
+	if _, err := c.do(ctx, http.MethodGet, "/setup", nil, nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// Setup creates the first admin and their team on a fresh install. It
+// fails with a 404 once the install has any user.
+func (c *Client) Setup(ctx context.Context, in types.SetupRequest) (*types.SetupResponse, error) {
+	var out types.SetupResponse
+	if _, err := c.do(ctx, http.MethodPost, "/setup", nil, in, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// LogoutAll revokes every session of the current user, including the
+// client's own access token.
+func (c *Client) LogoutAll(ctx context.Context) error {
+	_, err := c.do(ctx, http.MethodPost, "/auth/logout-all", nil, nil, nil)
+	return err
+}
+
+// Profile returns the current user, including their timezone.
+func (c *Client) Profile(ctx context.Context) (*types.User, error) {
+	var out types.User
+	if _, err := c.do(ctx, http.MethodGet, "/auth/me", nil, nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// UpdateProfile changes the current user's timezone.
+func (c *Client) UpdateProfile(ctx context.Context, in types.UpdateProfileRequest) (*types.User, error) {
+	var out types.User
+	if _, err := c.do(ctx, http.MethodPatch, "/auth/me", nil, in, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
@@ -0,0 +1,256 @@
+// Package client is the Go SDK for the interactive-todo API.
+//
+//	sess := client.NewPasswordSession(baseURL, email, password)
+//	c := client.New(baseURL, sess)
+//	task, err := c.GetTask(ctx, id)
+//
+// Request and response bodies are the api/types structs the server uses.
+// Failed calls return *APIError.
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand/v2"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/diagnosis/interactive-todo/api/types"
+)
+
+// APIVersion is the path prefix every request is sent under.
+const APIVersion = "/v1"
+
+const (
+	defaultMaxRetries = 3
+	baseBackoff       = 200 * time.Millisecond
+	maxBackoff        = 5 * time.Second
+)
+
+type Client struct {
+	baseURL    string
+	tokens     TokenSource
+	httpClient *http.Client
+	maxRetries int
+	userAgent  string
+}
+
+type Option func(*Client)
+
+// WithHTTPClient replaces http.DefaultClient.
+func WithHTTPClient(hc *http.Client) Option {
+	return func(c *Client) { c.httpClient = hc }
+}
+
+// WithMaxRetries sets how often idempotent requests are retried after a
+// transport error or a 429/502/503/504. 0 disables retries.
+func WithMaxRetries(n int) Option {
+	return func(c *Client) { c.maxRetries = n }
+}
+
+func WithUserAgent(ua string) Option {
+	return func(c *Client) { c.userAgent = ua }
+}
+
+// New returns a client for the API at baseURL, e.g. "https://todo.example.com".
+// tokens may be nil for the public endpoints only.
+func New(baseURL string, tokens TokenSource, opts ...Option) *Client {
+	c := &Client{
+		baseURL:    strings.TrimRight(baseURL, "/"),
+		tokens:     tokens,
+		httpClient: http.DefaultClient,
+		maxRetries: defaultMaxRetries,
+		userAgent:  "interactive-todo-go-client",
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
+}
+
+// APIError is a non-2xx response.
+type APIError struct {
+	StatusCode    int
+	Code          string
+	Message       string
+	CorrelationID string
+	// RetryAfter is set from the Retry-After header, if any
+	RetryAfter time.Duration
+}
+
+func (e *APIError) Error() string {
+	return fmt.Sprintf("api: %d %s: %s", e.StatusCode, e.Code, e.Message)
+}
+
+// IsStatus reports whether err is an *APIError with the given status.
+func IsStatus(err error, status int) bool {
+	var ae *APIError
+	return errors.As(err, &ae) && ae.StatusCode == status
+}
+
+// do sends a request and decodes the envelope's data into out (which may be
+// nil). It returns the response status.
+func (c *Client) do(ctx context.Context, method, path string, query url.Values, in, out any) (int, error) {
+	var body []byte
+	if in != nil {
+		var err error
+		if body, err = json.Marshal(in); err != nil {
+			return 0, fmt.Errorf("encode %s %s: %w", method, path, err)
+		}
+	}
+	target := c.baseURL + APIVersion + path
+	if len(query) > 0 {
+		target += "?" + query.Encode()
+	}
+
+	refreshed := false
+	for attempt := 0; ; attempt++ {
+		resp, err := c.send(ctx, method, target, body)
+		if err != nil {
+			if ctx.Err() == nil && retryable(method) && attempt < c.maxRetries {
+				if werr := wait(ctx, backoff(attempt, 0)); werr != nil {
+					return 0, werr
+				}
+				continue
+			}
+			return 0, err
+		}
+
+		status, err := decode(resp, out)
+		if err == nil {
+			return status, nil
+		}
+
+		var ae *APIError
+		if !errors.As(err, &ae) {
+			return status, err
+		}
+		// an access token can expire between minting and use; refresh once
+		if ae.StatusCode == http.StatusUnauthorized && !refreshed {
+			if r, ok := c.tokens.(Refresher); ok {
+				refreshed = true
+				if rerr := r.Refresh(ctx); rerr == nil {
+					continue
+				}
+			}
+		}
+		if retryableStatus(ae.StatusCode) && retryable(method) && attempt < c.maxRetries {
+			if werr := wait(ctx, backoff(attempt, ae.RetryAfter)); werr != nil {
+				return status, werr
+			}
+			continue
+		}
+		return status, err
+	}
+}
+
+func (c *Client) send(ctx context.Context, method, target string, body []byte) (*http.Response, error) {
+	var rdr io.Reader
+	if body != nil {
+		rdr = bytes.NewReader(body)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, target, rdr)
+	if err != nil {
+		return nil, err
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	req.Header.Set("Accept", "application/json")
+	req.Header.Set("User-Agent", c.userAgent)
+	if c.tokens != nil {
+		tok, err := c.tokens.Token(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("get access token: %w", err)
+		}
+		req.Header.Set("Authorization", "Bearer "+tok)
+	}
+	return c.httpClient.Do(req)
+}
+
+func decode(resp *http.Response, out any) (int, error) {
+	defer resp.Body.Close()
+	raw, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return resp.StatusCode, fmt.Errorf("read response: %w", err)
+	}
+
+	if resp.StatusCode >= 300 {
+		ae := &APIError{StatusCode: resp.StatusCode}
+		var er types.ErrorResponse
+		if json.Unmarshal(raw, &er) == nil && er.Error.Code != "" {
+			ae.Code = er.Error.Code
+			ae.Message = er.Error.Message
+			ae.CorrelationID = er.Error.CorrelationID
+		} else {
+			ae.Message = strings.TrimSpace(string(raw))
+		}
+		if s, err := strconv.Atoi(resp.Header.Get("Retry-After")); err == nil {
+			ae.RetryAfter = time.Duration(s) * time.Second
+		}
+		return resp.StatusCode, ae
+	}
+
+	if out == nil || len(raw) == 0 {
+		return resp.StatusCode, nil
+	}
+	env := types.Envelope[json.RawMessage]{}
+	if err := json.Unmarshal(raw, &env); err != nil {
+		return resp.StatusCode, fmt.Errorf("decode envelope: %w", err)
+	}
+	if len(env.Data) == 0 {
+		return resp.StatusCode, nil
+	}
+	if err := json.Unmarshal(env.Data, out); err != nil {
+		return resp.StatusCode, fmt.Errorf("decode data: %w", err)
+	}
+	return resp.StatusCode, nil
+}
+
+func retryable(method string) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodPut, http.MethodDelete:
+		return true
+	}
+	return false
+}
+
+func retryableStatus(status int) bool {
+	switch status {
+	case http.StatusTooManyRequests, http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return true
+	}
+	return false
+}
+
+// backoff is exponential with full jitter; the server's Retry-After wins
+// when it asks for longer.
+func backoff(attempt int, retryAfter time.Duration) time.Duration {
+	d := baseBackoff << attempt
+	if d > maxBackoff {
+		d = maxBackoff
+	}
+	d = time.Duration(rand.Int64N(int64(d) + 1))
+	if retryAfter > d {
+		d = retryAfter
+	}
+	return d
+}
+
+func wait(ctx context.Context, d time.Duration) error {
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-t.C:
+		return nil
+	}
+}
@@ -0,0 +1,527 @@
+package client
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/diagnosis/interactive-todo/api/types"
+	"github.com/google/uuid"
+)
+
+func (c *Client) CreateTask(ctx context.Context, in types.CreateTaskRequest) (*types.Task, error) {
+	var out types.Task
+	if _, err := c.do(ctx, http.MethodPost, "/tasks/", nil, in, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+func (c *Client) GetTask(ctx context.Context, id uuid.UUID) (*types.Task, error) {
+	var out types.GetTaskResponse
+	if _, err := c.do(ctx, http.MethodGet, taskPath(id, ""), nil, nil, &out); err != nil {
+		return nil, err
+	}
+	return out.Task, nil
+}
+
+// DeleteTask moves the task to its team's trash, from which RestoreTask
+// brings it back until it is purged.
+func (c *Client) DeleteTask(ctx context.Context, id uuid.UUID) error {
+	_, err := c.do(ctx, http.MethodDelete, taskPath(id, ""), nil, nil, nil)
+	return err
+}
+
+func (c *Client) RestoreTask(ctx context.Context, id uuid.UUID) (*types.Task, error) {
+	var out types.Task
+	if _, err := c.do(ctx, http.MethodPost, taskPath(id, "/restore"), nil, nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// ArchiveTask takes the task out of default lists without deleting it;
+// UnarchiveTask puts it back.
+func (c *Client) ArchiveTask(ctx context.Context, id uuid.UUID) (*types.Task, error) {
+	var out types.Task
+	if _, err := c.do(ctx, http.MethodPost, taskPath(id, "/archive"), nil, nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+func (c *Client) UnarchiveTask(ctx context.Context, id uuid.UUID) (*types.Task, error) {
+	var out types.Task
+	if _, err := c.do(ctx, http.MethodPost, taskPath(id, "/unarchive"), nil, nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+func (c *Client) AssignTask(ctx context.Context, id, assigneeID uuid.UUID) (*types.Task, error) {
+	var out types.Task
+	if _, err := c.do(ctx, http.MethodPatch, taskPath(id, "/assign"), nil, types.AssignTaskRequest{AssigneeID: assigneeID}, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// MoveTask puts the task between two cards of its status column on the
+// board; either neighbour may be nil at the ends of the column.
+func (c *Client) MoveTask(ctx context.Context, id uuid.UUID, afterID, beforeID *uuid.UUID) (*types.Task, error) {
+	var out types.Task
+	in := types.MoveTaskRequest{AfterID: afterID, BeforeID: beforeID}
+	if _, err := c.do(ctx, http.MethodPatch, taskPath(id, "/position"), nil, in, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// BulkAssignTasks gives the team's tasks to assigneeID in one change. It
+// fails, changing nothing, if any of them cannot be reassigned.
+func (c *Client) BulkAssignTasks(ctx context.Context, teamID uuid.UUID, taskIDs []uuid.UUID, assigneeID uuid.UUID) (*types.BulkAssignResponse, error) {
+	var out types.BulkAssignResponse
+	in := types.BulkAssignRequest{TaskIDs: taskIDs, AssigneeID: assigneeID}
+	if _, err := c.do(ctx, http.MethodPatch, "/teams/"+teamID.String()+"/tasks/bulk/assign", nil, in, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// UpdateTaskStatus changes the status. In teams that require approval the
+// change is held for review: pending is true and the task is unchanged.
+func (c *Client) UpdateTaskStatus(ctx context.Context, id uuid.UUID, status types.TaskStatus) (task *types.Task, pending bool, err error) {
+	var out struct {
+		types.Task
+		// only present on 202 Accepted
+		Held *types.Task `json:"task"`
+	}
+	code, err := c.do(ctx, http.MethodPatch, taskPath(id, "/status"), nil, types.UpdateStatusRequest{Status: status}, &out)
+	if err != nil {
+		return nil, false, err
+	}
+	if code == http.StatusAccepted && out.Held != nil {
+		return out.Held, true, nil
+	}
+	return &out.Task, false, nil
+}
+
+func (c *Client) PatchTask(ctx context.Context, id uuid.UUID, in types.PatchTaskRequest) (*types.Task, error) {
+	var out types.Task
+	if _, err := c.do(ctx, http.MethodPatch, taskPath(id, "/update-details"), nil, in, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// SuggestEdit proposes a new title and/or description to the task's
+// reporter, who accepts or rejects it.
+func (c *Client) SuggestEdit(ctx context.Context, id uuid.UUID, in types.SuggestEditRequest) (*types.EditSuggestion, error) {
+	var out types.EditSuggestion
+	if _, err := c.do(ctx, http.MethodPost, taskPath(id, "/suggestions"), nil, in, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+func (c *Client) EditSuggestions(ctx context.Context, id uuid.UUID) ([]types.EditSuggestion, error) {
+	var out types.EditSuggestionListResponse
+	if _, err := c.do(ctx, http.MethodGet, taskPath(id, "/suggestions"), nil, nil, &out); err != nil {
+		return nil, err
+	}
+	return out.Suggestions, nil
+}
+
+// AcceptEditSuggestion applies the suggestion and returns the updated
+// task in the decision; RejectEditSuggestion leaves the task alone.
+func (c *Client) AcceptEditSuggestion(ctx context.Context, id, suggestionID uuid.UUID) (*types.EditSuggestionDecision, error) {
+	var out types.EditSuggestionDecision
+	if _, err := c.do(ctx, http.MethodPost, taskPath(id, "/suggestions/"+suggestionID.String()+"/accept"), nil, nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+func (c *Client) RejectEditSuggestion(ctx context.Context, id, suggestionID uuid.UUID) (*types.EditSuggestionDecision, error) {
+	var out types.EditSuggestionDecision
+	if _, err := c.do(ctx, http.MethodPost, taskPath(id, "/suggestions/"+suggestionID.String()+"/reject"), nil, nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// VoteTask upvotes the task; UnvoteTask takes the vote back. Both return
+// the vote count without the voters.
+func (c *Client) VoteTask(ctx context.Context, id uuid.UUID) (*types.TaskVotesResponse, error) {
+	var out types.TaskVotesResponse
+	if _, err := c.do(ctx, http.MethodPost, taskPath(id, "/vote"), nil, nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+func (c *Client) UnvoteTask(ctx context.Context, id uuid.UUID) (*types.TaskVotesResponse, error) {
+	var out types.TaskVotesResponse
+	if _, err := c.do(ctx, http.MethodDelete, taskPath(id, "/vote"), nil, nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+func (c *Client) TaskVotes(ctx context.Context, id uuid.UUID) (*types.TaskVotesResponse, error) {
+	var out types.TaskVotesResponse
+	if _, err := c.do(ctx, http.MethodGet, taskPath(id, "/votes"), nil, nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+func (c *Client) TaskReminders(ctx context.Context, id uuid.UUID) ([]types.TaskReminder, error) {
+	var out types.TaskReminderListResponse
+	if _, err := c.do(ctx, http.MethodGet, taskPath(id, "/reminders"), nil, nil, &out); err != nil {
+		return nil, err
+	}
+	return out.Reminders, nil
+}
+
+// CreateTaskReminder reminds the assignee offsetMinutes before the task's
+// due date on channel, email when empty.
+func (c *Client) CreateTaskReminder(ctx context.Context, id uuid.UUID, offsetMinutes int, channel types.ReminderChannel) (*types.TaskReminder, error) {
+	var out types.TaskReminder
+	in := types.CreateReminderRequest{OffsetMinutes: offsetMinutes, Channel: channel}
+	if _, err := c.do(ctx, http.MethodPost, taskPath(id, "/reminders"), nil, in, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+func (c *Client) DeleteTaskReminder(ctx context.Context, id, reminderID uuid.UUID) error {
+	_, err := c.do(ctx, http.MethodDelete, taskPath(id, "/reminders/"+reminderID.String()), nil, nil, nil)
+	return err
+}
+
+// AddTaskLabels puts labels on a task by name and returns all of its
+// labels. Names the team does not have yet become new labels.
+func (c *Client) AddTaskLabels(ctx context.Context, id uuid.UUID, names []string) ([]types.Label, error) {
+	var out types.TaskLabelsResponse
+	if _, err := c.do(ctx, http.MethodPost, taskPath(id, "/labels"), nil, types.TaskLabelsRequest{Labels: names}, &out); err != nil {
+		return nil, err
+	}
+	return out.Labels, nil
+}
+
+func (c *Client) RemoveTaskLabel(ctx context.Context, id, labelID uuid.UUID) ([]types.Label, error) {
+	var out types.TaskLabelsResponse
+	if _, err := c.do(ctx, http.MethodDelete, taskPath(id, "/labels/"+labelID.String()), nil, nil, &out); err != nil {
+		return nil, err
+	}
+	return out.Labels, nil
+}
+
+func (c *Client) TaskAttachments(ctx context.Context, id uuid.UUID) ([]types.Attachment, error) {
+	var out types.AttachmentListResponse
+	if _, err := c.do(ctx, http.MethodGet, taskPath(id, "/attachments"), nil, nil, &out); err != nil {
+		return nil, err
+	}
+	return out.Attachments, nil
+}
+
+// AttachmentLink returns a signed download URL, a path on the API that
+// works without a token for a few minutes.
+func (c *Client) AttachmentLink(ctx context.Context, id, attachmentID uuid.UUID) (*types.AttachmentLink, error) {
+	var out types.AttachmentLink
+	if _, err := c.do(ctx, http.MethodPost, taskPath(id, "/attachments/"+attachmentID.String()+"/link"), nil, nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+func (c *Client) DeleteAttachment(ctx context.Context, id, attachmentID uuid.UUID) error {
+	_, err := c.do(ctx, http.MethodDelete, taskPath(id, "/attachments/"+attachmentID.String()), nil, nil, nil)
+	return err
+}
+
+// TaskHistory returns the task's changes, newest first. A zero limit uses
+// the server default.
+func (c *Client) TaskHistory(ctx context.Context, id uuid.UUID, limit int) ([]types.TaskEvent, error) {
+	q := url.Values{}
+	if limit > 0 {
+		q.Set("limit", strconv.Itoa(limit))
+	}
+	var out types.TaskHistoryResponse
+	if _, err := c.do(ctx, http.MethodGet, taskPath(id, "/history"), q, nil, &out); err != nil {
+		return nil, err
+	}
+	return out.Events, nil
+}
+
+// TaskDependencies returns the chain of blockers and blocked tasks around
+// the task, depth links in each direction. A zero depth uses the server
+// default.
+func (c *Client) TaskDependencies(ctx context.Context, id uuid.UUID, depth int) (*types.TaskDependenciesResponse, error) {
+	q := url.Values{}
+	if depth > 0 {
+		q.Set("depth", strconv.Itoa(depth))
+	}
+	var out types.TaskDependenciesResponse
+	if _, err := c.do(ctx, http.MethodGet, taskPath(id, "/dependencies"), q, nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// AddDependency marks the task as blocked by blockerID.
+func (c *Client) AddDependency(ctx context.Context, id, blockerID uuid.UUID) (*types.TaskDependency, error) {
+	var out types.TaskDependency
+	if _, err := c.do(ctx, http.MethodPost, taskPath(id, "/dependencies"), nil, types.AddDependencyRequest{BlockerID: blockerID}, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+func (c *Client) RemoveDependency(ctx context.Context, id, blockerID uuid.UUID) error {
+	_, err := c.do(ctx, http.MethodDelete, taskPath(id, "/dependencies/"+blockerID.String()), nil, nil, nil)
+	return err
+}
+
+// CrossTeamDependencies lists links between tasks of different teams the
+// user is in, newest first. A zero limit uses the server default.
+func (c *Client) CrossTeamDependencies(ctx context.Context, includeResolved bool, limit int) ([]types.CrossTeamDependency, error) {
+	q := url.Values{}
+	if includeResolved {
+		q.Set("include_resolved", "true")
+	}
+	if limit > 0 {
+		q.Set("limit", strconv.Itoa(limit))
+	}
+	var out types.CrossTeamDependenciesResponse
+	if _, err := c.do(ctx, http.MethodGet, "/tasks/dependencies", q, nil, &out); err != nil {
+		return nil, err
+	}
+	return out.Dependencies, nil
+}
+
+// ListOptions applies to every task list. Fields limits the keys returned
+// (?fields=); the rest of each Task is left zero. Lists are paged: Page
+// starts at 1, and zero values use the server defaults (page 1, 50 tasks).
+// Statuses, Labels (tasks having all of them), DueAfter and StartAfter
+// (inclusive), and DueBefore and StartBefore (exclusive) filter on the
+// server. Sort is due_at, created_at, priority, position (board order) or
+// votes, and Order asc or desc.
+// Cursor takes the NextCursor of a previous page in place of Page and
+// needs the same Sort and Order.
+type ListOptions struct {
+	Fields    []string
+	Page      int
+	Cursor    string
+	PerPage   int
+	Statuses  []types.TaskStatus
+	Labels    []string
+	DueAfter  time.Time
+	DueBefore time.Time
+	// StartAfter and StartBefore match only tasks with a start_at
+	StartAfter  time.Time
+	StartBefore time.Time
+	Sort        string
+	Order       string
+	// IncludeArchived lists archived tasks too
+	IncludeArchived bool
+	// ProjectID keeps one project's tasks; NoProject those in none
+	ProjectID *uuid.UUID
+	NoProject bool
+}
+
+func (o ListOptions) query() url.Values {
+	q := url.Values{}
+	if len(o.Fields) > 0 {
+		q.Set("fields", strings.Join(o.Fields, ","))
+	}
+	if o.Page > 0 {
+		q.Set("page", strconv.Itoa(o.Page))
+	}
+	if o.Cursor != "" {
+		q.Set("cursor", o.Cursor)
+	}
+	if o.PerPage > 0 {
+		q.Set("per_page", strconv.Itoa(o.PerPage))
+	}
+	if len(o.Statuses) > 0 {
+		statuses := make([]string, len(o.Statuses))
+		for i, s := range o.Statuses {
+			statuses[i] = string(s)
+		}
+		q.Set("status", strings.Join(statuses, ","))
+	}
+	if len(o.Labels) > 0 {
+		q.Set("label", strings.Join(o.Labels, ","))
+	}
+	if !o.DueAfter.IsZero() {
+		q.Set("due_after", o.DueAfter.UTC().Format(time.RFC3339))
+	}
+	if !o.DueBefore.IsZero() {
+		q.Set("due_before", o.DueBefore.UTC().Format(time.RFC3339))
+	}
+	if !o.StartAfter.IsZero() {
+		q.Set("start_after", o.StartAfter.UTC().Format(time.RFC3339))
+	}
+	if !o.StartBefore.IsZero() {
+		q.Set("start_before", o.StartBefore.UTC().Format(time.RFC3339))
+	}
+	if o.Sort != "" {
+		q.Set("sort", o.Sort)
+	}
+	if o.Order != "" {
+		q.Set("order", o.Order)
+	}
+	if o.IncludeArchived {
+		q.Set("include_archived", "true")
+	}
+	switch {
+	case o.NoProject:
+		q.Set("project_id", "none")
+	case o.ProjectID != nil:
+		q.Set("project_id", o.ProjectID.String())
+	}
+	return q
+}
+
+// AssignedTasks lists tasks assigned to the caller across all teams.
+func (c *Client) AssignedTasks(ctx context.Context, opts ListOptions) ([]types.Task, error) {
+	return c.listTasks(ctx, "/tasks/assignee", opts)
+}
+
+// ReportedTasks lists tasks the caller created across all teams.
+func (c *Client) ReportedTasks(ctx context.Context, opts ListOptions) ([]types.Task, error) {
+	return c.listTasks(ctx, "/tasks/reporter", opts)
+}
+
+// StatusReport tells whether the caller gets the weekly status report on
+// the tasks they created.
+func (c *Client) StatusReport(ctx context.Context) (*types.StatusReportSubscription, error) {
+	var out types.StatusReportSubscription
+	if _, err := c.do(ctx, http.MethodGet, "/tasks/reporter/status-report", nil, nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+func (c *Client) SubscribeStatusReport(ctx context.Context) (*types.StatusReportSubscription, error) {
+	var out types.StatusReportSubscription
+	if _, err := c.do(ctx, http.MethodPut, "/tasks/reporter/status-report", nil, nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+func (c *Client) UnsubscribeStatusReport(ctx context.Context) error {
+	_, err := c.do(ctx, http.MethodDelete, "/tasks/reporter/status-report", nil, nil, nil)
+	return err
+}
+
+// OutOfOffice returns the caller's out-of-office window.
+func (c *Client) OutOfOffice(ctx context.Context) (*types.OutOfOffice, error) {
+	var out types.OutOfOffice
+	if _, err := c.do(ctx, http.MethodGet, "/tasks/assignee/out-of-office", nil, nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+func (c *Client) SetOutOfOffice(ctx context.Context, in types.SetOutOfOfficeRequest) (*types.OutOfOffice, error) {
+	var out types.OutOfOffice
+	if _, err := c.do(ctx, http.MethodPut, "/tasks/assignee/out-of-office", nil, in, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+func (c *Client) ClearOutOfOffice(ctx context.Context) error {
+	_, err := c.do(ctx, http.MethodDelete, "/tasks/assignee/out-of-office", nil, nil, nil)
+	return err
+}
+
+// CalendarFeed returns the URL of the caller's calendar feed, relative to
+// the server.
+func (c *Client) CalendarFeed(ctx context.Context) (*types.CalendarFeed, error) {
+	var out types.CalendarFeed
+	if _, err := c.do(ctx, http.MethodGet, "/tasks/assignee/calendar-feed", nil, nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// CreateCalendarFeed turns the feed on with a new URL, replacing the old
+// one.
+func (c *Client) CreateCalendarFeed(ctx context.Context) (*types.CalendarFeed, error) {
+	var out types.CalendarFeed
+	if _, err := c.do(ctx, http.MethodPost, "/tasks/assignee/calendar-feed", nil, nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+func (c *Client) DeleteCalendarFeed(ctx context.Context) error {
+	_, err := c.do(ctx, http.MethodDelete, "/tasks/assignee/calendar-feed", nil, nil, nil)
+	return err
+}
+
+func (c *Client) TeamTasks(ctx context.Context, teamID uuid.UUID, opts ListOptions) ([]types.Task, error) {
+	return c.listTasks(ctx, "/teams/"+teamID.String()+"/tasks", opts)
+}
+
+// TeamTasksPage is TeamTasks with the pagination of the response, whose
+// NextCursor reads the following page.
+func (c *Client) TeamTasksPage(ctx context.Context, teamID uuid.UUID, opts ListOptions) (*types.TaskListResponse, error) {
+	return c.listPage(ctx, "/teams/"+teamID.String()+"/tasks", opts)
+}
+
+// SearchTeamTasks runs a full-text search over the team's tasks. A zero
+// limit uses the server default.
+func (c *Client) SearchTeamTasks(ctx context.Context, teamID uuid.UUID, query string, limit int) ([]types.TaskSearchResult, error) {
+	q := url.Values{"q": {query}}
+	if limit > 0 {
+		q.Set("limit", strconv.Itoa(limit))
+	}
+	var out types.TaskSearchResponse
+	if _, err := c.do(ctx, http.MethodGet, "/teams/"+teamID.String()+"/tasks/search", q, nil, &out); err != nil {
+		return nil, err
+	}
+	return out.Tasks, nil
+}
+
+// TeamTrash lists the team's deleted tasks, most recently deleted first.
+// A zero limit uses the server default.
+func (c *Client) TeamTrash(ctx context.Context, teamID uuid.UUID, limit int) ([]types.TrashedTask, error) {
+	q := url.Values{}
+	if limit > 0 {
+		q.Set("limit", strconv.Itoa(limit))
+	}
+	var out types.TrashListResponse
+	if _, err := c.do(ctx, http.MethodGet, "/teams/"+teamID.String()+"/tasks/trash", q, nil, &out); err != nil {
+		return nil, err
+	}
+	return out.Tasks, nil
+}
+
+func (c *Client) listTasks(ctx context.Context, path string, opts ListOptions) ([]types.Task, error) {
+	out, err := c.listPage(ctx, path, opts)
+	if err != nil {
+		return nil, err
+	}
+	return out.Tasks, nil
+}
+
+func (c *Client) listPage(ctx context.Context, path string, opts ListOptions) (*types.TaskListResponse, error) {
+	var out types.TaskListResponse
+	if _, err := c.do(ctx, http.MethodGet, path, opts.query(), nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+func taskPath(id uuid.UUID, suffix string) string {
+	return "/tasks/" + id.String() + suffix
+}
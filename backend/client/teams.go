@@ -0,0 +1,244 @@
+package client
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/diagnosis/interactive-todo/api/types"
+	"github.com/google/uuid"
+)
+
+func (c *Client) CreateTeam(ctx context.Context, name string) (*types.Team, error) {
+	var out types.Team
+	if _, err := c.do(ctx, http.MethodPost, "/teams/", nil, types.CreateTeamRequest{Name: name}, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// MyTeams lists the teams the caller belongs to.
+func (c *Client) MyTeams(ctx context.Context) ([]types.Team, error) {
+	var out types.TeamListResponse
+	if _, err := c.do(ctx, http.MethodGet, "/teams/mine", nil, nil, &out); err != nil {
+		return nil, err
+	}
+	return out.Teams, nil
+}
+
+func (c *Client) TeamMembers(ctx context.Context, teamID uuid.UUID) ([]types.TeamMember, error) {
+	var out types.MemberListResponse
+	if _, err := c.do(ctx, http.MethodGet, "/teams/"+teamID.String()+"/members", nil, nil, &out); err != nil {
+		return nil, err
+	}
+	return out.Members, nil
+}
+
+func (c *Client) AddTeamMember(ctx context.Context, teamID uuid.UUID, in types.AddMemberRequest) (*types.AddMemberResponse, error) {
+	var out types.AddMemberResponse
+	if _, err := c.do(ctx, http.MethodPost, "/teams/"+teamID.String()+"/members", nil, in, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+func (c *Client) RemoveTeamMember(ctx context.Context, teamID, userID uuid.UUID) error {
+	_, err := c.do(ctx, http.MethodDelete, "/teams/"+teamID.String()+"/members/"+userID.String(), nil, nil, nil)
+	return err
+}
+
+// ImportMembers adds people by email: users with an account join at once,
+// other emails are invited. Every entry gets a row in the report.
+func (c *Client) ImportMembers(ctx context.Context, teamID uuid.UUID, entries []types.MemberImportEntry) (*types.MemberImportResponse, error) {
+	var out types.MemberImportResponse
+	if _, err := c.do(ctx, http.MethodPost, "/teams/"+teamID.String()+"/members/import", nil, types.MemberImportRequest{Members: entries}, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+func (c *Client) TeamInvitations(ctx context.Context, teamID uuid.UUID) ([]types.Invitation, error) {
+	var out types.InvitationListResponse
+	if _, err := c.do(ctx, http.MethodGet, "/teams/"+teamID.String()+"/invitations", nil, nil, &out); err != nil {
+		return nil, err
+	}
+	return out.Invitations, nil
+}
+
+func (c *Client) RevokeInvitation(ctx context.Context, teamID, invitationID uuid.UUID) error {
+	_, err := c.do(ctx, http.MethodDelete, "/teams/"+teamID.String()+"/invitations/"+invitationID.String(), nil, nil, nil)
+	return err
+}
+
+func (c *Client) DirectoryLink(ctx context.Context, teamID uuid.UUID) (*types.DirectoryLink, error) {
+	var out types.DirectoryLink
+	if _, err := c.do(ctx, http.MethodGet, "/teams/"+teamID.String()+"/directory-sync", nil, nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+func (c *Client) PutDirectoryLink(ctx context.Context, teamID uuid.UUID, in types.PutDirectoryLinkRequest) (*types.DirectoryLink, error) {
+	var out types.DirectoryLink
+	if _, err := c.do(ctx, http.MethodPut, "/teams/"+teamID.String()+"/directory-sync", nil, in, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+func (c *Client) DeleteDirectoryLink(ctx context.Context, teamID uuid.UUID) error {
+	_, err := c.do(ctx, http.MethodDelete, "/teams/"+teamID.String()+"/directory-sync", nil, nil, nil)
+	return err
+}
+
+// RunDirectorySync syncs the team with its directory group now.
+func (c *Client) RunDirectorySync(ctx context.Context, teamID uuid.UUID) (*types.DirectorySyncResult, error) {
+	var out types.DirectorySyncResult
+	if _, err := c.do(ctx, http.MethodPost, "/teams/"+teamID.String()+"/directory-sync/run", nil, nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+func (c *Client) TeamLabels(ctx context.Context, teamID uuid.UUID) ([]types.Label, error) {
+	var out types.LabelListResponse
+	if _, err := c.do(ctx, http.MethodGet, "/teams/"+teamID.String()+"/labels", nil, nil, &out); err != nil {
+		return nil, err
+	}
+	return out.Labels, nil
+}
+
+func (c *Client) CreateLabel(ctx context.Context, teamID uuid.UUID, name string) (*types.Label, error) {
+	var out types.Label
+	if _, err := c.do(ctx, http.MethodPost, "/teams/"+teamID.String()+"/labels", nil, types.LabelRequest{Name: name}, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+func (c *Client) RenameLabel(ctx context.Context, teamID, labelID uuid.UUID, name string) (*types.Label, error) {
+	var out types.Label
+	if _, err := c.do(ctx, http.MethodPatch, "/teams/"+teamID.String()+"/labels/"+labelID.String(), nil, types.LabelRequest{Name: name}, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// DeleteLabel removes the label from the team and every task that has it.
+func (c *Client) DeleteLabel(ctx context.Context, teamID, labelID uuid.UUID) error {
+	_, err := c.do(ctx, http.MethodDelete, "/teams/"+teamID.String()+"/labels/"+labelID.String(), nil, nil, nil)
+	return err
+}
+
+func (c *Client) TeamProjects(ctx context.Context, teamID uuid.UUID) ([]types.Project, error) {
+	var out types.ProjectListResponse
+	if _, err := c.do(ctx, http.MethodGet, "/teams/"+teamID.String()+"/projects", nil, nil, &out); err != nil {
+		return nil, err
+	}
+	return out.Projects, nil
+}
+
+func (c *Client) CreateProject(ctx context.Context, teamID uuid.UUID, in types.CreateProjectRequest) (*types.Project, error) {
+	var out types.Project
+	if _, err := c.do(ctx, http.MethodPost, "/teams/"+teamID.String()+"/projects", nil, in, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+func (c *Client) UpdateProject(ctx context.Context, teamID, projectID uuid.UUID, in types.UpdateProjectRequest) (*types.Project, error) {
+	var out types.Project
+	if _, err := c.do(ctx, http.MethodPatch, "/teams/"+teamID.String()+"/projects/"+projectID.String(), nil, in, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// DeleteProject removes the project; its tasks stay in the team.
+func (c *Client) DeleteProject(ctx context.Context, teamID, projectID uuid.UUID) error {
+	_, err := c.do(ctx, http.MethodDelete, "/teams/"+teamID.String()+"/projects/"+projectID.String(), nil, nil, nil)
+	return err
+}
+
+func (c *Client) TeamRotations(ctx context.Context, teamID uuid.UUID) ([]types.Rotation, error) {
+	var out types.RotationListResponse
+	if _, err := c.do(ctx, http.MethodGet, "/teams/"+teamID.String()+"/rotations", nil, nil, &out); err != nil {
+		return nil, err
+	}
+	return out.Rotations, nil
+}
+
+// CreateRotation puts the first member on call right away.
+func (c *Client) CreateRotation(ctx context.Context, teamID uuid.UUID, in types.CreateRotationRequest) (*types.Rotation, error) {
+	var out types.Rotation
+	if _, err := c.do(ctx, http.MethodPost, "/teams/"+teamID.String()+"/rotations", nil, in, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+func (c *Client) UpdateRotation(ctx context.Context, teamID, rotationID uuid.UUID, in types.UpdateRotationRequest) (*types.Rotation, error) {
+	var out types.Rotation
+	if _, err := c.do(ctx, http.MethodPatch, "/teams/"+teamID.String()+"/rotations/"+rotationID.String(), nil, in, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+func (c *Client) DeleteRotation(ctx context.Context, teamID, rotationID uuid.UUID) error {
+	_, err := c.do(ctx, http.MethodDelete, "/teams/"+teamID.String()+"/rotations/"+rotationID.String(), nil, nil, nil)
+	return err
+}
+
+// TeamDelegations lists the team's delegations that have not ended.
+func (c *Client) TeamDelegations(ctx context.Context, teamID uuid.UUID) ([]types.Delegation, error) {
+	var out types.DelegationListResponse
+	if _, err := c.do(ctx, http.MethodGet, "/teams/"+teamID.String()+"/delegations", nil, nil, &out); err != nil {
+		return nil, err
+	}
+	return out.Delegations, nil
+}
+
+// CreateDelegation delegates the caller's powers over the tasks they
+// created in the team, replacing their previous delegation there.
+func (c *Client) CreateDelegation(ctx context.Context, teamID uuid.UUID, in types.CreateDelegationRequest) (*types.Delegation, error) {
+	var out types.Delegation
+	if _, err := c.do(ctx, http.MethodPost, "/teams/"+teamID.String()+"/delegations", nil, in, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+func (c *Client) DeleteDelegation(ctx context.Context, teamID, delegationID uuid.UUID) error {
+	_, err := c.do(ctx, http.MethodDelete, "/teams/"+teamID.String()+"/delegations/"+delegationID.String(), nil, nil, nil)
+	return err
+}
+
+func (c *Client) TeamCustomFields(ctx context.Context, teamID uuid.UUID) ([]types.CustomField, error) {
+	var out types.CustomFieldListResponse
+	if _, err := c.do(ctx, http.MethodGet, "/teams/"+teamID.String()+"/custom-fields", nil, nil, &out); err != nil {
+		return nil, err
+	}
+	return out.Fields, nil
+}
+
+func (c *Client) CreateCustomField(ctx context.Context, teamID uuid.UUID, in types.CreateCustomFieldRequest) (*types.CustomField, error) {
+	var out types.CustomField
+	if _, err := c.do(ctx, http.MethodPost, "/teams/"+teamID.String()+"/custom-fields", nil, in, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+func (c *Client) UpdateCustomField(ctx context.Context, teamID, fieldID uuid.UUID, in types.UpdateCustomFieldRequest) (*types.CustomField, error) {
+	var out types.CustomField
+	if _, err := c.do(ctx, http.MethodPatch, "/teams/"+teamID.String()+"/custom-fields/"+fieldID.String(), nil, in, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// DeleteCustomField removes the field and its values from the team's tasks.
+func (c *Client) DeleteCustomField(ctx context.Context, teamID, fieldID uuid.UUID) error {
+	_, err := c.do(ctx, http.MethodDelete, "/teams/"+teamID.String()+"/custom-fields/"+fieldID.String(), nil, nil, nil)
+	return err
+}
@@ -0,0 +1,134 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/cookiejar"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/diagnosis/interactive-todo/api/types"
+)
+
+// TokenSource supplies the bearer access token for each request.
+type TokenSource interface {
+	Token(ctx context.Context) (string, error)
+}
+
+// Refresher is implemented by token sources that can get a new access
+// token; the client calls Refresh once when a request comes back 401.
+type Refresher interface {
+	Refresh(ctx context.Context) error
+}
+
+type staticToken string
+
+func (t staticToken) Token(context.Context) (string, error) { return string(t), nil }
+
+// StaticToken always returns tok, e.g. a machine token.
+func StaticToken(tok string) TokenSource { return staticToken(tok) }
+
+// refreshSkew renews the access token this long before it expires.
+const refreshSkew = 30 * time.Second
+
+// PasswordSession logs in with email and password and keeps the access
+// token fresh through /auth/refresh, using the refresh cookie the server
+// sets. If the refresh token is rejected it logs in again.
+type PasswordSession struct {
+	baseURL  string
+	email    string
+	password string
+	http     *http.Client
+
+	mu        sync.Mutex
+	token     string
+	expiresAt time.Time
+	user      types.SessionUser
+}
+
+func NewPasswordSession(baseURL, email, password string) *PasswordSession {
+	jar, _ := cookiejar.New(nil)
+	return &PasswordSession{
+		baseURL:  strings.TrimRight(baseURL, "/"),
+		email:    email,
+		password: password,
+		http:     &http.Client{Jar: jar, Timeout: 30 * time.Second},
+	}
+}
+
+func (s *PasswordSession) Token(ctx context.Context) (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.token != "" && time.Until(s.expiresAt) > refreshSkew {
+		return s.token, nil
+	}
+	if err := s.renewLocked(ctx); err != nil {
+		return "", err
+	}
+	return s.token, nil
+}
+
+func (s *PasswordSession) Refresh(ctx context.Context) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.renewLocked(ctx)
+}
+
+// User is the account the session belongs to; zero before the first token.
+func (s *PasswordSession) User() types.SessionUser {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.user
+}
+
+func (s *PasswordSession) renewLocked(ctx context.Context) error {
+	if s.token != "" {
+		err := s.exchange(ctx, "/auth/refresh", nil)
+		if err == nil {
+			return nil
+		}
+		var ae *APIError
+		if !errors.As(err, &ae) || ae.StatusCode != http.StatusUnauthorized {
+			return err
+		}
+	}
+	return s.exchange(ctx, "/auth/login", types.LoginRequest{Email: s.email, Password: s.password})
+}
+
+func (s *PasswordSession) exchange(ctx context.Context, path string, in any) error {
+	var body bytes.Buffer
+	if in != nil {
+		if err := json.NewEncoder(&body).Encode(in); err != nil {
+			return err
+		}
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.baseURL+APIVersion+path, &body)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := s.http.Do(req)
+	if err != nil {
+		return fmt.Errorf("%s: %w", path, err)
+	}
+
+	var out types.TokenResponse
+	if _, err := decode(resp, &out); err != nil {
+		return err
+	}
+	s.token = out.AccessToken
+	s.expiresAt = time.Now().Add(time.Duration(out.ExpiresIn) * time.Second)
+	s.user = out.User
+	return nil
+}
+
+var (
+	_ TokenSource = (*PasswordSession)(nil)
+	_ Refresher   = (*PasswordSession)(nil)
+)
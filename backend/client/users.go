@@ -0,0 +1,68 @@
+package client
+
+import (
+	"context"
+	"iter"
+	"net/http"
+	"net/url"
+	"strconv"
+
+	"github.com/diagnosis/interactive-todo/api/types"
+)
+
+type ListUsersOptions struct {
+	UserType types.UserType
+	// Sort is email (default), -email, created_at or -created_at
+	Sort   string
+	Limit  int
+	Offset int
+}
+
+func (o ListUsersOptions) query() url.Values {
+	q := url.Values{}
+	if o.UserType != "" {
+		q.Set("user_type", string(o.UserType))
+	}
+	if o.Sort != "" {
+		q.Set("sort", o.Sort)
+	}
+	if o.Limit > 0 {
+		q.Set("limit", strconv.Itoa(o.Limit))
+	}
+	if o.Offset > 0 {
+		q.Set("offset", strconv.Itoa(o.Offset))
+	}
+	return q
+}
+
+// ListUsers returns one page; see Users to walk all of them.
+func (c *Client) ListUsers(ctx context.Context, opts ListUsersOptions) (*types.UserListResponse, error) {
+	var out types.UserListResponse
+	if _, err := c.do(ctx, http.MethodGet, "/users/", opts.query(), nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// Users iterates over every user visible to the caller, fetching pages as
+// needed. Iteration stops at the first error, which is yielded once.
+func (c *Client) Users(ctx context.Context, opts ListUsersOptions) iter.Seq2[types.UserSummary, error] {
+	return func(yield func(types.UserSummary, error) bool) {
+		for {
+			page, err := c.ListUsers(ctx, opts)
+			if err != nil {
+				yield(types.UserSummary{}, err)
+				return
+			}
+			for _, u := range page.Users {
+				if !yield(u, nil) {
+					return
+				}
+			}
+			if page.NextOffset == nil {
+				return
+			}
+			opts.Offset = *page.NextOffset
+		}
+	}
+}
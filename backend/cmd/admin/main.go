@@ -0,0 +1,122 @@
+// Command admin runs maintenance tasks against the database outside the
+// API server. It reads the same configuration as cmd/api, including
+// -config and -set.
+//
+//	admin restore-backup -key backups/2026/10/17/<id>-full.backup [-dry-run]
+//	admin restore-backup -file ./export.backup [-dry-run] [-config .env]
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/diagnosis/interactive-todo/internal/backup"
+	"github.com/diagnosis/interactive-todo/internal/config"
+	store "github.com/diagnosis/interactive-todo/internal/store/database"
+)
+
+func main() {
+	if len(os.Args) < 2 {
+		usage()
+	}
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	var err error
+	switch os.Args[1] {
+	case "restore-backup":
+		err = restoreBackup(ctx, os.Args[2:])
+	default:
+		usage()
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "admin:", err)
+		os.Exit(1)
+	}
+}
+
+func usage() {
+	fmt.Fprintln(os.Stderr, "usage: admin restore-backup (-key OBJECT_KEY | -file PATH) [-dry-run] [-config FILE] [-set KEY=VALUE ...]")
+	os.Exit(2)
+}
+
+// restoreBackup loads an export made by POST /admin/backups. The database
+// must already be migrated to the version the export was taken at.
+func restoreBackup(ctx context.Context, args []string) error {
+	fs := flag.NewFlagSet("restore-backup", flag.ExitOnError)
+	objectKey := fs.String("key", "", "object key of the archive in BACKUP_S3_BUCKET")
+	file := fs.String("file", "", "path of a downloaded archive")
+	dryRun := fs.Bool("dry-run", false, "restore inside a transaction and roll it back")
+	var sources config.Sources
+	sources.RegisterFlags(fs)
+	_ = fs.Parse(args)
+	if err := config.Load(sources); err != nil {
+		return err
+	}
+	if (*objectKey == "") == (*file == "") {
+		return errors.New("exactly one of -key or -file is required")
+	}
+
+	key, err := backup.KeyFromEnv("BACKUP_ENCRYPTION_KEY")
+	if err != nil {
+		return err
+	}
+	if key == nil {
+		return errors.New("BACKUP_ENCRYPTION_KEY is not set")
+	}
+
+	var src io.ReadCloser
+	if *objectKey != "" {
+		cfg, err := backup.ConfigFromEnv()
+		if err != nil {
+			return err
+		}
+		if cfg == nil {
+			return errors.New("BACKUP_S3_BUCKET is not set")
+		}
+		if src, err = cfg.S3.Get(ctx, *objectKey); err != nil {
+			return err
+		}
+	} else if src, err = os.Open(*file); err != nil {
+		return err
+	}
+	defer src.Close()
+
+	archive, err := backup.NewReader(src, key)
+	if err != nil {
+		return err
+	}
+
+	profile, err := config.ProfileFromEnv()
+	if err != nil {
+		return err
+	}
+	dsn := profile.DatabaseURL()
+	if dsn == "" {
+		return errors.New(profile.DatabaseURLVar() + " is not set")
+	}
+	pool, err := store.OpenPool(dsn)
+	if err != nil {
+		return err
+	}
+	defer pool.Close()
+
+	sum, err := backup.Restore(ctx, pool, archive, backup.RestoreOptions{DryRun: *dryRun})
+	if err != nil {
+		return err
+	}
+
+	enc := json.NewEncoder(os.Stdout)
+	enc.SetIndent("", "  ")
+	return enc.Encode(map[string]any{
+		"dry_run": *dryRun,
+		"summary": sum,
+	})
+}
@@ -2,7 +2,7 @@ package main
 
 import (
 	"context"
-	"fmt"
+	"flag"
 	"net/http"
 	"os"
 	"os/signal"
@@ -10,46 +10,146 @@ import (
 	"time"
 
 	"github.com/diagnosis/interactive-todo/internal/app"
+	jwttoken "github.com/diagnosis/interactive-todo/internal/auth/jwt"
+	"github.com/diagnosis/interactive-todo/internal/config"
+	"github.com/diagnosis/interactive-todo/internal/lifecycle"
 	"github.com/diagnosis/interactive-todo/internal/logger"
+	realipmiddleware "github.com/diagnosis/interactive-todo/internal/middleware/realip"
 	routes "github.com/diagnosis/interactive-todo/internal/routes/chi_router"
+	"github.com/diagnosis/interactive-todo/internal/server"
 	store "github.com/diagnosis/interactive-todo/internal/store/database"
 	"github.com/diagnosis/interactive-todo/migrations"
-	_ "github.com/joho/godotenv/autoload"
+	"github.com/jackc/pgx/v5"
 )
 
 func main() {
-	env := os.Getenv("APP_ENV")
+	var sources config.Sources
+	sources.RegisterFlags(flag.CommandLine)
+	validate := flag.Bool("validate-config", false, "check the configuration and the database, print the effective settings with secrets masked, and exit")
+	flag.Parse()
 	ctx := context.Background()
+
+	//defaults < file < env < flags, all settled before anything reads them
+	if err := config.Load(sources); err != nil {
+		logger.Error(ctx, "invalid configuration sources", "error", err)
+		os.Exit(1)
+	}
+
+	if *validate {
+		report := config.Validate(ctx)
+		if err := report.Write(os.Stdout); err != nil || !report.OK() {
+			os.Exit(1)
+		}
+		return
+	}
+
+	logCfg, err := logger.ConfigFromEnv()
+	if err != nil {
+		logger.Error(ctx, "invalid logging configuration", "error", err)
+		os.Exit(1)
+	}
+	logger.Init(logCfg)
 	logger.Info(ctx, "Launching the application...")
 
-	var dsn string
-	if env == "development" {
-		dsn = os.Getenv("DATABASE_URL_DEV")
-	} else {
-		dsn = os.Getenv("DATABASE_URL_PROD")
+	profile, err := config.ProfileFromEnv()
+	if err != nil {
+		logger.Error(ctx, "invalid profile", "error", err)
+		os.Exit(1)
 	}
+	dsn := profile.DatabaseURL()
 	if dsn == "" {
-		logger.Error(ctx, "DATABASE_URL is not set")
+		logger.Error(ctx, profile.DatabaseURLVar()+" is not set", "profile", profile)
+		os.Exit(1)
+	}
+	//fail before touching the database if tokens could not be issued safely
+	jwtCfg, err := jwttoken.ConfigFromEnv()
+	if err != nil {
+		logger.Error(ctx, "invalid JWT configuration", "error", err)
+		os.Exit(1)
+	}
+	tlsCfg, err := server.TLSFromEnv()
+	if err != nil {
+		logger.Error(ctx, "invalid TLS configuration", "error", err)
+		os.Exit(1)
+	}
+	listenCfg, err := server.ListenerFromEnv()
+	if err != nil {
+		logger.Error(ctx, "invalid listener configuration", "error", err)
+		os.Exit(1)
+	}
+	if _, err := realipmiddleware.TrustedFromEnv(); err != nil {
+		logger.Error(ctx, "invalid trusted proxy configuration", "error", err)
+		os.Exit(1)
+	}
+	retryCfg, err := store.RetryConfigFromEnv()
+	if err != nil {
+		logger.Error(ctx, "invalid database retry configuration", "error", err)
+		os.Exit(1)
+	}
+	poolWatch, err := store.PoolWatchFromEnv()
+	if err != nil {
+		logger.Error(ctx, "invalid database pool configuration", "error", err)
 		os.Exit(1)
 	}
-	pool, err := store.OpenPool(dsn)
+	// readiness gate: nothing else starts until the database answers
+	startCtx, stopStart := signal.NotifyContext(ctx, syscall.SIGINT, syscall.SIGTERM)
+	breaker := store.NewBreaker(5, 10*time.Second)
+	tracers := []pgx.QueryTracer{breaker.Tracer(), poolWatch}
+	if store.ExplainFromEnv() {
+		if profile == config.ProfileProd {
+			logger.Warn(ctx, "DB_EXPLAIN is on in production; every new query costs an extra EXPLAIN")
+		}
+		tracers = append(tracers, store.NewExplainTracer())
+	}
+	pool, err := store.OpenPoolWithRetry(startCtx, dsn, retryCfg, tracers...)
+	stopStart()
 	if err != nil {
 		logger.Error(ctx, "failed to connect to database", "error", err)
 		os.Exit(1)
 	}
+	// closing twice is harmless; this covers the exits before the
+	// lifecycle takes the pool over
 	defer pool.Close()
 	logger.Info(ctx, "database connection established!")
 
-	//migrate up
-	if err = store.MigrateFS(dsn, migrations.FS, ""); err != nil {
-		logger.Error(ctx, "failed to migrate", "error", err)
+	//migrations: up (default), plan (list pending and exit) or verify
+	//(refuse to boot unless another process already migrated)
+	switch mode := os.Getenv("MIGRATE_MODE"); mode {
+	case "", "up":
+		if err = store.MigrateFS(dsn, migrations.FS, ""); err != nil {
+			logger.Error(ctx, "failed to migrate", "error", err)
+			os.Exit(1)
+		}
+		if err = store.CheckSchemaVersionFS(dsn, migrations.FS, ""); err != nil {
+			logger.Warn(ctx, "schema version mismatch", "error", err)
+		}
+		logger.Info(ctx, "migration is complete")
+	case "plan":
+		pending, err := store.PlanFS(dsn, migrations.FS, "")
+		if err != nil {
+			logger.Error(ctx, "failed to plan migrations", "error", err)
+			os.Exit(1)
+		}
+		for _, m := range pending {
+			logger.Info(ctx, "pending migration", "version", m.Version, "path", m.Path)
+		}
+		logger.Info(ctx, "migration plan complete", "pending", len(pending))
+		return
+	case "verify":
+		if err = store.CheckSchemaVersionFS(dsn, migrations.FS, ""); err != nil {
+			logger.Error(ctx, "refusing to start", "error", err)
+			os.Exit(1)
+		}
+		logger.Info(ctx, "schema version verified")
+	default:
+		logger.Error(ctx, "MIGRATE_MODE must be up, plan or verify", "mode", mode)
 		os.Exit(1)
 	}
-	logger.Info(ctx, "migration is complete")
 
 	//create application
-	application := app.NewApplication(pool)
+	application := app.NewApplication(pool, breaker, poolWatch, jwtCfg)
 	logger.Info(ctx, "application initialized!")
+
 	//router
 	handler := routes.SetupRouter(application)
 
@@ -57,37 +157,95 @@ func main() {
 	port := os.Getenv("PORT")
 	if port == "" {
 		port = "8080"
+		if tlsCfg != nil {
+			port = "443"
+		}
 	}
 
+	// opened here so a socket that cannot be bound fails startup
+	ln, err := listenCfg.Listen(port)
+	if err != nil {
+		logger.Error(ctx, "failed to listen", "error", err)
+		os.Exit(1)
+	}
 	srv := &http.Server{
-		Addr:         fmt.Sprintf(":%s", port),
 		Handler:      handler,
 		ReadTimeout:  15 * time.Second,
 		WriteTimeout: 15 * time.Second,
 		IdleTimeout:  60 * time.Second,
 	}
+	//https terminated here (HTTP/2 included), plain http only redirects
+	var redirect *http.Server
+	if tlsCfg != nil {
+		srv.TLSConfig = tlsCfg.Config()
+		redirect = tlsCfg.RedirectServer(port)
+	}
 
-	go func() {
-		logger.Info(ctx, "starting server", "port", port)
-		if err = srv.ListenAndServe(); err != nil && err != http.ErrServerClosed {
-			//
-			logger.Error(ctx, "server failed to start", "error", err)
-			os.Exit(1)
-		}
-	}()
-	logger.Info(ctx, "server started successfully", "port", port)
+	//servers start last and stop first, while everything they call is up
+	application.Lifecycle.Append(lifecycle.Hook{
+		Name: "http server",
+		OnStart: func(ctx context.Context) error {
+			go func() {
+				logger.Info(ctx, "starting server", "network", ln.Addr().Network(), "addr", ln.Addr().String(), "tls", tlsCfg != nil)
+				var err error
+				if tlsCfg != nil {
+					err = srv.ServeTLS(ln, "", "")
+				} else {
+					err = srv.Serve(ln)
+				}
+				if err != nil && err != http.ErrServerClosed {
+					logger.Error(ctx, "server failed to start", "error", err)
+					os.Exit(1)
+				}
+			}()
+			return nil
+		},
+		OnStop: func(ctx context.Context) error {
+			return srv.Shutdown(ctx)
+		},
+		Timeout: 30 * time.Second,
+	})
+	if redirect != nil {
+		application.Lifecycle.Append(lifecycle.Hook{
+			Name: "https redirect",
+			OnStart: func(ctx context.Context) error {
+				go func() {
+					logger.Info(ctx, "starting https redirect", "addr", redirect.Addr)
+					if err := redirect.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+						logger.Error(ctx, "https redirect failed to start", "error", err)
+						os.Exit(1)
+					}
+				}()
+				return nil
+			},
+			OnStop: func(ctx context.Context) error {
+				return redirect.Shutdown(ctx)
+			},
+		})
+	}
+
+	//appended after the servers so it stops first: Shutdown would wait for
+	//open streams until its timeout
+	application.Lifecycle.Append(lifecycle.Hook{
+		Name:    "realtime streams",
+		OnStop:  application.Realtime.Drain,
+		Timeout: 10 * time.Second,
+	})
+
+	if err := application.Lifecycle.Start(ctx); err != nil {
+		logger.Error(ctx, "failed to start", "error", err)
+		os.Exit(1)
+	}
+	logger.Info(ctx, "server started successfully", "addr", ln.Addr().String())
 
 	quit := make(chan os.Signal, 1)
 	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
 	<-quit
 
 	logger.Info(ctx, "shutting down server...")
-
-	shutdownCtx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
-	defer cancel()
-
-	if err = srv.Shutdown(shutdownCtx); err != nil {
-		logger.Error(ctx, "server forced to shutdown", "err", err)
+	if err := application.Lifecycle.Stop(ctx); err != nil {
+		logger.Error(ctx, "shutdown was not clean", "err", err)
+		os.Exit(1)
 	}
 	logger.Info(ctx, "server exited gracefully")
 }
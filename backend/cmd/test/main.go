@@ -1,14 +1,22 @@
 package main
 
 import (
+	"flag"
 	"fmt"
 	"os"
 
-	_ "github.com/joho/godotenv/autoload"
+	"github.com/diagnosis/interactive-todo/internal/config"
 )
 
 func main() {
+	var sources config.Sources
+	sources.RegisterFlags(flag.CommandLine)
+	flag.Parse()
+	if err := config.Load(sources); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
 	appEnv := os.Getenv("APP_ENV")
-	fmt.Println(appEnv)
+	fmt.Println(appEnv, config.LayerOf("APP_ENV"))
 
 }
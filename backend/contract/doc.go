@@ -0,0 +1,4 @@
+// Package contract holds the tests that pin the HTTP API's wire format:
+// recorded frontend responses must still decode into api/types, and the
+// Go client must work end to end against the real router.
+package contract
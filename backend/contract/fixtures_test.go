@@ -0,0 +1,77 @@
+package contract
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/diagnosis/interactive-todo/api/types"
+)
+
+// fixtureTypes maps a fixture's "type" to the api/types body it records.
+var fixtureTypes = map[string]func() any{
+	"TokenResponse":    func() any { return new(types.TokenResponse) },
+	"RegisterResponse": func() any { return new(types.RegisterResponse) },
+	"UserListResponse": func() any { return new(types.UserListResponse) },
+	"TaskListResponse": func() any { return new(types.TaskListResponse) },
+	"GetTaskResponse":  func() any { return new(types.GetTaskResponse) },
+	"Task":             func() any { return new(types.Task) },
+	"ErrorResponse":    func() any { return new(types.ErrorResponse) },
+}
+
+type fixture struct {
+	Request  string          `json:"request"`
+	Type     string          `json:"type"`
+	Envelope *bool           `json:"envelope"`
+	Response json.RawMessage `json:"response"`
+}
+
+// TestFrontendFixtures fails when a field the frontend reads is renamed or
+// removed: every key in a recorded response must still exist in api/types.
+// New fields are fine.
+func TestFrontendFixtures(t *testing.T) {
+	paths, err := filepath.Glob(filepath.Join("testdata", "frontend", "*.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(paths) == 0 {
+		t.Fatal("no fixtures found")
+	}
+
+	for _, path := range paths {
+		t.Run(filepath.Base(path), func(t *testing.T) {
+			raw, err := os.ReadFile(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var fx fixture
+			if err := json.Unmarshal(raw, &fx); err != nil {
+				t.Fatalf("parse fixture: %v", err)
+			}
+			newBody, ok := fixtureTypes[fx.Type]
+			if !ok {
+				t.Fatalf("unknown fixture type %q", fx.Type)
+			}
+
+			body := fx.Response
+			if fx.Envelope == nil || *fx.Envelope {
+				var env types.Envelope[json.RawMessage]
+				if err := strictDecode(fx.Response, &env); err != nil {
+					t.Fatalf("%s: envelope: %v", fx.Request, err)
+				}
+				body = env.Data
+			}
+			if err := strictDecode(body, newBody()); err != nil {
+				t.Fatalf("%s: %s no longer matches: %v", fx.Request, fx.Type, err)
+			}
+		})
+	}
+}
+
+func strictDecode(raw []byte, v any) error {
+	dec := json.NewDecoder(bytes.NewReader(raw))
+	dec.DisallowUnknownFields()
+	return dec.Decode(v)
+}
@@ -0,0 +1,201 @@
+package contract
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/diagnosis/interactive-todo/api/types"
+	"github.com/diagnosis/interactive-todo/client"
+	"github.com/diagnosis/interactive-todo/internal/app"
+	jwttoken "github.com/diagnosis/interactive-todo/internal/auth/jwt"
+	routes "github.com/diagnosis/interactive-todo/internal/routes/chi_router"
+	store "github.com/diagnosis/interactive-todo/internal/store/database"
+	"github.com/diagnosis/interactive-todo/migrations"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// TestSDKAgainstRouter runs the client against the real router and stores.
+// It needs a disposable database in CONTRACT_DATABASE_URL; it migrates it
+// and leaves its rows behind. It is skipped without one, except in CI.
+func TestSDKAgainstRouter(t *testing.T) {
+	dsn := os.Getenv("CONTRACT_DATABASE_URL")
+	if dsn == "" {
+		if os.Getenv("CI") != "" {
+			t.Fatal("CONTRACT_DATABASE_URL not set")
+		}
+		t.Skip("CONTRACT_DATABASE_URL not set")
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Minute)
+	defer cancel()
+
+	srv, pool := startServer(t, dsn)
+
+	suffix := randomSuffix(t)
+	password := "contract-" + suffix
+	adminEmail := "contract-admin-" + suffix + "@example.com"
+	memberEmail := "contract-member-" + suffix + "@example.com"
+
+	public := client.New(srv.URL, nil)
+	if _, err := public.Register(ctx, types.RegisterRequest{Email: adminEmail, Password: password}); err != nil {
+		t.Fatalf("register admin: %v", err)
+	}
+	member, err := public.Register(ctx, types.RegisterRequest{Email: memberEmail, Password: password})
+	if err != nil {
+		t.Fatalf("register member: %v", err)
+	}
+	if member.UserType != types.UserTypeEmployee {
+		t.Fatalf("new user type = %q, want employee", member.UserType)
+	}
+	// only admins and task managers create teams
+	if _, err := pool.Exec(ctx, `UPDATE users SET user_type = 'admin' WHERE email = $1`, adminEmail); err != nil {
+		t.Fatal(err)
+	}
+
+	admin := client.New(srv.URL, client.NewPasswordSession(srv.URL, adminEmail, password))
+	memberSess := client.NewPasswordSession(srv.URL, memberEmail, password)
+	mc := client.New(srv.URL, memberSess)
+
+	team, err := admin.CreateTeam(ctx, "contract "+suffix)
+	if err != nil {
+		t.Fatalf("create team: %v", err)
+	}
+	added, err := admin.AddTeamMember(ctx, team.ID, types.AddMemberRequest{UserID: member.UserID, Role: types.TeamRoleMember})
+	if err != nil {
+		t.Fatalf("add member: %v", err)
+	}
+	if added.Member.ID != member.UserID {
+		t.Fatalf("added member = %s, want %s", added.Member.ID, member.UserID)
+	}
+
+	task, err := admin.CreateTask(ctx, types.CreateTaskRequest{
+		TeamID:     team.ID,
+		Title:      "contract task",
+		AssigneeID: &member.UserID,
+		DueAt:      time.Now().Add(48 * time.Hour).UTC(),
+	})
+	if err != nil {
+		t.Fatalf("create task: %v", err)
+	}
+	if task.Status != types.TaskStatusOpen || task.AssigneeID != member.UserID {
+		t.Fatalf("created task = %+v", task)
+	}
+
+	got, err := mc.GetTask(ctx, task.ID)
+	if err != nil {
+		t.Fatalf("get task: %v", err)
+	}
+	if got.ID != task.ID || got.AcknowledgedAt == nil {
+		t.Fatalf("get task = %+v, want acknowledged %s", got, task.ID)
+	}
+
+	updated, pending, err := mc.UpdateTaskStatus(ctx, task.ID, types.TaskStatusInProgress)
+	if err != nil {
+		t.Fatalf("update status: %v", err)
+	}
+	if pending || updated.Status != types.TaskStatusInProgress {
+		t.Fatalf("update status = %+v pending=%v", updated, pending)
+	}
+
+	assigned, err := mc.AssignedTasks(ctx, client.ListOptions{})
+	if err != nil {
+		t.Fatalf("assigned tasks: %v", err)
+	}
+	if !slices.ContainsFunc(assigned, func(x types.Task) bool { return x.ID == task.ID }) {
+		t.Fatalf("assigned tasks missing %s", task.ID)
+	}
+
+	slim, err := admin.TeamTasks(ctx, team.ID, client.ListOptions{Fields: []string{"id", "title"}})
+	if err != nil {
+		t.Fatalf("team tasks with fields: %v", err)
+	}
+	if len(slim) != 1 || slim[0].Title != "contract task" || !slim[0].CreatedAt.IsZero() {
+		t.Fatalf("team tasks with fields = %+v", slim)
+	}
+
+	// the member shares one team with the admin; a page size of 1 makes the
+	// iterator follow next_offset
+	var seen []uuid.UUID
+	for u, err := range mc.Users(ctx, client.ListUsersOptions{Limit: 1}) {
+		if err != nil {
+			t.Fatalf("users: %v", err)
+		}
+		seen = append(seen, u.ID)
+	}
+	if len(seen) != 2 || !slices.Contains(seen, member.UserID) {
+		t.Fatalf("member sees users %v, want self and admin", seen)
+	}
+
+	if err := admin.DeleteTask(ctx, task.ID); err != nil {
+		t.Fatalf("delete task: %v", err)
+	}
+	if _, err := mc.GetTask(ctx, task.ID); !client.IsStatus(err, http.StatusNotFound) {
+		t.Fatalf("get deleted task: err = %v, want 404", err)
+	}
+	trash, err := mc.TeamTrash(ctx, task.TeamID, 0)
+	if err != nil {
+		t.Fatalf("team trash: %v", err)
+	}
+	if len(trash) != 1 || trash[0].ID != task.ID {
+		t.Fatalf("team trash = %v, want the deleted task", trash)
+	}
+	if _, err := admin.RestoreTask(ctx, task.ID); err != nil {
+		t.Fatalf("restore task: %v", err)
+	}
+	if _, err := mc.GetTask(ctx, task.ID); err != nil {
+		t.Fatalf("get restored task: %v", err)
+	}
+
+	if err := mc.LogoutAll(ctx); err != nil {
+		t.Fatalf("logout all: %v", err)
+	}
+	// the session logs in again after its tokens were revoked
+	if _, err := mc.MyTeams(ctx); err != nil {
+		t.Fatalf("my teams after logout-all: %v", err)
+	}
+}
+
+func startServer(t *testing.T, dsn string) (*httptest.Server, *pgxpool.Pool) {
+	t.Helper()
+	if err := store.MigrateFS(dsn, migrations.FS, ""); err != nil {
+		t.Fatalf("migrate: %v", err)
+	}
+	breaker := store.NewBreaker(5, 10*time.Second)
+	poolWatch := store.NewPoolWatch(time.Second)
+	pool, err := store.OpenPool(dsn, breaker.Tracer(), poolWatch)
+	if err != nil {
+		t.Fatalf("open pool: %v", err)
+	}
+	t.Cleanup(pool.Close)
+
+	jwtCfg := &jwttoken.Config{
+		AccessSecret:       randomSuffix(t) + randomSuffix(t),
+		RefreshSecret:      randomSuffix(t) + randomSuffix(t),
+		AccessTokenExpiry:  15 * time.Minute,
+		RefreshTokenExpiry: 24 * time.Hour,
+		Issuer:             "interactive-todo-contract",
+	}
+	if err := jwtCfg.Validate(); err != nil {
+		t.Fatal(err)
+	}
+
+	srv := httptest.NewServer(routes.SetupRouter(app.NewApplication(pool, breaker, poolWatch, jwtCfg)))
+	t.Cleanup(srv.Close)
+	return srv, pool
+}
+
+func randomSuffix(t *testing.T) string {
+	t.Helper()
+	b := make([]byte, 12)
+	if _, err := rand.Read(b); err != nil {
+		t.Fatal(err)
+	}
+	return hex.EncodeToString(b)
+}
@@ -1,18 +1,79 @@
 package app
 
 import (
+	"context"
+	"fmt"
+	"net/url"
 	"os"
+	"strconv"
+	"strings"
 	"time"
 
 	jwttoken "github.com/diagnosis/interactive-todo/internal/auth/jwt"
+	"github.com/diagnosis/interactive-todo/internal/auth/signedurl"
+	"github.com/diagnosis/interactive-todo/internal/automation/runner"
+	"github.com/diagnosis/interactive-todo/internal/backup"
+	"github.com/diagnosis/interactive-todo/internal/demo"
+	"github.com/diagnosis/interactive-todo/internal/directory"
+	"github.com/diagnosis/interactive-todo/internal/embedding"
+	"github.com/diagnosis/interactive-todo/internal/events"
+	adminhandler "github.com/diagnosis/interactive-todo/internal/handler/admin"
 	authhandler "github.com/diagnosis/interactive-todo/internal/handler/auth"
+	realtimehandler "github.com/diagnosis/interactive-todo/internal/handler/realtime"
 	taskhandler "github.com/diagnosis/interactive-todo/internal/handler/task_handler"
 	teamHandler "github.com/diagnosis/interactive-todo/internal/handler/team"
+	"github.com/diagnosis/interactive-todo/internal/jobs"
+	"github.com/diagnosis/interactive-todo/internal/lifecycle"
+	"github.com/diagnosis/interactive-todo/internal/logger"
 	authmiddleware "github.com/diagnosis/interactive-todo/internal/middleware/auth"
+	ipallowmiddleware "github.com/diagnosis/interactive-todo/internal/middleware/ipallow"
+	throttlemiddleware "github.com/diagnosis/interactive-todo/internal/middleware/throttle"
+	usagemiddleware "github.com/diagnosis/interactive-todo/internal/middleware/usage"
+	"github.com/diagnosis/interactive-todo/internal/notify"
+	"github.com/diagnosis/interactive-todo/internal/realtime"
+	"github.com/diagnosis/interactive-todo/internal/secure/fieldcrypt"
+	"github.com/diagnosis/interactive-todo/internal/secure/spamguard"
+	"github.com/diagnosis/interactive-todo/internal/storage"
+	approvalstore "github.com/diagnosis/interactive-todo/internal/store/approvals"
+	assignmentstore "github.com/diagnosis/interactive-todo/internal/store/assignment"
+	attachmentstore "github.com/diagnosis/interactive-todo/internal/store/attachments"
+	auditstore "github.com/diagnosis/interactive-todo/internal/store/audit"
+	automationstore "github.com/diagnosis/interactive-todo/internal/store/automations"
+	backupstore "github.com/diagnosis/interactive-todo/internal/store/backups"
+	calendarfeedstore "github.com/diagnosis/interactive-todo/internal/store/calendarfeeds"
+	calendarstore "github.com/diagnosis/interactive-todo/internal/store/calendars"
+	commentstore "github.com/diagnosis/interactive-todo/internal/store/comments"
+	customfieldstore "github.com/diagnosis/interactive-todo/internal/store/customfields"
+	dbstore "github.com/diagnosis/interactive-todo/internal/store/database"
+	delegationstore "github.com/diagnosis/interactive-todo/internal/store/delegations"
+	directorystore "github.com/diagnosis/interactive-todo/internal/store/directory"
+	editlockstore "github.com/diagnosis/interactive-todo/internal/store/editlocks"
+	formstore "github.com/diagnosis/interactive-todo/internal/store/forms"
+	invitationstore "github.com/diagnosis/interactive-todo/internal/store/invitations"
+	allowliststore "github.com/diagnosis/interactive-todo/internal/store/ipallowlist"
+	labelrulestore "github.com/diagnosis/interactive-todo/internal/store/labelrules"
+	labelstore "github.com/diagnosis/interactive-todo/internal/store/labels"
+	legalholdstore "github.com/diagnosis/interactive-todo/internal/store/legalholds"
+	metricsstore "github.com/diagnosis/interactive-todo/internal/store/metrics"
+	mutestore "github.com/diagnosis/interactive-todo/internal/store/mutes"
+	outofficestore "github.com/diagnosis/interactive-todo/internal/store/outofoffice"
+	projectstore "github.com/diagnosis/interactive-todo/internal/store/projects"
+	provisioningstore "github.com/diagnosis/interactive-todo/internal/store/provisioning"
 	refreshtoken "github.com/diagnosis/interactive-todo/internal/store/refresh_tokens"
+	reminderstore "github.com/diagnosis/interactive-todo/internal/store/reminders"
+	reportstore "github.com/diagnosis/interactive-todo/internal/store/reports"
+	rotationstore "github.com/diagnosis/interactive-todo/internal/store/rotations"
+	setupstore "github.com/diagnosis/interactive-todo/internal/store/setup"
+	snapshotstore "github.com/diagnosis/interactive-todo/internal/store/snapshots"
 	taskstore "github.com/diagnosis/interactive-todo/internal/store/tasks"
+	teameventstore "github.com/diagnosis/interactive-todo/internal/store/teamevents"
 	teamstore "github.com/diagnosis/interactive-todo/internal/store/teams"
+	denyliststore "github.com/diagnosis/interactive-todo/internal/store/tokendenylist"
+	triagestore "github.com/diagnosis/interactive-todo/internal/store/triage"
+	usagestore "github.com/diagnosis/interactive-todo/internal/store/usage"
 	userstore "github.com/diagnosis/interactive-todo/internal/store/users"
+	replaystore "github.com/diagnosis/interactive-todo/internal/store/webhookreplay"
+	workflowstore "github.com/diagnosis/interactive-todo/internal/store/workflows"
 	"github.com/jackc/pgx/v5/pgxpool"
 )
 
@@ -22,60 +83,344 @@ type Application struct {
 	TaskStore         taskstore.TaskStore
 	RefreshTokenStore refreshtoken.RefreshTokenStore
 	TeamStore         teamstore.TeamStore
+	AuditStore        auditstore.AuditStore
+	MuteStore         mutestore.MuteStore
+	// WebhookReplay backs webhooksig.NewVerifier for integration endpoints
+	WebhookReplay replaystore.ReplayStore
 	//Auth
 	JWTManager     jwttoken.TokenManager
 	AuthMiddleware *authmiddleware.AuthMiddleware
+	IPAllowlist    *ipallowmiddleware.IPAllowlist
+	// Usage counts authenticated requests per user; flushed by a job
+	Usage *usagemiddleware.Tracker
+	// Throttle charges authenticated requests against per-user buckets
+	Throttle *throttlemiddleware.Limiter
+
+	//Database
+	Pool      *pgxpool.Pool
+	DBBreaker *dbstore.Breaker
 
 	//handler
-	AuthHandler *authhandler.AuthHandler
-	TaskHandler *taskhandler.TaskHandler
-	TeamHandler *teamHandler.TeamHandler
+	AuthHandler  *authhandler.AuthHandler
+	TaskHandler  *taskhandler.TaskHandler
+	TeamHandler  *teamHandler.TeamHandler
+	AdminHandler *adminhandler.AdminHandler
+	// RealtimeHandler streams team events; Realtime is its hub
+	RealtimeHandler *realtimehandler.RealtimeHandler
+	Realtime        *realtime.Hub
+
+	//Background
+	Notifier  notify.Notifier
+	Scheduler *jobs.Scheduler
+	// Events carries task changes to automations
+	Events *events.Bus
+	// Lifecycle starts and stops the subsystems above in dependency order;
+	// main appends the servers and the realtime drain last and runs it
+	Lifecycle *lifecycle.Manager
 	//Config
 	JWTConfig *jwttoken.Config
+	// Demo is set when DEMO_MODE is on; responses carry its banner headers
+	Demo *demo.Sandbox
+	// LegacyAPISunset is when the unprefixed (pre-/v1) paths stop being
+	// served; zero if not scheduled.
+	LegacyAPISunset time.Time
 }
 
-func NewApplication(pool *pgxpool.Pool) *Application {
-	accessSecret := os.Getenv("JWT_ACCESS_SECRET")
-	refreshSecret := os.Getenv("JWT_REFRESH_SECRET")
+// NewApplication wires stores, handlers and jobs. jwtConfig must already be
+// validated (see jwttoken.ConfigFromEnv). poolWatch, when set, must be one of
+// the pool's tracers.
+func NewApplication(pool *pgxpool.Pool, breaker *dbstore.Breaker, poolWatch *dbstore.PoolWatch, jwtConfig *jwttoken.Config) *Application {
+	//create jwt manager
+	jwtManager := jwttoken.NewJWTManager(jwtConfig)
+
+	//field encryption for confidential teams (optional)
+	fieldCipher, err := fieldcrypt.FromEnv("FIELD_ENCRYPTION_KEY")
+	if err != nil {
+		panic("FIELD_ENCRYPTION_KEY is invalid: " + err.Error())
+	}
 
-	// ✅ Validate secrets are set
-	if accessSecret == "" || refreshSecret == "" {
-		panic("JWT_ACCESS_SECRET and JWT_REFRESH_SECRET must be set in environment")
+	//finished tasks move to tasks_archive after this many months (0 = never)
+	archiveAfterMonths := 12
+	if v := os.Getenv("TASK_ARCHIVE_AFTER_MONTHS"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 0 {
+			panic("TASK_ARCHIVE_AFTER_MONTHS must be a non-negative integer")
+		}
+		archiveAfterMonths = n
 	}
-	//jwt config
-	jwtConfig := &jwttoken.Config{
-		AccessSecret:       accessSecret,
-		RefreshSecret:      refreshSecret,
-		AccessTokenExpiry:  15 * time.Minute,
-		RefreshTokenExpiry: 7 * 24 * time.Hour,
-		Issuer:             "interactive-todo",
+
+	//encrypted exports to S3 (optional)
+	backupCfg, err := backup.ConfigFromEnv()
+	if err != nil {
+		panic("backups: " + err.Error())
+	}
+
+	//files on tasks, on disk or S3
+	attachmentFiles, attachmentPrefix, err := storage.AttachmentsFromEnv()
+	if err != nil {
+		panic("attachments: " + err.Error())
+	}
+	attachmentMaxMB := 25
+	if v := os.Getenv("ATTACHMENT_MAX_MB"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 {
+			panic("ATTACHMENT_MAX_MB must be a positive integer")
+		}
+		attachmentMaxMB = n
+	}
+
+	//team membership from Google Workspace / Azure AD groups (optional)
+	directoryProviders, err := directory.ProvidersFromEnv()
+	if err != nil {
+		panic("directory sync: " + err.Error())
+	}
+
+	//per-user request budget, heavier endpoints cost more (nil = off)
+	throttleCfg, err := throttlemiddleware.ConfigFromEnv()
+	if err != nil {
+		panic(err.Error())
+	}
+
+	//unprefixed routes answer 410 from this date on (empty = keep serving)
+	var legacyAPISunset time.Time
+	if v := os.Getenv("API_LEGACY_SUNSET"); v != "" {
+		t, err := time.Parse(time.DateOnly, v)
+		if err != nil {
+			panic("API_LEGACY_SUNSET must be a date like 2027-04-01")
+		}
+		legacyAPISunset = t
+	}
+
+	//absolute links in mail, like status report unsubscribe links (empty = no such mail)
+	publicBaseURL := strings.TrimSuffix(os.Getenv("PUBLIC_BASE_URL"), "/")
+	if publicBaseURL != "" {
+		u, err := url.Parse(publicBaseURL)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			panic("PUBLIC_BASE_URL must be an absolute URL like https://todo.example.com")
+		}
 	}
-	//create jwt manager
-	jwtManager := jwttoken.NewJWTManager(jwtConfig)
 
 	//create store
 	userStore := userstore.NewPGUserStore(pool)
-	taskStore := taskstore.NewPGTaskStore(pool)
+	taskStore := taskstore.NewPGTaskStore(pool, fieldCipher)
 	refreshTokenStore := refreshtoken.NewPGRefreshTokenStore(pool)
+	denylistStore := denyliststore.NewPGDenylistStore(pool)
+	webhookReplayStore := replaystore.NewPGReplayStore(pool)
+	calendarStore := calendarstore.NewPGCalendarStore(pool)
+	labelStore := labelstore.NewPGLabelStore(pool)
+	projectStore := projectstore.NewPGProjectStore(pool)
+	customFieldStore := customfieldstore.NewPGCustomFieldStore(pool)
+	reportStore := reportstore.NewPGReportStore(pool)
+	delegationStore := delegationstore.NewPGDelegationStore(pool)
+	outOfOfficeStore := outofficestore.NewPGOutOfOfficeStore(pool)
+	calendarFeedStore := calendarfeedstore.NewPGCalendarFeedStore(pool)
+	rotationStore := rotationstore.NewPGRotationStore(pool)
+	reminderStore := reminderstore.NewPGReminderStore(pool)
+	labelRuleStore := labelrulestore.NewPGLabelRuleStore(pool)
+	assignmentStore := assignmentstore.NewPGAssignmentStore(pool)
+	recipeStore := automationstore.NewPGRecipeStore(pool)
+	commentStore := commentstore.NewPGCommentStore(pool)
 	teamStore := teamstore.NewPGTeamStore(pool)
+	auditStore := auditstore.NewPGAuditStore(pool)
+	muteStore := mutestore.NewPGMuteStore(pool)
+	metricsStore := metricsstore.NewPGMetricsStore(pool)
+	approvalStore := approvalstore.NewPGApprovalStore(pool)
+	workflowStore := workflowstore.NewPGWorkflowStore(pool)
+	formStore := formstore.NewPGFormStore(pool)
+	triageStore := triagestore.NewPGTriageStore(pool)
+	legalHoldStore := legalholdstore.NewPGLegalHoldStore(pool)
+	ipAllowlistStore := allowliststore.NewPGIPAllowlistStore(pool)
+	usageStore := usagestore.NewPGUsageStore(pool)
+	snapshotStore := snapshotstore.NewPGSnapshotStore(pool, fieldCipher)
+	backupStore := backupstore.NewPGBackupStore(pool)
+	attachmentStore := attachmentstore.NewPGAttachmentStore(pool)
+	invitationStore := invitationstore.NewPGInvitationStore(pool)
+	directoryStore := directorystore.NewPGDirectoryStore(pool)
+	setupStore := setupstore.NewPGSetupStore(pool)
+	provisioningStore := provisioningstore.NewPGProvisioningStore(pool)
+
+	//public demo with fake data, wiped hourly (optional)
+	demoCfg, err := demo.ConfigFromEnv()
+	if err != nil {
+		panic(err.Error())
+	}
+	var sandbox *demo.Sandbox
+	if demoCfg != nil {
+		sandbox = demo.NewSandbox(pool, *demoCfg)
+		ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+		err := sandbox.Reset(ctx, time.Now())
+		cancel()
+		if err != nil {
+			panic("demo mode: " + err.Error())
+		}
+	}
+
+	//semantic search (optional); needs migrations/optional/task_embeddings.sql
+	embedder, err := embedding.FromEnv()
+	if err != nil {
+		panic("embeddings: " + err.Error())
+	}
+	if embedder != nil {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		dims, err := taskStore.EmbeddingDimensions(ctx)
+		cancel()
+		switch {
+		case err != nil:
+			panic("embeddings: " + err.Error())
+		case dims == 0:
+			panic("EMBEDDINGS_PROVIDER is set but task_embeddings does not exist; apply migrations/optional/task_embeddings.sql")
+		case dims != embedder.Dimensions():
+			panic(fmt.Sprintf("task_embeddings holds %d-dimension vectors but EMBEDDINGS_DIMENSIONS is %d", dims, embedder.Dimensions()))
+		}
+	}
+
+	//create notifier
+	notifier := notify.NewLogNotifier()
+
+	//create event bus; automation recipes run off it
+	eventBus := events.NewBus(1024)
+	runner.New(recipeStore, taskStore, teamStore, commentStore, notifier, eventBus).Register(eventBus)
+	realtimeHub := realtime.NewHub()
+	teamEventStore := teameventstore.NewPGTeamEventStore(pool)
+	editLockStore := editlockstore.NewPGEditLockStore(pool)
+	realtimeBridge := realtime.NewBridge(realtimeHub, taskStore, teamEventStore)
+	realtimeBridge.Register(eventBus)
+
+	directorySyncer := directory.NewSyncer(directoryStore, directoryProviders)
+
+	//create guards
+	spamGuard := spamguard.NewGuard(spamguard.DefaultConfig(), taskStore, commentStore, muteStore, auditStore)
 
 	//create middleware
-	authMiddleware := authmiddleware.NewAuthMiddleware(jwtManager)
+	urlSigner := signedurl.NewSigner(jwtConfig.AccessSecret)
+	authMiddleware := authmiddleware.NewAuthMiddleware(jwtManager, urlSigner, denylistStore)
+	ipAllowlist := ipallowmiddleware.NewIPAllowlist(ipAllowlistStore, auditStore, os.Getenv("BREAK_GLASS_TOKEN"))
+	usageTracker := usagemiddleware.NewTracker(usageStore)
 
 	//create handlers
-	authHandler := authhandler.NewAuthHandler(userStore, refreshTokenStore, jwtManager)
-	taskHandler := taskhandler.NewTaskHandler(taskStore, teamStore)
-	teamHandler := teamHandler.NewTeamHandler(teamStore, userStore)
+	authHandler := authhandler.NewAuthHandler(userStore, refreshTokenStore, jwtManager, denylistStore, invitationStore, setupStore, sandbox)
+	taskHandler := taskhandler.NewTaskHandler(taskStore, teamStore, userStore, approvalStore, workflowStore, formStore, triageStore, auditStore, calendarStore, labelStore, labelRuleStore, assignmentStore, commentStore, customFieldStore, reportStore, delegationStore, outOfOfficeStore, calendarFeedStore, rotationStore, reminderStore, eventBus, embedder, spamGuard, urlSigner, taskhandler.Attachments{
+		Store:    attachmentStore,
+		Files:    attachmentFiles,
+		Prefix:   attachmentPrefix,
+		MaxBytes: int64(attachmentMaxMB) << 20,
+	})
+	teamHandler := teamHandler.NewTeamHandler(teamStore, userStore, workflowStore, formStore, calendarStore, labelStore, labelRuleStore, assignmentStore, recipeStore, snapshotStore, invitationStore, directoryStore, directorySyncer, projectStore, customFieldStore, rotationStore, fieldCipher != nil)
+	realtimeHandler := realtimehandler.NewRealtimeHandler(teamStore, taskStore, teamEventStore, editLockStore, realtimeHub, realtimeBridge)
+	adminHandler := adminhandler.NewAdminHandler(userStore, muteStore, auditStore, metricsStore, legalHoldStore, ipAllowlistStore, taskStore, usageStore, backupStore, provisioningStore, eventBus, breaker, pool, poolWatch, backupCfg != nil)
+
+	//background jobs
+	scheduler := jobs.NewScheduler()
+	scheduler.Register(jobs.NewStaleNudgeJob(taskStore, teamStore, calendarStore, notifier), time.Hour)
+	scheduler.Register(jobs.NewAckNudgeJob(taskStore, teamStore, calendarStore, notifier), 15*time.Minute)
+	scheduler.Register(jobs.NewDueReminderJob(reminderStore, userStore, notifier, eventBus), time.Minute)
+	scheduler.Register(jobs.NewRotateOnCallJob(rotationStore), 5*time.Minute)
+	scheduler.Register(jobs.NewViewLogRetentionJob(auditStore, jobs.TaskViewRetention), 24*time.Hour)
+	scheduler.Register(jobs.NewReconcileTaskCountersJob(taskStore), 6*time.Hour)
+	scheduler.Register(jobs.NewRebalanceTaskPositionsJob(taskStore), time.Hour)
+	scheduler.Register(jobs.NewPurgeTokenDenylistJob(denylistStore), time.Hour)
+	scheduler.Register(jobs.NewPurgeWebhookReplayJob(webhookReplayStore), time.Hour)
+	scheduler.Register(jobs.NewPruneTeamEventsJob(teamEventStore), time.Hour)
+	scheduler.Register(jobs.NewExpireEditLocksJob(editLockStore), time.Hour)
+	scheduler.Register(jobs.NewExpireDelegationsJob(delegationStore, auditStore), 15*time.Minute)
+	scheduler.Register(jobs.NewAutomationDueSoonJob(recipeStore, eventBus), 15*time.Minute)
+	scheduler.Register(jobs.NewFlushAPIUsageJob(usageTracker), time.Minute)
+	scheduler.Register(jobs.NewAPIUsageRetentionJob(usageStore, jobs.APIUsageRetention), 24*time.Hour)
+	scheduler.Register(jobs.NewPurgeAttachmentsJob(attachmentStore, attachmentFiles), 10*time.Minute)
+	scheduler.Register(jobs.NewPurgeTrashJob(taskStore, taskstore.TrashRetention), time.Hour)
+	if fieldCipher != nil {
+		scheduler.Register(jobs.NewSealDescriptionsJob(taskStore), 10*time.Minute)
+	}
+	if embedder != nil {
+		scheduler.Register(jobs.NewEmbedTasksJob(taskStore, embedder), 5*time.Minute)
+	}
+	if backupCfg != nil {
+		scheduler.Register(jobs.NewBackupExportsJob(backupStore, pool, backupCfg), time.Minute)
+	}
+	if len(directoryProviders) > 0 {
+		scheduler.Register(jobs.NewDirectorySyncJob(directoryStore, directorySyncer), time.Hour)
+	}
+	if publicBaseURL != "" {
+		scheduler.Register(jobs.NewStatusReportJob(reportStore, notifier, publicBaseURL), time.Hour)
+	} else {
+		logger.Warn(context.Background(), "PUBLIC_BASE_URL is not set; weekly status reports are not sent, their unsubscribe links would not work")
+	}
+	if archiveAfterMonths > 0 {
+		scheduler.Register(jobs.NewArchiveTasksJob(taskStore, archiveAfterMonths), 24*time.Hour)
+	}
+	if sandbox != nil {
+		scheduler.Register(jobs.NewDemoResetJob(sandbox), time.Minute)
+	}
+
+	//started in this order, stopped in reverse: the pool outlives the
+	//usage flush, the bus outlives the jobs that publish to it
+	lc := lifecycle.New()
+	lc.Append(lifecycle.Hook{
+		Name: "database pool",
+		OnStop: func(context.Context) error {
+			pool.Close()
+			return nil
+		},
+	})
+	lc.Append(lifecycle.Hook{
+		Name: "api usage",
+		OnStop: func(ctx context.Context) error {
+			//counts since the last flush
+			_, err := usageTracker.Flush(ctx)
+			return err
+		},
+	})
+	lc.Append(lifecycle.Hook{
+		Name: "event bus",
+		OnStart: func(ctx context.Context) error {
+			eventBus.Start(ctx)
+			return nil
+		},
+		OnStop: func(context.Context) error {
+			eventBus.Stop()
+			return nil
+		},
+	})
+	lc.Append(lifecycle.Hook{
+		Name: "scheduler",
+		OnStart: func(ctx context.Context) error {
+			scheduler.Start(ctx)
+			return nil
+		},
+		OnStop: func(context.Context) error {
+			scheduler.Stop()
+			return nil
+		},
+		// a job mid-run gets this long to notice the cancel
+		Timeout: 30 * time.Second,
+	})
 
 	return &Application{
 		UserStore:         userStore,
 		TaskStore:         taskStore,
 		RefreshTokenStore: refreshTokenStore,
+		AuditStore:        auditStore,
+		MuteStore:         muteStore,
+		WebhookReplay:     webhookReplayStore,
 		JWTManager:        jwtManager,
 		AuthMiddleware:    authMiddleware,
+		IPAllowlist:       ipAllowlist,
+		Usage:             usageTracker,
+		Throttle:          throttlemiddleware.NewLimiter(throttleCfg),
+		Pool:              pool,
+		DBBreaker:         breaker,
 		AuthHandler:       authHandler,
 		TaskHandler:       taskHandler,
 		TeamHandler:       teamHandler,
+		AdminHandler:      adminHandler,
+		RealtimeHandler:   realtimeHandler,
+		Realtime:          realtimeHub,
+		Notifier:          notifier,
+		Scheduler:         scheduler,
+		Events:            eventBus,
+		Lifecycle:         lc,
 		JWTConfig:         jwtConfig,
+		Demo:              sandbox,
+		LegacyAPISunset:   legacyAPISunset,
 	}
 }
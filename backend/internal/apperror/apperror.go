@@ -1,6 +1,7 @@
 package apperror
 
 import (
+	"context"
 	"errors"
 	"fmt"
 )
@@ -13,8 +14,14 @@ const (
 	CodeForbidden          ErrorCode = "FORBIDDEN"
 	CodeNotFound           ErrorCode = "NOT_FOUND"
 	CodeConflict           ErrorCode = "CONFLICT"
+	CodeGone               ErrorCode = "GONE"
 	CodeTooManyRequests    ErrorCode = "TOO_MANY_REQUESTS"
+	CodePayloadTooLarge    ErrorCode = "PAYLOAD_TOO_LARGE"
+	CodeUnsupportedMedia   ErrorCode = "UNSUPPORTED_MEDIA_TYPE"
 	CodeInternalError      ErrorCode = "INTERNAL_ERROR"
+	CodeUnavailable        ErrorCode = "SERVICE_UNAVAILABLE"
+	CodeBadGateway         ErrorCode = "BAD_GATEWAY"
+	CodeTimeout            ErrorCode = "TIMEOUT"
 	CodeDatabaseError      ErrorCode = "DATABASE_ERROR"
 	CodeValidationError    ErrorCode = "VALIDATION_ERROR"
 	CodeTokenError         ErrorCode = "TOKEN_ERROR"
@@ -74,14 +81,39 @@ func Conflict(message string) *AppError {
 	return New(CodeConflict, message, 409)
 }
 
+func Gone(message string) *AppError {
+	return New(CodeGone, message, 410)
+}
+
 func TooManyRequests(message string) *AppError {
 	return New(CodeTooManyRequests, message, 429)
 }
 
+func PayloadTooLarge(message string) *AppError {
+	return New(CodePayloadTooLarge, message, 413)
+}
+
+func UnsupportedMediaType(message string) *AppError {
+	return New(CodeUnsupportedMedia, message, 415)
+}
+
 func InternalError(message string, err error) *AppError {
 	return Wrap(CodeInternalError, message, 500, err)
 }
 
+func ServiceUnavailable(message string) *AppError {
+	return New(CodeUnavailable, message, 503)
+}
+
+// BadGateway is for failures of a service the request depends on.
+func BadGateway(message string) *AppError {
+	return New(CodeBadGateway, message, 502)
+}
+
+func Timeout(err error) *AppError {
+	return Wrap(CodeTimeout, "request timed out", 504, err)
+}
+
 func InvalidCredentials() *AppError {
 	return New(CodeInvalidCredentials, "Invalid email or password", 401)
 }
@@ -93,10 +125,19 @@ func AccountInactive() *AppError {
 func EmailAlreadyExists() *AppError {
 	return New(CodeEmailExists, "Email address already registered", 409)
 }
+
+// AsAppError maps err to an AppError. Internal errors caused by a deadline
+// become 504 so clients can tell a slow request from a failed one.
 func AsAppError(err error) *AppError {
 	var appError *AppError
 	if errors.As(err, &appError) {
+		if appError.HTTPStatus == 500 && errors.Is(err, context.DeadlineExceeded) {
+			return Timeout(appError.Err)
+		}
 		return appError
 	}
+	if errors.Is(err, context.DeadlineExceeded) {
+		return Timeout(err)
+	}
 	return InternalError("unexpected error", err)
 }
@@ -0,0 +1,122 @@
+package auth
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"os"
+	"strings"
+	"time"
+)
+
+const (
+	minSecretLen = 32
+	// Shannon estimate over the whole secret; a random 32-char base64
+	// string scores well above this, "aaaa..." or a repeated word does not
+	minSecretBits = 128
+
+	defaultAccessExpiry  = 15 * time.Minute
+	defaultRefreshExpiry = 7 * 24 * time.Hour
+	maxAccessExpiry      = 24 * time.Hour
+)
+
+// ConfigFromEnv reads JWT_ACCESS_SECRET, JWT_REFRESH_SECRET and the
+// optional JWT_ISSUER, JWT_ACCESS_TTL and JWT_REFRESH_TTL (Go durations)
+// and validates the result.
+func ConfigFromEnv() (*Config, error) {
+	cfg := &Config{
+		AccessSecret:       os.Getenv("JWT_ACCESS_SECRET"),
+		RefreshSecret:      os.Getenv("JWT_REFRESH_SECRET"),
+		AccessTokenExpiry:  defaultAccessExpiry,
+		RefreshTokenExpiry: defaultRefreshExpiry,
+		Issuer:             "interactive-todo",
+	}
+	if v := strings.TrimSpace(os.Getenv("JWT_ISSUER")); v != "" {
+		cfg.Issuer = v
+	}
+
+	var errs []error
+	if v := os.Getenv("JWT_ACCESS_TTL"); v != "" {
+		d, err := time.ParseDuration(v)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("JWT_ACCESS_TTL: %q is not a duration like 15m", v))
+		}
+		cfg.AccessTokenExpiry = d
+	}
+	if v := os.Getenv("JWT_REFRESH_TTL"); v != "" {
+		d, err := time.ParseDuration(v)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("JWT_REFRESH_TTL: %q is not a duration like 168h", v))
+		}
+		cfg.RefreshTokenExpiry = d
+	}
+	if len(errs) > 0 {
+		return nil, errors.Join(errs...)
+	}
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+	return cfg, nil
+}
+
+// Validate reports every problem at once so a misconfigured deployment can
+// be fixed in one pass.
+func (c *Config) Validate() error {
+	var errs []error
+
+	errs = append(errs, checkSecret("JWT_ACCESS_SECRET", c.AccessSecret)...)
+	errs = append(errs, checkSecret("JWT_REFRESH_SECRET", c.RefreshSecret)...)
+	if c.AccessSecret != "" && c.AccessSecret == c.RefreshSecret {
+		errs = append(errs, errors.New("JWT_ACCESS_SECRET and JWT_REFRESH_SECRET must differ, or a refresh token would pass as an access token"))
+	}
+
+	if c.AccessTokenExpiry <= 0 {
+		errs = append(errs, errors.New("access token expiry must be positive"))
+	} else if c.AccessTokenExpiry > maxAccessExpiry {
+		errs = append(errs, fmt.Errorf("access token expiry %s is longer than %s; keep access tokens short-lived", c.AccessTokenExpiry, maxAccessExpiry))
+	}
+	if c.RefreshTokenExpiry <= c.AccessTokenExpiry {
+		errs = append(errs, fmt.Errorf("refresh token expiry (%s) must be longer than access token expiry (%s)", c.RefreshTokenExpiry, c.AccessTokenExpiry))
+	}
+	if strings.TrimSpace(c.Issuer) == "" {
+		errs = append(errs, errors.New("issuer must be set"))
+	}
+
+	return errors.Join(errs...)
+}
+
+// CheckSecret applies the signing secret rules to any other shared secret,
+// such as the break-glass token.
+func CheckSecret(name, secret string) error {
+	return errors.Join(checkSecret(name, secret)...)
+}
+
+func checkSecret(name, secret string) []error {
+	const hint = "generate one with: openssl rand -base64 48"
+	switch {
+	case secret == "":
+		return []error{fmt.Errorf("%s is not set; %s", name, hint)}
+	case len(secret) < minSecretLen:
+		return []error{fmt.Errorf("%s is %d characters, need at least %d; %s", name, len(secret), minSecretLen, hint)}
+	case entropyBits(secret) < minSecretBits:
+		return []error{fmt.Errorf("%s is too predictable (repeated or few distinct characters); %s", name, hint)}
+	}
+	return nil
+}
+
+// entropyBits estimates the total Shannon entropy of s from its own
+// character frequencies.
+func entropyBits(s string) float64 {
+	counts := make(map[rune]int)
+	n := 0
+	for _, r := range s {
+		counts[r]++
+		n++
+	}
+	var perChar float64
+	for _, c := range counts {
+		p := float64(c) / float64(n)
+		perChar -= p * math.Log2(p)
+	}
+	return perChar * float64(n)
+}
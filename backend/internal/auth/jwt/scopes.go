@@ -0,0 +1,61 @@
+package auth
+
+import (
+	"slices"
+
+	store "github.com/diagnosis/interactive-todo/internal/store/users"
+)
+
+// Scope is a permission carried by an access token. Route groups require
+// scopes (see middleware.RequireScope); handlers still do their own
+// membership and role checks on top.
+type Scope string
+
+const (
+	ScopeTasksRead  Scope = "tasks:read"
+	ScopeTasksWrite Scope = "tasks:write"
+	ScopeTeamsRead  Scope = "teams:read"
+	ScopeTeamsWrite Scope = "teams:write"
+	ScopeUsersRead  Scope = "users:read"
+	ScopeAdmin      Scope = "admin"
+)
+
+// Audiences an access token may be issued for. Browser sessions get
+// AudienceFrontend; AudienceAPI is for machine tokens with narrowed scopes.
+const (
+	AudienceFrontend = "interactive todo frontend"
+	AudienceAPI      = "interactive todo api"
+)
+
+var memberScopes = []Scope{
+	ScopeTasksRead, ScopeTasksWrite,
+	ScopeTeamsRead, ScopeTeamsWrite,
+	ScopeUsersRead,
+}
+
+// ScopesForUserType is the full set a user of that type may hold. A token
+// never carries a scope outside this set.
+func ScopesForUserType(t store.UserType) []Scope {
+	out := slices.Clone(memberScopes)
+	if t == store.TypeAdmin {
+		out = append(out, ScopeAdmin)
+	}
+	return out
+}
+
+// NarrowScopes keeps the requested scopes the user type allows, in the
+// order requested. Unknown scopes are dropped.
+func NarrowScopes(t store.UserType, requested []Scope) []Scope {
+	allowed := ScopesForUserType(t)
+	var out []Scope
+	for _, s := range requested {
+		if slices.Contains(allowed, s) && !slices.Contains(out, s) {
+			out = append(out, s)
+		}
+	}
+	return out
+}
+
+func (c *Claims) HasScope(s Scope) bool {
+	return slices.Contains(c.Scopes, s)
+}
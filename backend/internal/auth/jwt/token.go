@@ -14,6 +14,10 @@ type Claims struct {
 	UserID   uuid.UUID      `json:"user_id"`
 	Email    string         `json:"email"`
 	UserType store.UserType `json:"user_type"`
+	// Scopes is only set on access tokens
+	Scopes []Scope `json:"scopes,omitempty"`
+	// TokenVersion is users.token_version at mint time; access tokens only
+	TokenVersion int `json:"ver,omitempty"`
 	jwt.RegisteredClaims
 }
 
@@ -29,12 +33,18 @@ type Config struct {
 // TokenManager handles JWT operations
 type TokenManager interface {
 	// Generate refresh_tokens (only return the token string)
-	MintAccessToken(userID uuid.UUID, email string, userType store.UserType) (string, error)
+	MintAccessToken(userID uuid.UUID, email string, userType store.UserType, tokenVersion int) (string, error)
+	// MintScopedAccessToken issues a machine token (AudienceAPI) limited to
+	// scopes, narrowed to what userType allows
+	MintScopedAccessToken(userID uuid.UUID, email string, userType store.UserType, tokenVersion int, scopes []Scope) (string, error)
 	MintRefreshToken(userID uuid.UUID) (string, error)
 
 	// Validate refresh_tokens (return claims if valid)
 	ValidateAccessToken(tok string) (*Claims, error)
 	ValidateRefreshToken(tok string) (*Claims, error)
+
+	// AccessTokenTTL is how long a freshly minted access token lives.
+	AccessTokenTTL() time.Duration
 }
 
 type JWTManager struct {
@@ -44,11 +54,25 @@ type JWTManager struct {
 func NewJWTManager(cfg *Config) *JWTManager {
 	return &JWTManager{config: cfg}
 }
-func (m *JWTManager) MintAccessToken(userID uuid.UUID, email string, userType store.UserType) (string, error) {
+func (m *JWTManager) MintAccessToken(userID uuid.UUID, email string, userType store.UserType, tokenVersion int) (string, error) {
+	return m.mintAccessToken(userID, email, userType, tokenVersion, ScopesForUserType(userType), AudienceFrontend)
+}
+
+func (m *JWTManager) MintScopedAccessToken(userID uuid.UUID, email string, userType store.UserType, tokenVersion int, scopes []Scope) (string, error) {
+	narrowed := NarrowScopes(userType, scopes)
+	if len(narrowed) == 0 {
+		return "", errors.New("no permitted scopes requested")
+	}
+	return m.mintAccessToken(userID, email, userType, tokenVersion, narrowed, AudienceAPI)
+}
+
+func (m *JWTManager) mintAccessToken(userID uuid.UUID, email string, userType store.UserType, tokenVersion int, scopes []Scope, audience string) (string, error) {
 	now := time.Now().UTC()
 	regClaims := jwt.RegisteredClaims{
+		// jti lets a single access token be denylisted on logout
+		ID:       uuid.New().String(),
 		Issuer:   m.config.Issuer,
-		Audience: []string{"interactive todo frontend"},
+		Audience: []string{audience},
 		Subject:  userID.String(),
 
 		IssuedAt:  jwt.NewNumericDate(now),
@@ -59,6 +83,8 @@ func (m *JWTManager) MintAccessToken(userID uuid.UUID, email string, userType st
 		UserID:           userID,
 		Email:            email,
 		UserType:         userType,
+		Scopes:           scopes,
+		TokenVersion:     tokenVersion,
 		RegisteredClaims: regClaims,
 	}
 	tok := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
@@ -70,7 +96,7 @@ func (m *JWTManager) MintRefreshToken(userID uuid.UUID) (string, error) {
 	reqClaims := jwt.RegisteredClaims{
 		ID:       uuid.New().String(),
 		Issuer:   m.config.Issuer,
-		Audience: []string{"interactive todo frontend"},
+		Audience: []string{AudienceFrontend},
 		Subject:  userID.String(),
 
 		IssuedAt:  jwt.NewNumericDate(now),
@@ -90,6 +116,7 @@ func (m *JWTManager) ValidateAccessToken(tokenString string) (*Claims, error) {
 	parser := jwt.NewParser(
 		jwt.WithValidMethods([]string{jwt.SigningMethodHS256.Alg()}),
 		jwt.WithIssuedAt(), jwt.WithExpirationRequired(), jwt.WithIssuer(m.config.Issuer),
+		jwt.WithAudience(AudienceFrontend, AudienceAPI),
 		jwt.WithLeeway(30*time.Second),
 	)
 	var claims Claims
@@ -102,6 +129,11 @@ func (m *JWTManager) ValidateAccessToken(tokenString string) (*Claims, error) {
 	if !token.Valid {
 		return nil, errors.New("invalid token")
 	}
+	// tokens minted before scopes existed carry none; they are always
+	// full browser sessions, so give them what their user type allows
+	if claims.Scopes == nil {
+		claims.Scopes = ScopesForUserType(claims.UserType)
+	}
 	return &claims, nil
 }
 func (m *JWTManager) ValidateRefreshToken(tokenString string) (*Claims, error) {
@@ -124,4 +156,8 @@ func (m *JWTManager) ValidateRefreshToken(tokenString string) (*Claims, error) {
 
 }
 
+func (m *JWTManager) AccessTokenTTL() time.Duration {
+	return m.config.AccessTokenExpiry
+}
+
 var _ TokenManager = (*JWTManager)(nil)
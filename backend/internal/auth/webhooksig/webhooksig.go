@@ -0,0 +1,279 @@
+// Package webhooksig verifies inbound webhooks from GitHub, Slack, Stripe
+// and Mailgun: the provider's HMAC signature, the signed timestamp where
+// the provider sends one, and that the delivery has not been seen before.
+//
+// Integration endpoints mount it through the webhook middleware rather
+// than checking signatures themselves.
+package webhooksig
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type Provider string
+
+const (
+	GitHub  Provider = "github"
+	Slack   Provider = "slack"
+	Stripe  Provider = "stripe"
+	Mailgun Provider = "mailgun"
+)
+
+const (
+	// Tolerance is how far a signed timestamp may be from now, either way.
+	Tolerance = 5 * time.Minute
+	// githubReplayWindow is how long GitHub delivery ids are remembered.
+	// GitHub signs no timestamp, so the id is the only replay protection.
+	githubReplayWindow = 72 * time.Hour
+	// MaxBody caps how much of a request body is read and MACed.
+	MaxBody = 1 << 20
+)
+
+var (
+	ErrMissing   = errors.New("webhook: no signature")
+	ErrMalformed = errors.New("webhook: malformed signature")
+	ErrBadSig    = errors.New("webhook: bad signature")
+	ErrStale     = errors.New("webhook: timestamp outside tolerance")
+	ErrReplayed  = errors.New("webhook: delivery already received")
+	ErrTooLarge  = errors.New("webhook: body too large")
+)
+
+// ReplayGuard remembers deliveries; the webhookreplay store implements it.
+type ReplayGuard interface {
+	Claim(ctx context.Context, provider, deliveryID string, expiresAt time.Time) (bool, error)
+	Release(ctx context.Context, provider, deliveryID string) error
+}
+
+// Delivery is a verified webhook.
+type Delivery struct {
+	Provider Provider
+	// ID is the provider's delivery id, or the signature when the provider
+	// sends none.
+	ID   string
+	Body []byte
+	// SignedAt is zero for GitHub.
+	SignedAt time.Time
+}
+
+type Verifier struct {
+	provider Provider
+	secrets  [][]byte
+	replay   ReplayGuard
+}
+
+// NewVerifier accepts several secrets so one can be rotated without
+// dropping deliveries: any of them may sign.
+func NewVerifier(provider Provider, replay ReplayGuard, secrets ...string) (*Verifier, error) {
+	switch provider {
+	case GitHub, Slack, Stripe, Mailgun:
+	default:
+		return nil, fmt.Errorf("webhook: unknown provider %q", provider)
+	}
+	if replay == nil {
+		return nil, errors.New("webhook: replay guard is required")
+	}
+	v := &Verifier{provider: provider, replay: replay}
+	for _, s := range secrets {
+		if s = strings.TrimSpace(s); s != "" {
+			v.secrets = append(v.secrets, []byte(s))
+		}
+	}
+	if len(v.secrets) == 0 {
+		return nil, fmt.Errorf("webhook: no secret for %s", provider)
+	}
+	return v, nil
+}
+
+func (v *Verifier) Provider() Provider { return v.provider }
+
+// Verify reads and checks r's body, then claims the delivery. r.Body is
+// replaced so the handler can still read it. Signatures are checked before
+// anything is stored, so unsigned requests cannot fill the replay table.
+func (v *Verifier) Verify(r *http.Request, now time.Time) (*Delivery, error) {
+	body, err := io.ReadAll(io.LimitReader(r.Body, MaxBody+1))
+	if err != nil {
+		return nil, fmt.Errorf("webhook: read body: %w", err)
+	}
+	if len(body) > MaxBody {
+		return nil, ErrTooLarge
+	}
+	r.Body = io.NopCloser(bytes.NewReader(body))
+
+	var d *Delivery
+	switch v.provider {
+	case GitHub:
+		d, err = v.github(r.Header, body)
+	case Slack:
+		d, err = v.slack(r.Header, body)
+	case Stripe:
+		d, err = v.stripe(r.Header, body)
+	case Mailgun:
+		d, err = v.mailgun(body)
+	}
+	if err != nil {
+		return nil, err
+	}
+	d.Provider = v.provider
+	d.Body = body
+
+	expiresAt := now.Add(githubReplayWindow)
+	if !d.SignedAt.IsZero() {
+		if age := now.Sub(d.SignedAt); age > Tolerance || age < -Tolerance {
+			return nil, ErrStale
+		}
+		expiresAt = d.SignedAt.Add(Tolerance)
+	}
+
+	ok, err := v.replay.Claim(r.Context(), string(v.provider), d.ID, expiresAt)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, ErrReplayed
+	}
+	return d, nil
+}
+
+// Release forgets a delivery so the provider's retry is accepted, for when
+// handling it failed.
+func (v *Verifier) Release(ctx context.Context, d *Delivery) error {
+	return v.replay.Release(ctx, string(d.Provider), d.ID)
+}
+
+// X-Hub-Signature-256: sha256=<hex HMAC of the body>
+func (v *Verifier) github(h http.Header, body []byte) (*Delivery, error) {
+	raw := h.Get("X-Hub-Signature-256")
+	if raw == "" {
+		return nil, ErrMissing
+	}
+	sig, ok := decodeHex(strings.TrimPrefix(raw, "sha256="), strings.HasPrefix(raw, "sha256="))
+	if !ok {
+		return nil, ErrMalformed
+	}
+	if !v.match(body, sig) {
+		return nil, ErrBadSig
+	}
+	id := h.Get("X-GitHub-Delivery")
+	if id == "" {
+		return nil, ErrMalformed
+	}
+	return &Delivery{ID: id}, nil
+}
+
+// X-Slack-Signature: v0=<hex HMAC of "v0:<timestamp>:<body>">
+func (v *Verifier) slack(h http.Header, body []byte) (*Delivery, error) {
+	raw, ts := h.Get("X-Slack-Signature"), h.Get("X-Slack-Request-Timestamp")
+	if raw == "" {
+		return nil, ErrMissing
+	}
+	sig, ok := decodeHex(strings.TrimPrefix(raw, "v0="), strings.HasPrefix(raw, "v0="))
+	signedAt, err := parseUnix(ts)
+	if !ok || err != nil {
+		return nil, ErrMalformed
+	}
+	if !v.match([]byte("v0:"+ts+":"+string(body)), sig) {
+		return nil, ErrBadSig
+	}
+	return &Delivery{ID: hex.EncodeToString(sig), SignedAt: signedAt}, nil
+}
+
+// Stripe-Signature: t=<timestamp>,v1=<hex HMAC of "<t>.<body>">[,v1=...]
+// Stripe sends one v1 per active secret while a secret is being rolled.
+func (v *Verifier) stripe(h http.Header, body []byte) (*Delivery, error) {
+	raw := h.Get("Stripe-Signature")
+	if raw == "" {
+		return nil, ErrMissing
+	}
+	var ts string
+	var sigs [][]byte
+	for _, part := range strings.Split(raw, ",") {
+		k, val, _ := strings.Cut(strings.TrimSpace(part), "=")
+		switch k {
+		case "t":
+			ts = val
+		case "v1":
+			if sig, ok := decodeHex(val, true); ok {
+				sigs = append(sigs, sig)
+			}
+		}
+	}
+	signedAt, err := parseUnix(ts)
+	if err != nil || len(sigs) == 0 {
+		return nil, ErrMalformed
+	}
+	msg := []byte(ts + "." + string(body))
+	for _, sig := range sigs {
+		if v.match(msg, sig) {
+			return &Delivery{ID: hex.EncodeToString(sig), SignedAt: signedAt}, nil
+		}
+	}
+	return nil, ErrBadSig
+}
+
+// Mailgun signs inside the JSON body: signature.signature is the hex HMAC
+// of timestamp+token. The token is unique per delivery.
+func (v *Verifier) mailgun(body []byte) (*Delivery, error) {
+	var in struct {
+		Signature struct {
+			Timestamp string `json:"timestamp"`
+			Token     string `json:"token"`
+			Signature string `json:"signature"`
+		} `json:"signature"`
+	}
+	if err := json.Unmarshal(body, &in); err != nil {
+		return nil, ErrMalformed
+	}
+	s := in.Signature
+	if s.Signature == "" {
+		return nil, ErrMissing
+	}
+	sig, ok := decodeHex(s.Signature, true)
+	signedAt, err := parseUnix(s.Timestamp)
+	if !ok || err != nil || s.Token == "" {
+		return nil, ErrMalformed
+	}
+	if !v.match([]byte(s.Timestamp+s.Token), sig) {
+		return nil, ErrBadSig
+	}
+	return &Delivery{ID: s.Token, SignedAt: signedAt}, nil
+}
+
+// match reports whether any secret signs msg as sig.
+func (v *Verifier) match(msg, sig []byte) bool {
+	for _, secret := range v.secrets {
+		mac := hmac.New(sha256.New, secret)
+		mac.Write(msg)
+		if hmac.Equal(mac.Sum(nil), sig) {
+			return true
+		}
+	}
+	return false
+}
+
+func decodeHex(s string, prefixed bool) ([]byte, bool) {
+	if !prefixed {
+		return nil, false
+	}
+	b, err := hex.DecodeString(s)
+	return b, err == nil && len(b) == sha256.Size
+}
+
+func parseUnix(s string) (time.Time, error) {
+	n, err := strconv.ParseInt(s, 10, 64)
+	if err != nil {
+		return time.Time{}, err
+	}
+	return time.Unix(n, 0).UTC(), nil
+}
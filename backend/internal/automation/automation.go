@@ -0,0 +1,190 @@
+// Package automation validates team recipes ("when a task moves to done,
+// comment and notify the reporter") and matches their triggers against
+// task events. The runner package executes them.
+package automation
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/diagnosis/interactive-todo/internal/events"
+	taskstore "github.com/diagnosis/interactive-todo/internal/store/tasks"
+	"github.com/google/uuid"
+)
+
+var ErrInvalidDefinition = errors.New("invalid automation recipe")
+
+type TriggerType string
+
+const (
+	TriggerStatusChanged TriggerType = "status_changed"
+	TriggerDueSoon       TriggerType = "due_soon"
+	TriggerLabelAdded    TriggerType = "label_added"
+)
+
+type ActionType string
+
+const (
+	ActionAssign     ActionType = "assign"
+	ActionComment    ActionType = "comment"
+	ActionNotify     ActionType = "notify"
+	ActionMoveStatus ActionType = "move_status"
+)
+
+// Role picks a user relative to the task.
+type Role string
+
+const (
+	RoleReporter Role = "reporter"
+	RoleAssignee Role = "assignee"
+)
+
+const (
+	maxActions     = 10
+	maxTextLength  = 2000
+	maxWithinHours = 24 * 14
+)
+
+// Trigger says which events start a recipe. From and To narrow
+// status_changed; Label narrows label_added (empty matches any label);
+// WithinHours is required for due_soon.
+type Trigger struct {
+	Type        TriggerType          `json:"type"`
+	From        taskstore.TaskStatus `json:"from,omitempty"`
+	To          taskstore.TaskStatus `json:"to,omitempty"`
+	Label       string               `json:"label,omitempty"`
+	WithinHours int                  `json:"within_hours,omitempty"`
+}
+
+// Action is one step of a recipe. assign takes AssigneeID or Role; notify
+// takes UserID or Role and Message; comment takes Body; move_status takes
+// Status.
+type Action struct {
+	Type       ActionType           `json:"type"`
+	AssigneeID *uuid.UUID           `json:"assignee_id,omitempty"`
+	UserID     *uuid.UUID           `json:"user_id,omitempty"`
+	Role       Role                 `json:"role,omitempty"`
+	Body       string               `json:"body,omitempty"`
+	Message    string               `json:"message,omitempty"`
+	Status     taskstore.TaskStatus `json:"status,omitempty"`
+}
+
+// Definition is stored as JSONB on the automation_recipes table.
+type Definition struct {
+	Trigger Trigger  `json:"trigger"`
+	Actions []Action `json:"actions"`
+}
+
+func validStatus(s taskstore.TaskStatus) bool {
+	switch s {
+	case taskstore.OpenStatus, taskstore.InProgressStatus, taskstore.DoneStatus, taskstore.CanceledStatus:
+		return true
+	default:
+		return false
+	}
+}
+
+func validRole(r Role) bool {
+	return r == RoleReporter || r == RoleAssignee
+}
+
+// Validate checks the definition and trims its text in place.
+func (d *Definition) Validate() error {
+	t := &d.Trigger
+	switch t.Type {
+	case TriggerStatusChanged:
+		if t.From != "" && !validStatus(t.From) {
+			return fmt.Errorf("%w: unknown trigger.from status %q", ErrInvalidDefinition, t.From)
+		}
+		if t.To != "" && !validStatus(t.To) {
+			return fmt.Errorf("%w: unknown trigger.to status %q", ErrInvalidDefinition, t.To)
+		}
+	case TriggerDueSoon:
+		if t.WithinHours < 1 || t.WithinHours > maxWithinHours {
+			return fmt.Errorf("%w: trigger.within_hours must be between 1 and %d", ErrInvalidDefinition, maxWithinHours)
+		}
+	case TriggerLabelAdded:
+		t.Label = strings.TrimSpace(t.Label)
+	default:
+		return fmt.Errorf("%w: trigger.type must be status_changed, due_soon or label_added", ErrInvalidDefinition)
+	}
+
+	if len(d.Actions) == 0 {
+		return fmt.Errorf("%w: at least one action is required", ErrInvalidDefinition)
+	}
+	if len(d.Actions) > maxActions {
+		return fmt.Errorf("%w: too many actions (max %d)", ErrInvalidDefinition, maxActions)
+	}
+	for i := range d.Actions {
+		if err := d.Actions[i].validate(); err != nil {
+			return fmt.Errorf("%w: actions[%d]: %s", ErrInvalidDefinition, i, err)
+		}
+	}
+	return nil
+}
+
+func (a *Action) validate() error {
+	a.Body = strings.TrimSpace(a.Body)
+	a.Message = strings.TrimSpace(a.Message)
+	switch a.Type {
+	case ActionAssign:
+		if (a.AssigneeID == nil) == (a.Role == "") {
+			return errors.New("assign needs exactly one of assignee_id or role")
+		}
+		if a.Role != "" && !validRole(a.Role) {
+			return errors.New("role must be reporter or assignee")
+		}
+	case ActionComment:
+		if a.Body == "" || len(a.Body) > maxTextLength {
+			return fmt.Errorf("comment needs a body (max %d chars)", maxTextLength)
+		}
+	case ActionNotify:
+		if (a.UserID == nil) == (a.Role == "") {
+			return errors.New("notify needs exactly one of user_id or role")
+		}
+		if a.Role != "" && !validRole(a.Role) {
+			return errors.New("role must be reporter or assignee")
+		}
+		if a.Message == "" || len(a.Message) > maxTextLength {
+			return fmt.Errorf("notify needs a message (max %d chars)", maxTextLength)
+		}
+	case ActionMoveStatus:
+		if !validStatus(a.Status) {
+			return errors.New("move_status needs a valid status")
+		}
+	default:
+		return errors.New("type must be assign, comment, notify or move_status")
+	}
+	return nil
+}
+
+// TriggerFor maps an event type to the recipes it can start.
+func TriggerFor(t events.Type) (TriggerType, bool) {
+	switch t {
+	case events.TaskStatusChanged:
+		return TriggerStatusChanged, true
+	case events.TaskDueSoon:
+		return TriggerDueSoon, true
+	case events.TaskLabelAdded:
+		return TriggerLabelAdded, true
+	default:
+		return "", false
+	}
+}
+
+// Matches reports whether e starts a recipe with this trigger.
+func (t Trigger) Matches(e events.Event) bool {
+	tt, ok := TriggerFor(e.Type)
+	if !ok || tt != t.Type {
+		return false
+	}
+	switch t.Type {
+	case TriggerStatusChanged:
+		return (t.From == "" || string(t.From) == e.From) && (t.To == "" || string(t.To) == e.Status)
+	case TriggerLabelAdded:
+		return t.Label == "" || strings.EqualFold(t.Label, e.Label)
+	default:
+		return true
+	}
+}
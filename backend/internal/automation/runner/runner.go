@@ -0,0 +1,209 @@
+// Package runner executes automation recipes when task events arrive on
+// the bus.
+package runner
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/diagnosis/interactive-todo/internal/automation"
+	"github.com/diagnosis/interactive-todo/internal/events"
+	"github.com/diagnosis/interactive-todo/internal/logger"
+	"github.com/diagnosis/interactive-todo/internal/notify"
+	automationstore "github.com/diagnosis/interactive-todo/internal/store/automations"
+	commentstore "github.com/diagnosis/interactive-todo/internal/store/comments"
+	taskstore "github.com/diagnosis/interactive-todo/internal/store/tasks"
+	teamstore "github.com/diagnosis/interactive-todo/internal/store/teams"
+	"github.com/google/uuid"
+)
+
+// MaxChain bounds how many recipes may trigger one another from a single
+// change. Together with never running a recipe twice in one chain, it
+// stops recipes from ping-ponging a task between statuses.
+const MaxChain = 5
+
+type Runner struct {
+	recipeStore  automationstore.RecipeStore
+	taskStore    taskstore.TaskStore
+	teamStore    teamstore.TeamStore
+	commentStore commentstore.CommentStore
+	notifier     notify.Notifier
+	events       events.Publisher
+}
+
+func New(
+	rs automationstore.RecipeStore,
+	ts taskstore.TaskStore,
+	tms teamstore.TeamStore,
+	cs commentstore.CommentStore,
+	n notify.Notifier,
+	ev events.Publisher,
+) *Runner {
+	return &Runner{recipeStore: rs, taskStore: ts, teamStore: tms, commentStore: cs, notifier: n, events: ev}
+}
+
+// Register subscribes the runner to every event a trigger can match.
+func (rn *Runner) Register(bus *events.Bus) {
+	for _, t := range []events.Type{events.TaskStatusChanged, events.TaskLabelAdded, events.TaskDueSoon} {
+		bus.Subscribe(t, rn.Handle)
+	}
+}
+
+func (rn *Runner) Handle(ctx context.Context, e events.Event) {
+	trigger, ok := automation.TriggerFor(e.Type)
+	if !ok {
+		return
+	}
+	if len(e.Cause) >= MaxChain {
+		logger.Warn(ctx, "automation: chain too long, stopping", "task_id", e.TaskID, "chain", e.Cause)
+		return
+	}
+
+	recipes, err := rn.recipeStore.ListEnabled(ctx, e.TeamID, trigger)
+	if err != nil {
+		logger.Error(ctx, "automation: list recipes failed", "team_id", e.TeamID, "err", err)
+		return
+	}
+
+	for _, rec := range recipes {
+		// due_soon events are addressed to the recipe that claimed them
+		if e.RecipeID != nil && *e.RecipeID != rec.ID {
+			continue
+		}
+		if !rec.Definition.Trigger.Matches(e) {
+			continue
+		}
+		if e.CausedBy(rec.ID) {
+			logger.Info(ctx, "automation: loop prevented", "recipe_id", rec.ID, "task_id", e.TaskID)
+			continue
+		}
+		rn.run(ctx, rec, e)
+	}
+}
+
+func (rn *Runner) run(ctx context.Context, rec automationstore.Recipe, e events.Event) {
+	cause := append(slices.Clone(e.Cause), rec.ID)
+	for i, a := range rec.Definition.Actions {
+		// reload so each action sees what the previous one did
+		task, err := rn.taskStore.GetTaskByID(ctx, e.TaskID)
+		if err != nil {
+			logger.Error(ctx, "automation: load task failed", "recipe_id", rec.ID, "task_id", e.TaskID, "err", err)
+			return
+		}
+		if task.TeamID != rec.TeamID {
+			return
+		}
+		if err := rn.act(ctx, rec, a, task, cause); err != nil {
+			logger.Error(ctx, "automation: action failed",
+				"recipe_id", rec.ID,
+				"task_id", task.ID,
+				"action", i,
+				"type", a.Type,
+				"err", err,
+			)
+			continue
+		}
+	}
+	logger.Info(ctx, "automation: recipe ran", "recipe_id", rec.ID, "task_id", e.TaskID, "trigger", e.Type)
+}
+
+func (rn *Runner) act(ctx context.Context, rec automationstore.Recipe, a automation.Action, task *taskstore.Task, cause []uuid.UUID) error {
+	now := time.Now().UTC()
+	switch a.Type {
+	case automation.ActionAssign:
+		assigneeID := userFor(task, a.AssigneeID, a.Role)
+		if assigneeID == task.AssigneeID {
+			return nil
+		}
+		if err := rn.requireMember(ctx, task.TeamID, assigneeID); err != nil {
+			return err
+		}
+		if _, err := rn.taskStore.Assign(ctx, task.ID, assigneeID, 0, nil, now); err != nil {
+			return err
+		}
+		rn.events.Publish(ctx, events.Event{
+			Type:     events.TaskAssigned,
+			TeamID:   task.TeamID,
+			TaskID:   task.ID,
+			RecipeID: &rec.ID,
+			Cause:    cause,
+			At:       now,
+		})
+
+	case automation.ActionComment:
+		if _, err := rn.commentStore.Create(ctx, task.ID, nil, &rec.ID, a.Body, now); err != nil {
+			return err
+		}
+
+	case automation.ActionNotify:
+		userID := userFor(task, a.UserID, a.Role)
+		if err := rn.requireMember(ctx, task.TeamID, userID); err != nil {
+			return err
+		}
+		return rn.notifier.Notify(ctx, notify.Notification{
+			UserID:  userID,
+			Kind:    notify.KindAutomation,
+			Subject: fmt.Sprintf("%s: %s", rec.Name, task.Title),
+			Body:    a.Message,
+			TaskID:  &task.ID,
+			TeamID:  &task.TeamID,
+		})
+
+	case automation.ActionMoveStatus:
+		if a.Status == task.Status {
+			return nil
+		}
+		settings, err := rn.teamStore.GetSettings(ctx, task.TeamID)
+		if err != nil {
+			return err
+		}
+		// recipes never bypass a custom workflow or the approval gate
+		if settings.WorkflowID != nil {
+			return errors.New("team uses a custom workflow")
+		}
+		if settings.RequiresApproval && a.Status == taskstore.DoneStatus && task.ReporterID != task.AssigneeID {
+			return errors.New("team requires approval to finish tasks")
+		}
+		if _, err := rn.taskStore.UpdateStatus(ctx, task.ID, a.Status, 0, nil, now); err != nil {
+			return err
+		}
+		rn.events.Publish(ctx, events.Event{
+			Type:     events.TaskStatusChanged,
+			TeamID:   task.TeamID,
+			TaskID:   task.ID,
+			Status:   string(a.Status),
+			From:     string(task.Status),
+			RecipeID: &rec.ID,
+			Cause:    cause,
+			At:       now,
+		})
+	}
+	return nil
+}
+
+func (rn *Runner) requireMember(ctx context.Context, teamID, userID uuid.UUID) error {
+	isMember, err := rn.teamStore.IsMember(ctx, teamID, userID)
+	if err != nil {
+		return err
+	}
+	if !isMember {
+		return fmt.Errorf("user_id=%s is not a member of the team", userID)
+	}
+	return nil
+}
+
+// userFor resolves an action's target: an explicit user, or the task's
+// reporter or assignee.
+func userFor(task *taskstore.Task, id *uuid.UUID, role automation.Role) uuid.UUID {
+	switch {
+	case id != nil:
+		return *id
+	case role == automation.RoleReporter:
+		return task.ReporterID
+	default:
+		return task.AssigneeID
+	}
+}
@@ -0,0 +1,120 @@
+package backup
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/diagnosis/interactive-todo/internal/storage"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// Config is where exports go and the key they are sealed with.
+type Config struct {
+	S3     *storage.S3
+	Key    []byte
+	Prefix string
+}
+
+// ConfigFromEnv returns nil, nil when BACKUP_S3_BUCKET is unset: exports
+// are off. Otherwise BACKUP_ENCRYPTION_KEY (base64, 32 bytes) and the AWS
+// credentials are required. BACKUP_S3_ENDPOINT points at an S3-compatible
+// store instead of AWS.
+func ConfigFromEnv() (*Config, error) {
+	bucket := strings.TrimSpace(os.Getenv("BACKUP_S3_BUCKET"))
+	if bucket == "" {
+		return nil, nil
+	}
+	key, err := KeyFromEnv("BACKUP_ENCRYPTION_KEY")
+	if err != nil {
+		return nil, err
+	}
+	if key == nil {
+		return nil, errors.New("BACKUP_ENCRYPTION_KEY is required when BACKUP_S3_BUCKET is set")
+	}
+	s3 := &storage.S3{
+		Bucket:          bucket,
+		Region:          envOr("BACKUP_S3_REGION", "us-east-1"),
+		Endpoint:        strings.TrimSpace(os.Getenv("BACKUP_S3_ENDPOINT")),
+		AccessKeyID:     os.Getenv("AWS_ACCESS_KEY_ID"),
+		SecretAccessKey: os.Getenv("AWS_SECRET_ACCESS_KEY"),
+		SessionToken:    os.Getenv("AWS_SESSION_TOKEN"),
+		Client:          &http.Client{Timeout: time.Hour},
+	}
+	if s3.AccessKeyID == "" || s3.SecretAccessKey == "" {
+		return nil, errors.New("AWS_ACCESS_KEY_ID and AWS_SECRET_ACCESS_KEY are required when BACKUP_S3_BUCKET is set")
+	}
+	return &Config{S3: s3, Key: key, Prefix: envOr("BACKUP_S3_PREFIX", "backups/")}, nil
+}
+
+func envOr(name, def string) string {
+	if v := strings.TrimSpace(os.Getenv(name)); v != "" {
+		return v
+	}
+	return def
+}
+
+// ObjectKey names the archive of an export, e.g.
+// backups/2026/10/17/<export id>-full.backup.
+func (c *Config) ObjectKey(exportID uuid.UUID, teamID *uuid.UUID, now time.Time) string {
+	scope := "full"
+	if teamID != nil {
+		scope = "team-" + teamID.String()
+	}
+	return c.Prefix + now.UTC().Format("2006/01/02") + "/" + exportID.String() + "-" + scope + ".backup"
+}
+
+// Uploaded describes an archive written to S3.
+type Uploaded struct {
+	ObjectKey string
+	SizeBytes int64
+	Summary   *Summary
+}
+
+// Upload exports the database (or one team) into an encrypted archive and
+// stores it under ObjectKey. The archive is staged in a temporary file,
+// since S3 needs its size and hash up front.
+func (c *Config) Upload(ctx context.Context, pool *pgxpool.Pool, exportID uuid.UUID, teamID *uuid.UUID, now time.Time) (*Uploaded, error) {
+	f, err := os.CreateTemp("", "backup-*.tmp")
+	if err != nil {
+		return nil, fmt.Errorf("upload: temp file: %w", err)
+	}
+	defer func() {
+		_ = f.Close()
+		_ = os.Remove(f.Name())
+	}()
+
+	hash := sha256.New()
+	enc, err := NewWriter(io.MultiWriter(f, hash), c.Key)
+	if err != nil {
+		return nil, fmt.Errorf("upload: %w", err)
+	}
+	sum, err := Export(ctx, pool, enc, teamID, now)
+	if err != nil {
+		return nil, err
+	}
+	if err := enc.Close(); err != nil {
+		return nil, fmt.Errorf("upload: seal: %w", err)
+	}
+
+	size, err := f.Seek(0, io.SeekEnd)
+	if err != nil {
+		return nil, fmt.Errorf("upload: %w", err)
+	}
+	if _, err := f.Seek(0, io.SeekStart); err != nil {
+		return nil, fmt.Errorf("upload: %w", err)
+	}
+	key := c.ObjectKey(exportID, teamID, now)
+	if err := c.S3.Put(ctx, key, f, size, hex.EncodeToString(hash.Sum(nil))); err != nil {
+		return nil, fmt.Errorf("upload %s: %w", key, err)
+	}
+	return &Uploaded{ObjectKey: key, SizeBytes: size, Summary: sum}, nil
+}
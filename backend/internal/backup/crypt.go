@@ -0,0 +1,223 @@
+package backup
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+// An archive is a header (magic and a random archive id) followed by
+// chunks of up to chunkSize plaintext bytes, each stored as
+// len(uint32) | nonce | AES-256-GCM ciphertext. The archive id, the chunk
+// index and a last-chunk flag are authenticated with every chunk, so
+// chunks cannot be reordered, swapped between archives or cut off.
+const (
+	magic     = "ITBACKUP1\n"
+	idLen     = 16
+	chunkSize = 64 << 10
+	keyLen    = 32
+)
+
+var (
+	ErrInvalidKey = errors.New("backup encryption key must be 32 bytes")
+	ErrNotArchive = errors.New("not a backup archive")
+	ErrTruncated  = errors.New("backup archive is truncated")
+	ErrCorrupt    = errors.New("backup archive is corrupt or the key is wrong")
+)
+
+// KeyFromEnv reads a base64 key from the named variable. An unset
+// variable returns nil, nil.
+func KeyFromEnv(name string) ([]byte, error) {
+	v := strings.TrimSpace(os.Getenv(name))
+	if v == "" {
+		return nil, nil
+	}
+	key, err := base64.StdEncoding.DecodeString(v)
+	if err != nil {
+		return nil, fmt.Errorf("%s: decode base64: %w", name, err)
+	}
+	if len(key) != keyLen {
+		return nil, ErrInvalidKey
+	}
+	return key, nil
+}
+
+func newAEAD(key []byte) (cipher.AEAD, error) {
+	if len(key) != keyLen {
+		return nil, ErrInvalidKey
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, fmt.Errorf("new aes cipher: %w", err)
+	}
+	return cipher.NewGCM(block)
+}
+
+func chunkAAD(id []byte, index uint64, last bool) []byte {
+	aad := make([]byte, 0, idLen+9)
+	aad = append(aad, id...)
+	aad = binary.BigEndian.AppendUint64(aad, index)
+	if last {
+		return append(aad, 1)
+	}
+	return append(aad, 0)
+}
+
+// Writer encrypts an archive onto w. Close writes the last chunk and must
+// be called; it does not close w.
+type Writer struct {
+	w     io.Writer
+	aead  cipher.AEAD
+	id    []byte
+	index uint64
+	buf   []byte
+}
+
+func NewWriter(w io.Writer, key []byte) (*Writer, error) {
+	aead, err := newAEAD(key)
+	if err != nil {
+		return nil, err
+	}
+	id := make([]byte, idLen)
+	if _, err := rand.Read(id); err != nil {
+		return nil, fmt.Errorf("generate archive id: %w", err)
+	}
+	if _, err := io.WriteString(w, magic); err != nil {
+		return nil, err
+	}
+	if _, err := w.Write(id); err != nil {
+		return nil, err
+	}
+	return &Writer{w: w, aead: aead, id: id, buf: make([]byte, 0, chunkSize)}, nil
+}
+
+func (e *Writer) Write(p []byte) (int, error) {
+	n := 0
+	for len(p) > 0 {
+		take := min(chunkSize-len(e.buf), len(p))
+		e.buf = append(e.buf, p[:take]...)
+		p = p[take:]
+		n += take
+		// a full buffer is sealed only once more data arrives, so the
+		// last chunk is never empty unless the archive is
+		if len(e.buf) == chunkSize && len(p) > 0 {
+			if err := e.seal(false); err != nil {
+				return n, err
+			}
+		}
+	}
+	return n, nil
+}
+
+func (e *Writer) Close() error {
+	return e.seal(true)
+}
+
+func (e *Writer) seal(last bool) error {
+	nonce := make([]byte, e.aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return fmt.Errorf("generate nonce: %w", err)
+	}
+	sealed := e.aead.Seal(nonce, nonce, e.buf, chunkAAD(e.id, e.index, last))
+
+	var size [4]byte
+	binary.BigEndian.PutUint32(size[:], uint32(len(sealed)))
+	if _, err := e.w.Write(size[:]); err != nil {
+		return err
+	}
+	if _, err := e.w.Write(sealed); err != nil {
+		return err
+	}
+	e.index++
+	e.buf = e.buf[:0]
+	return nil
+}
+
+// Reader decrypts an archive written by Writer. It returns ErrTruncated if
+// the input ends before the last chunk and ErrCorrupt if a chunk fails to
+// open.
+type Reader struct {
+	r     io.Reader
+	aead  cipher.AEAD
+	id    []byte
+	index uint64
+	plain []byte
+	done  bool
+}
+
+func NewReader(r io.Reader, key []byte) (*Reader, error) {
+	aead, err := newAEAD(key)
+	if err != nil {
+		return nil, err
+	}
+	head := make([]byte, len(magic)+idLen)
+	if _, err := io.ReadFull(r, head); err != nil {
+		return nil, ErrNotArchive
+	}
+	if !bytes.Equal(head[:len(magic)], []byte(magic)) {
+		return nil, ErrNotArchive
+	}
+	return &Reader{r: r, aead: aead, id: head[len(magic):]}, nil
+}
+
+func (d *Reader) Read(p []byte) (int, error) {
+	for len(d.plain) == 0 {
+		if d.done {
+			return 0, io.EOF
+		}
+		if err := d.next(); err != nil {
+			return 0, err
+		}
+	}
+	n := copy(p, d.plain)
+	d.plain = d.plain[n:]
+	return n, nil
+}
+
+func (d *Reader) next() error {
+	var size [4]byte
+	if _, err := io.ReadFull(d.r, size[:]); err != nil {
+		if errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+			return ErrTruncated
+		}
+		return err
+	}
+	n := int(binary.BigEndian.Uint32(size[:]))
+	if n < d.aead.NonceSize()+d.aead.Overhead() || n > d.aead.NonceSize()+chunkSize+d.aead.Overhead() {
+		return ErrCorrupt
+	}
+	sealed := make([]byte, n)
+	if _, err := io.ReadFull(d.r, sealed); err != nil {
+		if errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+			return ErrTruncated
+		}
+		return err
+	}
+
+	ns := d.aead.NonceSize()
+	// the flag is not stored; a chunk opens under exactly one of the two
+	plain, err := d.aead.Open(nil, sealed[:ns], sealed[ns:], chunkAAD(d.id, d.index, false))
+	if err != nil {
+		plain, err = d.aead.Open(nil, sealed[:ns], sealed[ns:], chunkAAD(d.id, d.index, true))
+		if err != nil {
+			return ErrCorrupt
+		}
+		d.done = true
+		// nothing may follow the last chunk
+		var extra [1]byte
+		if m, _ := d.r.Read(extra[:]); m > 0 {
+			return ErrCorrupt
+		}
+	}
+	d.index++
+	d.plain = plain
+	return nil
+}
@@ -0,0 +1,156 @@
+// Package calendar knows which days a team works. Due dates, stale nudges
+// and acknowledgement nudges go through it so weekends and holidays are
+// not counted against anyone.
+package calendar
+
+import (
+	"errors"
+	"fmt"
+	"time"
+	// team timezones must resolve on hosts without a zoneinfo database
+	_ "time/tzdata"
+
+	"github.com/diagnosis/interactive-todo/api/types"
+)
+
+// Policy is what happens when a due date lands on a non-working day.
+type Policy = types.DueDatePolicy
+
+const (
+	PolicyOff   = types.DueDatePolicyOff
+	PolicyWarn  = types.DueDatePolicyWarn
+	PolicyShift = types.DueDatePolicyShift
+)
+
+func ValidPolicy(p Policy) bool {
+	switch p {
+	case PolicyOff, PolicyWarn, PolicyShift:
+		return true
+	default:
+		return false
+	}
+}
+
+// DateLayout is how holidays are written.
+const DateLayout = time.DateOnly
+
+// maxScan bounds the day-by-day walks; a year of holidays in a row is a
+// configuration mistake, not a calendar.
+const maxScan = 400
+
+var ErrInvalid = errors.New("invalid calendar")
+
+type Calendar struct {
+	Location *time.Location
+	Weekend  map[time.Weekday]bool
+	// Holidays maps DateLayout days in Location to their names.
+	Holidays map[string]string
+	Policy   Policy
+}
+
+// Default is Monday to Friday in UTC, no holidays, policy off: what a team
+// gets before it configures anything.
+func Default() *Calendar {
+	c, _ := New("UTC", []int{int(time.Saturday), int(time.Sunday)}, PolicyOff)
+	return c
+}
+
+// New validates the stored settings. weekendDays use time.Weekday numbers
+// (0 is Sunday); at least one day of the week must be a working day.
+func New(timezone string, weekendDays []int, policy Policy) (*Calendar, error) {
+	loc, err := time.LoadLocation(timezone)
+	if err != nil {
+		return nil, fmt.Errorf("%w: unknown timezone %q", ErrInvalid, timezone)
+	}
+	if !ValidPolicy(policy) {
+		return nil, fmt.Errorf("%w: due_date_policy must be off, warn or shift", ErrInvalid)
+	}
+	weekend := make(map[time.Weekday]bool, len(weekendDays))
+	for _, d := range weekendDays {
+		if d < 0 || d > 6 {
+			return nil, fmt.Errorf("%w: weekend days must be 0 (Sunday) to 6 (Saturday)", ErrInvalid)
+		}
+		weekend[time.Weekday(d)] = true
+	}
+	if len(weekend) == 7 {
+		return nil, fmt.Errorf("%w: at least one day of the week must be a working day", ErrInvalid)
+	}
+	return &Calendar{Location: loc, Weekend: weekend, Holidays: map[string]string{}, Policy: policy}, nil
+}
+
+// NonWorking reports why t's day (in the team's timezone) is off: the
+// holiday name or the weekday. ok is false on working days.
+func (c *Calendar) NonWorking(t time.Time) (reason string, ok bool) {
+	local := t.In(c.Location)
+	if name, ok := c.Holidays[local.Format(DateLayout)]; ok {
+		return name, true
+	}
+	if c.Weekend[local.Weekday()] {
+		return local.Weekday().String(), true
+	}
+	return "", false
+}
+
+func (c *Calendar) IsWorkingDay(t time.Time) bool {
+	_, off := c.NonWorking(t)
+	return !off
+}
+
+// NextWorkingDay moves t forward a day at a time, keeping its local clock
+// time, until it lands on a working day. A working day is returned as is.
+func (c *Calendar) NextWorkingDay(t time.Time) time.Time {
+	local := t.In(c.Location)
+	for i := 0; i < maxScan && !c.IsWorkingDay(local); i++ {
+		local = local.AddDate(0, 0, 1)
+	}
+	return local.In(t.Location())
+}
+
+// AddWorkingDays returns t plus n working days, for SLA-style deadlines.
+func (c *Calendar) AddWorkingDays(t time.Time, n int) time.Time {
+	local := t.In(c.Location)
+	for i := 0; n > 0 && i < maxScan*7; i++ {
+		local = local.AddDate(0, 0, 1)
+		if c.IsWorkingDay(local) {
+			n--
+		}
+	}
+	return local.In(t.Location())
+}
+
+// WorkingDaysBetween counts the working days after from's day up to and
+// including to's day.
+func (c *Calendar) WorkingDaysBetween(from, to time.Time) int {
+	day := startOfDay(from.In(c.Location))
+	end := startOfDay(to.In(c.Location))
+	n := 0
+	for i := 0; day.Before(end) && i < maxScan*7; i++ {
+		day = day.AddDate(0, 0, 1)
+		if c.IsWorkingDay(day) {
+			n++
+		}
+	}
+	return n
+}
+
+// WorkingTime is the part of [from, to) that falls on working days.
+func (c *Calendar) WorkingTime(from, to time.Time) time.Duration {
+	var total time.Duration
+	cur := from.In(c.Location)
+	for i := 0; cur.Before(to) && i < maxScan*7; i++ {
+		next := startOfDay(cur).AddDate(0, 0, 1)
+		if next.After(to) {
+			next = to
+		}
+		if c.IsWorkingDay(cur) {
+			total += next.Sub(cur)
+		}
+		cur = next.In(c.Location)
+	}
+	return total
+}
+
+func startOfDay(t time.Time) time.Time {
+	y, m, d := t.Date()
+	return time.Date(y, m, d, 0, 0, 0, 0, t.Location())
+}
@@ -0,0 +1,149 @@
+package config
+
+import (
+	"bufio"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// Layer is where a setting's effective value came from. Later layers win:
+// defaults < file < env < flags.
+type Layer string
+
+const (
+	LayerDefault Layer = "default"
+	LayerFile    Layer = "file"
+	LayerEnv     Layer = "env"
+	LayerFlag    Layer = "flag"
+)
+
+// Sources are the config file and -set overrides given on the command line.
+// Nothing is read implicitly: without -config or CONFIG_FILE no file is
+// loaded, so a stray .env in the working directory changes nothing.
+type Sources struct {
+	File string
+	Set  []string
+}
+
+// RegisterFlags adds -config and -set to fs. Parse fs before calling Load.
+func (s *Sources) RegisterFlags(fs *flag.FlagSet) {
+	fs.StringVar(&s.File, "config", "", "file of KEY=VALUE lines to read settings from (default $CONFIG_FILE)")
+	fs.Func("set", "KEY=VALUE overriding the file and the environment; repeatable", func(v string) error {
+		if _, _, ok := strings.Cut(v, "="); !ok {
+			return fmt.Errorf("%q is not KEY=VALUE", v)
+		}
+		s.Set = append(s.Set, v)
+		return nil
+	})
+}
+
+// layers records the variables Load set.
+var layers = map[string]Layer{}
+
+// Load brings the layers into the process environment, which is what every
+// reader in the tree looks at. File values only fill variables the
+// environment leaves unset; -set values replace either. Call it once at
+// startup, before any config is read.
+func Load(s Sources) error {
+	file := s.File
+	if file == "" {
+		file = os.Getenv("CONFIG_FILE")
+	}
+	if file != "" {
+		vars, err := readFile(file)
+		if err != nil {
+			return err
+		}
+		for _, kv := range vars {
+			if _, ok := os.LookupEnv(kv[0]); ok {
+				continue
+			}
+			if err := os.Setenv(kv[0], kv[1]); err != nil {
+				return fmt.Errorf("config file %s: %w", file, err)
+			}
+			layers[kv[0]] = LayerFile
+		}
+	}
+
+	for _, v := range s.Set {
+		k, val, _ := strings.Cut(v, "=")
+		k = strings.TrimSpace(k)
+		if err := os.Setenv(k, val); err != nil {
+			return fmt.Errorf("-set %s: %w", k, err)
+		}
+		layers[k] = LayerFlag
+	}
+	return nil
+}
+
+// LayerOf tells where name's value came from.
+func LayerOf(name string) Layer {
+	if l, ok := layers[name]; ok {
+		return l
+	}
+	if _, ok := os.LookupEnv(name); ok {
+		return LayerEnv
+	}
+	return LayerDefault
+}
+
+// readFile parses KEY=VALUE lines in file order. Blank lines and lines
+// starting with # are skipped, a leading "export " is allowed, and values
+// may be single or double quoted; double quotes take Go escapes. There is
+// no variable expansion.
+func readFile(path string) ([][2]string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("config file: %w", err)
+	}
+	defer f.Close()
+
+	var (
+		vars [][2]string
+		errs []error
+		n    int
+	)
+	sc := bufio.NewScanner(f)
+	for sc.Scan() {
+		n++
+		line := strings.TrimSpace(sc.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		line = strings.TrimPrefix(line, "export ")
+		k, v, ok := strings.Cut(line, "=")
+		k, v = strings.TrimSpace(k), strings.TrimSpace(v)
+		if !ok || k == "" || strings.ContainsAny(k, " \t") {
+			errs = append(errs, fmt.Errorf("%s:%d: not KEY=VALUE", path, n))
+			continue
+		}
+		switch {
+		case len(v) >= 2 && v[0] == '"' && v[len(v)-1] == '"':
+			u, err := strconv.Unquote(v)
+			if err != nil {
+				errs = append(errs, fmt.Errorf("%s:%d: bad quoted value for %s", path, n, k))
+				continue
+			}
+			v = u
+		case len(v) >= 2 && v[0] == '\'' && v[len(v)-1] == '\'':
+			v = v[1 : len(v)-1]
+		default:
+			// trailing comments only on unquoted values
+			if i := strings.Index(v, " #"); i >= 0 {
+				v = strings.TrimSpace(v[:i])
+			}
+		}
+		vars = append(vars, [2]string{k, v})
+	}
+	if err := sc.Err(); err != nil {
+		return nil, fmt.Errorf("config file %s: %w", path, err)
+	}
+	if len(errs) > 0 {
+		return nil, errors.Join(errs...)
+	}
+	return vars, nil
+}
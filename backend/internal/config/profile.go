@@ -0,0 +1,47 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"strings"
+)
+
+// Profile is the kind of deployment, named by APP_ENV. It picks the
+// database and how strictly the configuration is checked.
+type Profile string
+
+const (
+	ProfileDev     Profile = "development"
+	ProfileStaging Profile = "staging"
+	ProfileProd    Profile = "production"
+)
+
+// ProfileFromEnv reads APP_ENV. Unset means production, so a server
+// started without it gets the strictest checks.
+func ProfileFromEnv() (Profile, error) {
+	switch p := Profile(strings.TrimSpace(os.Getenv("APP_ENV"))); p {
+	case "":
+		return ProfileProd, nil
+	case ProfileDev, ProfileStaging, ProfileProd:
+		return p, nil
+	default:
+		return ProfileProd, fmt.Errorf("APP_ENV must be %s, %s or %s, not %q", ProfileDev, ProfileStaging, ProfileProd, p)
+	}
+}
+
+// DatabaseURLVar names the variable holding the profile's database URL.
+func (p Profile) DatabaseURLVar() string {
+	switch p {
+	case ProfileDev:
+		return "DATABASE_URL_DEV"
+	case ProfileStaging:
+		return "DATABASE_URL_STAGING"
+	default:
+		return "DATABASE_URL_PROD"
+	}
+}
+
+// DatabaseURL returns the profile's database URL, empty if unset.
+func (p Profile) DatabaseURL() string {
+	return strings.TrimSpace(os.Getenv(p.DatabaseURLVar()))
+}
@@ -0,0 +1,424 @@
+package config
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	jwttoken "github.com/diagnosis/interactive-todo/internal/auth/jwt"
+	"github.com/diagnosis/interactive-todo/internal/backup"
+	"github.com/diagnosis/interactive-todo/internal/demo"
+	"github.com/diagnosis/interactive-todo/internal/directory"
+	"github.com/diagnosis/interactive-todo/internal/embedding"
+	"github.com/diagnosis/interactive-todo/internal/logger"
+	realipmiddleware "github.com/diagnosis/interactive-todo/internal/middleware/realip"
+	throttlemiddleware "github.com/diagnosis/interactive-todo/internal/middleware/throttle"
+	"github.com/diagnosis/interactive-todo/internal/secure/fieldcrypt"
+	"github.com/diagnosis/interactive-todo/internal/server"
+	"github.com/diagnosis/interactive-todo/internal/storage"
+	store "github.com/diagnosis/interactive-todo/internal/store/database"
+	"github.com/jackc/pgx/v5"
+)
+
+// dbCheckTimeout bounds the connection attempt of Validate; there are no
+// retries, a database that is still starting counts as unreachable.
+const dbCheckTimeout = 5 * time.Second
+
+// Report is the outcome of Validate.
+type Report struct {
+	Profile Profile
+	// Settings holds every known variable with its effective value.
+	// Secrets are masked.
+	Settings []Setting
+	// Database tells whether the profile's database answered.
+	Database string
+	Errors   []string
+	Warnings []string
+}
+
+type Setting struct {
+	Name    string
+	Value   string
+	Default bool
+	// Layer is where Value came from
+	Layer Layer
+}
+
+// OK reports whether the server would start with this configuration.
+func (r *Report) OK() bool {
+	return len(r.Errors) == 0
+}
+
+// Validate checks the environment the way the API reads it at startup,
+// plus the rules of the profile, and tries the database once. It collects
+// every problem instead of stopping at the first.
+func Validate(ctx context.Context) *Report {
+	r := &Report{}
+	p, err := ProfileFromEnv()
+	r.fail(err)
+	r.Profile = p
+	if os.Getenv("APP_ENV") == "" {
+		r.warnf("APP_ENV is not set; the production database is used, but logging and CORS act as in development")
+	}
+
+	for _, s := range settings(p) {
+		v, ok := os.LookupEnv(s.name)
+		v = strings.TrimSpace(v)
+		switch {
+		case !ok || v == "":
+			r.Settings = append(r.Settings, Setting{Name: s.name, Value: s.def, Default: true, Layer: LayerDefault})
+		case s.mask != nil:
+			r.Settings = append(r.Settings, Setting{Name: s.name, Value: s.mask(v), Layer: LayerOf(s.name)})
+		default:
+			r.Settings = append(r.Settings, Setting{Name: s.name, Value: v, Layer: LayerOf(s.name)})
+		}
+	}
+
+	if v := os.Getenv("PORT"); v != "" {
+		if n, err := strconv.Atoi(v); err != nil || n < 1 || n > 65535 {
+			r.errorf("PORT must be a port number, not %q", v)
+		}
+	}
+	switch mode := os.Getenv("MIGRATE_MODE"); mode {
+	case "", "up", "plan", "verify":
+	default:
+		r.errorf("MIGRATE_MODE must be up, plan or verify, not %q", mode)
+	}
+	r.checkDatabase(ctx, p)
+
+	_, err = jwttoken.ConfigFromEnv()
+	r.fail(err)
+	if v := os.Getenv("BREAK_GLASS_TOKEN"); v != "" {
+		r.fail(jwttoken.CheckSecret("BREAK_GLASS_TOKEN", v))
+	}
+	_, err = store.RetryConfigFromEnv()
+	r.fail(err)
+	_, err = store.PoolWatchFromEnv()
+	r.fail(err)
+	_, err = server.TLSFromEnv()
+	r.fail(err)
+	_, err = server.ListenerFromEnv()
+	r.fail(err)
+	_, err = realipmiddleware.TrustedFromEnv()
+	r.fail(err)
+	throttleCfg, err := throttlemiddleware.ConfigFromEnv()
+	r.fail(err)
+	if err == nil && throttleCfg == nil && p == ProfileProd {
+		r.warnf("THROTTLE_RATE is 0; one user's exports can take every database connection")
+	}
+
+	logCfg, err := logger.ConfigFromEnv()
+	r.fail(err)
+	if err == nil && p == ProfileProd {
+		if logCfg.Format != logger.FormatJSON {
+			r.warnf("LOG_FORMAT is %s; log collectors in production expect json", logCfg.Format)
+		}
+		if !logCfg.Redact {
+			r.warnf("LOG_REDACT is off; emails and tokens will reach the production logs")
+		}
+	}
+	if store.ExplainFromEnv() && p == ProfileProd {
+		r.warnf("DB_EXPLAIN is on in production; every new query costs an extra EXPLAIN")
+	}
+
+	fieldCipher, err := fieldcrypt.FromEnv("FIELD_ENCRYPTION_KEY")
+	if err != nil {
+		r.errorf("FIELD_ENCRYPTION_KEY: %v", err)
+	} else if fieldCipher == nil && p != ProfileDev {
+		r.warnf("FIELD_ENCRYPTION_KEY is not set; teams cannot be made confidential")
+	}
+	backupCfg, err := backup.ConfigFromEnv()
+	r.fail(err)
+	if backupCfg != nil && os.Getenv("BACKUP_ENCRYPTION_KEY") == os.Getenv("FIELD_ENCRYPTION_KEY") {
+		r.warnf("BACKUP_ENCRYPTION_KEY is the same as FIELD_ENCRYPTION_KEY; a leaked backup key would open both")
+	}
+	_, err = embedding.FromEnv()
+	r.fail(err)
+	_, err = directory.ProvidersFromEnv()
+	r.fail(err)
+	demoCfg, err := demo.ConfigFromEnv()
+	r.fail(err)
+	if demoCfg != nil && os.Getenv("DEMO_PASSWORD") == "" {
+		r.warnf("DEMO_MODE is on with the default DEMO_PASSWORD")
+	}
+	_, _, err = storage.AttachmentsFromEnv()
+	r.fail(err)
+	if err == nil && p == ProfileProd && os.Getenv("ATTACHMENTS_S3_BUCKET") == "" {
+		r.warnf("ATTACHMENTS_S3_BUCKET is not set; attachments are kept on local disk, which every instance must share")
+	}
+	if v := os.Getenv("ATTACHMENT_MAX_MB"); v != "" {
+		if n, err := strconv.Atoi(v); err != nil || n < 1 {
+			r.errorf("ATTACHMENT_MAX_MB must be a positive integer")
+		}
+	}
+
+	if v := os.Getenv("TASK_ARCHIVE_AFTER_MONTHS"); v != "" {
+		if n, err := strconv.Atoi(v); err != nil || n < 0 {
+			r.errorf("TASK_ARCHIVE_AFTER_MONTHS must be a non-negative integer")
+		}
+	}
+	if v := os.Getenv("API_LEGACY_SUNSET"); v != "" {
+		if _, err := time.Parse(time.DateOnly, v); err != nil {
+			r.errorf("API_LEGACY_SUNSET must be a date like 2027-04-01")
+		}
+	}
+	r.checkOrigins(p)
+	r.checkPublicBaseURL(p)
+	return r
+}
+
+func (r *Report) checkDatabase(ctx context.Context, p Profile) {
+	name, dsn := p.DatabaseURLVar(), p.DatabaseURL()
+	if dsn == "" {
+		r.errorf("%s is not set", name)
+		r.Database = "not checked"
+		return
+	}
+	cfg, err := pgx.ParseConfig(dsn)
+	if err != nil {
+		// the parse error may quote the password
+		r.errorf("%s is not a valid database URL", name)
+		r.Database = "not checked"
+		return
+	}
+	if cfg.TLSConfig == nil {
+		switch p {
+		case ProfileProd:
+			r.errorf("%s has sslmode=disable; use require or verify-full in production", name)
+		case ProfileStaging:
+			r.warnf("%s has sslmode=disable", name)
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, dbCheckTimeout)
+	defer cancel()
+	conn, err := pgx.ConnectConfig(ctx, cfg)
+	if err != nil {
+		r.errorf("cannot reach the database in %s: %v", name, err)
+		r.Database = "unreachable"
+		return
+	}
+	defer conn.Close(context.Background())
+	var version string
+	if err := conn.QueryRow(ctx, `SHOW server_version`).Scan(&version); err != nil {
+		r.errorf("database in %s does not answer queries: %v", name, err)
+		r.Database = "unreachable"
+		return
+	}
+	r.Database = "reachable, PostgreSQL " + version
+}
+
+func (r *Report) checkOrigins(p Profile) {
+	origins := os.Getenv("ALLOWED_ORIGINS")
+	if origins == "" {
+		if p != ProfileDev {
+			r.warnf("ALLOWED_ORIGINS is not set; browsers may only call the API from http://localhost:5173")
+		}
+		return
+	}
+	for _, o := range strings.Split(origins, ",") {
+		u, err := url.Parse(strings.TrimSpace(o))
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			r.errorf("ALLOWED_ORIGINS: %q is not an origin like https://app.example.com", o)
+			continue
+		}
+		if u.Scheme == "http" && p == ProfileProd {
+			r.warnf("ALLOWED_ORIGINS: %s is not https", o)
+		}
+	}
+}
+
+func (r *Report) checkPublicBaseURL(p Profile) {
+	v := os.Getenv("PUBLIC_BASE_URL")
+	if v == "" {
+		if p != ProfileDev {
+			r.warnf("PUBLIC_BASE_URL is not set; weekly status reports are not sent")
+		}
+		return
+	}
+	u, err := url.Parse(v)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		r.errorf("PUBLIC_BASE_URL: %q is not an absolute URL like https://todo.example.com", v)
+		return
+	}
+	if u.Scheme == "http" && p == ProfileProd {
+		r.warnf("PUBLIC_BASE_URL: %s is not https", v)
+	}
+}
+
+func (r *Report) errorf(format string, args ...any) {
+	r.Errors = append(r.Errors, fmt.Sprintf(format, args...))
+}
+
+func (r *Report) warnf(format string, args ...any) {
+	r.Warnings = append(r.Warnings, fmt.Sprintf(format, args...))
+}
+
+// fail records err, one entry per line: the config readers join all
+// their problems into one error.
+func (r *Report) fail(err error) {
+	if err == nil {
+		return
+	}
+	for _, line := range strings.Split(err.Error(), "\n") {
+		r.Errors = append(r.Errors, line)
+	}
+}
+
+// Write prints the report for a person at a terminal.
+func (r *Report) Write(w io.Writer) error {
+	var b bytes.Buffer
+	fmt.Fprintf(&b, "profile: %s\n\n", r.Profile)
+	tw := tabwriter.NewWriter(&b, 0, 0, 2, ' ', 0)
+	for _, s := range r.Settings {
+		switch {
+		case s.Default && s.Value == "":
+			fmt.Fprintf(tw, "%s\t(unset)\n", s.Name)
+		case s.Default:
+			fmt.Fprintf(tw, "%s\t%s (default)\n", s.Name, s.Value)
+		case s.Layer == LayerFile || s.Layer == LayerFlag:
+			fmt.Fprintf(tw, "%s\t%s (%s)\n", s.Name, s.Value, s.Layer)
+		default:
+			fmt.Fprintf(tw, "%s\t%s\n", s.Name, s.Value)
+		}
+	}
+	tw.Flush()
+	fmt.Fprintf(&b, "\ndatabase: %s\n", r.Database)
+
+	for _, section := range []struct {
+		title string
+		lines []string
+	}{
+		{"warnings", r.Warnings},
+		{"errors", r.Errors},
+	} {
+		if len(section.lines) == 0 {
+			continue
+		}
+		fmt.Fprintf(&b, "\n%s:\n", section.title)
+		for _, l := range section.lines {
+			fmt.Fprintf(&b, "  - %s\n", l)
+		}
+	}
+	if r.OK() {
+		b.WriteString("\nconfiguration is valid\n")
+	} else {
+		fmt.Fprintf(&b, "\nconfiguration is invalid: %d problem(s)\n", len(r.Errors))
+	}
+	_, err := w.Write(b.Bytes())
+	return err
+}
+
+// setting is a variable shown by Validate. def is what applies when it is
+// unset. mask hides secrets; values without one are printed as they are.
+type setting struct {
+	name string
+	def  string
+	mask func(string) string
+}
+
+func settings(p Profile) []setting {
+	// logging keys its defaults off APP_ENV itself
+	logLevel, logSample, logRedact := "debug", "1", "false"
+	if os.Getenv("APP_ENV") == string(ProfileProd) {
+		logLevel, logSample, logRedact = "info", "100", "true"
+	}
+	port := "8080"
+	if os.Getenv("TLS_CERT_FILE") != "" || os.Getenv("TLS_AUTOCERT_DOMAINS") != "" {
+		port = "443"
+	}
+	return []setting{
+		{name: "CONFIG_FILE"},
+		{name: "APP_ENV", def: string(ProfileProd)},
+		{name: "PORT", def: port},
+		{name: "LISTEN_SOCKET"},
+		{name: "LISTEN_SOCKET_MODE", def: "0660"},
+		{name: "TRUSTED_PROXIES", def: "127.0.0.0/8,::1/128"},
+		{name: "THROTTLE_RATE", def: "300"},
+		{name: "THROTTLE_BURST", def: "100"},
+		{name: "TLS_CERT_FILE"},
+		{name: "TLS_KEY_FILE"},
+		{name: "TLS_AUTOCERT_DOMAINS"},
+		{name: "TLS_AUTOCERT_CACHE_DIR", def: "certs"},
+		{name: "TLS_AUTOCERT_EMAIL"},
+		{name: "TLS_REDIRECT_ADDR", def: ":80"},
+		{name: p.DatabaseURLVar(), mask: maskDSN},
+		{name: "MIGRATE_MODE", def: "up"},
+		{name: "DB_CONNECT_ATTEMPTS", def: "10"},
+		{name: "DB_CONNECT_MAX_BACKOFF", def: "30s"},
+		{name: "DB_EXPLAIN", def: "false"},
+		{name: "DB_ACQUIRE_WARN", def: "1s"},
+		{name: "JWT_ACCESS_SECRET", mask: maskSecret},
+		{name: "JWT_REFRESH_SECRET", mask: maskSecret},
+		{name: "JWT_ISSUER", def: "interactive-todo"},
+		{name: "JWT_ACCESS_TTL", def: "15m"},
+		{name: "JWT_REFRESH_TTL", def: "168h"},
+		{name: "BREAK_GLASS_TOKEN", mask: maskSecret},
+		{name: "LOG_LEVEL", def: logLevel},
+		{name: "LOG_FORMAT", def: logger.FormatText},
+		{name: "LOG_DEBUG_SAMPLE", def: logSample},
+		{name: "LOG_REDACT", def: logRedact},
+		{name: "ALLOWED_ORIGINS"},
+		{name: "PUBLIC_BASE_URL"},
+		{name: "FIELD_ENCRYPTION_KEY", mask: maskSecret},
+		{name: "TASK_ARCHIVE_AFTER_MONTHS", def: "12"},
+		{name: "API_LEGACY_SUNSET"},
+		{name: "BACKUP_S3_BUCKET"},
+		{name: "BACKUP_S3_REGION", def: "us-east-1"},
+		{name: "BACKUP_S3_ENDPOINT"},
+		{name: "BACKUP_S3_PREFIX", def: "backups/"},
+		{name: "BACKUP_ENCRYPTION_KEY", mask: maskSecret},
+		{name: "ATTACHMENTS_DIR", def: "attachments"},
+		{name: "ATTACHMENTS_S3_BUCKET"},
+		{name: "ATTACHMENTS_S3_REGION", def: "us-east-1"},
+		{name: "ATTACHMENTS_S3_ENDPOINT"},
+		{name: "ATTACHMENTS_S3_PREFIX", def: "attachments/"},
+		{name: "ATTACHMENT_MAX_MB", def: "25"},
+		{name: "AWS_ACCESS_KEY_ID", mask: maskSecret},
+		{name: "AWS_SECRET_ACCESS_KEY", mask: maskSecret},
+		{name: "AWS_SESSION_TOKEN", mask: maskSecret},
+		{name: "EMBEDDINGS_PROVIDER"},
+		{name: "EMBEDDINGS_URL", def: "https://api.openai.com/v1/embeddings"},
+		{name: "EMBEDDINGS_API_KEY", mask: maskSecret},
+		{name: "EMBEDDINGS_MODEL", def: "text-embedding-3-small"},
+		{name: "EMBEDDINGS_DIMENSIONS", def: "1536"},
+		{name: "DIRECTORY_GOOGLE_CREDENTIALS"},
+		{name: "DIRECTORY_GOOGLE_ADMIN"},
+		{name: "DIRECTORY_AZURE_TENANT_ID"},
+		{name: "DIRECTORY_AZURE_CLIENT_ID"},
+		{name: "DIRECTORY_AZURE_CLIENT_SECRET", mask: maskSecret},
+		{name: "DEMO_MODE", def: "false"},
+		{name: "DEMO_PASSWORD", def: "demo-password"},
+	}
+}
+
+// maskSecret shows only that a secret is set and its length, which is
+// enough to spot a truncated value.
+func maskSecret(v string) string {
+	return fmt.Sprintf("******** (%d characters)", len(v))
+}
+
+// maskDSN shows where a database URL points, rebuilt from the parsed
+// config so no password can slip through.
+func maskDSN(dsn string) string {
+	cfg, err := pgx.ParseConfig(dsn)
+	if err != nil {
+		return "******** (invalid)"
+	}
+	user := cfg.User
+	if cfg.Password != "" {
+		user += ":********"
+	}
+	tls := "tls"
+	if cfg.TLSConfig == nil {
+		tls = "no tls"
+	}
+	return fmt.Sprintf("postgres://%s@%s:%d/%s (%s)", user, cfg.Host, cfg.Port, cfg.Database, tls)
+}
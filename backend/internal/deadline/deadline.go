@@ -0,0 +1,53 @@
+package deadline
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// Reserve is kept back from the request deadline so a handler whose store
+// call ran out of time can still write its error response.
+const Reserve = 250 * time.Millisecond
+
+type ctxKey struct{}
+
+type budget struct {
+	start time.Time
+	limit time.Duration
+}
+
+// Budget returns a context that expires after want, or earlier if the
+// parent's deadline (minus Reserve) comes first. Handlers use it instead of
+// a fixed context.WithTimeout so nested calls never outlive the request.
+func Budget(ctx context.Context, want time.Duration) (context.Context, context.CancelFunc) {
+	limit := want
+	if remaining, ok := Remaining(ctx); ok {
+		limit = max(min(limit, remaining-Reserve), 0)
+	}
+	ctx = context.WithValue(ctx, ctxKey{}, budget{start: time.Now(), limit: limit})
+	return context.WithTimeout(ctx, limit)
+}
+
+// Remaining is the time left before ctx's deadline; ok is false when ctx
+// has none.
+func Remaining(ctx context.Context) (time.Duration, bool) {
+	dl, ok := ctx.Deadline()
+	if !ok {
+		return 0, false
+	}
+	return time.Until(dl), true
+}
+
+// Exceeded reports the budget and time spent when ctx ran out of a budget
+// set by Budget.
+func Exceeded(ctx context.Context) (limit, elapsed time.Duration, ok bool) {
+	if !errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		return 0, 0, false
+	}
+	b, ok := ctx.Value(ctxKey{}).(budget)
+	if !ok {
+		return 0, 0, false
+	}
+	return b.limit, time.Since(b.start), true
+}
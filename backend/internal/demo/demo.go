@@ -0,0 +1,166 @@
+// Package demo runs an install as a public sandbox: it seeds fake teams,
+// users and tasks and wipes them again every ResetInterval, so whatever
+// visitors do is gone within the hour. It only ever wipes an install it
+// seeded itself.
+package demo
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// ResetInterval is how long sandbox data lives.
+const ResetInterval = time.Hour
+
+// ErrNotSandbox is returned when DEMO_MODE is turned on for an install
+// that has real users.
+var ErrNotSandbox = errors.New("the database has users that demo mode did not create; refusing to wipe it")
+
+type Config struct {
+	// Password is shared by every demo account and shown to visitors.
+	Password string
+}
+
+// ConfigFromEnv reads DEMO_MODE and DEMO_PASSWORD. Demo mode is off (nil)
+// unless DEMO_MODE is true.
+func ConfigFromEnv() (*Config, error) {
+	v := strings.TrimSpace(os.Getenv("DEMO_MODE"))
+	if v == "" {
+		return nil, nil
+	}
+	on, err := strconv.ParseBool(v)
+	if err != nil {
+		return nil, fmt.Errorf("DEMO_MODE must be true or false, not %q", v)
+	}
+	if !on {
+		return nil, nil
+	}
+	password := os.Getenv("DEMO_PASSWORD")
+	if password == "" {
+		password = "demo-password"
+	}
+	if len(password) < 8 {
+		return nil, errors.New("DEMO_PASSWORD must be at least 8 characters")
+	}
+	return &Config{Password: password}, nil
+}
+
+// Sandbox seeds and resets the demo data.
+type Sandbox struct {
+	pool *pgxpool.Pool
+	cfg  Config
+	// nextReset is a unix time, 0 until the first Reset
+	nextReset atomic.Int64
+}
+
+func NewSandbox(pool *pgxpool.Pool, cfg Config) *Sandbox {
+	return &Sandbox{pool: pool, cfg: cfg}
+}
+
+// NextReset is when the data will next be wiped; zero before the sandbox
+// was first seeded or checked.
+func (s *Sandbox) NextReset() time.Time {
+	n := s.nextReset.Load()
+	if n == 0 {
+		return time.Time{}
+	}
+	return time.Unix(n, 0).UTC()
+}
+
+// Password is the password of every demo account.
+func (s *Sandbox) Password() string { return s.cfg.Password }
+
+// Accounts are the emails visitors can log in with.
+func (s *Sandbox) Accounts() []string {
+	out := make([]string, len(people))
+	for i, p := range people {
+		out[i] = p.email
+	}
+	return out
+}
+
+// Reset wipes and reseeds the sandbox when it is due, and does nothing
+// otherwise. With several instances, the first one to get there resets
+// and the others see the fresh seed.
+func (s *Sandbox) Reset(ctx context.Context, now time.Time) error {
+	now = now.UTC()
+
+	tx, err := s.pool.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("demo reset: begin: %w", err)
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	// serializes resets across instances until commit
+	if _, err := tx.Exec(ctx, `LOCK TABLE instance_settings IN EXCLUSIVE MODE`); err != nil {
+		return fmt.Errorf("demo reset: lock: %w", err)
+	}
+
+	var (
+		demo    bool
+		setupAt time.Time
+	)
+	err = tx.QueryRow(ctx, `SELECT demo, setup_at FROM instance_settings`).Scan(&demo, &setupAt)
+	switch {
+	case errors.Is(err, pgx.ErrNoRows):
+		var hasUsers bool
+		if err := tx.QueryRow(ctx, `SELECT EXISTS (SELECT 1 FROM users)`).Scan(&hasUsers); err != nil {
+			return fmt.Errorf("demo reset: check users: %w", err)
+		}
+		if hasUsers {
+			return ErrNotSandbox
+		}
+	case err != nil:
+		return fmt.Errorf("demo reset: read settings: %w", err)
+	case !demo:
+		return ErrNotSandbox
+	case now.Before(setupAt.Add(ResetInterval)):
+		s.nextReset.Store(setupAt.Add(ResetInterval).Unix())
+		return nil
+	}
+
+	if err := wipe(ctx, tx); err != nil {
+		return err
+	}
+	if err := seed(ctx, tx, s.cfg.Password, now); err != nil {
+		return err
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("demo reset: commit: %w", err)
+	}
+	s.nextReset.Store(now.Add(ResetInterval).Unix())
+	return nil
+}
+
+// wipe deletes every row visitors could have made. Deleting teams and
+// users cascades to everything under them; attachment files are queued
+// for the purge job by the usual trigger.
+func wipe(ctx context.Context, tx pgx.Tx) error {
+	for _, q := range []string{
+		// held teams and tasks cannot be deleted
+		`DELETE FROM legal_holds`,
+		// a team's active workflow cannot be deleted either
+		`UPDATE team_settings SET workflow_id = NULL WHERE workflow_id IS NOT NULL`,
+		`DELETE FROM teams`,
+		`DELETE FROM announcements`,
+		`DELETE FROM audit_log`,
+		`DELETE FROM ip_allowlist`,
+		`DELETE FROM backup_exports`,
+		`DELETE FROM instance_settings`,
+		`DELETE FROM users`,
+	} {
+		if _, err := tx.Exec(ctx, q); err != nil {
+			return fmt.Errorf("demo reset: %s: %w", q, err)
+		}
+	}
+	return nil
+}
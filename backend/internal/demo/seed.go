@@ -0,0 +1,186 @@
+package demo
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/diagnosis/interactive-todo/api/types"
+	secure "github.com/diagnosis/interactive-todo/internal/secure/password"
+	reminderstore "github.com/diagnosis/interactive-todo/internal/store/reminders"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+const orgName = "Demo workspace"
+
+type person struct {
+	email    string
+	userType types.UserType
+}
+
+// people are the demo accounts; the first owns every team.
+var people = []person{
+	{"alex@demo.example", types.UserTypeTaskManager},
+	{"sam@demo.example", types.UserTypeEmployee},
+	{"jordan@demo.example", types.UserTypeEmployee},
+	{"riley@demo.example", types.UserTypeEmployee},
+}
+
+type demoTask struct {
+	title    string
+	reporter int
+	assignee int
+	// days from the reset; negative is in the past
+	createdDays int
+	dueDays     int
+	status      types.TaskStatus
+	priority    types.TaskPriority
+	estimate    float64
+	labels      []string
+	comment     string
+}
+
+type demoTeam struct {
+	name   string
+	labels []string
+	tasks  []demoTask
+}
+
+var teams = []demoTeam{
+	{
+		name:   "Product",
+		labels: []string{"bug", "feature", "design"},
+		tasks: []demoTask{
+			{"Fix login redirect loop on Safari", 1, 2, -9, -2, types.TaskStatusDone, types.TaskPriorityUrgent, 3, []string{"bug"}, "Reproduced with third-party cookies blocked."},
+			{"Add dark mode to settings page", 0, 1, -8, 5, types.TaskStatusInProgress, types.TaskPriorityNormal, 8, []string{"feature", "design"}, ""},
+			{"Write onboarding checklist copy", 0, 3, -6, 2, types.TaskStatusOpen, types.TaskPriorityLow, 2, nil, ""},
+			{"Export reports as CSV", 2, 2, -5, 9, types.TaskStatusOpen, types.TaskPriorityHigh, 12, []string{"feature"}, "Customers keep asking for this one."},
+			{"Broken image on pricing page", 3, 1, -3, -1, types.TaskStatusOpen, types.TaskPriorityHigh, 1, []string{"bug"}, ""},
+			{"Redesign empty states", 0, 3, -2, 12, types.TaskStatusOpen, types.TaskPriorityNormal, 6, []string{"design"}, ""},
+			{"Drop the legacy beta flag", 1, 0, -12, -4, types.TaskStatusCanceled, types.TaskPriorityLow, 1, nil, ""},
+		},
+	},
+	{
+		name:   "Operations",
+		labels: []string{"infra", "security"},
+		tasks: []demoTask{
+			{"Rotate database credentials", 0, 2, -10, -3, types.TaskStatusDone, types.TaskPriorityHigh, 2, []string{"security"}, ""},
+			{"Set up staging backups", 0, 3, -7, 1, types.TaskStatusInProgress, types.TaskPriorityNormal, 4, []string{"infra"}, "Bucket is created, the job is not scheduled yet."},
+			{"Renew TLS certificates", 2, 2, -4, 6, types.TaskStatusOpen, types.TaskPriorityUrgent, 1, []string{"security", "infra"}, ""},
+			{"Document the on-call handover", 3, 0, -1, 10, types.TaskStatusOpen, types.TaskPriorityLow, 3, nil, ""},
+		},
+	},
+}
+
+// seed writes the demo data; the tables must be empty.
+func seed(ctx context.Context, tx pgx.Tx, password string, now time.Time) error {
+	const (
+		insertSettings = `
+			INSERT INTO instance_settings (id, org_name, demo, setup_at, updated_at)
+			VALUES (TRUE, $1, TRUE, $2, $2)
+		`
+		insertUser = `
+			INSERT INTO users (email, password_hash, user_type, created_at, updated_at)
+			VALUES ($1, $2, $3, $4, $4)
+			RETURNING id
+		`
+		insertTeam = `
+			INSERT INTO teams (name, owner_id, created_at, updated_at)
+			VALUES ($1, $2, $3, $3)
+			RETURNING id
+		`
+		insertMember = `
+			INSERT INTO team_members (team_id, user_id, role, created_at)
+			VALUES ($1, $2, $3, $4)
+		`
+		insertLabels = `
+			INSERT INTO labels (team_id, name, created_at)
+			SELECT $1, n, $3 FROM unnest($2::text[]) AS n
+		`
+		insertTask = `
+			INSERT INTO tasks (team_id, title, reporter_id, assignee_id, due_at, status, priority,
+			                   estimate_hours, assigned_at, created_at, updated_at)
+			VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $9, $10)
+			RETURNING id
+		`
+		insertTaskLabels = `
+			INSERT INTO task_labels (task_id, team_id, label_id, created_at)
+			SELECT $1, $2, l.id, $4
+			FROM labels l
+			WHERE l.team_id = $2 AND l.name = ANY($3::text[])
+		`
+		insertComment = `
+			INSERT INTO task_comments (task_id, author_id, body, created_at)
+			VALUES ($1, $2, $3, $4)
+		`
+	)
+
+	hash, err := secure.HashPassword(password)
+	if err != nil {
+		return fmt.Errorf("demo seed: hash password: %w", err)
+	}
+	if _, err := tx.Exec(ctx, insertSettings, orgName, now); err != nil {
+		return fmt.Errorf("demo seed: settings: %w", err)
+	}
+
+	userIDs := make([]uuid.UUID, len(people))
+	for i, p := range people {
+		if err := tx.QueryRow(ctx, insertUser, p.email, hash, p.userType, now).Scan(&userIDs[i]); err != nil {
+			return fmt.Errorf("demo seed: user %s: %w", p.email, err)
+		}
+	}
+
+	day := 24 * time.Hour
+	for _, tm := range teams {
+		var teamID uuid.UUID
+		if err := tx.QueryRow(ctx, insertTeam, tm.name, userIDs[0], now).Scan(&teamID); err != nil {
+			return fmt.Errorf("demo seed: team %s: %w", tm.name, err)
+		}
+		for i, id := range userIDs {
+			role := types.TeamRoleMember
+			if i == 0 {
+				role = types.TeamRoleOwner
+			}
+			if _, err := tx.Exec(ctx, insertMember, teamID, id, role, now); err != nil {
+				return fmt.Errorf("demo seed: member of %s: %w", tm.name, err)
+			}
+		}
+		if _, err := tx.Exec(ctx, insertLabels, teamID, tm.labels, now); err != nil {
+			return fmt.Errorf("demo seed: labels of %s: %w", tm.name, err)
+		}
+
+		for _, t := range tm.tasks {
+			created := now.Add(time.Duration(t.createdDays) * day)
+			due := now.Add(time.Duration(t.dueDays) * day)
+			updated := created
+			if t.status != types.TaskStatusOpen {
+				// finished halfway between creation and the due date, or now
+				updated = created.Add(due.Sub(created) / 2)
+				if updated.After(now) {
+					updated = now
+				}
+			}
+			var taskID uuid.UUID
+			err := tx.QueryRow(ctx, insertTask, teamID, t.title, userIDs[t.reporter], userIDs[t.assignee],
+				due, t.status, t.priority, t.estimate, created, updated).Scan(&taskID)
+			if err != nil {
+				return fmt.Errorf("demo seed: task %q: %w", t.title, err)
+			}
+			if err := reminderstore.AddDefaults(ctx, tx, []uuid.UUID{taskID}, created); err != nil {
+				return fmt.Errorf("demo seed: task %q: %w", t.title, err)
+			}
+			if len(t.labels) > 0 {
+				if _, err := tx.Exec(ctx, insertTaskLabels, taskID, teamID, t.labels, created); err != nil {
+					return fmt.Errorf("demo seed: labels of task %q: %w", t.title, err)
+				}
+			}
+			if t.comment != "" {
+				if _, err := tx.Exec(ctx, insertComment, taskID, userIDs[t.assignee], t.comment, created.Add(time.Hour)); err != nil {
+					return fmt.Errorf("demo seed: comment on %q: %w", t.title, err)
+				}
+			}
+		}
+	}
+	return nil
+}
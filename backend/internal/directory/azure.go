@@ -0,0 +1,89 @@
+package directory
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+const graphURL = "https://graph.microsoft.com/v1.0/groups/"
+
+// Azure reads groups through Microsoft Graph with an app registration's
+// client credentials. The app needs the GroupMember.Read.All and
+// User.Read.All application permissions.
+type Azure struct {
+	TenantID     string
+	ClientID     string
+	ClientSecret string
+
+	client *http.Client
+	tokens tokenCache
+}
+
+func (a *Azure) Name() string { return ProviderAzure }
+
+// GroupMembers takes the group's object id. Users without a mail address
+// fall back to their user principal name, which is usually the same.
+func (a *Azure) GroupMembers(ctx context.Context, groupID string) ([]string, error) {
+	token, err := a.tokens.get(ctx, a.fetchToken)
+	if err != nil {
+		return nil, err
+	}
+
+	var emails []string
+	next := graphURL + url.PathEscape(groupID) +
+		"/transitiveMembers/microsoft.graph.user?$select=mail,userPrincipalName,accountEnabled&$top=999"
+	for next != "" {
+		var page struct {
+			Value []struct {
+				Mail              string `json:"mail"`
+				UserPrincipalName string `json:"userPrincipalName"`
+				AccountEnabled    *bool  `json:"accountEnabled"`
+			} `json:"value"`
+			NextLink string `json:"@odata.nextLink"`
+		}
+		if err := getJSON(ctx, a.client, next, token, &page); err != nil {
+			return nil, err
+		}
+		for _, u := range page.Value {
+			if u.AccountEnabled != nil && !*u.AccountEnabled {
+				continue
+			}
+			if u.Mail != "" {
+				emails = append(emails, u.Mail)
+			} else {
+				emails = append(emails, u.UserPrincipalName)
+			}
+		}
+		if len(emails) > maxGroupMembers {
+			return nil, fmt.Errorf("%w: group has more than %d members", ErrProvider, maxGroupMembers)
+		}
+		next = page.NextLink
+	}
+	return normalize(emails), nil
+}
+
+func (a *Azure) fetchToken(ctx context.Context) (string, time.Duration, error) {
+	form := url.Values{
+		"grant_type":    {"client_credentials"},
+		"client_id":     {a.ClientID},
+		"client_secret": {a.ClientSecret},
+		"scope":         {"https://graph.microsoft.com/.default"},
+	}
+	tokenURL := "https://login.microsoftonline.com/" + url.PathEscape(a.TenantID) + "/oauth2/v2.0/token"
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, tokenURL, strings.NewReader(form.Encode()))
+	if err != nil {
+		return "", 0, err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	var tok tokenResponse
+	if err := do(a.client, req, &tok); err != nil {
+		return "", 0, err
+	}
+	return tok.AccessToken, time.Duration(tok.ExpiresIn) * time.Second, nil
+}
+
+var _ Provider = (*Azure)(nil)
@@ -0,0 +1,145 @@
+// Package directory keeps team membership in line with groups in an
+// external directory (Google Workspace, Azure AD). A provider lists the
+// email addresses in a group; the Syncer applies that list to the linked
+// team. Providers are off unless their DIRECTORY_* variables are set.
+package directory
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	ProviderGoogle = "google"
+	ProviderAzure  = "azure"
+)
+
+// maxGroupMembers stops paging through a group; a team this large is a
+// misconfigured link, not a team.
+const maxGroupMembers = 20000
+
+var ErrProvider = errors.New("directory provider error")
+
+// Provider lists the members of a group by email.
+type Provider interface {
+	Name() string
+	// GroupMembers returns the email of every active user in the group,
+	// nested groups included.
+	GroupMembers(ctx context.Context, groupID string) ([]string, error)
+}
+
+// ProvidersFromEnv returns the configured providers by name; none is
+// configured when the map is empty. Google needs DIRECTORY_GOOGLE_CREDENTIALS
+// (a service account key file with domain-wide delegation) and
+// DIRECTORY_GOOGLE_ADMIN (the admin it acts as); Azure needs
+// DIRECTORY_AZURE_TENANT_ID, DIRECTORY_AZURE_CLIENT_ID and
+// DIRECTORY_AZURE_CLIENT_SECRET.
+func ProvidersFromEnv() (map[string]Provider, error) {
+	providers := make(map[string]Provider)
+	var errs []error
+
+	if path := strings.TrimSpace(os.Getenv("DIRECTORY_GOOGLE_CREDENTIALS")); path != "" {
+		g, err := newGoogle(path, strings.TrimSpace(os.Getenv("DIRECTORY_GOOGLE_ADMIN")))
+		if err != nil {
+			errs = append(errs, err)
+		} else {
+			providers[ProviderGoogle] = g
+		}
+	}
+
+	if tenant := strings.TrimSpace(os.Getenv("DIRECTORY_AZURE_TENANT_ID")); tenant != "" {
+		a := &Azure{
+			TenantID:     tenant,
+			ClientID:     strings.TrimSpace(os.Getenv("DIRECTORY_AZURE_CLIENT_ID")),
+			ClientSecret: os.Getenv("DIRECTORY_AZURE_CLIENT_SECRET"),
+			client:       &http.Client{Timeout: 30 * time.Second},
+		}
+		if a.ClientID == "" || a.ClientSecret == "" {
+			errs = append(errs, errors.New("DIRECTORY_AZURE_CLIENT_ID and DIRECTORY_AZURE_CLIENT_SECRET are required when DIRECTORY_AZURE_TENANT_ID is set"))
+		} else {
+			providers[ProviderAzure] = a
+		}
+	}
+
+	if len(errs) > 0 {
+		return nil, errors.Join(errs...)
+	}
+	return providers, nil
+}
+
+// normalize lowercases, trims and dedups emails, dropping empty ones.
+func normalize(emails []string) []string {
+	out := make([]string, 0, len(emails))
+	for _, e := range emails {
+		if e = strings.ToLower(strings.TrimSpace(e)); e != "" {
+			out = append(out, e)
+		}
+	}
+	slices.Sort(out)
+	return slices.Compact(out)
+}
+
+// tokenCache holds an access token until shortly before it expires.
+type tokenCache struct {
+	mu      sync.Mutex
+	token   string
+	expires time.Time
+}
+
+func (c *tokenCache) get(ctx context.Context, fetch func(context.Context) (string, time.Duration, error)) (string, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.token != "" && time.Now().Before(c.expires) {
+		return c.token, nil
+	}
+	token, ttl, err := fetch(ctx)
+	if err != nil {
+		return "", err
+	}
+	c.token = token
+	c.expires = time.Now().Add(ttl - time.Minute)
+	return token, nil
+}
+
+// tokenResponse is the OAuth 2 token endpoint reply both providers use.
+type tokenResponse struct {
+	AccessToken string `json:"access_token"`
+	ExpiresIn   int    `json:"expires_in"`
+}
+
+// do sends req and decodes a JSON reply into out.
+func do(client *http.Client, req *http.Request, out any) error {
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrProvider, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("%w: %s: status %d: %s", ErrProvider, req.URL.Host, resp.StatusCode, strings.TrimSpace(string(msg)))
+	}
+	if err := json.NewDecoder(io.LimitReader(resp.Body, 16<<20)).Decode(out); err != nil {
+		return fmt.Errorf("%w: decode response: %v", ErrProvider, err)
+	}
+	return nil
+}
+
+func getJSON(ctx context.Context, client *http.Client, url, token string, out any) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", "Bearer "+token)
+	req.Header.Set("Accept", "application/json")
+	return do(client, req, out)
+}
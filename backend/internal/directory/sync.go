@@ -0,0 +1,69 @@
+package directory
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	directorystore "github.com/diagnosis/interactive-todo/internal/store/directory"
+)
+
+// ErrEmptyGroup stops a sync that would remove every member the sync
+// added; an empty group is far more often a wrong id or a permission
+// problem than a team that really has no one left.
+var ErrEmptyGroup = errors.New("directory group has no members; nothing was changed")
+
+// ErrNotConfigured is a link to a provider this server has no credentials
+// for, e.g. after they were removed.
+var ErrNotConfigured = errors.New("directory provider is not configured on this server")
+
+// Syncer applies directory groups to their linked teams.
+type Syncer struct {
+	store     directorystore.DirectoryStore
+	providers map[string]Provider
+}
+
+func NewSyncer(s directorystore.DirectoryStore, providers map[string]Provider) *Syncer {
+	return &Syncer{store: s, providers: providers}
+}
+
+// Configured reports whether the provider can be used on this server.
+func (s *Syncer) Configured(provider string) bool {
+	_, ok := s.providers[provider]
+	return ok
+}
+
+// Sync reads the link's group and applies it to the team, then records
+// the outcome on the link, failures included.
+func (s *Syncer) Sync(ctx context.Context, link directorystore.Link) (*directorystore.SyncResult, error) {
+	res, err := s.sync(ctx, link)
+	// record the outcome even when shutdown cancelled the sync
+	ctx = context.WithoutCancel(ctx)
+	msg := ""
+	if err != nil {
+		msg = err.Error()
+		if len(msg) > 1000 {
+			msg = msg[:1000]
+		}
+	}
+	if rerr := s.store.Record(ctx, link.TeamID, res, msg, time.Now().UTC()); rerr != nil {
+		return nil, errors.Join(err, rerr)
+	}
+	return res, err
+}
+
+func (s *Syncer) sync(ctx context.Context, link directorystore.Link) (*directorystore.SyncResult, error) {
+	p, ok := s.providers[link.Provider]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrNotConfigured, link.Provider)
+	}
+	emails, err := p.GroupMembers(ctx, link.GroupID)
+	if err != nil {
+		return nil, err
+	}
+	if len(emails) == 0 {
+		return nil, ErrEmptyGroup
+	}
+	return s.store.Apply(ctx, link.TeamID, emails, time.Now().UTC())
+}
@@ -0,0 +1,147 @@
+// Package embedding turns task text into vectors for similarity search.
+// It is off unless EMBEDDINGS_PROVIDER is set, and needs the optional
+// pgvector schema in migrations/optional/task_embeddings.sql.
+package embedding
+
+import (
+	"bytes"
+	"context"
+	"crypto/md5"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Provider computes embeddings. Vectors from different models are not
+// comparable, so Model is stored next to each vector.
+type Provider interface {
+	Model() string
+	Dimensions() int
+	Embed(ctx context.Context, texts []string) ([][]float32, error)
+}
+
+var ErrProvider = errors.New("embedding provider error")
+
+// Text is what gets embedded for a task. ContentHash of the same title and
+// description tells whether a stored vector is still current; it matches
+// md5(title || E'\n' || coalesce(description, ”)) in SQL.
+func Text(title string, description *string) string {
+	if description == nil {
+		return title + "\n"
+	}
+	return title + "\n" + *description
+}
+
+func ContentHash(title string, description *string) string {
+	sum := md5.Sum([]byte(Text(title, description)))
+	return hex.EncodeToString(sum[:])
+}
+
+// FromEnv returns the configured provider, or nil, nil when
+// EMBEDDINGS_PROVIDER is unset. "openai" is any OpenAI-compatible
+// /embeddings endpoint (EMBEDDINGS_URL, EMBEDDINGS_API_KEY,
+// EMBEDDINGS_MODEL, EMBEDDINGS_DIMENSIONS).
+func FromEnv() (Provider, error) {
+	switch name := strings.TrimSpace(os.Getenv("EMBEDDINGS_PROVIDER")); name {
+	case "":
+		return nil, nil
+	case "openai":
+		dims := 1536
+		if v := os.Getenv("EMBEDDINGS_DIMENSIONS"); v != "" {
+			n, err := strconv.Atoi(v)
+			if err != nil || n < 1 || n > 4096 {
+				return nil, errors.New("EMBEDDINGS_DIMENSIONS must be between 1 and 4096")
+			}
+			dims = n
+		}
+		p := &OpenAIProvider{
+			URL:    envOr("EMBEDDINGS_URL", "https://api.openai.com/v1/embeddings"),
+			APIKey: os.Getenv("EMBEDDINGS_API_KEY"),
+			model:  envOr("EMBEDDINGS_MODEL", "text-embedding-3-small"),
+			dims:   dims,
+			client: &http.Client{Timeout: 10 * time.Second},
+		}
+		if p.APIKey == "" {
+			return nil, errors.New("EMBEDDINGS_API_KEY is required for the openai provider")
+		}
+		return p, nil
+	default:
+		return nil, fmt.Errorf("unknown EMBEDDINGS_PROVIDER %q", name)
+	}
+}
+
+func envOr(name, def string) string {
+	if v := strings.TrimSpace(os.Getenv(name)); v != "" {
+		return v
+	}
+	return def
+}
+
+// OpenAIProvider calls an OpenAI-compatible embeddings endpoint.
+type OpenAIProvider struct {
+	URL    string
+	APIKey string
+	model  string
+	dims   int
+	client *http.Client
+}
+
+func (p *OpenAIProvider) Model() string   { return p.model }
+func (p *OpenAIProvider) Dimensions() int { return p.dims }
+
+func (p *OpenAIProvider) Embed(ctx context.Context, texts []string) ([][]float32, error) {
+	body, err := json.Marshal(map[string]any{
+		"model":      p.model,
+		"input":      texts,
+		"dimensions": p.dims,
+	})
+	if err != nil {
+		return nil, err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, p.URL, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+p.APIKey)
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrProvider, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return nil, fmt.Errorf("%w: status %d: %s", ErrProvider, resp.StatusCode, strings.TrimSpace(string(msg)))
+	}
+
+	var out struct {
+		Data []struct {
+			Index     int       `json:"index"`
+			Embedding []float32 `json:"embedding"`
+		} `json:"data"`
+	}
+	if err := json.NewDecoder(io.LimitReader(resp.Body, 64<<20)).Decode(&out); err != nil {
+		return nil, fmt.Errorf("%w: decode response: %v", ErrProvider, err)
+	}
+	if len(out.Data) != len(texts) {
+		return nil, fmt.Errorf("%w: got %d embeddings for %d inputs", ErrProvider, len(out.Data), len(texts))
+	}
+	vecs := make([][]float32, len(texts))
+	for _, d := range out.Data {
+		if d.Index < 0 || d.Index >= len(texts) || len(d.Embedding) != p.dims {
+			return nil, fmt.Errorf("%w: unexpected embedding shape", ErrProvider)
+		}
+		vecs[d.Index] = d.Embedding
+	}
+	return vecs, nil
+}
+
+var _ Provider = (*OpenAIProvider)(nil)
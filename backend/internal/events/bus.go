@@ -0,0 +1,157 @@
+// Package events is the in-process bus task changes are published on.
+// Handlers run on a single worker, off the request path, in the order
+// events were published.
+package events
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/diagnosis/interactive-todo/internal/logger"
+	"github.com/google/uuid"
+)
+
+type Type string
+
+const (
+	TaskCreated       Type = "task.created"
+	TaskAssigned      Type = "task.assigned"
+	TaskStatusChanged Type = "task.status_changed"
+	TaskLabelAdded    Type = "task.label_added"
+	// TaskDueSoon is published by the automation job for one recipe at a
+	// time; RecipeID says which.
+	TaskDueSoon Type = "task.due_soon"
+	// TaskReminder is published by the reminder job for an in_app
+	// reminder; only UserID, the assignee, is shown it.
+	TaskReminder Type = "task.reminder"
+)
+
+// Event is something that happened to a task.
+type Event struct {
+	Type   Type
+	TeamID uuid.UUID
+	TaskID uuid.UUID
+	// ActorID is nil when the system or a recipe made the change.
+	ActorID *uuid.UUID
+	// Status is the new status for TaskStatusChanged, From the old one.
+	Status string
+	From   string
+	// Label is the label name for TaskLabelAdded.
+	Label    string
+	RecipeID *uuid.UUID
+	// UserID and DueAt are set on TaskReminder
+	UserID *uuid.UUID
+	DueAt  *time.Time
+	// Cause lists the recipes whose actions led to this event, oldest
+	// first. It is empty for changes made by people.
+	Cause []uuid.UUID
+	At    time.Time
+}
+
+// CausedBy reports whether recipeID is already in the event's chain.
+func (e Event) CausedBy(recipeID uuid.UUID) bool {
+	for _, id := range e.Cause {
+		if id == recipeID {
+			return true
+		}
+	}
+	return false
+}
+
+type Handler func(ctx context.Context, e Event)
+
+// Publisher is what handlers and jobs need from the bus.
+type Publisher interface {
+	Publish(ctx context.Context, e Event)
+}
+
+type Bus struct {
+	mu       sync.RWMutex
+	handlers map[Type][]Handler
+	queue    chan Event
+	cancel   context.CancelFunc
+	wg       sync.WaitGroup
+}
+
+// NewBus buffers up to size events; when the worker falls that far behind,
+// Publish drops events rather than block a request.
+func NewBus(size int) *Bus {
+	return &Bus{handlers: map[Type][]Handler{}, queue: make(chan Event, size)}
+}
+
+// Subscribe registers h for events of type t; it must be called before
+// Start.
+func (b *Bus) Subscribe(t Type, h Handler) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.handlers[t] = append(b.handlers[t], h)
+}
+
+func (b *Bus) Publish(ctx context.Context, e Event) {
+	if e.At.IsZero() {
+		e.At = time.Now().UTC()
+	}
+	select {
+	case b.queue <- e:
+	default:
+		logger.Warn(ctx, "event bus full, event dropped", "type", e.Type, "task_id", e.TaskID)
+	}
+}
+
+func (b *Bus) Start(ctx context.Context) {
+	ctx, b.cancel = context.WithCancel(ctx)
+	b.wg.Add(1)
+	go b.loop(ctx)
+	logger.Info(ctx, "event bus started")
+}
+
+// Stop delivers what is already queued, then returns.
+func (b *Bus) Stop() {
+	if b.cancel != nil {
+		b.cancel()
+	}
+	b.wg.Wait()
+}
+
+func (b *Bus) loop(ctx context.Context) {
+	defer b.wg.Done()
+	for {
+		select {
+		case e := <-b.queue:
+			b.deliver(ctx, e)
+		case <-ctx.Done():
+			// handlers still get a live context for the drain
+			drain := context.WithoutCancel(ctx)
+			for {
+				select {
+				case e := <-b.queue:
+					b.deliver(drain, e)
+				default:
+					return
+				}
+			}
+		}
+	}
+}
+
+func (b *Bus) deliver(ctx context.Context, e Event) {
+	b.mu.RLock()
+	handlers := b.handlers[e.Type]
+	b.mu.RUnlock()
+
+	for _, h := range handlers {
+		func() {
+			defer func() {
+				if rec := recover(); rec != nil {
+					logger.Error(ctx, "event handler panicked", "type", e.Type, "task_id", e.TaskID, "panic", rec)
+				}
+			}()
+			hctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+			defer cancel()
+			h(hctx, e)
+		}()
+	}
+}
+
+var _ Publisher = (*Bus)(nil)
@@ -0,0 +1,312 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/diagnosis/interactive-todo/api/types"
+	"github.com/diagnosis/interactive-todo/internal/apperror"
+	"github.com/diagnosis/interactive-todo/internal/deadline"
+	"github.com/diagnosis/interactive-todo/internal/events"
+	"github.com/diagnosis/interactive-todo/internal/helper"
+	"github.com/diagnosis/interactive-todo/internal/logger"
+	middleware "github.com/diagnosis/interactive-todo/internal/middleware/auth"
+	auditstore "github.com/diagnosis/interactive-todo/internal/store/audit"
+	backupstore "github.com/diagnosis/interactive-todo/internal/store/backups"
+	dbstore "github.com/diagnosis/interactive-todo/internal/store/database"
+	allowliststore "github.com/diagnosis/interactive-todo/internal/store/ipallowlist"
+	legalholdstore "github.com/diagnosis/interactive-todo/internal/store/legalholds"
+	metricsstore "github.com/diagnosis/interactive-todo/internal/store/metrics"
+	mutestore "github.com/diagnosis/interactive-todo/internal/store/mutes"
+	provisioningstore "github.com/diagnosis/interactive-todo/internal/store/provisioning"
+	taskstore "github.com/diagnosis/interactive-todo/internal/store/tasks"
+	usagestore "github.com/diagnosis/interactive-todo/internal/store/usage"
+	userstore "github.com/diagnosis/interactive-todo/internal/store/users"
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type AdminHandler struct {
+	userStore    userstore.UserStore
+	muteStore    mutestore.MuteStore
+	auditStore   auditstore.AuditStore
+	metricsStore metricsstore.MetricsStore
+	holdStore    legalholdstore.LegalHoldStore
+	ipStore      allowliststore.IPAllowlistStore
+	taskStore    taskstore.TaskStore
+	usageStore   usagestore.UsageStore
+	backupStore  backupstore.BackupStore
+	// provisioningStore backs the PUT-by-external-id endpoints
+	provisioningStore provisioningstore.ProvisioningStore
+	events            events.Publisher
+	breaker           *dbstore.Breaker
+	pool              *pgxpool.Pool
+	poolWatch         *dbstore.PoolWatch
+	// backupsEnabled is false when no backup bucket is configured
+	backupsEnabled bool
+}
+
+func NewAdminHandler(
+	us userstore.UserStore,
+	ms mutestore.MuteStore,
+	as auditstore.AuditStore,
+	mts metricsstore.MetricsStore,
+	lhs legalholdstore.LegalHoldStore,
+	ips allowliststore.IPAllowlistStore,
+	ts taskstore.TaskStore,
+	uss usagestore.UsageStore,
+	bs backupstore.BackupStore,
+	ps provisioningstore.ProvisioningStore,
+	pub events.Publisher,
+	breaker *dbstore.Breaker,
+	pool *pgxpool.Pool,
+	poolWatch *dbstore.PoolWatch,
+	backupsEnabled bool,
+) *AdminHandler {
+	return &AdminHandler{
+		userStore:         us,
+		muteStore:         ms,
+		auditStore:        as,
+		metricsStore:      mts,
+		holdStore:         lhs,
+		ipStore:           ips,
+		taskStore:         ts,
+		usageStore:        uss,
+		backupStore:       bs,
+		provisioningStore: ps,
+		events:            pub,
+		breaker:           breaker,
+		pool:              pool,
+		poolWatch:         poolWatch,
+		backupsEnabled:    backupsEnabled,
+	}
+}
+
+// =====================
+//  Dashboard metrics
+// =====================
+
+func (h *AdminHandler) MetricsSummary(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := deadline.Budget(r.Context(), 5*time.Second)
+	defer cancel()
+
+	if _, ok := h.requireAdmin(ctx, w, r); !ok {
+		return
+	}
+
+	now := time.Now().UTC()
+
+	users, err := h.metricsStore.CountUsers(ctx)
+	if err != nil {
+		logger.Error(ctx, "metrics summary: count users failed", "err", err)
+		helper.RespondError(w, r, apperror.InternalError("internal error", err))
+		return
+	}
+	sessions, err := h.metricsStore.CountActiveSessions(ctx, now)
+	if err != nil {
+		logger.Error(ctx, "metrics summary: count sessions failed", "err", err)
+		helper.RespondError(w, r, apperror.InternalError("internal error", err))
+		return
+	}
+	backlog, err := h.metricsStore.JobBacklog(ctx, now)
+	if err != nil {
+		logger.Error(ctx, "metrics summary: job backlog failed", "err", err)
+		helper.RespondError(w, r, apperror.InternalError("internal error", err))
+		return
+	}
+
+	helper.RespondJSON(w, r, http.StatusOK, types.MetricsSummary{
+		Users:          users,
+		ActiveSessions: sessions,
+		JobBacklog:     backlog,
+		DBBreaker:      h.breaker.Stats(),
+		DBPool:         h.poolWatch.Stats(h.pool.Stat()),
+		Requests:       helper.Outcomes(),
+		GeneratedAt:    now,
+	})
+}
+
+func (h *AdminHandler) MetricsTasksPerDay(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := deadline.Budget(r.Context(), 5*time.Second)
+	defer cancel()
+
+	if _, ok := h.requireAdmin(ctx, w, r); !ok {
+		return
+	}
+
+	days, ok := parseDays(w, r, 30)
+	if !ok {
+		return
+	}
+	since := time.Now().UTC().AddDate(0, 0, -(days - 1))
+
+	counts, err := h.metricsStore.TasksPerDay(ctx, since)
+	if err != nil {
+		logger.Error(ctx, "metrics tasks per day: store error", "err", err)
+		helper.RespondError(w, r, apperror.InternalError("internal error", err))
+		return
+	}
+
+	helper.RespondJSON(w, r, http.StatusOK, types.TasksPerDayResponse{Days: days, Counts: counts})
+}
+
+func (h *AdminHandler) MetricsTopTeams(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := deadline.Budget(r.Context(), 5*time.Second)
+	defer cancel()
+
+	if _, ok := h.requireAdmin(ctx, w, r); !ok {
+		return
+	}
+
+	days, ok := parseDays(w, r, 7)
+	if !ok {
+		return
+	}
+	limit := 10
+	if v := r.URL.Query().Get("limit"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 || n > 100 {
+			helper.RespondError(w, r, apperror.BadRequest("limit must be between 1 and 100"))
+			return
+		}
+		limit = n
+	}
+
+	teams, err := h.metricsStore.TopTeamsByActivity(ctx, time.Now().UTC().AddDate(0, 0, -days), limit)
+	if err != nil {
+		logger.Error(ctx, "metrics top teams: store error", "err", err)
+		helper.RespondError(w, r, apperror.InternalError("internal error", err))
+		return
+	}
+
+	helper.RespondJSON(w, r, http.StatusOK, types.TopTeamsResponse{Days: days, Teams: teams})
+}
+
+// =====================
+//  Audit log
+// =====================
+
+func (h *AdminHandler) ListAuditLog(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := deadline.Budget(r.Context(), 5*time.Second)
+	defer cancel()
+
+	adminID, ok := h.requireAdmin(ctx, w, r)
+	if !ok {
+		return
+	}
+
+	limit := 100
+	if v := r.URL.Query().Get("limit"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 || n > 500 {
+			helper.RespondError(w, r, apperror.BadRequest("limit must be between 1 and 500"))
+			return
+		}
+		limit = n
+	}
+	action := auditstore.Action(r.URL.Query().Get("action"))
+
+	entries, err := h.auditStore.List(ctx, action, limit)
+	if err != nil {
+		logger.Error(ctx, "list audit log: store error", "err", err)
+		helper.RespondError(w, r, apperror.InternalError("internal error", err))
+		return
+	}
+
+	logger.Info(ctx, "list audit log: success", "admin_id", adminID, "count", len(entries))
+	helper.RespondJSON(w, r, http.StatusOK, types.AuditLogResponse{Entries: entries})
+}
+
+// =====================
+//  Lift spam mute
+// =====================
+
+func (h *AdminHandler) LiftMute(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := deadline.Budget(r.Context(), 5*time.Second)
+	defer cancel()
+
+	adminID, ok := h.requireAdmin(ctx, w, r)
+	if !ok {
+		return
+	}
+
+	idStr := chi.URLParam(r, "user_id")
+	userID, err := uuid.Parse(idStr)
+	if err != nil {
+		logger.Error(ctx, "lift mute: bad id", "id", idStr, "err", err)
+		helper.RespondError(w, r, apperror.BadRequest("bad id"))
+		return
+	}
+
+	removed, err := h.muteStore.Unmute(ctx, userID)
+	if err != nil {
+		logger.Error(ctx, "lift mute: store error", "err", err)
+		helper.RespondError(w, r, apperror.InternalError("internal error", err))
+		return
+	}
+	if !removed {
+		helper.RespondError(w, r, apperror.NotFound("user is not muted"))
+		return
+	}
+
+	if err := h.auditStore.Record(ctx, auditstore.Entry{
+		ActorID:    &adminID,
+		Action:     auditstore.ActionUserUnmuted,
+		TargetType: auditstore.TargetUser,
+		TargetID:   &userID,
+		IP:         helper.GetClientIP(r),
+		CreatedAt:  time.Now().UTC(),
+	}); err != nil {
+		logger.Error(ctx, "lift mute: audit failed", "err", err)
+	}
+
+	logger.Info(ctx, "lift mute: success", "admin_id", adminID, "user_id", userID)
+	helper.RespondMessage(w, r, http.StatusOK, "mute lifted")
+}
+
+// =====================
+//  Helpers
+// =====================
+
+func parseDays(w http.ResponseWriter, r *http.Request, def int) (int, bool) {
+	v := r.URL.Query().Get("days")
+	if v == "" {
+		return def, true
+	}
+	n, err := strconv.Atoi(v)
+	if err != nil || n < 1 || n > 365 {
+		helper.RespondError(w, r, apperror.BadRequest("days must be between 1 and 365"))
+		return 0, false
+	}
+	return n, true
+}
+
+// requireAdmin re-reads the caller so a demoted admin loses access immediately
+func (h *AdminHandler) requireAdmin(ctx context.Context, w http.ResponseWriter, r *http.Request) (uuid.UUID, bool) {
+	userID, ok := middleware.GetUserIDFromContext(ctx)
+	if !ok {
+		helper.RespondError(w, r, apperror.Unauthorized("unauthorized"))
+		return uuid.Nil, false
+	}
+
+	user, err := h.userStore.GetUserByID(ctx, userID)
+	if err != nil {
+		if errors.Is(err, userstore.ErrNotFound) {
+			helper.RespondError(w, r, apperror.Unauthorized("unauthorized"))
+			return uuid.Nil, false
+		}
+		logger.Error(ctx, "admin: get user failed", "err", err)
+		helper.RespondError(w, r, apperror.InternalError("internal error", err))
+		return uuid.Nil, false
+	}
+	if user.UserType != userstore.TypeAdmin {
+		logger.Info(ctx, "admin: forbidden", "user_id", userID)
+		helper.RespondError(w, r, apperror.Forbidden("admin only"))
+		return uuid.Nil, false
+	}
+	return userID, true
+}
@@ -0,0 +1,187 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/diagnosis/interactive-todo/api/types"
+	"github.com/diagnosis/interactive-todo/internal/apperror"
+	"github.com/diagnosis/interactive-todo/internal/deadline"
+	"github.com/diagnosis/interactive-todo/internal/events"
+	"github.com/diagnosis/interactive-todo/internal/helper"
+	"github.com/diagnosis/interactive-todo/internal/labelrules"
+	"github.com/diagnosis/interactive-todo/internal/logger"
+	auditstore "github.com/diagnosis/interactive-todo/internal/store/audit"
+	taskstore "github.com/diagnosis/interactive-todo/internal/store/tasks"
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+)
+
+// =====================
+//  Announcements
+// =====================
+
+// one insert per team
+const announcementBudget = 30 * time.Second
+
+// CreateAnnouncement sends a task to every team at once. Each team's copy
+// is reported by the admin and assigned to the team owner, who can hand it
+// on like any other task.
+func (h *AdminHandler) CreateAnnouncement(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := deadline.Budget(r.Context(), announcementBudget)
+	defer cancel()
+
+	adminID, ok := h.requireAdmin(ctx, w, r)
+	if !ok {
+		return
+	}
+
+	r.Body = http.MaxBytesReader(w, r.Body, 1<<20)
+	defer r.Body.Close()
+
+	var in types.CreateAnnouncementRequest
+	dec := json.NewDecoder(r.Body)
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&in); err != nil {
+		logger.Error(ctx, "create announcement: bad json", "err", err)
+		helper.RespondError(w, r, apperror.BadRequest("invalid request body"))
+		return
+	}
+	if in.DueAt.IsZero() {
+		helper.RespondError(w, r, apperror.BadRequest("due_at is required"))
+		return
+	}
+	priority := types.TaskPriorityNormal
+	if in.Priority != nil {
+		if !labelrules.ValidPriority(*in.Priority) {
+			helper.RespondError(w, r, apperror.BadRequest("priority must be low, normal, high or urgent"))
+			return
+		}
+		priority = *in.Priority
+	}
+
+	now := time.Now().UTC()
+	a, tasks, err := h.taskStore.CreateAnnouncement(ctx, taskstore.NewAnnouncement{
+		Title:       in.Title,
+		Description: in.Description,
+		DueAt:       in.DueAt,
+		Priority:    priority,
+	}, adminID, now)
+	if err != nil {
+		switch {
+		case errors.Is(err, taskstore.ErrInvalidInput):
+			helper.RespondError(w, r, apperror.BadRequest(err.Error()))
+		case errors.Is(err, taskstore.ErrEncryptionUnavailable):
+			helper.RespondError(w, r, apperror.ServiceUnavailable("a confidential team needs the field encryption key, which is not configured"))
+		default:
+			logger.Error(ctx, "create announcement: store error", "err", err)
+			helper.RespondError(w, r, apperror.InternalError("internal error", err))
+		}
+		return
+	}
+
+	for _, t := range tasks {
+		h.events.Publish(ctx, events.Event{
+			Type:    events.TaskCreated,
+			TeamID:  t.TeamID,
+			TaskID:  t.ID,
+			ActorID: &adminID,
+			Status:  string(t.Status),
+			At:      now,
+		})
+	}
+
+	if err := h.auditStore.Record(ctx, auditstore.Entry{
+		ActorID:    &adminID,
+		Action:     auditstore.ActionAnnouncementSent,
+		TargetType: auditstore.TargetAnnouncement,
+		TargetID:   &a.ID,
+		Metadata: map[string]any{
+			"title": a.Title,
+			"teams": a.Teams,
+		},
+		IP:        helper.GetClientIP(r),
+		CreatedAt: now,
+	}); err != nil {
+		logger.Error(ctx, "create announcement: audit failed", "err", err)
+	}
+
+	logger.Info(ctx, "create announcement: success", "admin_id", adminID, "announcement_id", a.ID, "teams", a.Teams)
+	helper.RespondJSON(w, r, http.StatusCreated, a)
+}
+
+func (h *AdminHandler) ListAnnouncements(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := deadline.Budget(r.Context(), 5*time.Second)
+	defer cancel()
+
+	if _, ok := h.requireAdmin(ctx, w, r); !ok {
+		return
+	}
+
+	limit, offset := 50, 0
+	q := r.URL.Query()
+	if v := q.Get("limit"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 || n > 200 {
+			helper.RespondError(w, r, apperror.BadRequest("limit must be between 1 and 200"))
+			return
+		}
+		limit = n
+	}
+	if v := q.Get("offset"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 0 {
+			helper.RespondError(w, r, apperror.BadRequest("offset must be a non-negative integer"))
+			return
+		}
+		offset = n
+	}
+
+	// one extra row tells whether another page exists
+	list, err := h.taskStore.ListAnnouncements(ctx, limit+1, offset, time.Now().UTC())
+	if err != nil {
+		logger.Error(ctx, "list announcements: store error", "err", err)
+		helper.RespondError(w, r, apperror.InternalError("internal error", err))
+		return
+	}
+
+	resp := types.AnnouncementListResponse{Announcements: list}
+	if len(list) > limit {
+		resp.Announcements = list[:limit]
+		n := offset + limit
+		resp.NextOffset = &n
+	}
+	helper.RespondJSON(w, r, http.StatusOK, resp)
+}
+
+// GetAnnouncement is the completion dashboard: the totals and where each
+// team's copy stands.
+func (h *AdminHandler) GetAnnouncement(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := deadline.Budget(r.Context(), 5*time.Second)
+	defer cancel()
+
+	if _, ok := h.requireAdmin(ctx, w, r); !ok {
+		return
+	}
+
+	id, err := uuid.Parse(chi.URLParam(r, "announcement_id"))
+	if err != nil {
+		helper.RespondError(w, r, apperror.BadRequest("invalid announcement id"))
+		return
+	}
+
+	a, teams, err := h.taskStore.GetAnnouncement(ctx, id, time.Now().UTC())
+	if err != nil {
+		if errors.Is(err, taskstore.ErrAnnouncementNotFound) {
+			helper.RespondError(w, r, apperror.NotFound("announcement not found"))
+			return
+		}
+		logger.Error(ctx, "get announcement: store error", "err", err)
+		helper.RespondError(w, r, apperror.InternalError("internal error", err))
+		return
+	}
+	helper.RespondJSON(w, r, http.StatusOK, types.AnnouncementResponse{Announcement: a, Teams: teams})
+}
@@ -0,0 +1,165 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/diagnosis/interactive-todo/api/types"
+	"github.com/diagnosis/interactive-todo/internal/apperror"
+	"github.com/diagnosis/interactive-todo/internal/deadline"
+	"github.com/diagnosis/interactive-todo/internal/helper"
+	"github.com/diagnosis/interactive-todo/internal/logger"
+	auditstore "github.com/diagnosis/interactive-todo/internal/store/audit"
+	backupstore "github.com/diagnosis/interactive-todo/internal/store/backups"
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+)
+
+// =====================
+//  Backups
+// =====================
+
+// RequestBackup queues an export of one team ({"team_id": ...}) or of the
+// whole install (empty body). A job picks it up within a minute; poll
+// GetBackup for the outcome.
+func (h *AdminHandler) RequestBackup(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := deadline.Budget(r.Context(), 5*time.Second)
+	defer cancel()
+
+	adminID, ok := h.requireAdmin(ctx, w, r)
+	if !ok {
+		return
+	}
+	if !h.backupsEnabled {
+		helper.RespondError(w, r, apperror.ServiceUnavailable("backups are not configured"))
+		return
+	}
+
+	r.Body = http.MaxBytesReader(w, r.Body, 1<<20)
+	defer r.Body.Close()
+
+	var in struct {
+		TeamID *uuid.UUID `json:"team_id"`
+	}
+	dec := json.NewDecoder(r.Body)
+	dec.DisallowUnknownFields()
+
+	if err := dec.Decode(&in); err != nil && !errors.Is(err, io.EOF) {
+		logger.Error(ctx, "request backup: bad json", "err", err)
+		helper.RespondError(w, r, apperror.BadRequest("invalid request body"))
+		return
+	}
+
+	now := time.Now().UTC()
+	exp, err := h.backupStore.Request(ctx, in.TeamID, adminID, now)
+	if err != nil {
+		switch {
+		case errors.Is(err, backupstore.ErrTeamNotFound):
+			helper.RespondError(w, r, apperror.NotFound("team not found"))
+		case errors.Is(err, backupstore.ErrExportInProgress):
+			helper.RespondError(w, r, apperror.Conflict("an export of this scope is already pending or running"))
+		default:
+			logger.Error(ctx, "request backup: store error", "err", err)
+			helper.RespondError(w, r, apperror.InternalError("internal error", err))
+		}
+		return
+	}
+
+	if err := h.auditStore.Record(ctx, auditstore.Entry{
+		ActorID:    &adminID,
+		Action:     auditstore.ActionBackupRequested,
+		TargetType: auditstore.TargetBackup,
+		TargetID:   &exp.ID,
+		TeamID:     exp.TeamID,
+		IP:         helper.GetClientIP(r),
+		CreatedAt:  now,
+	}); err != nil {
+		logger.Error(ctx, "request backup: audit failed", "err", err)
+	}
+
+	logger.Info(ctx, "request backup: queued", "admin_id", adminID, "export_id", exp.ID, "team_id", exp.TeamID)
+	helper.RespondJSON(w, r, http.StatusAccepted, exp)
+}
+
+func (h *AdminHandler) ListBackups(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := deadline.Budget(r.Context(), 5*time.Second)
+	defer cancel()
+
+	if _, ok := h.requireAdmin(ctx, w, r); !ok {
+		return
+	}
+
+	limit, offset := 50, 0
+	q := r.URL.Query()
+	if v := q.Get("limit"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 || n > 200 {
+			helper.RespondError(w, r, apperror.BadRequest("limit must be between 1 and 200"))
+			return
+		}
+		limit = n
+	}
+	if v := q.Get("offset"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 0 {
+			helper.RespondError(w, r, apperror.BadRequest("offset must be a non-negative integer"))
+			return
+		}
+		offset = n
+	}
+
+	// one extra row tells whether another page exists
+	exports, err := h.backupStore.List(ctx, limit+1, offset)
+	if err != nil {
+		logger.Error(ctx, "list backups: store error", "err", err)
+		helper.RespondError(w, r, apperror.InternalError("internal error", err))
+		return
+	}
+
+	var nextOffset *int
+	if len(exports) > limit {
+		exports = exports[:limit]
+		n := offset + limit
+		nextOffset = &n
+	}
+
+	helper.RespondJSON(w, r, http.StatusOK, types.BackupListResponse{
+		Enabled:    h.backupsEnabled,
+		Exports:    exports,
+		NextOffset: nextOffset,
+	})
+}
+
+func (h *AdminHandler) GetBackup(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := deadline.Budget(r.Context(), 5*time.Second)
+	defer cancel()
+
+	if _, ok := h.requireAdmin(ctx, w, r); !ok {
+		return
+	}
+
+	idStr := chi.URLParam(r, "export_id")
+	exportID, err := uuid.Parse(idStr)
+	if err != nil {
+		logger.Error(ctx, "get backup: bad id", "id", idStr, "err", err)
+		helper.RespondError(w, r, apperror.BadRequest("bad id"))
+		return
+	}
+
+	exp, err := h.backupStore.Get(ctx, exportID)
+	if err != nil {
+		if errors.Is(err, backupstore.ErrExportNotFound) {
+			helper.RespondError(w, r, apperror.NotFound("backup export not found"))
+			return
+		}
+		logger.Error(ctx, "get backup: store error", "err", err)
+		helper.RespondError(w, r, apperror.InternalError("internal error", err))
+		return
+	}
+
+	helper.RespondJSON(w, r, http.StatusOK, exp)
+}
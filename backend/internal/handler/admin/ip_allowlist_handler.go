@@ -0,0 +1,177 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/netip"
+	"strings"
+	"time"
+
+	"github.com/diagnosis/interactive-todo/api/types"
+	"github.com/diagnosis/interactive-todo/internal/apperror"
+	"github.com/diagnosis/interactive-todo/internal/deadline"
+	"github.com/diagnosis/interactive-todo/internal/helper"
+	"github.com/diagnosis/interactive-todo/internal/logger"
+	auditstore "github.com/diagnosis/interactive-todo/internal/store/audit"
+	allowliststore "github.com/diagnosis/interactive-todo/internal/store/ipallowlist"
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+)
+
+// =====================
+//  IP allowlist
+// =====================
+
+func (h *AdminHandler) ListIPAllowlist(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := deadline.Budget(r.Context(), 5*time.Second)
+	defer cancel()
+
+	if _, ok := h.requireAdmin(ctx, w, r); !ok {
+		return
+	}
+
+	entries, err := h.ipStore.List(ctx)
+	if err != nil {
+		logger.Error(ctx, "list ip allowlist: store error", "err", err)
+		helper.RespondError(w, r, apperror.InternalError("internal error", err))
+		return
+	}
+
+	helper.RespondJSON(w, r, http.StatusOK, types.IPAllowlistResponse{Entries: entries})
+}
+
+// AddIPAllowlistEntry refuses entries that would lock the calling admin out:
+// once the list is non-empty, only listed ranges get through.
+func (h *AdminHandler) AddIPAllowlistEntry(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := deadline.Budget(r.Context(), 5*time.Second)
+	defer cancel()
+
+	adminID, ok := h.requireAdmin(ctx, w, r)
+	if !ok {
+		return
+	}
+
+	r.Body = http.MaxBytesReader(w, r.Body, 1<<20)
+	defer r.Body.Close()
+
+	var in struct {
+		CIDR string `json:"cidr"`
+		Note string `json:"note"`
+	}
+	dec := json.NewDecoder(r.Body)
+	dec.DisallowUnknownFields()
+
+	if err := dec.Decode(&in); err != nil {
+		logger.Error(ctx, "add ip allowlist entry: bad json", "err", err)
+		helper.RespondError(w, r, apperror.BadRequest("invalid request body"))
+		return
+	}
+	prefix, err := allowliststore.ParseCIDR(in.CIDR)
+	if err != nil {
+		helper.RespondError(w, r, apperror.BadRequest("cidr must be a valid CIDR range or IP address"))
+		return
+	}
+	in.Note = strings.TrimSpace(in.Note)
+	if len(in.Note) > 200 {
+		helper.RespondError(w, r, apperror.BadRequest("note too long (max 200 chars)"))
+		return
+	}
+
+	existing, err := h.ipStore.List(ctx)
+	if err != nil {
+		logger.Error(ctx, "add ip allowlist entry: list failed", "err", err)
+		helper.RespondError(w, r, apperror.InternalError("internal error", err))
+		return
+	}
+	after := append(allowliststore.Prefixes(existing), prefix)
+	if !allowliststore.Allows(after, helper.GetClientIP(r)) {
+		helper.RespondError(w, r, apperror.Conflict("this change would block your current IP address"))
+		return
+	}
+
+	entry, err := h.ipStore.Add(ctx, prefix, in.Note, adminID, time.Now().UTC())
+	if err != nil {
+		if errors.Is(err, allowliststore.ErrEntryExists) {
+			helper.RespondError(w, r, apperror.Conflict("cidr already in the allowlist"))
+			return
+		}
+		logger.Error(ctx, "add ip allowlist entry: store error", "err", err)
+		helper.RespondError(w, r, apperror.InternalError("internal error", err))
+		return
+	}
+
+	h.recordAllowlistChange(ctx, r, auditstore.ActionIPAllowlistAdded, adminID, entry)
+
+	logger.Info(ctx, "add ip allowlist entry: success", "admin_id", adminID, "cidr", entry.CIDR)
+	helper.RespondJSON(w, r, http.StatusCreated, entry)
+}
+
+func (h *AdminHandler) RemoveIPAllowlistEntry(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := deadline.Budget(r.Context(), 5*time.Second)
+	defer cancel()
+
+	adminID, ok := h.requireAdmin(ctx, w, r)
+	if !ok {
+		return
+	}
+
+	idStr := chi.URLParam(r, "entry_id")
+	entryID, err := uuid.Parse(idStr)
+	if err != nil {
+		logger.Error(ctx, "remove ip allowlist entry: bad id", "id", idStr, "err", err)
+		helper.RespondError(w, r, apperror.BadRequest("bad id"))
+		return
+	}
+
+	existing, err := h.ipStore.List(ctx)
+	if err != nil {
+		logger.Error(ctx, "remove ip allowlist entry: list failed", "err", err)
+		helper.RespondError(w, r, apperror.InternalError("internal error", err))
+		return
+	}
+	var after []netip.Prefix
+	for _, e := range existing {
+		if e.ID != entryID {
+			after = append(after, allowliststore.Prefixes([]allowliststore.Entry{e})...)
+		}
+	}
+	if !allowliststore.Allows(after, helper.GetClientIP(r)) {
+		helper.RespondError(w, r, apperror.Conflict("this change would block your current IP address"))
+		return
+	}
+
+	entry, err := h.ipStore.Remove(ctx, entryID)
+	if err != nil {
+		if errors.Is(err, allowliststore.ErrEntryNotFound) {
+			helper.RespondError(w, r, apperror.NotFound("allowlist entry not found"))
+			return
+		}
+		logger.Error(ctx, "remove ip allowlist entry: store error", "err", err)
+		helper.RespondError(w, r, apperror.InternalError("internal error", err))
+		return
+	}
+
+	h.recordAllowlistChange(ctx, r, auditstore.ActionIPAllowlistRemoved, adminID, entry)
+
+	logger.Info(ctx, "remove ip allowlist entry: success", "admin_id", adminID, "cidr", entry.CIDR)
+	helper.RespondJSON(w, r, http.StatusOK, entry)
+}
+
+func (h *AdminHandler) recordAllowlistChange(ctx context.Context, r *http.Request, action auditstore.Action, adminID uuid.UUID, entry *allowliststore.Entry) {
+	if err := h.auditStore.Record(ctx, auditstore.Entry{
+		ActorID:    &adminID,
+		Action:     action,
+		TargetType: auditstore.TargetIPAllowlist,
+		TargetID:   &entry.ID,
+		Metadata: map[string]any{
+			"cidr": entry.CIDR,
+			"note": entry.Note,
+		},
+		IP:        helper.GetClientIP(r),
+		CreatedAt: time.Now().UTC(),
+	}); err != nil {
+		logger.Error(ctx, "ip allowlist: audit failed", "action", action, "err", err)
+	}
+}
@@ -0,0 +1,169 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/diagnosis/interactive-todo/api/types"
+	"github.com/diagnosis/interactive-todo/internal/apperror"
+	"github.com/diagnosis/interactive-todo/internal/deadline"
+	"github.com/diagnosis/interactive-todo/internal/helper"
+	"github.com/diagnosis/interactive-todo/internal/logger"
+	auditstore "github.com/diagnosis/interactive-todo/internal/store/audit"
+	legalholdstore "github.com/diagnosis/interactive-todo/internal/store/legalholds"
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+)
+
+// =====================
+//  Legal holds
+// =====================
+
+func (h *AdminHandler) ListLegalHolds(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := deadline.Budget(r.Context(), 5*time.Second)
+	defer cancel()
+
+	if _, ok := h.requireAdmin(ctx, w, r); !ok {
+		return
+	}
+
+	holds, err := h.holdStore.List(ctx)
+	if err != nil {
+		logger.Error(ctx, "list legal holds: store error", "err", err)
+		helper.RespondError(w, r, apperror.InternalError("internal error", err))
+		return
+	}
+
+	helper.RespondJSON(w, r, http.StatusOK, types.LegalHoldListResponse{Holds: holds})
+}
+
+// PlaceLegalHold freezes a team or a single task against deletion.
+// Exactly one of team_id or task_id must be given.
+func (h *AdminHandler) PlaceLegalHold(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := deadline.Budget(r.Context(), 5*time.Second)
+	defer cancel()
+
+	adminID, ok := h.requireAdmin(ctx, w, r)
+	if !ok {
+		return
+	}
+
+	r.Body = http.MaxBytesReader(w, r.Body, 1<<20)
+	defer r.Body.Close()
+
+	var in struct {
+		TeamID *uuid.UUID `json:"team_id"`
+		TaskID *uuid.UUID `json:"task_id"`
+		Reason string     `json:"reason"`
+	}
+	dec := json.NewDecoder(r.Body)
+	dec.DisallowUnknownFields()
+
+	if err := dec.Decode(&in); err != nil {
+		logger.Error(ctx, "place legal hold: bad json", "err", err)
+		helper.RespondError(w, r, apperror.BadRequest("invalid request body"))
+		return
+	}
+	if (in.TeamID == nil) == (in.TaskID == nil) {
+		helper.RespondError(w, r, apperror.BadRequest("exactly one of team_id or task_id is required"))
+		return
+	}
+	in.Reason = strings.TrimSpace(in.Reason)
+	if in.Reason == "" {
+		helper.RespondError(w, r, apperror.BadRequest("reason is required"))
+		return
+	}
+	if len(in.Reason) > 1000 {
+		helper.RespondError(w, r, apperror.BadRequest("reason too long (max 1000 chars)"))
+		return
+	}
+
+	now := time.Now().UTC()
+	var (
+		hold *legalholdstore.Hold
+		err  error
+	)
+	if in.TeamID != nil {
+		hold, err = h.holdStore.PlaceOnTeam(ctx, *in.TeamID, in.Reason, adminID, now)
+	} else {
+		hold, err = h.holdStore.PlaceOnTask(ctx, *in.TaskID, in.Reason, adminID, now)
+	}
+	if err != nil {
+		switch {
+		case errors.Is(err, legalholdstore.ErrTargetNotFound):
+			helper.RespondError(w, r, apperror.NotFound("team or task not found"))
+		case errors.Is(err, legalholdstore.ErrAlreadyHeld):
+			helper.RespondError(w, r, apperror.Conflict("already under legal hold"))
+		default:
+			logger.Error(ctx, "place legal hold: store error", "err", err)
+			helper.RespondError(w, r, apperror.InternalError("internal error", err))
+		}
+		return
+	}
+
+	h.recordHold(ctx, r, auditstore.ActionLegalHoldPlaced, adminID, hold)
+
+	logger.Info(ctx, "place legal hold: success", "admin_id", adminID, "hold_id", hold.ID)
+	helper.RespondJSON(w, r, http.StatusCreated, hold)
+}
+
+func (h *AdminHandler) ReleaseLegalHold(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := deadline.Budget(r.Context(), 5*time.Second)
+	defer cancel()
+
+	adminID, ok := h.requireAdmin(ctx, w, r)
+	if !ok {
+		return
+	}
+
+	idStr := chi.URLParam(r, "hold_id")
+	holdID, err := uuid.Parse(idStr)
+	if err != nil {
+		logger.Error(ctx, "release legal hold: bad id", "id", idStr, "err", err)
+		helper.RespondError(w, r, apperror.BadRequest("bad id"))
+		return
+	}
+
+	hold, err := h.holdStore.Release(ctx, holdID)
+	if err != nil {
+		if errors.Is(err, legalholdstore.ErrHoldNotFound) {
+			helper.RespondError(w, r, apperror.NotFound("legal hold not found"))
+			return
+		}
+		logger.Error(ctx, "release legal hold: store error", "err", err)
+		helper.RespondError(w, r, apperror.InternalError("internal error", err))
+		return
+	}
+
+	h.recordHold(ctx, r, auditstore.ActionLegalHoldReleased, adminID, hold)
+
+	logger.Info(ctx, "release legal hold: success", "admin_id", adminID, "hold_id", hold.ID)
+	helper.RespondJSON(w, r, http.StatusOK, hold)
+}
+
+func (h *AdminHandler) recordHold(ctx context.Context, r *http.Request, action auditstore.Action, adminID uuid.UUID, hold *legalholdstore.Hold) {
+	entry := auditstore.Entry{
+		ActorID:    &adminID,
+		Action:     action,
+		TargetType: auditstore.TargetTeam,
+		TargetID:   &hold.TeamID,
+		TeamID:     &hold.TeamID,
+		Metadata: map[string]any{
+			"hold_id": hold.ID,
+			"reason":  hold.Reason,
+		},
+		IP:        helper.GetClientIP(r),
+		CreatedAt: time.Now().UTC(),
+	}
+	if hold.TaskID != nil {
+		entry.TargetType = auditstore.TargetTask
+		entry.TargetID = hold.TaskID
+	}
+	if err := h.auditStore.Record(ctx, entry); err != nil {
+		logger.Error(ctx, "legal hold: audit failed", "action", action, "err", err)
+	}
+}
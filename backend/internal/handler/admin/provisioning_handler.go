@@ -0,0 +1,237 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/diagnosis/interactive-todo/api/types"
+	"github.com/diagnosis/interactive-todo/internal/apperror"
+	"github.com/diagnosis/interactive-todo/internal/deadline"
+	"github.com/diagnosis/interactive-todo/internal/helper"
+	"github.com/diagnosis/interactive-todo/internal/logger"
+	secure "github.com/diagnosis/interactive-todo/internal/secure/password"
+	auditstore "github.com/diagnosis/interactive-todo/internal/store/audit"
+	provisioningstore "github.com/diagnosis/interactive-todo/internal/store/provisioning"
+	userstore "github.com/diagnosis/interactive-todo/internal/store/users"
+	"github.com/go-chi/chi/v5"
+)
+
+// =====================
+//  Provisioning
+// =====================
+
+// maxExternalIDLen matches the check on users and teams.
+const maxExternalIDLen = 200
+
+// PutProvisionedUser creates or updates the user with the external id in
+// the path, for infrastructure-as-code and onboarding scripts: applying
+// the same body twice changes nothing the second time. 201 when the user
+// was created, 200 otherwise.
+func (h *AdminHandler) PutProvisionedUser(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := deadline.Budget(r.Context(), 5*time.Second)
+	defer cancel()
+
+	adminID, ok := h.requireAdmin(ctx, w, r)
+	if !ok {
+		return
+	}
+
+	externalID, ok := parseExternalID(w, r)
+	if !ok {
+		return
+	}
+
+	r.Body = http.MaxBytesReader(w, r.Body, 1<<20)
+	defer r.Body.Close()
+
+	var in types.PutUserRequest
+	dec := json.NewDecoder(r.Body)
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&in); err != nil {
+		logger.Error(ctx, "put user: bad json", "err", err)
+		helper.RespondError(w, r, apperror.BadRequest("invalid request body"))
+		return
+	}
+
+	email := strings.TrimSpace(strings.ToLower(in.Email))
+	if len(email) < 4 || !strings.Contains(email, "@") {
+		helper.RespondError(w, r, apperror.BadRequest("Invalid email address"))
+		return
+	}
+	switch in.UserType {
+	case userstore.TypeEmployee, userstore.TypeAdmin, userstore.TypeTaskManager:
+		// ok
+	default:
+		helper.RespondError(w, r, apperror.BadRequest("invalid user_type"))
+		return
+	}
+	spec := provisioningstore.UserSpec{Email: email, UserType: in.UserType, ActorID: adminID}
+	if in.Password != nil {
+		password := strings.TrimSpace(*in.Password)
+		if len(password) < 8 {
+			helper.RespondError(w, r, apperror.BadRequest("Password must be at least 8 characters"))
+			return
+		}
+		hash, err := secure.HashPassword(password)
+		if err != nil {
+			logger.Error(ctx, "put user: hash password failed", "err", err)
+			helper.RespondError(w, r, apperror.InternalError("internal error", err))
+			return
+		}
+		spec.PasswordHash = hash
+	}
+
+	now := time.Now().UTC()
+	out, err := h.provisioningStore.PutUser(ctx, externalID, spec, now)
+	if err != nil {
+		switch {
+		case errors.Is(err, provisioningstore.ErrPasswordRequired):
+			helper.RespondError(w, r, apperror.BadRequest(err.Error()))
+		case errors.Is(err, provisioningstore.ErrOwnUserType):
+			helper.RespondError(w, r, apperror.Forbidden(err.Error()))
+		case errors.Is(err, provisioningstore.ErrEmailTaken),
+			errors.Is(err, provisioningstore.ErrConcurrentPut):
+			helper.RespondError(w, r, apperror.Conflict(err.Error()))
+		default:
+			logger.Error(ctx, "put user: store error", "err", err)
+			helper.RespondError(w, r, apperror.InternalError("internal error", err))
+		}
+		return
+	}
+
+	if err := h.auditStore.Record(ctx, auditstore.Entry{
+		ActorID:    &adminID,
+		Action:     auditstore.ActionUserProvisioned,
+		TargetType: auditstore.TargetUser,
+		TargetID:   &out.User.ID,
+		Metadata: map[string]any{
+			"external_id": externalID,
+			"user_type":   out.User.UserType,
+			"created":     out.Created,
+		},
+		IP:        helper.GetClientIP(r),
+		CreatedAt: now,
+	}); err != nil {
+		logger.Error(ctx, "put user: audit failed", "err", err)
+	}
+
+	status := http.StatusOK
+	if out.Created {
+		status = http.StatusCreated
+	}
+	logger.Info(ctx, "put user: success", "admin_id", adminID, "user_id", out.User.ID, "created", out.Created)
+	helper.RespondJSON(w, r, status, out)
+}
+
+// PutProvisionedTeam creates or updates the team with the external id in
+// the path. Its membership becomes exactly the owner and members given,
+// all named by user external ids. 201 when the team was created, 200
+// otherwise.
+func (h *AdminHandler) PutProvisionedTeam(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := deadline.Budget(r.Context(), 10*time.Second)
+	defer cancel()
+
+	adminID, ok := h.requireAdmin(ctx, w, r)
+	if !ok {
+		return
+	}
+
+	externalID, ok := parseExternalID(w, r)
+	if !ok {
+		return
+	}
+
+	r.Body = http.MaxBytesReader(w, r.Body, 1<<20)
+	defer r.Body.Close()
+
+	var in types.PutTeamRequest
+	dec := json.NewDecoder(r.Body)
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&in); err != nil {
+		logger.Error(ctx, "put team: bad json", "err", err)
+		helper.RespondError(w, r, apperror.BadRequest("invalid request body"))
+		return
+	}
+
+	name := strings.TrimSpace(in.Name)
+	if name == "" || len(name) > 100 {
+		helper.RespondError(w, r, apperror.BadRequest("name must be 1 to 100 characters"))
+		return
+	}
+	if in.Owner == "" {
+		helper.RespondError(w, r, apperror.BadRequest("owner is required"))
+		return
+	}
+	seen := map[string]bool{in.Owner: true}
+	for _, m := range in.Members {
+		switch {
+		case m.ExternalID == "":
+			helper.RespondError(w, r, apperror.BadRequest("members need an external_id"))
+			return
+		case seen[m.ExternalID]:
+			helper.RespondError(w, r, apperror.BadRequest("members must not repeat anyone or include the owner: "+m.ExternalID))
+			return
+		case m.Role != types.TeamRoleAdmin && m.Role != types.TeamRoleMember:
+			helper.RespondError(w, r, apperror.BadRequest("member role must be admin or member"))
+			return
+		}
+		seen[m.ExternalID] = true
+	}
+
+	now := time.Now().UTC()
+	out, err := h.provisioningStore.PutTeam(ctx, externalID, provisioningstore.TeamSpec{
+		Name:    name,
+		Owner:   in.Owner,
+		Members: in.Members,
+	}, now)
+	if err != nil {
+		switch {
+		case errors.Is(err, provisioningstore.ErrUnknownUser):
+			helper.RespondError(w, r, apperror.BadRequest(err.Error()))
+		case errors.Is(err, provisioningstore.ErrTeamNameTaken),
+			errors.Is(err, provisioningstore.ErrConcurrentPut):
+			helper.RespondError(w, r, apperror.Conflict(err.Error()))
+		default:
+			logger.Error(ctx, "put team: store error", "err", err)
+			helper.RespondError(w, r, apperror.InternalError("internal error", err))
+		}
+		return
+	}
+
+	if err := h.auditStore.Record(ctx, auditstore.Entry{
+		ActorID:    &adminID,
+		Action:     auditstore.ActionTeamProvisioned,
+		TargetType: auditstore.TargetTeam,
+		TargetID:   &out.Team.ID,
+		TeamID:     &out.Team.ID,
+		Metadata: map[string]any{
+			"external_id": externalID,
+			"members":     len(out.Members) + 1,
+			"removed":     out.Removed,
+			"created":     out.Created,
+		},
+		IP:        helper.GetClientIP(r),
+		CreatedAt: now,
+	}); err != nil {
+		logger.Error(ctx, "put team: audit failed", "err", err)
+	}
+
+	status := http.StatusOK
+	if out.Created {
+		status = http.StatusCreated
+	}
+	logger.Info(ctx, "put team: success", "admin_id", adminID, "team_id", out.Team.ID, "created", out.Created, "removed", out.Removed)
+	helper.RespondJSON(w, r, status, out)
+}
+
+func parseExternalID(w http.ResponseWriter, r *http.Request) (string, bool) {
+	id := chi.URLParam(r, "external_id")
+	if id == "" || len(id) > maxExternalIDLen || strings.TrimSpace(id) != id {
+		helper.RespondError(w, r, apperror.BadRequest("external_id must be 1 to 200 characters without surrounding spaces"))
+		return "", false
+	}
+	return id, true
+}
@@ -0,0 +1,139 @@
+package handler
+
+import (
+	"encoding/base64"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/diagnosis/interactive-todo/api/types"
+	"github.com/diagnosis/interactive-todo/internal/apperror"
+	"github.com/diagnosis/interactive-todo/internal/deadline"
+	"github.com/diagnosis/interactive-todo/internal/helper"
+	"github.com/diagnosis/interactive-todo/internal/logger"
+	auditstore "github.com/diagnosis/interactive-todo/internal/store/audit"
+	taskstore "github.com/diagnosis/interactive-todo/internal/store/tasks"
+	"github.com/google/uuid"
+)
+
+// =====================
+//  Task listing
+// =====================
+
+const maxAdminTaskPage = 200
+
+// ListTasks lists one team's tasks for support and investigations. It is
+// always scoped to a team and paginated, and every call is audited since
+// it reads private and confidential tasks too.
+func (h *AdminHandler) ListTasks(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := deadline.Budget(r.Context(), 5*time.Second)
+	defer cancel()
+
+	adminID, ok := h.requireAdmin(ctx, w, r)
+	if !ok {
+		return
+	}
+
+	q := r.URL.Query()
+	var f taskstore.AdminTaskFilter
+
+	teamID, err := uuid.Parse(q.Get("team_id"))
+	if err != nil {
+		helper.RespondError(w, r, apperror.BadRequest("team_id is required"))
+		return
+	}
+	f.TeamID = teamID
+
+	f.Limit, err = strconv.Atoi(q.Get("limit"))
+	if err != nil || f.Limit < 1 || f.Limit > maxAdminTaskPage {
+		helper.RespondError(w, r, apperror.BadRequest("limit is required and must be between 1 and 200"))
+		return
+	}
+
+	if v := q.Get("status"); v != "" {
+		switch s := taskstore.TaskStatus(v); s {
+		case taskstore.OpenStatus, taskstore.InProgressStatus, taskstore.DoneStatus, taskstore.CanceledStatus:
+			f.Status = s
+		default:
+			helper.RespondError(w, r, apperror.BadRequest("invalid status"))
+			return
+		}
+	}
+	if v := q.Get("assignee_id"); v != "" {
+		if f.AssigneeID, err = uuid.Parse(v); err != nil {
+			helper.RespondError(w, r, apperror.BadRequest("invalid assignee_id"))
+			return
+		}
+	}
+	if v := q.Get("reporter_id"); v != "" {
+		if f.ReporterID, err = uuid.Parse(v); err != nil {
+			helper.RespondError(w, r, apperror.BadRequest("invalid reporter_id"))
+			return
+		}
+	}
+	if v := q.Get("cursor"); v != "" {
+		if f.BeforeAt, f.BeforeID, err = decodeTaskCursor(v); err != nil {
+			helper.RespondError(w, r, apperror.BadRequest("invalid cursor"))
+			return
+		}
+	}
+
+	tasks, err := h.taskStore.ListTasksForAdmin(ctx, f)
+	if err != nil {
+		logger.Error(ctx, "admin list tasks: store error", "err", err)
+		helper.RespondError(w, r, apperror.InternalError("internal error", err))
+		return
+	}
+
+	if err := h.auditStore.Record(ctx, auditstore.Entry{
+		ActorID:    &adminID,
+		Action:     auditstore.ActionAdminTasksListed,
+		TargetType: auditstore.TargetTeam,
+		TargetID:   &teamID,
+		TeamID:     &teamID,
+		Metadata: map[string]any{
+			"filter": q.Encode(),
+			"count":  len(tasks),
+		},
+		IP:        helper.GetClientIP(r),
+		CreatedAt: time.Now().UTC(),
+	}); err != nil {
+		logger.Error(ctx, "admin list tasks: audit failed", "err", err)
+	}
+
+	var next string
+	if len(tasks) == f.Limit {
+		last := tasks[len(tasks)-1]
+		next = encodeTaskCursor(last.CreatedAt, last.ID)
+	}
+
+	logger.Info(ctx, "admin list tasks: success", "admin_id", adminID, "team_id", teamID, "count", len(tasks))
+	helper.RespondJSON(w, r, http.StatusOK, types.AdminTaskListResponse{Tasks: tasks, NextCursor: next})
+}
+
+func encodeTaskCursor(createdAt time.Time, id uuid.UUID) string {
+	raw := createdAt.UTC().Format(time.RFC3339Nano) + "|" + id.String()
+	return base64.RawURLEncoding.EncodeToString([]byte(raw))
+}
+
+func decodeTaskCursor(s string) (time.Time, uuid.UUID, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return time.Time{}, uuid.Nil, err
+	}
+	at, idStr, ok := strings.Cut(string(raw), "|")
+	if !ok {
+		return time.Time{}, uuid.Nil, errors.New("malformed cursor")
+	}
+	t, err := time.Parse(time.RFC3339Nano, at)
+	if err != nil {
+		return time.Time{}, uuid.Nil, err
+	}
+	id, err := uuid.Parse(idStr)
+	if err != nil {
+		return time.Time{}, uuid.Nil, err
+	}
+	return t, id, nil
+}
@@ -0,0 +1,167 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/diagnosis/interactive-todo/api/types"
+	"github.com/diagnosis/interactive-todo/internal/apperror"
+	"github.com/diagnosis/interactive-todo/internal/deadline"
+	"github.com/diagnosis/interactive-todo/internal/helper"
+	"github.com/diagnosis/interactive-todo/internal/logger"
+	auditstore "github.com/diagnosis/interactive-todo/internal/store/audit"
+	taskstore "github.com/diagnosis/interactive-todo/internal/store/tasks"
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+)
+
+// =====================
+//  Deleted tasks
+// =====================
+
+// Tasks are the only thing that is soft deleted: teams and users have no
+// delete at all. Both endpoints are audited, as they reach private tasks.
+
+const (
+	defaultAdminTrashPage = 50
+	maxAdminTrashPage     = 200
+)
+
+// ListDeletedTasks lists deleted tasks of all teams, most recently deleted
+// first, with who deleted them and when they will be purged.
+func (h *AdminHandler) ListDeletedTasks(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := deadline.Budget(r.Context(), 5*time.Second)
+	defer cancel()
+
+	adminID, ok := h.requireAdmin(ctx, w, r)
+	if !ok {
+		return
+	}
+
+	q := r.URL.Query()
+	f := taskstore.AdminTrashFilter{Limit: defaultAdminTrashPage}
+	var err error
+
+	if v := q.Get("limit"); v != "" {
+		f.Limit, err = strconv.Atoi(v)
+		if err != nil || f.Limit < 1 || f.Limit > maxAdminTrashPage {
+			helper.RespondError(w, r, apperror.BadRequest("limit must be between 1 and 200"))
+			return
+		}
+	}
+	if v := q.Get("team_id"); v != "" {
+		if f.TeamID, err = uuid.Parse(v); err != nil {
+			helper.RespondError(w, r, apperror.BadRequest("invalid team_id"))
+			return
+		}
+	}
+	if v := q.Get("deleted_by"); v != "" {
+		if f.DeletedBy, err = uuid.Parse(v); err != nil {
+			helper.RespondError(w, r, apperror.BadRequest("invalid deleted_by"))
+			return
+		}
+	}
+	if v := q.Get("cursor"); v != "" {
+		if f.BeforeAt, f.BeforeID, err = decodeTaskCursor(v); err != nil {
+			helper.RespondError(w, r, apperror.BadRequest("invalid cursor"))
+			return
+		}
+	}
+
+	tasks, err := h.taskStore.ListTrashForAdmin(ctx, f)
+	if err != nil {
+		logger.Error(ctx, "admin list deleted tasks: store error", "err", err)
+		helper.RespondError(w, r, apperror.InternalError("internal error", err))
+		return
+	}
+
+	entry := auditstore.Entry{
+		ActorID:    &adminID,
+		Action:     auditstore.ActionAdminTrashListed,
+		TargetType: auditstore.TargetTask,
+		Metadata: map[string]any{
+			"filter": q.Encode(),
+			"count":  len(tasks),
+		},
+		IP:        helper.GetClientIP(r),
+		CreatedAt: time.Now().UTC(),
+	}
+	if f.TeamID != uuid.Nil {
+		entry.TeamID = &f.TeamID
+	}
+	if err := h.auditStore.Record(ctx, entry); err != nil {
+		logger.Error(ctx, "admin list deleted tasks: audit failed", "err", err)
+	}
+
+	var next string
+	if len(tasks) == f.Limit {
+		last := tasks[len(tasks)-1]
+		next = encodeTaskCursor(last.DeletedAt, last.ID)
+	}
+
+	logger.Info(ctx, "admin list deleted tasks: success", "admin_id", adminID, "count", len(tasks))
+	helper.RespondJSON(w, r, http.StatusOK, types.AdminTrashListResponse{Tasks: tasks, NextCursor: next})
+}
+
+// RestoreDeletedTask takes any task out of the trash, whoever deleted it.
+// The restore is recorded in the task's history under the admin.
+func (h *AdminHandler) RestoreDeletedTask(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := deadline.Budget(r.Context(), 5*time.Second)
+	defer cancel()
+
+	adminID, ok := h.requireAdmin(ctx, w, r)
+	if !ok {
+		return
+	}
+
+	taskID, err := uuid.Parse(chi.URLParam(r, "task_id"))
+	if err != nil {
+		helper.RespondError(w, r, apperror.BadRequest("invalid task id"))
+		return
+	}
+
+	trashed, err := h.taskStore.GetTrashedTask(ctx, taskID)
+	if err != nil {
+		if errors.Is(err, taskstore.ErrTaskNotFound) {
+			helper.RespondError(w, r, apperror.NotFound("task not in trash"))
+			return
+		}
+		logger.Error(ctx, "admin restore task: get trashed task failed", "err", err)
+		helper.RespondError(w, r, apperror.InternalError("internal error", err))
+		return
+	}
+
+	now := time.Now().UTC()
+	task, err := h.taskStore.RestoreTask(ctx, taskID, &adminID, now)
+	if err != nil {
+		if errors.Is(err, taskstore.ErrTaskNotFound) {
+			helper.RespondError(w, r, apperror.NotFound("task not in trash"))
+			return
+		}
+		logger.Error(ctx, "admin restore task: store restore failed", "err", err)
+		helper.RespondError(w, r, apperror.InternalError("internal error", err))
+		return
+	}
+
+	metadata := map[string]any{"deleted_at": trashed.DeletedAt}
+	if trashed.DeletedBy != nil {
+		metadata["deleted_by"] = *trashed.DeletedBy
+	}
+	if err := h.auditStore.Record(ctx, auditstore.Entry{
+		ActorID:    &adminID,
+		Action:     auditstore.ActionAdminTaskRestored,
+		TargetType: auditstore.TargetTask,
+		TargetID:   &taskID,
+		TeamID:     &task.TeamID,
+		Metadata:   metadata,
+		IP:         helper.GetClientIP(r),
+		CreatedAt:  now,
+	}); err != nil {
+		logger.Error(ctx, "admin restore task: audit failed", "err", err)
+	}
+
+	logger.Info(ctx, "admin restored task", "admin_id", adminID, "task_id", taskID)
+	helper.RespondJSON(w, r, http.StatusOK, task)
+}
@@ -0,0 +1,89 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/diagnosis/interactive-todo/api/types"
+	"github.com/diagnosis/interactive-todo/internal/apperror"
+	"github.com/diagnosis/interactive-todo/internal/deadline"
+	"github.com/diagnosis/interactive-todo/internal/helper"
+	"github.com/diagnosis/interactive-todo/internal/logger"
+	usagestore "github.com/diagnosis/interactive-todo/internal/store/usage"
+)
+
+// =====================
+//  API usage
+// =====================
+
+// ListAPIUsage lists users with their request count over ?days= and their
+// last activity. ?inactive_days= lists only users idle at least that long,
+// for license audits and finding abandoned accounts.
+func (h *AdminHandler) ListAPIUsage(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := deadline.Budget(r.Context(), 5*time.Second)
+	defer cancel()
+
+	adminID, ok := h.requireAdmin(ctx, w, r)
+	if !ok {
+		return
+	}
+
+	days, ok := parseDays(w, r, 30)
+	if !ok {
+		return
+	}
+	now := time.Now().UTC()
+	filter := usagestore.UsageFilter{Since: now.AddDate(0, 0, -(days - 1)), Limit: 100}
+
+	q := r.URL.Query()
+	if v := q.Get("inactive_days"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 || n > 365 {
+			helper.RespondError(w, r, apperror.BadRequest("inactive_days must be between 1 and 365"))
+			return
+		}
+		since := now.AddDate(0, 0, -n)
+		filter.InactiveSince = &since
+	}
+	if v := q.Get("limit"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 || n > 500 {
+			helper.RespondError(w, r, apperror.BadRequest("limit must be between 1 and 500"))
+			return
+		}
+		filter.Limit = n
+	}
+	if v := q.Get("offset"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 0 {
+			helper.RespondError(w, r, apperror.BadRequest("offset must be a non-negative integer"))
+			return
+		}
+		filter.Offset = n
+	}
+
+	// one extra row tells whether another page exists
+	page := filter
+	page.Limit++
+	users, err := h.usageStore.ListUsers(ctx, page)
+	if err != nil {
+		logger.Error(ctx, "list api usage: store error", "err", err)
+		helper.RespondError(w, r, apperror.InternalError("internal error", err))
+		return
+	}
+
+	var nextOffset *int
+	if len(users) > filter.Limit {
+		users = users[:filter.Limit]
+		n := filter.Offset + filter.Limit
+		nextOffset = &n
+	}
+
+	logger.Info(ctx, "list api usage: success", "admin_id", adminID, "count", len(users))
+	helper.RespondJSON(w, r, http.StatusOK, types.UsageResponse{
+		Days:       days,
+		Users:      users,
+		NextOffset: nextOffset,
+	})
+}
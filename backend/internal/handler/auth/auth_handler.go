@@ -8,36 +8,59 @@ import (
 	"fmt"
 	"net"
 	"net/http"
+	"strconv"
 	"strings"
 	"time"
 
+	"github.com/diagnosis/interactive-todo/api/types"
 	"github.com/diagnosis/interactive-todo/internal/apperror"
 	jwttoken "github.com/diagnosis/interactive-todo/internal/auth/jwt"
+	"github.com/diagnosis/interactive-todo/internal/deadline"
+	"github.com/diagnosis/interactive-todo/internal/demo"
 	"github.com/diagnosis/interactive-todo/internal/helper"
 	"github.com/diagnosis/interactive-todo/internal/logger"
 	middleware "github.com/diagnosis/interactive-todo/internal/middleware/auth"
 	secure "github.com/diagnosis/interactive-todo/internal/secure/password"
+	invitationstore "github.com/diagnosis/interactive-todo/internal/store/invitations"
 	refreshstore "github.com/diagnosis/interactive-todo/internal/store/refresh_tokens"
+	setupstore "github.com/diagnosis/interactive-todo/internal/store/setup"
+	denyliststore "github.com/diagnosis/interactive-todo/internal/store/tokendenylist"
 	userstore "github.com/diagnosis/interactive-todo/internal/store/users"
 	"github.com/go-chi/chi/v5"
 	"github.com/google/uuid"
 )
 
 type AuthHandler struct {
-	userStore    userstore.UserStore
-	refreshStore refreshstore.RefreshTokenStore
-	jwtManager   jwttoken.TokenManager
+	userStore     userstore.UserStore
+	refreshStore  refreshstore.RefreshTokenStore
+	jwtManager    jwttoken.TokenManager
+	denylistStore denyliststore.DenylistStore
+	// invitationStore turns invitations for a new user's email into
+	// memberships
+	invitationStore invitationstore.InvitationStore
+	// setupStore backs the first-run setup, see setup_handler.go
+	setupStore setupstore.SetupStore
+	// sandbox is set in demo mode, for the banner in GET /setup
+	sandbox *demo.Sandbox
 }
 
 func NewAuthHandler(
 	us userstore.UserStore,
 	rts refreshstore.RefreshTokenStore,
 	jm jwttoken.TokenManager,
+	dls denyliststore.DenylistStore,
+	is invitationstore.InvitationStore,
+	ss setupstore.SetupStore,
+	sb *demo.Sandbox,
 ) *AuthHandler {
 	return &AuthHandler{
-		userStore:    us,
-		refreshStore: rts,
-		jwtManager:   jm,
+		userStore:       us,
+		refreshStore:    rts,
+		jwtManager:      jm,
+		denylistStore:   dls,
+		invitationStore: is,
+		setupStore:      ss,
+		sandbox:         sb,
 	}
 }
 
@@ -46,7 +69,7 @@ func NewAuthHandler(
 // =====================
 
 func (h *AuthHandler) HandleUpdateUserType(w http.ResponseWriter, r *http.Request) {
-	ctx, cancel := context.WithTimeout(r.Context(), 5*time.Second)
+	ctx, cancel := deadline.Budget(r.Context(), 5*time.Second)
 	defer cancel()
 
 	adminID, ok := middleware.GetUserIDFromContext(ctx)
@@ -83,9 +106,7 @@ func (h *AuthHandler) HandleUpdateUserType(w http.ResponseWriter, r *http.Reques
 	r.Body = http.MaxBytesReader(w, r.Body, 1<<20)
 	defer r.Body.Close()
 
-	var in struct {
-		UserType userstore.UserType `json:"user_type"`
-	}
+	var in types.UpdateUserTypeRequest
 
 	dec := json.NewDecoder(r.Body)
 	dec.DisallowUnknownFields()
@@ -121,11 +142,16 @@ func (h *AuthHandler) HandleUpdateUserType(w http.ResponseWriter, r *http.Reques
 		"user_type", updatedUser.UserType,
 	)
 
-	response := map[string]any{
-		"message": "user_type updated successfully",
-		"user":    updatedUser,
-	}
-	helper.RespondJSON(w, r, http.StatusOK, response)
+	helper.RespondJSON(w, r, http.StatusOK, types.UpdateUserTypeResponse{
+		Message: "user_type updated successfully",
+		User: types.User{
+			ID:        updatedUser.ID,
+			Email:     updatedUser.Email,
+			UserType:  updatedUser.UserType,
+			CreatedAt: updatedUser.CreatedAt,
+			UpdatedAt: updatedUser.UpdatedAt,
+		},
+	})
 }
 
 // =====================
@@ -133,7 +159,7 @@ func (h *AuthHandler) HandleUpdateUserType(w http.ResponseWriter, r *http.Reques
 // =====================
 
 func (h *AuthHandler) Register(w http.ResponseWriter, r *http.Request) {
-	ctx, cancel := context.WithTimeout(r.Context(), 5*time.Second)
+	ctx, cancel := deadline.Budget(r.Context(), 5*time.Second)
 	defer cancel()
 
 	logger.Info(ctx, "register: attempt")
@@ -141,10 +167,7 @@ func (h *AuthHandler) Register(w http.ResponseWriter, r *http.Request) {
 	r.Body = http.MaxBytesReader(w, r.Body, 1<<20)
 	defer r.Body.Close()
 
-	var in struct {
-		Email    string `json:"email"`
-		Password string `json:"password"`
-	}
+	var in types.RegisterRequest
 
 	dec := json.NewDecoder(r.Body)
 	dec.DisallowUnknownFields()
@@ -195,13 +218,21 @@ func (h *AuthHandler) Register(w http.ResponseWriter, r *http.Request) {
 		"user_type", created.UserType,
 	)
 
-	response := map[string]any{
-		"user_id":    created.ID,
-		"email":      created.Email,
-		"user_type":  created.UserType,
-		"created_at": created.CreatedAt,
+	// the account exists either way; after a failure, importing the email
+	// again adds the user directly
+	joined, err := h.invitationStore.Accept(ctx, created.ID, created.Email, now)
+	if err != nil {
+		logger.Error(ctx, "register: accept invitations failed", "user_id", created.ID, "err", err)
+	} else if len(joined) > 0 {
+		logger.Info(ctx, "register: joined invited teams", "user_id", created.ID, "team_ids", joined)
 	}
-	helper.RespondJSON(w, r, http.StatusCreated, response)
+
+	helper.RespondJSON(w, r, http.StatusCreated, types.RegisterResponse{
+		UserID:    created.ID,
+		Email:     created.Email,
+		UserType:  created.UserType,
+		CreatedAt: created.CreatedAt,
+	})
 }
 
 // =====================
@@ -209,7 +240,7 @@ func (h *AuthHandler) Register(w http.ResponseWriter, r *http.Request) {
 // =====================
 
 func (h *AuthHandler) Login(w http.ResponseWriter, r *http.Request) {
-	ctx, cancel := context.WithTimeout(r.Context(), 5*time.Second)
+	ctx, cancel := deadline.Budget(r.Context(), 5*time.Second)
 	defer cancel()
 
 	logger.Info(ctx, "login: attempt")
@@ -217,10 +248,7 @@ func (h *AuthHandler) Login(w http.ResponseWriter, r *http.Request) {
 	r.Body = http.MaxBytesReader(w, r.Body, 1<<20)
 	defer r.Body.Close()
 
-	var in struct {
-		Email    string `json:"email"`
-		Password string `json:"password"`
-	}
+	var in types.LoginRequest
 
 	dec := json.NewDecoder(r.Body)
 	dec.DisallowUnknownFields()
@@ -269,7 +297,7 @@ func (h *AuthHandler) Login(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	accessToken, err := h.jwtManager.MintAccessToken(user.ID, user.Email, user.UserType)
+	accessToken, err := h.jwtManager.MintAccessToken(user.ID, user.Email, user.UserType, user.TokenVersion)
 	if err != nil {
 		logger.Error(ctx, "login: mint access token failed", "err", err)
 		helper.RespondError(w, r, apperror.InternalError("internal error", err))
@@ -286,7 +314,7 @@ func (h *AuthHandler) Login(w http.ResponseWriter, r *http.Request) {
 	sha := sha256.Sum256([]byte(refreshToken))
 	tokenHash := fmt.Sprintf("%x", sha[:])
 	ua := r.UserAgent()
-	ip := getClientIP(r)
+	ip := helper.GetClientIP(r)
 	now := time.Now().UTC()
 	expiresAt := now.Add(7 * 24 * time.Hour)
 
@@ -301,17 +329,7 @@ func (h *AuthHandler) Login(w http.ResponseWriter, r *http.Request) {
 
 	setRefreshTokenCookie(w, refreshToken)
 
-	response := map[string]any{
-		"access_token": accessToken,
-		"token_type":   "Bearer",
-		"expires_in":   int((15 * time.Minute).Seconds()),
-		"user": map[string]any{
-			"id":    user.ID,
-			"email": user.Email,
-			"type":  user.UserType,
-		},
-	}
-	helper.RespondJSON(w, r, http.StatusOK, response)
+	helper.RespondJSON(w, r, http.StatusOK, h.tokenResponse(accessToken, user))
 }
 
 // =====================
@@ -319,7 +337,7 @@ func (h *AuthHandler) Login(w http.ResponseWriter, r *http.Request) {
 // =====================
 
 func (h *AuthHandler) RefreshAccessToken(w http.ResponseWriter, r *http.Request) {
-	ctx, cancel := context.WithTimeout(r.Context(), 5*time.Second)
+	ctx, cancel := deadline.Budget(r.Context(), 5*time.Second)
 	defer cancel()
 
 	logger.Info(ctx, "refresh token: attempt")
@@ -356,7 +374,7 @@ func (h *AuthHandler) RefreshAccessToken(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
-	accessToken, err := h.jwtManager.MintAccessToken(user.ID, user.Email, user.UserType)
+	accessToken, err := h.jwtManager.MintAccessToken(user.ID, user.Email, user.UserType, user.TokenVersion)
 	if err != nil {
 		logger.Error(ctx, "refresh token: mint access failed", "err", err)
 		helper.RespondError(w, r, apperror.InternalError("internal error", err))
@@ -370,17 +388,7 @@ func (h *AuthHandler) RefreshAccessToken(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
-	response := map[string]any{
-		"access_token": accessToken,
-		"token_type":   "Bearer",
-		"expires_in":   int((15 * time.Minute).Seconds()),
-		"user": map[string]any{
-			"id":    user.ID,
-			"email": user.Email,
-			"type":  user.UserType,
-		},
-	}
-	helper.RespondJSON(w, r, http.StatusOK, response)
+	helper.RespondJSON(w, r, http.StatusOK, h.tokenResponse(accessToken, user))
 }
 
 // =====================
@@ -388,7 +396,7 @@ func (h *AuthHandler) RefreshAccessToken(w http.ResponseWriter, r *http.Request)
 // =====================
 
 func (h *AuthHandler) Logout(w http.ResponseWriter, r *http.Request) {
-	ctx, cancel := context.WithTimeout(r.Context(), 5*time.Second)
+	ctx, cancel := deadline.Budget(r.Context(), 5*time.Second)
 	defer cancel()
 
 	logger.Info(ctx, "logout: attempt")
@@ -406,6 +414,7 @@ func (h *AuthHandler) Logout(w http.ResponseWriter, r *http.Request) {
 
 	_ = h.refreshStore.Revoke(ctx, tokenHash, time.Now().UTC())
 	cleanRefreshToken(w)
+	h.denyBearerToken(ctx, r)
 
 	logger.Info(ctx, "logout: success")
 	helper.RespondMessage(w, r, http.StatusOK, "logged out successfully")
@@ -416,7 +425,7 @@ func (h *AuthHandler) Logout(w http.ResponseWriter, r *http.Request) {
 // =====================
 
 func (h *AuthHandler) LogoutFromAllDevices(w http.ResponseWriter, r *http.Request) {
-	ctx, cancel := context.WithTimeout(r.Context(), 5*time.Second)
+	ctx, cancel := deadline.Budget(r.Context(), 5*time.Second)
 	defer cancel()
 
 	logger.Info(ctx, "logout all: attempt")
@@ -428,12 +437,22 @@ func (h *AuthHandler) LogoutFromAllDevices(w http.ResponseWriter, r *http.Reques
 		return
 	}
 
-	if err := h.refreshStore.RevokeAllForUser(ctx, userID, time.Now().UTC()); err != nil {
+	now := time.Now().UTC()
+	if err := h.refreshStore.RevokeAllForUser(ctx, userID, now); err != nil {
 		logger.Error(ctx, "logout all: revoke all failed", "err", err)
 		helper.RespondError(w, r, apperror.InternalError("internal error", err))
 		return
 	}
 
+	// block access tokens already handed out; iat has second precision, so
+	// the cutoff is too, and this request's own token is denied by jti
+	if err := h.denylistStore.DenyAllForUser(ctx, userID, now.Truncate(time.Second), now.Add(h.accessTokenLifetime())); err != nil {
+		logger.Error(ctx, "logout all: deny access tokens failed", "err", err)
+		helper.RespondError(w, r, apperror.InternalError("internal error", err))
+		return
+	}
+	h.denyBearerToken(ctx, r)
+
 	cleanRefreshToken(w)
 
 	logger.Info(ctx, "logout all: success", "user_id", userID)
@@ -444,30 +463,98 @@ func (h *AuthHandler) LogoutFromAllDevices(w http.ResponseWriter, r *http.Reques
 //  List Users
 // =====================
 
+const (
+	defaultUsersPageSize = 50
+	maxUsersPageSize     = 100
+)
+
+// ListUsers returns a page of users. Admins see everyone; other callers see
+// only the users they share a team with. Query: user_type, sort (email,
+// -email, created_at, -created_at), limit, offset.
 func (h *AuthHandler) ListUsers(w http.ResponseWriter, r *http.Request) {
-	ctx, cancel := context.WithTimeout(r.Context(), 5*time.Second)
+	ctx, cancel := deadline.Budget(r.Context(), 5*time.Second)
 	defer cancel()
 
-	logger.Info(ctx, "list users: start")
+	claims, ok := middleware.GetClaimsFromContext(ctx)
+	if !ok {
+		logger.Info(ctx, "list users: unauthorized")
+		helper.RespondError(w, r, apperror.Unauthorized("authentication required"))
+		return
+	}
+
+	q := r.URL.Query()
+	filter := userstore.UserFilter{Limit: defaultUsersPageSize}
+
+	if v := q.Get("user_type"); v != "" {
+		ut := userstore.UserType(v)
+		switch ut {
+		case userstore.TypeEmployee, userstore.TypeAdmin, userstore.TypeTaskManager:
+			filter.UserType = &ut
+		default:
+			helper.RespondError(w, r, apperror.BadRequest("invalid user_type"))
+			return
+		}
+	}
 
-	users, err := h.userStore.ListAll(ctx)
+	sort, err := userstore.ParseUserSort(q.Get("sort"))
+	if err != nil {
+		helper.RespondError(w, r, apperror.BadRequest("sort must be one of email, -email, created_at, -created_at"))
+		return
+	}
+	filter.Sort = sort
+
+	if v := q.Get("limit"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 || n > maxUsersPageSize {
+			helper.RespondError(w, r, apperror.BadRequest(fmt.Sprintf("limit must be between 1 and %d", maxUsersPageSize)))
+			return
+		}
+		filter.Limit = n
+	}
+	if v := q.Get("offset"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 0 {
+			helper.RespondError(w, r, apperror.BadRequest("offset must be a non-negative integer"))
+			return
+		}
+		filter.Offset = n
+	}
+
+	if claims.UserType != userstore.TypeAdmin {
+		filter.SharesTeamWith = &claims.UserID
+	}
+
+	// one extra row tells whether another page exists
+	page := filter
+	page.Limit++
+	users, err := h.userStore.ListUsers(ctx, page)
 	if err != nil {
 		logger.Error(ctx, "list users: store error", "err", err)
 		helper.RespondError(w, r, apperror.InternalError("internal error", err))
 		return
 	}
 
-	response := make([]map[string]any, len(users))
+	var nextOffset *int
+	if len(users) > filter.Limit {
+		users = users[:filter.Limit]
+		n := filter.Offset + filter.Limit
+		nextOffset = &n
+	}
+
+	out := make([]types.UserSummary, len(users))
 	for i, user := range users {
-		response[i] = map[string]any{
-			"id":        user.ID,
-			"email":     user.Email,
-			"user_type": user.UserType,
+		out[i] = types.UserSummary{
+			ID:       user.ID,
+			Email:    user.Email,
+			UserType: user.UserType,
 		}
 	}
 
 	logger.Info(ctx, "list users: success", "count", len(users))
-	helper.RespondJSON(w, r, http.StatusOK, response)
+	helper.RespondJSON(w, r, http.StatusOK, types.UserListResponse{
+		Users:      out,
+		NextOffset: nextOffset,
+	})
 }
 
 // =====================
@@ -491,34 +578,6 @@ func (h *AuthHandler) CleanupExpiredTokens() {
 //  Helpers
 // =====================
 
-func getClientIP(r *http.Request) string {
-	// Prefer X-Forwarded-For (first IP)
-	if xff := strings.TrimSpace(r.Header.Get("X-Forwarded-For")); xff != "" {
-		parts := strings.Split(xff, ",")
-		if len(parts) > 0 {
-			return strings.TrimSpace(parts[0])
-		}
-	}
-
-	// Fallback to X-Real-IP
-	if xRealIP := strings.TrimSpace(r.Header.Get("X-Real-IP")); xRealIP != "" {
-		return xRealIP
-	}
-
-	// Finally, use RemoteAddr (host:port)
-	remote := strings.TrimSpace(r.RemoteAddr)
-	if remote == "" {
-		return ""
-	}
-
-	host, _, err := net.SplitHostPort(remote)
-	if err != nil {
-		// If it fails (e.g., no port), just return raw
-		return remote
-	}
-	return host
-}
-
 func setRefreshTokenCookie(w http.ResponseWriter, refreshToken string) {
 	http.SetCookie(w, &http.Cookie{
 		Name:     "refresh_token",
@@ -561,7 +620,7 @@ func (h *AuthHandler) rotateRefresh(w http.ResponseWriter, r *http.Request, oldT
 	sha := sha256.Sum256([]byte(refreshToken))
 	tokenHash := fmt.Sprintf("%x", sha[:])
 	ua := r.UserAgent()
-	ip := getClientIP(r)
+	ip := helper.GetClientIP(r)
 	expiresAt := time.Now().UTC().Add(7 * 24 * time.Hour)
 
 	if _, err = h.refreshStore.Create(ctx, userID, tokenHash, expiresAt, ua, net.ParseIP(ip)); err != nil {
@@ -571,3 +630,47 @@ func (h *AuthHandler) rotateRefresh(w http.ResponseWriter, r *http.Request, oldT
 	setRefreshTokenCookie(w, refreshToken)
 	return nil
 }
+
+// accessTokenLifetime covers the validation leeway as well, so a denylist
+// row never expires before the token it blocks.
+func (h *AuthHandler) accessTokenLifetime() time.Duration {
+	return h.jwtManager.AccessTokenTTL() + time.Minute
+}
+
+// denyBearerToken denylists the access token sent with r, if any, until it
+// expires. Logout still succeeds when this fails; the token expires on its
+// own.
+func (h *AuthHandler) denyBearerToken(ctx context.Context, r *http.Request) {
+	tok, err := middleware.ExtractAccessTokenFromBearer(r.Header.Get("Authorization"))
+	if err != nil {
+		return
+	}
+	claims, err := h.jwtManager.ValidateAccessToken(tok)
+	if err != nil {
+		return
+	}
+	jti := middleware.TokenID(claims)
+	if jti == uuid.Nil {
+		return
+	}
+	expiresAt := time.Now().UTC().Add(h.accessTokenLifetime())
+	if claims.ExpiresAt != nil {
+		expiresAt = claims.ExpiresAt.Add(time.Minute)
+	}
+	if err := h.denylistStore.Deny(ctx, jti, claims.UserID, expiresAt); err != nil {
+		logger.Error(ctx, "logout: deny access token failed", "err", err)
+	}
+}
+
+func (h *AuthHandler) tokenResponse(accessToken string, user *userstore.User) types.TokenResponse {
+	return types.TokenResponse{
+		AccessToken: accessToken,
+		TokenType:   "Bearer",
+		ExpiresIn:   int(h.jwtManager.AccessTokenTTL().Seconds()),
+		User: types.SessionUser{
+			ID:    user.ID,
+			Email: user.Email,
+			Type:  user.UserType,
+		},
+	}
+}
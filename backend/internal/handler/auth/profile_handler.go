@@ -0,0 +1,108 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/diagnosis/interactive-todo/api/types"
+	"github.com/diagnosis/interactive-todo/internal/apperror"
+	"github.com/diagnosis/interactive-todo/internal/deadline"
+	"github.com/diagnosis/interactive-todo/internal/helper"
+	"github.com/diagnosis/interactive-todo/internal/localtime"
+	"github.com/diagnosis/interactive-todo/internal/logger"
+	middleware "github.com/diagnosis/interactive-todo/internal/middleware/auth"
+	userstore "github.com/diagnosis/interactive-todo/internal/store/users"
+)
+
+// =====================
+//  Own profile
+// =====================
+
+func (h *AuthHandler) GetProfile(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := deadline.Budget(r.Context(), 5*time.Second)
+	defer cancel()
+
+	userID, ok := middleware.GetUserIDFromContext(ctx)
+	if !ok {
+		helper.RespondError(w, r, apperror.Unauthorized("unauthorized"))
+		return
+	}
+
+	user, err := h.userStore.GetUserByID(ctx, userID)
+	if err != nil {
+		if errors.Is(err, userstore.ErrNotFound) {
+			helper.RespondError(w, r, apperror.NotFound("user not found"))
+			return
+		}
+		logger.Error(ctx, "get profile: store failed", "err", err)
+		helper.RespondError(w, r, apperror.InternalError("internal error", err))
+		return
+	}
+	helper.RespondJSON(w, r, http.StatusOK, profile(user))
+}
+
+// UpdateProfile changes the caller's own settings; for now the timezone
+// their due dates are entered and shown in.
+func (h *AuthHandler) UpdateProfile(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := deadline.Budget(r.Context(), 5*time.Second)
+	defer cancel()
+
+	userID, ok := middleware.GetUserIDFromContext(ctx)
+	if !ok {
+		helper.RespondError(w, r, apperror.Unauthorized("unauthorized"))
+		return
+	}
+
+	r.Body = http.MaxBytesReader(w, r.Body, 1<<20)
+	defer r.Body.Close()
+
+	var in types.UpdateProfileRequest
+	dec := json.NewDecoder(r.Body)
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&in); err != nil {
+		logger.Error(ctx, "update profile: bad json", "err", err)
+		helper.RespondError(w, r, apperror.BadRequest("invalid request body"))
+		return
+	}
+	if in.Timezone == nil {
+		helper.RespondError(w, r, apperror.BadRequest("nothing to update"))
+		return
+	}
+
+	tz := strings.TrimSpace(*in.Timezone)
+	if tz == "" {
+		tz = localtime.Default
+	}
+	if _, err := localtime.Load(tz); err != nil {
+		helper.RespondError(w, r, apperror.BadRequest(err.Error()))
+		return
+	}
+
+	user, err := h.userStore.SetTimezone(ctx, userID, tz, time.Now().UTC())
+	if err != nil {
+		if errors.Is(err, userstore.ErrNotFound) {
+			helper.RespondError(w, r, apperror.NotFound("user not found"))
+			return
+		}
+		logger.Error(ctx, "update profile: store failed", "err", err)
+		helper.RespondError(w, r, apperror.InternalError("internal error", err))
+		return
+	}
+
+	logger.Info(ctx, "profile updated", "user_id", userID, "timezone", tz)
+	helper.RespondJSON(w, r, http.StatusOK, profile(user))
+}
+
+func profile(u *userstore.User) types.User {
+	return types.User{
+		ID:        u.ID,
+		Email:     u.Email,
+		UserType:  u.UserType,
+		Timezone:  u.Timezone,
+		CreatedAt: u.CreatedAt,
+		UpdatedAt: u.UpdatedAt,
+	}
+}
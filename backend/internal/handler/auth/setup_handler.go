@@ -0,0 +1,127 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/diagnosis/interactive-todo/api/types"
+	"github.com/diagnosis/interactive-todo/internal/apperror"
+	"github.com/diagnosis/interactive-todo/internal/deadline"
+	"github.com/diagnosis/interactive-todo/internal/helper"
+	"github.com/diagnosis/interactive-todo/internal/logger"
+	secure "github.com/diagnosis/interactive-todo/internal/secure/password"
+	setupstore "github.com/diagnosis/interactive-todo/internal/store/setup"
+)
+
+// =====================
+//  First-run setup
+// =====================
+
+// SetupStatus tells the frontend whether to show the setup wizard, and on
+// a demo install what to put in the demo banner.
+func (h *AuthHandler) SetupStatus(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := deadline.Budget(r.Context(), 5*time.Second)
+	defer cancel()
+
+	st, err := h.setupStore.Status(ctx)
+	if err != nil {
+		logger.Error(ctx, "setup status: store error", "err", err)
+		helper.RespondError(w, r, apperror.InternalError("internal error", err))
+		return
+	}
+	if h.sandbox != nil {
+		st.Demo = &types.DemoInfo{
+			Accounts: h.sandbox.Accounts(),
+			Password: h.sandbox.Password(),
+			ResetAt:  h.sandbox.NextReset(),
+		}
+	}
+	helper.RespondJSON(w, r, http.StatusOK, st)
+}
+
+// Setup creates the first admin, names the install and gives the admin a
+// team, on an install without users. It answers 404 from then on, so it
+// cannot be used to take over a running install.
+func (h *AuthHandler) Setup(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := deadline.Budget(r.Context(), 5*time.Second)
+	defer cancel()
+
+	logger.Info(ctx, "setup: attempt")
+
+	// cheap refusal before hashing a password on a set-up install
+	st, err := h.setupStore.Status(ctx)
+	if err != nil {
+		logger.Error(ctx, "setup: status failed", "err", err)
+		helper.RespondError(w, r, apperror.InternalError("internal error", err))
+		return
+	}
+	if !st.Required {
+		helper.RespondError(w, r, apperror.NotFound("setup already completed"))
+		return
+	}
+
+	r.Body = http.MaxBytesReader(w, r.Body, 1<<20)
+	defer r.Body.Close()
+
+	var in types.SetupRequest
+	dec := json.NewDecoder(r.Body)
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&in); err != nil {
+		logger.Error(ctx, "setup: bad json", "err", err)
+		helper.RespondError(w, r, apperror.BadRequest("bad json"))
+		return
+	}
+
+	orgName := strings.TrimSpace(in.OrgName)
+	teamName := strings.TrimSpace(in.TeamName)
+	email := strings.TrimSpace(strings.ToLower(in.Email))
+	password := strings.TrimSpace(in.Password)
+
+	switch {
+	case orgName == "" || len(orgName) > 100:
+		helper.RespondError(w, r, apperror.BadRequest("org_name must be 1 to 100 characters"))
+		return
+	case teamName == "" || len(teamName) > 100:
+		helper.RespondError(w, r, apperror.BadRequest("team_name must be 1 to 100 characters"))
+		return
+	case len(email) < 4 || !strings.Contains(email, "@"):
+		helper.RespondError(w, r, apperror.BadRequest("Invalid email address"))
+		return
+	case len(password) < 8:
+		helper.RespondError(w, r, apperror.BadRequest("Password must be at least 8 characters"))
+		return
+	}
+
+	passwordHash, err := secure.HashPassword(password)
+	if err != nil {
+		logger.Error(ctx, "setup: hash password failed", "err", err)
+		helper.RespondError(w, r, apperror.InternalError("internal server error", err))
+		return
+	}
+
+	out, err := h.setupStore.Bootstrap(ctx, setupstore.Bootstrap{
+		OrgName:      orgName,
+		Email:        email,
+		PasswordHash: passwordHash,
+		TeamName:     teamName,
+	}, time.Now().UTC())
+	if err != nil {
+		if errors.Is(err, setupstore.ErrAlreadySetUp) {
+			helper.RespondError(w, r, apperror.NotFound("setup already completed"))
+			return
+		}
+		logger.Error(ctx, "setup: store error", "err", err)
+		helper.RespondError(w, r, apperror.InternalError("internal error", err))
+		return
+	}
+
+	logger.Info(ctx, "setup: completed",
+		"user_id", out.Admin.ID,
+		"team_id", out.Team.ID,
+		"org_name", out.OrgName,
+	)
+	helper.RespondJSON(w, r, http.StatusCreated, out)
+}
@@ -0,0 +1,171 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/diagnosis/interactive-todo/api/types"
+	"github.com/diagnosis/interactive-todo/internal/apperror"
+	"github.com/diagnosis/interactive-todo/internal/deadline"
+	"github.com/diagnosis/interactive-todo/internal/helper"
+	"github.com/diagnosis/interactive-todo/internal/logger"
+	editlockstore "github.com/diagnosis/interactive-todo/internal/store/editlocks"
+	taskstore "github.com/diagnosis/interactive-todo/internal/store/tasks"
+	"github.com/google/uuid"
+)
+
+// GetEditLock tells whether someone is editing the task's description.
+func (h *RealtimeHandler) GetEditLock(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := deadline.Budget(r.Context(), 5*time.Second)
+	defer cancel()
+
+	task, _, ok := h.visibleTask(ctx, w, r, "get edit lock")
+	if !ok {
+		return
+	}
+	lock, err := h.lockStore.Current(ctx, task.ID, time.Now().UTC())
+	if err != nil {
+		logger.Error(ctx, "get edit lock: store error", "err", err)
+		helper.RespondError(w, r, apperror.InternalError("internal error", err))
+		return
+	}
+	helper.RespondJSON(w, r, http.StatusOK, types.EditLockStatus{TaskID: task.ID, Lock: lock})
+}
+
+// AcquireEditLock takes the lock on the task's description when the
+// editor opens. If someone else holds it the answer is still 200, with
+// acquired false and their lock, so the client can warn before the user
+// types over their work. Only those who may change the description can
+// take the lock.
+func (h *RealtimeHandler) AcquireEditLock(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := deadline.Budget(r.Context(), 5*time.Second)
+	defer cancel()
+
+	task, userID, ok := h.editableTask(ctx, w, r, "acquire edit lock")
+	if !ok {
+		return
+	}
+
+	now := time.Now().UTC()
+	lock, lockID, acquired, err := h.lockStore.Acquire(ctx, task.ID, userID, now)
+	if err != nil {
+		logger.Error(ctx, "acquire edit lock: store error", "err", err)
+		helper.RespondError(w, r, apperror.InternalError("internal error", err))
+		return
+	}
+	out := types.EditLockResponse{Acquired: acquired, Lock: *lock}
+	if acquired {
+		out.LockID = &lockID
+		h.publishLock(ctx, task, lock, true, now)
+		logger.Info(ctx, "edit lock acquired", "task_id", task.ID, "user_id", userID)
+	}
+	helper.RespondJSON(w, r, http.StatusOK, out)
+}
+
+// HeartbeatEditLock renews the caller's lock; clients call it every
+// editlockstore.HeartbeatEvery while the editor is open. A lock someone
+// else took after it lapsed returns 409.
+func (h *RealtimeHandler) HeartbeatEditLock(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := deadline.Budget(r.Context(), 5*time.Second)
+	defer cancel()
+
+	task, _, ok := h.editableTask(ctx, w, r, "renew edit lock")
+	if !ok {
+		return
+	}
+	lockID, ok := decodeLockID(ctx, w, r, "renew edit lock")
+	if !ok {
+		return
+	}
+
+	now := time.Now().UTC()
+	lock, err := h.lockStore.Heartbeat(ctx, task.ID, lockID, now)
+	if err != nil {
+		if errors.Is(err, editlockstore.ErrNotHeld) {
+			helper.RespondError(w, r, apperror.Conflict("edit lock was lost; acquire it again"))
+			return
+		}
+		logger.Error(ctx, "renew edit lock: store error", "err", err)
+		helper.RespondError(w, r, apperror.InternalError("internal error", err))
+		return
+	}
+	h.publishLock(ctx, task, lock, true, now)
+	helper.RespondJSON(w, r, http.StatusOK, types.EditLockResponse{Acquired: true, LockID: &lockID, Lock: *lock})
+}
+
+// ReleaseEditLock frees the caller's lock when the editor closes. It is a
+// POST so browsers can send it with sendBeacon on unload. Releasing a
+// lock that was already lost is not an error.
+func (h *RealtimeHandler) ReleaseEditLock(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := deadline.Budget(r.Context(), 5*time.Second)
+	defer cancel()
+
+	task, _, ok := h.editableTask(ctx, w, r, "release edit lock")
+	if !ok {
+		return
+	}
+	lockID, ok := decodeLockID(ctx, w, r, "release edit lock")
+	if !ok {
+		return
+	}
+
+	lock, err := h.lockStore.Release(ctx, task.ID, lockID)
+	if err != nil && !errors.Is(err, editlockstore.ErrNotHeld) {
+		logger.Error(ctx, "release edit lock: store error", "err", err)
+		helper.RespondError(w, r, apperror.InternalError("internal error", err))
+		return
+	}
+	if err == nil {
+		h.publishLock(ctx, task, lock, false, time.Now().UTC())
+		logger.Info(ctx, "edit lock released", "task_id", task.ID, "user_id", lock.UserID)
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// editableTask is visibleTask for a caller who may also change the
+// description, as PATCH /tasks/{id}/update-details allows: the reporter.
+func (h *RealtimeHandler) editableTask(ctx context.Context, w http.ResponseWriter, r *http.Request, op string) (*taskstore.Task, uuid.UUID, bool) {
+	task, userID, ok := h.visibleTask(ctx, w, r, op)
+	if !ok {
+		return nil, uuid.Nil, false
+	}
+	if task.ReporterID != userID {
+		helper.RespondError(w, r, apperror.Forbidden("only the creator can edit the description"))
+		return nil, uuid.Nil, false
+	}
+	return task, userID, true
+}
+
+// publishLock tells the task's viewers about the lock. The lock itself is
+// already stored, so a failed broadcast is only logged.
+func (h *RealtimeHandler) publishLock(ctx context.Context, task *taskstore.Task, lock *types.EditLock, locked bool, now time.Time) {
+	publish := h.bridge.EditLocked
+	if !locked {
+		publish = h.bridge.EditUnlocked
+	}
+	if err := publish(ctx, task, lock, now); err != nil {
+		logger.Error(ctx, "edit lock: broadcast failed", "task_id", task.ID, "err", err)
+	}
+}
+
+func decodeLockID(ctx context.Context, w http.ResponseWriter, r *http.Request, op string) (uuid.UUID, bool) {
+	r.Body = http.MaxBytesReader(w, r.Body, 1<<10)
+	defer r.Body.Close()
+
+	var in types.EditLockRequest
+	dec := json.NewDecoder(r.Body)
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&in); err != nil {
+		logger.Error(ctx, op+": bad json", "err", err)
+		helper.RespondError(w, r, apperror.BadRequest("invalid request body"))
+		return uuid.Nil, false
+	}
+	if in.LockID == uuid.Nil {
+		helper.RespondError(w, r, apperror.BadRequest("lock_id is required"))
+		return uuid.Nil, false
+	}
+	return in.LockID, true
+}
@@ -0,0 +1,163 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/diagnosis/interactive-todo/internal/apperror"
+	"github.com/diagnosis/interactive-todo/internal/deadline"
+	"github.com/diagnosis/interactive-todo/internal/helper"
+	"github.com/diagnosis/interactive-todo/internal/logger"
+	middleware "github.com/diagnosis/interactive-todo/internal/middleware/auth"
+	"github.com/diagnosis/interactive-todo/internal/realtime"
+	editlockstore "github.com/diagnosis/interactive-todo/internal/store/editlocks"
+	taskstore "github.com/diagnosis/interactive-todo/internal/store/tasks"
+	teameventstore "github.com/diagnosis/interactive-todo/internal/store/teamevents"
+	teamstore "github.com/diagnosis/interactive-todo/internal/store/teams"
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+)
+
+type RealtimeHandler struct {
+	teamStore  teamstore.TeamStore
+	taskStore  taskstore.TaskStore
+	eventStore teameventstore.TeamEventStore
+	lockStore  editlockstore.EditLockStore
+	hub        *realtime.Hub
+	bridge     *realtime.Bridge
+}
+
+func NewRealtimeHandler(ts teamstore.TeamStore, tks taskstore.TaskStore, es teameventstore.TeamEventStore, ls editlockstore.EditLockStore, hub *realtime.Hub, bridge *realtime.Bridge) *RealtimeHandler {
+	return &RealtimeHandler{teamStore: ts, taskStore: tks, eventStore: es, lockStore: ls, hub: hub, bridge: bridge}
+}
+
+// StreamTeamEvents streams the team's task events as server-sent events.
+// Private tasks only reach the users who may see them. A client resuming
+// with Last-Event-ID (or ?last_event_id=, for the first connection of an
+// EventSource) first gets the events it missed.
+func (h *RealtimeHandler) StreamTeamEvents(w http.ResponseWriter, r *http.Request) {
+	userID, ok := middleware.GetUserIDFromContext(r.Context())
+	if !ok {
+		helper.RespondError(w, r, apperror.Unauthorized("unauthorized"))
+		return
+	}
+
+	teamID, err := uuid.Parse(chi.URLParam(r, "team_id"))
+	if err != nil {
+		helper.RespondError(w, r, apperror.BadRequest("invalid team id"))
+		return
+	}
+
+	var catchUp realtime.CatchUp
+	lastID := r.Header.Get("Last-Event-ID")
+	if lastID == "" {
+		lastID = r.URL.Query().Get("last_event_id")
+	}
+	if lastID != "" {
+		after, err := strconv.ParseInt(lastID, 10, 64)
+		if err != nil || after < 0 {
+			helper.RespondError(w, r, apperror.BadRequest("Last-Event-ID must be an event id from this stream"))
+			return
+		}
+		catchUp = func(ctx context.Context) ([]realtime.Message, bool, error) {
+			ctx, cancel := deadline.Budget(ctx, 5*time.Second)
+			defer cancel()
+			stored, complete, err := h.eventStore.Since(ctx, teamID, userID, after)
+			if err != nil {
+				return nil, false, err
+			}
+			missed := make([]realtime.Message, len(stored))
+			for i, e := range stored {
+				missed[i] = realtime.Message{Seq: e.Seq, Type: e.Type, Data: e.Data}
+			}
+			return missed, complete, nil
+		}
+	}
+
+	// only the membership check has a budget; the stream runs on
+	ctx, cancel := deadline.Budget(r.Context(), 5*time.Second)
+	isMember, err := h.teamStore.IsMember(ctx, teamID, userID)
+	cancel()
+	if err != nil {
+		logger.Error(r.Context(), "stream events: membership check failed", "err", err)
+		helper.RespondError(w, r, apperror.InternalError("internal error", err))
+		return
+	}
+	if !isMember {
+		helper.RespondError(w, r, apperror.Forbidden("only team members can stream team events"))
+		return
+	}
+
+	logger.Info(r.Context(), "stream events: open", "team_id", teamID, "user_id", userID)
+	if err := h.hub.Serve(w, r, teamID, userID, catchUp); err != nil {
+		if errors.Is(err, realtime.ErrDraining) {
+			w.Header().Set("Retry-After", strconv.Itoa(int(realtime.RetryAfter.Seconds())))
+			helper.RespondError(w, r, apperror.ServiceUnavailable("server is shutting down, reconnect shortly"))
+			return
+		}
+		logger.Error(r.Context(), "stream events: failed", "team_id", teamID, "err", err)
+		helper.RespondError(w, r, apperror.InternalError("internal error", err))
+	}
+}
+
+// CommentTyping shows the other people streaming the task's team that the
+// caller is writing a comment on it. Clients call it every few seconds
+// while the user types; calls closer than realtime.TypingInterval are
+// accepted and dropped. Nothing is stored.
+func (h *RealtimeHandler) CommentTyping(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := deadline.Budget(r.Context(), 5*time.Second)
+	defer cancel()
+
+	task, userID, ok := h.visibleTask(ctx, w, r, "comment typing")
+	if !ok {
+		return
+	}
+
+	if _, err := h.bridge.Typing(ctx, task, userID, time.Now().UTC()); err != nil {
+		logger.Error(ctx, "comment typing: publish failed", "task_id", task.ID, "err", err)
+		helper.RespondError(w, r, apperror.InternalError("internal error", err))
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// visibleTask loads the task in the URL for a team member who may see it.
+// On failure it has already written the error response.
+func (h *RealtimeHandler) visibleTask(ctx context.Context, w http.ResponseWriter, r *http.Request, op string) (*taskstore.Task, uuid.UUID, bool) {
+	userID, ok := middleware.GetUserIDFromContext(ctx)
+	if !ok {
+		helper.RespondError(w, r, apperror.Unauthorized("unauthorized"))
+		return nil, uuid.Nil, false
+	}
+
+	taskID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		helper.RespondError(w, r, apperror.BadRequest("invalid task id"))
+		return nil, uuid.Nil, false
+	}
+
+	task, err := h.taskStore.GetVisibleTaskByID(ctx, taskID, userID)
+	if err != nil {
+		if errors.Is(err, taskstore.ErrTaskNotFound) {
+			helper.RespondError(w, r, apperror.NotFound("task not found"))
+			return nil, uuid.Nil, false
+		}
+		logger.Error(ctx, op+": failed to get task", "err", err)
+		helper.RespondError(w, r, apperror.InternalError("internal error", err))
+		return nil, uuid.Nil, false
+	}
+	isMember, err := h.teamStore.IsMember(ctx, task.TeamID, userID)
+	if err != nil {
+		logger.Error(ctx, op+": membership check failed", "err", err)
+		helper.RespondError(w, r, apperror.InternalError("internal error", err))
+		return nil, uuid.Nil, false
+	}
+	if !isMember {
+		helper.RespondError(w, r, apperror.Forbidden("only team members can see this task"))
+		return nil, uuid.Nil, false
+	}
+	return task, userID, true
+}
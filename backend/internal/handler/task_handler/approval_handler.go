@@ -0,0 +1,229 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/diagnosis/interactive-todo/api/types"
+	"github.com/diagnosis/interactive-todo/internal/apperror"
+	"github.com/diagnosis/interactive-todo/internal/deadline"
+	"github.com/diagnosis/interactive-todo/internal/helper"
+	"github.com/diagnosis/interactive-todo/internal/logger"
+	middleware "github.com/diagnosis/interactive-todo/internal/middleware/auth"
+	approvalstore "github.com/diagnosis/interactive-todo/internal/store/approvals"
+	store "github.com/diagnosis/interactive-todo/internal/store/tasks"
+)
+
+// routeThroughApproval intercepts status updates for teams that require
+// approval. It returns true when it has already written the response.
+// Self-reported tasks skip approval since reporter and assignee are the same.
+func (h *TaskHandler) routeThroughApproval(
+	ctx context.Context,
+	w http.ResponseWriter,
+	r *http.Request,
+	task *store.Task,
+	newStatus store.TaskStatus,
+) bool {
+	_, err := h.approvalStore.GetPending(ctx, task.ID)
+	switch {
+	case err == nil:
+		helper.RespondError(w, r, apperror.Conflict("task is awaiting approval"))
+		return true
+	case !errors.Is(err, approvalstore.ErrApprovalNotFound):
+		logger.Error(ctx, "update status: pending approval check failed", "err", err)
+		helper.RespondError(w, r, apperror.InternalError("internal error", err))
+		return true
+	}
+
+	if newStatus != store.DoneStatus || task.ReporterID == task.AssigneeID {
+		return false
+	}
+
+	settings, err := h.teamStore.GetSettings(ctx, task.TeamID)
+	if err != nil {
+		logger.Error(ctx, "update status: get team settings failed", "err", err)
+		helper.RespondError(w, r, apperror.InternalError("internal error", err))
+		return true
+	}
+	if !settings.RequiresApproval {
+		return false
+	}
+
+	approval, err := h.approvalStore.Request(ctx, task.ID, task.AssigneeID, time.Now().UTC())
+	if err != nil {
+		if errors.Is(err, approvalstore.ErrApprovalPending) {
+			helper.RespondError(w, r, apperror.Conflict("task is awaiting approval"))
+			return true
+		}
+		logger.Error(ctx, "update status: request approval failed", "err", err)
+		helper.RespondError(w, r, apperror.InternalError("internal error", err))
+		return true
+	}
+
+	logger.Info(ctx, "task approval requested", "task_id", task.ID, "approval_id", approval.ID)
+	helper.RespondJSON(w, r, http.StatusAccepted, types.TaskApprovalResponse{Task: task, Approval: approval})
+	return true
+}
+
+func (h *TaskHandler) ListApprovals(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := deadline.Budget(r.Context(), 5*time.Second)
+	defer cancel()
+
+	userID, ok := middleware.GetUserIDFromContext(ctx)
+	if !ok {
+		helper.RespondError(w, r, apperror.Unauthorized("unauthorized"))
+		return
+	}
+
+	taskID, err := parseTaskID(r)
+	if err != nil {
+		logger.Error(ctx, "list approvals: invalid task id", "err", err)
+		helper.RespondError(w, r, apperror.BadRequest("invalid task id"))
+		return
+	}
+
+	task, err := h.getTaskByID(ctx, taskID, userID)
+	if err != nil {
+		if errors.Is(err, store.ErrTaskNotFound) {
+			helper.RespondError(w, r, apperror.NotFound("task not found"))
+			return
+		}
+		logger.Error(ctx, "list approvals: failed to get task", "err", err)
+		helper.RespondError(w, r, apperror.InternalError("internal error", err))
+		return
+	}
+
+	isMember, err := h.teamStore.IsMember(ctx, task.TeamID, userID)
+	if err != nil {
+		logger.Error(ctx, "list approvals: membership check failed", "err", err)
+		helper.RespondError(w, r, apperror.InternalError("internal error", err))
+		return
+	}
+	if !isMember {
+		helper.RespondError(w, r, apperror.Forbidden("forbidden"))
+		return
+	}
+
+	approvals, err := h.approvalStore.ListForTask(ctx, taskID)
+	if err != nil {
+		logger.Error(ctx, "list approvals: store query failed", "err", err)
+		helper.RespondError(w, r, apperror.InternalError("internal error", err))
+		return
+	}
+
+	helper.RespondJSON(w, r, http.StatusOK, types.ApprovalListResponse{TaskID: taskID, Approvals: approvals})
+}
+
+func (h *TaskHandler) ApproveTask(w http.ResponseWriter, r *http.Request) {
+	h.decideApproval(w, r, true)
+}
+
+func (h *TaskHandler) RejectTask(w http.ResponseWriter, r *http.Request) {
+	h.decideApproval(w, r, false)
+}
+
+func (h *TaskHandler) decideApproval(w http.ResponseWriter, r *http.Request, approve bool) {
+	ctx, cancel := deadline.Budget(r.Context(), 5*time.Second)
+	defer cancel()
+
+	userID, ok := middleware.GetUserIDFromContext(ctx)
+	if !ok {
+		helper.RespondError(w, r, apperror.Unauthorized("unauthorized"))
+		return
+	}
+
+	taskID, err := parseTaskID(r)
+	if err != nil {
+		logger.Error(ctx, "decide approval: invalid task id", "err", err)
+		helper.RespondError(w, r, apperror.BadRequest("invalid task id"))
+		return
+	}
+
+	task, err := h.getTaskByID(ctx, taskID, userID)
+	if err != nil {
+		if errors.Is(err, store.ErrTaskNotFound) {
+			helper.RespondError(w, r, apperror.NotFound("task not found"))
+			return
+		}
+		logger.Error(ctx, "decide approval: failed to get task", "err", err)
+		helper.RespondError(w, r, apperror.InternalError("internal error", err))
+		return
+	}
+
+	now := time.Now().UTC()
+	allowed, delegated, err := h.actsAsReporter(ctx, task, userID, now)
+	if err != nil {
+		logger.Error(ctx, "decide approval: delegation check failed", "err", err)
+		helper.RespondError(w, r, apperror.InternalError("internal error", err))
+		return
+	}
+	if !allowed {
+		logger.Info(ctx, "decide approval: forbidden (not reporter)",
+			"user_id", userID,
+			"reporter_id", task.ReporterID,
+		)
+		helper.RespondError(w, r, apperror.Forbidden("only task creator can approve or reject"))
+		return
+	}
+
+	var in struct {
+		Reason *string `json:"reason"`
+	}
+	if r.ContentLength != 0 {
+		r.Body = http.MaxBytesReader(w, r.Body, 1<<20)
+		defer r.Body.Close()
+
+		dec := json.NewDecoder(r.Body)
+		dec.DisallowUnknownFields()
+		if err := dec.Decode(&in); err != nil {
+			logger.Error(ctx, "decide approval: bad json", "err", err)
+			helper.RespondError(w, r, apperror.BadRequest("invalid request body"))
+			return
+		}
+	}
+	if in.Reason != nil {
+		reason := strings.TrimSpace(*in.Reason)
+		if len(reason) > 1000 {
+			helper.RespondError(w, r, apperror.BadRequest("reason too long (max 1000 chars)"))
+			return
+		}
+		in.Reason = &reason
+	}
+	if !approve && (in.Reason == nil || *in.Reason == "") {
+		helper.RespondError(w, r, apperror.BadRequest("reason is required when rejecting"))
+		return
+	}
+
+	approval, err := h.approvalStore.Decide(ctx, taskID, userID, approve, in.Reason, now)
+	if err != nil {
+		if errors.Is(err, approvalstore.ErrApprovalNotFound) {
+			helper.RespondError(w, r, apperror.NotFound("no pending approval for this task"))
+			return
+		}
+		logger.Error(ctx, "decide approval: store failed", "err", err)
+		helper.RespondError(w, r, apperror.InternalError("internal error", err))
+		return
+	}
+
+	updatedTask, err := h.getTaskByID(ctx, taskID, userID)
+	if err != nil {
+		logger.Error(ctx, "decide approval: reload task failed", "err", err)
+		helper.RespondError(w, r, apperror.InternalError("internal error", err))
+		return
+	}
+	h.publishStatusChanged(ctx, updatedTask, task.Status, userID, now)
+	if delegated {
+		action := "reject"
+		if approve {
+			action = "approve"
+		}
+		h.recordDelegationUse(ctx, r, updatedTask, userID, action, now)
+	}
+
+	logger.Info(ctx, "task approval decided", "task_id", taskID, "approved", approve)
+	helper.RespondJSON(w, r, http.StatusOK, types.TaskApprovalResponse{Task: updatedTask, Approval: approval})
+}
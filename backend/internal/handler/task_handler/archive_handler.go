@@ -0,0 +1,92 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/diagnosis/interactive-todo/internal/apperror"
+	"github.com/diagnosis/interactive-todo/internal/deadline"
+	"github.com/diagnosis/interactive-todo/internal/helper"
+	"github.com/diagnosis/interactive-todo/internal/logger"
+	middleware "github.com/diagnosis/interactive-todo/internal/middleware/auth"
+	store "github.com/diagnosis/interactive-todo/internal/store/tasks"
+)
+
+// ArchiveTask takes a task out of the team's lists without deleting it.
+// The reporter and team owners/admins can archive and unarchive a task.
+func (h *TaskHandler) ArchiveTask(w http.ResponseWriter, r *http.Request) {
+	h.setArchived(w, r, true)
+}
+
+// UnarchiveTask puts an archived task back in the lists.
+func (h *TaskHandler) UnarchiveTask(w http.ResponseWriter, r *http.Request) {
+	h.setArchived(w, r, false)
+}
+
+func (h *TaskHandler) setArchived(w http.ResponseWriter, r *http.Request, archive bool) {
+	ctx, cancel := deadline.Budget(r.Context(), 5*time.Second)
+	defer cancel()
+
+	op := "archive task"
+	if !archive {
+		op = "unarchive task"
+	}
+
+	userID, ok := middleware.GetUserIDFromContext(ctx)
+	if !ok {
+		helper.RespondError(w, r, apperror.Unauthorized("authentication required"))
+		return
+	}
+
+	taskID, err := parseTaskID(r)
+	if err != nil {
+		helper.RespondError(w, r, apperror.BadRequest("invalid task id"))
+		return
+	}
+
+	task, err := h.getTaskByID(ctx, taskID, userID)
+	if err != nil {
+		if errors.Is(err, store.ErrTaskNotFound) {
+			helper.RespondError(w, r, apperror.NotFound("task not found"))
+			return
+		}
+		logger.Error(ctx, op+": failed to get task", "err", err)
+		helper.RespondError(w, r, apperror.InternalError("internal error", err))
+		return
+	}
+
+	allowed := userID == task.ReporterID
+	if !allowed {
+		if allowed, err = h.teamStore.IsOwnerOrAdmin(ctx, task.TeamID, userID); err != nil {
+			logger.Error(ctx, op+": role check failed", "err", err)
+			helper.RespondError(w, r, apperror.InternalError("internal error", err))
+			return
+		}
+	}
+	if !allowed {
+		logger.Info(ctx, op+": forbidden", "user_id", userID, "task_id", task.ID)
+		helper.RespondError(w, r, apperror.Forbidden("only the task creator or team owner/admin can archive or unarchive"))
+		return
+	}
+
+	set := h.taskStore.ArchiveTask
+	if !archive {
+		set = h.taskStore.UnarchiveTask
+	}
+	updated, err := set(ctx, taskID, &userID, time.Now().UTC())
+	if err != nil {
+		if errors.Is(err, store.ErrTaskNotFound) {
+			helper.RespondError(w, r, apperror.NotFound("task not found"))
+			return
+		}
+		logger.Error(ctx, op+": store update failed", "err", err)
+		helper.RespondError(w, r, apperror.InternalError("internal error", err))
+		return
+	}
+
+	logger.Info(ctx, op, "task_id", taskID, "user_id", userID)
+	setTaskETag(w, updated)
+	h.localize(ctx, updated)
+	helper.RespondJSON(w, r, http.StatusOK, updated)
+}
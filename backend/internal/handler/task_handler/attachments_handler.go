@@ -0,0 +1,441 @@
+package handler
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"os"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/diagnosis/interactive-todo/api/types"
+	"github.com/diagnosis/interactive-todo/internal/apperror"
+	jwttoken "github.com/diagnosis/interactive-todo/internal/auth/jwt"
+	"github.com/diagnosis/interactive-todo/internal/auth/signedurl"
+	"github.com/diagnosis/interactive-todo/internal/deadline"
+	"github.com/diagnosis/interactive-todo/internal/helper"
+	"github.com/diagnosis/interactive-todo/internal/logger"
+	middleware "github.com/diagnosis/interactive-todo/internal/middleware/auth"
+	"github.com/diagnosis/interactive-todo/internal/storage"
+	attachmentstore "github.com/diagnosis/interactive-todo/internal/store/attachments"
+	store "github.com/diagnosis/interactive-todo/internal/store/tasks"
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+)
+
+const (
+	maxTaskAttachments = 20
+	// uploads and downloads outlive the usual budget on slow links; the
+	// router's timeout still caps them
+	attachmentBudget  = time.Minute
+	maxFilenameLength = 255
+)
+
+// attachmentTypes are the content types accepted, as detected from the
+// first bytes of the file. Office documents are zip files underneath.
+var attachmentTypes = map[string]bool{
+	"image/png":          true,
+	"image/jpeg":         true,
+	"image/gif":          true,
+	"image/webp":         true,
+	"application/pdf":    true,
+	"text/plain":         true,
+	"application/zip":    true,
+	"application/x-gzip": true,
+}
+
+// Attachments configures files on tasks.
+type Attachments struct {
+	Store attachmentstore.AttachmentStore
+	Files storage.Store
+	// Prefix is put in front of every storage key
+	Prefix   string
+	MaxBytes int64
+}
+
+// UploadAttachment stores the multipart field "file" on a task. Any team
+// member who can see the task may attach files.
+func (h *TaskHandler) UploadAttachment(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := deadline.Budget(r.Context(), attachmentBudget)
+	defer cancel()
+
+	task, userID, ok := h.attachmentTask(ctx, w, r, "upload attachment")
+	if !ok {
+		return
+	}
+
+	n, err := h.attachments.Store.CountForTask(ctx, task.ID)
+	if err != nil {
+		logger.Error(ctx, "upload attachment: count failed", "err", err)
+		helper.RespondError(w, r, apperror.InternalError("internal error", err))
+		return
+	}
+	if n >= maxTaskAttachments {
+		helper.RespondError(w, r, apperror.BadRequest(fmt.Sprintf("a task can have at most %d attachments", maxTaskAttachments)))
+		return
+	}
+
+	rc := http.NewResponseController(w)
+	if err := rc.SetReadDeadline(time.Now().Add(attachmentBudget)); err != nil {
+		logger.Warn(ctx, "upload attachment: cannot extend read deadline", "err", err)
+	}
+	// room for the multipart framing around the file
+	r.Body = http.MaxBytesReader(w, r.Body, h.attachments.MaxBytes+1<<20)
+	defer r.Body.Close()
+
+	mr, err := r.MultipartReader()
+	if err != nil {
+		helper.RespondError(w, r, apperror.BadRequest("expected a multipart/form-data body"))
+		return
+	}
+	var (
+		filename string
+		tmp      *os.File
+		size     int64
+		sum      string
+	)
+	for {
+		part, err := mr.NextPart()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			h.uploadError(ctx, w, r, err)
+			return
+		}
+		if part.FormName() != "file" || tmp != nil {
+			_ = part.Close()
+			continue
+		}
+		filename = cleanFilename(part.FileName())
+		tmp, size, sum, err = spool(part, h.attachments.MaxBytes)
+		_ = part.Close()
+		if err != nil {
+			h.uploadError(ctx, w, r, err)
+			return
+		}
+		defer func() {
+			_ = tmp.Close()
+			_ = os.Remove(tmp.Name())
+		}()
+	}
+	if tmp == nil {
+		helper.RespondError(w, r, apperror.BadRequest(`the form field "file" is required`))
+		return
+	}
+	if size == 0 {
+		helper.RespondError(w, r, apperror.BadRequest("file is empty"))
+		return
+	}
+
+	head := make([]byte, 512)
+	hn, _ := tmp.ReadAt(head, 0)
+	contentType := http.DetectContentType(head[:hn])
+	if base, _, _ := mime.ParseMediaType(contentType); !attachmentTypes[base] {
+		helper.RespondError(w, r, apperror.UnsupportedMediaType(fmt.Sprintf("files of type %s cannot be attached", base)))
+		return
+	}
+	if _, err := tmp.Seek(0, io.SeekStart); err != nil {
+		logger.Error(ctx, "upload attachment: rewind failed", "err", err)
+		helper.RespondError(w, r, apperror.InternalError("internal error", err))
+		return
+	}
+
+	now := time.Now().UTC()
+	a := &attachmentstore.Attachment{
+		ID:          uuid.New(),
+		TaskID:      task.ID,
+		UploaderID:  &userID,
+		Filename:    filename,
+		ContentType: contentType,
+		SizeBytes:   size,
+		SHA256:      sum,
+		CreatedAt:   now,
+	}
+	a.StorageKey = h.attachments.Prefix + task.TeamID.String() + "/" + task.ID.String() + "/" + a.ID.String()
+
+	if err := h.attachments.Files.Put(ctx, a.StorageKey, tmp, size, sum); err != nil {
+		logger.Error(ctx, "upload attachment: store file failed", "err", err)
+		helper.RespondError(w, r, apperror.InternalError("internal error", err))
+		return
+	}
+	if err := h.attachments.Store.Create(ctx, a); err != nil {
+		// the row is what the purge job goes by, so the file is removed here
+		if derr := h.attachments.Files.Delete(context.WithoutCancel(ctx), a.StorageKey); derr != nil {
+			logger.Error(ctx, "upload attachment: orphaned file", "key", a.StorageKey, "err", derr)
+		}
+		logger.Error(ctx, "upload attachment: store row failed", "err", err)
+		helper.RespondError(w, r, apperror.InternalError("internal error", err))
+		return
+	}
+
+	logger.Info(ctx, "attachment uploaded", "task_id", task.ID, "attachment_id", a.ID, "user_id", userID, "size", size, "content_type", contentType)
+	helper.RespondJSON(w, r, http.StatusCreated, a)
+}
+
+func (h *TaskHandler) ListAttachments(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := deadline.Budget(r.Context(), 5*time.Second)
+	defer cancel()
+
+	task, _, ok := h.attachmentTask(ctx, w, r, "list attachments")
+	if !ok {
+		return
+	}
+
+	list, err := h.attachments.Store.ListForTask(ctx, task.ID)
+	if err != nil {
+		logger.Error(ctx, "list attachments: store query failed", "err", err)
+		helper.RespondError(w, r, apperror.InternalError("internal error", err))
+		return
+	}
+	helper.RespondJSON(w, r, http.StatusOK, types.AttachmentListResponse{TaskID: task.ID, Attachments: list})
+}
+
+// DownloadAttachment streams the file. It also accepts a signed URL from
+// CreateAttachmentLink. Images are shown inline, everything else is
+// downloaded, and nothing is run by the browser.
+func (h *TaskHandler) DownloadAttachment(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := deadline.Budget(r.Context(), attachmentBudget)
+	defer cancel()
+
+	a, _, ok := h.loadAttachment(ctx, w, r, "download attachment")
+	if !ok {
+		return
+	}
+
+	if r.Header.Get("If-None-Match") == `"`+a.SHA256+`"` {
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+
+	body, err := h.attachments.Files.Get(ctx, a.StorageKey)
+	if err != nil {
+		if errors.Is(err, storage.ErrNotFound) {
+			logger.Error(ctx, "download attachment: file missing", "attachment_id", a.ID, "key", a.StorageKey)
+			helper.RespondError(w, r, apperror.NotFound("attachment file not found"))
+			return
+		}
+		logger.Error(ctx, "download attachment: open failed", "err", err)
+		helper.RespondError(w, r, apperror.InternalError("internal error", err))
+		return
+	}
+	defer body.Close()
+
+	rc := http.NewResponseController(w)
+	if err := rc.SetWriteDeadline(time.Now().Add(attachmentBudget)); err != nil {
+		logger.Warn(ctx, "download attachment: cannot extend write deadline", "err", err)
+	}
+
+	disposition := "attachment"
+	if strings.HasPrefix(a.ContentType, "image/") {
+		disposition = "inline"
+	}
+	w.Header().Set("Content-Type", a.ContentType)
+	w.Header().Set("Content-Length", strconv.FormatInt(a.SizeBytes, 10))
+	w.Header().Set("Content-Disposition", mime.FormatMediaType(disposition, map[string]string{"filename": a.Filename}))
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	w.Header().Set("Content-Security-Policy", "default-src 'none'; sandbox")
+	w.Header().Set("Cache-Control", "private, max-age=300")
+	w.Header().Set("ETag", `"`+a.SHA256+`"`)
+	w.WriteHeader(http.StatusOK)
+	if _, err := io.Copy(w, body); err != nil {
+		logger.Warn(ctx, "download attachment: copy failed", "attachment_id", a.ID, "err", err)
+	}
+}
+
+// CreateAttachmentLink returns a signed URL for DownloadAttachment that a
+// browser can open without the Authorization header. It is valid for
+// signedurl.MaxTTL.
+func (h *TaskHandler) CreateAttachmentLink(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := deadline.Budget(r.Context(), 5*time.Second)
+	defer cancel()
+
+	claims, ok := middleware.GetClaimsFromContext(ctx)
+	if !ok {
+		helper.RespondError(w, r, apperror.Unauthorized("authentication required"))
+		return
+	}
+	// a link must not grant more than the token asking for it
+	if !claims.HasScope(jwttoken.ScopeTasksRead) {
+		helper.RespondError(w, r, apperror.Forbidden("token is missing scope "+string(jwttoken.ScopeTasksRead)))
+		return
+	}
+
+	a, _, ok := h.loadAttachment(ctx, w, r, "create attachment link")
+	if !ok {
+		return
+	}
+
+	// sign the download path under the same version prefix this link was requested on
+	p := strings.TrimSuffix(r.URL.Path, "/link")
+	signed, expiresAt := h.urlSigner.Sign(p, nil, claims.UserID, jwttoken.ScopeTasksRead, signedurl.MaxTTL, time.Now())
+
+	logger.Info(ctx, "create attachment link: success", "user_id", claims.UserID, "attachment_id", a.ID)
+	helper.RespondJSON(w, r, http.StatusCreated, types.AttachmentLink{URL: p + "?" + signed.Encode(), ExpiresAt: expiresAt})
+}
+
+// DeleteAttachment removes an attachment. The uploader, the task's
+// reporter and team owners/admins may. The file is deleted by the purge
+// job shortly after.
+func (h *TaskHandler) DeleteAttachment(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := deadline.Budget(r.Context(), 5*time.Second)
+	defer cancel()
+
+	a, task, ok := h.loadAttachment(ctx, w, r, "delete attachment")
+	if !ok {
+		return
+	}
+	userID, _ := middleware.GetUserIDFromContext(ctx)
+
+	if (a.UploaderID == nil || *a.UploaderID != userID) && task.ReporterID != userID {
+		isAdmin, err := h.teamStore.IsOwnerOrAdmin(ctx, task.TeamID, userID)
+		if err != nil {
+			logger.Error(ctx, "delete attachment: role check failed", "err", err)
+			helper.RespondError(w, r, apperror.InternalError("internal error", err))
+			return
+		}
+		if !isAdmin {
+			helper.RespondError(w, r, apperror.Forbidden("only the uploader, the reporter or team owner/admin can delete an attachment"))
+			return
+		}
+	}
+
+	if err := h.attachments.Store.Delete(ctx, task.ID, a.ID); err != nil {
+		if errors.Is(err, attachmentstore.ErrAttachmentNotFound) {
+			helper.RespondError(w, r, apperror.NotFound("attachment not found"))
+			return
+		}
+		logger.Error(ctx, "delete attachment: store delete failed", "err", err)
+		helper.RespondError(w, r, apperror.InternalError("internal error", err))
+		return
+	}
+
+	logger.Info(ctx, "attachment deleted", "task_id", task.ID, "attachment_id", a.ID, "user_id", userID)
+	helper.RespondMessage(w, r, http.StatusOK, "attachment deleted")
+}
+
+// attachmentTask loads the task in the URL for a team member who may see
+// it.
+func (h *TaskHandler) attachmentTask(ctx context.Context, w http.ResponseWriter, r *http.Request, op string) (*store.Task, uuid.UUID, bool) {
+	userID, ok := middleware.GetUserIDFromContext(ctx)
+	if !ok {
+		helper.RespondError(w, r, apperror.Unauthorized("unauthorized"))
+		return nil, uuid.Nil, false
+	}
+
+	taskID, err := parseTaskID(r)
+	if err != nil {
+		helper.RespondError(w, r, apperror.BadRequest("invalid task id"))
+		return nil, uuid.Nil, false
+	}
+
+	task, err := h.getTaskByID(ctx, taskID, userID)
+	if err != nil {
+		if errors.Is(err, store.ErrTaskNotFound) {
+			helper.RespondError(w, r, apperror.NotFound("task not found"))
+			return nil, uuid.Nil, false
+		}
+		logger.Error(ctx, op+": failed to get task", "err", err)
+		helper.RespondError(w, r, apperror.InternalError("internal error", err))
+		return nil, uuid.Nil, false
+	}
+
+	isMember, err := h.teamStore.IsMember(ctx, task.TeamID, userID)
+	if err != nil {
+		logger.Error(ctx, op+": membership check failed", "err", err)
+		helper.RespondError(w, r, apperror.InternalError("internal error", err))
+		return nil, uuid.Nil, false
+	}
+	if !isMember {
+		helper.RespondError(w, r, apperror.Forbidden("only team members can see attachments"))
+		return nil, uuid.Nil, false
+	}
+	return task, userID, true
+}
+
+// loadAttachment loads the attachment in the URL along with its task.
+func (h *TaskHandler) loadAttachment(ctx context.Context, w http.ResponseWriter, r *http.Request, op string) (*attachmentstore.Attachment, *store.Task, bool) {
+	task, _, ok := h.attachmentTask(ctx, w, r, op)
+	if !ok {
+		return nil, nil, false
+	}
+
+	id, err := uuid.Parse(chi.URLParam(r, "attachment_id"))
+	if err != nil {
+		helper.RespondError(w, r, apperror.BadRequest("invalid attachment id"))
+		return nil, nil, false
+	}
+
+	a, err := h.attachments.Store.Get(ctx, task.ID, id)
+	if err != nil {
+		if errors.Is(err, attachmentstore.ErrAttachmentNotFound) {
+			helper.RespondError(w, r, apperror.NotFound("attachment not found"))
+			return nil, nil, false
+		}
+		logger.Error(ctx, op+": store query failed", "err", err)
+		helper.RespondError(w, r, apperror.InternalError("internal error", err))
+		return nil, nil, false
+	}
+	return a, task, true
+}
+
+var errTooLarge = errors.New("file too large")
+
+// spool copies at most limit bytes of src into a temporary file and
+// returns it with its size and hex SHA-256. The caller removes the file.
+func spool(src io.Reader, limit int64) (*os.File, int64, string, error) {
+	f, err := os.CreateTemp("", "attachment-*")
+	if err != nil {
+		return nil, 0, "", err
+	}
+	hash := sha256.New()
+	n, err := io.Copy(io.MultiWriter(f, hash), io.LimitReader(src, limit+1))
+	if err == nil && n > limit {
+		err = errTooLarge
+	}
+	if err != nil {
+		_ = f.Close()
+		_ = os.Remove(f.Name())
+		return nil, 0, "", err
+	}
+	return f, n, hex.EncodeToString(hash.Sum(nil)), nil
+}
+
+func (h *TaskHandler) uploadError(ctx context.Context, w http.ResponseWriter, r *http.Request, err error) {
+	var maxErr *http.MaxBytesError
+	if errors.Is(err, errTooLarge) || errors.As(err, &maxErr) {
+		helper.RespondError(w, r, apperror.PayloadTooLarge(fmt.Sprintf("attachments can be at most %d MB", h.attachments.MaxBytes>>20)))
+		return
+	}
+	logger.Info(ctx, "upload attachment: bad multipart body", "err", err)
+	helper.RespondError(w, r, apperror.BadRequest("invalid multipart body"))
+}
+
+// cleanFilename keeps the last path element of an uploaded name, without
+// control characters, so it is safe to show and to put in a header.
+func cleanFilename(name string) string {
+	name = path.Base(strings.ReplaceAll(name, `\`, "/"))
+	name = strings.TrimSpace(strings.Map(func(r rune) rune {
+		if unicode.IsControl(r) || r == utf8.RuneError {
+			return -1
+		}
+		return r
+	}, name))
+	if name == "" || name == "." || name == "/" {
+		return "file"
+	}
+	for len(name) > maxFilenameLength {
+		_, size := utf8.DecodeLastRuneInString(name)
+		name = name[:len(name)-size]
+	}
+	return name
+}
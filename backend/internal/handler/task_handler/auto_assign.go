@@ -0,0 +1,171 @@
+package handler
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/diagnosis/interactive-todo/api/types"
+	"github.com/diagnosis/interactive-todo/internal/logger"
+	assignmentstore "github.com/diagnosis/interactive-todo/internal/store/assignment"
+	auditstore "github.com/diagnosis/interactive-todo/internal/store/audit"
+	labelstore "github.com/diagnosis/interactive-todo/internal/store/labels"
+	outofficestore "github.com/diagnosis/interactive-todo/internal/store/outofoffice"
+	teamstore "github.com/diagnosis/interactive-todo/internal/store/teams"
+	"github.com/google/uuid"
+)
+
+// onCall is recorded as the strategy of tasks a rotation assigned; it is
+// not an auto_assign setting.
+const onCall teamstore.AutoAssignStrategy = "on_call"
+
+// autoAssignment records how an assignee was chosen, for the audit log.
+type autoAssignment struct {
+	Strategy   teamstore.AutoAssignStrategy
+	Label      string
+	RotationID *uuid.UUID
+	// BackupFor is the out-of-office member whose backup took the task
+	BackupFor *uuid.UUID
+}
+
+// pickAssignee chooses the assignee of a task created without one: whoever
+// is on call for one of its labels, or else the pick of the team's
+// auto_assign strategy. It returns nil when neither applies, or when the
+// strategy found nobody, and the caller falls back.
+//
+// Nobody out of office is picked. An on-call member or label route that is
+// away hands the task to their backup if available, or else to the next
+// rule; the notice tells the reporter who was passed over.
+func (h *TaskHandler) pickAssignee(
+	ctx context.Context,
+	teamID uuid.UUID,
+	labels []labelstore.Label,
+	now time.Time,
+) (uuid.UUID, *autoAssignment, *types.AssignmentNotice, error) {
+	names := make([]string, 0, len(labels))
+	for _, l := range labels {
+		names = append(names, l.Name)
+	}
+	oc, err := h.rotationStore.OnCall(ctx, teamID, names)
+	if err != nil {
+		return uuid.Nil, nil, nil, fmt.Errorf("find on call: %w", err)
+	}
+	var notice *types.AssignmentNotice
+	if oc != nil {
+		pick := &autoAssignment{Strategy: onCall, Label: oc.Label, RotationID: &oc.RotationID}
+		assigneeID, n, err := h.standIn(ctx, teamID, oc.UserID, pick, now)
+		if err != nil {
+			return uuid.Nil, nil, nil, err
+		}
+		if assigneeID != uuid.Nil {
+			return assigneeID, pick, n, nil
+		}
+		notice = n
+	}
+
+	settings, err := h.teamStore.GetSettings(ctx, teamID)
+	if err != nil {
+		return uuid.Nil, nil, nil, fmt.Errorf("load team settings: %w", err)
+	}
+	if settings.AutoAssign == nil {
+		return uuid.Nil, nil, notice, nil
+	}
+
+	strategy := *settings.AutoAssign
+	var assigneeID uuid.UUID
+	switch strategy {
+	case teamstore.AutoAssignRoundRobin:
+		assigneeID, err = h.assignmentStore.NextRoundRobin(ctx, teamID, now)
+	case teamstore.AutoAssignLeastLoaded:
+		assigneeID, err = h.assignmentStore.LeastLoaded(ctx, teamID, now)
+	case teamstore.AutoAssignLabelRouting:
+		labelIDs := make([]uuid.UUID, 0, len(labels))
+		for _, l := range labels {
+			labelIDs = append(labelIDs, l.ID)
+		}
+		route, rerr := h.assignmentStore.MatchRoute(ctx, teamID, labelIDs)
+		if rerr != nil {
+			return uuid.Nil, nil, nil, rerr
+		}
+		if route != nil {
+			pick := &autoAssignment{Strategy: strategy, Label: route.Label}
+			routed, n, rerr := h.standIn(ctx, teamID, route.AssigneeID, pick, now)
+			if rerr != nil {
+				return uuid.Nil, nil, nil, rerr
+			}
+			if routed != uuid.Nil {
+				return routed, pick, cmp.Or(notice, n), nil
+			}
+			notice = cmp.Or(notice, n)
+		}
+		// Tasks no route claims still get spread across the team
+		assigneeID, err = h.assignmentStore.LeastLoaded(ctx, teamID, now)
+	default:
+		return uuid.Nil, nil, notice, nil
+	}
+	if err != nil {
+		if errors.Is(err, assignmentstore.ErrNoMembers) {
+			return uuid.Nil, nil, notice, nil
+		}
+		return uuid.Nil, nil, nil, err
+	}
+	return assigneeID, &autoAssignment{Strategy: strategy}, notice, nil
+}
+
+// standIn returns userID unless they are out of office, then their backup
+// if available, recording that on pick. It returns uuid.Nil when neither
+// can take the task; the notice is set whenever userID was passed over.
+func (h *TaskHandler) standIn(
+	ctx context.Context,
+	teamID, userID uuid.UUID,
+	pick *autoAssignment,
+	now time.Time,
+) (uuid.UUID, *types.AssignmentNotice, error) {
+	o, err := h.outOfOfficeStore.GetActive(ctx, userID, now)
+	if err != nil {
+		if errors.Is(err, outofficestore.ErrOutOfOfficeNotFound) {
+			return userID, nil, nil
+		}
+		return uuid.Nil, nil, fmt.Errorf("out of office check: %w", err)
+	}
+
+	notice := &types.AssignmentNotice{Skipped: userID, Until: o.EndsAt.UTC()}
+	backupID, ok := h.availableBackup(ctx, teamID, o, now)
+	if !ok {
+		notice.Reason = "out of office and no backup is available"
+		return uuid.Nil, notice, nil
+	}
+	notice.BackupID = &backupID
+	notice.Reason = "out of office; their backup took the task"
+	pick.BackupFor = &userID
+	return backupID, notice, nil
+}
+
+func (h *TaskHandler) recordAutoAssignment(ctx context.Context, task *newTask, taskID, assigneeID uuid.UUID, pick *autoAssignment, now time.Time) {
+	metadata := map[string]any{
+		"strategy":    string(pick.Strategy),
+		"assignee_id": assigneeID.String(),
+	}
+	if pick.Label != "" {
+		metadata["label"] = pick.Label
+	}
+	if pick.RotationID != nil {
+		metadata["rotation_id"] = pick.RotationID.String()
+	}
+	if pick.BackupFor != nil {
+		metadata["backup_for"] = pick.BackupFor.String()
+	}
+	if err := h.auditStore.Record(ctx, auditstore.Entry{
+		ActorID:    &task.ReporterID,
+		Action:     auditstore.ActionTaskAutoAssigned,
+		TargetType: auditstore.TargetTask,
+		TargetID:   &taskID,
+		TeamID:     &task.TeamID,
+		Metadata:   metadata,
+		CreatedAt:  now,
+	}); err != nil {
+		logger.Error(ctx, "auto assign: audit failed", "task_id", taskID, "err", err)
+	}
+}
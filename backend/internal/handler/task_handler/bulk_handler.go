@@ -0,0 +1,161 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/diagnosis/interactive-todo/api/types"
+	"github.com/diagnosis/interactive-todo/internal/apperror"
+	"github.com/diagnosis/interactive-todo/internal/deadline"
+	"github.com/diagnosis/interactive-todo/internal/events"
+	"github.com/diagnosis/interactive-todo/internal/helper"
+	"github.com/diagnosis/interactive-todo/internal/logger"
+	middleware "github.com/diagnosis/interactive-todo/internal/middleware/auth"
+	store "github.com/diagnosis/interactive-todo/internal/store/tasks"
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+)
+
+// BulkAssignTasks hands many of the team's tasks to one member at once,
+// e.g. when someone leaves the team. Team owners/admins can reassign any
+// task they can see; other members only tasks they reported, as with
+// PATCH /tasks/{id}/assign. Nothing changes unless every task can.
+func (h *TaskHandler) BulkAssignTasks(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := deadline.Budget(r.Context(), 10*time.Second)
+	defer cancel()
+
+	userID, ok := middleware.GetUserIDFromContext(ctx)
+	if !ok {
+		helper.RespondError(w, r, apperror.Unauthorized("unauthorized"))
+		return
+	}
+
+	teamID, err := uuid.Parse(chi.URLParam(r, "team_id"))
+	if err != nil {
+		helper.RespondError(w, r, apperror.BadRequest("invalid team id"))
+		return
+	}
+
+	r.Body = http.MaxBytesReader(w, r.Body, 1<<20)
+	defer r.Body.Close()
+
+	var in types.BulkAssignRequest
+	dec := json.NewDecoder(r.Body)
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&in); err != nil {
+		logger.Error(ctx, "bulk assign: bad json", "err", err)
+		helper.RespondError(w, r, apperror.BadRequest("invalid request body"))
+		return
+	}
+	if in.AssigneeID == uuid.Nil {
+		helper.RespondError(w, r, apperror.BadRequest("assignee_id is required"))
+		return
+	}
+	ids := make([]uuid.UUID, 0, len(in.TaskIDs))
+	seen := make(map[uuid.UUID]bool, len(in.TaskIDs))
+	for _, id := range in.TaskIDs {
+		if id == uuid.Nil {
+			helper.RespondError(w, r, apperror.BadRequest("task_ids cannot contain a nil id"))
+			return
+		}
+		if !seen[id] {
+			seen[id] = true
+			ids = append(ids, id)
+		}
+	}
+	if len(ids) == 0 || len(ids) > store.MaxBulkTasks {
+		helper.RespondError(w, r, apperror.BadRequest(fmt.Sprintf("task_ids must have between 1 and %d tasks", store.MaxBulkTasks)))
+		return
+	}
+
+	isMember, err := h.teamStore.IsMember(ctx, teamID, userID)
+	if err != nil {
+		logger.Error(ctx, "bulk assign: membership check failed", "err", err)
+		helper.RespondError(w, r, apperror.InternalError("internal error", err))
+		return
+	}
+	if !isMember {
+		helper.RespondError(w, r, apperror.Forbidden("only team members can assign tasks"))
+		return
+	}
+	isAdmin, err := h.teamStore.IsOwnerOrAdmin(ctx, teamID, userID)
+	if err != nil {
+		logger.Error(ctx, "bulk assign: role check failed", "err", err)
+		helper.RespondError(w, r, apperror.InternalError("internal error", err))
+		return
+	}
+
+	now := time.Now().UTC()
+	// members may also move the tasks of those who delegated to them
+	var delegators []uuid.UUID
+	if !isAdmin {
+		if delegators, err = h.delegationStore.DelegatorsOf(ctx, teamID, userID, now); err != nil {
+			logger.Error(ctx, "bulk assign: delegation check failed", "err", err)
+			helper.RespondError(w, r, apperror.InternalError("internal error", err))
+			return
+		}
+	}
+
+	// one check for the new assignee covers every task: they are all in
+	// this team
+	isAssigneeMember, err := h.teamStore.IsMember(ctx, teamID, in.AssigneeID)
+	if err != nil {
+		logger.Error(ctx, "bulk assign: assignee membership check failed", "err", err)
+		helper.RespondError(w, r, apperror.InternalError("internal error", err))
+		return
+	}
+	if !isAssigneeMember {
+		helper.RespondError(w, r, apperror.BadRequest("assignee must be a member of the team"))
+		return
+	}
+	if !h.checkOutOfOffice(ctx, w, r, "bulk assign", teamID, userID, in.AssigneeID, in.AllowOutOfOffice, now) {
+		return
+	}
+
+	tasks, err := h.taskStore.BulkAssign(ctx, store.BulkAssignment{
+		TeamID:       teamID,
+		TaskIDs:      ids,
+		AssigneeID:   in.AssigneeID,
+		ViewerID:     userID,
+		ReportedOnly: !isAdmin,
+		Delegators:   delegators,
+	}, &userID, now)
+	if err != nil {
+		switch {
+		case errors.Is(err, store.ErrInvalidInput):
+			helper.RespondError(w, r, apperror.BadRequest(err.Error()))
+		case errors.Is(err, store.ErrTaskNotFound):
+			helper.RespondError(w, r, apperror.NotFound(err.Error()))
+		case errors.Is(err, store.ErrNotReporter):
+			helper.RespondError(w, r, apperror.Forbidden("only team owners/admins can reassign tasks others reported; "+err.Error()))
+		default:
+			logger.Error(ctx, "bulk assign: store error", "err", err)
+			helper.RespondError(w, r, apperror.InternalError("internal error", err))
+		}
+		return
+	}
+
+	for _, t := range tasks {
+		if !isAdmin && t.ReporterID != userID {
+			h.recordDelegationUse(ctx, r, &t, userID, "assign", now)
+		}
+		h.events.Publish(ctx, events.Event{
+			Type:    events.TaskAssigned,
+			TeamID:  t.TeamID,
+			TaskID:  t.ID,
+			ActorID: &userID,
+			At:      now,
+		})
+	}
+
+	logger.Info(ctx, "tasks bulk assigned", "team_id", teamID, "assignee_id", in.AssigneeID, "user_id", userID,
+		"requested", len(ids), "changed", len(tasks))
+	helper.RespondJSON(w, r, http.StatusOK, types.BulkAssignResponse{
+		AssigneeID: in.AssigneeID,
+		Tasks:      tasks,
+		Unchanged:  len(ids) - len(tasks),
+	})
+}
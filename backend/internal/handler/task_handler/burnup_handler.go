@@ -0,0 +1,95 @@
+package handler
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/diagnosis/interactive-todo/api/types"
+	"github.com/diagnosis/interactive-todo/internal/apperror"
+	"github.com/diagnosis/interactive-todo/internal/deadline"
+	"github.com/diagnosis/interactive-todo/internal/helper"
+	"github.com/diagnosis/interactive-todo/internal/logger"
+	middleware "github.com/diagnosis/interactive-todo/internal/middleware/auth"
+	store "github.com/diagnosis/interactive-todo/internal/store/tasks"
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+)
+
+// defaultBurnUpDays is the range of a burn-up chart without ?from.
+const defaultBurnUpDays = 30
+
+// GetTeamBurnUp returns the team's cumulative scope and completed work per
+// day, in estimate hours and in tasks, for ?from to ?to (YYYY-MM-DD in the
+// team's timezone). ?to defaults to today and ?from to 30 days before it.
+// Team members only; private tasks count for those who can see them.
+func (h *TaskHandler) GetTeamBurnUp(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := deadline.Budget(r.Context(), 5*time.Second)
+	defer cancel()
+
+	userID, ok := middleware.GetUserIDFromContext(ctx)
+	if !ok {
+		helper.RespondError(w, r, apperror.Unauthorized("authentication required"))
+		return
+	}
+
+	teamID, err := uuid.Parse(chi.URLParam(r, "team_id"))
+	if err != nil {
+		helper.RespondError(w, r, apperror.BadRequest("invalid team id"))
+		return
+	}
+
+	isMember, err := h.teamStore.IsMember(ctx, teamID, userID)
+	if err != nil {
+		logger.Error(ctx, "burn-up: membership check failed", "err", err)
+		helper.RespondError(w, r, apperror.InternalError("internal error", err))
+		return
+	}
+	if !isMember {
+		helper.RespondError(w, r, apperror.Forbidden("only team members can view team tasks"))
+		return
+	}
+
+	cal, err := h.calendarStore.Load(ctx, teamID)
+	if err != nil {
+		logger.Error(ctx, "burn-up: load team calendar failed", "err", err)
+		helper.RespondError(w, r, apperror.InternalError("internal error", err))
+		return
+	}
+
+	now := time.Now().In(cal.Location)
+	to := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+	if v := r.URL.Query().Get("to"); v != "" {
+		if to, err = time.Parse(time.DateOnly, v); err != nil {
+			helper.RespondError(w, r, apperror.BadRequest("to must be a date, YYYY-MM-DD"))
+			return
+		}
+	}
+	from := to.AddDate(0, 0, -(defaultBurnUpDays - 1))
+	if v := r.URL.Query().Get("from"); v != "" {
+		if from, err = time.Parse(time.DateOnly, v); err != nil {
+			helper.RespondError(w, r, apperror.BadRequest("from must be a date, YYYY-MM-DD"))
+			return
+		}
+	}
+
+	days, err := h.taskStore.BurnUp(ctx, teamID, userID, from, to, cal.Location.String())
+	if err != nil {
+		if errors.Is(err, store.ErrInvalidInput) {
+			helper.RespondError(w, r, apperror.BadRequest(fmt.Sprintf("from must not be after to, and at most %d days apart", store.MaxBurnUpDays)))
+			return
+		}
+		logger.Error(ctx, "burn-up: store error", "err", err)
+		helper.RespondError(w, r, apperror.InternalError("internal error", err))
+		return
+	}
+
+	helper.RespondJSON(w, r, http.StatusOK, types.BurnUpResponse{
+		TeamID:   teamID,
+		From:     from.Format(time.DateOnly),
+		To:       to.Format(time.DateOnly),
+		Timezone: cal.Location.String(),
+		Days:     days,
+	})
+}
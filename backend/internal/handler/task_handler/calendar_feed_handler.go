@@ -0,0 +1,185 @@
+package handler
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/diagnosis/interactive-todo/api/types"
+	"github.com/diagnosis/interactive-todo/internal/apperror"
+	"github.com/diagnosis/interactive-todo/internal/deadline"
+	"github.com/diagnosis/interactive-todo/internal/helper"
+	"github.com/diagnosis/interactive-todo/internal/logger"
+	middleware "github.com/diagnosis/interactive-todo/internal/middleware/auth"
+	calendarfeedstore "github.com/diagnosis/interactive-todo/internal/store/calendarfeeds"
+	"github.com/google/uuid"
+)
+
+// Calendar feed: the caller's assigned tasks as an iCalendar subscription
+// for Google or Apple Calendar. Calendar apps cannot send a bearer token,
+// so the feed URL carries a signed feed token instead; see
+// signedurl.FeedToken.
+
+const (
+	// calendarFeedMaxTasks bounds the feed, soonest due first
+	calendarFeedMaxTasks = 1000
+	// calendarFeedDoneFor keeps finished tasks in the feed for a while, so
+	// they show as done instead of disappearing
+	calendarFeedDoneFor = 30 * 24 * time.Hour
+)
+
+// GetCalendarFeed returns the caller's feed URL, if the feed is on.
+func (h *TaskHandler) GetCalendarFeed(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := deadline.Budget(r.Context(), 5*time.Second)
+	defer cancel()
+
+	userID, ok := middleware.GetUserIDFromContext(ctx)
+	if !ok {
+		helper.RespondError(w, r, apperror.Unauthorized("authentication required"))
+		return
+	}
+
+	feed, err := h.calendarFeedStore.Get(ctx, userID)
+	if err != nil {
+		if errors.Is(err, calendarfeedstore.ErrFeedNotFound) {
+			helper.RespondError(w, r, apperror.NotFound("calendar feed is off"))
+			return
+		}
+		logger.Error(ctx, "get calendar feed: store failed", "err", err)
+		helper.RespondError(w, r, apperror.InternalError("internal error", err))
+		return
+	}
+	helper.RespondJSON(w, r, http.StatusOK, h.calendarFeed(r, feed))
+}
+
+// CreateCalendarFeed turns the caller's feed on with a new URL; the
+// previous URL, if any, stops working.
+func (h *TaskHandler) CreateCalendarFeed(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := deadline.Budget(r.Context(), 5*time.Second)
+	defer cancel()
+
+	userID, ok := middleware.GetUserIDFromContext(ctx)
+	if !ok {
+		helper.RespondError(w, r, apperror.Unauthorized("authentication required"))
+		return
+	}
+
+	feed, err := h.calendarFeedStore.Rotate(ctx, userID, time.Now().UTC())
+	if err != nil {
+		logger.Error(ctx, "create calendar feed: store failed", "err", err)
+		helper.RespondError(w, r, apperror.InternalError("internal error", err))
+		return
+	}
+
+	logger.Info(ctx, "calendar feed created", "user_id", userID, "generation", feed.Generation)
+	helper.RespondJSON(w, r, http.StatusCreated, h.calendarFeed(r, feed))
+}
+
+// DeleteCalendarFeed turns the caller's feed off.
+func (h *TaskHandler) DeleteCalendarFeed(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := deadline.Budget(r.Context(), 5*time.Second)
+	defer cancel()
+
+	userID, ok := middleware.GetUserIDFromContext(ctx)
+	if !ok {
+		helper.RespondError(w, r, apperror.Unauthorized("authentication required"))
+		return
+	}
+
+	if err := h.calendarFeedStore.Disable(ctx, userID); err != nil {
+		if errors.Is(err, calendarfeedstore.ErrFeedNotFound) {
+			helper.RespondError(w, r, apperror.NotFound("calendar feed is off"))
+			return
+		}
+		logger.Error(ctx, "delete calendar feed: store failed", "err", err)
+		helper.RespondError(w, r, apperror.InternalError("internal error", err))
+		return
+	}
+
+	logger.Info(ctx, "calendar feed turned off", "user_id", userID)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// ServeCalendarFeed answers calendar apps, authenticated by ?token= alone.
+// ?kind=todo lists tasks as to-dos rather than events.
+func (h *TaskHandler) ServeCalendarFeed(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := deadline.Budget(r.Context(), 5*time.Second)
+	defer cancel()
+
+	var todos bool
+	switch r.URL.Query().Get("kind") {
+	case "", "event":
+	case "todo":
+		todos = true
+	default:
+		helper.RespondError(w, r, apperror.BadRequest("kind must be event or todo"))
+		return
+	}
+
+	userID, ok := h.verifyCalendarFeed(ctx, w, r)
+	if !ok {
+		return
+	}
+
+	now := time.Now().UTC()
+	tasks, err := h.taskStore.ListCalendarTasks(ctx, userID, now.Add(-calendarFeedDoneFor), calendarFeedMaxTasks)
+	if err != nil {
+		logger.Error(ctx, "serve calendar feed: list tasks failed", "err", err)
+		helper.RespondError(w, r, apperror.InternalError("internal error", err))
+		return
+	}
+
+	// at most calendarFeedMaxTasks tasks, so it is built before the status
+	// is sent and a failure can still be reported
+	var buf bytes.Buffer
+	if err := writeICS(&buf, tasks, todos, now); err != nil {
+		logger.Error(ctx, "serve calendar feed: render failed", "err", err)
+		helper.RespondError(w, r, apperror.InternalError("internal error", err))
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/calendar; charset=utf-8")
+	w.Header().Set("Content-Disposition", `inline; filename="tasks.ics"`)
+	w.Header().Set("Cache-Control", "private, no-cache")
+	w.WriteHeader(http.StatusOK)
+	_, _ = w.Write(buf.Bytes())
+}
+
+// verifyCalendarFeed checks the feed token against the user's current
+// feed. Bad, replaced and turned-off tokens all get the same 401.
+func (h *TaskHandler) verifyCalendarFeed(ctx context.Context, w http.ResponseWriter, r *http.Request) (uuid.UUID, bool) {
+	userID, generation, err := h.urlSigner.VerifyFeedToken(r.URL.Query().Get("token"))
+	if err != nil {
+		logger.Info(ctx, "serve calendar feed: bad token", "err", err)
+		helper.RespondError(w, r, apperror.Unauthorized("invalid calendar feed token"))
+		return uuid.Nil, false
+	}
+
+	feed, err := h.calendarFeedStore.Get(ctx, userID)
+	if err != nil && !errors.Is(err, calendarfeedstore.ErrFeedNotFound) {
+		logger.Error(ctx, "serve calendar feed: store failed", "err", err)
+		helper.RespondError(w, r, apperror.InternalError("internal error", err))
+		return uuid.Nil, false
+	}
+	if feed == nil || feed.Generation != generation {
+		logger.Info(ctx, "serve calendar feed: revoked token", "user_id", userID)
+		helper.RespondError(w, r, apperror.Unauthorized("invalid calendar feed token"))
+		return uuid.Nil, false
+	}
+	return userID, true
+}
+
+// calendarFeed builds the feed URL under the API version prefix the
+// request came in on.
+func (h *TaskHandler) calendarFeed(r *http.Request, feed *calendarfeedstore.Feed) types.CalendarFeed {
+	path := strings.TrimSuffix(strings.TrimSuffix(r.URL.Path, "/"), "/calendar-feed") + "/calendar.ics"
+	query := url.Values{"token": {h.urlSigner.FeedToken(feed.UserID, feed.Generation)}}
+	return types.CalendarFeed{
+		URL:       path + "?" + query.Encode(),
+		CreatedAt: feed.CreatedAt,
+	}
+}
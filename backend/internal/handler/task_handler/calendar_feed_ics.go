@@ -0,0 +1,123 @@
+package handler
+
+import (
+	"bufio"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/diagnosis/interactive-todo/api/types"
+	store "github.com/diagnosis/interactive-todo/internal/store/tasks"
+)
+
+// iCalendar (RFC 5545) rendering of the calendar feed. Google Calendar
+// shows only events, so tasks are events at their due date by default;
+// apps that keep to-dos, like Apple Reminders, can ask for VTODOs.
+
+const icsTimeLayout = "20060102T150405Z"
+
+// icsPriority maps task priorities onto iCalendar's 1 (highest) to 9.
+var icsPriority = map[types.TaskPriority]int{
+	types.TaskPriorityUrgent: 1,
+	types.TaskPriorityHigh:   3,
+	types.TaskPriorityNormal: 5,
+	types.TaskPriorityLow:    9,
+}
+
+var icsTodoStatus = map[types.TaskStatus]string{
+	types.TaskStatusOpen:       "NEEDS-ACTION",
+	types.TaskStatusInProgress: "IN-PROCESS",
+	types.TaskStatusDone:       "COMPLETED",
+	types.TaskStatusCanceled:   "CANCELLED",
+}
+
+var icsEscaper = strings.NewReplacer(`\`, `\\`, ";", `\;`, ",", `\,`, "\r\n", `\n`, "\n", `\n`, "\r", `\n`)
+
+type icsWriter struct {
+	w *bufio.Writer
+}
+
+// writeICS writes the feed: each task as a VEVENT from its start (or due
+// date) to its due date, or as a VTODO when todos is set.
+func writeICS(w io.Writer, tasks []store.Task, todos bool, now time.Time) error {
+	out := icsWriter{w: bufio.NewWriter(w)}
+	out.line("BEGIN:VCALENDAR")
+	out.line("VERSION:2.0")
+	out.line("PRODID:-//interactive-todo//assigned tasks//EN")
+	out.line("CALSCALE:GREGORIAN")
+	out.line("METHOD:PUBLISH")
+	out.text("X-WR-CALNAME", "Assigned tasks")
+	// a hint to subscribing apps; most poll on their own schedule anyway
+	out.line("REFRESH-INTERVAL;VALUE=DURATION:PT1H")
+	out.line("X-PUBLISHED-TTL:PT1H")
+
+	for i := range tasks {
+		t := &tasks[i]
+		component := "VEVENT"
+		if todos {
+			component = "VTODO"
+		}
+		out.line("BEGIN:" + component)
+		out.line("UID:" + t.ID.String() + "@interactive-todo")
+		out.line("DTSTAMP:" + icsTime(now))
+		out.line("LAST-MODIFIED:" + icsTime(t.UpdatedAt))
+		out.line("SEQUENCE:" + strconv.FormatInt(t.Version, 10))
+		out.text("SUMMARY", t.Title)
+		if t.Description != nil && *t.Description != "" {
+			out.text("DESCRIPTION", *t.Description)
+		}
+		if p, ok := icsPriority[t.Priority]; ok {
+			out.line("PRIORITY:" + strconv.Itoa(p))
+		}
+		if todos {
+			if t.StartAt != nil {
+				out.line("DTSTART:" + icsTime(*t.StartAt))
+			}
+			out.line("DUE:" + icsTime(t.DueAt))
+			out.line("STATUS:" + icsTodoStatus[t.Status])
+			if t.CompletedAt != nil {
+				out.line("COMPLETED:" + icsTime(*t.CompletedAt))
+			}
+		} else {
+			start := t.DueAt
+			if t.StartAt != nil {
+				start = *t.StartAt
+			}
+			out.line("DTSTART:" + icsTime(start))
+			out.line("DTEND:" + icsTime(t.DueAt))
+			out.line("TRANSP:TRANSPARENT")
+		}
+		out.line("END:" + component)
+	}
+
+	out.line("END:VCALENDAR")
+	return out.w.Flush()
+}
+
+// text writes a property whose value is free text, escaped.
+func (o icsWriter) text(name, value string) {
+	o.line(name + ":" + icsEscaper.Replace(value))
+}
+
+// line writes a content line, folded at 75 octets without splitting a
+// UTF-8 sequence. Write errors surface at Flush.
+func (o icsWriter) line(s string) {
+	limit := 75
+	for len(s) > limit {
+		cut := limit
+		for !utf8.RuneStart(s[cut]) {
+			cut--
+		}
+		_, _ = o.w.WriteString(s[:cut] + "\r\n ")
+		s = s[cut:]
+		// the space that starts a continuation counts too
+		limit = 74
+	}
+	_, _ = o.w.WriteString(s + "\r\n")
+}
+
+func icsTime(t time.Time) string {
+	return t.UTC().Format(icsTimeLayout)
+}
@@ -0,0 +1,252 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/diagnosis/interactive-todo/api/types"
+	"github.com/diagnosis/interactive-todo/internal/apperror"
+	"github.com/diagnosis/interactive-todo/internal/deadline"
+	"github.com/diagnosis/interactive-todo/internal/helper"
+	"github.com/diagnosis/interactive-todo/internal/logger"
+	middleware "github.com/diagnosis/interactive-todo/internal/middleware/auth"
+	"github.com/diagnosis/interactive-todo/internal/secure/spamguard"
+	commentstore "github.com/diagnosis/interactive-todo/internal/store/comments"
+	store "github.com/diagnosis/interactive-todo/internal/store/tasks"
+	userstore "github.com/diagnosis/interactive-todo/internal/store/users"
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+)
+
+func (h *TaskHandler) ListComments(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := deadline.Budget(r.Context(), 5*time.Second)
+	defer cancel()
+
+	task, _, ok := h.commentTask(ctx, w, r, "list comments")
+	if !ok {
+		return
+	}
+
+	comments, err := h.commentStore.ListForTask(ctx, task.ID)
+	if err != nil {
+		logger.Error(ctx, "list comments: store failed", "err", err)
+		helper.RespondError(w, r, apperror.InternalError("internal error", err))
+		return
+	}
+	helper.RespondJSON(w, r, http.StatusOK, types.CommentListResponse{TaskID: task.ID, Comments: comments})
+}
+
+func (h *TaskHandler) CreateComment(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := deadline.Budget(r.Context(), 5*time.Second)
+	defer cancel()
+
+	task, userID, ok := h.commentTask(ctx, w, r, "create comment")
+	if !ok {
+		return
+	}
+
+	var in types.CreateCommentRequest
+	if !decodeCommentBody(ctx, w, r, "create comment", &in) {
+		return
+	}
+	body, ok := validCommentBody(w, r, in.Body)
+	if !ok {
+		return
+	}
+
+	now := time.Now().UTC()
+	// Admins are exempt from the spam guard, as when creating tasks
+	if claims, ok := middleware.GetClaimsFromContext(ctx); !ok || claims.UserType != userstore.TypeAdmin {
+		mute, err := h.spamGuard.CheckComment(ctx, userID, body, now)
+		if err != nil {
+			if errors.Is(err, spamguard.ErrMuted) {
+				logger.Info(ctx, "create comment: author muted", "user_id", userID, "muted_until", mute.MutedUntil)
+				helper.RespondError(w, r, apperror.TooManyRequests("too many similar comments, try again after "+mute.MutedUntil.Format(time.RFC3339)))
+				return
+			}
+			logger.Error(ctx, "create comment: spam guard failed", "err", err)
+			helper.RespondError(w, r, apperror.InternalError("internal error", err))
+			return
+		}
+	}
+
+	comment, err := h.commentStore.Create(ctx, task.ID, &userID, nil, body, now)
+	if err != nil {
+		logger.Error(ctx, "create comment: store failed", "err", err)
+		helper.RespondError(w, r, apperror.InternalError("internal error", err))
+		return
+	}
+
+	logger.Info(ctx, "comment created", "task_id", task.ID, "comment_id", comment.ID, "user_id", userID)
+	helper.RespondJSON(w, r, http.StatusCreated, comment)
+}
+
+// EditComment lets the author change a comment's body. The previous body
+// is kept in the comment's history.
+func (h *TaskHandler) EditComment(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := deadline.Budget(r.Context(), 5*time.Second)
+	defer cancel()
+
+	comment, userID, ok := h.loadComment(ctx, w, r, "edit comment")
+	if !ok {
+		return
+	}
+	if comment.AuthorID == nil || *comment.AuthorID != userID {
+		helper.RespondError(w, r, apperror.Forbidden("only the author can edit a comment"))
+		return
+	}
+
+	var in types.EditCommentRequest
+	if !decodeCommentBody(ctx, w, r, "edit comment", &in) {
+		return
+	}
+	body, ok := validCommentBody(w, r, in.Body)
+	if !ok {
+		return
+	}
+	if body == comment.Body {
+		helper.RespondJSON(w, r, http.StatusOK, comment)
+		return
+	}
+
+	edited, err := h.commentStore.Edit(ctx, comment.ID, userID, body, time.Now().UTC())
+	if err != nil {
+		if errors.Is(err, commentstore.ErrCommentNotFound) {
+			helper.RespondError(w, r, apperror.NotFound("comment not found"))
+			return
+		}
+		logger.Error(ctx, "edit comment: store failed", "err", err)
+		helper.RespondError(w, r, apperror.InternalError("internal error", err))
+		return
+	}
+
+	logger.Info(ctx, "comment edited", "task_id", edited.TaskID, "comment_id", edited.ID, "user_id", userID)
+	helper.RespondJSON(w, r, http.StatusOK, edited)
+}
+
+// CommentHistory returns a comment with the bodies it had before each edit.
+func (h *TaskHandler) CommentHistory(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := deadline.Budget(r.Context(), 5*time.Second)
+	defer cancel()
+
+	comment, _, ok := h.loadComment(ctx, w, r, "comment history")
+	if !ok {
+		return
+	}
+
+	revs, err := h.commentStore.ListRevisions(ctx, comment.ID)
+	if err != nil {
+		logger.Error(ctx, "comment history: store failed", "err", err)
+		helper.RespondError(w, r, apperror.InternalError("internal error", err))
+		return
+	}
+	helper.RespondJSON(w, r, http.StatusOK, types.CommentHistoryResponse{Comment: *comment, Revisions: revs})
+}
+
+func decodeCommentBody(ctx context.Context, w http.ResponseWriter, r *http.Request, op string, dst any) bool {
+	r.Body = http.MaxBytesReader(w, r.Body, 1<<20)
+	defer r.Body.Close()
+
+	dec := json.NewDecoder(r.Body)
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(dst); err != nil {
+		logger.Error(ctx, op+": bad json", "err", err)
+		helper.RespondError(w, r, apperror.BadRequest("invalid request body"))
+		return false
+	}
+	return true
+}
+
+func validCommentBody(w http.ResponseWriter, r *http.Request, raw string) (string, bool) {
+	body := strings.TrimSpace(raw)
+	if body == "" {
+		helper.RespondError(w, r, apperror.BadRequest("body is required"))
+		return "", false
+	}
+	if len(body) > commentstore.MaxBodyLength {
+		helper.RespondError(w, r, apperror.BadRequest(fmt.Sprintf("body too long (max %d chars)", commentstore.MaxBodyLength)))
+		return "", false
+	}
+	return body, true
+}
+
+// loadComment loads the comment in the URL for a team member who may see
+// its task.
+func (h *TaskHandler) loadComment(ctx context.Context, w http.ResponseWriter, r *http.Request, op string) (*commentstore.Comment, uuid.UUID, bool) {
+	userID, ok := middleware.GetUserIDFromContext(ctx)
+	if !ok {
+		helper.RespondError(w, r, apperror.Unauthorized("unauthorized"))
+		return nil, uuid.Nil, false
+	}
+
+	commentID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		helper.RespondError(w, r, apperror.BadRequest("invalid comment id"))
+		return nil, uuid.Nil, false
+	}
+
+	comment, err := h.commentStore.GetByID(ctx, commentID)
+	if err != nil {
+		if errors.Is(err, commentstore.ErrCommentNotFound) {
+			helper.RespondError(w, r, apperror.NotFound("comment not found"))
+			return nil, uuid.Nil, false
+		}
+		logger.Error(ctx, op+": failed to get comment", "err", err)
+		helper.RespondError(w, r, apperror.InternalError("internal error", err))
+		return nil, uuid.Nil, false
+	}
+
+	// A comment on a task the caller cannot see does not exist for them.
+	if _, ok := h.visibleCommentTask(ctx, w, r, op, comment.TaskID, userID, "comment not found"); !ok {
+		return nil, uuid.Nil, false
+	}
+	return comment, userID, true
+}
+
+// commentTask loads the task in the URL for a team member who may see it.
+func (h *TaskHandler) commentTask(ctx context.Context, w http.ResponseWriter, r *http.Request, op string) (*store.Task, uuid.UUID, bool) {
+	userID, ok := middleware.GetUserIDFromContext(ctx)
+	if !ok {
+		helper.RespondError(w, r, apperror.Unauthorized("unauthorized"))
+		return nil, uuid.Nil, false
+	}
+
+	taskID, err := parseTaskID(r)
+	if err != nil {
+		helper.RespondError(w, r, apperror.BadRequest("invalid task id"))
+		return nil, uuid.Nil, false
+	}
+
+	task, ok := h.visibleCommentTask(ctx, w, r, op, taskID, userID, "task not found")
+	return task, userID, ok
+}
+
+func (h *TaskHandler) visibleCommentTask(ctx context.Context, w http.ResponseWriter, r *http.Request, op string, taskID, userID uuid.UUID, notFound string) (*store.Task, bool) {
+	task, err := h.getTaskByID(ctx, taskID, userID)
+	if err != nil {
+		if errors.Is(err, store.ErrTaskNotFound) {
+			helper.RespondError(w, r, apperror.NotFound(notFound))
+			return nil, false
+		}
+		logger.Error(ctx, op+": failed to get task", "err", err)
+		helper.RespondError(w, r, apperror.InternalError("internal error", err))
+		return nil, false
+	}
+
+	isMember, err := h.teamStore.IsMember(ctx, task.TeamID, userID)
+	if err != nil {
+		logger.Error(ctx, op+": membership check failed", "err", err)
+		helper.RespondError(w, r, apperror.InternalError("internal error", err))
+		return nil, false
+	}
+	if !isMember {
+		helper.RespondError(w, r, apperror.Forbidden("only team members can see comments"))
+		return nil, false
+	}
+	return task, true
+}
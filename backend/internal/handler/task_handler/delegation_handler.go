@@ -0,0 +1,273 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/diagnosis/interactive-todo/api/types"
+	"github.com/diagnosis/interactive-todo/internal/apperror"
+	"github.com/diagnosis/interactive-todo/internal/deadline"
+	"github.com/diagnosis/interactive-todo/internal/helper"
+	"github.com/diagnosis/interactive-todo/internal/logger"
+	middleware "github.com/diagnosis/interactive-todo/internal/middleware/auth"
+	auditstore "github.com/diagnosis/interactive-todo/internal/store/audit"
+	delegationstore "github.com/diagnosis/interactive-todo/internal/store/delegations"
+	store "github.com/diagnosis/interactive-todo/internal/store/tasks"
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+)
+
+// Delegation ("vacation mode"): a member lets another member of the team
+// act as them on the tasks they created, for a date range. The delegate
+// can approve or reject those tasks and assign them, alone or in bulk.
+// Each use is recorded in the audit log, as are creating, revoking and
+// expiry.
+
+const maxDelegationReasonLen = 500
+
+func (h *TaskHandler) ListDelegations(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := deadline.Budget(r.Context(), 5*time.Second)
+	defer cancel()
+
+	teamID, _, ok := h.requireDelegationMember(ctx, w, r)
+	if !ok {
+		return
+	}
+
+	delegations, err := h.delegationStore.List(ctx, teamID, time.Now().UTC())
+	if err != nil {
+		logger.Error(ctx, "list delegations: store failed", "err", err)
+		helper.RespondError(w, r, apperror.InternalError("internal error", err))
+		return
+	}
+	helper.RespondJSON(w, r, http.StatusOK, types.DelegationListResponse{TeamID: teamID, Delegations: delegations})
+}
+
+// CreateDelegation delegates the caller's powers in the team. It replaces
+// the caller's previous delegation there, if any.
+func (h *TaskHandler) CreateDelegation(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := deadline.Budget(r.Context(), 5*time.Second)
+	defer cancel()
+
+	teamID, userID, ok := h.requireDelegationMember(ctx, w, r)
+	if !ok {
+		return
+	}
+
+	r.Body = http.MaxBytesReader(w, r.Body, 1<<20)
+	defer r.Body.Close()
+
+	var in types.CreateDelegationRequest
+	dec := json.NewDecoder(r.Body)
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&in); err != nil {
+		logger.Error(ctx, "create delegation: bad json", "err", err)
+		helper.RespondError(w, r, apperror.BadRequest("invalid request body"))
+		return
+	}
+
+	now := time.Now().UTC()
+	startsAt := now
+	if in.StartsAt != nil && in.StartsAt.After(now) {
+		startsAt = in.StartsAt.UTC()
+	}
+	switch {
+	case in.DelegateID == uuid.Nil:
+		helper.RespondError(w, r, apperror.BadRequest("delegate_id is required"))
+		return
+	case in.DelegateID == userID:
+		helper.RespondError(w, r, apperror.BadRequest("cannot delegate to yourself"))
+		return
+	case in.EndsAt.IsZero():
+		helper.RespondError(w, r, apperror.BadRequest("ends_at is required"))
+		return
+	case !in.EndsAt.After(startsAt):
+		helper.RespondError(w, r, apperror.BadRequest("ends_at must be after starts_at and in the future"))
+		return
+	case in.EndsAt.Sub(startsAt) > delegationstore.MaxDuration:
+		helper.RespondError(w, r, apperror.BadRequest("a delegation can last at most 90 days"))
+		return
+	}
+	if in.Reason != nil {
+		reason := strings.TrimSpace(*in.Reason)
+		if utf8.RuneCountInString(reason) > maxDelegationReasonLen {
+			helper.RespondError(w, r, apperror.BadRequest("reason is too long (max 500 chars)"))
+			return
+		}
+		in.Reason = &reason
+		if reason == "" {
+			in.Reason = nil
+		}
+	}
+
+	isMember, err := h.teamStore.IsMember(ctx, teamID, in.DelegateID)
+	if err != nil {
+		logger.Error(ctx, "create delegation: delegate membership check failed", "err", err)
+		helper.RespondError(w, r, apperror.InternalError("internal error", err))
+		return
+	}
+	if !isMember {
+		helper.RespondError(w, r, apperror.BadRequest("delegate must be a member of the team"))
+		return
+	}
+
+	d, err := h.delegationStore.Set(ctx, delegationstore.Delegation{
+		TeamID:      teamID,
+		DelegatorID: userID,
+		DelegateID:  in.DelegateID,
+		StartsAt:    startsAt,
+		EndsAt:      in.EndsAt.UTC(),
+		Reason:      in.Reason,
+	}, now)
+	if err != nil {
+		logger.Error(ctx, "create delegation: store failed", "err", err)
+		helper.RespondError(w, r, apperror.InternalError("internal error", err))
+		return
+	}
+
+	h.recordDelegation(ctx, r, auditstore.ActionDelegationCreated, &userID, d, now)
+	logger.Info(ctx, "delegation created", "team_id", teamID, "delegation_id", d.ID,
+		"delegator_id", userID, "delegate_id", d.DelegateID)
+	helper.RespondJSON(w, r, http.StatusCreated, d)
+}
+
+// DeleteDelegation revokes a delegation, by its delegator or a team
+// owner/admin.
+func (h *TaskHandler) DeleteDelegation(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := deadline.Budget(r.Context(), 5*time.Second)
+	defer cancel()
+
+	teamID, userID, ok := h.requireDelegationMember(ctx, w, r)
+	if !ok {
+		return
+	}
+
+	delegationID, err := uuid.Parse(chi.URLParam(r, "delegation_id"))
+	if err != nil {
+		helper.RespondError(w, r, apperror.BadRequest("invalid delegation id"))
+		return
+	}
+
+	now := time.Now().UTC()
+	d, err := h.delegationStore.Get(ctx, teamID, delegationID, now)
+	if err != nil {
+		if errors.Is(err, delegationstore.ErrDelegationNotFound) {
+			helper.RespondError(w, r, apperror.NotFound("delegation not found"))
+			return
+		}
+		logger.Error(ctx, "delete delegation: get failed", "err", err)
+		helper.RespondError(w, r, apperror.InternalError("internal error", err))
+		return
+	}
+	if d.DelegatorID != userID {
+		isOwnerOrAdmin, err := h.teamStore.IsOwnerOrAdmin(ctx, teamID, userID)
+		if err != nil {
+			logger.Error(ctx, "delete delegation: role check failed", "err", err)
+			helper.RespondError(w, r, apperror.InternalError("internal error", err))
+			return
+		}
+		if !isOwnerOrAdmin {
+			helper.RespondError(w, r, apperror.Forbidden("only the delegator or a team owner/admin can revoke a delegation"))
+			return
+		}
+	}
+
+	if err := h.delegationStore.Delete(ctx, teamID, delegationID); err != nil {
+		if errors.Is(err, delegationstore.ErrDelegationNotFound) {
+			helper.RespondError(w, r, apperror.NotFound("delegation not found"))
+			return
+		}
+		logger.Error(ctx, "delete delegation: store failed", "err", err)
+		helper.RespondError(w, r, apperror.InternalError("internal error", err))
+		return
+	}
+
+	h.recordDelegation(ctx, r, auditstore.ActionDelegationRevoked, &userID, d, now)
+	logger.Info(ctx, "delegation revoked", "team_id", teamID, "delegation_id", delegationID, "user_id", userID)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *TaskHandler) requireDelegationMember(ctx context.Context, w http.ResponseWriter, r *http.Request) (uuid.UUID, uuid.UUID, bool) {
+	userID, ok := middleware.GetUserIDFromContext(ctx)
+	if !ok {
+		helper.RespondError(w, r, apperror.Unauthorized("authentication required"))
+		return uuid.Nil, uuid.Nil, false
+	}
+
+	teamID, err := uuid.Parse(chi.URLParam(r, "team_id"))
+	if err != nil {
+		helper.RespondError(w, r, apperror.BadRequest("invalid team id"))
+		return uuid.Nil, uuid.Nil, false
+	}
+
+	isMember, err := h.teamStore.IsMember(ctx, teamID, userID)
+	if err != nil {
+		logger.Error(ctx, "delegations: membership check failed", "err", err)
+		helper.RespondError(w, r, apperror.InternalError("internal error", err))
+		return uuid.Nil, uuid.Nil, false
+	}
+	if !isMember {
+		helper.RespondError(w, r, apperror.Forbidden("only team members can manage delegations"))
+		return uuid.Nil, uuid.Nil, false
+	}
+	return teamID, userID, true
+}
+
+// actsAsReporter reports whether userID holds the powers of the task's
+// creator: as the creator, or through a delegation from them. delegated
+// is true in the second case.
+func (h *TaskHandler) actsAsReporter(ctx context.Context, task *store.Task, userID uuid.UUID, now time.Time) (allowed, delegated bool, err error) {
+	if userID == task.ReporterID {
+		return true, false, nil
+	}
+	covered, err := h.delegationStore.Covers(ctx, task.TeamID, task.ReporterID, userID, now)
+	if err != nil {
+		return false, false, err
+	}
+	return covered, covered, nil
+}
+
+// recordDelegationUse audits an action a delegate took on a task in its
+// creator's place.
+func (h *TaskHandler) recordDelegationUse(ctx context.Context, r *http.Request, task *store.Task, delegateID uuid.UUID, action string, now time.Time) {
+	if err := h.auditStore.Record(ctx, auditstore.Entry{
+		ActorID:    &delegateID,
+		Action:     auditstore.ActionDelegationUsed,
+		TargetType: auditstore.TargetTask,
+		TargetID:   &task.ID,
+		TeamID:     &task.TeamID,
+		Metadata: map[string]any{
+			"on_behalf_of": task.ReporterID,
+			"action":       action,
+		},
+		IP:        helper.GetClientIP(r),
+		CreatedAt: now,
+	}); err != nil {
+		logger.Error(ctx, "delegation: audit use failed", "task_id", task.ID, "err", err)
+	}
+}
+
+func (h *TaskHandler) recordDelegation(ctx context.Context, r *http.Request, action auditstore.Action, actorID *uuid.UUID, d *delegationstore.Delegation, now time.Time) {
+	if err := h.auditStore.Record(ctx, auditstore.Entry{
+		ActorID:    actorID,
+		Action:     action,
+		TargetType: auditstore.TargetDelegation,
+		TargetID:   &d.ID,
+		TeamID:     &d.TeamID,
+		Metadata: map[string]any{
+			"delegator_id": d.DelegatorID,
+			"delegate_id":  d.DelegateID,
+			"starts_at":    d.StartsAt,
+			"ends_at":      d.EndsAt,
+		},
+		IP:        helper.GetClientIP(r),
+		CreatedAt: now,
+	}); err != nil {
+		logger.Error(ctx, "delegation: audit failed", "delegation_id", d.ID, "err", err)
+	}
+}
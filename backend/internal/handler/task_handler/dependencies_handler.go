@@ -0,0 +1,224 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/diagnosis/interactive-todo/api/types"
+	"github.com/diagnosis/interactive-todo/internal/apperror"
+	"github.com/diagnosis/interactive-todo/internal/deadline"
+	"github.com/diagnosis/interactive-todo/internal/helper"
+	"github.com/diagnosis/interactive-todo/internal/logger"
+	middleware "github.com/diagnosis/interactive-todo/internal/middleware/auth"
+	store "github.com/diagnosis/interactive-todo/internal/store/tasks"
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+)
+
+const (
+	defaultDependencyDepth = 5
+	maxDependencyDepth     = 20
+
+	defaultCrossTeamLimit = 100
+	maxCrossTeamLimit     = 500
+)
+
+// ListTaskDependencies shows what blocks the task and what it blocks,
+// following the chain through every team the user is in.
+func (h *TaskHandler) ListTaskDependencies(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := deadline.Budget(r.Context(), 5*time.Second)
+	defer cancel()
+
+	userID, ok := middleware.GetUserIDFromContext(ctx)
+	if !ok {
+		helper.RespondError(w, r, apperror.Unauthorized("unauthorized"))
+		return
+	}
+
+	id, err := parseTaskID(r)
+	if err != nil {
+		helper.RespondError(w, r, apperror.BadRequest("invalid task id"))
+		return
+	}
+
+	depth := defaultDependencyDepth
+	if v := r.URL.Query().Get("depth"); v != "" {
+		depth, err = strconv.Atoi(v)
+		if err != nil || depth < 1 || depth > maxDependencyDepth {
+			helper.RespondError(w, r, apperror.BadRequest(fmt.Sprintf("depth must be between 1 and %d", maxDependencyDepth)))
+			return
+		}
+	}
+
+	task, err := h.getTaskByID(ctx, id, userID)
+	if err != nil {
+		if errors.Is(err, store.ErrTaskNotFound) {
+			helper.RespondError(w, r, apperror.NotFound("task not found"))
+			return
+		}
+		logger.Error(ctx, "list dependencies: get task failed", "task_id", id, "err", err)
+		helper.RespondError(w, r, apperror.InternalError("internal error", err))
+		return
+	}
+	isMember, err := h.teamStore.IsMember(ctx, task.TeamID, userID)
+	if err != nil {
+		logger.Error(ctx, "list dependencies: membership check failed", "err", err)
+		helper.RespondError(w, r, apperror.InternalError("internal error", err))
+		return
+	}
+	if !isMember {
+		helper.RespondError(w, r, apperror.Forbidden("forbidden"))
+		return
+	}
+
+	chain, err := h.taskStore.DependencyChain(ctx, id, userID, depth)
+	if err != nil {
+		logger.Error(ctx, "list dependencies: store query failed", "task_id", id, "err", err)
+		helper.RespondError(w, r, apperror.InternalError("internal error", err))
+		return
+	}
+	helper.RespondJSON(w, r, http.StatusOK, chain)
+}
+
+// AddTaskDependency marks the task as blocked by another, which may be in
+// any team the user is in.
+func (h *TaskHandler) AddTaskDependency(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := deadline.Budget(r.Context(), 5*time.Second)
+	defer cancel()
+
+	r.Body = http.MaxBytesReader(w, r.Body, 1<<20)
+	defer r.Body.Close()
+
+	var in types.AddDependencyRequest
+	dec := json.NewDecoder(r.Body)
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&in); err != nil {
+		logger.Error(ctx, "add dependency: bad json", "err", err)
+		helper.RespondError(w, r, apperror.BadRequest("invalid request body"))
+		return
+	}
+	if in.BlockerID == uuid.Nil {
+		helper.RespondError(w, r, apperror.BadRequest("blocker_id is required"))
+		return
+	}
+
+	task, userID, ok := h.taskToChange(ctx, w, r, "add dependency", "change task dependencies")
+	if !ok {
+		return
+	}
+
+	// the blocker must be a task the user could open
+	blocker, err := h.getTaskByID(ctx, in.BlockerID, userID)
+	if err == nil {
+		var isMember bool
+		if isMember, err = h.teamStore.IsMember(ctx, blocker.TeamID, userID); err == nil && !isMember {
+			err = store.ErrTaskNotFound
+		}
+	}
+	if err != nil {
+		if errors.Is(err, store.ErrTaskNotFound) {
+			helper.RespondError(w, r, apperror.NotFound("blocker task not found"))
+			return
+		}
+		logger.Error(ctx, "add dependency: get blocker failed", "blocker_id", in.BlockerID, "err", err)
+		helper.RespondError(w, r, apperror.InternalError("internal error", err))
+		return
+	}
+
+	dep, err := h.taskStore.AddDependency(ctx, task.ID, blocker.ID, &userID, time.Now().UTC())
+	if err != nil {
+		switch {
+		case errors.Is(err, store.ErrInvalidInput):
+			helper.RespondError(w, r, apperror.BadRequest(err.Error()))
+		case errors.Is(err, store.ErrDependencyExists):
+			helper.RespondError(w, r, apperror.Conflict("task is already blocked by this task"))
+		case errors.Is(err, store.ErrDependencyCycle):
+			helper.RespondError(w, r, apperror.Conflict("the blocker already waits on this task"))
+		case errors.Is(err, store.ErrTaskNotFound):
+			helper.RespondError(w, r, apperror.NotFound("task not found"))
+		default:
+			logger.Error(ctx, "add dependency: store error", "err", err)
+			helper.RespondError(w, r, apperror.InternalError("internal error", err))
+		}
+		return
+	}
+
+	logger.Info(ctx, "task dependency added", "task_id", task.ID, "blocker_id", blocker.ID, "user_id", userID,
+		"cross_team", task.TeamID != blocker.TeamID)
+	helper.RespondJSON(w, r, http.StatusCreated, dep)
+}
+
+func (h *TaskHandler) RemoveTaskDependency(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := deadline.Budget(r.Context(), 5*time.Second)
+	defer cancel()
+
+	blockerID, err := uuid.Parse(chi.URLParam(r, "blocker_id"))
+	if err != nil {
+		helper.RespondError(w, r, apperror.BadRequest("invalid blocker id"))
+		return
+	}
+
+	task, userID, ok := h.taskToChange(ctx, w, r, "remove dependency", "change task dependencies")
+	if !ok {
+		return
+	}
+
+	if err := h.taskStore.RemoveDependency(ctx, task.ID, blockerID); err != nil {
+		if errors.Is(err, store.ErrDependencyNotFound) {
+			helper.RespondError(w, r, apperror.NotFound("task is not blocked by this task"))
+			return
+		}
+		logger.Error(ctx, "remove dependency: store error", "err", err)
+		helper.RespondError(w, r, apperror.InternalError("internal error", err))
+		return
+	}
+
+	logger.Info(ctx, "task dependency removed", "task_id", task.ID, "blocker_id", blockerID, "user_id", userID)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// ListCrossTeamDependencies lists the links between tasks of different
+// teams among the user's teams, for tracing work that waits on another
+// team. Links where either task is done or canceled are left out unless
+// ?include_resolved=true.
+func (h *TaskHandler) ListCrossTeamDependencies(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := deadline.Budget(r.Context(), 5*time.Second)
+	defer cancel()
+
+	userID, ok := middleware.GetUserIDFromContext(ctx)
+	if !ok {
+		helper.RespondError(w, r, apperror.Unauthorized("unauthorized"))
+		return
+	}
+
+	var err error
+	q := r.URL.Query()
+	limit := defaultCrossTeamLimit
+	if v := q.Get("limit"); v != "" {
+		limit, err = strconv.Atoi(v)
+		if err != nil || limit < 1 || limit > maxCrossTeamLimit {
+			helper.RespondError(w, r, apperror.BadRequest(fmt.Sprintf("limit must be between 1 and %d", maxCrossTeamLimit)))
+			return
+		}
+	}
+	includeResolved := false
+	if v := q.Get("include_resolved"); v != "" {
+		includeResolved, err = strconv.ParseBool(v)
+		if err != nil {
+			helper.RespondError(w, r, apperror.BadRequest("include_resolved must be true or false"))
+			return
+		}
+	}
+
+	deps, err := h.taskStore.ListCrossTeamDependencies(ctx, userID, includeResolved, limit)
+	if err != nil {
+		logger.Error(ctx, "list cross-team dependencies: store query failed", "err", err)
+		helper.RespondError(w, r, apperror.InternalError("internal error", err))
+		return
+	}
+	helper.RespondJSON(w, r, http.StatusOK, types.CrossTeamDependenciesResponse{Dependencies: deps})
+}
@@ -0,0 +1,41 @@
+package handler
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/diagnosis/interactive-todo/api/types"
+	"github.com/diagnosis/interactive-todo/internal/calendar"
+	"github.com/google/uuid"
+)
+
+// applyDueDatePolicy checks dueAt against the team calendar. Under warn the
+// date is kept and a notice returned; under shift it moves to the next
+// working day at the same local time.
+func (h *TaskHandler) applyDueDatePolicy(ctx context.Context, teamID uuid.UUID, dueAt time.Time) (time.Time, *types.DueDateNotice, error) {
+	cal, err := h.calendarStore.Load(ctx, teamID)
+	if err != nil {
+		return time.Time{}, nil, fmt.Errorf("load team calendar: %w", err)
+	}
+	dueAt, notice := dueDatePolicy(cal, dueAt)
+	return dueAt, notice, nil
+}
+
+// dueDatePolicy is applyDueDatePolicy for a calendar already loaded.
+func dueDatePolicy(cal *calendar.Calendar, dueAt time.Time) (time.Time, *types.DueDateNotice) {
+	if cal.Policy == calendar.PolicyOff {
+		return dueAt, nil
+	}
+	reason, off := cal.NonWorking(dueAt)
+	if !off {
+		return dueAt, nil
+	}
+
+	notice := &types.DueDateNotice{Requested: dueAt.UTC(), Reason: reason}
+	if cal.Policy == calendar.PolicyShift {
+		notice.Shifted = true
+		dueAt = cal.NextWorkingDay(dueAt).UTC()
+	}
+	return dueAt, notice
+}
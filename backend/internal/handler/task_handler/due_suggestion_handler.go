@@ -0,0 +1,115 @@
+package handler
+
+import (
+	"fmt"
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/diagnosis/interactive-todo/api/types"
+	"github.com/diagnosis/interactive-todo/internal/apperror"
+	"github.com/diagnosis/interactive-todo/internal/deadline"
+	"github.com/diagnosis/interactive-todo/internal/helper"
+	"github.com/diagnosis/interactive-todo/internal/logger"
+	middleware "github.com/diagnosis/interactive-todo/internal/middleware/auth"
+	store "github.com/diagnosis/interactive-todo/internal/store/tasks"
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+)
+
+const (
+	// focusHoursPerDay is the task work assumed to fit in a working day,
+	// after meetings and interruptions.
+	focusHoursPerDay = 6.0
+	// defaultEstimateHours stands in for tasks without an estimate.
+	defaultEstimateHours = 4.0
+	// suggestedDueHour is the local time of day suggestions land on.
+	suggestedDueHour = 17
+)
+
+// SuggestDueDate proposes a due date for a new task: the assignee's queued
+// work plus this task's estimate, spread over the team's working days.
+// Team members only; the assignee defaults to the caller.
+func (h *TaskHandler) SuggestDueDate(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := deadline.Budget(r.Context(), 5*time.Second)
+	defer cancel()
+
+	userID, ok := middleware.GetUserIDFromContext(ctx)
+	if !ok {
+		helper.RespondError(w, r, apperror.Unauthorized("authentication required"))
+		return
+	}
+
+	teamID, err := uuid.Parse(chi.URLParam(r, "team_id"))
+	if err != nil {
+		helper.RespondError(w, r, apperror.BadRequest("invalid team id"))
+		return
+	}
+
+	assigneeID := userID
+	if v := r.URL.Query().Get("assignee_id"); v != "" {
+		if assigneeID, err = uuid.Parse(v); err != nil {
+			helper.RespondError(w, r, apperror.BadRequest("invalid assignee_id"))
+			return
+		}
+	}
+
+	estimate := defaultEstimateHours
+	if v := r.URL.Query().Get("estimate_hours"); v != "" {
+		estimate, err = strconv.ParseFloat(v, 64)
+		if err != nil || estimate <= 0 || estimate > store.MaxEstimateHours {
+			helper.RespondError(w, r, apperror.BadRequest(fmt.Sprintf("estimate_hours must be above 0 and at most %d", store.MaxEstimateHours)))
+			return
+		}
+	}
+
+	for _, id := range []uuid.UUID{userID, assigneeID} {
+		isMember, err := h.teamStore.IsMember(ctx, teamID, id)
+		if err != nil {
+			logger.Error(ctx, "suggest due date: membership check failed", "err", err)
+			helper.RespondError(w, r, apperror.InternalError("internal error", err))
+			return
+		}
+		if !isMember && id == userID {
+			helper.RespondError(w, r, apperror.Forbidden("only team members can get due date suggestions"))
+			return
+		}
+		if !isMember {
+			helper.RespondError(w, r, apperror.BadRequest("assignee must be a member of the team"))
+			return
+		}
+	}
+
+	now := time.Now().UTC()
+	workload, err := h.taskStore.GetAssigneeWorkload(ctx, assigneeID, now)
+	if err != nil {
+		logger.Error(ctx, "suggest due date: load workload failed", "err", err)
+		helper.RespondError(w, r, apperror.InternalError("internal error", err))
+		return
+	}
+	cal, err := h.calendarStore.Load(ctx, teamID)
+	if err != nil {
+		logger.Error(ctx, "suggest due date: load team calendar failed", "err", err)
+		helper.RespondError(w, r, apperror.InternalError("internal error", err))
+		return
+	}
+
+	queued := workload.EstimatedHours + float64(workload.UnestimatedTasks)*defaultEstimateHours
+	days := max(1, int(math.Ceil((queued+estimate)/focusHoursPerDay)))
+
+	day := cal.AddWorkingDays(now, days).In(cal.Location)
+	due := time.Date(day.Year(), day.Month(), day.Day(), suggestedDueHour, 0, 0, 0, cal.Location)
+
+	helper.RespondJSON(w, r, http.StatusOK, types.DueDateSuggestion{
+		TeamID:               teamID,
+		AssigneeID:           assigneeID,
+		SuggestedDueAt:       due.UTC(),
+		WorkingDays:          days,
+		EstimateHours:        estimate,
+		QueuedHours:          queued,
+		Workload:             *workload,
+		HoursPerDay:          focusHoursPerDay,
+		DefaultEstimateHours: defaultEstimateHours,
+	})
+}
@@ -0,0 +1,219 @@
+package handler
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/diagnosis/interactive-todo/internal/apperror"
+	store "github.com/diagnosis/interactive-todo/internal/store/tasks"
+)
+
+// exportStatusTrailer is set to "complete" or "interrupted" once an export
+// has been written.
+const exportStatusTrailer = "X-Export-Status"
+
+// taskExportWriter writes tasks in one export format.
+type taskExportWriter interface {
+	write(t *store.ExportedTask) error
+	// close ends a complete export
+	close() error
+	// fail ends an export that broke off
+	fail(err error)
+}
+
+type exportFormat struct {
+	contentType string
+	ext         string
+	newWriter   func(w io.Writer) taskExportWriter
+}
+
+// exportFormats are the ?format= values; none is NDJSON.
+var exportFormats = map[string]exportFormat{
+	"":       {"application/x-ndjson", "ndjson", newNDJSONExport},
+	"ndjson": {"application/x-ndjson", "ndjson", newNDJSONExport},
+	"json":   {"application/json", "json", newJSONExport},
+	"csv":    {"text/csv; charset=utf-8", "csv", newCSVExport},
+}
+
+type ndjsonExport struct {
+	enc *json.Encoder
+}
+
+func newNDJSONExport(w io.Writer) taskExportWriter {
+	return &ndjsonExport{enc: json.NewEncoder(w)}
+}
+
+func (e *ndjsonExport) write(t *store.ExportedTask) error { return e.enc.Encode(t) }
+
+func (e *ndjsonExport) close() error { return nil }
+
+func (e *ndjsonExport) fail(err error) {
+	_ = e.enc.Encode(map[string]any{
+		"error": map[string]string{
+			"code":    string(apperror.AsAppError(err).Code),
+			"message": "export interrupted",
+		},
+	})
+}
+
+// jsonExport writes one array, element by element.
+type jsonExport struct {
+	w       io.Writer
+	written bool
+}
+
+func newJSONExport(w io.Writer) taskExportWriter {
+	return &jsonExport{w: w}
+}
+
+func (e *jsonExport) write(t *store.ExportedTask) error {
+	b, err := json.Marshal(t)
+	if err != nil {
+		return err
+	}
+	sep := ",\n"
+	if !e.written {
+		sep = "[\n"
+		e.written = true
+	}
+	if _, err := io.WriteString(e.w, sep); err != nil {
+		return err
+	}
+	_, err = e.w.Write(b)
+	return err
+}
+
+func (e *jsonExport) close() error {
+	end := "\n]\n"
+	if !e.written {
+		end = "[]\n"
+	}
+	_, err := io.WriteString(e.w, end)
+	return err
+}
+
+// fail leaves the array open, so the truncated export does not parse.
+func (e *jsonExport) fail(error) {}
+
+// csvColumns are the columns of a CSV export, in order.
+var csvColumns = []string{
+	"id", "title", "description", "status", "workflow_state", "priority",
+	"reporter_email", "assignee_email", "labels", "project_id",
+	"start_at", "due_at", "estimate_hours", "private", "vote_count",
+	"custom_fields", "created_at", "updated_at", "completed_at", "canceled_at",
+	"archived_at", "archived",
+}
+
+// csvExport writes one row per task. Labels are joined with "; " and
+// custom fields are a JSON object. Text a spreadsheet would read as a
+// formula is prefixed with a single quote.
+type csvExport struct {
+	cw     *csv.Writer
+	header bool
+}
+
+func newCSVExport(w io.Writer) taskExportWriter {
+	return &csvExport{cw: csv.NewWriter(w)}
+}
+
+func (e *csvExport) writeHeader() error {
+	if e.header {
+		return nil
+	}
+	e.header = true
+	return e.cw.Write(csvColumns)
+}
+
+func (e *csvExport) write(t *store.ExportedTask) error {
+	if err := e.writeHeader(); err != nil {
+		return err
+	}
+
+	labels := make([]string, len(t.Labels))
+	for i, l := range t.Labels {
+		labels[i] = l.Name
+	}
+	customFields := ""
+	if len(t.CustomFields) > 0 {
+		b, err := json.Marshal(t.CustomFields)
+		if err != nil {
+			return err
+		}
+		customFields = string(b)
+	}
+	projectID := ""
+	if t.ProjectID != nil {
+		projectID = t.ProjectID.String()
+	}
+	estimate := ""
+	if t.EstimateHours != nil {
+		estimate = strconv.FormatFloat(*t.EstimateHours, 'f', -1, 64)
+	}
+
+	if err := e.cw.Write([]string{
+		t.ID.String(),
+		csvText(t.Title),
+		csvText(deref(t.Description)),
+		string(t.Status),
+		csvText(deref(t.WorkflowState)),
+		string(t.Priority),
+		t.ReporterEmail,
+		t.AssigneeEmail,
+		csvText(strings.Join(labels, "; ")),
+		projectID,
+		csvTime(t.StartAt),
+		csvTime(&t.DueAt),
+		estimate,
+		strconv.FormatBool(t.Private),
+		strconv.Itoa(t.VoteCount),
+		customFields,
+		csvTime(&t.CreatedAt),
+		csvTime(&t.UpdatedAt),
+		csvTime(t.CompletedAt),
+		csvTime(t.CanceledAt),
+		csvTime(t.ArchivedAt),
+		strconv.FormatBool(t.Archived),
+	}); err != nil {
+		return err
+	}
+	// the csv writer buffers; hand each row on so the handler's
+	// periodic flushes reach the client
+	e.cw.Flush()
+	return e.cw.Error()
+}
+
+func (e *csvExport) close() error {
+	if err := e.writeHeader(); err != nil {
+		return err
+	}
+	e.cw.Flush()
+	return e.cw.Error()
+}
+
+func (e *csvExport) fail(error) { e.cw.Flush() }
+
+func csvTime(t *time.Time) string {
+	if t == nil {
+		return ""
+	}
+	return t.UTC().Format(time.RFC3339)
+}
+
+// csvText keeps spreadsheets from evaluating user text as a formula.
+func csvText(s string) string {
+	if s != "" && strings.ContainsRune("=+-@\t\r", rune(s[0])) {
+		return "'" + s
+	}
+	return s
+}
+
+func deref(s *string) string {
+	if s == nil {
+		return ""
+	}
+	return *s
+}
@@ -0,0 +1,196 @@
+package handler
+
+import (
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/diagnosis/interactive-todo/api/types"
+	"github.com/diagnosis/interactive-todo/internal/apperror"
+	jwttoken "github.com/diagnosis/interactive-todo/internal/auth/jwt"
+	"github.com/diagnosis/interactive-todo/internal/auth/signedurl"
+	"github.com/diagnosis/interactive-todo/internal/deadline"
+	"github.com/diagnosis/interactive-todo/internal/helper"
+	"github.com/diagnosis/interactive-todo/internal/logger"
+	middleware "github.com/diagnosis/interactive-todo/internal/middleware/auth"
+	store "github.com/diagnosis/interactive-todo/internal/store/tasks"
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+)
+
+const (
+	// exports stream for up to the whole request budget, well past the
+	// server's default write timeout
+	exportBudget = 55 * time.Second
+	// rows written between flushes
+	exportFlushEvery = 500
+)
+
+// ExportTeamTasks streams the team's tasks, with their labels and the
+// emails of their reporter and assignee, as they are read from the
+// database. ?format= picks ndjson (the default, one task per line), json
+// (one array) or csv. ?include_archived=true adds archived tasks, and
+// appends those moved to the archive table.
+//
+// Once streaming has started the status can no longer change, so the
+// X-Export-Status trailer says whether the export is complete. A failure
+// part way through also ends NDJSON with an {"error": ...} line and leaves
+// the JSON array unclosed, so it does not parse.
+func (h *TaskHandler) ExportTeamTasks(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := deadline.Budget(r.Context(), exportBudget)
+	defer cancel()
+
+	userID, ok := middleware.GetUserIDFromContext(ctx)
+	if !ok {
+		logger.Info(ctx, "export team tasks: unauthorized")
+		helper.RespondError(w, r, apperror.Unauthorized("authentication required"))
+		return
+	}
+
+	teamIDStr := chi.URLParam(r, "team_id")
+	teamID, err := uuid.Parse(teamIDStr)
+	if err != nil {
+		logger.Error(ctx, "export team tasks: invalid team id", "team_id", teamIDStr, "err", err)
+		helper.RespondError(w, r, apperror.BadRequest("invalid team id"))
+		return
+	}
+
+	format, ok := exportFormats[r.URL.Query().Get("format")]
+	if !ok {
+		helper.RespondError(w, r, apperror.BadRequest("format must be ndjson, json or csv"))
+		return
+	}
+	includeArchived := false
+	if v := r.URL.Query().Get("include_archived"); v != "" {
+		includeArchived, err = strconv.ParseBool(v)
+		if err != nil {
+			helper.RespondError(w, r, apperror.BadRequest("include_archived must be true or false"))
+			return
+		}
+	}
+
+	isMember, err := h.teamStore.IsMember(ctx, teamID, userID)
+	if err != nil {
+		logger.Error(ctx, "export team tasks: membership check failed", "err", err)
+		helper.RespondError(w, r, apperror.InternalError("internal error", err))
+		return
+	}
+	if !isMember {
+		helper.RespondError(w, r, apperror.Forbidden("only team members can export team tasks"))
+		return
+	}
+
+	rc := http.NewResponseController(w)
+	if err := rc.SetWriteDeadline(time.Now().Add(exportBudget)); err != nil {
+		logger.Warn(ctx, "export team tasks: cannot extend write deadline", "err", err)
+	}
+
+	w.Header().Set("Content-Type", format.contentType)
+	w.Header().Set("Content-Disposition", `attachment; filename="tasks-`+teamID.String()+`.`+format.ext+`"`)
+	w.Header().Set("Trailer", exportStatusTrailer)
+	w.WriteHeader(http.StatusOK)
+
+	out := format.newWriter(w)
+	count := 0
+	write := func(t *store.ExportedTask) error {
+		if err := out.write(t); err != nil {
+			return err
+		}
+		count++
+		if count%exportFlushEvery == 0 {
+			return rc.Flush()
+		}
+		return nil
+	}
+	err = h.taskStore.StreamTeamTasks(ctx, teamID, userID, includeArchived, write)
+	if err == nil && includeArchived {
+		err = h.taskStore.StreamArchivedTeamTasks(ctx, teamID, userID, write)
+	}
+	if err == nil {
+		err = out.close()
+	}
+	if err != nil {
+		if helper.ClientGone(r) {
+			logger.Info(ctx, "export team tasks: client disconnected", "team_id", teamID, "count", count)
+			return
+		}
+		logger.Error(ctx, "export team tasks: interrupted", "team_id", teamID, "count", count, "err", err)
+		out.fail(err)
+		w.Header().Set(exportStatusTrailer, "interrupted")
+		_ = rc.Flush()
+		return
+	}
+	w.Header().Set(exportStatusTrailer, "complete")
+	_ = rc.Flush()
+
+	logger.Info(ctx, "export team tasks: success", "user_id", userID, "team_id", teamID,
+		"format", format.ext, "count", count)
+}
+
+// CreateExportLink returns a signed URL for ExportTeamTasks that a browser
+// can open without the Authorization header. It is valid for
+// signedurl.MaxTTL and only for this team and these options.
+func (h *TaskHandler) CreateExportLink(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := deadline.Budget(r.Context(), 5*time.Second)
+	defer cancel()
+
+	claims, ok := middleware.GetClaimsFromContext(ctx)
+	if !ok {
+		logger.Info(ctx, "create export link: unauthorized")
+		helper.RespondError(w, r, apperror.Unauthorized("authentication required"))
+		return
+	}
+	// a link must not grant more than the token asking for it
+	if !claims.HasScope(jwttoken.ScopeTasksRead) {
+		helper.RespondError(w, r, apperror.Forbidden("token is missing scope "+string(jwttoken.ScopeTasksRead)))
+		return
+	}
+
+	teamIDStr := chi.URLParam(r, "team_id")
+	teamID, err := uuid.Parse(teamIDStr)
+	if err != nil {
+		logger.Error(ctx, "create export link: invalid team id", "team_id", teamIDStr, "err", err)
+		helper.RespondError(w, r, apperror.BadRequest("invalid team id"))
+		return
+	}
+
+	query := url.Values{}
+	if v := r.URL.Query().Get("format"); v != "" {
+		if _, ok := exportFormats[v]; !ok {
+			helper.RespondError(w, r, apperror.BadRequest("format must be ndjson, json or csv"))
+			return
+		}
+		query.Set("format", v)
+	}
+	if v := r.URL.Query().Get("include_archived"); v != "" {
+		includeArchived, err := strconv.ParseBool(v)
+		if err != nil {
+			helper.RespondError(w, r, apperror.BadRequest("include_archived must be true or false"))
+			return
+		}
+		query.Set("include_archived", strconv.FormatBool(includeArchived))
+	}
+
+	isMember, err := h.teamStore.IsMember(ctx, teamID, claims.UserID)
+	if err != nil {
+		logger.Error(ctx, "create export link: membership check failed", "err", err)
+		helper.RespondError(w, r, apperror.InternalError("internal error", err))
+		return
+	}
+	if !isMember {
+		helper.RespondError(w, r, apperror.Forbidden("only team members can export team tasks"))
+		return
+	}
+
+	// sign the export path under the same version prefix this link was requested on
+	path := strings.TrimSuffix(r.URL.Path, "/link")
+	signed, expiresAt := h.urlSigner.Sign(path, query, claims.UserID, jwttoken.ScopeTasksRead, signedurl.MaxTTL, time.Now())
+
+	logger.Info(ctx, "create export link: success", "user_id", claims.UserID, "team_id", teamID)
+	helper.RespondJSON(w, r, http.StatusCreated, types.ExportLink{
+		URL:       path + "?" + signed.Encode(),
+		ExpiresAt: expiresAt,
+	})
+}
@@ -0,0 +1,269 @@
+package handler
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/diagnosis/interactive-todo/api/types"
+	"github.com/diagnosis/interactive-todo/internal/apperror"
+	"github.com/diagnosis/interactive-todo/internal/deadline"
+	"github.com/diagnosis/interactive-todo/internal/helper"
+	"github.com/diagnosis/interactive-todo/internal/logger"
+	triagestore "github.com/diagnosis/interactive-todo/internal/store/triage"
+	"github.com/go-chi/chi/v5"
+)
+
+// Anonymous feedback: a team owner/admin hands out a link, and whoever has
+// it can leave feedback without signing in. It lands in the triage inbox
+// with no submitter, and the client IP is used for throttling only: it is
+// neither stored nor logged, and the routes' access log lines leave it out
+// (see middleware.Anonymous).
+
+const (
+	maxFeedbackPromptLen  = 500
+	maxFeedbackTitleLen   = 100
+	maxFeedbackMessageLen = 5000
+)
+
+// GetFeedbackLink reports whether the team has a feedback link. The token
+// is not kept, so it cannot be shown again; make a new link instead.
+func (h *TaskHandler) GetFeedbackLink(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := deadline.Budget(r.Context(), 5*time.Second)
+	defer cancel()
+
+	teamID, _, ok := h.requireTriageManager(ctx, w, r)
+	if !ok {
+		return
+	}
+
+	link, err := h.triageStore.GetFeedbackLink(ctx, teamID)
+	if err != nil && !errors.Is(err, triagestore.ErrFeedbackLinkNotFound) {
+		logger.Error(ctx, "get feedback link: store failed", "err", err)
+		helper.RespondError(w, r, apperror.InternalError("internal error", err))
+		return
+	}
+
+	helper.RespondJSON(w, r, http.StatusOK, types.FeedbackLinkStatus{
+		TeamID:  teamID,
+		Enabled: link != nil,
+		Link:    link,
+	})
+}
+
+// CreateFeedbackLink makes a new feedback link for the team. The previous
+// one, if any, stops working.
+func (h *TaskHandler) CreateFeedbackLink(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := deadline.Budget(r.Context(), 5*time.Second)
+	defer cancel()
+
+	teamID, userID, ok := h.requireTriageManager(ctx, w, r)
+	if !ok {
+		return
+	}
+
+	r.Body = http.MaxBytesReader(w, r.Body, 1<<20)
+	defer r.Body.Close()
+
+	// the body is optional
+	var in types.CreateFeedbackLinkRequest
+	dec := json.NewDecoder(r.Body)
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&in); err != nil && !errors.Is(err, io.EOF) {
+		logger.Error(ctx, "create feedback link: bad json", "err", err)
+		helper.RespondError(w, r, apperror.BadRequest("invalid request body"))
+		return
+	}
+	if in.Prompt != nil {
+		p := strings.TrimSpace(*in.Prompt)
+		if utf8.RuneCountInString(p) > maxFeedbackPromptLen {
+			helper.RespondError(w, r, apperror.BadRequest("prompt is too long"))
+			return
+		}
+		in.Prompt = &p
+		if p == "" {
+			in.Prompt = nil
+		}
+	}
+
+	raw := make([]byte, 32)
+	if _, err := rand.Read(raw); err != nil {
+		logger.Error(ctx, "create feedback link: token failed", "err", err)
+		helper.RespondError(w, r, apperror.InternalError("internal error", err))
+		return
+	}
+	token := base64.RawURLEncoding.EncodeToString(raw)
+
+	link, err := h.triageStore.SetFeedbackLink(ctx, teamID, feedbackTokenHash(token), in.Prompt, userID, time.Now().UTC())
+	if err != nil {
+		logger.Error(ctx, "create feedback link: store failed", "err", err)
+		helper.RespondError(w, r, apperror.InternalError("internal error", err))
+		return
+	}
+
+	logger.Info(ctx, "feedback link created", "team_id", teamID, "user_id", userID)
+	helper.RespondJSON(w, r, http.StatusCreated, types.FeedbackLinkCreated{
+		Link:  *link,
+		Token: token,
+		Path:  "/feedback/" + token,
+	})
+}
+
+func (h *TaskHandler) DeleteFeedbackLink(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := deadline.Budget(r.Context(), 5*time.Second)
+	defer cancel()
+
+	teamID, userID, ok := h.requireTriageManager(ctx, w, r)
+	if !ok {
+		return
+	}
+
+	if err := h.triageStore.DeleteFeedbackLink(ctx, teamID); err != nil {
+		if errors.Is(err, triagestore.ErrFeedbackLinkNotFound) {
+			helper.RespondError(w, r, apperror.NotFound("feedback link not found"))
+			return
+		}
+		logger.Error(ctx, "delete feedback link: store failed", "err", err)
+		helper.RespondError(w, r, apperror.InternalError("internal error", err))
+		return
+	}
+
+	logger.Info(ctx, "feedback link deleted", "team_id", teamID, "user_id", userID)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// GetPublicFeedback shows anonymous submitters which team the link is for.
+func (h *TaskHandler) GetPublicFeedback(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := deadline.Budget(r.Context(), 5*time.Second)
+	defer cancel()
+
+	link, ok := h.loadFeedbackLink(ctx, w, r)
+	if !ok {
+		return
+	}
+
+	helper.RespondJSON(w, r, http.StatusOK, types.PublicFeedback{
+		TeamName: link.TeamName,
+		Prompt:   link.Prompt,
+	})
+}
+
+// SubmitFeedback files anonymous feedback in the team's triage inbox.
+// Feedback taken for spam is dropped with the same response, so the
+// filter cannot be probed.
+func (h *TaskHandler) SubmitFeedback(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := deadline.Budget(r.Context(), 5*time.Second)
+	defer cancel()
+
+	link, ok := h.loadFeedbackLink(ctx, w, r)
+	if !ok {
+		return
+	}
+
+	now := time.Now().UTC()
+	if !h.spamGuard.AllowFeedback(helper.GetClientIP(r), link.TeamID, now) {
+		logger.Info(ctx, "submit feedback: throttled", "team_id", link.TeamID)
+		helper.RespondError(w, r, apperror.TooManyRequests("too much feedback, try again later"))
+		return
+	}
+
+	r.Body = http.MaxBytesReader(w, r.Body, 1<<20)
+	defer r.Body.Close()
+
+	var in types.FeedbackSubmission
+	dec := json.NewDecoder(r.Body)
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&in); err != nil {
+		logger.Error(ctx, "submit feedback: bad json", "err", err)
+		helper.RespondError(w, r, apperror.BadRequest("invalid request body"))
+		return
+	}
+
+	message := strings.TrimSpace(in.Message)
+	if message == "" {
+		helper.RespondError(w, r, apperror.BadRequest("message is required"))
+		return
+	}
+	if utf8.RuneCountInString(message) > maxFeedbackMessageLen {
+		helper.RespondError(w, r, apperror.BadRequest("message is too long"))
+		return
+	}
+	title := strings.TrimSpace(in.Title)
+	if utf8.RuneCountInString(title) > maxFeedbackTitleLen {
+		helper.RespondError(w, r, apperror.BadRequest("title is too long"))
+		return
+	}
+	if title == "" {
+		title = feedbackTitle(message)
+	}
+
+	recent, err := h.triageStore.RecentFeedbackTitles(ctx, link.TeamID, now.Add(-h.spamGuard.FeedbackDupeWindow()))
+	if err != nil {
+		logger.Error(ctx, "submit feedback: recent titles failed", "err", err)
+		helper.RespondError(w, r, apperror.InternalError("internal error", err))
+		return
+	}
+
+	if h.spamGuard.IsSpamFeedback(title, message, recent) {
+		logger.Info(ctx, "submit feedback: dropped as spam", "team_id", link.TeamID)
+	} else {
+		item, err := h.triageStore.Create(ctx, triagestore.Item{
+			TeamID:      link.TeamID,
+			Title:       title,
+			Description: &message,
+			Source:      triagestore.SourceFeedback,
+		}, now)
+		if err != nil {
+			logger.Error(ctx, "submit feedback: create triage item failed", "err", err)
+			helper.RespondError(w, r, apperror.InternalError("internal error", err))
+			return
+		}
+		logger.Info(ctx, "feedback submitted to triage", "team_id", link.TeamID, "triage_item_id", item.ID)
+	}
+
+	helper.RespondJSON(w, r, http.StatusAccepted, types.FeedbackReceipt{Status: "received"})
+}
+
+func (h *TaskHandler) loadFeedbackLink(ctx context.Context, w http.ResponseWriter, r *http.Request) (*triagestore.FeedbackLink, bool) {
+	token := chi.URLParam(r, "token")
+	if token == "" {
+		helper.RespondError(w, r, apperror.NotFound("feedback link not found"))
+		return nil, false
+	}
+
+	link, err := h.triageStore.FeedbackLinkByToken(ctx, feedbackTokenHash(token))
+	if err != nil {
+		if errors.Is(err, triagestore.ErrFeedbackLinkNotFound) {
+			helper.RespondError(w, r, apperror.NotFound("feedback link not found"))
+			return nil, false
+		}
+		logger.Error(ctx, "feedback: get link failed", "err", err)
+		helper.RespondError(w, r, apperror.InternalError("internal error", err))
+		return nil, false
+	}
+	return link, true
+}
+
+func feedbackTokenHash(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return fmt.Sprintf("%x", sum[:])
+}
+
+// feedbackTitle is the first line of message, cut to maxFeedbackTitleLen.
+func feedbackTitle(message string) string {
+	line, _, _ := strings.Cut(message, "\n")
+	line = strings.TrimSpace(line)
+	if utf8.RuneCountInString(line) <= maxFeedbackTitleLen {
+		return line
+	}
+	return strings.TrimSpace(string([]rune(line)[:maxFeedbackTitleLen]))
+}
@@ -0,0 +1,271 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/diagnosis/interactive-todo/internal/apperror"
+	"github.com/diagnosis/interactive-todo/internal/deadline"
+	"github.com/diagnosis/interactive-todo/internal/helper"
+	"github.com/diagnosis/interactive-todo/internal/logger"
+	middleware "github.com/diagnosis/interactive-todo/internal/middleware/auth"
+	"github.com/diagnosis/interactive-todo/internal/secure/spamguard"
+	formstore "github.com/diagnosis/interactive-todo/internal/store/forms"
+	store "github.com/diagnosis/interactive-todo/internal/store/tasks"
+	triagestore "github.com/diagnosis/interactive-todo/internal/store/triage"
+	userstore "github.com/diagnosis/interactive-todo/internal/store/users"
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+)
+
+type formSubmission struct {
+	Values map[string]string `json:"values"`
+}
+
+// GetPublicForm renders a public form for anonymous submitters. Internal
+// details such as the default assignee are not exposed.
+func (h *TaskHandler) GetPublicForm(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := deadline.Budget(r.Context(), 5*time.Second)
+	defer cancel()
+
+	form, ok := h.loadPublicForm(ctx, w, r)
+	if !ok {
+		return
+	}
+
+	helper.RespondJSON(w, r, http.StatusOK, map[string]any{
+		"id":          form.ID,
+		"name":        form.Name,
+		"description": form.Description,
+		"fields":      form.Fields,
+	})
+}
+
+// SubmitPublicForm handles an anonymous submission. The form's creator is
+// recorded as reporter; submissions are throttled per client IP.
+func (h *TaskHandler) SubmitPublicForm(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := deadline.Budget(r.Context(), 5*time.Second)
+	defer cancel()
+
+	now := time.Now().UTC()
+	ip := helper.GetClientIP(r)
+	if !h.spamGuard.AllowPublicSubmit(ip, now) {
+		logger.Info(ctx, "submit public form: throttled", "ip", ip)
+		helper.RespondError(w, r, apperror.TooManyRequests("too many submissions, try again later"))
+		return
+	}
+
+	form, ok := h.loadPublicForm(ctx, w, r)
+	if !ok {
+		return
+	}
+
+	in, ok := decodeFormSubmission(ctx, w, r)
+	if !ok {
+		return
+	}
+
+	h.submitForm(ctx, w, r, form, nil, in, now)
+}
+
+// SubmitTeamForm lets team members file requests through any team form.
+func (h *TaskHandler) SubmitTeamForm(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := deadline.Budget(r.Context(), 5*time.Second)
+	defer cancel()
+
+	userID, ok := middleware.GetUserIDFromContext(ctx)
+	if !ok {
+		helper.RespondError(w, r, apperror.Unauthorized("authentication required"))
+		return
+	}
+
+	teamID, err := uuid.Parse(chi.URLParam(r, "team_id"))
+	if err != nil {
+		helper.RespondError(w, r, apperror.BadRequest("invalid team id"))
+		return
+	}
+	formID, err := uuid.Parse(chi.URLParam(r, "form_id"))
+	if err != nil {
+		helper.RespondError(w, r, apperror.BadRequest("invalid form id"))
+		return
+	}
+
+	isMember, err := h.teamStore.IsMember(ctx, teamID, userID)
+	if err != nil {
+		logger.Error(ctx, "submit team form: membership check failed", "err", err)
+		helper.RespondError(w, r, apperror.InternalError("internal error", err))
+		return
+	}
+	if !isMember {
+		helper.RespondError(w, r, apperror.Forbidden("only team members can submit team forms"))
+		return
+	}
+
+	form, err := h.formStore.Get(ctx, formID)
+	if err != nil && !errors.Is(err, formstore.ErrFormNotFound) {
+		logger.Error(ctx, "submit team form: get form failed", "err", err)
+		helper.RespondError(w, r, apperror.InternalError("internal error", err))
+		return
+	}
+	if err != nil || form.TeamID != teamID {
+		helper.RespondError(w, r, apperror.NotFound("form not found"))
+		return
+	}
+
+	in, ok := decodeFormSubmission(ctx, w, r)
+	if !ok {
+		return
+	}
+
+	now := time.Now().UTC()
+	if claims, ok := middleware.GetClaimsFromContext(ctx); !ok || claims.UserType != userstore.TypeAdmin {
+		mute, err := h.spamGuard.CheckTaskCreate(ctx, userID, in.Values[formTitleKey(form)], now)
+		if err != nil {
+			if errors.Is(err, spamguard.ErrMuted) {
+				helper.RespondError(w, r, apperror.TooManyRequests("too many similar tasks, try again after "+mute.MutedUntil.Format(time.RFC3339)))
+				return
+			}
+			logger.Error(ctx, "submit team form: spam guard failed", "err", err)
+			helper.RespondError(w, r, apperror.InternalError("internal error", err))
+			return
+		}
+	}
+
+	h.submitForm(ctx, w, r, form, &userID, in, now)
+}
+
+// submitForm turns a submission into a task, or into a triage item for forms
+// with triage enabled. submitter is nil for anonymous submissions.
+func (h *TaskHandler) submitForm(
+	ctx context.Context,
+	w http.ResponseWriter,
+	r *http.Request,
+	form *formstore.Form,
+	submitter *uuid.UUID,
+	in *formSubmission,
+	now time.Time,
+) {
+	title, description, err := form.Render(in.Values)
+	if err != nil {
+		helper.RespondError(w, r, apperror.BadRequest(err.Error()))
+		return
+	}
+
+	if form.Triage {
+		item, err := h.triageStore.Create(ctx, triagestore.Item{
+			TeamID:      form.TeamID,
+			FormID:      &form.ID,
+			Title:       title,
+			Description: description,
+			SubmittedBy: submitter,
+		}, now)
+		if err != nil {
+			logger.Error(ctx, "submit form: create triage item failed", "err", err)
+			helper.RespondError(w, r, apperror.InternalError("internal error", err))
+			return
+		}
+		logger.Info(ctx, "form submitted to triage", "form_id", form.ID, "triage_item_id", item.ID)
+		helper.RespondJSON(w, r, http.StatusAccepted, item)
+		return
+	}
+
+	reporterID := form.CreatedBy
+	if submitter != nil {
+		reporterID = *submitter
+	}
+
+	assigneeID, err := h.formAssignee(ctx, form)
+	if err != nil {
+		logger.Error(ctx, "submit form: assignee check failed", "err", err)
+		helper.RespondError(w, r, apperror.InternalError("internal error", err))
+		return
+	}
+
+	dueAt := now.AddDate(0, 0, form.DueInDays)
+	task, err := h.createTask(ctx, newTask{
+		TeamID:      form.TeamID,
+		Title:       title,
+		Description: description,
+		ReporterID:  reporterID,
+		AssigneeID:  assigneeID,
+		Fallback:    form.CreatedBy,
+		DueAt:       dueAt,
+	}, now)
+	if err != nil {
+		if errors.Is(err, store.ErrInvalidInput) {
+			helper.RespondError(w, r, apperror.BadRequest(err.Error()))
+			return
+		}
+		logger.Error(ctx, "submit form: create task failed", "err", err)
+		helper.RespondError(w, r, apperror.InternalError("failed to create task", err))
+		return
+	}
+
+	logger.Info(ctx, "form submitted", "form_id", form.ID, "task_id", task.ID)
+	helper.RespondJSON(w, r, http.StatusCreated, task)
+}
+
+// formAssignee returns nil when the configured assignee is unset or has
+// since left the team, leaving the choice to auto-assignment and then the
+// form's creator.
+func (h *TaskHandler) formAssignee(ctx context.Context, form *formstore.Form) (*uuid.UUID, error) {
+	if form.AssigneeID == nil {
+		return nil, nil
+	}
+	isMember, err := h.teamStore.IsMember(ctx, form.TeamID, *form.AssigneeID)
+	if err != nil {
+		return nil, err
+	}
+	if !isMember {
+		return nil, nil
+	}
+	return form.AssigneeID, nil
+}
+
+func (h *TaskHandler) loadPublicForm(ctx context.Context, w http.ResponseWriter, r *http.Request) (*formstore.Form, bool) {
+	formID, err := uuid.Parse(chi.URLParam(r, "form_id"))
+	if err != nil {
+		helper.RespondError(w, r, apperror.BadRequest("invalid form id"))
+		return nil, false
+	}
+
+	form, err := h.formStore.Get(ctx, formID)
+	if err != nil && !errors.Is(err, formstore.ErrFormNotFound) {
+		logger.Error(ctx, "public form: get form failed", "err", err)
+		helper.RespondError(w, r, apperror.InternalError("internal error", err))
+		return nil, false
+	}
+	// Private forms look exactly like missing ones to anonymous callers.
+	if err != nil || !form.IsPublic {
+		helper.RespondError(w, r, apperror.NotFound("form not found"))
+		return nil, false
+	}
+	return form, true
+}
+
+func decodeFormSubmission(ctx context.Context, w http.ResponseWriter, r *http.Request) (*formSubmission, bool) {
+	r.Body = http.MaxBytesReader(w, r.Body, 1<<20)
+	defer r.Body.Close()
+
+	var in formSubmission
+	dec := json.NewDecoder(r.Body)
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&in); err != nil {
+		logger.Error(ctx, "submit form: bad json", "err", err)
+		helper.RespondError(w, r, apperror.BadRequest("invalid request body"))
+		return nil, false
+	}
+	return &in, true
+}
+
+func formTitleKey(form *formstore.Form) string {
+	for _, f := range form.Fields {
+		if f.MapsTo == formstore.MapsToTitle {
+			return f.Key
+		}
+	}
+	return ""
+}
@@ -0,0 +1,426 @@
+package handler
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"mime"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/diagnosis/interactive-todo/api/types"
+	"github.com/diagnosis/interactive-todo/internal/apperror"
+	"github.com/diagnosis/interactive-todo/internal/calendar"
+	"github.com/diagnosis/interactive-todo/internal/deadline"
+	"github.com/diagnosis/interactive-todo/internal/events"
+	"github.com/diagnosis/interactive-todo/internal/helper"
+	"github.com/diagnosis/interactive-todo/internal/labelrules"
+	"github.com/diagnosis/interactive-todo/internal/localtime"
+	"github.com/diagnosis/interactive-todo/internal/logger"
+	middleware "github.com/diagnosis/interactive-todo/internal/middleware/auth"
+	"github.com/diagnosis/interactive-todo/internal/secure/spamguard"
+	customfieldstore "github.com/diagnosis/interactive-todo/internal/store/customfields"
+	store "github.com/diagnosis/interactive-todo/internal/store/tasks"
+	userstore "github.com/diagnosis/interactive-todo/internal/store/users"
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+)
+
+// importFieldPrefix starts the name of a custom field column.
+const importFieldPrefix = "cf."
+
+const (
+	maxImportBytes = 10 << 20
+	maxImportRows  = 5000
+	importBudget   = 55 * time.Second
+)
+
+// importColumns are the CSV columns an import understands besides the
+// team's custom fields, which are cf.<key>; title and due_at are required.
+var importColumns = map[string]bool{
+	"title":          true,
+	"description":    true,
+	"assignee":       true,
+	"due_at":         true,
+	"start_at":       true,
+	"priority":       true,
+	"estimate_hours": true,
+}
+
+// taskImport is the state of an import while its rows are read.
+type taskImport struct {
+	reporterID uuid.UUID
+	members    map[string]uuid.UUID
+	cal        *calendar.Calendar
+	fields     []customfieldstore.CustomField
+	// loc is the importer's timezone, for dates without an offset
+	loc     *time.Location
+	now     time.Time
+	columns map[string]int
+}
+
+// ImportTeamTasks creates tasks from a CSV upload, sent as the body with
+// Content-Type text/csv or as the multipart field "file". The first row
+// names the columns. Each row is checked on its own and a rejected row
+// does not stop the others; the rows that pass are inserted together, so
+// either all of them are created or, on a database error, none.
+//
+// Imports apply the team's due date policy, workflow and confidentiality,
+// but not label rules or auto-assignment: a row without an assignee goes
+// to the importer.
+func (h *TaskHandler) ImportTeamTasks(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := deadline.Budget(r.Context(), importBudget)
+	defer cancel()
+
+	userID, ok := middleware.GetUserIDFromContext(ctx)
+	if !ok {
+		helper.RespondError(w, r, apperror.Unauthorized("authentication required"))
+		return
+	}
+
+	teamID, err := uuid.Parse(chi.URLParam(r, "team_id"))
+	if err != nil {
+		helper.RespondError(w, r, apperror.BadRequest("invalid team id"))
+		return
+	}
+
+	isOwnerOrAdmin, err := h.teamStore.IsOwnerOrAdmin(ctx, teamID, userID)
+	if err != nil {
+		logger.Error(ctx, "import tasks: role check failed", "err", err)
+		helper.RespondError(w, r, apperror.InternalError("internal error", err))
+		return
+	}
+	if !isOwnerOrAdmin {
+		helper.RespondError(w, r, apperror.Forbidden("only team owner/admin can import tasks"))
+		return
+	}
+
+	now := time.Now().UTC()
+	// checked before the file is read, so a muted user's upload is not
+	// parsed at all
+	if claims, ok := middleware.GetClaimsFromContext(ctx); !ok || claims.UserType != userstore.TypeAdmin {
+		mute, err := h.spamGuard.CheckTaskImport(ctx, userID, now)
+		if err != nil {
+			if errors.Is(err, spamguard.ErrMuted) {
+				logger.Info(ctx, "import tasks: importer muted", "user_id", userID, "muted_until", mute.MutedUntil)
+				helper.RespondError(w, r, apperror.TooManyRequests("too many tasks created, try again after "+mute.MutedUntil.Format(time.RFC3339)))
+				return
+			}
+			logger.Error(ctx, "import tasks: spam guard failed", "err", err)
+			helper.RespondError(w, r, apperror.InternalError("internal error", err))
+			return
+		}
+	}
+
+	r.Body = http.MaxBytesReader(w, r.Body, maxImportBytes)
+	defer r.Body.Close()
+
+	body, ok := importBody(w, r)
+	if !ok {
+		return
+	}
+
+	imp := &taskImport{reporterID: userID, loc: h.userLocation(ctx, userID), now: now}
+	if imp.members, err = h.teamStore.MemberIDsByEmail(ctx, teamID); err != nil {
+		logger.Error(ctx, "import tasks: list members failed", "err", err)
+		helper.RespondError(w, r, apperror.InternalError("internal error", err))
+		return
+	}
+	if imp.cal, err = h.calendarStore.Load(ctx, teamID); err != nil {
+		logger.Error(ctx, "import tasks: load team calendar failed", "err", err)
+		helper.RespondError(w, r, apperror.InternalError("internal error", err))
+		return
+	}
+	if imp.fields, err = h.customFieldStore.List(ctx, teamID); err != nil {
+		logger.Error(ctx, "import tasks: list custom fields failed", "err", err)
+		helper.RespondError(w, r, apperror.InternalError("internal error", err))
+		return
+	}
+
+	// rows are read one at a time; only the parsed tasks are kept
+	cr := csv.NewReader(body)
+	cr.FieldsPerRecord = -1
+	cr.TrimLeadingSpace = true
+	cr.ReuseRecord = true
+
+	header, err := cr.Read()
+	if err != nil {
+		importReadError(w, r, err)
+		return
+	}
+	if imp.columns, err = importHeader(header, imp.fields); err != nil {
+		helper.RespondError(w, r, apperror.BadRequest(err.Error()))
+		return
+	}
+
+	resp := types.TaskImportResponse{TeamID: teamID, Rows: []types.TaskImportRow{}}
+	var (
+		tasks []store.ImportedTask
+		// index into resp.Rows of each task
+		rowOf []int
+	)
+	for n := 1; ; n++ {
+		rec, err := cr.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			importReadError(w, r, err)
+			return
+		}
+		// rows of empty fields are skipped
+		if strings.TrimSpace(strings.Join(rec, "")) == "" {
+			continue
+		}
+		if len(resp.Rows) == maxImportRows {
+			helper.RespondError(w, r, apperror.BadRequest(fmt.Sprintf("at most %d rows per import", maxImportRows)))
+			return
+		}
+
+		t, err := imp.parseRow(rec)
+		row := types.TaskImportRow{Row: n, Title: t.Title, Status: types.TaskImportRejected}
+		if err != nil {
+			row.Error = err.Error()
+			resp.Rejected++
+		} else {
+			tasks = append(tasks, t)
+			rowOf = append(rowOf, len(resp.Rows))
+		}
+		resp.Rows = append(resp.Rows, row)
+	}
+	if len(resp.Rows) == 0 {
+		helper.RespondError(w, r, apperror.BadRequest("no tasks to import"))
+		return
+	}
+
+	var start *store.WorkflowStart
+	def, err := h.teamWorkflow(ctx, teamID)
+	if err != nil {
+		logger.Error(ctx, "import tasks: load team workflow failed", "err", err)
+		helper.RespondError(w, r, apperror.InternalError("internal error", err))
+		return
+	}
+	if def != nil {
+		initial, _ := def.State(def.Initial)
+		start = &store.WorkflowStart{State: initial.Key, Category: initial.Category}
+	}
+
+	created, err := h.taskStore.ImportTasks(ctx, teamID, userID, tasks, start, imp.now)
+	if err != nil {
+		if errors.Is(err, store.ErrEncryptionUnavailable) {
+			helper.RespondError(w, r, apperror.ServiceUnavailable("a confidential team needs the field encryption key, which is not configured"))
+			return
+		}
+		logger.Error(ctx, "import tasks: store import failed", "err", err)
+		helper.RespondError(w, r, apperror.InternalError("internal error", err))
+		return
+	}
+
+	for i, task := range created {
+		row := &resp.Rows[rowOf[i]]
+		row.Status = types.TaskImportCreated
+		row.TaskID = &task.ID
+		resp.Created++
+
+		h.events.Publish(ctx, events.Event{
+			Type:    events.TaskCreated,
+			TeamID:  task.TeamID,
+			TaskID:  task.ID,
+			ActorID: &userID,
+			Status:  string(task.Status),
+			At:      imp.now,
+		})
+	}
+
+	logger.Info(ctx, "tasks imported", "team_id", teamID, "user_id", userID,
+		"created", resp.Created, "rejected", resp.Rejected)
+	helper.RespondJSON(w, r, http.StatusOK, resp)
+}
+
+// importBody returns the CSV of the request: the body itself for text/csv,
+// or the "file" part of a multipart form, streamed without buffering.
+func importBody(w http.ResponseWriter, r *http.Request) (io.Reader, bool) {
+	mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	switch mediaType {
+	case "text/csv":
+		return r.Body, true
+	case "multipart/form-data":
+		mr, err := r.MultipartReader()
+		if err != nil {
+			helper.RespondError(w, r, apperror.BadRequest("invalid multipart body"))
+			return nil, false
+		}
+		for {
+			part, err := mr.NextPart()
+			if errors.Is(err, io.EOF) {
+				helper.RespondError(w, r, apperror.BadRequest(`the form field "file" is required`))
+				return nil, false
+			}
+			if err != nil {
+				importReadError(w, r, err)
+				return nil, false
+			}
+			if part.FormName() == "file" {
+				return part, true
+			}
+			_ = part.Close()
+		}
+	default:
+		helper.RespondError(w, r, apperror.UnsupportedMediaType("send text/csv or multipart/form-data"))
+		return nil, false
+	}
+}
+
+func importReadError(w http.ResponseWriter, r *http.Request, err error) {
+	var maxErr *http.MaxBytesError
+	switch {
+	case errors.As(err, &maxErr):
+		helper.RespondError(w, r, apperror.PayloadTooLarge(fmt.Sprintf("imports can be at most %d MB", maxImportBytes>>20)))
+	case errors.Is(err, io.EOF):
+		helper.RespondError(w, r, apperror.BadRequest("the csv is empty"))
+	default:
+		helper.RespondError(w, r, apperror.BadRequest(fmt.Sprintf("invalid csv: %v", err)))
+	}
+}
+
+// importHeader maps each column name to its index. A cf.<key> column must
+// name one of the team's custom fields.
+func importHeader(header []string, fields []customfieldstore.CustomField) (map[string]int, error) {
+	columns := make(map[string]int, len(header))
+	for i, f := range header {
+		name := strings.ToLower(strings.TrimSpace(f))
+		if i == 0 {
+			// a byte order mark left by spreadsheet exports
+			name = strings.TrimPrefix(name, "\ufeff")
+		}
+		if key, ok := strings.CutPrefix(name, importFieldPrefix); ok {
+			if !slices.ContainsFunc(fields, func(cf customfieldstore.CustomField) bool { return cf.Key == key }) {
+				return nil, fmt.Errorf("unknown custom field %q", key)
+			}
+		} else if !importColumns[name] {
+			return nil, fmt.Errorf("unknown column %q", f)
+		}
+		if _, dup := columns[name]; dup {
+			return nil, fmt.Errorf("column %q appears more than once", name)
+		}
+		columns[name] = i
+	}
+	for _, name := range []string{"title", "due_at"} {
+		if _, ok := columns[name]; !ok {
+			return nil, fmt.Errorf("the header row must have a %q column", name)
+		}
+	}
+	return columns, nil
+}
+
+// parseRow checks one row by the rules of POST /tasks. The title is set
+// even when the row is rejected, for the report.
+func (imp *taskImport) parseRow(rec []string) (store.ImportedTask, error) {
+	field := func(name string) string {
+		i, ok := imp.columns[name]
+		if !ok || i >= len(rec) {
+			return ""
+		}
+		return strings.TrimSpace(rec[i])
+	}
+
+	t := store.ImportedTask{
+		Title:      field("title"),
+		AssigneeID: imp.reporterID,
+		Priority:   types.TaskPriorityNormal,
+	}
+	if len(t.Title) < 1 || len(t.Title) > 100 {
+		return t, errors.New("title length must be between 1 and 100")
+	}
+	if d := field("description"); d != "" {
+		t.Description = &d
+	}
+
+	if email := strings.ToLower(field("assignee")); email != "" {
+		id, ok := imp.members[email]
+		if !ok {
+			return t, fmt.Errorf("assignee %s is not a member of the team", email)
+		}
+		t.AssigneeID = id
+	}
+
+	dueAt, err := parseImportTime(field("due_at"), imp.loc)
+	if err != nil {
+		return t, fmt.Errorf("due_at %v", err)
+	}
+	if dueAt.Before(imp.now.Add(8 * time.Hour)) {
+		return t, errors.New("due_at must be at least 8 hours from now")
+	}
+	if v := field("start_at"); v != "" {
+		startAt, err := parseImportTime(v, imp.loc)
+		if err != nil {
+			return t, fmt.Errorf("start_at %v", err)
+		}
+		if !startAt.Before(dueAt) {
+			return t, errors.New("start_at must be before due_at")
+		}
+		t.StartAt = &startAt
+	}
+	// a due date policy only moves due_at later, so start_at stays before it
+	t.DueAt, _ = dueDatePolicy(imp.cal, dueAt)
+
+	if v := field("priority"); v != "" {
+		t.Priority = store.TaskPriority(strings.ToLower(v))
+		if !labelrules.ValidPriority(t.Priority) {
+			return t, errors.New("priority must be low, normal, high or urgent")
+		}
+	}
+	if v := field("estimate_hours"); v != "" {
+		hours, err := strconv.ParseFloat(v, 64)
+		if err != nil || hours <= 0 || hours > store.MaxEstimateHours {
+			return t, fmt.Errorf("estimate_hours must be a number above 0 and at most %d", store.MaxEstimateHours)
+		}
+		t.EstimateHours = &hours
+	}
+
+	// cells are text; numbers are parsed here so CheckValues sees them as
+	// POST /tasks would, and an empty cell leaves the field unset
+	values := make(map[string]any)
+	for _, f := range imp.fields {
+		v := field(importFieldPrefix + f.Key)
+		if v == "" {
+			continue
+		}
+		values[f.Key] = v
+		if f.Type == types.CustomFieldNumber {
+			if n, err := strconv.ParseFloat(v, 64); err == nil && !math.IsNaN(n) && !math.IsInf(n, 0) {
+				values[f.Key] = n
+			}
+		}
+	}
+	cf, err := customfieldstore.CheckValues(imp.fields, values, false)
+	if err != nil {
+		return t, err
+	}
+	t.CustomFields = cf
+	return t, nil
+}
+
+// parseImportTime takes an RFC 3339 time, or a local time or YYYY-MM-DD
+// day in loc; a day starts at midnight.
+func parseImportTime(v string, loc *time.Location) (time.Time, error) {
+	if v == "" {
+		return time.Time{}, errors.New("is required")
+	}
+	if t, err := time.Parse(time.RFC3339, v); err == nil {
+		return t, nil
+	}
+	if _, err := time.Parse(time.DateOnly, v); err == nil {
+		v += " 00:00"
+	}
+	t, err := localtime.Parse(v, loc)
+	if err != nil {
+		return time.Time{}, errors.New("must be an RFC 3339 time, a YYYY-MM-DD HH:MM local time or a YYYY-MM-DD date")
+	}
+	return t, nil
+}
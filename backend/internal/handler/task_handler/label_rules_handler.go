@@ -0,0 +1,176 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/diagnosis/interactive-todo/api/types"
+	"github.com/diagnosis/interactive-todo/internal/apperror"
+	"github.com/diagnosis/interactive-todo/internal/deadline"
+	"github.com/diagnosis/interactive-todo/internal/helper"
+	"github.com/diagnosis/interactive-todo/internal/labelrules"
+	"github.com/diagnosis/interactive-todo/internal/logger"
+	middleware "github.com/diagnosis/interactive-todo/internal/middleware/auth"
+	store "github.com/diagnosis/interactive-todo/internal/store/tasks"
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+)
+
+const (
+	defaultDryRunLimit = 200
+	maxDryRunLimit     = 1000
+)
+
+func (h *TaskHandler) evaluateLabelRules(ctx context.Context, teamID uuid.UUID, title string, description *string) (labelrules.Outcome, error) {
+	rules, err := h.labelRuleStore.List(ctx, teamID)
+	if err != nil {
+		return labelrules.Outcome{}, fmt.Errorf("load label rules: %w", err)
+	}
+	return labelrules.Evaluate(rules, title, description), nil
+}
+
+// addRuleLabels attaches the labels an edit's rules ask for. Rules only
+// ever add; labels already on the task stay.
+func (h *TaskHandler) addRuleLabels(ctx context.Context, task *store.Task, outcome labelrules.Outcome, actorID uuid.UUID, now time.Time) error {
+	if len(outcome.Labels) == 0 {
+		return nil
+	}
+	labels, err := h.labelStore.Ensure(ctx, task.TeamID, outcome.Labels, now)
+	if err != nil {
+		return err
+	}
+	labelIDs := make([]uuid.UUID, 0, len(labels))
+	for _, l := range labels {
+		labelIDs = append(labelIDs, l.ID)
+	}
+	added, err := h.labelStore.Attach(ctx, task.ID, labelIDs, now)
+	if err != nil {
+		return err
+	}
+	h.publishLabelsAdded(ctx, task, labels, added, &actorID, now)
+	return nil
+}
+
+// DryRunLabelRules previews what the team's label rules, or a draft rule,
+// would do, without changing anything. Team owners/admins only, since they
+// are the ones writing rules.
+func (h *TaskHandler) DryRunLabelRules(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := deadline.Budget(r.Context(), 10*time.Second)
+	defer cancel()
+
+	userID, ok := middleware.GetUserIDFromContext(ctx)
+	if !ok {
+		helper.RespondError(w, r, apperror.Unauthorized("authentication required"))
+		return
+	}
+
+	teamID, err := uuid.Parse(chi.URLParam(r, "team_id"))
+	if err != nil {
+		helper.RespondError(w, r, apperror.BadRequest("invalid team id"))
+		return
+	}
+
+	isOwnerOrAdmin, err := h.teamStore.IsOwnerOrAdmin(ctx, teamID, userID)
+	if err != nil {
+		logger.Error(ctx, "label rules dry run: role check failed", "err", err)
+		helper.RespondError(w, r, apperror.InternalError("internal error", err))
+		return
+	}
+	if !isOwnerOrAdmin {
+		helper.RespondError(w, r, apperror.Forbidden("only team owner/admin can preview label rules"))
+		return
+	}
+
+	r.Body = http.MaxBytesReader(w, r.Body, 1<<20)
+	defer r.Body.Close()
+
+	var in types.LabelRuleDryRunRequest
+	dec := json.NewDecoder(r.Body)
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&in); err != nil {
+		logger.Error(ctx, "label rules dry run: bad json", "err", err)
+		helper.RespondError(w, r, apperror.BadRequest("invalid request body"))
+		return
+	}
+	if in.Title == nil && in.Description != nil {
+		helper.RespondError(w, r, apperror.BadRequest("description needs a title"))
+		return
+	}
+	if in.Limit == 0 {
+		in.Limit = defaultDryRunLimit
+	}
+	if in.Limit < 1 || in.Limit > maxDryRunLimit {
+		helper.RespondError(w, r, apperror.BadRequest(fmt.Sprintf("limit must be between 1 and %d", maxDryRunLimit)))
+		return
+	}
+
+	var rules []labelrules.Rule
+	if in.Rule != nil {
+		if err := labelrules.Normalize(in.Rule); err != nil {
+			helper.RespondError(w, r, apperror.BadRequest(err.Error()))
+			return
+		}
+		rules = []labelrules.Rule{{
+			Name:        in.Rule.Name,
+			Field:       in.Rule.Field,
+			Contains:    in.Rule.Contains,
+			AddLabels:   in.Rule.AddLabels,
+			SetPriority: in.Rule.SetPriority,
+			Enabled:     true,
+		}}
+	} else {
+		if rules, err = h.labelRuleStore.List(ctx, teamID); err != nil {
+			logger.Error(ctx, "label rules dry run: list rules failed", "err", err)
+			helper.RespondError(w, r, apperror.InternalError("internal error", err))
+			return
+		}
+	}
+
+	out := types.LabelRuleDryRunResponse{TeamID: teamID, Matches: []types.LabelRuleMatch{}}
+	match := func(taskID *uuid.UUID, title string, description *string) {
+		o := labelrules.Evaluate(rules, title, description)
+		if !o.Matched() {
+			return
+		}
+		if o.Labels == nil {
+			o.Labels = []string{}
+		}
+		out.Matches = append(out.Matches, types.LabelRuleMatch{
+			TaskID:    taskID,
+			Title:     title,
+			Rules:     o.Rules,
+			AddLabels: o.Labels,
+			Priority:  o.Priority,
+		})
+	}
+
+	if in.Title != nil {
+		out.Scanned = 1
+		match(nil, strings.TrimSpace(*in.Title), in.Description)
+		helper.RespondJSON(w, r, http.StatusOK, out)
+		return
+	}
+
+	tasks, _, err := h.taskStore.ListTeamTasks(ctx, teamID, userID, store.TaskFilter{}, store.SortSpec{}, store.Page{Limit: in.Limit})
+	if err != nil {
+		if errors.Is(err, store.ErrInvalidInput) {
+			helper.RespondError(w, r, apperror.BadRequest(err.Error()))
+			return
+		}
+		logger.Error(ctx, "label rules dry run: list tasks failed", "err", err)
+		helper.RespondError(w, r, apperror.InternalError("internal error", err))
+		return
+	}
+	for i := range tasks {
+		match(&tasks[i].ID, tasks[i].Title, tasks[i].Description)
+	}
+	out.Scanned = len(tasks)
+
+	logger.Info(ctx, "label rules dry run", "team_id", teamID, "scanned", out.Scanned, "matched", len(out.Matches))
+	helper.RespondJSON(w, r, http.StatusOK, out)
+}
@@ -0,0 +1,208 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/diagnosis/interactive-todo/api/types"
+	"github.com/diagnosis/interactive-todo/internal/apperror"
+	"github.com/diagnosis/interactive-todo/internal/deadline"
+	"github.com/diagnosis/interactive-todo/internal/helper"
+	"github.com/diagnosis/interactive-todo/internal/logger"
+	middleware "github.com/diagnosis/interactive-todo/internal/middleware/auth"
+	labelstore "github.com/diagnosis/interactive-todo/internal/store/labels"
+	store "github.com/diagnosis/interactive-todo/internal/store/tasks"
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+)
+
+// withLabels fills in the labels of tasks with one query.
+func (h *TaskHandler) withLabels(ctx context.Context, tasks ...*store.Task) error {
+	ids := make([]uuid.UUID, len(tasks))
+	for i, t := range tasks {
+		ids[i] = t.ID
+	}
+	byTask, err := h.labelStore.ListForTasks(ctx, ids)
+	if err != nil {
+		return fmt.Errorf("load task labels: %w", err)
+	}
+	for _, t := range tasks {
+		t.Labels = byTask[t.ID]
+	}
+	return nil
+}
+
+// AddTaskLabels puts labels on a task by name, creating the ones the team
+// does not have yet. Labels already on the task are left alone.
+func (h *TaskHandler) AddTaskLabels(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := deadline.Budget(r.Context(), 5*time.Second)
+	defer cancel()
+
+	r.Body = http.MaxBytesReader(w, r.Body, 1<<20)
+	defer r.Body.Close()
+
+	var in types.TaskLabelsRequest
+	dec := json.NewDecoder(r.Body)
+	dec.DisallowUnknownFields()
+
+	if err := dec.Decode(&in); err != nil {
+		logger.Error(ctx, "add task labels: bad json", "err", err)
+		helper.RespondError(w, r, apperror.BadRequest("invalid request body"))
+		return
+	}
+	names := labelstore.NormalizeNames(in.Labels)
+	if len(names) == 0 {
+		helper.RespondError(w, r, apperror.BadRequest("labels is required"))
+		return
+	}
+	for _, n := range names {
+		if len(n) > labelstore.MaxNameLength {
+			helper.RespondError(w, r, apperror.BadRequest(fmt.Sprintf("labels must be at most %d chars", labelstore.MaxNameLength)))
+			return
+		}
+	}
+
+	task, userID, ok := h.labelTask(ctx, w, r, "add task labels")
+	if !ok {
+		return
+	}
+
+	current, err := h.labelStore.ListForTask(ctx, task.ID)
+	if err != nil {
+		logger.Error(ctx, "add task labels: list labels failed", "err", err)
+		helper.RespondError(w, r, apperror.InternalError("internal error", err))
+		return
+	}
+	onTask := make(map[string]bool, len(current))
+	for _, l := range current {
+		onTask[strings.ToLower(l.Name)] = true
+	}
+	count := len(current)
+	for _, n := range names {
+		if !onTask[strings.ToLower(n)] {
+			count++
+		}
+	}
+	if count > maxTaskLabels {
+		helper.RespondError(w, r, apperror.BadRequest(fmt.Sprintf("a task can have at most %d labels", maxTaskLabels)))
+		return
+	}
+
+	now := time.Now().UTC()
+	labels, err := h.labelStore.Ensure(ctx, task.TeamID, names, now)
+	if err != nil {
+		logger.Error(ctx, "add task labels: ensure labels failed", "err", err)
+		helper.RespondError(w, r, apperror.InternalError("internal error", err))
+		return
+	}
+	labelIDs := make([]uuid.UUID, 0, len(labels))
+	for _, l := range labels {
+		labelIDs = append(labelIDs, l.ID)
+	}
+	added, err := h.labelStore.Attach(ctx, task.ID, labelIDs, now)
+	if err != nil {
+		logger.Error(ctx, "add task labels: attach failed", "err", err)
+		helper.RespondError(w, r, apperror.InternalError("internal error", err))
+		return
+	}
+	h.publishLabelsAdded(ctx, task, labels, added, &userID, now)
+
+	logger.Info(ctx, "task labels added", "task_id", task.ID, "user_id", userID, "added", len(added))
+	h.respondTaskLabels(ctx, w, r, "add task labels", task.ID)
+}
+
+// RemoveTaskLabel takes one label off a task. The label stays in the team.
+func (h *TaskHandler) RemoveTaskLabel(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := deadline.Budget(r.Context(), 5*time.Second)
+	defer cancel()
+
+	labelID, err := uuid.Parse(chi.URLParam(r, "label_id"))
+	if err != nil {
+		helper.RespondError(w, r, apperror.BadRequest("invalid label id"))
+		return
+	}
+
+	task, userID, ok := h.labelTask(ctx, w, r, "remove task label")
+	if !ok {
+		return
+	}
+
+	removed, err := h.labelStore.Detach(ctx, task.ID, labelID)
+	if err != nil {
+		logger.Error(ctx, "remove task label: detach failed", "err", err)
+		helper.RespondError(w, r, apperror.InternalError("internal error", err))
+		return
+	}
+	if !removed {
+		helper.RespondError(w, r, apperror.NotFound("label is not on this task"))
+		return
+	}
+
+	logger.Info(ctx, "task label removed", "task_id", task.ID, "label_id", labelID, "user_id", userID)
+	h.respondTaskLabels(ctx, w, r, "remove task label", task.ID)
+}
+
+// labelTask loads the task of a label change. The reporter, the assignee
+// and team owners/admins may change a task's labels.
+func (h *TaskHandler) labelTask(ctx context.Context, w http.ResponseWriter, r *http.Request, op string) (*store.Task, uuid.UUID, bool) {
+	return h.taskToChange(ctx, w, r, op, "change task labels")
+}
+
+// taskToChange loads the task named in the URL for a change only its
+// reporter, its assignee and team owners/admins may make; what completes
+// the 403 message.
+func (h *TaskHandler) taskToChange(ctx context.Context, w http.ResponseWriter, r *http.Request, op, what string) (*store.Task, uuid.UUID, bool) {
+	userID, ok := middleware.GetUserIDFromContext(ctx)
+	if !ok {
+		helper.RespondError(w, r, apperror.Unauthorized("unauthorized"))
+		return nil, uuid.Nil, false
+	}
+
+	taskID, err := parseTaskID(r)
+	if err != nil {
+		helper.RespondError(w, r, apperror.BadRequest("invalid task id"))
+		return nil, uuid.Nil, false
+	}
+
+	task, err := h.getTaskByID(ctx, taskID, userID)
+	if err != nil {
+		if errors.Is(err, store.ErrTaskNotFound) {
+			helper.RespondError(w, r, apperror.NotFound("task not found"))
+			return nil, uuid.Nil, false
+		}
+		logger.Error(ctx, op+": failed to get task", "err", err)
+		helper.RespondError(w, r, apperror.InternalError("internal error", err))
+		return nil, uuid.Nil, false
+	}
+
+	if userID == task.ReporterID || userID == task.AssigneeID {
+		return task, userID, true
+	}
+	isAdmin, err := h.teamStore.IsOwnerOrAdmin(ctx, task.TeamID, userID)
+	if err != nil {
+		logger.Error(ctx, op+": role check failed", "err", err)
+		helper.RespondError(w, r, apperror.InternalError("internal error", err))
+		return nil, uuid.Nil, false
+	}
+	if !isAdmin {
+		logger.Info(ctx, op+": forbidden", "user_id", userID, "task_id", task.ID)
+		helper.RespondError(w, r, apperror.Forbidden("only the reporter, the assignee or team owner/admin can "+what))
+		return nil, uuid.Nil, false
+	}
+	return task, userID, true
+}
+
+func (h *TaskHandler) respondTaskLabels(ctx context.Context, w http.ResponseWriter, r *http.Request, op string, taskID uuid.UUID) {
+	labels, err := h.labelStore.ListForTask(ctx, taskID)
+	if err != nil {
+		logger.Error(ctx, op+": list labels failed", "err", err)
+		helper.RespondError(w, r, apperror.InternalError("internal error", err))
+		return
+	}
+	helper.RespondJSON(w, r, http.StatusOK, types.TaskLabelsResponse{TaskID: taskID, Labels: labels})
+}
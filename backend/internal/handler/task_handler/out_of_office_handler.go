@@ -0,0 +1,219 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/diagnosis/interactive-todo/api/types"
+	"github.com/diagnosis/interactive-todo/internal/apperror"
+	"github.com/diagnosis/interactive-todo/internal/deadline"
+	"github.com/diagnosis/interactive-todo/internal/helper"
+	"github.com/diagnosis/interactive-todo/internal/logger"
+	middleware "github.com/diagnosis/interactive-todo/internal/middleware/auth"
+	outofficestore "github.com/diagnosis/interactive-todo/internal/store/outofoffice"
+	"github.com/google/uuid"
+)
+
+// Out of office: a user marks when they are away and who covers for them.
+// Assigning them a task in that window is refused until the caller
+// confirms, and the refusal suggests the backup.
+
+const maxOutOfOfficeNoteLen = 500
+
+// suggestedAssigneeHeader carries the backup on a refused assignment, so
+// clients can offer it without parsing the message.
+const suggestedAssigneeHeader = "X-Suggested-Assignee"
+
+// GetOutOfOffice returns the caller's absence, if it has not ended.
+func (h *TaskHandler) GetOutOfOffice(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := deadline.Budget(r.Context(), 5*time.Second)
+	defer cancel()
+
+	userID, ok := middleware.GetUserIDFromContext(ctx)
+	if !ok {
+		helper.RespondError(w, r, apperror.Unauthorized("authentication required"))
+		return
+	}
+
+	o, err := h.outOfOfficeStore.Get(ctx, userID, time.Now().UTC())
+	if err != nil {
+		if errors.Is(err, outofficestore.ErrOutOfOfficeNotFound) {
+			helper.RespondError(w, r, apperror.NotFound("not out of office"))
+			return
+		}
+		logger.Error(ctx, "get out of office: store failed", "err", err)
+		helper.RespondError(w, r, apperror.InternalError("internal error", err))
+		return
+	}
+	helper.RespondJSON(w, r, http.StatusOK, o)
+}
+
+// SetOutOfOffice sets the caller's absence, replacing the one they had.
+func (h *TaskHandler) SetOutOfOffice(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := deadline.Budget(r.Context(), 5*time.Second)
+	defer cancel()
+
+	userID, ok := middleware.GetUserIDFromContext(ctx)
+	if !ok {
+		helper.RespondError(w, r, apperror.Unauthorized("authentication required"))
+		return
+	}
+
+	r.Body = http.MaxBytesReader(w, r.Body, 1<<20)
+	defer r.Body.Close()
+
+	var in types.SetOutOfOfficeRequest
+	dec := json.NewDecoder(r.Body)
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&in); err != nil {
+		logger.Error(ctx, "set out of office: bad json", "err", err)
+		helper.RespondError(w, r, apperror.BadRequest("invalid request body"))
+		return
+	}
+
+	now := time.Now().UTC()
+	startsAt := now
+	if in.StartsAt != nil && in.StartsAt.After(now) {
+		startsAt = in.StartsAt.UTC()
+	}
+	if in.BackupID != nil && *in.BackupID == uuid.Nil {
+		in.BackupID = nil
+	}
+	switch {
+	case in.EndsAt.IsZero():
+		helper.RespondError(w, r, apperror.BadRequest("ends_at is required"))
+		return
+	case !in.EndsAt.After(startsAt):
+		helper.RespondError(w, r, apperror.BadRequest("ends_at must be after starts_at and in the future"))
+		return
+	case in.EndsAt.Sub(startsAt) > outofficestore.MaxDuration:
+		helper.RespondError(w, r, apperror.BadRequest("an absence can last at most 365 days"))
+		return
+	case in.BackupID != nil && *in.BackupID == userID:
+		helper.RespondError(w, r, apperror.BadRequest("cannot be your own backup"))
+		return
+	}
+	if in.Note != nil {
+		note := strings.TrimSpace(*in.Note)
+		if utf8.RuneCountInString(note) > maxOutOfOfficeNoteLen {
+			helper.RespondError(w, r, apperror.BadRequest("note is too long (max 500 chars)"))
+			return
+		}
+		in.Note = &note
+		if note == "" {
+			in.Note = nil
+		}
+	}
+
+	o, err := h.outOfOfficeStore.Set(ctx, outofficestore.OutOfOffice{
+		UserID:   userID,
+		StartsAt: startsAt,
+		EndsAt:   in.EndsAt.UTC(),
+		BackupID: in.BackupID,
+		Note:     in.Note,
+	}, now)
+	if err != nil {
+		if errors.Is(err, outofficestore.ErrBackupNotFound) {
+			helper.RespondError(w, r, apperror.BadRequest("backup user not found"))
+			return
+		}
+		logger.Error(ctx, "set out of office: store failed", "err", err)
+		helper.RespondError(w, r, apperror.InternalError("internal error", err))
+		return
+	}
+
+	logger.Info(ctx, "out of office set", "user_id", userID, "starts_at", o.StartsAt, "ends_at", o.EndsAt)
+	helper.RespondJSON(w, r, http.StatusOK, o)
+}
+
+// ClearOutOfOffice ends the caller's absence early.
+func (h *TaskHandler) ClearOutOfOffice(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := deadline.Budget(r.Context(), 5*time.Second)
+	defer cancel()
+
+	userID, ok := middleware.GetUserIDFromContext(ctx)
+	if !ok {
+		helper.RespondError(w, r, apperror.Unauthorized("authentication required"))
+		return
+	}
+
+	cleared, err := h.outOfOfficeStore.Clear(ctx, userID)
+	if err != nil {
+		logger.Error(ctx, "clear out of office: store failed", "err", err)
+		helper.RespondError(w, r, apperror.InternalError("internal error", err))
+		return
+	}
+	if !cleared {
+		helper.RespondError(w, r, apperror.NotFound("not out of office"))
+		return
+	}
+
+	logger.Info(ctx, "out of office cleared", "user_id", userID)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// checkOutOfOffice stops an assignment to someone who is out of office,
+// unless the caller confirmed it or is assigning themselves. The refusal
+// names the assignee's backup when the backup is in the team and not out
+// too. It reports whether the assignment may go ahead, having written the
+// response when not.
+func (h *TaskHandler) checkOutOfOffice(
+	ctx context.Context,
+	w http.ResponseWriter,
+	r *http.Request,
+	op string,
+	teamID, callerID, assigneeID uuid.UUID,
+	confirmed bool,
+	now time.Time,
+) bool {
+	if confirmed || assigneeID == callerID {
+		return true
+	}
+	o, err := h.outOfOfficeStore.GetActive(ctx, assigneeID, now)
+	if err != nil {
+		if errors.Is(err, outofficestore.ErrOutOfOfficeNotFound) {
+			return true
+		}
+		logger.Error(ctx, op+": out of office check failed", "err", err)
+		helper.RespondError(w, r, apperror.InternalError("internal error", err))
+		return false
+	}
+
+	msg := "assignee is out of office until " + o.EndsAt.UTC().Format(time.RFC3339)
+	if backupID, ok := h.availableBackup(ctx, teamID, o, now); ok {
+		w.Header().Set(suggestedAssigneeHeader, backupID.String())
+		msg += "; their backup " + backupID.String() + " is available"
+	}
+	logger.Info(ctx, op+": assignee out of office", "assignee_id", assigneeID, "ends_at", o.EndsAt)
+	helper.RespondError(w, r, apperror.Conflict(msg+"; set allow_out_of_office to assign anyway"))
+	return false
+}
+
+// availableBackup returns the backup of o if they can take the task in the
+// team. Failures only cost the suggestion.
+func (h *TaskHandler) availableBackup(ctx context.Context, teamID uuid.UUID, o *outofficestore.OutOfOffice, now time.Time) (uuid.UUID, bool) {
+	if o.BackupID == nil {
+		return uuid.Nil, false
+	}
+	backupID := *o.BackupID
+	isMember, err := h.teamStore.IsMember(ctx, teamID, backupID)
+	if err != nil {
+		logger.Warn(ctx, "out of office: backup membership check failed", "err", err)
+		return uuid.Nil, false
+	}
+	if !isMember {
+		return uuid.Nil, false
+	}
+	if _, err := h.outOfOfficeStore.GetActive(ctx, backupID, now); !errors.Is(err, outofficestore.ErrOutOfOfficeNotFound) {
+		if err != nil {
+			logger.Warn(ctx, "out of office: backup check failed", "err", err)
+		}
+		return uuid.Nil, false
+	}
+	return backupID, true
+}
@@ -0,0 +1,95 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/diagnosis/interactive-todo/api/types"
+	"github.com/diagnosis/interactive-todo/internal/apperror"
+	"github.com/diagnosis/interactive-todo/internal/deadline"
+	"github.com/diagnosis/interactive-todo/internal/helper"
+	"github.com/diagnosis/interactive-todo/internal/logger"
+	middleware "github.com/diagnosis/interactive-todo/internal/middleware/auth"
+	store "github.com/diagnosis/interactive-todo/internal/store/tasks"
+)
+
+// MoveTask reorders a card within its status column on the board: the
+// task goes between after_id and before_id. Any team member who can see
+// the task can move it. Moving to another column is a status change.
+func (h *TaskHandler) MoveTask(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := deadline.Budget(r.Context(), 5*time.Second)
+	defer cancel()
+
+	userID, ok := middleware.GetUserIDFromContext(ctx)
+	if !ok {
+		helper.RespondError(w, r, apperror.Unauthorized("unauthorized"))
+		return
+	}
+
+	taskID, err := parseTaskID(r)
+	if err != nil {
+		helper.RespondError(w, r, apperror.BadRequest("invalid task id"))
+		return
+	}
+
+	r.Body = http.MaxBytesReader(w, r.Body, 1<<20)
+	defer r.Body.Close()
+
+	var in types.MoveTaskRequest
+	dec := json.NewDecoder(r.Body)
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&in); err != nil {
+		logger.Error(ctx, "move task: bad json", "err", err)
+		helper.RespondError(w, r, apperror.BadRequest("invalid request body"))
+		return
+	}
+	if in.AfterID == nil && in.BeforeID == nil {
+		helper.RespondError(w, r, apperror.BadRequest("after_id or before_id is required"))
+		return
+	}
+
+	task, err := h.getTaskByID(ctx, taskID, userID)
+	if err != nil {
+		if errors.Is(err, store.ErrTaskNotFound) {
+			helper.RespondError(w, r, apperror.NotFound("task not found"))
+			return
+		}
+		logger.Error(ctx, "move task: failed to get task", "err", err)
+		helper.RespondError(w, r, apperror.InternalError("internal error", err))
+		return
+	}
+
+	isMember, err := h.teamStore.IsMember(ctx, task.TeamID, userID)
+	if err != nil {
+		logger.Error(ctx, "move task: membership check failed", "err", err)
+		helper.RespondError(w, r, apperror.InternalError("internal error", err))
+		return
+	}
+	if !isMember {
+		helper.RespondError(w, r, apperror.Forbidden("only team members can move tasks"))
+		return
+	}
+
+	moved, err := h.taskStore.MoveTask(ctx, taskID, in.AfterID, in.BeforeID)
+	if err != nil {
+		switch {
+		case errors.Is(err, store.ErrInvalidInput):
+			helper.RespondError(w, r, apperror.BadRequest(err.Error()))
+		case errors.Is(err, store.ErrTaskNotFound):
+			helper.RespondError(w, r, apperror.NotFound("task not found"))
+		case errors.Is(err, store.ErrVersionConflict):
+			helper.RespondError(w, r, apperror.Conflict(err.Error()))
+		default:
+			logger.Error(ctx, "move task: store error", "err", err)
+			helper.RespondError(w, r, apperror.InternalError("internal error", err))
+		}
+		return
+	}
+
+	logger.Info(ctx, "task moved", "task_id", taskID, "user_id", userID, "position", moved.Position)
+	setTaskETag(w, moved)
+	h.localize(ctx, moved)
+	helper.RespondJSON(w, r, http.StatusOK, moved)
+}
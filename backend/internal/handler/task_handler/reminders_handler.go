@@ -0,0 +1,211 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/diagnosis/interactive-todo/api/types"
+	"github.com/diagnosis/interactive-todo/internal/apperror"
+	"github.com/diagnosis/interactive-todo/internal/deadline"
+	"github.com/diagnosis/interactive-todo/internal/helper"
+	"github.com/diagnosis/interactive-todo/internal/logger"
+	middleware "github.com/diagnosis/interactive-todo/internal/middleware/auth"
+	reminderstore "github.com/diagnosis/interactive-todo/internal/store/reminders"
+	store "github.com/diagnosis/interactive-todo/internal/store/tasks"
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+)
+
+// ListTaskReminders returns a task's reminders, to team members who can
+// see the task.
+func (h *TaskHandler) ListTaskReminders(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := deadline.Budget(r.Context(), 5*time.Second)
+	defer cancel()
+
+	userID, ok := middleware.GetUserIDFromContext(ctx)
+	if !ok {
+		helper.RespondError(w, r, apperror.Unauthorized("unauthorized"))
+		return
+	}
+
+	taskID, err := parseTaskID(r)
+	if err != nil {
+		helper.RespondError(w, r, apperror.BadRequest("invalid task id"))
+		return
+	}
+
+	if _, ok := h.memberTask(ctx, w, r, taskID, userID, "list task reminders", "forbidden"); !ok {
+		return
+	}
+
+	reminders, err := h.reminderStore.List(ctx, taskID)
+	if err != nil {
+		logger.Error(ctx, "list task reminders: store query failed", "err", err)
+		helper.RespondError(w, r, apperror.InternalError("internal error", err))
+		return
+	}
+
+	h.localizeReminders(ctx, userID, reminders)
+	helper.RespondJSON(w, r, http.StatusOK, types.TaskReminderListResponse{TaskID: taskID, Reminders: reminders})
+}
+
+// CreateTaskReminder adds a reminder offset_minutes before the task's due
+// date. The task's creator, their delegates and the assignee can manage
+// its reminders.
+func (h *TaskHandler) CreateTaskReminder(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := deadline.Budget(r.Context(), 5*time.Second)
+	defer cancel()
+
+	userID, ok := middleware.GetUserIDFromContext(ctx)
+	if !ok {
+		helper.RespondError(w, r, apperror.Unauthorized("unauthorized"))
+		return
+	}
+
+	taskID, err := parseTaskID(r)
+	if err != nil {
+		helper.RespondError(w, r, apperror.BadRequest("invalid task id"))
+		return
+	}
+
+	now := time.Now().UTC()
+	task, ok := h.reminderTask(ctx, w, r, taskID, userID, "create task reminder", now)
+	if !ok {
+		return
+	}
+
+	r.Body = http.MaxBytesReader(w, r.Body, 1<<20)
+	defer r.Body.Close()
+
+	var in types.CreateReminderRequest
+	dec := json.NewDecoder(r.Body)
+	dec.DisallowUnknownFields()
+
+	if err := dec.Decode(&in); err != nil {
+		logger.Error(ctx, "create task reminder: bad json", "err", err)
+		helper.RespondError(w, r, apperror.BadRequest("invalid request body"))
+		return
+	}
+	if in.OffsetMinutes < 0 || in.OffsetMinutes > reminderstore.MaxOffsetMinutes {
+		helper.RespondError(w, r, apperror.BadRequest(fmt.Sprintf("offset_minutes must be between 0 and %d", reminderstore.MaxOffsetMinutes)))
+		return
+	}
+	if in.Channel == "" {
+		in.Channel = types.ReminderEmail
+	}
+	if in.Channel != types.ReminderEmail && in.Channel != types.ReminderInApp {
+		helper.RespondError(w, r, apperror.BadRequest("channel must be email or in_app"))
+		return
+	}
+
+	existing, err := h.reminderStore.List(ctx, taskID)
+	if err != nil {
+		logger.Error(ctx, "create task reminder: store query failed", "err", err)
+		helper.RespondError(w, r, apperror.InternalError("internal error", err))
+		return
+	}
+	if len(existing) >= reminderstore.MaxPerTask {
+		helper.RespondError(w, r, apperror.BadRequest(fmt.Sprintf("a task can have at most %d reminders", reminderstore.MaxPerTask)))
+		return
+	}
+
+	reminder, err := h.reminderStore.Create(ctx, taskID, in.OffsetMinutes, in.Channel, &userID, now)
+	if err != nil {
+		if errors.Is(err, reminderstore.ErrReminderExists) {
+			helper.RespondError(w, r, apperror.Conflict("task already has a reminder with this offset and channel"))
+			return
+		}
+		logger.Error(ctx, "create task reminder: store error", "err", err)
+		helper.RespondError(w, r, apperror.InternalError("internal error", err))
+		return
+	}
+
+	logger.Info(ctx, "task reminder created",
+		"task_id", task.ID,
+		"reminder_id", reminder.ID,
+		"offset_minutes", reminder.OffsetMinutes,
+		"channel", reminder.Channel,
+		"user_id", userID,
+	)
+	reminder.RemindAt = reminder.RemindAt.In(h.userLocation(ctx, userID))
+	helper.RespondJSON(w, r, http.StatusCreated, reminder)
+}
+
+// DeleteTaskReminder removes one of a task's reminders.
+func (h *TaskHandler) DeleteTaskReminder(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := deadline.Budget(r.Context(), 5*time.Second)
+	defer cancel()
+
+	userID, ok := middleware.GetUserIDFromContext(ctx)
+	if !ok {
+		helper.RespondError(w, r, apperror.Unauthorized("unauthorized"))
+		return
+	}
+
+	taskID, err := parseTaskID(r)
+	if err != nil {
+		helper.RespondError(w, r, apperror.BadRequest("invalid task id"))
+		return
+	}
+	reminderID, err := uuid.Parse(chi.URLParam(r, "reminder_id"))
+	if err != nil {
+		helper.RespondError(w, r, apperror.BadRequest("invalid reminder id"))
+		return
+	}
+
+	if _, ok := h.reminderTask(ctx, w, r, taskID, userID, "delete task reminder", time.Now().UTC()); !ok {
+		return
+	}
+
+	if err := h.reminderStore.Delete(ctx, taskID, reminderID); err != nil {
+		if errors.Is(err, reminderstore.ErrReminderNotFound) {
+			helper.RespondError(w, r, apperror.NotFound("reminder not found"))
+			return
+		}
+		logger.Error(ctx, "delete task reminder: store error", "err", err)
+		helper.RespondError(w, r, apperror.InternalError("internal error", err))
+		return
+	}
+
+	logger.Info(ctx, "task reminder deleted", "task_id", taskID, "reminder_id", reminderID, "user_id", userID)
+	helper.RespondMessage(w, r, http.StatusOK, "reminder deleted")
+}
+
+// reminderTask loads a task the caller may manage reminders on, writing
+// the error response and returning false otherwise.
+func (h *TaskHandler) reminderTask(ctx context.Context, w http.ResponseWriter, r *http.Request, taskID, userID uuid.UUID, op string, now time.Time) (*store.Task, bool) {
+	task, ok := h.memberTask(ctx, w, r, taskID, userID, op, "forbidden")
+	if !ok {
+		return nil, false
+	}
+	if userID == task.AssigneeID {
+		return task, true
+	}
+	allowed, _, err := h.actsAsReporter(ctx, task, userID, now)
+	if err != nil {
+		logger.Error(ctx, op+": delegation check failed", "err", err)
+		helper.RespondError(w, r, apperror.InternalError("internal error", err))
+		return nil, false
+	}
+	if !allowed {
+		helper.RespondError(w, r, apperror.Forbidden("only the task's creator or assignee can manage its reminders"))
+		return nil, false
+	}
+	return task, true
+}
+
+// localizeReminders writes remind_at in the caller's timezone, like the
+// task's own dates.
+func (h *TaskHandler) localizeReminders(ctx context.Context, userID uuid.UUID, reminders []types.TaskReminder) {
+	if len(reminders) == 0 {
+		return
+	}
+	loc := h.userLocation(ctx, userID)
+	for i := range reminders {
+		reminders[i].RemindAt = reminders[i].RemindAt.In(loc)
+	}
+}
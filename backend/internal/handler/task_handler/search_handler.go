@@ -0,0 +1,90 @@
+package handler
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/diagnosis/interactive-todo/api/types"
+	"github.com/diagnosis/interactive-todo/internal/apperror"
+	"github.com/diagnosis/interactive-todo/internal/deadline"
+	"github.com/diagnosis/interactive-todo/internal/helper"
+	"github.com/diagnosis/interactive-todo/internal/logger"
+	middleware "github.com/diagnosis/interactive-todo/internal/middleware/auth"
+	store "github.com/diagnosis/interactive-todo/internal/store/tasks"
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+)
+
+const (
+	defaultSearchLimit = 20
+	maxSearchLimit     = 100
+	maxSearchQuery     = 200
+)
+
+// SearchTeamTasks finds the team's tasks containing the words of ?q=,
+// most relevant first. Unlike /tasks/similar it needs no embedding
+// provider and works for confidential teams, on titles only.
+func (h *TaskHandler) SearchTeamTasks(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := deadline.Budget(r.Context(), 5*time.Second)
+	defer cancel()
+
+	userID, ok := middleware.GetUserIDFromContext(ctx)
+	if !ok {
+		helper.RespondError(w, r, apperror.Unauthorized("authentication required"))
+		return
+	}
+
+	teamID, err := uuid.Parse(chi.URLParam(r, "team_id"))
+	if err != nil {
+		helper.RespondError(w, r, apperror.BadRequest("invalid team id"))
+		return
+	}
+
+	query := strings.TrimSpace(r.URL.Query().Get("q"))
+	if query == "" || len(query) > maxSearchQuery {
+		helper.RespondError(w, r, apperror.BadRequest(fmt.Sprintf("q is required (max %d chars)", maxSearchQuery)))
+		return
+	}
+	limit := defaultSearchLimit
+	if v := r.URL.Query().Get("limit"); v != "" {
+		limit, err = strconv.Atoi(v)
+		if err != nil || limit < 1 || limit > maxSearchLimit {
+			helper.RespondError(w, r, apperror.BadRequest(fmt.Sprintf("limit must be between 1 and %d", maxSearchLimit)))
+			return
+		}
+	}
+
+	isMember, err := h.teamStore.IsMember(ctx, teamID, userID)
+	if err != nil {
+		logger.Error(ctx, "search tasks: membership check failed", "err", err)
+		helper.RespondError(w, r, apperror.InternalError("internal error", err))
+		return
+	}
+	if !isMember {
+		helper.RespondError(w, r, apperror.Forbidden("only team members can search team tasks"))
+		return
+	}
+
+	tasks, err := h.taskStore.SearchInTeam(ctx, teamID, userID, query, limit)
+	if err != nil {
+		logger.Error(ctx, "search tasks: store query failed", "err", err)
+		helper.RespondError(w, r, apperror.InternalError("internal error", err))
+		return
+	}
+	refs := make([]*store.Task, len(tasks))
+	for i := range tasks {
+		refs[i] = &tasks[i].Task
+	}
+	if err := h.withLabels(ctx, refs...); err != nil {
+		logger.Error(ctx, "search tasks: load labels failed", "err", err)
+		helper.RespondError(w, r, apperror.InternalError("internal error", err))
+		return
+	}
+	h.localize(ctx, refs...)
+
+	logger.Info(ctx, "search tasks: success", "user_id", userID, "team_id", teamID, "results", len(tasks))
+	helper.RespondJSON(w, r, http.StatusOK, types.TaskSearchResponse{TeamID: teamID, Query: query, Tasks: tasks})
+}
@@ -0,0 +1,143 @@
+package handler
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/diagnosis/interactive-todo/api/types"
+	"github.com/diagnosis/interactive-todo/internal/apperror"
+	"github.com/diagnosis/interactive-todo/internal/deadline"
+	"github.com/diagnosis/interactive-todo/internal/embedding"
+	"github.com/diagnosis/interactive-todo/internal/helper"
+	"github.com/diagnosis/interactive-todo/internal/logger"
+	middleware "github.com/diagnosis/interactive-todo/internal/middleware/auth"
+	store "github.com/diagnosis/interactive-todo/internal/store/tasks"
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+)
+
+const (
+	defaultSimilarLimit = 10
+	maxSimilarLimit     = 50
+	maxSimilarQuery     = 500
+
+	// duplicateMinScore is how alike a task must be to be flagged as a
+	// possible duplicate on create.
+	duplicateMinScore = 0.85
+	maxDuplicates     = 3
+)
+
+// SearchSimilarTasks finds the team's tasks closest in meaning to ?q=,
+// rather than by the words used. Only available with an embedding
+// provider, and never for confidential teams, whose text is not sent out.
+func (h *TaskHandler) SearchSimilarTasks(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := deadline.Budget(r.Context(), 10*time.Second)
+	defer cancel()
+
+	userID, ok := middleware.GetUserIDFromContext(ctx)
+	if !ok {
+		helper.RespondError(w, r, apperror.Unauthorized("authentication required"))
+		return
+	}
+
+	if h.embedder == nil {
+		helper.RespondError(w, r, apperror.NotFound("semantic search is not enabled"))
+		return
+	}
+
+	teamID, err := uuid.Parse(chi.URLParam(r, "team_id"))
+	if err != nil {
+		helper.RespondError(w, r, apperror.BadRequest("invalid team id"))
+		return
+	}
+
+	query := strings.TrimSpace(r.URL.Query().Get("q"))
+	if query == "" || len(query) > maxSimilarQuery {
+		helper.RespondError(w, r, apperror.BadRequest(fmt.Sprintf("q is required (max %d chars)", maxSimilarQuery)))
+		return
+	}
+	limit := defaultSimilarLimit
+	if v := r.URL.Query().Get("limit"); v != "" {
+		limit, err = strconv.Atoi(v)
+		if err != nil || limit < 1 || limit > maxSimilarLimit {
+			helper.RespondError(w, r, apperror.BadRequest(fmt.Sprintf("limit must be between 1 and %d", maxSimilarLimit)))
+			return
+		}
+	}
+
+	isMember, err := h.teamStore.IsMember(ctx, teamID, userID)
+	if err != nil {
+		logger.Error(ctx, "similar tasks: membership check failed", "err", err)
+		helper.RespondError(w, r, apperror.InternalError("internal error", err))
+		return
+	}
+	if !isMember {
+		helper.RespondError(w, r, apperror.Forbidden("only team members can search team tasks"))
+		return
+	}
+
+	settings, err := h.teamStore.GetSettings(ctx, teamID)
+	if err != nil {
+		logger.Error(ctx, "similar tasks: get team settings failed", "err", err)
+		helper.RespondError(w, r, apperror.InternalError("internal error", err))
+		return
+	}
+	if settings.Confidential {
+		helper.RespondError(w, r, apperror.Conflict("semantic search is not available for confidential teams"))
+		return
+	}
+
+	vecs, err := h.embedder.Embed(ctx, []string{query})
+	if err != nil {
+		logger.Error(ctx, "similar tasks: embed query failed", "err", err)
+		helper.RespondError(w, r, apperror.ServiceUnavailable("semantic search is temporarily unavailable"))
+		return
+	}
+
+	tasks, err := h.taskStore.SimilarTasks(ctx, teamID, userID, h.embedder.Model(), vecs[0], uuid.Nil, -1, limit)
+	if err != nil {
+		logger.Error(ctx, "similar tasks: store query failed", "err", err)
+		helper.RespondError(w, r, apperror.InternalError("internal error", err))
+		return
+	}
+
+	helper.RespondJSON(w, r, http.StatusOK, types.SimilarTaskListResponse{TeamID: teamID, Query: query, Tasks: tasks})
+}
+
+// findDuplicates embeds a task just created and returns similar tasks the
+// reporter can see. The vector is saved so the task is searchable at once.
+// Failures are logged and yield none: the task is already created.
+func (h *TaskHandler) findDuplicates(ctx context.Context, task *store.Task, viewerID uuid.UUID) []store.SimilarTask {
+	if h.embedder == nil {
+		return nil
+	}
+	ctx, cancel := context.WithTimeout(ctx, 3*time.Second)
+	defer cancel()
+
+	settings, err := h.teamStore.GetSettings(ctx, task.TeamID)
+	if err != nil || settings.Confidential {
+		return nil
+	}
+
+	vecs, err := h.embedder.Embed(ctx, []string{embedding.Text(task.Title, task.Description)})
+	if err != nil {
+		logger.Warn(ctx, "create task: embed failed", "task_id", task.ID, "err", err)
+		return nil
+	}
+	model := h.embedder.Model()
+	hash := embedding.ContentHash(task.Title, task.Description)
+	if err := h.taskStore.SaveEmbedding(ctx, task.ID, model, hash, vecs[0], time.Now().UTC()); err != nil {
+		logger.Warn(ctx, "create task: save embedding failed", "task_id", task.ID, "err", err)
+	}
+
+	dups, err := h.taskStore.SimilarTasks(ctx, task.TeamID, viewerID, model, vecs[0], task.ID, duplicateMinScore, maxDuplicates)
+	if err != nil {
+		logger.Warn(ctx, "create task: duplicate search failed", "task_id", task.ID, "err", err)
+		return nil
+	}
+	return dups
+}
@@ -0,0 +1,136 @@
+package handler
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/diagnosis/interactive-todo/api/types"
+	"github.com/diagnosis/interactive-todo/internal/apperror"
+	"github.com/diagnosis/interactive-todo/internal/deadline"
+	"github.com/diagnosis/interactive-todo/internal/helper"
+	"github.com/diagnosis/interactive-todo/internal/logger"
+	middleware "github.com/diagnosis/interactive-todo/internal/middleware/auth"
+	reportstore "github.com/diagnosis/interactive-todo/internal/store/reports"
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+)
+
+// Weekly status reports: a reporter opts in to a roll-up of the tasks they
+// created, sent by the status report job. Each report carries a link that
+// turns it off without signing in.
+
+// GetStatusReport reports whether the caller gets the weekly report.
+func (h *TaskHandler) GetStatusReport(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := deadline.Budget(r.Context(), 5*time.Second)
+	defer cancel()
+
+	userID, ok := middleware.GetUserIDFromContext(ctx)
+	if !ok {
+		helper.RespondError(w, r, apperror.Unauthorized("authentication required"))
+		return
+	}
+
+	sub, err := h.reportStore.Get(ctx, userID)
+	if err != nil && !errors.Is(err, reportstore.ErrNotSubscribed) {
+		logger.Error(ctx, "get status report: store failed", "err", err)
+		helper.RespondError(w, r, apperror.InternalError("internal error", err))
+		return
+	}
+	helper.RespondJSON(w, r, http.StatusOK, statusReportSubscription(userID, sub))
+}
+
+// SubscribeStatusReport opts the caller in; the first report comes a week
+// later. Subscribing again changes nothing.
+func (h *TaskHandler) SubscribeStatusReport(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := deadline.Budget(r.Context(), 5*time.Second)
+	defer cancel()
+
+	userID, ok := middleware.GetUserIDFromContext(ctx)
+	if !ok {
+		helper.RespondError(w, r, apperror.Unauthorized("authentication required"))
+		return
+	}
+
+	raw := make([]byte, 32)
+	if _, err := rand.Read(raw); err != nil {
+		logger.Error(ctx, "subscribe status report: token failed", "err", err)
+		helper.RespondError(w, r, apperror.InternalError("internal error", err))
+		return
+	}
+
+	sub, err := h.reportStore.Subscribe(ctx, userID, base64.RawURLEncoding.EncodeToString(raw), time.Now().UTC())
+	if err != nil {
+		logger.Error(ctx, "subscribe status report: store failed", "err", err)
+		helper.RespondError(w, r, apperror.InternalError("internal error", err))
+		return
+	}
+
+	logger.Info(ctx, "status report subscribed", "user_id", userID)
+	helper.RespondJSON(w, r, http.StatusOK, statusReportSubscription(userID, sub))
+}
+
+func (h *TaskHandler) UnsubscribeStatusReport(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := deadline.Budget(r.Context(), 5*time.Second)
+	defer cancel()
+
+	userID, ok := middleware.GetUserIDFromContext(ctx)
+	if !ok {
+		helper.RespondError(w, r, apperror.Unauthorized("authentication required"))
+		return
+	}
+
+	if err := h.reportStore.Unsubscribe(ctx, userID); err != nil {
+		if errors.Is(err, reportstore.ErrNotSubscribed) {
+			helper.RespondError(w, r, apperror.NotFound("not subscribed to status reports"))
+			return
+		}
+		logger.Error(ctx, "unsubscribe status report: store failed", "err", err)
+		helper.RespondError(w, r, apperror.InternalError("internal error", err))
+		return
+	}
+
+	logger.Info(ctx, "status report unsubscribed", "user_id", userID)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// GetStatusReportUnsubscribe answers the link in a report. It changes
+// nothing, as mail scanners follow links; the page it leads to posts back
+// to unsubscribe.
+func (h *TaskHandler) GetStatusReportUnsubscribe(w http.ResponseWriter, r *http.Request) {
+	helper.RespondJSON(w, r, http.StatusOK, types.StatusReportUnsubscribeInfo{
+		Action: "POST to this address to stop the weekly status report",
+	})
+}
+
+// UnsubscribeStatusReportByToken turns the report off from its link, which
+// is also what one-click unsubscribe in mail clients sends. An unknown or
+// already used token gets the same answer.
+func (h *TaskHandler) UnsubscribeStatusReportByToken(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := deadline.Budget(r.Context(), 5*time.Second)
+	defer cancel()
+
+	userID, err := h.reportStore.UnsubscribeByToken(ctx, chi.URLParam(r, "token"))
+	switch {
+	case err == nil:
+		logger.Info(ctx, "status report unsubscribed by link", "user_id", userID)
+	case !errors.Is(err, reportstore.ErrNotSubscribed):
+		logger.Error(ctx, "unsubscribe status report by link: store failed", "err", err)
+		helper.RespondError(w, r, apperror.InternalError("internal error", err))
+		return
+	}
+	helper.RespondMessage(w, r, http.StatusOK, "unsubscribed from the weekly status report")
+}
+
+func statusReportSubscription(userID uuid.UUID, sub *reportstore.Subscription) types.StatusReportSubscription {
+	out := types.StatusReportSubscription{UserID: userID}
+	if sub != nil {
+		out.Subscribed = true
+		out.LastSentAt = sub.LastSentAt
+		out.NextReportAt = &sub.NextReportAt
+		out.CreatedAt = &sub.CreatedAt
+	}
+	return out
+}
@@ -0,0 +1,197 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/diagnosis/interactive-todo/api/types"
+	"github.com/diagnosis/interactive-todo/internal/apperror"
+	"github.com/diagnosis/interactive-todo/internal/deadline"
+	"github.com/diagnosis/interactive-todo/internal/helper"
+	"github.com/diagnosis/interactive-todo/internal/logger"
+	middleware "github.com/diagnosis/interactive-todo/internal/middleware/auth"
+	store "github.com/diagnosis/interactive-todo/internal/store/tasks"
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+)
+
+// SuggestEdit records a title or description change proposed by a team
+// member who is not the reporter; the reporter edits directly instead.
+func (h *TaskHandler) SuggestEdit(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := deadline.Budget(r.Context(), 5*time.Second)
+	defer cancel()
+
+	userID, ok := middleware.GetUserIDFromContext(ctx)
+	if !ok {
+		helper.RespondError(w, r, apperror.Unauthorized("unauthorized"))
+		return
+	}
+
+	taskID, err := parseTaskID(r)
+	if err != nil {
+		helper.RespondError(w, r, apperror.BadRequest("invalid task id"))
+		return
+	}
+
+	r.Body = http.MaxBytesReader(w, r.Body, 1<<20)
+	defer r.Body.Close()
+
+	var in types.SuggestEditRequest
+	dec := json.NewDecoder(r.Body)
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&in); err != nil {
+		logger.Error(ctx, "suggest edit: bad json", "err", err)
+		helper.RespondError(w, r, apperror.BadRequest("invalid request body"))
+		return
+	}
+	if in.Title == nil && in.Description == nil {
+		helper.RespondError(w, r, apperror.BadRequest("title or description is required"))
+		return
+	}
+
+	task, ok := h.memberTask(ctx, w, r, taskID, userID, "suggest edit", "only team members can suggest edits")
+	if !ok {
+		return
+	}
+	if task.ReporterID == userID {
+		helper.RespondError(w, r, apperror.BadRequest("the task creator can edit the task directly"))
+		return
+	}
+
+	sg, err := h.taskStore.SuggestEdit(ctx, taskID, userID, in.Title, in.Description, time.Now().UTC())
+	if err != nil {
+		switch {
+		case errors.Is(err, store.ErrInvalidInput):
+			helper.RespondError(w, r, apperror.BadRequest(err.Error()))
+		case errors.Is(err, store.ErrTaskNotFound):
+			helper.RespondError(w, r, apperror.NotFound("task not found"))
+		case errors.Is(err, store.ErrSuggestionPending):
+			helper.RespondError(w, r, apperror.Conflict("you already have a pending suggestion on this task"))
+		case errors.Is(err, store.ErrEncryptionUnavailable):
+			helper.RespondError(w, r, apperror.ServiceUnavailable("a confidential team needs the field encryption key, which is not configured"))
+		default:
+			logger.Error(ctx, "suggest edit: store error", "err", err)
+			helper.RespondError(w, r, apperror.InternalError("internal error", err))
+		}
+		return
+	}
+
+	logger.Info(ctx, "edit suggested", "task_id", taskID, "suggestion_id", sg.ID, "user_id", userID)
+	helper.RespondJSON(w, r, http.StatusCreated, sg)
+}
+
+// ListEditSuggestions lists a task's suggestions, pending or not, to team
+// members who can see the task.
+func (h *TaskHandler) ListEditSuggestions(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := deadline.Budget(r.Context(), 5*time.Second)
+	defer cancel()
+
+	userID, ok := middleware.GetUserIDFromContext(ctx)
+	if !ok {
+		helper.RespondError(w, r, apperror.Unauthorized("unauthorized"))
+		return
+	}
+
+	taskID, err := parseTaskID(r)
+	if err != nil {
+		helper.RespondError(w, r, apperror.BadRequest("invalid task id"))
+		return
+	}
+
+	if _, ok := h.memberTask(ctx, w, r, taskID, userID, "list edit suggestions", "forbidden"); !ok {
+		return
+	}
+
+	suggestions, err := h.taskStore.ListEditSuggestions(ctx, taskID)
+	if err != nil {
+		logger.Error(ctx, "list edit suggestions: store query failed", "err", err)
+		helper.RespondError(w, r, apperror.InternalError("internal error", err))
+		return
+	}
+
+	helper.RespondJSON(w, r, http.StatusOK, types.EditSuggestionListResponse{
+		TaskID:      taskID,
+		Suggestions: suggestions,
+	})
+}
+
+// AcceptEditSuggestion applies a pending suggestion to the task. Only the
+// reporter can accept or reject.
+func (h *TaskHandler) AcceptEditSuggestion(w http.ResponseWriter, r *http.Request) {
+	h.resolveEditSuggestion(w, r, true)
+}
+
+func (h *TaskHandler) RejectEditSuggestion(w http.ResponseWriter, r *http.Request) {
+	h.resolveEditSuggestion(w, r, false)
+}
+
+func (h *TaskHandler) resolveEditSuggestion(w http.ResponseWriter, r *http.Request, accept bool) {
+	ctx, cancel := deadline.Budget(r.Context(), 5*time.Second)
+	defer cancel()
+
+	op := "accept edit suggestion"
+	if !accept {
+		op = "reject edit suggestion"
+	}
+
+	userID, ok := middleware.GetUserIDFromContext(ctx)
+	if !ok {
+		helper.RespondError(w, r, apperror.Unauthorized("unauthorized"))
+		return
+	}
+
+	taskID, err := parseTaskID(r)
+	if err != nil {
+		helper.RespondError(w, r, apperror.BadRequest("invalid task id"))
+		return
+	}
+	suggestionID, err := uuid.Parse(chi.URLParam(r, "suggestion_id"))
+	if err != nil {
+		helper.RespondError(w, r, apperror.BadRequest("invalid suggestion id"))
+		return
+	}
+
+	task, ok := h.memberTask(ctx, w, r, taskID, userID, op, "forbidden")
+	if !ok {
+		return
+	}
+	if task.ReporterID != userID {
+		helper.RespondError(w, r, apperror.Forbidden("only the task creator can accept or reject suggestions"))
+		return
+	}
+
+	now := time.Now().UTC()
+	var out types.EditSuggestionDecision
+	var sg *store.EditSuggestion
+	if accept {
+		sg, out.Task, err = h.taskStore.AcceptEditSuggestion(ctx, taskID, suggestionID, userID, now)
+	} else {
+		sg, err = h.taskStore.RejectEditSuggestion(ctx, taskID, suggestionID, userID, now)
+	}
+	if err != nil {
+		switch {
+		case errors.Is(err, store.ErrSuggestionNotFound):
+			helper.RespondError(w, r, apperror.NotFound("suggestion not found"))
+		case errors.Is(err, store.ErrTaskNotFound):
+			helper.RespondError(w, r, apperror.NotFound("task not found"))
+		case errors.Is(err, store.ErrSuggestionResolved), errors.Is(err, store.ErrVersionConflict):
+			helper.RespondError(w, r, apperror.Conflict(err.Error()))
+		case errors.Is(err, store.ErrEncryptionUnavailable):
+			helper.RespondError(w, r, apperror.ServiceUnavailable("a confidential team needs the field encryption key, which is not configured"))
+		default:
+			logger.Error(ctx, op+": store error", "err", err)
+			helper.RespondError(w, r, apperror.InternalError("internal error", err))
+		}
+		return
+	}
+	out.Suggestion = *sg
+
+	logger.Info(ctx, op, "task_id", taskID, "suggestion_id", suggestionID, "user_id", userID)
+	if out.Task != nil {
+		setTaskETag(w, out.Task)
+	}
+	h.localize(ctx, out.Task)
+	helper.RespondJSON(w, r, http.StatusOK, out)
+}
@@ -0,0 +1,48 @@
+package handler
+
+import (
+	"context"
+	"time"
+
+	"github.com/diagnosis/interactive-todo/internal/events"
+	labelstore "github.com/diagnosis/interactive-todo/internal/store/labels"
+	store "github.com/diagnosis/interactive-todo/internal/store/tasks"
+	"github.com/google/uuid"
+)
+
+// publishStatusChanged tells automations a task left status from; it is a
+// no-op when the status did not actually change.
+func (h *TaskHandler) publishStatusChanged(ctx context.Context, task *store.Task, from store.TaskStatus, actorID uuid.UUID, now time.Time) {
+	if task.Status == from {
+		return
+	}
+	h.events.Publish(ctx, events.Event{
+		Type:    events.TaskStatusChanged,
+		TeamID:  task.TeamID,
+		TaskID:  task.ID,
+		ActorID: &actorID,
+		Status:  string(task.Status),
+		From:    string(from),
+		At:      now,
+	})
+}
+
+// publishLabelsAdded publishes one event per label in added, which holds
+// the IDs Attach reported as new on the task.
+func (h *TaskHandler) publishLabelsAdded(ctx context.Context, task *store.Task, labels []labelstore.Label, added []uuid.UUID, actorID *uuid.UUID, now time.Time) {
+	for _, l := range labels {
+		for _, id := range added {
+			if l.ID != id {
+				continue
+			}
+			h.events.Publish(ctx, events.Event{
+				Type:    events.TaskLabelAdded,
+				TeamID:  task.TeamID,
+				TaskID:  task.ID,
+				ActorID: actorID,
+				Label:   l.Name,
+				At:      now,
+			})
+		}
+	}
+}
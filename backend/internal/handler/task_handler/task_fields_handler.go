@@ -0,0 +1,231 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/diagnosis/interactive-todo/api/types"
+	"github.com/diagnosis/interactive-todo/internal/apperror"
+	"github.com/diagnosis/interactive-todo/internal/helper"
+	"github.com/diagnosis/interactive-todo/internal/logger"
+	labelstore "github.com/diagnosis/interactive-todo/internal/store/labels"
+	store "github.com/diagnosis/interactive-todo/internal/store/tasks"
+	"github.com/google/uuid"
+)
+
+const (
+	defaultTasksPerPage = 50
+	maxTasksPerPage     = 200
+	// deep offsets are slow; past this, narrow the list instead
+	maxTaskPage = 10000
+)
+
+// parsePage reads ?page= (from 1) or ?cursor=, and ?per_page=.
+func parsePage(r *http.Request) (types.Pagination, store.Page, error) {
+	pg := types.Pagination{Page: 1, PerPage: defaultTasksPerPage}
+	q := r.URL.Query()
+	var after *store.Cursor
+	if v := q.Get("cursor"); v != "" {
+		if q.Get("page") != "" {
+			return pg, store.Page{}, errors.New("use either page or cursor")
+		}
+		c, err := store.ParseCursor(v)
+		if err != nil {
+			return pg, store.Page{}, err
+		}
+		after = c
+		pg.Page = 0
+	}
+	if v := q.Get("page"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 || n > maxTaskPage {
+			return pg, store.Page{}, fmt.Errorf("page must be between 1 and %d", maxTaskPage)
+		}
+		pg.Page = n
+	}
+	if v := q.Get("per_page"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 || n > maxTasksPerPage {
+			return pg, store.Page{}, fmt.Errorf("per_page must be between 1 and %d", maxTasksPerPage)
+		}
+		pg.PerPage = n
+	}
+	if after != nil {
+		return pg, store.Page{Limit: pg.PerPage, After: after}, nil
+	}
+	return pg, store.Page{Limit: pg.PerPage, Offset: (pg.Page - 1) * pg.PerPage}, nil
+}
+
+// parseTaskFilter reads ?status= and ?label= (both comma-separated),
+// ?due_after=, ?due_before=, ?start_after= and ?start_before=. Dates are RFC 3339 times or plain days,
+// which start at midnight UTC. Several labels select tasks having all of
+// them. ?include_archived=true lists archived tasks too. ?project_id=
+// selects one project's tasks, or with "none" those in no project.
+func parseTaskFilter(r *http.Request) (store.TaskFilter, error) {
+	var f store.TaskFilter
+	q := r.URL.Query()
+	if v := q.Get("status"); v != "" {
+		seen := make(map[store.TaskStatus]bool)
+		for _, s := range strings.Split(v, ",") {
+			switch st := store.TaskStatus(strings.TrimSpace(s)); st {
+			case store.OpenStatus, store.InProgressStatus, store.DoneStatus, store.CanceledStatus:
+				if !seen[st] {
+					seen[st] = true
+					f.Statuses = append(f.Statuses, st)
+				}
+			default:
+				return f, fmt.Errorf("invalid status %q", s)
+			}
+		}
+	}
+	if v := q.Get("label"); v != "" {
+		for _, name := range labelstore.NormalizeNames(strings.Split(v, ",")) {
+			if len(name) > labelstore.MaxNameLength {
+				return f, fmt.Errorf("labels must be at most %d chars", labelstore.MaxNameLength)
+			}
+			f.Labels = append(f.Labels, strings.ToLower(name))
+		}
+		if len(f.Labels) > maxTaskLabels {
+			return f, fmt.Errorf("at most %d labels", maxTaskLabels)
+		}
+	}
+	for _, p := range []struct {
+		name string
+		dst  **time.Time
+	}{
+		{"due_after", &f.DueAfter},
+		{"due_before", &f.DueBefore},
+		{"start_after", &f.StartAfter},
+		{"start_before", &f.StartBefore},
+	} {
+		v := q.Get(p.name)
+		if v == "" {
+			continue
+		}
+		t, err := time.Parse(time.RFC3339, v)
+		if err != nil {
+			if t, err = time.Parse(time.DateOnly, v); err != nil {
+				return f, fmt.Errorf("%s must be an RFC 3339 time or a YYYY-MM-DD date", p.name)
+			}
+		}
+		*p.dst = &t
+	}
+	if v := q.Get("project_id"); v != "" {
+		if v == "none" {
+			f.NoProject = true
+		} else {
+			id, err := uuid.Parse(v)
+			if err != nil {
+				return f, errors.New(`project_id must be a project id or "none"`)
+			}
+			f.ProjectID = &id
+		}
+	}
+	if v := q.Get("include_archived"); v != "" {
+		b, err := strconv.ParseBool(v)
+		if err != nil {
+			return f, errors.New("include_archived must be true or false")
+		}
+		f.IncludeArchived = b
+	}
+	if f.DueAfter != nil && f.DueBefore != nil && !f.DueAfter.Before(*f.DueBefore) {
+		return f, errors.New("due_after must be before due_before")
+	}
+	if f.StartAfter != nil && f.StartBefore != nil && !f.StartAfter.Before(*f.StartBefore) {
+		return f, errors.New("start_after must be before start_before")
+	}
+	return f, nil
+}
+
+// listWithFields returns a page of full tasks from list, or only the
+// columns named in ?fields= for the same tasks (described by scope) when the
+// parameter is set. Both are narrowed by the ?status=, ?label= and
+// due date filters and ordered by ?sort= and ?order=. On failure it has already written the error response
+// and ok is false. A cursor that does not fit the sort is a bad request.
+func (h *TaskHandler) listWithFields(
+	ctx context.Context,
+	w http.ResponseWriter,
+	r *http.Request,
+	op string,
+	scope store.TaskScope,
+	list func(filter store.TaskFilter, sort store.SortSpec, page store.Page) ([]store.Task, store.PageInfo, error),
+) (tasks any, pg types.Pagination, ok bool) {
+	fields, err := store.ParseTaskFields(r.URL.Query().Get("fields"))
+	if err != nil {
+		if errors.Is(err, store.ErrUnknownField) {
+			helper.RespondError(w, r, apperror.BadRequest(err.Error()))
+			return nil, pg, false
+		}
+		helper.RespondError(w, r, apperror.BadRequest("invalid fields"))
+		return nil, pg, false
+	}
+	pg, page, err := parsePage(r)
+	if err != nil {
+		helper.RespondError(w, r, apperror.BadRequest(err.Error()))
+		return nil, pg, false
+	}
+	filter, err := parseTaskFilter(r)
+	if err != nil {
+		helper.RespondError(w, r, apperror.BadRequest(err.Error()))
+		return nil, pg, false
+	}
+	sort, err := store.ParseSortSpec(r.URL.Query().Get("sort"), r.URL.Query().Get("order"))
+	if err != nil {
+		helper.RespondError(w, r, apperror.BadRequest(err.Error()))
+		return nil, pg, false
+	}
+
+	if fields == nil {
+		full, info, err := list(filter, sort, page)
+		if err != nil {
+			listError(ctx, w, r, op, err)
+			return nil, pg, false
+		}
+		refs := make([]*store.Task, len(full))
+		for i := range full {
+			refs[i] = &full[i]
+		}
+		if err := h.withLabels(ctx, refs...); err != nil {
+			listError(ctx, w, r, op, err)
+			return nil, pg, false
+		}
+		h.localize(ctx, refs...)
+		pg.Total, pg.NextCursor = info.Total, info.Next
+		return full, pg, true
+	}
+
+	scope.Filter = filter
+	scope.Sort = sort
+	slim, info, err := h.taskStore.ListTaskFields(ctx, scope, fields, page)
+	if err != nil {
+		listError(ctx, w, r, op, err)
+		return nil, pg, false
+	}
+	h.localizeFields(ctx, slim)
+	pg.Total, pg.NextCursor = info.Total, info.Next
+	return slim, pg, true
+}
+
+func listError(ctx context.Context, w http.ResponseWriter, r *http.Request, op string, err error) {
+	if errors.Is(err, store.ErrInvalidCursor) {
+		helper.RespondError(w, r, apperror.BadRequest(err.Error()))
+		return
+	}
+	logger.Error(ctx, op+": store query failed", "err", err)
+	helper.RespondError(w, r, apperror.InternalError("internal error", err))
+}
+
+// respondTaskList writes the result of listWithFields with its list meta.
+func respondTaskList(w http.ResponseWriter, r *http.Request, meta types.TaskListMeta, pg types.Pagination, tasks any) {
+	switch t := tasks.(type) {
+	case []map[string]any:
+		helper.RespondJSON(w, r, http.StatusOK, types.PartialTaskListResponse{TaskListMeta: meta, Pagination: pg, Tasks: t})
+	case []store.Task:
+		helper.RespondJSON(w, r, http.StatusOK, types.TaskListResponse{TaskListMeta: meta, Pagination: pg, Tasks: t})
+	}
+}
@@ -4,16 +4,44 @@ import (
 	"context"
 	"encoding/json"
 	"errors"
+	"fmt"
 	"net/http"
+	"slices"
+	"strconv"
 	"strings"
 	"time"
 
+	"github.com/diagnosis/interactive-todo/api/types"
 	"github.com/diagnosis/interactive-todo/internal/apperror"
+	"github.com/diagnosis/interactive-todo/internal/auth/signedurl"
+	"github.com/diagnosis/interactive-todo/internal/deadline"
+	"github.com/diagnosis/interactive-todo/internal/embedding"
+	"github.com/diagnosis/interactive-todo/internal/events"
 	"github.com/diagnosis/interactive-todo/internal/helper"
+	"github.com/diagnosis/interactive-todo/internal/labelrules"
 	"github.com/diagnosis/interactive-todo/internal/logger"
 	middleware "github.com/diagnosis/interactive-todo/internal/middleware/auth"
+	"github.com/diagnosis/interactive-todo/internal/secure/spamguard"
+	approvalstore "github.com/diagnosis/interactive-todo/internal/store/approvals"
+	assignmentstore "github.com/diagnosis/interactive-todo/internal/store/assignment"
+	auditstore "github.com/diagnosis/interactive-todo/internal/store/audit"
+	calendarfeedstore "github.com/diagnosis/interactive-todo/internal/store/calendarfeeds"
+	calendarstore "github.com/diagnosis/interactive-todo/internal/store/calendars"
+	commentstore "github.com/diagnosis/interactive-todo/internal/store/comments"
+	customfieldstore "github.com/diagnosis/interactive-todo/internal/store/customfields"
+	delegationstore "github.com/diagnosis/interactive-todo/internal/store/delegations"
+	formstore "github.com/diagnosis/interactive-todo/internal/store/forms"
+	labelrulestore "github.com/diagnosis/interactive-todo/internal/store/labelrules"
+	labelstore "github.com/diagnosis/interactive-todo/internal/store/labels"
+	outofficestore "github.com/diagnosis/interactive-todo/internal/store/outofoffice"
+	reminderstore "github.com/diagnosis/interactive-todo/internal/store/reminders"
+	reportstore "github.com/diagnosis/interactive-todo/internal/store/reports"
+	rotationstore "github.com/diagnosis/interactive-todo/internal/store/rotations"
 	store "github.com/diagnosis/interactive-todo/internal/store/tasks"
 	teamstore "github.com/diagnosis/interactive-todo/internal/store/teams"
+	triagestore "github.com/diagnosis/interactive-todo/internal/store/triage"
+	userstore "github.com/diagnosis/interactive-todo/internal/store/users"
+	workflowstore "github.com/diagnosis/interactive-todo/internal/store/workflows"
 	"github.com/go-chi/chi/v5"
 	"github.com/google/uuid"
 )
@@ -21,21 +49,95 @@ import (
 type TaskHandler struct {
 	taskStore store.TaskStore
 	teamStore teamstore.TeamStore
+	// userStore holds the timezones task dates are shown in
+	userStore       userstore.UserStore
+	approvalStore   approvalstore.ApprovalStore
+	workflowStore   workflowstore.WorkflowStore
+	formStore       formstore.FormStore
+	triageStore     triagestore.TriageStore
+	auditStore      auditstore.AuditStore
+	calendarStore   calendarstore.CalendarStore
+	labelStore      labelstore.LabelStore
+	labelRuleStore  labelrulestore.LabelRuleStore
+	assignmentStore assignmentstore.AssignmentStore
+	commentStore    commentstore.CommentStore
+	// customFieldStore holds the team field definitions task values are
+	// checked against
+	customFieldStore customfieldstore.CustomFieldStore
+	reportStore      reportstore.ReportStore
+	delegationStore  delegationstore.DelegationStore
+	outOfOfficeStore outofficestore.OutOfOfficeStore
+	// calendarFeedStore holds which feed token of each user is current
+	calendarFeedStore calendarfeedstore.CalendarFeedStore
+	// rotationStore puts labelled tasks on whoever is on call
+	rotationStore rotationstore.RotationStore
+	reminderStore reminderstore.ReminderStore
+	events        events.Publisher
+	// embedder is nil unless semantic search is enabled
+	embedder  embedding.Provider
+	spamGuard *spamguard.Guard
+	urlSigner *signedurl.Signer
+	// attachments holds the store and limits of files on tasks
+	attachments Attachments
 }
 
-type input struct {
-	TeamID      uuid.UUID  `json:"team_id"`
-	Title       string     `json:"title"`
-	Description *string    `json:"description"`
-	AssigneeID  *uuid.UUID `json:"assignee_id"`
-	DueAt       time.Time  `json:"due_at"`
-}
-
-func NewTaskHandler(ts store.TaskStore, tms teamstore.TeamStore) *TaskHandler {
-	return &TaskHandler{taskStore: ts, teamStore: tms}
+func NewTaskHandler(
+	ts store.TaskStore,
+	tms teamstore.TeamStore,
+	us userstore.UserStore,
+	as approvalstore.ApprovalStore,
+	ws workflowstore.WorkflowStore,
+	fs formstore.FormStore,
+	trs triagestore.TriageStore,
+	aus auditstore.AuditStore,
+	cs calendarstore.CalendarStore,
+	ls labelstore.LabelStore,
+	lrs labelrulestore.LabelRuleStore,
+	asg assignmentstore.AssignmentStore,
+	cms commentstore.CommentStore,
+	cfs customfieldstore.CustomFieldStore,
+	rps reportstore.ReportStore,
+	dls delegationstore.DelegationStore,
+	oos outofficestore.OutOfOfficeStore,
+	cfd calendarfeedstore.CalendarFeedStore,
+	rts rotationstore.RotationStore,
+	rms reminderstore.ReminderStore,
+	ev events.Publisher,
+	emb embedding.Provider,
+	sg *spamguard.Guard,
+	signer *signedurl.Signer,
+	att Attachments,
+) *TaskHandler {
+	return &TaskHandler{
+		taskStore:         ts,
+		teamStore:         tms,
+		userStore:         us,
+		approvalStore:     as,
+		workflowStore:     ws,
+		formStore:         fs,
+		triageStore:       trs,
+		auditStore:        aus,
+		calendarStore:     cs,
+		labelStore:        ls,
+		labelRuleStore:    lrs,
+		assignmentStore:   asg,
+		commentStore:      cms,
+		customFieldStore:  cfs,
+		reportStore:       rps,
+		delegationStore:   dls,
+		outOfOfficeStore:  oos,
+		calendarFeedStore: cfd,
+		rotationStore:     rts,
+		reminderStore:     rms,
+		events:            ev,
+		embedder:          emb,
+		spamGuard:         sg,
+		urlSigner:         signer,
+		attachments:       att,
+	}
 }
 func (h *TaskHandler) ListAssigneeTasksInTeam(w http.ResponseWriter, r *http.Request) {
-	ctx, cancel := context.WithTimeout(r.Context(), 5*time.Second)
+	ctx, cancel := deadline.Budget(r.Context(), 5*time.Second)
 	defer cancel()
 
 	userID, ok := middleware.GetUserIDFromContext(ctx)
@@ -71,29 +173,28 @@ func (h *TaskHandler) ListAssigneeTasksInTeam(w http.ResponseWriter, r *http.Req
 		return
 	}
 
-	tasks, err := h.taskStore.ListAssigneeTasksInTeam(ctx, teamID, userID)
-	if err != nil {
-		logger.Error(ctx, "list assignee tasks in team: store query failed", "err", err)
-		helper.RespondError(w, r, apperror.InternalError("internal error", err))
+	tasks, pg, ok := h.listWithFields(ctx, w, r, "list assignee tasks in team",
+		store.TaskScope{TeamID: teamID, AssigneeID: userID, ByDueDate: true},
+		func(filter store.TaskFilter, sort store.SortSpec, page store.Page) ([]store.Task, store.PageInfo, error) {
+			return h.taskStore.ListAssigneeTasksInTeam(ctx, teamID, userID, filter, sort, page)
+		},
+	)
+	if !ok {
 		return
 	}
 
 	logger.Info(ctx, "list assignee tasks in team: success",
 		"user_id", userID,
 		"team_id", teamID,
-		"count", len(tasks),
+		"page", pg.Page,
+		"more", pg.NextCursor != "",
 	)
 
-	response := map[string]any{
-		"user_id": userID,
-		"team_id": teamID,
-		"tasks":   tasks,
-	}
-	helper.RespondJSON(w, r, http.StatusOK, response)
+	respondTaskList(w, r, types.TaskListMeta{UserID: userID, TeamID: &teamID}, pg, tasks)
 }
 
 func (h *TaskHandler) ListReporterTasksInTeam(w http.ResponseWriter, r *http.Request) {
-	ctx, cancel := context.WithTimeout(r.Context(), 5*time.Second)
+	ctx, cancel := deadline.Budget(r.Context(), 5*time.Second)
 	defer cancel()
 
 	userID, ok := middleware.GetUserIDFromContext(ctx)
@@ -129,29 +230,28 @@ func (h *TaskHandler) ListReporterTasksInTeam(w http.ResponseWriter, r *http.Req
 		return
 	}
 
-	tasks, err := h.taskStore.ListReporterTasksInTeam(ctx, teamID, userID)
-	if err != nil {
-		logger.Error(ctx, "list reporter tasks in team: store query failed", "err", err)
-		helper.RespondError(w, r, apperror.InternalError("internal error", err))
+	tasks, pg, ok := h.listWithFields(ctx, w, r, "list reporter tasks in team",
+		store.TaskScope{TeamID: teamID, ReporterID: userID},
+		func(filter store.TaskFilter, sort store.SortSpec, page store.Page) ([]store.Task, store.PageInfo, error) {
+			return h.taskStore.ListReporterTasksInTeam(ctx, teamID, userID, filter, sort, page)
+		},
+	)
+	if !ok {
 		return
 	}
 
 	logger.Info(ctx, "list reporter tasks in team: success",
 		"user_id", userID,
 		"team_id", teamID,
-		"count", len(tasks),
+		"page", pg.Page,
+		"more", pg.NextCursor != "",
 	)
 
-	response := map[string]any{
-		"user_id": userID,
-		"team_id": teamID,
-		"tasks":   tasks,
-	}
-	helper.RespondJSON(w, r, http.StatusOK, response)
+	respondTaskList(w, r, types.TaskListMeta{UserID: userID, TeamID: &teamID}, pg, tasks)
 }
 
 func (h *TaskHandler) ListTeamTasks(w http.ResponseWriter, r *http.Request) {
-	ctx, cancel := context.WithTimeout(r.Context(), 5*time.Second)
+	ctx, cancel := deadline.Budget(r.Context(), 5*time.Second)
 	defer cancel()
 
 	userID, ok := middleware.GetUserIDFromContext(ctx)
@@ -184,29 +284,150 @@ func (h *TaskHandler) ListTeamTasks(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	tasks, err := h.taskStore.ListTeamTasks(ctx, teamID)
+	tasks, pg, ok := h.listWithFields(ctx, w, r, "list team tasks",
+		store.TaskScope{TeamID: teamID, ViewerID: userID},
+		func(filter store.TaskFilter, sort store.SortSpec, page store.Page) ([]store.Task, store.PageInfo, error) {
+			return h.taskStore.ListTeamTasks(ctx, teamID, userID, filter, sort, page)
+		},
+	)
+	if !ok {
+		return
+	}
+
+	logger.Info(ctx, "list team tasks: success",
+		"user_id", userID,
+		"team_id", teamID,
+		"page", pg.Page,
+		"more", pg.NextCursor != "",
+	)
+
+	respondTaskList(w, r, types.TaskListMeta{UserID: userID, TeamID: &teamID}, pg, tasks)
+}
+
+// GetTeamTaskStats returns the team's task counts by status plus overdue
+// tasks, read from maintained counters rather than a scan of the team.
+func (h *TaskHandler) GetTeamTaskStats(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := deadline.Budget(r.Context(), 5*time.Second)
+	defer cancel()
+
+	userID, ok := middleware.GetUserIDFromContext(ctx)
+	if !ok {
+		logger.Info(ctx, "team task stats: unauthorized")
+		helper.RespondError(w, r, apperror.Unauthorized("authentication required"))
+		return
+	}
+
+	teamIDStr := chi.URLParam(r, "team_id")
+	teamID, err := uuid.Parse(teamIDStr)
+	if err != nil {
+		logger.Error(ctx, "team task stats: invalid team id", "team_id", teamIDStr, "err", err)
+		helper.RespondError(w, r, apperror.BadRequest("invalid team id"))
+		return
+	}
+
+	isMember, err := h.teamStore.IsMember(ctx, teamID, userID)
+	if err != nil {
+		logger.Error(ctx, "team task stats: membership check failed", "err", err)
+		helper.RespondError(w, r, apperror.InternalError("internal error", err))
+		return
+	}
+	if !isMember {
+		helper.RespondError(w, r, apperror.Forbidden("only team members can view team tasks"))
+		return
+	}
+
+	counts, err := h.taskStore.GetTeamTaskCounts(ctx, teamID, time.Now().UTC())
 	if err != nil {
-		logger.Error(ctx, "list team tasks: store query failed", "err", err)
+		logger.Error(ctx, "team task stats: store query failed", "err", err)
 		helper.RespondError(w, r, apperror.InternalError("internal error", err))
 		return
 	}
 
-	logger.Info(ctx, "list team tasks: success",
+	helper.RespondJSON(w, r, http.StatusOK, counts)
+}
+
+// ListStaleTasks reports active tasks not updated in ?days= (default 14),
+// grouped by assignee so managers can spot overloaded people.
+func (h *TaskHandler) ListStaleTasks(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := deadline.Budget(r.Context(), 5*time.Second)
+	defer cancel()
+
+	userID, ok := middleware.GetUserIDFromContext(ctx)
+	if !ok {
+		logger.Info(ctx, "list stale tasks: unauthorized")
+		helper.RespondError(w, r, apperror.Unauthorized("authentication required"))
+		return
+	}
+
+	teamIDStr := chi.URLParam(r, "team_id")
+	teamID, err := uuid.Parse(teamIDStr)
+	if err != nil {
+		logger.Error(ctx, "list stale tasks: invalid team id", "team_id", teamIDStr, "err", err)
+		helper.RespondError(w, r, apperror.BadRequest("invalid team id"))
+		return
+	}
+
+	days := 14
+	if v := r.URL.Query().Get("days"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 || n > 365 {
+			helper.RespondError(w, r, apperror.BadRequest("days must be between 1 and 365"))
+			return
+		}
+		days = n
+	}
+
+	isMember, err := h.teamStore.IsMember(ctx, teamID, userID)
+	if err != nil {
+		logger.Error(ctx, "list stale tasks: membership check failed", "err", err)
+		helper.RespondError(w, r, apperror.InternalError("internal error", err))
+		return
+	}
+	if !isMember {
+		logger.Info(ctx, "list stale tasks: forbidden (not team member)",
+			"user_id", userID,
+			"team_id", teamID,
+		)
+		helper.RespondError(w, r, apperror.Forbidden("only team members can view team tasks"))
+		return
+	}
+
+	cutoff := time.Now().UTC().AddDate(0, 0, -days)
+	tasks, err := h.taskStore.ListStaleTasksInTeam(ctx, teamID, userID, cutoff)
+	if err != nil {
+		logger.Error(ctx, "list stale tasks: store query failed", "err", err)
+		helper.RespondError(w, r, apperror.InternalError("internal error", err))
+		return
+	}
+
+	// tasks arrive ordered by assignee
+	groups := []types.StaleAssignee{}
+	for _, t := range tasks {
+		if n := len(groups); n == 0 || groups[n-1].AssigneeID != t.AssigneeID {
+			groups = append(groups, types.StaleAssignee{AssigneeID: t.AssigneeID})
+		}
+		g := &groups[len(groups)-1]
+		g.Tasks = append(g.Tasks, t)
+		g.Count++
+	}
+
+	logger.Info(ctx, "list stale tasks: success",
 		"user_id", userID,
 		"team_id", teamID,
 		"count", len(tasks),
 	)
 
-	response := map[string]any{
-		"user_id": userID,
-		"team_id": teamID,
-		"tasks":   tasks,
-	}
-	helper.RespondJSON(w, r, http.StatusOK, response)
+	helper.RespondJSON(w, r, http.StatusOK, types.StaleTasksResponse{
+		TeamID:     teamID,
+		Days:       days,
+		StaleSince: cutoff,
+		Total:      len(tasks),
+		Assignees:  groups,
+	})
 }
 
 func (h *TaskHandler) CreateTask(w http.ResponseWriter, r *http.Request) {
-	ctx, cancel := context.WithTimeout(r.Context(), 5*time.Second)
+	ctx, cancel := deadline.Budget(r.Context(), 5*time.Second)
 	defer cancel()
 
 	reporterID, ok := middleware.GetUserIDFromContext(ctx)
@@ -219,7 +440,7 @@ func (h *TaskHandler) CreateTask(w http.ResponseWriter, r *http.Request) {
 	r.Body = http.MaxBytesReader(w, r.Body, 1<<20)
 	defer r.Body.Close()
 
-	var in input
+	var in types.CreateTaskRequest
 	dec := json.NewDecoder(r.Body)
 	dec.DisallowUnknownFields()
 
@@ -229,9 +450,23 @@ func (h *TaskHandler) CreateTask(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// Default assignee to reporter if not provided
-	if in.AssigneeID == nil || *in.AssigneeID == uuid.Nil {
-		in.AssigneeID = &reporterID
+	// Without an assignee the team's auto_assign strategy picks one,
+	// falling back to the reporter
+	if in.AssigneeID != nil && *in.AssigneeID == uuid.Nil {
+		in.AssigneeID = nil
+	}
+
+	if in.DueLocal != nil {
+		if !in.DueAt.IsZero() {
+			helper.RespondError(w, r, apperror.BadRequest("due_at and due_local cannot both be set"))
+			return
+		}
+		dueAt, err := h.parseDueLocal(ctx, reporterID, *in.DueLocal)
+		if err != nil {
+			helper.RespondError(w, r, apperror.BadRequest(err.Error()))
+			return
+		}
+		in.DueAt = dueAt
 	}
 
 	if err := taskInputValidation(in); err != nil {
@@ -254,7 +489,7 @@ func (h *TaskHandler) CreateTask(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// Ensure assignee is also a member of the team
-	if *in.AssigneeID != reporterID {
+	if in.AssigneeID != nil && *in.AssigneeID != reporterID {
 		isAssigneeMember, err := h.teamStore.IsMember(ctx, in.TeamID, *in.AssigneeID)
 		if err != nil {
 			logger.Error(ctx, "create task: assignee membership check failed", "err", err)
@@ -269,17 +504,194 @@ func (h *TaskHandler) CreateTask(w http.ResponseWriter, r *http.Request) {
 	}
 
 	now := time.Now().UTC()
-	task, err := h.taskStore.Create(ctx, in.TeamID, in.Title, in.Description, reporterID, *in.AssigneeID, in.DueAt, now)
+	if in.AssigneeID != nil && !h.checkOutOfOffice(ctx, w, r, "create task", in.TeamID, reporterID, *in.AssigneeID, in.AllowOutOfOffice, now) {
+		return
+	}
+
+	// Admins are exempt from the spam guard so they can run bulk operations
+	if claims, ok := middleware.GetClaimsFromContext(ctx); !ok || claims.UserType != userstore.TypeAdmin {
+		mute, err := h.spamGuard.CheckTaskCreate(ctx, reporterID, in.Title, now)
+		if err != nil {
+			if errors.Is(err, spamguard.ErrMuted) {
+				logger.Info(ctx, "create task: reporter muted", "reporter_id", reporterID, "muted_until", mute.MutedUntil)
+				helper.RespondError(w, r, apperror.TooManyRequests("too many similar tasks, try again after "+mute.MutedUntil.Format(time.RFC3339)))
+				return
+			}
+			logger.Error(ctx, "create task: spam guard failed", "err", err)
+			helper.RespondError(w, r, apperror.InternalError("internal error", err))
+			return
+		}
+	}
+
+	if len(in.ViewerIDs) > 0 {
+		if !in.Private {
+			helper.RespondError(w, r, apperror.BadRequest("viewer_ids requires private"))
+			return
+		}
+		if ok := h.checkViewersInTeam(ctx, w, r, in.TeamID, in.ViewerIDs); !ok {
+			return
+		}
+	}
+
+	customFields, ok := h.checkCustomFields(ctx, w, r, in.TeamID, in.CustomFields, false)
+	if !ok {
+		return
+	}
+
+	task, err := h.createTask(ctx, newTask{
+		TeamID:        in.TeamID,
+		Title:         in.Title,
+		Description:   in.Description,
+		ReporterID:    reporterID,
+		AssigneeID:    in.AssigneeID,
+		Fallback:      reporterID,
+		StartAt:       in.StartAt,
+		DueAt:         in.DueAt,
+		Private:       in.Private,
+		Labels:        in.Labels,
+		Priority:      in.Priority,
+		EstimateHours: in.EstimateHours,
+		ProjectID:     in.ProjectID,
+		CustomFields:  customFields,
+	}, now)
 	if err != nil {
+		if errors.Is(err, store.ErrProjectNotInTeam) {
+			helper.RespondError(w, r, apperror.BadRequest(err.Error()))
+			return
+		}
 		logger.Error(ctx, "create task: store create failed", "err", err)
 		helper.RespondError(w, r, apperror.InternalError("failed to create task", err))
 		return
 	}
 
+	if len(in.ViewerIDs) > 0 {
+		task, err = h.taskStore.SetVisibility(ctx, task.ID, true, in.ViewerIDs, &reporterID, now)
+		if err != nil {
+			logger.Error(ctx, "create task: set viewers failed", "err", err)
+			helper.RespondError(w, r, apperror.InternalError("internal error", err))
+			return
+		}
+	}
+
+	task.PossibleDuplicates = h.findDuplicates(ctx, task, reporterID)
+
 	logger.Info(ctx, "task created", "task_id", task.ID)
+	h.localize(ctx, task)
 	helper.RespondJSON(w, r, http.StatusCreated, task)
 }
 
+// newTask is what each creation path hands to createTask.
+type newTask struct {
+	TeamID      uuid.UUID
+	Title       string
+	Description *string
+	ReporterID  uuid.UUID
+	// AssigneeID nil lets the team's auto_assign strategy pick; Fallback is
+	// used when the team has none
+	AssigneeID *uuid.UUID
+	Fallback   uuid.UUID
+	StartAt    *time.Time
+	DueAt      time.Time
+	Private    bool
+	// Labels are created in the team on first use
+	Labels []string
+	// Priority nil takes what the team's label rules set, or normal
+	Priority      *store.TaskPriority
+	EstimateHours *float64
+	// ProjectID must be a project of TeamID
+	ProjectID *uuid.UUID
+	// CustomFields must have been through checkCustomFields; only
+	// CreateTask sets them, so required fields apply to it alone
+	CustomFields map[string]any
+}
+
+// createTask inserts a task and places it in the initial state of the team's
+// custom workflow, if any. Every path that creates tasks one at a time goes
+// through here, so the team's due date policy, label rules and
+// auto-assignment apply to all of them; the CSV import does not, see
+// ImportTeamTasks. Rules run first so label routing sees the labels they add.
+func (h *TaskHandler) createTask(ctx context.Context, t newTask, now time.Time) (*store.Task, error) {
+	dueAt, notice, err := h.applyDueDatePolicy(ctx, t.TeamID, t.DueAt)
+	if err != nil {
+		return nil, err
+	}
+
+	outcome, err := h.evaluateLabelRules(ctx, t.TeamID, t.Title, t.Description)
+	if err != nil {
+		return nil, err
+	}
+	priority := store.TaskPriority(types.TaskPriorityNormal)
+	switch {
+	case t.Priority != nil:
+		priority = *t.Priority
+	case outcome.Priority != nil:
+		priority = *outcome.Priority
+	}
+
+	labels, err := h.labelStore.Ensure(ctx, t.TeamID, slices.Concat(t.Labels, outcome.Labels), now)
+	if err != nil {
+		return nil, err
+	}
+
+	var pick *autoAssignment
+	var assignNotice *types.AssignmentNotice
+	assigneeID := t.Fallback
+	if t.AssigneeID != nil {
+		assigneeID = *t.AssigneeID
+	} else {
+		picked, p, n, err := h.pickAssignee(ctx, t.TeamID, labels, now)
+		if err != nil {
+			return nil, fmt.Errorf("auto assign: %w", err)
+		}
+		if p != nil {
+			assigneeID, pick = picked, p
+		}
+		assignNotice = n
+	}
+
+	task, err := h.taskStore.Create(ctx, t.TeamID, t.Title, t.Description, t.ReporterID, assigneeID, t.StartAt, dueAt, t.Private, priority, t.EstimateHours, t.ProjectID, t.CustomFields, &t.ReporterID, now)
+	if err != nil {
+		return nil, err
+	}
+
+	labelIDs := make([]uuid.UUID, 0, len(labels))
+	for _, l := range labels {
+		labelIDs = append(labelIDs, l.ID)
+	}
+	added, err := h.labelStore.Attach(ctx, task.ID, labelIDs, now)
+	if err != nil {
+		return nil, err
+	}
+	if pick != nil {
+		h.recordAutoAssignment(ctx, &t, task.ID, assigneeID, pick, now)
+	}
+
+	def, err := h.teamWorkflow(ctx, t.TeamID)
+	if err != nil {
+		return nil, fmt.Errorf("load team workflow: %w", err)
+	}
+	if def != nil {
+		initial, _ := def.State(def.Initial)
+		if task, err = h.taskStore.SetWorkflowState(ctx, task.ID, initial.Key, initial.Category, &t.ReporterID, now); err != nil {
+			return nil, err
+		}
+	}
+
+	h.events.Publish(ctx, events.Event{
+		Type:    events.TaskCreated,
+		TeamID:  task.TeamID,
+		TaskID:  task.ID,
+		ActorID: &t.ReporterID,
+		Status:  string(task.Status),
+		At:      now,
+	})
+	h.publishLabelsAdded(ctx, task, labels, added, &t.ReporterID, now)
+
+	task.DueDateNotice = notice
+	task.AssignmentNotice = assignNotice
+	return task, nil
+}
+
 func (h *TaskHandler) ListTasksAsReporter(w http.ResponseWriter, r *http.Request) {
 	h.listTasks(w, r, true)
 }
@@ -289,7 +701,7 @@ func (h *TaskHandler) ListTasksAsAssignee(w http.ResponseWriter, r *http.Request
 }
 
 func (h *TaskHandler) GetTask(w http.ResponseWriter, r *http.Request) {
-	ctx, cancel := context.WithTimeout(r.Context(), 5*time.Second)
+	ctx, cancel := deadline.Budget(r.Context(), 5*time.Second)
 	defer cancel()
 
 	userID, ok := middleware.GetUserIDFromContext(ctx)
@@ -305,7 +717,7 @@ func (h *TaskHandler) GetTask(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	task, err := h.getTaskByID(ctx, id)
+	task, err := h.getTaskByID(ctx, id, userID)
 	if err != nil {
 		if errors.Is(err, store.ErrTaskNotFound) {
 			logger.Info(ctx, "get task: not found", "task_id", id)
@@ -329,15 +741,33 @@ func (h *TaskHandler) GetTask(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	response := map[string]any{
-		"user_id": userID,
-		"task":    task,
+	// The assignee opening the task is the read receipt the reporter sees.
+	if userID == task.AssigneeID && task.AcknowledgedAt == nil {
+		now := time.Now().UTC()
+		acked, err := h.taskStore.Acknowledge(ctx, task.ID, userID, now)
+		if err != nil {
+			logger.Error(ctx, "get task: acknowledge failed", "task_id", task.ID, "err", err)
+		} else if acked {
+			task.AcknowledgedAt = &now
+			logger.Info(ctx, "task acknowledged", "task_id", task.ID, "assignee_id", userID)
+		}
+	}
+
+	if err := h.withLabels(ctx, task); err != nil {
+		logger.Error(ctx, "get task: load labels failed", "err", err)
+		helper.RespondError(w, r, apperror.InternalError("internal error", err))
+		return
 	}
-	helper.RespondJSON(w, r, http.StatusOK, response)
+
+	h.recordTaskView(ctx, r, task, userID)
+
+	setTaskETag(w, task)
+	h.localize(ctx, task)
+	helper.RespondJSON(w, r, http.StatusOK, types.GetTaskResponse{UserID: userID, Task: task})
 }
 
 func (h *TaskHandler) AssignTask(w http.ResponseWriter, r *http.Request) {
-	ctx, cancel := context.WithTimeout(r.Context(), 5*time.Second)
+	ctx, cancel := deadline.Budget(r.Context(), 5*time.Second)
 	defer cancel()
 
 	userID, ok := middleware.GetUserIDFromContext(ctx)
@@ -353,7 +783,7 @@ func (h *TaskHandler) AssignTask(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	task, err := h.getTaskByID(ctx, taskID)
+	task, err := h.getTaskByID(ctx, taskID, userID)
 	if err != nil {
 		if errors.Is(err, store.ErrTaskNotFound) {
 			logger.Info(ctx, "assign task: task not found", "task_id", taskID)
@@ -378,8 +808,15 @@ func (h *TaskHandler) AssignTask(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// Only reporter can assign
-	if userID != task.ReporterID {
+	// Only reporter, or a delegate of theirs, can assign
+	now := time.Now().UTC()
+	allowed, delegated, err := h.actsAsReporter(ctx, task, userID, now)
+	if err != nil {
+		logger.Error(ctx, "assign task: delegation check failed", "err", err)
+		helper.RespondError(w, r, apperror.InternalError("internal error", err))
+		return
+	}
+	if !allowed {
 		logger.Info(ctx, "assign task: forbidden (not reporter)",
 			"user_id", userID,
 			"reporter_id", task.ReporterID,
@@ -391,9 +828,7 @@ func (h *TaskHandler) AssignTask(w http.ResponseWriter, r *http.Request) {
 	r.Body = http.MaxBytesReader(w, r.Body, 1<<20)
 	defer r.Body.Close()
 
-	var in struct {
-		AssigneeID uuid.UUID `json:"assignee_id"`
-	}
+	var in types.AssignTaskRequest
 	dec := json.NewDecoder(r.Body)
 	dec.DisallowUnknownFields()
 
@@ -406,6 +841,10 @@ func (h *TaskHandler) AssignTask(w http.ResponseWriter, r *http.Request) {
 		helper.RespondError(w, r, apperror.BadRequest("assignee_id is required"))
 		return
 	}
+	version, ok := checkIfVersion(w, r, task, in.Version)
+	if !ok {
+		return
+	}
 
 	// Ensure assignee is a member of the team
 	isAssigneeMember, err := h.teamStore.IsMember(ctx, task.TeamID, in.AssigneeID)
@@ -419,20 +858,39 @@ func (h *TaskHandler) AssignTask(w http.ResponseWriter, r *http.Request) {
 		helper.RespondError(w, r, apperror.BadRequest("assignee must be a member of the team"))
 		return
 	}
+	if !h.checkOutOfOffice(ctx, w, r, "assign task", task.TeamID, userID, in.AssigneeID, in.AllowOutOfOffice, now) {
+		return
+	}
 
-	task, err = h.taskStore.Assign(ctx, task.ID, in.AssigneeID, time.Now().UTC())
+	task, err = h.taskStore.Assign(ctx, task.ID, in.AssigneeID, version, &userID, now)
 	if err != nil {
+		if errors.Is(err, store.ErrVersionConflict) {
+			helper.RespondError(w, r, apperror.Conflict(err.Error()))
+			return
+		}
 		logger.Error(ctx, "assign task: store assign failed", "err", err)
 		helper.RespondError(w, r, apperror.InternalError("internal error", err))
 		return
 	}
+	if delegated {
+		h.recordDelegationUse(ctx, r, task, userID, "assign", now)
+	}
+	h.events.Publish(ctx, events.Event{
+		Type:    events.TaskAssigned,
+		TeamID:  task.TeamID,
+		TaskID:  task.ID,
+		ActorID: &userID,
+		At:      now,
+	})
 
 	logger.Info(ctx, "task assigned", "task_id", task.ID, "assignee_id", task.AssigneeID)
+	setTaskETag(w, task)
+	h.localize(ctx, task)
 	helper.RespondJSON(w, r, http.StatusOK, task)
 }
 
 func (h *TaskHandler) UpdateStatus(w http.ResponseWriter, r *http.Request) {
-	ctx, cancel := context.WithTimeout(r.Context(), 5*time.Second)
+	ctx, cancel := deadline.Budget(r.Context(), 5*time.Second)
 	defer cancel()
 
 	userID, ok := middleware.GetUserIDFromContext(ctx)
@@ -451,9 +909,7 @@ func (h *TaskHandler) UpdateStatus(w http.ResponseWriter, r *http.Request) {
 	r.Body = http.MaxBytesReader(w, r.Body, 1<<20)
 	defer r.Body.Close()
 
-	var in struct {
-		Status store.TaskStatus `json:"status"`
-	}
+	var in types.UpdateStatusRequest
 	dec := json.NewDecoder(r.Body)
 	dec.DisallowUnknownFields()
 
@@ -467,7 +923,7 @@ func (h *TaskHandler) UpdateStatus(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	task, err := h.getTaskByID(ctx, taskID)
+	task, err := h.getTaskByID(ctx, taskID, userID)
 	if err != nil {
 		if errors.Is(err, store.ErrTaskNotFound) {
 			logger.Info(ctx, "update status: task not found", "task_id", taskID)
@@ -487,20 +943,48 @@ func (h *TaskHandler) UpdateStatus(w http.ResponseWriter, r *http.Request) {
 		helper.RespondError(w, r, apperror.Forbidden("only assignee can update task status"))
 		return
 	}
+	// checked before the approval flow too, so a stale request is not held
+	version, ok := checkIfVersion(w, r, task, in.Version)
+	if !ok {
+		return
+	}
+
+	def, err := h.teamWorkflow(ctx, task.TeamID)
+	if err != nil {
+		logger.Error(ctx, "update status: load team workflow failed", "err", err)
+		helper.RespondError(w, r, apperror.InternalError("internal error", err))
+		return
+	}
+	if def != nil {
+		helper.RespondError(w, r, apperror.Conflict("team uses a custom workflow; use PATCH /tasks/{id}/state"))
+		return
+	}
 
-	updatedTask, err := h.taskStore.UpdateStatus(ctx, taskID, in.Status, time.Now().UTC())
+	if handled := h.routeThroughApproval(ctx, w, r, task, in.Status); handled {
+		return
+	}
+
+	now := time.Now().UTC()
+	updatedTask, err := h.taskStore.UpdateStatus(ctx, taskID, in.Status, version, &userID, now)
 	if err != nil {
+		if errors.Is(err, store.ErrVersionConflict) {
+			helper.RespondError(w, r, apperror.Conflict(err.Error()))
+			return
+		}
 		logger.Error(ctx, "update status: store update failed", "err", err)
 		helper.RespondError(w, r, apperror.InternalError("internal error", err))
 		return
 	}
+	h.publishStatusChanged(ctx, updatedTask, task.Status, userID, now)
 
 	logger.Info(ctx, "task status updated", "task_id", taskID, "status", in.Status)
+	setTaskETag(w, updatedTask)
+	h.localize(ctx, updatedTask)
 	helper.RespondJSON(w, r, http.StatusOK, updatedTask)
 }
 
 func (h *TaskHandler) DeleteTask(w http.ResponseWriter, r *http.Request) {
-	ctx, cancel := context.WithTimeout(r.Context(), 5*time.Second)
+	ctx, cancel := deadline.Budget(r.Context(), 5*time.Second)
 	defer cancel()
 
 	userID, ok := middleware.GetUserIDFromContext(ctx)
@@ -516,7 +1000,7 @@ func (h *TaskHandler) DeleteTask(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	task, err := h.getTaskByID(ctx, taskID)
+	task, err := h.getTaskByID(ctx, taskID, userID)
 	if err != nil {
 		if errors.Is(err, store.ErrTaskNotFound) {
 			helper.RespondError(w, r, apperror.NotFound("task not found"))
@@ -536,11 +1020,15 @@ func (h *TaskHandler) DeleteTask(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if err := h.taskStore.DeleteTask(ctx, taskID); err != nil {
+	if err := h.taskStore.DeleteTask(ctx, taskID, &userID, time.Now().UTC()); err != nil {
 		if errors.Is(err, store.ErrTaskNotFound) {
 			helper.RespondError(w, r, apperror.NotFound("task not found"))
 			return
 		}
+		if errors.Is(err, store.ErrLegalHold) {
+			helper.RespondError(w, r, apperror.Conflict("task is under legal hold and cannot be deleted"))
+			return
+		}
 		logger.Error(ctx, "delete task: store delete failed", "err", err)
 		helper.RespondError(w, r, apperror.InternalError("internal error", err))
 		return
@@ -551,7 +1039,7 @@ func (h *TaskHandler) DeleteTask(w http.ResponseWriter, r *http.Request) {
 }
 
 func (h *TaskHandler) HandlePatchTask(w http.ResponseWriter, r *http.Request) {
-	ctx, cancel := context.WithTimeout(r.Context(), 5*time.Second)
+	ctx, cancel := deadline.Budget(r.Context(), 5*time.Second)
 	defer cancel()
 
 	logger.Info(ctx, "patch task: start")
@@ -569,7 +1057,7 @@ func (h *TaskHandler) HandlePatchTask(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	task, err := h.getTaskByID(ctx, taskID)
+	task, err := h.getTaskByID(ctx, taskID, userID)
 	if err != nil {
 		if errors.Is(err, store.ErrTaskNotFound) {
 			helper.RespondError(w, r, apperror.NotFound("task not found"))
@@ -592,7 +1080,7 @@ func (h *TaskHandler) HandlePatchTask(w http.ResponseWriter, r *http.Request) {
 	r.Body = http.MaxBytesReader(w, r.Body, 1<<20)
 	defer r.Body.Close()
 
-	var in patchTaskInput
+	var in types.PatchTaskRequest
 	dec := json.NewDecoder(r.Body)
 	dec.DisallowUnknownFields()
 
@@ -602,21 +1090,90 @@ func (h *TaskHandler) HandlePatchTask(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if in.Title == nil && in.Description == nil && in.DueAt == nil {
-		helper.RespondError(w, r, apperror.BadRequest("at least one of title, description, or due_at must be provided"))
+	if in.DueLocal != nil {
+		if in.DueAt != nil {
+			helper.RespondError(w, r, apperror.BadRequest("due_at and due_local cannot both be set"))
+			return
+		}
+		dueAt, err := h.parseDueLocal(ctx, userID, *in.DueLocal)
+		if err != nil {
+			helper.RespondError(w, r, apperror.BadRequest(err.Error()))
+			return
+		}
+		in.DueAt = &dueAt
+	}
+
+	if in.Title == nil && in.Description == nil && in.StartAt == nil && !in.ClearStartAt && in.DueAt == nil && in.Priority == nil && in.EstimateHours == nil && in.ProjectID == nil && !in.ClearProject && len(in.CustomFields) == 0 {
+		helper.RespondError(w, r, apperror.BadRequest("at least one of title, description, start_at, clear_start_at, due_at, priority, estimate_hours, project_id, clear_project or custom_fields must be provided"))
+		return
+	}
+	if in.Priority != nil && !labelrules.ValidPriority(*in.Priority) {
+		helper.RespondError(w, r, apperror.BadRequest("priority must be low, normal, high or urgent"))
 		return
 	}
+	version, ok := checkIfVersion(w, r, task, in.Version)
+	if !ok {
+		return
+	}
+	var customFields map[string]any
+	if len(in.CustomFields) > 0 {
+		if customFields, ok = h.checkCustomFields(ctx, w, r, task.TeamID, in.CustomFields, true); !ok {
+			return
+		}
+	}
+
+	var notice *types.DueDateNotice
+	if in.DueAt != nil {
+		dueAt, n, err := h.applyDueDatePolicy(ctx, task.TeamID, *in.DueAt)
+		if err != nil {
+			logger.Error(ctx, "patch task: load team calendar failed", "err", err)
+			helper.RespondError(w, r, apperror.InternalError("internal error", err))
+			return
+		}
+		in.DueAt, notice = &dueAt, n
+	}
+
+	// Edited text is run through the label rules again; an explicit
+	// priority in the same request wins over theirs
+	var outcome labelrules.Outcome
+	if in.Title != nil || in.Description != nil {
+		title, description := task.Title, task.Description
+		if in.Title != nil {
+			title = strings.TrimSpace(*in.Title)
+		}
+		if in.Description != nil {
+			description = in.Description
+		}
+		outcome, err = h.evaluateLabelRules(ctx, task.TeamID, title, description)
+		if err != nil {
+			logger.Error(ctx, "patch task: label rules failed", "err", err)
+			helper.RespondError(w, r, apperror.InternalError("internal error", err))
+			return
+		}
+		if in.Priority == nil {
+			in.Priority = outcome.Priority
+		}
+	}
 
 	now := time.Now().UTC()
 	updatedTask, err := h.taskStore.UpdateDetails(ctx, taskID, store.TaskUpdate{
-		Title:       in.Title,
-		Description: in.Description,
-		DueAt:       in.DueAt,
-	}, now)
+		Title:         in.Title,
+		Description:   in.Description,
+		StartAt:       in.StartAt,
+		ClearStartAt:  in.ClearStartAt,
+		DueAt:         in.DueAt,
+		Priority:      in.Priority,
+		EstimateHours: in.EstimateHours,
+		ProjectID:     in.ProjectID,
+		ClearProject:  in.ClearProject,
+		CustomFields:  customFields,
+	}, version, &userID, now)
 	if err != nil {
 		switch {
 		case errors.Is(err, store.ErrTaskNotFound):
 			helper.RespondError(w, r, apperror.NotFound("task not found"))
+		case errors.Is(err, store.ErrVersionConflict):
+			helper.RespondError(w, r, apperror.Conflict(err.Error()))
 		case errors.Is(err, store.ErrInvalidInput):
 			helper.RespondError(w, r, apperror.BadRequest(err.Error()))
 		default:
@@ -626,14 +1183,40 @@ func (h *TaskHandler) HandlePatchTask(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if err := h.addRuleLabels(ctx, updatedTask, outcome, userID, now); err != nil {
+		logger.Error(ctx, "patch task: attach rule labels failed", "err", err)
+		helper.RespondError(w, r, apperror.InternalError("internal error", err))
+		return
+	}
+
+	updatedTask.DueDateNotice = notice
 	logger.Info(ctx, "patch task: success", "task_id", taskID)
+	setTaskETag(w, updatedTask)
+	h.localize(ctx, updatedTask)
 	helper.RespondJSON(w, r, http.StatusOK, updatedTask)
 }
 
 // ===== helpers =====
 
+// checkCustomFields checks task values against the team's custom fields;
+// partial is set for edits, see customfieldstore.CheckValues.
+func (h *TaskHandler) checkCustomFields(ctx context.Context, w http.ResponseWriter, r *http.Request, teamID uuid.UUID, values map[string]any, partial bool) (map[string]any, bool) {
+	fields, err := h.customFieldStore.List(ctx, teamID)
+	if err != nil {
+		logger.Error(ctx, "custom fields: list failed", "err", err)
+		helper.RespondError(w, r, apperror.InternalError("internal error", err))
+		return nil, false
+	}
+	out, err := customfieldstore.CheckValues(fields, values, partial)
+	if err != nil {
+		helper.RespondError(w, r, apperror.BadRequest(err.Error()))
+		return nil, false
+	}
+	return out, true
+}
+
 func (h *TaskHandler) listTasks(w http.ResponseWriter, r *http.Request, asReporter bool) {
-	ctx, cancel := context.WithTimeout(r.Context(), 5*time.Second)
+	ctx, cancel := deadline.Budget(r.Context(), 5*time.Second)
 	defer cancel()
 
 	userID, ok := middleware.GetUserIDFromContext(ctx)
@@ -644,31 +1227,23 @@ func (h *TaskHandler) listTasks(w http.ResponseWriter, r *http.Request, asReport
 
 	logger.Info(ctx, "listing tasks", "user_id", userID, "as_reporter", asReporter)
 
-	var (
-		tasks []store.Task
-		err   error
-	)
-
+	scope := store.TaskScope{AssigneeID: userID, ByDueDate: true}
 	if asReporter {
-		tasks, err = h.taskStore.GetTasksByReporterID(ctx, userID)
-	} else {
-		tasks, err = h.taskStore.GetTasksByAssigneeID(ctx, userID)
+		scope = store.TaskScope{ReporterID: userID}
 	}
-
-	if err != nil {
-		logger.Error(ctx, "list tasks: store query failed", "err", err)
-		helper.RespondError(w, r, apperror.InternalError("internal error", err))
+	tasks, pg, ok := h.listWithFields(ctx, w, r, "list tasks", scope, func(filter store.TaskFilter, sort store.SortSpec, page store.Page) ([]store.Task, store.PageInfo, error) {
+		if asReporter {
+			return h.taskStore.GetTasksByReporterID(ctx, userID, filter, sort, page)
+		}
+		return h.taskStore.GetTasksByAssigneeID(ctx, userID, filter, sort, page)
+	})
+	if !ok {
 		return
 	}
 
-	logger.Info(ctx, "list tasks: success", "user_id", userID, "count", len(tasks))
+	logger.Info(ctx, "list tasks: success", "user_id", userID, "page", pg.Page, "more", pg.NextCursor != "")
 
-	response := map[string]any{
-		"user_id":     userID,
-		"as_reporter": asReporter,
-		"tasks":       tasks,
-	}
-	helper.RespondJSON(w, r, http.StatusOK, response)
+	respondTaskList(w, r, types.TaskListMeta{UserID: userID, AsReporter: &asReporter}, pg, tasks)
 }
 
 func parseTaskID(r *http.Request) (uuid.UUID, error) {
@@ -680,8 +1255,43 @@ func parseTaskID(r *http.Request) (uuid.UUID, error) {
 	return id, nil
 }
 
-func (h *TaskHandler) getTaskByID(ctx context.Context, id uuid.UUID) (*store.Task, error) {
-	task, err := h.taskStore.GetTaskByID(ctx, id)
+// checkIfVersion reads the version a change was made against, from the
+// If-Match header or the body, and answers 409 right away when the task has
+// moved on. The store checks again under its row lock. 0 means the client
+// sent neither and the change applies to whatever version is current.
+func checkIfVersion(w http.ResponseWriter, r *http.Request, task *store.Task, body *int64) (int64, bool) {
+	var version int64
+	if h := strings.TrimSpace(r.Header.Get("If-Match")); h != "" && h != "*" {
+		n, err := strconv.ParseInt(strings.Trim(strings.TrimPrefix(h, "W/"), `"`), 10, 64)
+		if err != nil || n < 1 {
+			helper.RespondError(w, r, apperror.BadRequest(`If-Match must be a task version like "3"`))
+			return 0, false
+		}
+		version = n
+	}
+	if body != nil {
+		if *body < 1 || (version != 0 && version != *body) {
+			helper.RespondError(w, r, apperror.BadRequest("version must be positive and agree with If-Match"))
+			return 0, false
+		}
+		version = *body
+	}
+	if version != 0 && version != task.Version {
+		helper.RespondError(w, r, apperror.Conflict(fmt.Sprintf("%s: task is at version %d", store.ErrVersionConflict, task.Version)))
+		return 0, false
+	}
+	return version, true
+}
+
+// setTaskETag lets clients echo the version back as If-Match.
+func setTaskETag(w http.ResponseWriter, task *store.Task) {
+	w.Header().Set("ETag", `"`+strconv.FormatInt(task.Version, 10)+`"`)
+}
+
+// getTaskByID only returns tasks the viewer may see; hidden private tasks
+// surface as ErrTaskNotFound so their existence is not leaked.
+func (h *TaskHandler) getTaskByID(ctx context.Context, id, viewerID uuid.UUID) (*store.Task, error) {
+	task, err := h.taskStore.GetVisibleTaskByID(ctx, id, viewerID)
 	if err != nil {
 		if errors.Is(err, store.ErrTaskNotFound) {
 			return nil, store.ErrTaskNotFound
@@ -691,7 +1301,37 @@ func (h *TaskHandler) getTaskByID(ctx context.Context, id uuid.UUID) (*store.Tas
 	return task, nil
 }
 
-func taskInputValidation(in input) error {
+// memberTask reads a task the caller can see and checks they are in its
+// team, answering forbidden otherwise. It returns false when it has
+// already written the response.
+func (h *TaskHandler) memberTask(ctx context.Context, w http.ResponseWriter, r *http.Request, taskID, userID uuid.UUID, op, forbidden string) (*store.Task, bool) {
+	task, err := h.getTaskByID(ctx, taskID, userID)
+	if err != nil {
+		if errors.Is(err, store.ErrTaskNotFound) {
+			helper.RespondError(w, r, apperror.NotFound("task not found"))
+			return nil, false
+		}
+		logger.Error(ctx, op+": failed to get task", "err", err)
+		helper.RespondError(w, r, apperror.InternalError("internal error", err))
+		return nil, false
+	}
+
+	isMember, err := h.teamStore.IsMember(ctx, task.TeamID, userID)
+	if err != nil {
+		logger.Error(ctx, op+": membership check failed", "err", err)
+		helper.RespondError(w, r, apperror.InternalError("internal error", err))
+		return nil, false
+	}
+	if !isMember {
+		helper.RespondError(w, r, apperror.Forbidden(forbidden))
+		return nil, false
+	}
+	return task, true
+}
+
+const maxTaskLabels = 20
+
+func taskInputValidation(in types.CreateTaskRequest) error {
 	title := strings.TrimSpace(in.Title)
 	if len(title) < 1 || len(title) > 100 {
 		return errors.New("title length must be between 1 and 100")
@@ -702,6 +1342,24 @@ func taskInputValidation(in input) error {
 	if in.DueAt.Before(time.Now().UTC().Add(8 * time.Hour)) {
 		return errors.New("due_at must be at least 8 hours from now")
 	}
+	// a due date policy only moves due_at later, so this still holds after
+	if in.StartAt != nil && !in.StartAt.Before(in.DueAt) {
+		return errors.New("start_at must be before due_at")
+	}
+	if len(in.Labels) > maxTaskLabels {
+		return fmt.Errorf("at most %d labels", maxTaskLabels)
+	}
+	for _, l := range in.Labels {
+		if len(strings.TrimSpace(l)) > labelstore.MaxNameLength {
+			return fmt.Errorf("labels must be at most %d chars", labelstore.MaxNameLength)
+		}
+	}
+	if in.Priority != nil && !labelrules.ValidPriority(*in.Priority) {
+		return errors.New("priority must be low, normal, high or urgent")
+	}
+	if in.EstimateHours != nil && (*in.EstimateHours <= 0 || *in.EstimateHours > store.MaxEstimateHours) {
+		return fmt.Errorf("estimate_hours must be above 0 and at most %d", store.MaxEstimateHours)
+	}
 	return nil
 }
 
@@ -713,9 +1371,3 @@ func isValidStatus(status store.TaskStatus) bool {
 		return false
 	}
 }
-
-type patchTaskInput struct {
-	Title       *string    `json:"title"`
-	Description *string    `json:"description"`
-	DueAt       *time.Time `json:"due_at"`
-}
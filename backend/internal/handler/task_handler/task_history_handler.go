@@ -0,0 +1,105 @@
+package handler
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/diagnosis/interactive-todo/api/types"
+	"github.com/diagnosis/interactive-todo/internal/apperror"
+	"github.com/diagnosis/interactive-todo/internal/deadline"
+	"github.com/diagnosis/interactive-todo/internal/helper"
+	"github.com/diagnosis/interactive-todo/internal/logger"
+	middleware "github.com/diagnosis/interactive-todo/internal/middleware/auth"
+	store "github.com/diagnosis/interactive-todo/internal/store/tasks"
+	"github.com/google/uuid"
+)
+
+const (
+	defaultHistoryLimit = 100
+	maxHistoryLimit     = 500
+)
+
+// TaskHistory lists who changed what on a task, newest first. Team members
+// who can see the task can read its history; once the task is deleted,
+// only team owners/admins can, and never for a task that was private.
+func (h *TaskHandler) TaskHistory(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := deadline.Budget(r.Context(), 5*time.Second)
+	defer cancel()
+
+	userID, ok := middleware.GetUserIDFromContext(ctx)
+	if !ok {
+		helper.RespondError(w, r, apperror.Unauthorized("unauthorized"))
+		return
+	}
+
+	id, err := parseTaskID(r)
+	if err != nil {
+		helper.RespondError(w, r, apperror.BadRequest("invalid task id"))
+		return
+	}
+
+	limit := defaultHistoryLimit
+	if v := r.URL.Query().Get("limit"); v != "" {
+		limit, err = strconv.Atoi(v)
+		if err != nil || limit < 1 || limit > maxHistoryLimit {
+			helper.RespondError(w, r, apperror.BadRequest(fmt.Sprintf("limit must be between 1 and %d", maxHistoryLimit)))
+			return
+		}
+	}
+
+	var teamID uuid.UUID
+	admin := false
+	task, err := h.getTaskByID(ctx, id, userID)
+	switch {
+	case err == nil:
+		teamID = task.TeamID
+	case errors.Is(err, store.ErrTaskNotFound):
+		// a hidden private task has no deleted event, so it stays not found
+		var private bool
+		teamID, private, err = h.taskStore.DeletedTask(ctx, id)
+		if errors.Is(err, store.ErrTaskNotFound) || (err == nil && private) {
+			helper.RespondError(w, r, apperror.NotFound("task not found"))
+			return
+		}
+		if err != nil {
+			logger.Error(ctx, "task history: deleted task lookup failed", "task_id", id, "err", err)
+			helper.RespondError(w, r, apperror.InternalError("internal error", err))
+			return
+		}
+		admin = true
+	default:
+		logger.Error(ctx, "task history: get task failed", "task_id", id, "err", err)
+		helper.RespondError(w, r, apperror.InternalError("internal error", err))
+		return
+	}
+
+	allowed, err := h.teamStore.IsMember(ctx, teamID, userID)
+	if err == nil && admin {
+		allowed, err = h.teamStore.IsOwnerOrAdmin(ctx, teamID, userID)
+	}
+	if err != nil {
+		logger.Error(ctx, "task history: membership check failed", "err", err)
+		helper.RespondError(w, r, apperror.InternalError("internal error", err))
+		return
+	}
+	if !allowed {
+		// deleted tasks are not confirmed to people who could not see them
+		if admin {
+			helper.RespondError(w, r, apperror.NotFound("task not found"))
+			return
+		}
+		helper.RespondError(w, r, apperror.Forbidden("forbidden"))
+		return
+	}
+
+	events, err := h.taskStore.ListEvents(ctx, id, limit)
+	if err != nil {
+		logger.Error(ctx, "task history: list events failed", "task_id", id, "err", err)
+		helper.RespondError(w, r, apperror.InternalError("internal error", err))
+		return
+	}
+	helper.RespondJSON(w, r, http.StatusOK, types.TaskHistoryResponse{TaskID: id, Events: events})
+}
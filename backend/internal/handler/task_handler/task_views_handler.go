@@ -0,0 +1,111 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/diagnosis/interactive-todo/api/types"
+	"github.com/diagnosis/interactive-todo/internal/apperror"
+	"github.com/diagnosis/interactive-todo/internal/deadline"
+	"github.com/diagnosis/interactive-todo/internal/helper"
+	"github.com/diagnosis/interactive-todo/internal/logger"
+	middleware "github.com/diagnosis/interactive-todo/internal/middleware/auth"
+	auditstore "github.com/diagnosis/interactive-todo/internal/store/audit"
+	store "github.com/diagnosis/interactive-todo/internal/store/tasks"
+	"github.com/google/uuid"
+)
+
+// recordTaskView writes a task.viewed audit entry for tasks of confidential
+// teams. Failures are logged only; they never block reading the task.
+func (h *TaskHandler) recordTaskView(ctx context.Context, r *http.Request, task *store.Task, viewerID uuid.UUID) {
+	settings, err := h.teamStore.GetSettings(ctx, task.TeamID)
+	if err != nil {
+		logger.Error(ctx, "record task view: get settings failed", "task_id", task.ID, "err", err)
+		return
+	}
+	if !settings.Confidential {
+		return
+	}
+
+	if err := h.auditStore.Record(ctx, auditstore.Entry{
+		ActorID:    &viewerID,
+		Action:     auditstore.ActionTaskViewed,
+		TargetType: auditstore.TargetTask,
+		TargetID:   &task.ID,
+		TeamID:     &task.TeamID,
+		IP:         helper.GetClientIP(r),
+		CreatedAt:  time.Now().UTC(),
+	}); err != nil {
+		logger.Error(ctx, "record task view: audit failed", "task_id", task.ID, "err", err)
+	}
+}
+
+// ListTaskViews shows the team owner who opened a task and when (?limit=,
+// default 100, max 500). Only confidential teams record views.
+func (h *TaskHandler) ListTaskViews(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := deadline.Budget(r.Context(), 5*time.Second)
+	defer cancel()
+
+	userID, ok := middleware.GetUserIDFromContext(ctx)
+	if !ok {
+		helper.RespondError(w, r, apperror.Unauthorized("unauthorized"))
+		return
+	}
+
+	taskID, err := parseTaskID(r)
+	if err != nil {
+		logger.Error(ctx, "list task views: invalid task id", "err", err)
+		helper.RespondError(w, r, apperror.BadRequest("invalid task id"))
+		return
+	}
+
+	limit := 100
+	if v := r.URL.Query().Get("limit"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 || n > 500 {
+			helper.RespondError(w, r, apperror.BadRequest("limit must be between 1 and 500"))
+			return
+		}
+		limit = n
+	}
+
+	// owners see views of private tasks too, so look the task up unfiltered
+	task, err := h.taskStore.GetTaskByID(ctx, taskID)
+	if err != nil {
+		if errors.Is(err, store.ErrTaskNotFound) {
+			helper.RespondError(w, r, apperror.NotFound("task not found"))
+			return
+		}
+		logger.Error(ctx, "list task views: failed to get task", "err", err)
+		helper.RespondError(w, r, apperror.InternalError("internal error", err))
+		return
+	}
+
+	isOwner, err := h.teamStore.IsOwner(ctx, task.TeamID, userID)
+	if err != nil {
+		logger.Error(ctx, "list task views: role check failed", "err", err)
+		helper.RespondError(w, r, apperror.InternalError("internal error", err))
+		return
+	}
+	if !isOwner {
+		helper.RespondError(w, r, apperror.NotFound("task not found"))
+		return
+	}
+
+	entries, err := h.auditStore.ListForTarget(ctx, auditstore.ActionTaskViewed, auditstore.TargetTask, task.ID, limit)
+	if err != nil {
+		logger.Error(ctx, "list task views: store query failed", "err", err)
+		helper.RespondError(w, r, apperror.InternalError("internal error", err))
+		return
+	}
+
+	views := make([]types.TaskView, 0, len(entries))
+	for _, e := range entries {
+		views = append(views, types.TaskView{ViewerID: e.ActorID, IP: e.IP, ViewedAt: e.CreatedAt})
+	}
+
+	helper.RespondJSON(w, r, http.StatusOK, types.TaskViewsResponse{TaskID: task.ID, Views: views})
+}
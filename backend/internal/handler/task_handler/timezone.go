@@ -0,0 +1,87 @@
+package handler
+
+import (
+	"context"
+	"time"
+
+	"github.com/diagnosis/interactive-todo/internal/localtime"
+	"github.com/diagnosis/interactive-todo/internal/logger"
+	middleware "github.com/diagnosis/interactive-todo/internal/middleware/auth"
+	store "github.com/diagnosis/interactive-todo/internal/store/tasks"
+	"github.com/google/uuid"
+)
+
+// Due dates are stored in UTC. The caller's timezone, set on their
+// profile, decides how due_local is read and the offset start_at and
+// due_at are written with; the instants never change.
+
+// userLocation returns the user's timezone. Failing to read it is not
+// worth failing the request over, so it falls back to UTC.
+func (h *TaskHandler) userLocation(ctx context.Context, userID uuid.UUID) *time.Location {
+	name, err := h.userStore.Timezone(ctx, userID)
+	if err != nil {
+		logger.Warn(ctx, "user timezone lookup failed", "user_id", userID, "err", err)
+		return time.UTC
+	}
+	loc, err := localtime.Load(name)
+	if err != nil {
+		logger.Warn(ctx, "user timezone unknown", "user_id", userID, "timezone", name)
+		return time.UTC
+	}
+	return loc
+}
+
+// parseDueLocal reads due_local, a wall clock time in the user's timezone.
+func (h *TaskHandler) parseDueLocal(ctx context.Context, userID uuid.UUID, s string) (time.Time, error) {
+	t, err := localtime.Parse(s, h.userLocation(ctx, userID))
+	if err != nil {
+		return time.Time{}, err
+	}
+	return t.UTC(), nil
+}
+
+// localize writes the tasks' start and due dates in the caller's timezone.
+func (h *TaskHandler) localize(ctx context.Context, tasks ...*store.Task) {
+	userID, ok := middleware.GetUserIDFromContext(ctx)
+	if !ok || len(tasks) == 0 {
+		return
+	}
+	loc := h.userLocation(ctx, userID)
+	if loc == time.UTC {
+		return
+	}
+	for _, t := range tasks {
+		if t == nil {
+			continue
+		}
+		t.StartAt = inLocation(t.StartAt, loc)
+		t.DueAt = t.DueAt.In(loc)
+	}
+}
+
+// localizeFields is localize for tasks listed with ?fields=.
+func (h *TaskHandler) localizeFields(ctx context.Context, rows []map[string]any) {
+	userID, ok := middleware.GetUserIDFromContext(ctx)
+	if !ok || len(rows) == 0 {
+		return
+	}
+	loc := h.userLocation(ctx, userID)
+	if loc == time.UTC {
+		return
+	}
+	for _, row := range rows {
+		for _, key := range []string{"start_at", "due_at"} {
+			if p, ok := row[key].(**time.Time); ok {
+				*p = inLocation(*p, loc)
+			}
+		}
+	}
+}
+
+func inLocation(t *time.Time, loc *time.Location) *time.Time {
+	if t == nil {
+		return nil
+	}
+	in := t.In(loc)
+	return &in
+}
@@ -0,0 +1,155 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+	"strconv"
+	"time"
+
+	"github.com/diagnosis/interactive-todo/api/types"
+	"github.com/diagnosis/interactive-todo/internal/apperror"
+	"github.com/diagnosis/interactive-todo/internal/deadline"
+	"github.com/diagnosis/interactive-todo/internal/helper"
+	"github.com/diagnosis/interactive-todo/internal/logger"
+	middleware "github.com/diagnosis/interactive-todo/internal/middleware/auth"
+	store "github.com/diagnosis/interactive-todo/internal/store/tasks"
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+)
+
+const (
+	defaultTrashLimit = 100
+	maxTrashLimit     = 500
+)
+
+// ListTrash lists the team's deleted tasks that can still be restored,
+// most recently deleted first. Private tasks are listed only for the
+// people who could see them.
+func (h *TaskHandler) ListTrash(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := deadline.Budget(r.Context(), 5*time.Second)
+	defer cancel()
+
+	userID, ok := middleware.GetUserIDFromContext(ctx)
+	if !ok {
+		helper.RespondError(w, r, apperror.Unauthorized("authentication required"))
+		return
+	}
+
+	teamID, err := uuid.Parse(chi.URLParam(r, "team_id"))
+	if err != nil {
+		helper.RespondError(w, r, apperror.BadRequest("invalid team id"))
+		return
+	}
+
+	limit := defaultTrashLimit
+	if v := r.URL.Query().Get("limit"); v != "" {
+		limit, err = strconv.Atoi(v)
+		if err != nil || limit < 1 || limit > maxTrashLimit {
+			helper.RespondError(w, r, apperror.BadRequest(fmt.Sprintf("limit must be between 1 and %d", maxTrashLimit)))
+			return
+		}
+	}
+
+	isMember, err := h.teamStore.IsMember(ctx, teamID, userID)
+	if err != nil {
+		logger.Error(ctx, "list trash: membership check failed", "err", err)
+		helper.RespondError(w, r, apperror.InternalError("internal error", err))
+		return
+	}
+	if !isMember {
+		helper.RespondError(w, r, apperror.Forbidden("only team members can view team tasks"))
+		return
+	}
+
+	tasks, err := h.taskStore.ListTrash(ctx, teamID, userID, limit)
+	if err != nil {
+		logger.Error(ctx, "list trash: store query failed", "err", err)
+		helper.RespondError(w, r, apperror.InternalError("internal error", err))
+		return
+	}
+	helper.RespondJSON(w, r, http.StatusOK, types.TrashListResponse{TeamID: teamID, Tasks: tasks})
+}
+
+// RestoreTask takes a task out of the trash. The reporter, who could
+// delete it, and team owners/admins can restore it.
+func (h *TaskHandler) RestoreTask(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := deadline.Budget(r.Context(), 5*time.Second)
+	defer cancel()
+
+	userID, ok := middleware.GetUserIDFromContext(ctx)
+	if !ok {
+		helper.RespondError(w, r, apperror.Unauthorized("authentication required"))
+		return
+	}
+
+	taskID, err := parseTaskID(r)
+	if err != nil {
+		helper.RespondError(w, r, apperror.BadRequest("invalid task id"))
+		return
+	}
+
+	trashed, err := h.taskStore.GetTrashedTask(ctx, taskID)
+	if err == nil {
+		var visible bool
+		if visible, err = h.canSeeTrashed(ctx, &trashed.Task, userID); err == nil && !visible {
+			err = store.ErrTaskNotFound
+		}
+	}
+	if err != nil {
+		if errors.Is(err, store.ErrTaskNotFound) {
+			helper.RespondError(w, r, apperror.NotFound("task not in trash"))
+			return
+		}
+		logger.Error(ctx, "restore task: get trashed task failed", "err", err)
+		helper.RespondError(w, r, apperror.InternalError("internal error", err))
+		return
+	}
+
+	allowed := userID == trashed.ReporterID
+	if !allowed {
+		if allowed, err = h.teamStore.IsOwnerOrAdmin(ctx, trashed.TeamID, userID); err != nil {
+			logger.Error(ctx, "restore task: role check failed", "err", err)
+			helper.RespondError(w, r, apperror.InternalError("internal error", err))
+			return
+		}
+	}
+	if !allowed {
+		helper.RespondError(w, r, apperror.Forbidden("only the task creator or team owner/admin can restore"))
+		return
+	}
+
+	task, err := h.taskStore.RestoreTask(ctx, taskID, &userID, time.Now().UTC())
+	if err != nil {
+		if errors.Is(err, store.ErrTaskNotFound) {
+			helper.RespondError(w, r, apperror.NotFound("task not in trash"))
+			return
+		}
+		logger.Error(ctx, "restore task: store restore failed", "err", err)
+		helper.RespondError(w, r, apperror.InternalError("internal error", err))
+		return
+	}
+
+	logger.Info(ctx, "task restored", "task_id", taskID, "user_id", userID)
+	h.localize(ctx, task)
+	helper.RespondJSON(w, r, http.StatusOK, task)
+}
+
+// canSeeTrashed applies the rules of GetVisibleTaskByID, and team
+// membership, to a task in the trash.
+func (h *TaskHandler) canSeeTrashed(ctx context.Context, t *store.Task, userID uuid.UUID) (bool, error) {
+	isMember, err := h.teamStore.IsMember(ctx, t.TeamID, userID)
+	if err != nil || !isMember {
+		return false, err
+	}
+	if !t.Private || userID == t.ReporterID || userID == t.AssigneeID {
+		return true, nil
+	}
+	viewers, err := h.taskStore.ListViewers(ctx, t.ID)
+	if err != nil {
+		return false, err
+	}
+	return slices.Contains(viewers, userID), nil
+}
@@ -0,0 +1,256 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/diagnosis/interactive-todo/api/types"
+	"github.com/diagnosis/interactive-todo/internal/apperror"
+	"github.com/diagnosis/interactive-todo/internal/deadline"
+	"github.com/diagnosis/interactive-todo/internal/helper"
+	"github.com/diagnosis/interactive-todo/internal/logger"
+	middleware "github.com/diagnosis/interactive-todo/internal/middleware/auth"
+	store "github.com/diagnosis/interactive-todo/internal/store/tasks"
+	triagestore "github.com/diagnosis/interactive-todo/internal/store/triage"
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+)
+
+// ListTriage shows the team's triage inbox (?status=pending|accepted|rejected,
+// default pending) to team owners/admins.
+func (h *TaskHandler) ListTriage(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := deadline.Budget(r.Context(), 5*time.Second)
+	defer cancel()
+
+	teamID, _, ok := h.requireTriageManager(ctx, w, r)
+	if !ok {
+		return
+	}
+
+	status := triagestore.Status(r.URL.Query().Get("status"))
+	switch status {
+	case "":
+		status = triagestore.StatusPending
+	case triagestore.StatusPending, triagestore.StatusAccepted, triagestore.StatusRejected:
+	default:
+		helper.RespondError(w, r, apperror.BadRequest("status must be pending, accepted or rejected"))
+		return
+	}
+
+	items, err := h.triageStore.ListForTeam(ctx, teamID, status)
+	if err != nil {
+		logger.Error(ctx, "list triage: store query failed", "err", err)
+		helper.RespondError(w, r, apperror.InternalError("internal error", err))
+		return
+	}
+
+	helper.RespondJSON(w, r, http.StatusOK, types.TriageListResponse{TeamID: teamID, Status: status, Items: items})
+}
+
+// AcceptTriage schedules and assigns a pending item, turning it into a task.
+func (h *TaskHandler) AcceptTriage(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := deadline.Budget(r.Context(), 5*time.Second)
+	defer cancel()
+
+	teamID, userID, ok := h.requireTriageManager(ctx, w, r)
+	if !ok {
+		return
+	}
+
+	item, ok := h.teamTriageItem(ctx, w, r, teamID)
+	if !ok {
+		return
+	}
+
+	r.Body = http.MaxBytesReader(w, r.Body, 1<<20)
+	defer r.Body.Close()
+
+	var in struct {
+		AssigneeID       uuid.UUID `json:"assignee_id"`
+		DueAt            time.Time `json:"due_at"`
+		AllowOutOfOffice bool      `json:"allow_out_of_office"`
+	}
+	dec := json.NewDecoder(r.Body)
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&in); err != nil {
+		logger.Error(ctx, "accept triage: bad json", "err", err)
+		helper.RespondError(w, r, apperror.BadRequest("invalid request body"))
+		return
+	}
+
+	now := time.Now().UTC()
+	if in.AssigneeID == uuid.Nil {
+		helper.RespondError(w, r, apperror.BadRequest("assignee_id is required"))
+		return
+	}
+	if !in.DueAt.After(now) {
+		helper.RespondError(w, r, apperror.BadRequest("due_at must be in the future"))
+		return
+	}
+
+	isAssigneeMember, err := h.teamStore.IsMember(ctx, teamID, in.AssigneeID)
+	if err != nil {
+		logger.Error(ctx, "accept triage: assignee membership check failed", "err", err)
+		helper.RespondError(w, r, apperror.InternalError("internal error", err))
+		return
+	}
+	if !isAssigneeMember {
+		helper.RespondError(w, r, apperror.BadRequest("assignee must be a member of the team"))
+		return
+	}
+	if !h.checkOutOfOffice(ctx, w, r, "accept triage", teamID, userID, in.AssigneeID, in.AllowOutOfOffice, now) {
+		return
+	}
+
+	if _, err := h.triageStore.MarkAccepted(ctx, item.ID, userID, now); err != nil {
+		respondTriageError(ctx, w, r, "accept triage", err)
+		return
+	}
+
+	// Anonymous submissions are reported by the manager who accepted them.
+	reporterID := userID
+	if item.SubmittedBy != nil {
+		reporterID = *item.SubmittedBy
+	}
+
+	task, err := h.createTask(ctx, newTask{
+		TeamID:      teamID,
+		Title:       item.Title,
+		Description: item.Description,
+		ReporterID:  reporterID,
+		AssigneeID:  &in.AssigneeID,
+		DueAt:       in.DueAt,
+	}, now)
+	if err != nil {
+		if rerr := h.triageStore.Reopen(ctx, item.ID); rerr != nil {
+			logger.Error(ctx, "accept triage: reopen after failure failed", "err", rerr)
+		}
+		if errors.Is(err, store.ErrInvalidInput) {
+			helper.RespondError(w, r, apperror.BadRequest(err.Error()))
+			return
+		}
+		logger.Error(ctx, "accept triage: create task failed", "err", err)
+		helper.RespondError(w, r, apperror.InternalError("failed to create task", err))
+		return
+	}
+
+	item, err = h.triageStore.LinkTask(ctx, item.ID, task.ID)
+	if err != nil {
+		logger.Error(ctx, "accept triage: link task failed", "err", err)
+		helper.RespondError(w, r, apperror.InternalError("internal error", err))
+		return
+	}
+
+	logger.Info(ctx, "triage item accepted", "triage_item_id", item.ID, "task_id", task.ID, "user_id", userID)
+	helper.RespondJSON(w, r, http.StatusCreated, types.TriageAcceptResponse{Item: item, Task: task})
+}
+
+func (h *TaskHandler) RejectTriage(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := deadline.Budget(r.Context(), 5*time.Second)
+	defer cancel()
+
+	teamID, userID, ok := h.requireTriageManager(ctx, w, r)
+	if !ok {
+		return
+	}
+
+	item, ok := h.teamTriageItem(ctx, w, r, teamID)
+	if !ok {
+		return
+	}
+
+	r.Body = http.MaxBytesReader(w, r.Body, 1<<20)
+	defer r.Body.Close()
+
+	var in struct {
+		Reason string `json:"reason"`
+	}
+	dec := json.NewDecoder(r.Body)
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&in); err != nil {
+		logger.Error(ctx, "reject triage: bad json", "err", err)
+		helper.RespondError(w, r, apperror.BadRequest("invalid request body"))
+		return
+	}
+	in.Reason = strings.TrimSpace(in.Reason)
+	if in.Reason == "" {
+		helper.RespondError(w, r, apperror.BadRequest("reason is required when rejecting"))
+		return
+	}
+	if len(in.Reason) > 1000 {
+		helper.RespondError(w, r, apperror.BadRequest("reason too long (max 1000 chars)"))
+		return
+	}
+
+	item, err := h.triageStore.Reject(ctx, item.ID, userID, in.Reason, time.Now().UTC())
+	if err != nil {
+		respondTriageError(ctx, w, r, "reject triage", err)
+		return
+	}
+
+	logger.Info(ctx, "triage item rejected", "triage_item_id", item.ID, "user_id", userID)
+	helper.RespondJSON(w, r, http.StatusOK, item)
+}
+
+// requireTriageManager allows team owners/admins to work the inbox.
+func (h *TaskHandler) requireTriageManager(ctx context.Context, w http.ResponseWriter, r *http.Request) (uuid.UUID, uuid.UUID, bool) {
+	userID, ok := middleware.GetUserIDFromContext(ctx)
+	if !ok {
+		helper.RespondError(w, r, apperror.Unauthorized("authentication required"))
+		return uuid.Nil, uuid.Nil, false
+	}
+
+	teamID, err := uuid.Parse(chi.URLParam(r, "team_id"))
+	if err != nil {
+		helper.RespondError(w, r, apperror.BadRequest("invalid team id"))
+		return uuid.Nil, uuid.Nil, false
+	}
+
+	isOwnerOrAdmin, err := h.teamStore.IsOwnerOrAdmin(ctx, teamID, userID)
+	if err != nil {
+		logger.Error(ctx, "triage: role check failed", "err", err)
+		helper.RespondError(w, r, apperror.InternalError("internal error", err))
+		return uuid.Nil, uuid.Nil, false
+	}
+	if !isOwnerOrAdmin {
+		helper.RespondError(w, r, apperror.Forbidden("only team owner/admin can manage the triage inbox"))
+		return uuid.Nil, uuid.Nil, false
+	}
+	return teamID, userID, true
+}
+
+func (h *TaskHandler) teamTriageItem(ctx context.Context, w http.ResponseWriter, r *http.Request, teamID uuid.UUID) (*triagestore.Item, bool) {
+	itemID, err := uuid.Parse(chi.URLParam(r, "item_id"))
+	if err != nil {
+		helper.RespondError(w, r, apperror.BadRequest("invalid triage item id"))
+		return nil, false
+	}
+
+	item, err := h.triageStore.Get(ctx, itemID)
+	if err != nil && !errors.Is(err, triagestore.ErrItemNotFound) {
+		logger.Error(ctx, "triage: get item failed", "err", err)
+		helper.RespondError(w, r, apperror.InternalError("internal error", err))
+		return nil, false
+	}
+	if err != nil || item.TeamID != teamID {
+		helper.RespondError(w, r, apperror.NotFound("triage item not found"))
+		return nil, false
+	}
+	return item, true
+}
+
+func respondTriageError(ctx context.Context, w http.ResponseWriter, r *http.Request, op string, err error) {
+	switch {
+	case errors.Is(err, triagestore.ErrItemNotPending):
+		helper.RespondError(w, r, apperror.Conflict("triage item already decided"))
+	case errors.Is(err, triagestore.ErrItemNotFound):
+		helper.RespondError(w, r, apperror.NotFound("triage item not found"))
+	default:
+		logger.Error(ctx, op+": store failed", "err", err)
+		helper.RespondError(w, r, apperror.InternalError("internal error", err))
+	}
+}
@@ -0,0 +1,175 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/diagnosis/interactive-todo/api/types"
+	"github.com/diagnosis/interactive-todo/internal/apperror"
+	"github.com/diagnosis/interactive-todo/internal/deadline"
+	"github.com/diagnosis/interactive-todo/internal/helper"
+	"github.com/diagnosis/interactive-todo/internal/logger"
+	middleware "github.com/diagnosis/interactive-todo/internal/middleware/auth"
+	store "github.com/diagnosis/interactive-todo/internal/store/tasks"
+	"github.com/google/uuid"
+)
+
+const maxTaskViewers = 50
+
+// SetTaskVisibility lets the reporter restrict a task to reporter, assignee
+// and the listed viewers, or open it back up to the whole team.
+// Omitting viewer_ids keeps the current viewer list.
+func (h *TaskHandler) SetTaskVisibility(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := deadline.Budget(r.Context(), 5*time.Second)
+	defer cancel()
+
+	userID, ok := middleware.GetUserIDFromContext(ctx)
+	if !ok {
+		helper.RespondError(w, r, apperror.Unauthorized("unauthorized"))
+		return
+	}
+
+	taskID, err := parseTaskID(r)
+	if err != nil {
+		logger.Error(ctx, "set visibility: invalid task id", "err", err)
+		helper.RespondError(w, r, apperror.BadRequest("invalid task id"))
+		return
+	}
+
+	r.Body = http.MaxBytesReader(w, r.Body, 1<<20)
+	defer r.Body.Close()
+
+	var in struct {
+		Private   *bool       `json:"private"`
+		ViewerIDs []uuid.UUID `json:"viewer_ids"`
+	}
+	dec := json.NewDecoder(r.Body)
+	dec.DisallowUnknownFields()
+
+	if err := dec.Decode(&in); err != nil {
+		logger.Error(ctx, "set visibility: bad json", "err", err)
+		helper.RespondError(w, r, apperror.BadRequest("invalid request body"))
+		return
+	}
+	if in.Private == nil {
+		helper.RespondError(w, r, apperror.BadRequest("private is required"))
+		return
+	}
+
+	task, err := h.getTaskByID(ctx, taskID, userID)
+	if err != nil {
+		if errors.Is(err, store.ErrTaskNotFound) {
+			helper.RespondError(w, r, apperror.NotFound("task not found"))
+			return
+		}
+		logger.Error(ctx, "set visibility: failed to get task", "err", err)
+		helper.RespondError(w, r, apperror.InternalError("internal error", err))
+		return
+	}
+
+	if task.ReporterID != userID {
+		logger.Info(ctx, "set visibility: forbidden (not reporter)", "user_id", userID, "task_id", task.ID)
+		helper.RespondError(w, r, apperror.Forbidden("only the reporter can change task visibility"))
+		return
+	}
+
+	if in.ViewerIDs != nil {
+		if ok := h.checkViewersInTeam(ctx, w, r, task.TeamID, in.ViewerIDs); !ok {
+			return
+		}
+	}
+
+	updatedTask, err := h.taskStore.SetVisibility(ctx, task.ID, *in.Private, in.ViewerIDs, &userID, time.Now().UTC())
+	if err != nil {
+		if errors.Is(err, store.ErrTaskNotFound) {
+			helper.RespondError(w, r, apperror.NotFound("task not found"))
+			return
+		}
+		logger.Error(ctx, "set visibility: store update failed", "err", err)
+		helper.RespondError(w, r, apperror.InternalError("internal error", err))
+		return
+	}
+
+	logger.Info(ctx, "task visibility changed", "task_id", task.ID, "private", updatedTask.Private, "user_id", userID)
+	h.localize(ctx, updatedTask)
+	helper.RespondJSON(w, r, http.StatusOK, updatedTask)
+}
+
+// ListTaskViewers returns the extra members allowed to see a private task.
+func (h *TaskHandler) ListTaskViewers(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := deadline.Budget(r.Context(), 5*time.Second)
+	defer cancel()
+
+	userID, ok := middleware.GetUserIDFromContext(ctx)
+	if !ok {
+		helper.RespondError(w, r, apperror.Unauthorized("unauthorized"))
+		return
+	}
+
+	taskID, err := parseTaskID(r)
+	if err != nil {
+		logger.Error(ctx, "list viewers: invalid task id", "err", err)
+		helper.RespondError(w, r, apperror.BadRequest("invalid task id"))
+		return
+	}
+
+	task, err := h.getTaskByID(ctx, taskID, userID)
+	if err != nil {
+		if errors.Is(err, store.ErrTaskNotFound) {
+			helper.RespondError(w, r, apperror.NotFound("task not found"))
+			return
+		}
+		logger.Error(ctx, "list viewers: failed to get task", "err", err)
+		helper.RespondError(w, r, apperror.InternalError("internal error", err))
+		return
+	}
+
+	isMember, err := h.teamStore.IsMember(ctx, task.TeamID, userID)
+	if err != nil {
+		logger.Error(ctx, "list viewers: membership check failed", "err", err)
+		helper.RespondError(w, r, apperror.InternalError("internal error", err))
+		return
+	}
+	if !isMember {
+		helper.RespondError(w, r, apperror.Forbidden("forbidden"))
+		return
+	}
+
+	viewers, err := h.taskStore.ListViewers(ctx, task.ID)
+	if err != nil {
+		logger.Error(ctx, "list viewers: store query failed", "err", err)
+		helper.RespondError(w, r, apperror.InternalError("internal error", err))
+		return
+	}
+
+	helper.RespondJSON(w, r, http.StatusOK, types.TaskVisibility{
+		TaskID:    task.ID,
+		Private:   task.Private,
+		ViewerIDs: viewers,
+	})
+}
+
+// checkViewersInTeam rejects viewer lists that are too long or name users
+// outside the team.
+func (h *TaskHandler) checkViewersInTeam(ctx context.Context, w http.ResponseWriter, r *http.Request, teamID uuid.UUID, viewerIDs []uuid.UUID) bool {
+	if len(viewerIDs) > maxTaskViewers {
+		helper.RespondError(w, r, apperror.BadRequest("too many viewers (max 50)"))
+		return false
+	}
+	for _, id := range viewerIDs {
+		isMember, err := h.teamStore.IsMember(ctx, teamID, id)
+		if err != nil {
+			logger.Error(ctx, "viewers: membership check failed", "err", err)
+			helper.RespondError(w, r, apperror.InternalError("internal error", err))
+			return false
+		}
+		if !isMember {
+			helper.RespondError(w, r, apperror.BadRequest("viewers must be members of the team"))
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,113 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/diagnosis/interactive-todo/api/types"
+	"github.com/diagnosis/interactive-todo/internal/apperror"
+	"github.com/diagnosis/interactive-todo/internal/deadline"
+	"github.com/diagnosis/interactive-todo/internal/helper"
+	"github.com/diagnosis/interactive-todo/internal/logger"
+	middleware "github.com/diagnosis/interactive-todo/internal/middleware/auth"
+	store "github.com/diagnosis/interactive-todo/internal/store/tasks"
+)
+
+// ListTaskVotes returns a task's vote count and who voted, to team
+// members who can see the task.
+func (h *TaskHandler) ListTaskVotes(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := deadline.Budget(r.Context(), 5*time.Second)
+	defer cancel()
+
+	userID, ok := middleware.GetUserIDFromContext(ctx)
+	if !ok {
+		helper.RespondError(w, r, apperror.Unauthorized("unauthorized"))
+		return
+	}
+
+	taskID, err := parseTaskID(r)
+	if err != nil {
+		helper.RespondError(w, r, apperror.BadRequest("invalid task id"))
+		return
+	}
+
+	if _, ok := h.memberTask(ctx, w, r, taskID, userID, "list task votes", "forbidden"); !ok {
+		return
+	}
+
+	voters, err := h.taskStore.ListVoters(ctx, taskID)
+	if err != nil {
+		logger.Error(ctx, "list task votes: store query failed", "err", err)
+		helper.RespondError(w, r, apperror.InternalError("internal error", err))
+		return
+	}
+
+	out := types.TaskVotesResponse{TaskID: taskID, VoteCount: len(voters), Voters: voters}
+	for _, v := range voters {
+		if v.UserID == userID {
+			out.Voted = true
+		}
+	}
+	helper.RespondJSON(w, r, http.StatusOK, out)
+}
+
+// VoteTask upvotes a task for the caller; each team member has one vote
+// per task, and voting again changes nothing.
+func (h *TaskHandler) VoteTask(w http.ResponseWriter, r *http.Request) {
+	h.setVote(w, r, true)
+}
+
+// UnvoteTask takes the caller's vote back.
+func (h *TaskHandler) UnvoteTask(w http.ResponseWriter, r *http.Request) {
+	h.setVote(w, r, false)
+}
+
+func (h *TaskHandler) setVote(w http.ResponseWriter, r *http.Request, vote bool) {
+	ctx, cancel := deadline.Budget(r.Context(), 5*time.Second)
+	defer cancel()
+
+	op := "vote task"
+	if !vote {
+		op = "unvote task"
+	}
+
+	userID, ok := middleware.GetUserIDFromContext(ctx)
+	if !ok {
+		helper.RespondError(w, r, apperror.Unauthorized("unauthorized"))
+		return
+	}
+
+	taskID, err := parseTaskID(r)
+	if err != nil {
+		helper.RespondError(w, r, apperror.BadRequest("invalid task id"))
+		return
+	}
+
+	if _, ok := h.memberTask(ctx, w, r, taskID, userID, op, "only team members can vote on tasks"); !ok {
+		return
+	}
+
+	var count int
+	if vote {
+		count, err = h.taskStore.Vote(ctx, taskID, userID, time.Now().UTC())
+	} else {
+		count, err = h.taskStore.Unvote(ctx, taskID, userID)
+	}
+	if err != nil {
+		if errors.Is(err, store.ErrTaskNotFound) {
+			helper.RespondError(w, r, apperror.NotFound("task not found"))
+			return
+		}
+		logger.Error(ctx, op+": store error", "err", err)
+		helper.RespondError(w, r, apperror.InternalError("internal error", err))
+		return
+	}
+
+	logger.Info(ctx, op, "task_id", taskID, "user_id", userID, "vote_count", count)
+	helper.RespondJSON(w, r, http.StatusOK, types.TaskVotesResponse{
+		TaskID:    taskID,
+		VoteCount: count,
+		Voted:     vote,
+	})
+}
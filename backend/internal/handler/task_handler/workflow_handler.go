@@ -0,0 +1,175 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/diagnosis/interactive-todo/internal/apperror"
+	"github.com/diagnosis/interactive-todo/internal/deadline"
+	"github.com/diagnosis/interactive-todo/internal/helper"
+	"github.com/diagnosis/interactive-todo/internal/logger"
+	middleware "github.com/diagnosis/interactive-todo/internal/middleware/auth"
+	approvalstore "github.com/diagnosis/interactive-todo/internal/store/approvals"
+	store "github.com/diagnosis/interactive-todo/internal/store/tasks"
+	"github.com/diagnosis/interactive-todo/internal/workflow"
+	"github.com/google/uuid"
+)
+
+// teamWorkflow returns the team's active workflow definition, or nil when the
+// team uses the global status set.
+func (h *TaskHandler) teamWorkflow(ctx context.Context, teamID uuid.UUID) (*workflow.Definition, error) {
+	settings, err := h.teamStore.GetSettings(ctx, teamID)
+	if err != nil {
+		return nil, err
+	}
+	if settings.WorkflowID == nil {
+		return nil, nil
+	}
+	wf, err := h.workflowStore.Get(ctx, *settings.WorkflowID)
+	if err != nil {
+		return nil, err
+	}
+	return &wf.Definition, nil
+}
+
+// TransitionTask moves a task along its team's custom workflow.
+func (h *TaskHandler) TransitionTask(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := deadline.Budget(r.Context(), 5*time.Second)
+	defer cancel()
+
+	userID, ok := middleware.GetUserIDFromContext(ctx)
+	if !ok {
+		helper.RespondError(w, r, apperror.Unauthorized("unauthorized"))
+		return
+	}
+
+	taskID, err := parseTaskID(r)
+	if err != nil {
+		logger.Error(ctx, "transition task: invalid task id", "err", err)
+		helper.RespondError(w, r, apperror.BadRequest("invalid task id"))
+		return
+	}
+
+	r.Body = http.MaxBytesReader(w, r.Body, 1<<20)
+	defer r.Body.Close()
+
+	var in struct {
+		State string `json:"state"`
+	}
+	dec := json.NewDecoder(r.Body)
+	dec.DisallowUnknownFields()
+
+	if err := dec.Decode(&in); err != nil {
+		logger.Error(ctx, "transition task: bad json", "err", err)
+		helper.RespondError(w, r, apperror.BadRequest("invalid request body"))
+		return
+	}
+	in.State = strings.TrimSpace(in.State)
+	if in.State == "" {
+		helper.RespondError(w, r, apperror.BadRequest("state is required"))
+		return
+	}
+
+	task, err := h.getTaskByID(ctx, taskID, userID)
+	if err != nil {
+		if errors.Is(err, store.ErrTaskNotFound) {
+			helper.RespondError(w, r, apperror.NotFound("task not found"))
+			return
+		}
+		logger.Error(ctx, "transition task: failed to get task", "err", err)
+		helper.RespondError(w, r, apperror.InternalError("internal error", err))
+		return
+	}
+
+	isMember, err := h.teamStore.IsMember(ctx, task.TeamID, userID)
+	if err != nil {
+		logger.Error(ctx, "transition task: membership check failed", "err", err)
+		helper.RespondError(w, r, apperror.InternalError("internal error", err))
+		return
+	}
+	if !isMember {
+		helper.RespondError(w, r, apperror.Forbidden("forbidden"))
+		return
+	}
+
+	def, err := h.teamWorkflow(ctx, task.TeamID)
+	if err != nil {
+		logger.Error(ctx, "transition task: load team workflow failed", "err", err)
+		helper.RespondError(w, r, apperror.InternalError("internal error", err))
+		return
+	}
+	if def == nil {
+		helper.RespondError(w, r, apperror.Conflict("team has no custom workflow; use PATCH /tasks/{id}/status"))
+		return
+	}
+
+	if _, err := h.approvalStore.GetPending(ctx, task.ID); err == nil {
+		helper.RespondError(w, r, apperror.Conflict("task is awaiting approval"))
+		return
+	} else if !errors.Is(err, approvalstore.ErrApprovalNotFound) {
+		logger.Error(ctx, "transition task: pending approval check failed", "err", err)
+		helper.RespondError(w, r, apperror.InternalError("internal error", err))
+		return
+	}
+
+	actors, err := h.actorsFor(ctx, task, userID)
+	if err != nil {
+		logger.Error(ctx, "transition task: role check failed", "err", err)
+		helper.RespondError(w, r, apperror.InternalError("internal error", err))
+		return
+	}
+
+	target, err := def.CheckTransition(task, in.State, actors)
+	if err != nil {
+		switch {
+		case errors.Is(err, workflow.ErrActorNotAllowed):
+			helper.RespondError(w, r, apperror.Forbidden(err.Error()))
+		case errors.Is(err, workflow.ErrUnknownState),
+			errors.Is(err, workflow.ErrTransitionNotAllowed),
+			errors.Is(err, workflow.ErrMissingField):
+			helper.RespondError(w, r, apperror.BadRequest(err.Error()))
+		default:
+			helper.RespondError(w, r, apperror.InternalError("internal error", err))
+		}
+		return
+	}
+
+	now := time.Now().UTC()
+	updatedTask, err := h.taskStore.SetWorkflowState(ctx, task.ID, target.Key, target.Category, &userID, now)
+	if err != nil {
+		logger.Error(ctx, "transition task: store update failed", "err", err)
+		helper.RespondError(w, r, apperror.InternalError("internal error", err))
+		return
+	}
+	h.publishStatusChanged(ctx, updatedTask, task.Status, userID, now)
+
+	logger.Info(ctx, "task transitioned",
+		"task_id", task.ID,
+		"from", def.CurrentState(task),
+		"to", target.Key,
+	)
+	h.localize(ctx, updatedTask)
+	helper.RespondJSON(w, r, http.StatusOK, updatedTask)
+}
+
+func (h *TaskHandler) actorsFor(ctx context.Context, task *store.Task, userID uuid.UUID) ([]workflow.Actor, error) {
+	var actors []workflow.Actor
+	if userID == task.AssigneeID {
+		actors = append(actors, workflow.ActorAssignee)
+	}
+	if userID == task.ReporterID {
+		actors = append(actors, workflow.ActorReporter)
+	}
+	isAdmin, err := h.teamStore.IsOwnerOrAdmin(ctx, task.TeamID, userID)
+	if err != nil {
+		return nil, err
+	}
+	if isAdmin {
+		actors = append(actors, workflow.ActorTeamAdmin)
+	}
+	return actors, nil
+}
@@ -0,0 +1,116 @@
+package handler
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/diagnosis/interactive-todo/api/types"
+	"github.com/diagnosis/interactive-todo/internal/apperror"
+	"github.com/diagnosis/interactive-todo/internal/deadline"
+	"github.com/diagnosis/interactive-todo/internal/helper"
+	"github.com/diagnosis/interactive-todo/internal/logger"
+	assignmentstore "github.com/diagnosis/interactive-todo/internal/store/assignment"
+	labelstore "github.com/diagnosis/interactive-todo/internal/store/labels"
+)
+
+// maxAssignmentRoutes keeps label routing tables small enough to read.
+const maxAssignmentRoutes = 100
+
+func (h *TeamHandler) ListAssignmentRoutes(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := deadline.Budget(r.Context(), 5*time.Second)
+	defer cancel()
+
+	teamID, ok := h.requireTeamMember(ctx, w, r, "only team members can view assignment routes")
+	if !ok {
+		return
+	}
+
+	routes, err := h.assignmentStore.ListRoutes(ctx, teamID)
+	if err != nil {
+		internalError(ctx, w, r, err)
+		return
+	}
+	helper.RespondJSON(w, r, http.StatusOK, types.AssignmentRouteListResponse{TeamID: teamID, Routes: routes})
+}
+
+// PutAssignmentRoutes replaces the team's label routing table. Routes are
+// tried in the order given; a label may appear only once.
+func (h *TeamHandler) PutAssignmentRoutes(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := deadline.Budget(r.Context(), 5*time.Second)
+	defer cancel()
+
+	userID, teamID, ok := h.requireTeamAdmin(ctx, w, r, "only team owner/admin can change assignment routes")
+	if !ok {
+		return
+	}
+
+	r.Body = http.MaxBytesReader(w, r.Body, 1<<20)
+	dec := json.NewDecoder(r.Body)
+	dec.DisallowUnknownFields()
+	defer r.Body.Close()
+
+	var in types.PutAssignmentRoutesRequest
+	if err := dec.Decode(&in); err != nil {
+		badJsonCheck(ctx, w, r, "bad json")
+		return
+	}
+	if len(in.Routes) > maxAssignmentRoutes {
+		helper.RespondError(w, r, apperror.BadRequest(fmt.Sprintf("at most %d routes", maxAssignmentRoutes)))
+		return
+	}
+
+	names := make([]string, 0, len(in.Routes))
+	seen := make(map[string]bool, len(in.Routes))
+	for i := range in.Routes {
+		route := &in.Routes[i]
+		route.Label = strings.TrimSpace(route.Label)
+		if route.Label == "" || len(route.Label) > labelstore.MaxNameLength {
+			helper.RespondError(w, r, apperror.BadRequest(fmt.Sprintf("label is required (max %d chars)", labelstore.MaxNameLength)))
+			return
+		}
+		key := strings.ToLower(route.Label)
+		if seen[key] {
+			helper.RespondError(w, r, apperror.BadRequest(fmt.Sprintf("label %q routed more than once", route.Label)))
+			return
+		}
+		seen[key] = true
+
+		isMember, err := h.teamsStore.IsMember(ctx, teamID, route.AssigneeID)
+		if err != nil {
+			internalError(ctx, w, r, err)
+			return
+		}
+		if !isMember {
+			helper.RespondError(w, r, apperror.BadRequest(fmt.Sprintf("assignee for label %q is not a team member", route.Label)))
+			return
+		}
+		names = append(names, route.Label)
+	}
+
+	now := time.Now().UTC()
+	labels, err := h.labelStore.Ensure(ctx, teamID, names, now)
+	if err != nil {
+		internalError(ctx, w, r, err)
+		return
+	}
+	byName := make(map[string]labelstore.Label, len(labels))
+	for _, l := range labels {
+		byName[strings.ToLower(l.Name)] = l
+	}
+
+	routes := make([]assignmentstore.Route, 0, len(in.Routes))
+	for _, route := range in.Routes {
+		l := byName[strings.ToLower(route.Label)]
+		routes = append(routes, assignmentstore.Route{LabelID: l.ID, Label: l.Name, AssigneeID: route.AssigneeID})
+	}
+	if err := h.assignmentStore.ReplaceRoutes(ctx, teamID, routes); err != nil {
+		internalError(ctx, w, r, err)
+		return
+	}
+
+	logger.Info(ctx, "assignment routes updated", "team_id", teamID, "user_id", userID, "route_count", len(routes))
+	helper.RespondJSON(w, r, http.StatusOK, types.AssignmentRouteListResponse{TeamID: teamID, Routes: routes})
+}
@@ -0,0 +1,194 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/diagnosis/interactive-todo/internal/apperror"
+	"github.com/diagnosis/interactive-todo/internal/automation"
+	"github.com/diagnosis/interactive-todo/internal/deadline"
+	"github.com/diagnosis/interactive-todo/internal/helper"
+	"github.com/diagnosis/interactive-todo/internal/logger"
+	automationstore "github.com/diagnosis/interactive-todo/internal/store/automations"
+	"github.com/google/uuid"
+)
+
+// maxAutomations bounds the recipes checked on every task event.
+const maxAutomations = 50
+
+type automationInput struct {
+	Name       string                `json:"name"`
+	Definition automation.Definition `json:"definition"`
+	Enabled    *bool                 `json:"enabled"`
+}
+
+func (h *TeamHandler) ListAutomations(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := deadline.Budget(r.Context(), 5*time.Second)
+	defer cancel()
+
+	teamID, ok := h.requireTeamMember(ctx, w, r, "only team members can view automations")
+	if !ok {
+		return
+	}
+
+	recipes, err := h.recipeStore.ListForTeam(ctx, teamID)
+	if err != nil {
+		internalError(ctx, w, r, err)
+		return
+	}
+
+	helper.RespondJSON(w, r, http.StatusOK, map[string]any{
+		"team_id":     teamID,
+		"automations": recipes,
+	})
+}
+
+func (h *TeamHandler) CreateAutomation(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := deadline.Budget(r.Context(), 5*time.Second)
+	defer cancel()
+
+	userID, teamID, ok := h.requireTeamAdmin(ctx, w, r, "only team owner/admin can manage automations")
+	if !ok {
+		return
+	}
+
+	in, ok := h.decodeAutomationInput(ctx, w, r, teamID)
+	if !ok {
+		return
+	}
+
+	existing, err := h.recipeStore.ListForTeam(ctx, teamID)
+	if err != nil {
+		internalError(ctx, w, r, err)
+		return
+	}
+	if len(existing) >= maxAutomations {
+		helper.RespondError(w, r, apperror.BadRequest(fmt.Sprintf("a team can have at most %d automations", maxAutomations)))
+		return
+	}
+
+	rec, err := h.recipeStore.Create(ctx, teamID, in.Name, in.Definition, *in.Enabled, userID, time.Now().UTC())
+	if err != nil {
+		respondAutomationError(ctx, w, r, err)
+		return
+	}
+
+	logger.Info(ctx, "automation created", "team_id", teamID, "recipe_id", rec.ID, "user_id", userID)
+	helper.RespondJSON(w, r, http.StatusCreated, rec)
+}
+
+func (h *TeamHandler) UpdateAutomation(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := deadline.Budget(r.Context(), 5*time.Second)
+	defer cancel()
+
+	userID, teamID, ok := h.requireTeamAdmin(ctx, w, r, "only team owner/admin can manage automations")
+	if !ok {
+		return
+	}
+
+	recipeID, ok := parseID("recipe_id", r)
+	if !ok {
+		helper.RespondError(w, r, apperror.BadRequest("invalid automation id"))
+		return
+	}
+
+	in, ok := h.decodeAutomationInput(ctx, w, r, teamID)
+	if !ok {
+		return
+	}
+
+	rec, err := h.recipeStore.Update(ctx, teamID, recipeID, in.Name, in.Definition, *in.Enabled, time.Now().UTC())
+	if err != nil {
+		respondAutomationError(ctx, w, r, err)
+		return
+	}
+
+	logger.Info(ctx, "automation updated", "team_id", teamID, "recipe_id", rec.ID, "user_id", userID)
+	helper.RespondJSON(w, r, http.StatusOK, rec)
+}
+
+func (h *TeamHandler) DeleteAutomation(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := deadline.Budget(r.Context(), 5*time.Second)
+	defer cancel()
+
+	userID, teamID, ok := h.requireTeamAdmin(ctx, w, r, "only team owner/admin can manage automations")
+	if !ok {
+		return
+	}
+
+	recipeID, ok := parseID("recipe_id", r)
+	if !ok {
+		helper.RespondError(w, r, apperror.BadRequest("invalid automation id"))
+		return
+	}
+
+	if err := h.recipeStore.Delete(ctx, teamID, recipeID); err != nil {
+		respondAutomationError(ctx, w, r, err)
+		return
+	}
+
+	logger.Info(ctx, "automation deleted", "team_id", teamID, "recipe_id", recipeID, "user_id", userID)
+	helper.RespondMessage(w, r, http.StatusOK, "automation deleted")
+}
+
+// decodeAutomationInput also checks that users named in the actions are in
+// the team; the runner checks again when a recipe fires, since people
+// leave.
+func (h *TeamHandler) decodeAutomationInput(ctx context.Context, w http.ResponseWriter, r *http.Request, teamID uuid.UUID) (*automationInput, bool) {
+	r.Body = http.MaxBytesReader(w, r.Body, 1<<20)
+	dec := json.NewDecoder(r.Body)
+	dec.DisallowUnknownFields()
+	defer r.Body.Close()
+
+	var in automationInput
+	if err := dec.Decode(&in); err != nil {
+		badJsonCheck(ctx, w, r, "bad json")
+		return nil, false
+	}
+
+	in.Name = strings.TrimSpace(in.Name)
+	if in.Name == "" || len(in.Name) > 100 {
+		helper.RespondError(w, r, apperror.BadRequest("name is required (max 100 chars)"))
+		return nil, false
+	}
+	if in.Enabled == nil {
+		enabled := true
+		in.Enabled = &enabled
+	}
+
+	for i, a := range in.Definition.Actions {
+		for _, id := range []*uuid.UUID{a.AssigneeID, a.UserID} {
+			if id == nil {
+				continue
+			}
+			isMember, err := h.teamsStore.IsMember(ctx, teamID, *id)
+			if err != nil {
+				internalError(ctx, w, r, err)
+				return nil, false
+			}
+			if !isMember {
+				helper.RespondError(w, r, apperror.BadRequest(fmt.Sprintf("actions[%d]: user must be a member of the team", i)))
+				return nil, false
+			}
+		}
+	}
+	return &in, true
+}
+
+func respondAutomationError(ctx context.Context, w http.ResponseWriter, r *http.Request, err error) {
+	switch {
+	case errors.Is(err, automation.ErrInvalidDefinition):
+		helper.RespondError(w, r, apperror.BadRequest(err.Error()))
+	case errors.Is(err, automationstore.ErrRecipeNameTaken):
+		helper.RespondError(w, r, apperror.Conflict("automation name already used in team"))
+	case errors.Is(err, automationstore.ErrRecipeNotFound):
+		helper.RespondError(w, r, apperror.NotFound("automation not found"))
+	default:
+		internalError(ctx, w, r, err)
+	}
+}
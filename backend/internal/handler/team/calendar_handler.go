@@ -0,0 +1,212 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/diagnosis/interactive-todo/api/types"
+	"github.com/diagnosis/interactive-todo/internal/apperror"
+	"github.com/diagnosis/interactive-todo/internal/calendar"
+	"github.com/diagnosis/interactive-todo/internal/deadline"
+	"github.com/diagnosis/interactive-todo/internal/helper"
+	"github.com/diagnosis/interactive-todo/internal/logger"
+	middleware "github.com/diagnosis/interactive-todo/internal/middleware/auth"
+	calendarstore "github.com/diagnosis/interactive-todo/internal/store/calendars"
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+)
+
+func (h *TeamHandler) GetCalendar(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := deadline.Budget(r.Context(), 5*time.Second)
+	defer cancel()
+
+	teamID, ok := h.requireTeamMember(ctx, w, r, "only team members can view the team calendar")
+	if !ok {
+		return
+	}
+
+	tc, err := h.calendarStore.Get(ctx, teamID)
+	if err != nil {
+		internalError(ctx, w, r, err)
+		return
+	}
+	helper.RespondJSON(w, r, http.StatusOK, tc)
+}
+
+// PutCalendar replaces the timezone, weekend and due date policy; holidays
+// are managed separately.
+func (h *TeamHandler) PutCalendar(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := deadline.Budget(r.Context(), 5*time.Second)
+	defer cancel()
+
+	userID, teamID, ok := h.requireTeamAdmin(ctx, w, r, "only team owner/admin can change the team calendar")
+	if !ok {
+		return
+	}
+
+	r.Body = http.MaxBytesReader(w, r.Body, 1<<20)
+	dec := json.NewDecoder(r.Body)
+	dec.DisallowUnknownFields()
+	defer r.Body.Close()
+
+	var in types.PutCalendarRequest
+	if err := dec.Decode(&in); err != nil {
+		badJsonCheck(ctx, w, r, "bad json")
+		return
+	}
+	in.Timezone = strings.TrimSpace(in.Timezone)
+	if in.Timezone == "" {
+		in.Timezone = "UTC"
+	}
+	if in.DueDatePolicy == "" {
+		in.DueDatePolicy = calendar.PolicyOff
+	}
+
+	tc, err := h.calendarStore.Put(ctx, teamID, in, time.Now().UTC())
+	if err != nil {
+		if errors.Is(err, calendar.ErrInvalid) {
+			helper.RespondError(w, r, apperror.BadRequest(err.Error()))
+			return
+		}
+		internalError(ctx, w, r, err)
+		return
+	}
+
+	logger.Info(ctx, "team calendar updated", "team_id", teamID, "user_id", userID)
+	helper.RespondJSON(w, r, http.StatusOK, tc)
+}
+
+func (h *TeamHandler) AddHoliday(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := deadline.Budget(r.Context(), 5*time.Second)
+	defer cancel()
+
+	userID, teamID, ok := h.requireTeamAdmin(ctx, w, r, "only team owner/admin can change the team calendar")
+	if !ok {
+		return
+	}
+
+	r.Body = http.MaxBytesReader(w, r.Body, 1<<20)
+	dec := json.NewDecoder(r.Body)
+	dec.DisallowUnknownFields()
+	defer r.Body.Close()
+
+	var in types.Holiday
+	if err := dec.Decode(&in); err != nil {
+		badJsonCheck(ctx, w, r, "bad json")
+		return
+	}
+	day, err := time.Parse(calendar.DateLayout, in.Day)
+	if err != nil {
+		helper.RespondError(w, r, apperror.BadRequest("day must be a date like 2026-12-25"))
+		return
+	}
+	in.Name = strings.TrimSpace(in.Name)
+	if in.Name == "" || len(in.Name) > 100 {
+		helper.RespondError(w, r, apperror.BadRequest("name is required (max 100 chars)"))
+		return
+	}
+
+	holiday, err := h.calendarStore.AddHoliday(ctx, teamID, day, in.Name, time.Now().UTC())
+	if err != nil {
+		if errors.Is(err, calendarstore.ErrHolidayExists) {
+			helper.RespondError(w, r, apperror.Conflict("that day is already a holiday"))
+			return
+		}
+		internalError(ctx, w, r, err)
+		return
+	}
+
+	logger.Info(ctx, "holiday added", "team_id", teamID, "day", holiday.Day, "user_id", userID)
+	helper.RespondJSON(w, r, http.StatusCreated, holiday)
+}
+
+func (h *TeamHandler) DeleteHoliday(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := deadline.Budget(r.Context(), 5*time.Second)
+	defer cancel()
+
+	userID, teamID, ok := h.requireTeamAdmin(ctx, w, r, "only team owner/admin can change the team calendar")
+	if !ok {
+		return
+	}
+
+	day, err := time.Parse(calendar.DateLayout, chi.URLParam(r, "day"))
+	if err != nil {
+		helper.RespondError(w, r, apperror.BadRequest("day must be a date like 2026-12-25"))
+		return
+	}
+
+	if err := h.calendarStore.DeleteHoliday(ctx, teamID, day); err != nil {
+		if errors.Is(err, calendarstore.ErrHolidayNotFound) {
+			helper.RespondError(w, r, apperror.NotFound("holiday not found"))
+			return
+		}
+		internalError(ctx, w, r, err)
+		return
+	}
+
+	logger.Info(ctx, "holiday deleted", "team_id", teamID, "day", day.Format(calendar.DateLayout), "user_id", userID)
+	helper.RespondMessage(w, r, http.StatusOK, "holiday deleted")
+}
+
+// CheckDueDate tells a client whether ?due_at= falls on a working day, so
+// it can warn before the task is saved.
+func (h *TeamHandler) CheckDueDate(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := deadline.Budget(r.Context(), 5*time.Second)
+	defer cancel()
+
+	teamID, ok := h.requireTeamMember(ctx, w, r, "only team members can view the team calendar")
+	if !ok {
+		return
+	}
+
+	dueAt, err := time.Parse(time.RFC3339, r.URL.Query().Get("due_at"))
+	if err != nil {
+		helper.RespondError(w, r, apperror.BadRequest("due_at must be an RFC 3339 timestamp"))
+		return
+	}
+
+	cal, err := h.calendarStore.Load(ctx, teamID)
+	if err != nil {
+		internalError(ctx, w, r, err)
+		return
+	}
+	reason, off := cal.NonWorking(dueAt)
+	helper.RespondJSON(w, r, http.StatusOK, types.DueDateCheckResponse{
+		DueAt:          dueAt.UTC(),
+		WorkingDay:     !off,
+		Reason:         reason,
+		NextWorkingDay: cal.NextWorkingDay(dueAt).UTC(),
+	})
+}
+
+// requireTeamMember resolves {team_id} and checks the caller belongs to it,
+// responding with forbiddenMsg otherwise.
+func (h *TeamHandler) requireTeamMember(ctx context.Context, w http.ResponseWriter, r *http.Request, forbiddenMsg string) (uuid.UUID, bool) {
+	userID, ok := middleware.GetUserIDFromContext(ctx)
+	if !ok {
+		logger.Error(ctx, "unauthorized team member action attempt")
+		helper.RespondError(w, r, apperror.Unauthorized("authentication required"))
+		return uuid.Nil, false
+	}
+
+	teamID, ok := parseID("team_id", r)
+	if !ok {
+		helper.RespondError(w, r, apperror.BadRequest("invalid team id"))
+		return uuid.Nil, false
+	}
+
+	isMember, err := h.teamsStore.IsMember(ctx, teamID, userID)
+	if err != nil {
+		internalError(ctx, w, r, err)
+		return uuid.Nil, false
+	}
+	if !isMember {
+		forbiddenError(ctx, w, r, forbiddenMsg)
+		return uuid.Nil, false
+	}
+	return teamID, true
+}
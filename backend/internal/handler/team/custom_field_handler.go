@@ -0,0 +1,148 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/diagnosis/interactive-todo/api/types"
+	"github.com/diagnosis/interactive-todo/internal/apperror"
+	"github.com/diagnosis/interactive-todo/internal/deadline"
+	"github.com/diagnosis/interactive-todo/internal/helper"
+	"github.com/diagnosis/interactive-todo/internal/logger"
+	customfieldstore "github.com/diagnosis/interactive-todo/internal/store/customfields"
+)
+
+func (h *TeamHandler) ListCustomFields(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := deadline.Budget(r.Context(), 5*time.Second)
+	defer cancel()
+
+	teamID, ok := h.requireTeamMember(ctx, w, r, "only team members can view custom fields")
+	if !ok {
+		return
+	}
+
+	fields, err := h.customFields.List(ctx, teamID)
+	if err != nil {
+		internalError(ctx, w, r, err)
+		return
+	}
+	helper.RespondJSON(w, r, http.StatusOK, types.CustomFieldListResponse{TeamID: teamID, Fields: fields})
+}
+
+func (h *TeamHandler) CreateCustomField(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := deadline.Budget(r.Context(), 5*time.Second)
+	defer cancel()
+
+	userID, teamID, ok := h.requireTeamAdmin(ctx, w, r, "only team owner/admin can create custom fields")
+	if !ok {
+		return
+	}
+
+	r.Body = http.MaxBytesReader(w, r.Body, 1<<20)
+	dec := json.NewDecoder(r.Body)
+	dec.DisallowUnknownFields()
+	defer r.Body.Close()
+
+	var in types.CreateCustomFieldRequest
+	if err := dec.Decode(&in); err != nil {
+		badJsonCheck(ctx, w, r, "bad json")
+		return
+	}
+
+	field, err := h.customFields.Create(ctx, teamID, in, userID, time.Now().UTC())
+	if err != nil {
+		switch {
+		case errors.Is(err, customfieldstore.ErrInvalidField):
+			helper.RespondError(w, r, apperror.BadRequest(err.Error()))
+		case errors.Is(err, customfieldstore.ErrFieldExists):
+			helper.RespondError(w, r, apperror.Conflict("a custom field with this key already exists"))
+		default:
+			internalError(ctx, w, r, err)
+		}
+		return
+	}
+
+	logger.Info(ctx, "custom field created", "team_id", teamID, "field_id", field.ID, "user_id", userID)
+	helper.RespondJSON(w, r, http.StatusCreated, field)
+}
+
+// UpdateCustomField changes a field's label, options or whether it is
+// required. Values tasks already hold are left as they are.
+func (h *TeamHandler) UpdateCustomField(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := deadline.Budget(r.Context(), 5*time.Second)
+	defer cancel()
+
+	userID, teamID, ok := h.requireTeamAdmin(ctx, w, r, "only team owner/admin can update custom fields")
+	if !ok {
+		return
+	}
+
+	fieldID, ok := parseID("field_id", r)
+	if !ok {
+		helper.RespondError(w, r, apperror.BadRequest("invalid field id"))
+		return
+	}
+
+	r.Body = http.MaxBytesReader(w, r.Body, 1<<20)
+	dec := json.NewDecoder(r.Body)
+	dec.DisallowUnknownFields()
+	defer r.Body.Close()
+
+	var in types.UpdateCustomFieldRequest
+	if err := dec.Decode(&in); err != nil {
+		badJsonCheck(ctx, w, r, "bad json")
+		return
+	}
+	if in.Label == nil && in.Options == nil && in.Required == nil {
+		helper.RespondError(w, r, apperror.BadRequest("label, options or required is required"))
+		return
+	}
+
+	field, err := h.customFields.Update(ctx, teamID, fieldID, in, time.Now().UTC())
+	if err != nil {
+		switch {
+		case errors.Is(err, customfieldstore.ErrFieldNotFound):
+			helper.RespondError(w, r, apperror.NotFound("custom field not found"))
+		case errors.Is(err, customfieldstore.ErrInvalidField):
+			helper.RespondError(w, r, apperror.BadRequest(err.Error()))
+		default:
+			internalError(ctx, w, r, err)
+		}
+		return
+	}
+
+	logger.Info(ctx, "custom field updated", "team_id", teamID, "field_id", fieldID, "user_id", userID)
+	helper.RespondJSON(w, r, http.StatusOK, field)
+}
+
+// DeleteCustomField removes a field and its values from every task of the
+// team. The removal is not written to the tasks' history.
+func (h *TeamHandler) DeleteCustomField(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := deadline.Budget(r.Context(), 5*time.Second)
+	defer cancel()
+
+	userID, teamID, ok := h.requireTeamAdmin(ctx, w, r, "only team owner/admin can delete custom fields")
+	if !ok {
+		return
+	}
+
+	fieldID, ok := parseID("field_id", r)
+	if !ok {
+		helper.RespondError(w, r, apperror.BadRequest("invalid field id"))
+		return
+	}
+
+	if err := h.customFields.Delete(ctx, teamID, fieldID); err != nil {
+		if errors.Is(err, customfieldstore.ErrFieldNotFound) {
+			helper.RespondError(w, r, apperror.NotFound("custom field not found"))
+			return
+		}
+		internalError(ctx, w, r, err)
+		return
+	}
+
+	logger.Info(ctx, "custom field deleted", "team_id", teamID, "field_id", fieldID, "user_id", userID)
+	helper.RespondMessage(w, r, http.StatusOK, "custom field deleted")
+}
@@ -0,0 +1,160 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/diagnosis/interactive-todo/api/types"
+	"github.com/diagnosis/interactive-todo/internal/apperror"
+	"github.com/diagnosis/interactive-todo/internal/deadline"
+	"github.com/diagnosis/interactive-todo/internal/directory"
+	"github.com/diagnosis/interactive-todo/internal/helper"
+	"github.com/diagnosis/interactive-todo/internal/logger"
+	directorystore "github.com/diagnosis/interactive-todo/internal/store/directory"
+	teamstore "github.com/diagnosis/interactive-todo/internal/store/teams"
+)
+
+// directorySyncBudget covers paging through a large group
+const directorySyncBudget = time.Minute
+
+func (h *TeamHandler) GetDirectoryLink(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := deadline.Budget(r.Context(), 5*time.Second)
+	defer cancel()
+
+	_, teamID, ok := h.requireTeamAdmin(ctx, w, r, "only team owner/admin can view directory sync")
+	if !ok {
+		return
+	}
+
+	link, err := h.directoryStore.Get(ctx, teamID)
+	if err != nil {
+		if errors.Is(err, directorystore.ErrLinkNotFound) {
+			helper.RespondError(w, r, apperror.NotFound("team is not linked to a directory group"))
+			return
+		}
+		internalError(ctx, w, r, err)
+		return
+	}
+	helper.RespondJSON(w, r, http.StatusOK, link)
+}
+
+// PutDirectoryLink links the team to a directory group. From the next sync
+// on, group members with an account here are added and members the sync
+// added are removed once they leave the group.
+func (h *TeamHandler) PutDirectoryLink(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := deadline.Budget(r.Context(), 5*time.Second)
+	defer cancel()
+
+	userID, teamID, ok := h.requireTeamAdmin(ctx, w, r, "only team owner/admin can change directory sync")
+	if !ok {
+		return
+	}
+
+	r.Body = http.MaxBytesReader(w, r.Body, 1<<20)
+	dec := json.NewDecoder(r.Body)
+	dec.DisallowUnknownFields()
+	defer r.Body.Close()
+
+	var in types.PutDirectoryLinkRequest
+	if err := dec.Decode(&in); err != nil {
+		badJsonCheck(ctx, w, r, "bad json")
+		return
+	}
+	in.GroupID = strings.TrimSpace(in.GroupID)
+	if in.Role == "" {
+		in.Role = teamstore.RoleMember
+	}
+	switch {
+	case in.Provider != directory.ProviderGoogle && in.Provider != directory.ProviderAzure:
+		helper.RespondError(w, r, apperror.BadRequest("provider must be google or azure"))
+		return
+	case !h.directorySync.Configured(in.Provider):
+		helper.RespondError(w, r, apperror.BadRequest("provider "+in.Provider+" is not configured on this server"))
+		return
+	case in.GroupID == "" || len(in.GroupID) > 255:
+		helper.RespondError(w, r, apperror.BadRequest("group_id is required (max 255 chars)"))
+		return
+	case in.Role == teamstore.RoleOwner || !isValidTeamRole(in.Role):
+		helper.RespondError(w, r, apperror.BadRequest("role must be admin or member"))
+		return
+	}
+
+	link, err := h.directoryStore.Put(ctx, teamID, in.Provider, in.GroupID, in.Role, userID, time.Now().UTC())
+	if err != nil {
+		internalError(ctx, w, r, err)
+		return
+	}
+
+	logger.Info(ctx, "directory link set", "team_id", teamID, "provider", in.Provider, "group_id", in.GroupID, "user_id", userID)
+	helper.RespondJSON(w, r, http.StatusOK, link)
+}
+
+// DeleteDirectoryLink stops syncing the team. Members stay; those the sync
+// added become ordinary members.
+func (h *TeamHandler) DeleteDirectoryLink(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := deadline.Budget(r.Context(), 5*time.Second)
+	defer cancel()
+
+	userID, teamID, ok := h.requireTeamAdmin(ctx, w, r, "only team owner/admin can change directory sync")
+	if !ok {
+		return
+	}
+
+	if err := h.directoryStore.Delete(ctx, teamID); err != nil {
+		if errors.Is(err, directorystore.ErrLinkNotFound) {
+			helper.RespondError(w, r, apperror.NotFound("team is not linked to a directory group"))
+			return
+		}
+		internalError(ctx, w, r, err)
+		return
+	}
+
+	logger.Info(ctx, "directory link removed", "team_id", teamID, "user_id", userID)
+	helper.RespondMessage(w, r, http.StatusOK, "directory link removed")
+}
+
+// RunDirectorySync syncs the team now instead of waiting for the job, e.g.
+// right after linking it.
+func (h *TeamHandler) RunDirectorySync(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := deadline.Budget(r.Context(), directorySyncBudget)
+	defer cancel()
+
+	userID, teamID, ok := h.requireTeamAdmin(ctx, w, r, "only team owner/admin can run directory sync")
+	if !ok {
+		return
+	}
+
+	link, err := h.directoryStore.Get(ctx, teamID)
+	if err != nil {
+		if errors.Is(err, directorystore.ErrLinkNotFound) {
+			helper.RespondError(w, r, apperror.NotFound("team is not linked to a directory group"))
+			return
+		}
+		internalError(ctx, w, r, err)
+		return
+	}
+
+	res, err := h.directorySync.Sync(ctx, *link)
+	switch {
+	case errors.Is(err, directory.ErrProvider):
+		logger.Warn(ctx, "directory sync failed", "team_id", teamID, "err", err)
+		helper.RespondError(w, r, apperror.BadGateway(err.Error()))
+		return
+	case errors.Is(err, directory.ErrEmptyGroup), errors.Is(err, directory.ErrNotConfigured):
+		helper.RespondError(w, r, apperror.Conflict(err.Error()))
+		return
+	case errors.Is(err, directorystore.ErrLinkNotFound):
+		helper.RespondError(w, r, apperror.NotFound("team is not linked to a directory group"))
+		return
+	case err != nil:
+		internalError(ctx, w, r, err)
+		return
+	}
+
+	logger.Info(ctx, "directory sync run", "team_id", teamID, "user_id", userID,
+		"added", res.Added, "removed", res.Removed, "unmatched", res.Unmatched)
+	helper.RespondJSON(w, r, http.StatusOK, res)
+}
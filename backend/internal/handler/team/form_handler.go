@@ -0,0 +1,191 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/diagnosis/interactive-todo/internal/apperror"
+	"github.com/diagnosis/interactive-todo/internal/deadline"
+	"github.com/diagnosis/interactive-todo/internal/helper"
+	"github.com/diagnosis/interactive-todo/internal/logger"
+	middleware "github.com/diagnosis/interactive-todo/internal/middleware/auth"
+	formstore "github.com/diagnosis/interactive-todo/internal/store/forms"
+	"github.com/google/uuid"
+)
+
+func (h *TeamHandler) ListForms(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := deadline.Budget(r.Context(), 5*time.Second)
+	defer cancel()
+
+	userID, ok := middleware.GetUserIDFromContext(ctx)
+	if !ok {
+		logger.Error(ctx, "unauthorized list forms attempt")
+		helper.RespondError(w, r, apperror.Unauthorized("authentication required"))
+		return
+	}
+
+	teamID, ok := parseID("team_id", r)
+	if !ok {
+		helper.RespondError(w, r, apperror.BadRequest("invalid team id"))
+		return
+	}
+
+	isMember, err := h.teamsStore.IsMember(ctx, teamID, userID)
+	if err != nil {
+		internalError(ctx, w, r, err)
+		return
+	}
+	if !isMember {
+		forbiddenError(ctx, w, r, "only team members can view forms")
+		return
+	}
+
+	forms, err := h.formStore.ListForTeam(ctx, teamID)
+	if err != nil {
+		internalError(ctx, w, r, err)
+		return
+	}
+
+	helper.RespondJSON(w, r, http.StatusOK, map[string]any{
+		"team_id": teamID,
+		"forms":   forms,
+	})
+}
+
+func (h *TeamHandler) CreateForm(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := deadline.Budget(r.Context(), 5*time.Second)
+	defer cancel()
+
+	userID, teamID, ok := h.requireTeamAdmin(ctx, w, r, "only team owner/admin can manage forms")
+	if !ok {
+		return
+	}
+
+	in, ok := h.decodeFormInput(ctx, w, r, teamID)
+	if !ok {
+		return
+	}
+
+	form, err := h.formStore.Create(ctx, teamID, *in, userID, time.Now().UTC())
+	if err != nil {
+		respondFormError(ctx, w, r, err)
+		return
+	}
+
+	logger.Info(ctx, "form created", "team_id", teamID, "form_id", form.ID, "user_id", userID)
+	helper.RespondJSON(w, r, http.StatusCreated, form)
+}
+
+func (h *TeamHandler) UpdateForm(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := deadline.Budget(r.Context(), 5*time.Second)
+	defer cancel()
+
+	userID, teamID, ok := h.requireTeamAdmin(ctx, w, r, "only team owner/admin can manage forms")
+	if !ok {
+		return
+	}
+
+	formID, ok := h.teamFormID(ctx, w, r, teamID)
+	if !ok {
+		return
+	}
+
+	in, ok := h.decodeFormInput(ctx, w, r, teamID)
+	if !ok {
+		return
+	}
+
+	form, err := h.formStore.Update(ctx, formID, *in, time.Now().UTC())
+	if err != nil {
+		respondFormError(ctx, w, r, err)
+		return
+	}
+
+	logger.Info(ctx, "form updated", "team_id", teamID, "form_id", form.ID, "user_id", userID)
+	helper.RespondJSON(w, r, http.StatusOK, form)
+}
+
+func (h *TeamHandler) DeleteForm(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := deadline.Budget(r.Context(), 5*time.Second)
+	defer cancel()
+
+	userID, teamID, ok := h.requireTeamAdmin(ctx, w, r, "only team owner/admin can manage forms")
+	if !ok {
+		return
+	}
+
+	formID, ok := h.teamFormID(ctx, w, r, teamID)
+	if !ok {
+		return
+	}
+
+	if err := h.formStore.Delete(ctx, formID); err != nil {
+		respondFormError(ctx, w, r, err)
+		return
+	}
+
+	logger.Info(ctx, "form deleted", "team_id", teamID, "form_id", formID, "user_id", userID)
+	helper.RespondMessage(w, r, http.StatusOK, "form deleted")
+}
+
+// teamFormID parses {form_id} and hides forms of other teams behind a 404.
+func (h *TeamHandler) teamFormID(ctx context.Context, w http.ResponseWriter, r *http.Request, teamID uuid.UUID) (uuid.UUID, bool) {
+	formID, ok := parseID("form_id", r)
+	if !ok {
+		helper.RespondError(w, r, apperror.BadRequest("invalid form id"))
+		return uuid.Nil, false
+	}
+
+	form, err := h.formStore.Get(ctx, formID)
+	if err != nil && !errors.Is(err, formstore.ErrFormNotFound) {
+		internalError(ctx, w, r, err)
+		return uuid.Nil, false
+	}
+	if err != nil || form.TeamID != teamID {
+		helper.RespondError(w, r, apperror.NotFound("form not found"))
+		return uuid.Nil, false
+	}
+	return formID, true
+}
+
+func (h *TeamHandler) decodeFormInput(ctx context.Context, w http.ResponseWriter, r *http.Request, teamID uuid.UUID) (*formstore.FormInput, bool) {
+	r.Body = http.MaxBytesReader(w, r.Body, 1<<20)
+	dec := json.NewDecoder(r.Body)
+	dec.DisallowUnknownFields()
+	defer r.Body.Close()
+
+	var in formstore.FormInput
+	if err := dec.Decode(&in); err != nil {
+		badJsonCheck(ctx, w, r, "bad json")
+		return nil, false
+	}
+
+	if in.AssigneeID != nil {
+		isMember, err := h.teamsStore.IsMember(ctx, teamID, *in.AssigneeID)
+		if err != nil {
+			internalError(ctx, w, r, err)
+			return nil, false
+		}
+		if !isMember {
+			helper.RespondError(w, r, apperror.BadRequest("assignee must be a member of the team"))
+			return nil, false
+		}
+	}
+	return &in, true
+}
+
+func respondFormError(ctx context.Context, w http.ResponseWriter, r *http.Request, err error) {
+	switch {
+	case errors.Is(err, formstore.ErrInvalidFields), errors.Is(err, formstore.ErrInvalidInput):
+		helper.RespondError(w, r, apperror.BadRequest(err.Error()))
+	case errors.Is(err, formstore.ErrFormNameTaken):
+		helper.RespondError(w, r, apperror.Conflict("form name already used in team"))
+	case errors.Is(err, formstore.ErrFormNotFound):
+		helper.RespondError(w, r, apperror.NotFound("form not found"))
+	default:
+		internalError(ctx, w, r, err)
+	}
+}
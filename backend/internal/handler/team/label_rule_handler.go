@@ -0,0 +1,155 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/diagnosis/interactive-todo/api/types"
+	"github.com/diagnosis/interactive-todo/internal/apperror"
+	"github.com/diagnosis/interactive-todo/internal/deadline"
+	"github.com/diagnosis/interactive-todo/internal/helper"
+	"github.com/diagnosis/interactive-todo/internal/labelrules"
+	"github.com/diagnosis/interactive-todo/internal/logger"
+	labelrulestore "github.com/diagnosis/interactive-todo/internal/store/labelrules"
+)
+
+// maxLabelRules bounds the rules run on every task create and edit.
+const maxLabelRules = 100
+
+func (h *TeamHandler) ListLabelRules(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := deadline.Budget(r.Context(), 5*time.Second)
+	defer cancel()
+
+	teamID, ok := h.requireTeamMember(ctx, w, r, "only team members can view label rules")
+	if !ok {
+		return
+	}
+
+	rules, err := h.labelRuleStore.List(ctx, teamID)
+	if err != nil {
+		internalError(ctx, w, r, err)
+		return
+	}
+	helper.RespondJSON(w, r, http.StatusOK, types.LabelRuleListResponse{TeamID: teamID, Rules: rules})
+}
+
+// CreateLabelRule appends a rule; rules run in the order they were added.
+func (h *TeamHandler) CreateLabelRule(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := deadline.Budget(r.Context(), 5*time.Second)
+	defer cancel()
+
+	userID, teamID, ok := h.requireTeamAdmin(ctx, w, r, "only team owner/admin can change label rules")
+	if !ok {
+		return
+	}
+
+	in, ok := decodeLabelRule(w, r)
+	if !ok {
+		return
+	}
+
+	existing, err := h.labelRuleStore.List(ctx, teamID)
+	if err != nil {
+		internalError(ctx, w, r, err)
+		return
+	}
+	if len(existing) >= maxLabelRules {
+		helper.RespondError(w, r, apperror.BadRequest(fmt.Sprintf("a team can have at most %d label rules", maxLabelRules)))
+		return
+	}
+
+	rule, err := h.labelRuleStore.Create(ctx, teamID, userID, in, time.Now().UTC())
+	if err != nil {
+		if errors.Is(err, labelrules.ErrInvalid) {
+			helper.RespondError(w, r, apperror.BadRequest(err.Error()))
+			return
+		}
+		internalError(ctx, w, r, err)
+		return
+	}
+
+	logger.Info(ctx, "label rule created", "team_id", teamID, "rule_id", rule.ID, "user_id", userID)
+	helper.RespondJSON(w, r, http.StatusCreated, rule)
+}
+
+func (h *TeamHandler) UpdateLabelRule(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := deadline.Budget(r.Context(), 5*time.Second)
+	defer cancel()
+
+	userID, teamID, ok := h.requireTeamAdmin(ctx, w, r, "only team owner/admin can change label rules")
+	if !ok {
+		return
+	}
+
+	ruleID, ok := parseID("rule_id", r)
+	if !ok {
+		helper.RespondError(w, r, apperror.BadRequest("invalid rule id"))
+		return
+	}
+
+	in, ok := decodeLabelRule(w, r)
+	if !ok {
+		return
+	}
+
+	rule, err := h.labelRuleStore.Update(ctx, teamID, ruleID, in, time.Now().UTC())
+	if err != nil {
+		switch {
+		case errors.Is(err, labelrules.ErrInvalid):
+			helper.RespondError(w, r, apperror.BadRequest(err.Error()))
+		case errors.Is(err, labelrulestore.ErrRuleNotFound):
+			helper.RespondError(w, r, apperror.NotFound("label rule not found"))
+		default:
+			internalError(ctx, w, r, err)
+		}
+		return
+	}
+
+	logger.Info(ctx, "label rule updated", "team_id", teamID, "rule_id", rule.ID, "user_id", userID)
+	helper.RespondJSON(w, r, http.StatusOK, rule)
+}
+
+func (h *TeamHandler) DeleteLabelRule(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := deadline.Budget(r.Context(), 5*time.Second)
+	defer cancel()
+
+	userID, teamID, ok := h.requireTeamAdmin(ctx, w, r, "only team owner/admin can change label rules")
+	if !ok {
+		return
+	}
+
+	ruleID, ok := parseID("rule_id", r)
+	if !ok {
+		helper.RespondError(w, r, apperror.BadRequest("invalid rule id"))
+		return
+	}
+
+	if err := h.labelRuleStore.Delete(ctx, teamID, ruleID); err != nil {
+		if errors.Is(err, labelrulestore.ErrRuleNotFound) {
+			helper.RespondError(w, r, apperror.NotFound("label rule not found"))
+			return
+		}
+		internalError(ctx, w, r, err)
+		return
+	}
+
+	logger.Info(ctx, "label rule deleted", "team_id", teamID, "rule_id", ruleID, "user_id", userID)
+	helper.RespondMessage(w, r, http.StatusOK, "label rule deleted")
+}
+
+func decodeLabelRule(w http.ResponseWriter, r *http.Request) (types.LabelRuleRequest, bool) {
+	r.Body = http.MaxBytesReader(w, r.Body, 1<<20)
+	dec := json.NewDecoder(r.Body)
+	dec.DisallowUnknownFields()
+	defer r.Body.Close()
+
+	var in types.LabelRuleRequest
+	if err := dec.Decode(&in); err != nil {
+		badJsonCheck(r.Context(), w, r, "bad json")
+		return in, false
+	}
+	return in, true
+}
@@ -0,0 +1,153 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/diagnosis/interactive-todo/api/types"
+	"github.com/diagnosis/interactive-todo/internal/apperror"
+	"github.com/diagnosis/interactive-todo/internal/deadline"
+	"github.com/diagnosis/interactive-todo/internal/helper"
+	"github.com/diagnosis/interactive-todo/internal/logger"
+	middleware "github.com/diagnosis/interactive-todo/internal/middleware/auth"
+	labelstore "github.com/diagnosis/interactive-todo/internal/store/labels"
+)
+
+func (h *TeamHandler) ListLabels(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := deadline.Budget(r.Context(), 5*time.Second)
+	defer cancel()
+
+	teamID, ok := h.requireTeamMember(ctx, w, r, "only team members can view labels")
+	if !ok {
+		return
+	}
+
+	labels, err := h.labelStore.List(ctx, teamID)
+	if err != nil {
+		internalError(ctx, w, r, err)
+		return
+	}
+	helper.RespondJSON(w, r, http.StatusOK, types.LabelListResponse{TeamID: teamID, Labels: labels})
+}
+
+// CreateLabel adds a label to the team. Any member may, as they can already
+// create labels by naming them on a task.
+func (h *TeamHandler) CreateLabel(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := deadline.Budget(r.Context(), 5*time.Second)
+	defer cancel()
+
+	teamID, ok := h.requireTeamMember(ctx, w, r, "only team members can create labels")
+	if !ok {
+		return
+	}
+	userID, _ := middleware.GetUserIDFromContext(ctx)
+
+	name, ok := decodeLabelName(w, r)
+	if !ok {
+		return
+	}
+
+	label, err := h.labelStore.Create(ctx, teamID, name, time.Now().UTC())
+	if err != nil {
+		if errors.Is(err, labelstore.ErrLabelExists) {
+			helper.RespondError(w, r, apperror.Conflict("a label with this name already exists"))
+			return
+		}
+		internalError(ctx, w, r, err)
+		return
+	}
+
+	logger.Info(ctx, "label created", "team_id", teamID, "label_id", label.ID, "user_id", userID)
+	helper.RespondJSON(w, r, http.StatusCreated, label)
+}
+
+// RenameLabel changes a label's name on every task that has it.
+func (h *TeamHandler) RenameLabel(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := deadline.Budget(r.Context(), 5*time.Second)
+	defer cancel()
+
+	userID, teamID, ok := h.requireTeamAdmin(ctx, w, r, "only team owner/admin can rename labels")
+	if !ok {
+		return
+	}
+
+	labelID, ok := parseID("label_id", r)
+	if !ok {
+		helper.RespondError(w, r, apperror.BadRequest("invalid label id"))
+		return
+	}
+
+	name, ok := decodeLabelName(w, r)
+	if !ok {
+		return
+	}
+
+	label, err := h.labelStore.Rename(ctx, teamID, labelID, name)
+	if err != nil {
+		switch {
+		case errors.Is(err, labelstore.ErrLabelNotFound):
+			helper.RespondError(w, r, apperror.NotFound("label not found"))
+		case errors.Is(err, labelstore.ErrLabelExists):
+			helper.RespondError(w, r, apperror.Conflict("a label with this name already exists"))
+		default:
+			internalError(ctx, w, r, err)
+		}
+		return
+	}
+
+	logger.Info(ctx, "label renamed", "team_id", teamID, "label_id", label.ID, "user_id", userID)
+	helper.RespondJSON(w, r, http.StatusOK, label)
+}
+
+// DeleteLabel removes a label from the team and from all of its tasks,
+// along with any assignment route that used it.
+func (h *TeamHandler) DeleteLabel(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := deadline.Budget(r.Context(), 5*time.Second)
+	defer cancel()
+
+	userID, teamID, ok := h.requireTeamAdmin(ctx, w, r, "only team owner/admin can delete labels")
+	if !ok {
+		return
+	}
+
+	labelID, ok := parseID("label_id", r)
+	if !ok {
+		helper.RespondError(w, r, apperror.BadRequest("invalid label id"))
+		return
+	}
+
+	if err := h.labelStore.Delete(ctx, teamID, labelID); err != nil {
+		if errors.Is(err, labelstore.ErrLabelNotFound) {
+			helper.RespondError(w, r, apperror.NotFound("label not found"))
+			return
+		}
+		internalError(ctx, w, r, err)
+		return
+	}
+
+	logger.Info(ctx, "label deleted", "team_id", teamID, "label_id", labelID, "user_id", userID)
+	helper.RespondMessage(w, r, http.StatusOK, "label deleted")
+}
+
+func decodeLabelName(w http.ResponseWriter, r *http.Request) (string, bool) {
+	r.Body = http.MaxBytesReader(w, r.Body, 1<<20)
+	dec := json.NewDecoder(r.Body)
+	dec.DisallowUnknownFields()
+	defer r.Body.Close()
+
+	var in types.LabelRequest
+	if err := dec.Decode(&in); err != nil {
+		badJsonCheck(r.Context(), w, r, "bad json")
+		return "", false
+	}
+	name := strings.TrimSpace(in.Name)
+	if name == "" || len(name) > labelstore.MaxNameLength {
+		helper.RespondError(w, r, apperror.BadRequest(fmt.Sprintf("name is required (max %d chars)", labelstore.MaxNameLength)))
+		return "", false
+	}
+	return name, true
+}
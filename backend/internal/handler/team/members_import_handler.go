@@ -0,0 +1,275 @@
+package handler
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/diagnosis/interactive-todo/api/types"
+	"github.com/diagnosis/interactive-todo/internal/apperror"
+	"github.com/diagnosis/interactive-todo/internal/deadline"
+	"github.com/diagnosis/interactive-todo/internal/helper"
+	"github.com/diagnosis/interactive-todo/internal/logger"
+	invitationstore "github.com/diagnosis/interactive-todo/internal/store/invitations"
+	teamstore "github.com/diagnosis/interactive-todo/internal/store/teams"
+	userstore "github.com/diagnosis/interactive-todo/internal/store/users"
+	"github.com/google/uuid"
+)
+
+const (
+	maxImportRows = 500
+	// a few queries per row
+	importBudget = 30 * time.Second
+)
+
+// ImportMembers adds a list of people to the team by email. Users with an
+// account become members; other emails get an invitation that turns into
+// a membership when they register. Each entry is reported on its own, so
+// one bad row does not stop the rest. The body is JSON
+// (types.MemberImportRequest) or, with Content-Type text/csv, rows of
+// email and role with an optional header.
+func (h *TeamHandler) ImportMembers(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := deadline.Budget(r.Context(), importBudget)
+	defer cancel()
+
+	userID, teamID, ok := h.requireTeamAdmin(ctx, w, r, "only team owner/admin can import members")
+	if !ok {
+		return
+	}
+
+	r.Body = http.MaxBytesReader(w, r.Body, 1<<20)
+	defer r.Body.Close()
+
+	var entries []types.MemberImportEntry
+	mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	switch mediaType {
+	case "text/csv":
+		var err error
+		if entries, err = readImportCSV(r.Body); err != nil {
+			helper.RespondError(w, r, apperror.BadRequest(err.Error()))
+			return
+		}
+	case "", "application/json":
+		var in types.MemberImportRequest
+		dec := json.NewDecoder(r.Body)
+		dec.DisallowUnknownFields()
+		if err := dec.Decode(&in); err != nil {
+			badJsonCheck(ctx, w, r, "bad json")
+			return
+		}
+		entries = in.Members
+	default:
+		helper.RespondError(w, r, apperror.UnsupportedMediaType("send application/json or text/csv"))
+		return
+	}
+	if len(entries) == 0 {
+		helper.RespondError(w, r, apperror.BadRequest("no members to import"))
+		return
+	}
+	if len(entries) > maxImportRows {
+		helper.RespondError(w, r, apperror.BadRequest(fmt.Sprintf("at most %d members per import", maxImportRows)))
+		return
+	}
+
+	members, err := h.teamsStore.ListMembersInTeam(ctx, teamID)
+	if err != nil {
+		internalError(ctx, w, r, err)
+		return
+	}
+	isMember := make(map[uuid.UUID]bool, len(members))
+	for _, m := range members {
+		isMember[m.UserID] = true
+	}
+
+	now := time.Now().UTC()
+	resp := types.MemberImportResponse{TeamID: teamID, Rows: make([]types.MemberImportRow, 0, len(entries))}
+	seen := make(map[string]bool, len(entries))
+	for i, e := range entries {
+		row := h.importMember(ctx, teamID, userID, i+1, e, seen, isMember, now)
+		switch row.Status {
+		case types.ImportAdded:
+			resp.Added++
+		case types.ImportInvited:
+			resp.Invited++
+		case types.ImportAlreadyMember:
+			resp.AlreadyMember++
+		default:
+			resp.Failed++
+		}
+		resp.Rows = append(resp.Rows, row)
+	}
+
+	logger.Info(ctx, "members imported", "team_id", teamID, "user_id", userID,
+		"added", resp.Added, "invited", resp.Invited, "already_member", resp.AlreadyMember, "failed", resp.Failed)
+	helper.RespondJSON(w, r, http.StatusOK, resp)
+}
+
+func (h *TeamHandler) importMember(
+	ctx context.Context,
+	teamID, inviterID uuid.UUID,
+	n int,
+	e types.MemberImportEntry,
+	seen map[string]bool,
+	isMember map[uuid.UUID]bool,
+	now time.Time,
+) types.MemberImportRow {
+	email := strings.TrimSpace(strings.ToLower(e.Email))
+	role := e.Role
+	if role == "" {
+		role = teamstore.RoleMember
+	}
+	row := types.MemberImportRow{Row: n, Email: email, Role: role, Status: types.ImportFailed}
+
+	switch {
+	case len(email) < 4 || !strings.Contains(email, "@") || strings.ContainsAny(email, " \t,;<>"):
+		row.Error = "invalid email address"
+		return row
+	case role == teamstore.RoleOwner:
+		row.Error = "owner cannot be imported; transfer ownership instead"
+		return row
+	case !isValidTeamRole(role):
+		row.Error = "role must be admin or member"
+		return row
+	case seen[email]:
+		row.Error = "email appears more than once"
+		return row
+	}
+	seen[email] = true
+
+	user, err := h.userStore.GetUserByEmail(ctx, email)
+	switch {
+	case errors.Is(err, userstore.ErrNotFound):
+		inv, err := h.invitationStore.Invite(ctx, teamID, email, role, inviterID, now)
+		if err != nil {
+			logger.Error(ctx, "import members: invite failed", "row", n, "err", err)
+			row.Error = "internal error"
+			return row
+		}
+		row.Status = types.ImportInvited
+		row.InvitationID = &inv.ID
+		return row
+	case err != nil:
+		logger.Error(ctx, "import members: user lookup failed", "row", n, "err", err)
+		row.Error = "internal error"
+		return row
+	}
+
+	row.UserID = &user.ID
+	// an existing member keeps their role; changing roles is not an import
+	if isMember[user.ID] {
+		row.Status = types.ImportAlreadyMember
+		return row
+	}
+	if err := h.teamsStore.AddMember(ctx, teamID, inviterID, user.ID, role, now); err != nil {
+		logger.Error(ctx, "import members: add member failed", "row", n, "err", err)
+		row.Error = "internal error"
+		return row
+	}
+	isMember[user.ID] = true
+	row.Status = types.ImportAdded
+	return row
+}
+
+// readImportCSV reads rows of email and role. A first row with an "email"
+// column is a header and may put the columns in any order.
+func readImportCSV(body io.Reader) ([]types.MemberImportEntry, error) {
+	cr := csv.NewReader(body)
+	cr.FieldsPerRecord = -1
+	cr.TrimLeadingSpace = true
+	records, err := cr.ReadAll()
+	if err != nil {
+		var maxErr *http.MaxBytesError
+		if errors.As(err, &maxErr) {
+			return nil, errors.New("body is larger than 1 MB")
+		}
+		return nil, fmt.Errorf("invalid csv: %v", err)
+	}
+
+	emailCol, roleCol := 0, 1
+	if len(records) > 0 {
+		email, role := -1, -1
+		for i, f := range records[0] {
+			switch strings.ToLower(strings.TrimSpace(f)) {
+			case "email":
+				email = i
+			case "role":
+				role = i
+			}
+		}
+		if email >= 0 {
+			emailCol, roleCol = email, role
+			records = records[1:]
+		}
+	}
+
+	entries := make([]types.MemberImportEntry, 0, len(records))
+	for _, rec := range records {
+		// the reader skips blank lines; rows of empty fields are skipped too
+		if strings.TrimSpace(strings.Join(rec, "")) == "" {
+			continue
+		}
+		var e types.MemberImportEntry
+		if emailCol < len(rec) {
+			e.Email = rec[emailCol]
+		}
+		if roleCol >= 0 && roleCol < len(rec) {
+			e.Role = types.TeamRole(strings.ToLower(strings.TrimSpace(rec[roleCol])))
+		}
+		entries = append(entries, e)
+	}
+	return entries, nil
+}
+
+// ListInvitations returns the team's invitations still waiting for a
+// registration.
+func (h *TeamHandler) ListInvitations(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := deadline.Budget(r.Context(), 5*time.Second)
+	defer cancel()
+
+	_, teamID, ok := h.requireTeamAdmin(ctx, w, r, "only team owner/admin can view invitations")
+	if !ok {
+		return
+	}
+
+	invitations, err := h.invitationStore.List(ctx, teamID, time.Now().UTC())
+	if err != nil {
+		internalError(ctx, w, r, err)
+		return
+	}
+	helper.RespondJSON(w, r, http.StatusOK, types.InvitationListResponse{TeamID: teamID, Invitations: invitations})
+}
+
+func (h *TeamHandler) RevokeInvitation(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := deadline.Budget(r.Context(), 5*time.Second)
+	defer cancel()
+
+	userID, teamID, ok := h.requireTeamAdmin(ctx, w, r, "only team owner/admin can revoke invitations")
+	if !ok {
+		return
+	}
+
+	invitationID, ok := parseID("invitation_id", r)
+	if !ok {
+		helper.RespondError(w, r, apperror.BadRequest("invalid invitation id"))
+		return
+	}
+
+	if err := h.invitationStore.Delete(ctx, teamID, invitationID); err != nil {
+		if errors.Is(err, invitationstore.ErrInvitationNotFound) {
+			helper.RespondError(w, r, apperror.NotFound("invitation not found"))
+			return
+		}
+		internalError(ctx, w, r, err)
+		return
+	}
+
+	logger.Info(ctx, "invitation revoked", "team_id", teamID, "invitation_id", invitationID, "user_id", userID)
+	helper.RespondMessage(w, r, http.StatusOK, "invitation revoked")
+}
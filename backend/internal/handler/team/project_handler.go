@@ -0,0 +1,202 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/diagnosis/interactive-todo/api/types"
+	"github.com/diagnosis/interactive-todo/internal/apperror"
+	"github.com/diagnosis/interactive-todo/internal/deadline"
+	"github.com/diagnosis/interactive-todo/internal/helper"
+	"github.com/diagnosis/interactive-todo/internal/logger"
+	projectstore "github.com/diagnosis/interactive-todo/internal/store/projects"
+)
+
+const maxProjectDescriptionLength = 2000
+
+func (h *TeamHandler) ListProjects(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := deadline.Budget(r.Context(), 5*time.Second)
+	defer cancel()
+
+	teamID, ok := h.requireTeamMember(ctx, w, r, "only team members can view projects")
+	if !ok {
+		return
+	}
+
+	projects, err := h.projectStore.List(ctx, teamID)
+	if err != nil {
+		internalError(ctx, w, r, err)
+		return
+	}
+	helper.RespondJSON(w, r, http.StatusOK, types.ProjectListResponse{TeamID: teamID, Projects: projects})
+}
+
+func (h *TeamHandler) GetProject(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := deadline.Budget(r.Context(), 5*time.Second)
+	defer cancel()
+
+	teamID, ok := h.requireTeamMember(ctx, w, r, "only team members can view projects")
+	if !ok {
+		return
+	}
+
+	projectID, ok := parseID("project_id", r)
+	if !ok {
+		helper.RespondError(w, r, apperror.BadRequest("invalid project id"))
+		return
+	}
+
+	project, err := h.projectStore.Get(ctx, teamID, projectID)
+	if err != nil {
+		if errors.Is(err, projectstore.ErrProjectNotFound) {
+			helper.RespondError(w, r, apperror.NotFound("project not found"))
+			return
+		}
+		internalError(ctx, w, r, err)
+		return
+	}
+	helper.RespondJSON(w, r, http.StatusOK, project)
+}
+
+func (h *TeamHandler) CreateProject(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := deadline.Budget(r.Context(), 5*time.Second)
+	defer cancel()
+
+	userID, teamID, ok := h.requireTeamAdmin(ctx, w, r, "only team owner/admin can create projects")
+	if !ok {
+		return
+	}
+
+	r.Body = http.MaxBytesReader(w, r.Body, 1<<20)
+	dec := json.NewDecoder(r.Body)
+	dec.DisallowUnknownFields()
+	defer r.Body.Close()
+
+	var in types.CreateProjectRequest
+	if err := dec.Decode(&in); err != nil {
+		badJsonCheck(ctx, w, r, "bad json")
+		return
+	}
+	if !validProjectName(w, r, in.Name) || !validProjectDescription(w, r, in.Description) {
+		return
+	}
+	if in.Description != nil && *in.Description == "" {
+		in.Description = nil
+	}
+
+	project, err := h.projectStore.Create(ctx, teamID, in.Name, in.Description, userID, time.Now().UTC())
+	if err != nil {
+		if errors.Is(err, projectstore.ErrProjectExists) {
+			helper.RespondError(w, r, apperror.Conflict("a project with this name already exists"))
+			return
+		}
+		internalError(ctx, w, r, err)
+		return
+	}
+
+	logger.Info(ctx, "project created", "team_id", teamID, "project_id", project.ID, "user_id", userID)
+	helper.RespondJSON(w, r, http.StatusCreated, project)
+}
+
+func (h *TeamHandler) UpdateProject(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := deadline.Budget(r.Context(), 5*time.Second)
+	defer cancel()
+
+	userID, teamID, ok := h.requireTeamAdmin(ctx, w, r, "only team owner/admin can update projects")
+	if !ok {
+		return
+	}
+
+	projectID, ok := parseID("project_id", r)
+	if !ok {
+		helper.RespondError(w, r, apperror.BadRequest("invalid project id"))
+		return
+	}
+
+	r.Body = http.MaxBytesReader(w, r.Body, 1<<20)
+	dec := json.NewDecoder(r.Body)
+	dec.DisallowUnknownFields()
+	defer r.Body.Close()
+
+	var in types.UpdateProjectRequest
+	if err := dec.Decode(&in); err != nil {
+		badJsonCheck(ctx, w, r, "bad json")
+		return
+	}
+	if in.Name == nil && in.Description == nil {
+		helper.RespondError(w, r, apperror.BadRequest("name or description is required"))
+		return
+	}
+	if in.Name != nil && !validProjectName(w, r, *in.Name) {
+		return
+	}
+	if !validProjectDescription(w, r, in.Description) {
+		return
+	}
+
+	project, err := h.projectStore.Update(ctx, teamID, projectID, in.Name, in.Description, time.Now().UTC())
+	if err != nil {
+		switch {
+		case errors.Is(err, projectstore.ErrProjectNotFound):
+			helper.RespondError(w, r, apperror.NotFound("project not found"))
+		case errors.Is(err, projectstore.ErrProjectExists):
+			helper.RespondError(w, r, apperror.Conflict("a project with this name already exists"))
+		default:
+			internalError(ctx, w, r, err)
+		}
+		return
+	}
+
+	logger.Info(ctx, "project updated", "team_id", teamID, "project_id", projectID, "user_id", userID)
+	helper.RespondJSON(w, r, http.StatusOK, project)
+}
+
+// DeleteProject removes a project. Its tasks are kept, without a project.
+func (h *TeamHandler) DeleteProject(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := deadline.Budget(r.Context(), 5*time.Second)
+	defer cancel()
+
+	userID, teamID, ok := h.requireTeamAdmin(ctx, w, r, "only team owner/admin can delete projects")
+	if !ok {
+		return
+	}
+
+	projectID, ok := parseID("project_id", r)
+	if !ok {
+		helper.RespondError(w, r, apperror.BadRequest("invalid project id"))
+		return
+	}
+
+	if err := h.projectStore.Delete(ctx, teamID, projectID); err != nil {
+		if errors.Is(err, projectstore.ErrProjectNotFound) {
+			helper.RespondError(w, r, apperror.NotFound("project not found"))
+			return
+		}
+		internalError(ctx, w, r, err)
+		return
+	}
+
+	logger.Info(ctx, "project deleted", "team_id", teamID, "project_id", projectID, "user_id", userID)
+	helper.RespondMessage(w, r, http.StatusOK, "project deleted")
+}
+
+func validProjectName(w http.ResponseWriter, r *http.Request, name string) bool {
+	name = strings.TrimSpace(name)
+	if name == "" || len(name) > projectstore.MaxNameLength {
+		helper.RespondError(w, r, apperror.BadRequest(fmt.Sprintf("name is required (max %d chars)", projectstore.MaxNameLength)))
+		return false
+	}
+	return true
+}
+
+func validProjectDescription(w http.ResponseWriter, r *http.Request, description *string) bool {
+	if description != nil && len(*description) > maxProjectDescriptionLength {
+		helper.RespondError(w, r, apperror.BadRequest(fmt.Sprintf("description too long (max %d chars)", maxProjectDescriptionLength)))
+		return false
+	}
+	return true
+}
@@ -0,0 +1,306 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/diagnosis/interactive-todo/api/types"
+	"github.com/diagnosis/interactive-todo/internal/apperror"
+	"github.com/diagnosis/interactive-todo/internal/deadline"
+	"github.com/diagnosis/interactive-todo/internal/helper"
+	"github.com/diagnosis/interactive-todo/internal/logger"
+	labelstore "github.com/diagnosis/interactive-todo/internal/store/labels"
+	rotationstore "github.com/diagnosis/interactive-todo/internal/store/rotations"
+	"github.com/google/uuid"
+)
+
+func (h *TeamHandler) ListRotations(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := deadline.Budget(r.Context(), 5*time.Second)
+	defer cancel()
+
+	teamID, ok := h.requireTeamMember(ctx, w, r, "only team members can view rotations")
+	if !ok {
+		return
+	}
+
+	rotations, err := h.rotationStore.List(ctx, teamID)
+	if err != nil {
+		internalError(ctx, w, r, err)
+		return
+	}
+	helper.RespondJSON(w, r, http.StatusOK, types.RotationListResponse{TeamID: teamID, Rotations: rotations})
+}
+
+func (h *TeamHandler) GetRotation(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := deadline.Budget(r.Context(), 5*time.Second)
+	defer cancel()
+
+	teamID, ok := h.requireTeamMember(ctx, w, r, "only team members can view rotations")
+	if !ok {
+		return
+	}
+
+	rotationID, ok := parseID("rotation_id", r)
+	if !ok {
+		helper.RespondError(w, r, apperror.BadRequest("invalid rotation id"))
+		return
+	}
+
+	rotation, err := h.rotationStore.Get(ctx, teamID, rotationID)
+	if err != nil {
+		if errors.Is(err, rotationstore.ErrRotationNotFound) {
+			helper.RespondError(w, r, apperror.NotFound("rotation not found"))
+			return
+		}
+		internalError(ctx, w, r, err)
+		return
+	}
+	helper.RespondJSON(w, r, http.StatusOK, rotation)
+}
+
+func (h *TeamHandler) CreateRotation(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := deadline.Budget(r.Context(), 5*time.Second)
+	defer cancel()
+
+	userID, teamID, ok := h.requireTeamAdmin(ctx, w, r, "only team owner/admin can create rotations")
+	if !ok {
+		return
+	}
+
+	r.Body = http.MaxBytesReader(w, r.Body, 1<<20)
+	dec := json.NewDecoder(r.Body)
+	dec.DisallowUnknownFields()
+	defer r.Body.Close()
+
+	var in types.CreateRotationRequest
+	if err := dec.Decode(&in); err != nil {
+		badJsonCheck(ctx, w, r, "bad json")
+		return
+	}
+
+	now := time.Now().UTC()
+	if !validRotationName(w, r, in.Name) || !validRotationCadence(w, r, in.Cadence) ||
+		!validRotationMembers(w, r, in.MemberIDs) || !validHandoffAt(w, r, in.HandoffAt, now) {
+		return
+	}
+	if in.Labels, ok = validRotationLabels(w, r, in.Labels); !ok {
+		return
+	}
+	if !h.rotationLabelsFree(w, r, teamID, uuid.Nil, in.Labels) {
+		return
+	}
+	if in.HandoffAt == nil {
+		first := now.Add(rotationstore.Period(in.Cadence))
+		in.HandoffAt = &first
+	}
+
+	rotation, err := h.rotationStore.Create(ctx, teamID, in, userID, now)
+	if err != nil {
+		rotationError(w, r, err)
+		return
+	}
+
+	logger.Info(ctx, "rotation created", "team_id", teamID, "rotation_id", rotation.ID, "user_id", userID)
+	helper.RespondJSON(w, r, http.StatusCreated, rotation)
+}
+
+func (h *TeamHandler) UpdateRotation(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := deadline.Budget(r.Context(), 5*time.Second)
+	defer cancel()
+
+	userID, teamID, ok := h.requireTeamAdmin(ctx, w, r, "only team owner/admin can update rotations")
+	if !ok {
+		return
+	}
+
+	rotationID, ok := parseID("rotation_id", r)
+	if !ok {
+		helper.RespondError(w, r, apperror.BadRequest("invalid rotation id"))
+		return
+	}
+
+	r.Body = http.MaxBytesReader(w, r.Body, 1<<20)
+	dec := json.NewDecoder(r.Body)
+	dec.DisallowUnknownFields()
+	defer r.Body.Close()
+
+	var in types.UpdateRotationRequest
+	if err := dec.Decode(&in); err != nil {
+		badJsonCheck(ctx, w, r, "bad json")
+		return
+	}
+	if in.Name == nil && in.Cadence == nil && in.MemberIDs == nil && in.Labels == nil && in.HandoffAt == nil {
+		helper.RespondError(w, r, apperror.BadRequest("at least one of name, cadence, member_ids, labels or handoff_at must be provided"))
+		return
+	}
+
+	now := time.Now().UTC()
+	if in.Name != nil && !validRotationName(w, r, *in.Name) {
+		return
+	}
+	if in.Cadence != nil && !validRotationCadence(w, r, *in.Cadence) {
+		return
+	}
+	if in.MemberIDs != nil && !validRotationMembers(w, r, in.MemberIDs) {
+		return
+	}
+	if !validHandoffAt(w, r, in.HandoffAt, now) {
+		return
+	}
+	if in.Labels != nil {
+		if in.Labels, ok = validRotationLabels(w, r, in.Labels); !ok {
+			return
+		}
+		if !h.rotationLabelsFree(w, r, teamID, rotationID, in.Labels) {
+			return
+		}
+	}
+
+	rotation, err := h.rotationStore.Update(ctx, teamID, rotationID, in, now)
+	if err != nil {
+		rotationError(w, r, err)
+		return
+	}
+
+	logger.Info(ctx, "rotation updated", "team_id", teamID, "rotation_id", rotationID, "user_id", userID)
+	helper.RespondJSON(w, r, http.StatusOK, rotation)
+}
+
+// DeleteRotation removes a rotation. Tasks it assigned keep their assignee.
+func (h *TeamHandler) DeleteRotation(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := deadline.Budget(r.Context(), 5*time.Second)
+	defer cancel()
+
+	userID, teamID, ok := h.requireTeamAdmin(ctx, w, r, "only team owner/admin can delete rotations")
+	if !ok {
+		return
+	}
+
+	rotationID, ok := parseID("rotation_id", r)
+	if !ok {
+		helper.RespondError(w, r, apperror.BadRequest("invalid rotation id"))
+		return
+	}
+
+	if err := h.rotationStore.Delete(ctx, teamID, rotationID); err != nil {
+		if errors.Is(err, rotationstore.ErrRotationNotFound) {
+			helper.RespondError(w, r, apperror.NotFound("rotation not found"))
+			return
+		}
+		internalError(ctx, w, r, err)
+		return
+	}
+
+	logger.Info(ctx, "rotation deleted", "team_id", teamID, "rotation_id", rotationID, "user_id", userID)
+	helper.RespondMessage(w, r, http.StatusOK, "rotation deleted")
+}
+
+// rotationLabelsFree refuses labels another rotation of the team already
+// has, so each label has one on-call.
+func (h *TeamHandler) rotationLabelsFree(w http.ResponseWriter, r *http.Request, teamID, rotationID uuid.UUID, labels []string) bool {
+	if len(labels) == 0 {
+		return true
+	}
+	rotations, err := h.rotationStore.List(r.Context(), teamID)
+	if err != nil {
+		internalError(r.Context(), w, r, err)
+		return false
+	}
+	for _, other := range rotations {
+		if other.ID == rotationID {
+			continue
+		}
+		for _, taken := range other.Labels {
+			for _, l := range labels {
+				if strings.EqualFold(l, taken) {
+					helper.RespondError(w, r, apperror.Conflict(fmt.Sprintf("label %q already belongs to rotation %q", l, other.Name)))
+					return false
+				}
+			}
+		}
+	}
+	return true
+}
+
+func rotationError(w http.ResponseWriter, r *http.Request, err error) {
+	switch {
+	case errors.Is(err, rotationstore.ErrRotationNotFound):
+		helper.RespondError(w, r, apperror.NotFound("rotation not found"))
+	case errors.Is(err, rotationstore.ErrRotationExists):
+		helper.RespondError(w, r, apperror.Conflict("a rotation with this name already exists"))
+	case errors.Is(err, rotationstore.ErrNotMember):
+		helper.RespondError(w, r, apperror.BadRequest("every member must be in the team"))
+	default:
+		internalError(r.Context(), w, r, err)
+	}
+}
+
+func validRotationName(w http.ResponseWriter, r *http.Request, name string) bool {
+	name = strings.TrimSpace(name)
+	if name == "" || len(name) > rotationstore.MaxNameLength {
+		helper.RespondError(w, r, apperror.BadRequest(fmt.Sprintf("name is required (max %d chars)", rotationstore.MaxNameLength)))
+		return false
+	}
+	return true
+}
+
+func validRotationCadence(w http.ResponseWriter, r *http.Request, c types.RotationCadence) bool {
+	if c != types.RotationDaily && c != types.RotationWeekly {
+		helper.RespondError(w, r, apperror.BadRequest("cadence must be daily or weekly"))
+		return false
+	}
+	return true
+}
+
+func validRotationMembers(w http.ResponseWriter, r *http.Request, ids []uuid.UUID) bool {
+	if len(ids) == 0 || len(ids) > rotationstore.MaxMembers {
+		helper.RespondError(w, r, apperror.BadRequest(fmt.Sprintf("member_ids needs 1 to %d members", rotationstore.MaxMembers)))
+		return false
+	}
+	seen := make(map[uuid.UUID]bool, len(ids))
+	for _, id := range ids {
+		if id == uuid.Nil || seen[id] {
+			helper.RespondError(w, r, apperror.BadRequest("member_ids must be distinct user ids"))
+			return false
+		}
+		seen[id] = true
+	}
+	return true
+}
+
+func validHandoffAt(w http.ResponseWriter, r *http.Request, t *time.Time, now time.Time) bool {
+	if t != nil && !t.After(now) {
+		helper.RespondError(w, r, apperror.BadRequest("handoff_at must be in the future"))
+		return false
+	}
+	return true
+}
+
+// validRotationLabels trims the labels; they are matched by name, so they
+// need not exist in the team yet.
+func validRotationLabels(w http.ResponseWriter, r *http.Request, labels []string) ([]string, bool) {
+	if len(labels) > rotationstore.MaxLabels {
+		helper.RespondError(w, r, apperror.BadRequest(fmt.Sprintf("at most %d labels", rotationstore.MaxLabels)))
+		return nil, false
+	}
+	out := make([]string, 0, len(labels))
+	seen := make(map[string]bool, len(labels))
+	for _, l := range labels {
+		l = strings.TrimSpace(l)
+		if l == "" || len(l) > labelstore.MaxNameLength {
+			helper.RespondError(w, r, apperror.BadRequest(fmt.Sprintf("labels must be 1-%d chars", labelstore.MaxNameLength)))
+			return nil, false
+		}
+		if seen[strings.ToLower(l)] {
+			helper.RespondError(w, r, apperror.BadRequest(fmt.Sprintf("label %q given more than once", l)))
+			return nil, false
+		}
+		seen[strings.ToLower(l)] = true
+		out = append(out, l)
+	}
+	return out, true
+}
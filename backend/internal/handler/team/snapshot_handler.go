@@ -0,0 +1,199 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/diagnosis/interactive-todo/api/types"
+	"github.com/diagnosis/interactive-todo/internal/apperror"
+	"github.com/diagnosis/interactive-todo/internal/deadline"
+	"github.com/diagnosis/interactive-todo/internal/helper"
+	"github.com/diagnosis/interactive-todo/internal/logger"
+	snapshotstore "github.com/diagnosis/interactive-todo/internal/store/snapshots"
+	userstore "github.com/diagnosis/interactive-todo/internal/store/users"
+)
+
+// maxSnapshots bounds the copies kept per team; delete old ones to make
+// room.
+const maxSnapshots = 20
+
+func (h *TeamHandler) ListSnapshots(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := deadline.Budget(r.Context(), 5*time.Second)
+	defer cancel()
+
+	_, teamID, ok := h.requireTeamAdmin(ctx, w, r, "only team owner/admin can manage snapshots")
+	if !ok {
+		return
+	}
+
+	snaps, err := h.snapshotStore.ListForTeam(ctx, teamID)
+	if err != nil {
+		internalError(ctx, w, r, err)
+		return
+	}
+
+	helper.RespondJSON(w, r, http.StatusOK, types.SnapshotListResponse{TeamID: teamID, Snapshots: snaps})
+}
+
+// CreateSnapshot stores a point-in-time copy of the team's tasks, members
+// and labels, e.g. before a bulk import or an automation change.
+func (h *TeamHandler) CreateSnapshot(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := deadline.Budget(r.Context(), 30*time.Second)
+	defer cancel()
+
+	userID, teamID, ok := h.requireTeamAdmin(ctx, w, r, "only team owner/admin can manage snapshots")
+	if !ok {
+		return
+	}
+
+	var in struct {
+		Name string `json:"name"`
+	}
+	if !decodeSnapshotBody(ctx, w, r, &in) {
+		return
+	}
+	name := strings.TrimSpace(in.Name)
+	if name == "" || len(name) > 100 {
+		helper.RespondError(w, r, apperror.BadRequest("name is required (max 100 chars)"))
+		return
+	}
+
+	settings, err := h.teamsStore.GetSettings(ctx, teamID)
+	if err != nil {
+		internalError(ctx, w, r, err)
+		return
+	}
+	// a snapshot would hold the descriptions in the clear
+	if settings.Confidential {
+		helper.RespondError(w, r, apperror.Conflict("confidential teams cannot be snapshotted"))
+		return
+	}
+
+	existing, err := h.snapshotStore.ListForTeam(ctx, teamID)
+	if err != nil {
+		internalError(ctx, w, r, err)
+		return
+	}
+	if len(existing) >= maxSnapshots {
+		helper.RespondError(w, r, apperror.Conflict(fmt.Sprintf("a team can keep at most %d snapshots; delete one first", maxSnapshots)))
+		return
+	}
+
+	snap, err := h.snapshotStore.Create(ctx, teamID, name, userID, time.Now().UTC())
+	if err != nil {
+		internalError(ctx, w, r, err)
+		return
+	}
+
+	logger.Info(ctx, "snapshot created", "team_id", teamID, "snapshot_id", snap.ID, "tasks", snap.TaskCount, "user_id", userID)
+	helper.RespondJSON(w, r, http.StatusCreated, snap)
+}
+
+func (h *TeamHandler) DeleteSnapshot(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := deadline.Budget(r.Context(), 5*time.Second)
+	defer cancel()
+
+	userID, teamID, ok := h.requireTeamAdmin(ctx, w, r, "only team owner/admin can manage snapshots")
+	if !ok {
+		return
+	}
+
+	snapshotID, ok := parseID("snapshot_id", r)
+	if !ok {
+		helper.RespondError(w, r, apperror.BadRequest("invalid snapshot id"))
+		return
+	}
+
+	if err := h.snapshotStore.Delete(ctx, teamID, snapshotID); err != nil {
+		if errors.Is(err, snapshotstore.ErrSnapshotNotFound) {
+			helper.RespondError(w, r, apperror.NotFound("snapshot not found"))
+			return
+		}
+		internalError(ctx, w, r, err)
+		return
+	}
+
+	logger.Info(ctx, "snapshot deleted", "team_id", teamID, "snapshot_id", snapshotID, "user_id", userID)
+	helper.RespondMessage(w, r, http.StatusOK, "snapshot deleted")
+}
+
+// RestoreSnapshot creates a new team from a snapshot; the source team is
+// left as it is. The caller owns the new team, so they must be allowed to
+// create teams.
+func (h *TeamHandler) RestoreSnapshot(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := deadline.Budget(r.Context(), 30*time.Second)
+	defer cancel()
+
+	userID, teamID, ok := h.requireTeamAdmin(ctx, w, r, "only team owner/admin can manage snapshots")
+	if !ok {
+		return
+	}
+
+	snapshotID, ok := parseID("snapshot_id", r)
+	if !ok {
+		helper.RespondError(w, r, apperror.BadRequest("invalid snapshot id"))
+		return
+	}
+
+	user, err := h.userStore.GetUserByID(ctx, userID)
+	if err != nil {
+		internalError(ctx, w, r, err)
+		return
+	}
+	if user.UserType != userstore.TypeAdmin && user.UserType != userstore.TypeTaskManager {
+		forbiddenError(ctx, w, r, "only admin or task_manager can create team")
+		return
+	}
+
+	var in struct {
+		TeamName string `json:"team_name"`
+	}
+	if !decodeSnapshotBody(ctx, w, r, &in) {
+		return
+	}
+	name := strings.TrimSpace(in.TeamName)
+	if name == "" || len(name) > 100 {
+		helper.RespondError(w, r, apperror.BadRequest("team_name is required (max 100 chars)"))
+		return
+	}
+
+	restored, err := h.snapshotStore.Restore(ctx, teamID, snapshotID, name, userID, time.Now().UTC())
+	if err != nil {
+		switch {
+		case errors.Is(err, snapshotstore.ErrSnapshotNotFound):
+			helper.RespondError(w, r, apperror.NotFound("snapshot not found"))
+		case errors.Is(err, snapshotstore.ErrTeamNameTaken):
+			helper.RespondError(w, r, apperror.Conflict("team name already in use"))
+		default:
+			internalError(ctx, w, r, err)
+		}
+		return
+	}
+
+	logger.Info(ctx, "snapshot restored",
+		"team_id", teamID,
+		"snapshot_id", snapshotID,
+		"new_team_id", restored.TeamID,
+		"tasks", restored.Tasks,
+		"user_id", userID,
+	)
+	helper.RespondJSON(w, r, http.StatusCreated, restored)
+}
+
+func decodeSnapshotBody(ctx context.Context, w http.ResponseWriter, r *http.Request, dst any) bool {
+	r.Body = http.MaxBytesReader(w, r.Body, 1<<20)
+	dec := json.NewDecoder(r.Body)
+	dec.DisallowUnknownFields()
+	defer r.Body.Close()
+
+	if err := dec.Decode(dst); err != nil {
+		badJsonCheck(ctx, w, r, "bad json")
+		return false
+	}
+	return true
+}
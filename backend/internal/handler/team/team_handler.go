@@ -8,26 +8,77 @@ import (
 	"strings"
 	"time"
 
+	"github.com/diagnosis/interactive-todo/api/types"
 	"github.com/diagnosis/interactive-todo/internal/apperror"
+	"github.com/diagnosis/interactive-todo/internal/deadline"
+	"github.com/diagnosis/interactive-todo/internal/directory"
 	"github.com/diagnosis/interactive-todo/internal/helper"
 	"github.com/diagnosis/interactive-todo/internal/logger"
 	middleware "github.com/diagnosis/interactive-todo/internal/middleware/auth"
+	assignmentstore "github.com/diagnosis/interactive-todo/internal/store/assignment"
+	automationstore "github.com/diagnosis/interactive-todo/internal/store/automations"
+	calendarstore "github.com/diagnosis/interactive-todo/internal/store/calendars"
+	customfieldstore "github.com/diagnosis/interactive-todo/internal/store/customfields"
+	directorystore "github.com/diagnosis/interactive-todo/internal/store/directory"
+	formstore "github.com/diagnosis/interactive-todo/internal/store/forms"
+	invitationstore "github.com/diagnosis/interactive-todo/internal/store/invitations"
+	labelrulestore "github.com/diagnosis/interactive-todo/internal/store/labelrules"
+	labelstore "github.com/diagnosis/interactive-todo/internal/store/labels"
+	projectstore "github.com/diagnosis/interactive-todo/internal/store/projects"
+	rotationstore "github.com/diagnosis/interactive-todo/internal/store/rotations"
+	snapshotstore "github.com/diagnosis/interactive-todo/internal/store/snapshots"
 	teamstore "github.com/diagnosis/interactive-todo/internal/store/teams"
 	userstore "github.com/diagnosis/interactive-todo/internal/store/users"
+	workflowstore "github.com/diagnosis/interactive-todo/internal/store/workflows"
 	"github.com/go-chi/chi/v5"
 	"github.com/google/uuid"
 )
 
 type TeamHandler struct {
-	teamsStore teamstore.TeamStore
-	userStore  userstore.UserStore
+	teamsStore      teamstore.TeamStore
+	userStore       userstore.UserStore
+	workflowStore   workflowstore.WorkflowStore
+	formStore       formstore.FormStore
+	calendarStore   calendarstore.CalendarStore
+	labelStore      labelstore.LabelStore
+	labelRuleStore  labelrulestore.LabelRuleStore
+	assignmentStore assignmentstore.AssignmentStore
+	recipeStore     automationstore.RecipeStore
+	snapshotStore   snapshotstore.SnapshotStore
+	invitationStore invitationstore.InvitationStore
+	directoryStore  directorystore.DirectoryStore
+	directorySync   *directory.Syncer
+	projectStore    projectstore.ProjectStore
+	customFields    customfieldstore.CustomFieldStore
+	rotationStore   rotationstore.RotationStore
+
+	// encryptionEnabled gates marking a team confidential.
+	encryptionEnabled bool
 }
 
-func NewTeamHandler(ts teamstore.TeamStore, us userstore.UserStore) *TeamHandler {
-	return &TeamHandler{ts, us}
+func NewTeamHandler(
+	ts teamstore.TeamStore,
+	us userstore.UserStore,
+	ws workflowstore.WorkflowStore,
+	fs formstore.FormStore,
+	cs calendarstore.CalendarStore,
+	ls labelstore.LabelStore,
+	lrs labelrulestore.LabelRuleStore,
+	as assignmentstore.AssignmentStore,
+	rs automationstore.RecipeStore,
+	ss snapshotstore.SnapshotStore,
+	is invitationstore.InvitationStore,
+	ds directorystore.DirectoryStore,
+	dsync *directory.Syncer,
+	ps projectstore.ProjectStore,
+	cfs customfieldstore.CustomFieldStore,
+	rts rotationstore.RotationStore,
+	encryptionEnabled bool,
+) *TeamHandler {
+	return &TeamHandler{ts, us, ws, fs, cs, ls, lrs, as, rs, ss, is, ds, dsync, ps, cfs, rts, encryptionEnabled}
 }
 func (h *TeamHandler) ListTeamsForUser(w http.ResponseWriter, r *http.Request) {
-	ctx, cancel := context.WithTimeout(r.Context(), 5*time.Second)
+	ctx, cancel := deadline.Budget(r.Context(), 5*time.Second)
 	defer cancel()
 
 	userID, ok := middleware.GetUserIDFromContext(ctx)
@@ -49,13 +100,10 @@ func (h *TeamHandler) ListTeamsForUser(w http.ResponseWriter, r *http.Request) {
 		"team_count", len(teams),
 	)
 
-	helper.RespondJSON(w, r, http.StatusOK, map[string]any{
-		"user_id": userID,
-		"teams":   teams,
-	})
+	helper.RespondJSON(w, r, http.StatusOK, types.TeamListResponse{UserID: userID, Teams: teams})
 }
 func (h *TeamHandler) ListMembers(w http.ResponseWriter, r *http.Request) {
-	ctx, cancel := context.WithTimeout(r.Context(), 5*time.Second)
+	ctx, cancel := deadline.Budget(r.Context(), 5*time.Second)
 	defer cancel()
 
 	userID, ok := middleware.GetUserIDFromContext(ctx)
@@ -95,14 +143,11 @@ func (h *TeamHandler) ListMembers(w http.ResponseWriter, r *http.Request) {
 		"member_count", len(members),
 	)
 
-	helper.RespondJSON(w, r, http.StatusOK, map[string]any{
-		"team_id": teamID,
-		"members": members,
-	})
+	helper.RespondJSON(w, r, http.StatusOK, types.MemberListResponse{TeamID: teamID, Members: members})
 }
 
 func (h *TeamHandler) CreateTeam(w http.ResponseWriter, r *http.Request) {
-	ctx, cancel := context.WithTimeout(r.Context(), 5*time.Second)
+	ctx, cancel := deadline.Budget(r.Context(), 5*time.Second)
 	defer cancel()
 	//check if user admin or task manager
 	userId, ok := middleware.GetUserIDFromContext(ctx)
@@ -129,9 +174,7 @@ func (h *TeamHandler) CreateTeam(w http.ResponseWriter, r *http.Request) {
 	dec := json.NewDecoder(r.Body)
 	dec.DisallowUnknownFields()
 	defer r.Body.Close()
-	var in struct {
-		Name string `json:"name"`
-	}
+	var in types.CreateTeamRequest
 
 	err = dec.Decode(&in)
 	if err != nil {
@@ -172,7 +215,7 @@ func (h *TeamHandler) CreateTeam(w http.ResponseWriter, r *http.Request) {
 }
 
 func (h *TeamHandler) HandleAddMember(w http.ResponseWriter, r *http.Request) {
-	ctx, cancel := context.WithTimeout(r.Context(), 5*time.Second)
+	ctx, cancel := deadline.Budget(r.Context(), 5*time.Second)
 	defer cancel()
 	//check if user admin or task manager
 	userId, ok := middleware.GetUserIDFromContext(ctx)
@@ -192,10 +235,7 @@ func (h *TeamHandler) HandleAddMember(w http.ResponseWriter, r *http.Request) {
 	dec := json.NewDecoder(r.Body)
 	dec.DisallowUnknownFields()
 	defer r.Body.Close()
-	var in struct {
-		UserID uuid.UUID          `json:"user_id"`
-		Role   teamstore.TeamRole `json:"role"`
-	}
+	var in types.AddMemberRequest
 
 	err := dec.Decode(&in)
 	if err != nil {
@@ -231,14 +271,20 @@ func (h *TeamHandler) HandleAddMember(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 	logger.Info(ctx, "new member added to team", "userId:", member.ID, "teamID", teamId)
-	helper.RespondJSON(w, r, 200, map[string]any{
-		"teamID": teamId,
-		"member": member,
+	helper.RespondJSON(w, r, 200, types.AddMemberResponse{
+		TeamID: teamId,
+		Member: types.User{
+			ID:        member.ID,
+			Email:     member.Email,
+			UserType:  member.UserType,
+			CreatedAt: member.CreatedAt,
+			UpdatedAt: member.UpdatedAt,
+		},
 	})
 
 }
 func (h *TeamHandler) RemoveMember(w http.ResponseWriter, r *http.Request) {
-	ctx, cancel := context.WithTimeout(r.Context(), 5*time.Second)
+	ctx, cancel := deadline.Budget(r.Context(), 5*time.Second)
 	defer cancel()
 
 	currentUserID, ok := middleware.GetUserIDFromContext(ctx)
@@ -285,12 +331,165 @@ func (h *TeamHandler) RemoveMember(w http.ResponseWriter, r *http.Request) {
 	}
 
 	logger.Info(ctx, "user removed from team", "user_id", userID, "team_id", teamID)
-	helper.RespondJSON(w, r, http.StatusOK, map[string]any{
-		"message": "member removed from team",
-		"team_id": teamID,
-		"user_id": userID,
+	helper.RespondJSON(w, r, http.StatusOK, types.RemoveMemberResponse{
+		Message: "member removed from team",
+		TeamID:  teamID,
+		UserID:  userID,
 	})
 }
+func (h *TeamHandler) GetSettings(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := deadline.Budget(r.Context(), 5*time.Second)
+	defer cancel()
+
+	userID, ok := middleware.GetUserIDFromContext(ctx)
+	if !ok {
+		logger.Error(ctx, "unauthorized get team settings attempt")
+		helper.RespondError(w, r, apperror.Unauthorized("authentication required"))
+		return
+	}
+
+	teamID, ok := parseID("team_id", r)
+	if !ok {
+		helper.RespondError(w, r, apperror.BadRequest("invalid team id"))
+		return
+	}
+
+	isMember, err := h.teamsStore.IsMember(ctx, teamID, userID)
+	if err != nil {
+		internalError(ctx, w, r, err)
+		return
+	}
+	if !isMember {
+		forbiddenError(ctx, w, r, "only team members can view team settings")
+		return
+	}
+
+	settings, err := h.teamsStore.GetSettings(ctx, teamID)
+	if err != nil {
+		internalError(ctx, w, r, err)
+		return
+	}
+	helper.RespondJSON(w, r, http.StatusOK, settings)
+}
+
+// UpdateSettings applies a partial update: omitted fields keep their current
+// value, explicit nulls switch the feature off.
+func (h *TeamHandler) UpdateSettings(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := deadline.Budget(r.Context(), 5*time.Second)
+	defer cancel()
+
+	userID, ok := middleware.GetUserIDFromContext(ctx)
+	if !ok {
+		logger.Error(ctx, "unauthorized update team settings attempt")
+		helper.RespondError(w, r, apperror.Unauthorized("authentication required"))
+		return
+	}
+
+	teamID, ok := parseID("team_id", r)
+	if !ok {
+		helper.RespondError(w, r, apperror.BadRequest("invalid team id"))
+		return
+	}
+
+	isOwnerOrAdmin, err := h.teamsStore.IsOwnerOrAdmin(ctx, teamID, userID)
+	if err != nil {
+		internalError(ctx, w, r, err)
+		return
+	}
+	if !isOwnerOrAdmin {
+		forbiddenError(ctx, w, r, "only team owner/admin can update team settings")
+		return
+	}
+
+	current, err := h.teamsStore.GetSettings(ctx, teamID)
+	if err != nil {
+		internalError(ctx, w, r, err)
+		return
+	}
+
+	in := teamSettingsInput{
+		StaleNudgeDays:   current.StaleNudgeDays,
+		RequiresApproval: &current.RequiresApproval,
+		WorkflowID:       current.WorkflowID,
+		AckNudgeHours:    current.AckNudgeHours,
+		Confidential:     &current.Confidential,
+		AutoAssign:       current.AutoAssign,
+	}
+
+	r.Body = http.MaxBytesReader(w, r.Body, 1<<20)
+	dec := json.NewDecoder(r.Body)
+	dec.DisallowUnknownFields()
+	defer r.Body.Close()
+
+	if err := dec.Decode(&in); err != nil {
+		badJsonCheck(ctx, w, r, "bad json")
+		return
+	}
+	if in.StaleNudgeDays != nil && (*in.StaleNudgeDays < 1 || *in.StaleNudgeDays > 365) {
+		helper.RespondError(w, r, apperror.BadRequest("stale_nudge_days must be between 1 and 365"))
+		return
+	}
+	if in.AckNudgeHours != nil && (*in.AckNudgeHours < 1 || *in.AckNudgeHours > 720) {
+		helper.RespondError(w, r, apperror.BadRequest("ack_nudge_hours must be between 1 and 720"))
+		return
+	}
+
+	if in.AutoAssign != nil && !in.AutoAssign.Valid() {
+		helper.RespondError(w, r, apperror.BadRequest("auto_assign must be round_robin, least_loaded or label_routing"))
+		return
+	}
+
+	if in.RequiresApproval == nil {
+		helper.RespondError(w, r, apperror.BadRequest("requires_approval cannot be null"))
+		return
+	}
+	if in.Confidential == nil {
+		helper.RespondError(w, r, apperror.BadRequest("confidential cannot be null"))
+		return
+	}
+	if *in.Confidential && !current.Confidential && !h.encryptionEnabled {
+		helper.RespondError(w, r, apperror.BadRequest("confidential teams require FIELD_ENCRYPTION_KEY to be configured"))
+		return
+	}
+
+	if in.WorkflowID != nil {
+		wf, err := h.workflowStore.Get(ctx, *in.WorkflowID)
+		if err != nil && !errors.Is(err, workflowstore.ErrWorkflowNotFound) {
+			internalError(ctx, w, r, err)
+			return
+		}
+		if err != nil || wf.TeamID != teamID {
+			helper.RespondError(w, r, apperror.BadRequest("workflow_id does not belong to this team"))
+			return
+		}
+	}
+
+	current.StaleNudgeDays = in.StaleNudgeDays
+	current.RequiresApproval = *in.RequiresApproval
+	current.WorkflowID = in.WorkflowID
+	current.AckNudgeHours = in.AckNudgeHours
+	current.Confidential = *in.Confidential
+	current.AutoAssign = in.AutoAssign
+
+	updated, err := h.teamsStore.UpdateSettings(ctx, *current, time.Now().UTC())
+	if err != nil {
+		internalError(ctx, w, r, err)
+		return
+	}
+
+	logger.Info(ctx, "team settings updated", "team_id", teamID, "user_id", userID)
+	helper.RespondJSON(w, r, http.StatusOK, updated)
+}
+
+type teamSettingsInput struct {
+	StaleNudgeDays   *int                          `json:"stale_nudge_days"`
+	RequiresApproval *bool                         `json:"requires_approval"`
+	WorkflowID       *uuid.UUID                    `json:"workflow_id"`
+	AckNudgeHours    *int                          `json:"ack_nudge_hours"`
+	Confidential     *bool                         `json:"confidential"`
+	AutoAssign       *teamstore.AutoAssignStrategy `json:"auto_assign"`
+}
+
 func parseID(key string, r *http.Request) (uuid.UUID, bool) {
 	idstr := chi.URLParam(r, key)
 	id, err := uuid.Parse(idstr)
@@ -0,0 +1,223 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/diagnosis/interactive-todo/internal/apperror"
+	"github.com/diagnosis/interactive-todo/internal/deadline"
+	"github.com/diagnosis/interactive-todo/internal/helper"
+	"github.com/diagnosis/interactive-todo/internal/logger"
+	middleware "github.com/diagnosis/interactive-todo/internal/middleware/auth"
+	workflowstore "github.com/diagnosis/interactive-todo/internal/store/workflows"
+	"github.com/diagnosis/interactive-todo/internal/workflow"
+	"github.com/google/uuid"
+)
+
+type workflowInput struct {
+	Name       string              `json:"name"`
+	Definition workflow.Definition `json:"definition"`
+}
+
+func (h *TeamHandler) ListWorkflows(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := deadline.Budget(r.Context(), 5*time.Second)
+	defer cancel()
+
+	userID, ok := middleware.GetUserIDFromContext(ctx)
+	if !ok {
+		logger.Error(ctx, "unauthorized list workflows attempt")
+		helper.RespondError(w, r, apperror.Unauthorized("authentication required"))
+		return
+	}
+
+	teamID, ok := parseID("team_id", r)
+	if !ok {
+		helper.RespondError(w, r, apperror.BadRequest("invalid team id"))
+		return
+	}
+
+	isMember, err := h.teamsStore.IsMember(ctx, teamID, userID)
+	if err != nil {
+		internalError(ctx, w, r, err)
+		return
+	}
+	if !isMember {
+		forbiddenError(ctx, w, r, "only team members can view workflows")
+		return
+	}
+
+	workflows, err := h.workflowStore.ListForTeam(ctx, teamID)
+	if err != nil {
+		internalError(ctx, w, r, err)
+		return
+	}
+
+	helper.RespondJSON(w, r, http.StatusOK, map[string]any{
+		"team_id":   teamID,
+		"workflows": workflows,
+	})
+}
+
+func (h *TeamHandler) CreateWorkflow(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := deadline.Budget(r.Context(), 5*time.Second)
+	defer cancel()
+
+	userID, teamID, ok := h.requireTeamAdmin(ctx, w, r, "only team owner/admin can manage workflows")
+	if !ok {
+		return
+	}
+
+	in, ok := decodeWorkflowInput(ctx, w, r)
+	if !ok {
+		return
+	}
+
+	wf, err := h.workflowStore.Create(ctx, teamID, in.Name, in.Definition, userID, time.Now().UTC())
+	if err != nil {
+		respondWorkflowError(ctx, w, r, err)
+		return
+	}
+
+	logger.Info(ctx, "workflow created", "team_id", teamID, "workflow_id", wf.ID, "user_id", userID)
+	helper.RespondJSON(w, r, http.StatusCreated, wf)
+}
+
+func (h *TeamHandler) UpdateWorkflow(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := deadline.Budget(r.Context(), 5*time.Second)
+	defer cancel()
+
+	userID, teamID, ok := h.requireTeamAdmin(ctx, w, r, "only team owner/admin can manage workflows")
+	if !ok {
+		return
+	}
+
+	workflowID, ok := h.teamWorkflowID(ctx, w, r, teamID)
+	if !ok {
+		return
+	}
+
+	in, ok := decodeWorkflowInput(ctx, w, r)
+	if !ok {
+		return
+	}
+
+	wf, err := h.workflowStore.Update(ctx, workflowID, in.Name, in.Definition, time.Now().UTC())
+	if err != nil {
+		respondWorkflowError(ctx, w, r, err)
+		return
+	}
+
+	logger.Info(ctx, "workflow updated", "team_id", teamID, "workflow_id", wf.ID, "user_id", userID)
+	helper.RespondJSON(w, r, http.StatusOK, wf)
+}
+
+func (h *TeamHandler) DeleteWorkflow(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := deadline.Budget(r.Context(), 5*time.Second)
+	defer cancel()
+
+	userID, teamID, ok := h.requireTeamAdmin(ctx, w, r, "only team owner/admin can manage workflows")
+	if !ok {
+		return
+	}
+
+	workflowID, ok := h.teamWorkflowID(ctx, w, r, teamID)
+	if !ok {
+		return
+	}
+
+	if err := h.workflowStore.Delete(ctx, workflowID); err != nil {
+		respondWorkflowError(ctx, w, r, err)
+		return
+	}
+
+	logger.Info(ctx, "workflow deleted", "team_id", teamID, "workflow_id", workflowID, "user_id", userID)
+	helper.RespondMessage(w, r, http.StatusOK, "workflow deleted")
+}
+
+// requireTeamAdmin resolves the caller and {team_id} and checks the caller is
+// team owner/admin, responding with forbiddenMsg otherwise.
+func (h *TeamHandler) requireTeamAdmin(ctx context.Context, w http.ResponseWriter, r *http.Request, forbiddenMsg string) (uuid.UUID, uuid.UUID, bool) {
+	userID, ok := middleware.GetUserIDFromContext(ctx)
+	if !ok {
+		logger.Error(ctx, "unauthorized team admin action attempt")
+		helper.RespondError(w, r, apperror.Unauthorized("authentication required"))
+		return uuid.Nil, uuid.Nil, false
+	}
+
+	teamID, ok := parseID("team_id", r)
+	if !ok {
+		helper.RespondError(w, r, apperror.BadRequest("invalid team id"))
+		return uuid.Nil, uuid.Nil, false
+	}
+
+	isOwnerOrAdmin, err := h.teamsStore.IsOwnerOrAdmin(ctx, teamID, userID)
+	if err != nil {
+		internalError(ctx, w, r, err)
+		return uuid.Nil, uuid.Nil, false
+	}
+	if !isOwnerOrAdmin {
+		forbiddenError(ctx, w, r, forbiddenMsg)
+		return uuid.Nil, uuid.Nil, false
+	}
+	return userID, teamID, true
+}
+
+// teamWorkflowID parses {workflow_id} and hides workflows of other teams
+// behind a 404.
+func (h *TeamHandler) teamWorkflowID(ctx context.Context, w http.ResponseWriter, r *http.Request, teamID uuid.UUID) (uuid.UUID, bool) {
+	workflowID, ok := parseID("workflow_id", r)
+	if !ok {
+		helper.RespondError(w, r, apperror.BadRequest("invalid workflow id"))
+		return uuid.Nil, false
+	}
+
+	wf, err := h.workflowStore.Get(ctx, workflowID)
+	if err != nil && !errors.Is(err, workflowstore.ErrWorkflowNotFound) {
+		internalError(ctx, w, r, err)
+		return uuid.Nil, false
+	}
+	if err != nil || wf.TeamID != teamID {
+		helper.RespondError(w, r, apperror.NotFound("workflow not found"))
+		return uuid.Nil, false
+	}
+	return workflowID, true
+}
+
+func decodeWorkflowInput(ctx context.Context, w http.ResponseWriter, r *http.Request) (*workflowInput, bool) {
+	r.Body = http.MaxBytesReader(w, r.Body, 1<<20)
+	dec := json.NewDecoder(r.Body)
+	dec.DisallowUnknownFields()
+	defer r.Body.Close()
+
+	var in workflowInput
+	if err := dec.Decode(&in); err != nil {
+		badJsonCheck(ctx, w, r, "bad json")
+		return nil, false
+	}
+
+	in.Name = strings.TrimSpace(in.Name)
+	if in.Name == "" || len(in.Name) > 100 {
+		helper.RespondError(w, r, apperror.BadRequest("name is required (max 100 chars)"))
+		return nil, false
+	}
+	return &in, true
+}
+
+func respondWorkflowError(ctx context.Context, w http.ResponseWriter, r *http.Request, err error) {
+	switch {
+	case errors.Is(err, workflow.ErrInvalidDefinition):
+		helper.RespondError(w, r, apperror.BadRequest(err.Error()))
+	case errors.Is(err, workflowstore.ErrWorkflowNameTaken):
+		helper.RespondError(w, r, apperror.Conflict("workflow name already used in team"))
+	case errors.Is(err, workflowstore.ErrWorkflowInUse):
+		helper.RespondError(w, r, apperror.Conflict("workflow is active; switch the team off it first"))
+	case errors.Is(err, workflowstore.ErrWorkflowNotFound):
+		helper.RespondError(w, r, apperror.NotFound("workflow not found"))
+	default:
+		internalError(ctx, w, r, err)
+	}
+}
@@ -6,21 +6,10 @@ import (
 	"strings"
 )
 
+// GetClientIP returns the client address of r. Forwarding headers are not
+// read here: the RealIP middleware has already put the client address in
+// RemoteAddr when the request came through a trusted proxy.
 func GetClientIP(r *http.Request) string {
-	// Prefer X-Forwarded-For (first IP)
-	if xff := strings.TrimSpace(r.Header.Get("X-Forwarded-For")); xff != "" {
-		parts := strings.Split(xff, ",")
-		if len(parts) > 0 {
-			return strings.TrimSpace(parts[0])
-		}
-	}
-
-	// Fallback to X-Real-IP
-	if xRealIP := strings.TrimSpace(r.Header.Get("X-Real-IP")); xRealIP != "" {
-		return xRealIP
-	}
-
-	// Finally, use RemoteAddr (host:port)
 	remote := strings.TrimSpace(r.RemoteAddr)
 	if remote == "" {
 		return ""
@@ -0,0 +1,36 @@
+package helper
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sync/atomic"
+
+	"github.com/diagnosis/interactive-todo/api/types"
+)
+
+// StatusClientClosedRequest is written (for access logs only) when the
+// client hung up before the response was ready.
+const StatusClientClosedRequest = 499
+
+type RequestOutcomes = types.RequestOutcomes
+
+var outcomes struct {
+	canceled     atomic.Int64
+	timedOut     atomic.Int64
+	serverErrors atomic.Int64
+}
+
+func Outcomes() RequestOutcomes {
+	return RequestOutcomes{
+		ClientCanceled: outcomes.canceled.Load(),
+		TimedOut:       outcomes.timedOut.Load(),
+		ServerErrors:   outcomes.serverErrors.Load(),
+	}
+}
+
+// ClientGone reports whether the client disconnected: the request context
+// was canceled rather than timed out.
+func ClientGone(r *http.Request) bool {
+	return errors.Is(r.Context().Err(), context.Canceled)
+}
@@ -6,6 +6,7 @@ import (
 	"net/http"
 	"time"
 
+	"github.com/diagnosis/interactive-todo/api/types"
 	"github.com/diagnosis/interactive-todo/internal/apperror"
 )
 
@@ -13,21 +14,9 @@ type ctxKey string
 
 const correlationIDKey ctxKey = "correlation_id"
 
-type ErrorResponse struct {
-	Error struct {
-		Code          string    `json:"code"`
-		Message       string    `json:"message"`
-		CorrelationID string    `json:",omitempty"`
-		Timestamp     time.Time `json:"timestamp"`
-	} `json:"error"`
-}
+type ErrorResponse = types.ErrorResponse
 
-type SuccessResponse struct {
-	Data          any       `json:"data,omitempty"`
-	Message       string    `json:"message,omitempty"`
-	CorrelationID string    `json:"correlation_id,omitempty"`
-	Timestamp     time.Time `json:"timestamp"`
-}
+type SuccessResponse = types.Envelope[any]
 
 func WithCorrelationID(ctx context.Context, correlationID string) context.Context {
 	return context.WithValue(ctx, correlationIDKey, correlationID)
@@ -41,9 +30,21 @@ func GetCorrelationID(ctx context.Context) string {
 
 func RespondError(w http.ResponseWriter, r *http.Request, err error) {
 	ctx := r.Context()
+	// nobody is listening; a store error caused by the hang-up is not ours
+	if ClientGone(r) {
+		outcomes.canceled.Add(1)
+		w.WriteHeader(StatusClientClosedRequest)
+		return
+	}
 	correlationID := GetCorrelationID(ctx)
 	//apperr instance
 	ae := apperror.AsAppError(err)
+	switch {
+	case ae.Code == apperror.CodeTimeout:
+		outcomes.timedOut.Add(1)
+	case ae.HTTPStatus >= 500:
+		outcomes.serverErrors.Add(1)
+	}
 
 	errorResponse := ErrorResponse{}
 	errorResponse.Error.Code = string(ae.Code)
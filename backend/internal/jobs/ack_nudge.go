@@ -0,0 +1,75 @@
+package jobs
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/diagnosis/interactive-todo/internal/logger"
+	"github.com/diagnosis/interactive-todo/internal/notify"
+	calendarstore "github.com/diagnosis/interactive-todo/internal/store/calendars"
+	taskstore "github.com/diagnosis/interactive-todo/internal/store/tasks"
+	teamstore "github.com/diagnosis/interactive-todo/internal/store/teams"
+)
+
+// AckNudgeJob reminds assignees who have not opened a newly assigned task
+// within their team's ack_nudge_hours. Hours on the team's days off do not
+// count, and nobody is nudged on one. Each assignment is nudged once.
+type AckNudgeJob struct {
+	taskStore     taskstore.TaskStore
+	teamStore     teamstore.TeamStore
+	calendarStore calendarstore.CalendarStore
+	notifier      notify.Notifier
+}
+
+func NewAckNudgeJob(ts taskstore.TaskStore, tms teamstore.TeamStore, cs calendarstore.CalendarStore, n notify.Notifier) *AckNudgeJob {
+	return &AckNudgeJob{taskStore: ts, teamStore: tms, calendarStore: cs, notifier: n}
+}
+
+func (j *AckNudgeJob) Name() string { return "ack_nudge" }
+
+func (j *AckNudgeJob) Run(ctx context.Context) error {
+	now := time.Now().UTC()
+
+	tasks, err := j.taskStore.FindUnacknowledgedForNudge(ctx, now)
+	if err != nil {
+		return err
+	}
+
+	rules := newTeamRules(j.calendarStore, j.teamStore)
+	sent := 0
+	for _, t := range tasks {
+		cal, err := rules.calendar(ctx, t.TeamID)
+		if err != nil {
+			return fmt.Errorf("ack nudge: team_id=%s: %w", t.TeamID, err)
+		}
+		settings, err := rules.teamSettings(ctx, t.TeamID)
+		if err != nil {
+			return fmt.Errorf("ack nudge: team_id=%s: %w", t.TeamID, err)
+		}
+		if !cal.IsWorkingDay(now) || settings.AckNudgeHours == nil ||
+			cal.WorkingTime(t.AssignedAt, now) < time.Duration(*settings.AckNudgeHours)*time.Hour {
+			continue
+		}
+
+		if err := j.notifier.Notify(ctx, notify.Notification{
+			UserID:  t.AssigneeID,
+			Kind:    notify.KindUnacknowledged,
+			Subject: fmt.Sprintf("You were assigned %q on %s and have not opened it yet", t.Title, t.AssignedAt.Format("2006-01-02")),
+			TaskID:  &t.ID,
+			TeamID:  &t.TeamID,
+		}); err != nil {
+			logger.Error(ctx, "ack nudge: notify failed", "task_id", t.ID, "err", err)
+			continue
+		}
+		if err := j.taskStore.MarkAckNudged(ctx, t.ID, now); err != nil {
+			return fmt.Errorf("ack nudge: mark task_id=%s: %w", t.ID, err)
+		}
+		sent++
+	}
+
+	if sent > 0 {
+		logger.Info(ctx, "ack nudge: sent", "count", sent)
+	}
+	return nil
+}
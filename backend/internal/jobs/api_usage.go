@@ -0,0 +1,61 @@
+package jobs
+
+import (
+	"context"
+	"time"
+
+	"github.com/diagnosis/interactive-todo/internal/logger"
+	usagemw "github.com/diagnosis/interactive-todo/internal/middleware/usage"
+	usagestore "github.com/diagnosis/interactive-todo/internal/store/usage"
+)
+
+// APIUsageRetention is how long daily request counts are kept. Last
+// activity is read from the same rows, so users idle for longer show as
+// never seen.
+const APIUsageRetention = 400 * 24 * time.Hour
+
+// FlushAPIUsageJob writes the request counts held in memory to the database.
+type FlushAPIUsageJob struct {
+	tracker *usagemw.Tracker
+}
+
+func NewFlushAPIUsageJob(t *usagemw.Tracker) *FlushAPIUsageJob {
+	return &FlushAPIUsageJob{tracker: t}
+}
+
+func (j *FlushAPIUsageJob) Name() string { return "flush_api_usage" }
+
+func (j *FlushAPIUsageJob) Run(ctx context.Context) error {
+	n, err := j.tracker.Flush(ctx)
+	if err != nil {
+		return err
+	}
+	if n > 0 {
+		logger.Debug(ctx, "flush api usage: written", "rows", n)
+	}
+	return nil
+}
+
+// APIUsageRetentionJob purges daily request counts older than the
+// retention window.
+type APIUsageRetentionJob struct {
+	usageStore usagestore.UsageStore
+	retention  time.Duration
+}
+
+func NewAPIUsageRetentionJob(us usagestore.UsageStore, retention time.Duration) *APIUsageRetentionJob {
+	return &APIUsageRetentionJob{usageStore: us, retention: retention}
+}
+
+func (j *APIUsageRetentionJob) Name() string { return "api_usage_retention" }
+
+func (j *APIUsageRetentionJob) Run(ctx context.Context) error {
+	n, err := j.usageStore.PurgeBefore(ctx, time.Now().UTC().Add(-j.retention))
+	if err != nil {
+		return err
+	}
+	if n > 0 {
+		logger.Info(ctx, "api usage retention: purged", "count", n)
+	}
+	return nil
+}
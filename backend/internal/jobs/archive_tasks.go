@@ -0,0 +1,46 @@
+package jobs
+
+import (
+	"context"
+	"time"
+
+	"github.com/diagnosis/interactive-todo/internal/logger"
+	taskstore "github.com/diagnosis/interactive-todo/internal/store/tasks"
+)
+
+const archiveBatchSize = 500
+
+// ArchiveTasksJob moves tasks finished more than afterMonths ago into
+// tasks_archive so the hot table only holds work people still look at.
+type ArchiveTasksJob struct {
+	taskStore   taskstore.TaskStore
+	afterMonths int
+}
+
+func NewArchiveTasksJob(ts taskstore.TaskStore, afterMonths int) *ArchiveTasksJob {
+	return &ArchiveTasksJob{taskStore: ts, afterMonths: afterMonths}
+}
+
+func (j *ArchiveTasksJob) Name() string { return "archive_tasks" }
+
+// Run works in batches so each statement holds its locks briefly.
+func (j *ArchiveTasksJob) Run(ctx context.Context) error {
+	now := time.Now().UTC()
+	cutoff := now.AddDate(0, -j.afterMonths, 0)
+
+	total := 0
+	for {
+		n, err := j.taskStore.ArchiveFinished(ctx, cutoff, archiveBatchSize, now)
+		if err != nil {
+			return err
+		}
+		total += n
+		if n < archiveBatchSize || ctx.Err() != nil {
+			break
+		}
+	}
+	if total > 0 {
+		logger.Info(ctx, "archive tasks: moved", "count", total)
+	}
+	return nil
+}
@@ -0,0 +1,56 @@
+package jobs
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/diagnosis/interactive-todo/internal/events"
+	"github.com/diagnosis/interactive-todo/internal/logger"
+	automationstore "github.com/diagnosis/interactive-todo/internal/store/automations"
+)
+
+// AutomationDueSoonJob fires due_soon recipes. Each recipe fires once per
+// task and due date, so moving the due date arms it again.
+type AutomationDueSoonJob struct {
+	recipeStore automationstore.RecipeStore
+	events      events.Publisher
+}
+
+func NewAutomationDueSoonJob(rs automationstore.RecipeStore, ev events.Publisher) *AutomationDueSoonJob {
+	return &AutomationDueSoonJob{recipeStore: rs, events: ev}
+}
+
+func (j *AutomationDueSoonJob) Name() string { return "automation_due_soon" }
+
+func (j *AutomationDueSoonJob) Run(ctx context.Context) error {
+	now := time.Now().UTC()
+
+	recipes, err := j.recipeStore.ListDueSoon(ctx)
+	if err != nil {
+		return err
+	}
+
+	fired := 0
+	for _, rec := range recipes {
+		taskIDs, err := j.recipeStore.ClaimDueSoon(ctx, rec, now)
+		if err != nil {
+			return fmt.Errorf("automation due soon: recipe_id=%s: %w", rec.ID, err)
+		}
+		for _, taskID := range taskIDs {
+			j.events.Publish(ctx, events.Event{
+				Type:     events.TaskDueSoon,
+				TeamID:   rec.TeamID,
+				TaskID:   taskID,
+				RecipeID: &rec.ID,
+				At:       now,
+			})
+		}
+		fired += len(taskIDs)
+	}
+
+	if fired > 0 {
+		logger.Info(ctx, "automation due soon: fired", "count", fired)
+	}
+	return nil
+}
@@ -0,0 +1,83 @@
+package jobs
+
+import (
+	"context"
+	"time"
+
+	"github.com/diagnosis/interactive-todo/internal/backup"
+	"github.com/diagnosis/interactive-todo/internal/logger"
+	backupstore "github.com/diagnosis/interactive-todo/internal/store/backups"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// backupStaleAfter is how long an export may run before it is taken for
+// one whose process died.
+const backupStaleAfter = 6 * time.Hour
+
+// BackupExportsJob runs the exports admins request, one at a time, and
+// records how each went. Only registered when backups are configured.
+type BackupExportsJob struct {
+	backupStore backupstore.BackupStore
+	pool        *pgxpool.Pool
+	cfg         *backup.Config
+}
+
+func NewBackupExportsJob(bs backupstore.BackupStore, pool *pgxpool.Pool, cfg *backup.Config) *BackupExportsJob {
+	return &BackupExportsJob{backupStore: bs, pool: pool, cfg: cfg}
+}
+
+func (j *BackupExportsJob) Name() string { return "backup_exports" }
+
+func (j *BackupExportsJob) Run(ctx context.Context) error {
+	now := time.Now().UTC()
+	n, err := j.backupStore.FailStale(ctx, now.Add(-backupStaleAfter), now)
+	if err != nil {
+		return err
+	}
+	if n > 0 {
+		logger.Warn(ctx, "backup exports: marked interrupted", "count", n)
+	}
+
+	for ctx.Err() == nil {
+		exp, err := j.backupStore.Claim(ctx, time.Now().UTC())
+		if err != nil {
+			return err
+		}
+		if exp == nil {
+			return nil
+		}
+		j.run(ctx, exp)
+	}
+	return nil
+}
+
+func (j *BackupExportsJob) run(ctx context.Context, exp *backupstore.Export) {
+	start := time.Now().UTC()
+	up, err := j.cfg.Upload(ctx, j.pool, exp.ID, exp.TeamID, start)
+	// record the outcome even when shutdown cancelled the export
+	ctx = context.WithoutCancel(ctx)
+	if err != nil {
+		logger.Error(ctx, "backup export failed", "export_id", exp.ID, "team_id", exp.TeamID, "err", err)
+		reason := err.Error()
+		if len(reason) > 1000 {
+			reason = reason[:1000]
+		}
+		if err := j.backupStore.Fail(ctx, exp.ID, reason, time.Now().UTC()); err != nil {
+			logger.Error(ctx, "backup export: record failure", "export_id", exp.ID, "err", err)
+		}
+		return
+	}
+
+	if err := j.backupStore.Finish(ctx, exp.ID, up.ObjectKey, up.SizeBytes, up.Summary.Rows, time.Now().UTC()); err != nil {
+		logger.Error(ctx, "backup export: record success", "export_id", exp.ID, "err", err)
+		return
+	}
+	logger.Info(ctx, "backup export finished",
+		"export_id", exp.ID,
+		"team_id", exp.TeamID,
+		"object_key", up.ObjectKey,
+		"bytes", up.SizeBytes,
+		"rows", up.Summary.Rows,
+		"took", time.Since(start),
+	)
+}
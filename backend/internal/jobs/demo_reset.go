@@ -0,0 +1,33 @@
+package jobs
+
+import (
+	"context"
+	"time"
+
+	"github.com/diagnosis/interactive-todo/internal/demo"
+	"github.com/diagnosis/interactive-todo/internal/logger"
+)
+
+// DemoResetJob wipes and reseeds the demo sandbox once its data is
+// demo.ResetInterval old. It runs more often than that so the reset lands
+// close to the advertised time.
+type DemoResetJob struct {
+	sandbox *demo.Sandbox
+}
+
+func NewDemoResetJob(sb *demo.Sandbox) *DemoResetJob {
+	return &DemoResetJob{sandbox: sb}
+}
+
+func (j *DemoResetJob) Name() string { return "demo_reset" }
+
+func (j *DemoResetJob) Run(ctx context.Context) error {
+	before := j.sandbox.NextReset()
+	if err := j.sandbox.Reset(ctx, time.Now()); err != nil {
+		return err
+	}
+	if next := j.sandbox.NextReset(); !next.Equal(before) {
+		logger.Info(ctx, "demo reset: sandbox reseeded", "next_reset", next)
+	}
+	return nil
+}
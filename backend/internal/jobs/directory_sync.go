@@ -0,0 +1,45 @@
+package jobs
+
+import (
+	"context"
+
+	"github.com/diagnosis/interactive-todo/internal/directory"
+	"github.com/diagnosis/interactive-todo/internal/logger"
+	directorystore "github.com/diagnosis/interactive-todo/internal/store/directory"
+)
+
+// DirectorySyncJob syncs every team linked to a directory group. A team
+// that fails is logged and retried on the next run; the others go on.
+// Only registered when a directory provider is configured.
+type DirectorySyncJob struct {
+	store  directorystore.DirectoryStore
+	syncer *directory.Syncer
+}
+
+func NewDirectorySyncJob(s directorystore.DirectoryStore, syncer *directory.Syncer) *DirectorySyncJob {
+	return &DirectorySyncJob{store: s, syncer: syncer}
+}
+
+func (j *DirectorySyncJob) Name() string { return "directory_sync" }
+
+func (j *DirectorySyncJob) Run(ctx context.Context) error {
+	links, err := j.store.List(ctx)
+	if err != nil {
+		return err
+	}
+	for _, link := range links {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		res, err := j.syncer.Sync(ctx, link)
+		if err != nil {
+			logger.Error(ctx, "directory sync failed", "team_id", link.TeamID, "provider", link.Provider, "group_id", link.GroupID, "err", err)
+			continue
+		}
+		if res.Added > 0 || res.Removed > 0 {
+			logger.Info(ctx, "directory sync changed members", "team_id", link.TeamID,
+				"added", res.Added, "removed", res.Removed, "unmatched", res.Unmatched)
+		}
+	}
+	return nil
+}
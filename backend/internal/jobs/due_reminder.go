@@ -0,0 +1,101 @@
+package jobs
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/diagnosis/interactive-todo/api/types"
+	"github.com/diagnosis/interactive-todo/internal/events"
+	"github.com/diagnosis/interactive-todo/internal/localtime"
+	"github.com/diagnosis/interactive-todo/internal/logger"
+	"github.com/diagnosis/interactive-todo/internal/notify"
+	reminderstore "github.com/diagnosis/interactive-todo/internal/store/reminders"
+	userstore "github.com/diagnosis/interactive-todo/internal/store/users"
+	"github.com/google/uuid"
+)
+
+const dueReminderBatchSize = 500
+
+// DueReminderJob sends each task reminder whose time has come to the task's
+// assignee, on the reminder's channel, and marks it sent. Reminders are
+// independent: one failing to send is retried on the next run without
+// holding back the others. Email reminders write the due date in the
+// assignee's timezone.
+type DueReminderJob struct {
+	reminderStore reminderstore.ReminderStore
+	userStore     userstore.UserStore
+	notifier      notify.Notifier
+	events        events.Publisher
+}
+
+func NewDueReminderJob(rs reminderstore.ReminderStore, us userstore.UserStore, n notify.Notifier, ev events.Publisher) *DueReminderJob {
+	return &DueReminderJob{reminderStore: rs, userStore: us, notifier: n, events: ev}
+}
+
+func (j *DueReminderJob) Name() string { return "due_reminder" }
+
+func (j *DueReminderJob) Run(ctx context.Context) error {
+	now := time.Now().UTC()
+
+	due, err := j.reminderStore.ListDue(ctx, now, dueReminderBatchSize)
+	if err != nil {
+		return err
+	}
+
+	zones := map[uuid.UUID]*time.Location{}
+	sent := 0
+	for _, d := range due {
+		switch d.Channel {
+		case types.ReminderInApp:
+			j.events.Publish(ctx, events.Event{
+				Type:   events.TaskReminder,
+				TeamID: d.TeamID,
+				TaskID: d.TaskID,
+				UserID: &d.AssigneeID,
+				DueAt:  &d.DueAt,
+				At:     now,
+			})
+		default:
+			loc, ok := zones[d.AssigneeID]
+			if !ok {
+				loc = j.location(ctx, d.AssigneeID)
+				zones[d.AssigneeID] = loc
+			}
+			if err := j.notifier.Notify(ctx, notify.Notification{
+				UserID:  d.AssigneeID,
+				Kind:    notify.KindDueReminder,
+				Subject: fmt.Sprintf("Task %q is due %s", d.Title, localtime.Format(d.DueAt, loc)),
+				TaskID:  &d.TaskID,
+				TeamID:  &d.TeamID,
+			}); err != nil {
+				logger.Error(ctx, "due reminder: notify failed", "reminder_id", d.ID, "task_id", d.TaskID, "err", err)
+				continue
+			}
+		}
+		if _, err := j.reminderStore.MarkSent(ctx, d.ID, now); err != nil {
+			return fmt.Errorf("due reminder: mark reminder_id=%s: %w", d.ID, err)
+		}
+		sent++
+	}
+
+	if sent > 0 {
+		logger.Info(ctx, "due reminder: sent", "count", sent)
+	}
+	return nil
+}
+
+// location falls back to UTC; a reminder in the wrong timezone beats none.
+func (j *DueReminderJob) location(ctx context.Context, userID uuid.UUID) *time.Location {
+	name, err := j.userStore.Timezone(ctx, userID)
+	if err != nil {
+		logger.Warn(ctx, "due reminder: timezone lookup failed", "user_id", userID, "err", err)
+		return time.UTC
+	}
+	loc, err := localtime.Load(name)
+	if err != nil {
+		logger.Warn(ctx, "due reminder: unknown timezone", "user_id", userID, "timezone", name)
+		return time.UTC
+	}
+	return loc
+}
@@ -0,0 +1,72 @@
+package jobs
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/diagnosis/interactive-todo/internal/embedding"
+	"github.com/diagnosis/interactive-todo/internal/logger"
+	taskstore "github.com/diagnosis/interactive-todo/internal/store/tasks"
+)
+
+const embedBatchSize = 64
+
+// EmbedTasksJob keeps task_embeddings current: new tasks, edited ones and,
+// after a model change, all of them, a batch per run. Only registered when
+// an embedding provider is configured.
+type EmbedTasksJob struct {
+	taskStore taskstore.TaskStore
+	provider  embedding.Provider
+}
+
+func NewEmbedTasksJob(ts taskstore.TaskStore, p embedding.Provider) *EmbedTasksJob {
+	return &EmbedTasksJob{taskStore: ts, provider: p}
+}
+
+func (j *EmbedTasksJob) Name() string { return "embed_tasks" }
+
+func (j *EmbedTasksJob) Run(ctx context.Context) error {
+	model := j.provider.Model()
+	backlog, err := j.taskStore.ListEmbeddingBacklog(ctx, model, embedBatchSize)
+	if err != nil {
+		return err
+	}
+	if len(backlog) == 0 {
+		return nil
+	}
+
+	now := time.Now().UTC()
+	var texts []string
+	var pending []taskstore.EmbeddingSource
+	var hashes []string
+	for _, src := range backlog {
+		hash := embedding.ContentHash(src.Title, src.Description)
+		// touched but not edited, e.g. a status change
+		if hash == src.StoredHash && model == src.StoredModel {
+			if err := j.taskStore.SaveEmbedding(ctx, src.TaskID, model, hash, nil, now); err != nil {
+				return err
+			}
+			continue
+		}
+		texts = append(texts, embedding.Text(src.Title, src.Description))
+		pending = append(pending, src)
+		hashes = append(hashes, hash)
+	}
+	if len(pending) == 0 {
+		return nil
+	}
+
+	vecs, err := j.provider.Embed(ctx, texts)
+	if err != nil {
+		return fmt.Errorf("embed tasks: %w", err)
+	}
+	for i, src := range pending {
+		if err := j.taskStore.SaveEmbedding(ctx, src.TaskID, model, hashes[i], vecs[i], now); err != nil {
+			return err
+		}
+	}
+
+	logger.Info(ctx, "embed tasks: embedded", "count", len(pending))
+	return nil
+}
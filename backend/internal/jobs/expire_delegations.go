@@ -0,0 +1,63 @@
+package jobs
+
+import (
+	"context"
+	"time"
+
+	"github.com/diagnosis/interactive-todo/internal/logger"
+	auditstore "github.com/diagnosis/interactive-todo/internal/store/audit"
+	delegationstore "github.com/diagnosis/interactive-todo/internal/store/delegations"
+)
+
+const expireDelegationsBatchSize = 500
+
+// ExpireDelegationsJob deletes delegations whose date range has ended and
+// records each in the audit log. An ended delegation grants nothing even
+// before the job runs.
+type ExpireDelegationsJob struct {
+	delegationStore delegationstore.DelegationStore
+	auditStore      auditstore.AuditStore
+}
+
+func NewExpireDelegationsJob(ds delegationstore.DelegationStore, as auditstore.AuditStore) *ExpireDelegationsJob {
+	return &ExpireDelegationsJob{delegationStore: ds, auditStore: as}
+}
+
+func (j *ExpireDelegationsJob) Name() string { return "expire_delegations" }
+
+func (j *ExpireDelegationsJob) Run(ctx context.Context) error {
+	now := time.Now().UTC()
+
+	total := 0
+	for {
+		expired, err := j.delegationStore.DeleteExpired(ctx, now, expireDelegationsBatchSize)
+		if err != nil {
+			return err
+		}
+		for _, d := range expired {
+			if err := j.auditStore.Record(ctx, auditstore.Entry{
+				Action:     auditstore.ActionDelegationExpired,
+				TargetType: auditstore.TargetDelegation,
+				TargetID:   &d.ID,
+				TeamID:     &d.TeamID,
+				Metadata: map[string]any{
+					"delegator_id": d.DelegatorID,
+					"delegate_id":  d.DelegateID,
+					"starts_at":    d.StartsAt,
+					"ends_at":      d.EndsAt,
+				},
+				CreatedAt: now,
+			}); err != nil {
+				logger.Error(ctx, "expire delegations: audit failed", "delegation_id", d.ID, "err", err)
+			}
+		}
+		total += len(expired)
+		if len(expired) < expireDelegationsBatchSize || ctx.Err() != nil {
+			break
+		}
+	}
+	if total > 0 {
+		logger.Info(ctx, "expire delegations: deleted", "count", total)
+	}
+	return nil
+}
@@ -0,0 +1,32 @@
+package jobs
+
+import (
+	"context"
+	"time"
+
+	"github.com/diagnosis/interactive-todo/internal/logger"
+	editlockstore "github.com/diagnosis/interactive-todo/internal/store/editlocks"
+)
+
+// ExpireEditLocksJob deletes description locks that lapsed. They already
+// count as free; this only keeps the table small.
+type ExpireEditLocksJob struct {
+	store editlockstore.EditLockStore
+}
+
+func NewExpireEditLocksJob(s editlockstore.EditLockStore) *ExpireEditLocksJob {
+	return &ExpireEditLocksJob{store: s}
+}
+
+func (j *ExpireEditLocksJob) Name() string { return "expire_edit_locks" }
+
+func (j *ExpireEditLocksJob) Run(ctx context.Context) error {
+	n, err := j.store.DeleteExpired(ctx, time.Now().UTC())
+	if err != nil {
+		return err
+	}
+	if n > 0 {
+		logger.Info(ctx, "expire edit locks: deleted", "count", n)
+	}
+	return nil
+}
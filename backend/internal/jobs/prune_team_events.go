@@ -0,0 +1,31 @@
+package jobs
+
+import (
+	"context"
+
+	"github.com/diagnosis/interactive-todo/internal/logger"
+	teameventstore "github.com/diagnosis/interactive-todo/internal/store/teamevents"
+)
+
+// PruneTeamEventsJob keeps the realtime replay history of each team to its
+// newest teameventstore.KeepPerTeam events.
+type PruneTeamEventsJob struct {
+	store teameventstore.TeamEventStore
+}
+
+func NewPruneTeamEventsJob(s teameventstore.TeamEventStore) *PruneTeamEventsJob {
+	return &PruneTeamEventsJob{store: s}
+}
+
+func (j *PruneTeamEventsJob) Name() string { return "prune_team_events" }
+
+func (j *PruneTeamEventsJob) Run(ctx context.Context) error {
+	n, err := j.store.Prune(ctx, teameventstore.KeepPerTeam)
+	if err != nil {
+		return err
+	}
+	if n > 0 {
+		logger.Info(ctx, "prune team events: pruned", "count", n)
+	}
+	return nil
+}
@@ -0,0 +1,49 @@
+package jobs
+
+import (
+	"context"
+
+	"github.com/diagnosis/interactive-todo/internal/logger"
+	"github.com/diagnosis/interactive-todo/internal/storage"
+	attachmentstore "github.com/diagnosis/interactive-todo/internal/store/attachments"
+)
+
+// purgeBatch bounds the objects deleted per run.
+const purgeBatch = 500
+
+// PurgeAttachmentsJob deletes the stored files of attachments whose rows
+// are gone, whether the attachment, its task or its team was deleted. A
+// key is forgotten only after its object is deleted, so failures are
+// retried on the next run.
+type PurgeAttachmentsJob struct {
+	store attachmentstore.AttachmentStore
+	files storage.Store
+}
+
+func NewPurgeAttachmentsJob(s attachmentstore.AttachmentStore, files storage.Store) *PurgeAttachmentsJob {
+	return &PurgeAttachmentsJob{store: s, files: files}
+}
+
+func (j *PurgeAttachmentsJob) Name() string { return "purge_attachments" }
+
+func (j *PurgeAttachmentsJob) Run(ctx context.Context) error {
+	keys, err := j.store.PendingPurges(ctx, purgeBatch)
+	if err != nil {
+		return err
+	}
+	done := make([]string, 0, len(keys))
+	for _, k := range keys {
+		if err := j.files.Delete(ctx, k); err != nil {
+			logger.Error(ctx, "purge attachments: delete failed", "key", k, "err", err)
+			continue
+		}
+		done = append(done, k)
+	}
+	if err := j.store.Purged(ctx, done); err != nil {
+		return err
+	}
+	if len(done) > 0 {
+		logger.Info(ctx, "purge attachments: purged", "count", len(done))
+	}
+	return nil
+}
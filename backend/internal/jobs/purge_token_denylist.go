@@ -0,0 +1,32 @@
+package jobs
+
+import (
+	"context"
+	"time"
+
+	"github.com/diagnosis/interactive-todo/internal/logger"
+	denyliststore "github.com/diagnosis/interactive-todo/internal/store/tokendenylist"
+)
+
+// PurgeTokenDenylistJob drops denylist rows whose tokens have expired on
+// their own; keeps the per-request lookup small.
+type PurgeTokenDenylistJob struct {
+	store denyliststore.DenylistStore
+}
+
+func NewPurgeTokenDenylistJob(s denyliststore.DenylistStore) *PurgeTokenDenylistJob {
+	return &PurgeTokenDenylistJob{store: s}
+}
+
+func (j *PurgeTokenDenylistJob) Name() string { return "purge_token_denylist" }
+
+func (j *PurgeTokenDenylistJob) Run(ctx context.Context) error {
+	n, err := j.store.Purge(ctx, time.Now().UTC())
+	if err != nil {
+		return err
+	}
+	if n > 0 {
+		logger.Info(ctx, "purge token denylist: purged", "count", n)
+	}
+	return nil
+}
@@ -0,0 +1,44 @@
+package jobs
+
+import (
+	"context"
+	"time"
+
+	"github.com/diagnosis/interactive-todo/internal/logger"
+	taskstore "github.com/diagnosis/interactive-todo/internal/store/tasks"
+)
+
+const purgeTrashBatchSize = 500
+
+// PurgeTrashJob deletes tasks that have been in the trash longer than the
+// retention, along with their comments, labels and attachments.
+type PurgeTrashJob struct {
+	taskStore taskstore.TaskStore
+	retention time.Duration
+}
+
+func NewPurgeTrashJob(ts taskstore.TaskStore, retention time.Duration) *PurgeTrashJob {
+	return &PurgeTrashJob{taskStore: ts, retention: retention}
+}
+
+func (j *PurgeTrashJob) Name() string { return "purge_trash" }
+
+func (j *PurgeTrashJob) Run(ctx context.Context) error {
+	cutoff := time.Now().UTC().Add(-j.retention)
+
+	total := 0
+	for {
+		n, err := j.taskStore.PurgeTrash(ctx, cutoff, purgeTrashBatchSize)
+		if err != nil {
+			return err
+		}
+		total += n
+		if n < purgeTrashBatchSize || ctx.Err() != nil {
+			break
+		}
+	}
+	if total > 0 {
+		logger.Info(ctx, "purge trash: deleted", "count", total)
+	}
+	return nil
+}
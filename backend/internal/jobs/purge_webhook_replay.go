@@ -0,0 +1,32 @@
+package jobs
+
+import (
+	"context"
+	"time"
+
+	"github.com/diagnosis/interactive-todo/internal/logger"
+	replaystore "github.com/diagnosis/interactive-todo/internal/store/webhookreplay"
+)
+
+// PurgeWebhookReplayJob drops remembered webhook deliveries whose
+// signatures would be rejected as stale anyway.
+type PurgeWebhookReplayJob struct {
+	store replaystore.ReplayStore
+}
+
+func NewPurgeWebhookReplayJob(s replaystore.ReplayStore) *PurgeWebhookReplayJob {
+	return &PurgeWebhookReplayJob{store: s}
+}
+
+func (j *PurgeWebhookReplayJob) Name() string { return "purge_webhook_replay" }
+
+func (j *PurgeWebhookReplayJob) Run(ctx context.Context) error {
+	n, err := j.store.Purge(ctx, time.Now().UTC())
+	if err != nil {
+		return err
+	}
+	if n > 0 {
+		logger.Info(ctx, "purge webhook replay: purged", "count", n)
+	}
+	return nil
+}
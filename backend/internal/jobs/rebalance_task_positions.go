@@ -0,0 +1,32 @@
+package jobs
+
+import (
+	"context"
+
+	"github.com/diagnosis/interactive-todo/internal/logger"
+	taskstore "github.com/diagnosis/interactive-todo/internal/store/tasks"
+)
+
+// rebalanceBatch caps the board columns renumbered per run.
+const rebalanceBatch = 200
+
+// RebalanceTaskPositionsJob renumbers board columns whose cards got too
+// close to be split again. Moves renumber the column they write to on
+// their own; this catches the rest.
+type RebalanceTaskPositionsJob struct {
+	taskStore taskstore.TaskStore
+}
+
+func NewRebalanceTaskPositionsJob(ts taskstore.TaskStore) *RebalanceTaskPositionsJob {
+	return &RebalanceTaskPositionsJob{taskStore: ts}
+}
+
+func (j *RebalanceTaskPositionsJob) Name() string { return "rebalance_task_positions" }
+
+func (j *RebalanceTaskPositionsJob) Run(ctx context.Context) error {
+	n, err := j.taskStore.RebalancePositions(ctx, rebalanceBatch)
+	if n > 0 {
+		logger.Info(ctx, "rebalance task positions: renumbered columns", "count", n)
+	}
+	return err
+}
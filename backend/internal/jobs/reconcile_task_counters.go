@@ -0,0 +1,32 @@
+package jobs
+
+import (
+	"context"
+	"time"
+
+	"github.com/diagnosis/interactive-todo/internal/logger"
+	taskstore "github.com/diagnosis/interactive-todo/internal/store/tasks"
+)
+
+// ReconcileTaskCountersJob corrects team task counters that drifted from
+// the tasks table, e.g. after manual data fixes that bypassed the trigger.
+type ReconcileTaskCountersJob struct {
+	taskStore taskstore.TaskStore
+}
+
+func NewReconcileTaskCountersJob(ts taskstore.TaskStore) *ReconcileTaskCountersJob {
+	return &ReconcileTaskCountersJob{taskStore: ts}
+}
+
+func (j *ReconcileTaskCountersJob) Name() string { return "reconcile_task_counters" }
+
+func (j *ReconcileTaskCountersJob) Run(ctx context.Context) error {
+	n, err := j.taskStore.ReconcileTaskCounters(ctx, time.Now().UTC())
+	if err != nil {
+		return err
+	}
+	if n > 0 {
+		logger.Warn(ctx, "reconcile task counters: corrected drift", "count", n)
+	}
+	return nil
+}
@@ -0,0 +1,87 @@
+package jobs
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/diagnosis/interactive-todo/internal/logger"
+	rotationstore "github.com/diagnosis/interactive-todo/internal/store/rotations"
+	"github.com/google/uuid"
+)
+
+const rotateOnCallBatchSize = 500
+
+// RotateOnCallJob hands each on-call rotation off to its next member once
+// its hand-off time has come. A rotation missed for several periods, say
+// while the service was down, moves on one member per period, so it stays
+// on the schedule it would have kept. When the member on call leaves the
+// team, the first member takes over until the next hand-off.
+type RotateOnCallJob struct {
+	rotationStore rotationstore.RotationStore
+}
+
+func NewRotateOnCallJob(rs rotationstore.RotationStore) *RotateOnCallJob {
+	return &RotateOnCallJob{rotationStore: rs}
+}
+
+func (j *RotateOnCallJob) Name() string { return "rotate_on_call" }
+
+func (j *RotateOnCallJob) Run(ctx context.Context) error {
+	now := time.Now().UTC()
+
+	due, err := j.rotationStore.ListDue(ctx, now, rotateOnCallBatchSize)
+	if err != nil {
+		return err
+	}
+
+	handed := 0
+	for i := range due {
+		r := &due[i]
+		onCallID, handoffAt := nextOnCall(r, now)
+		ok, err := j.rotationStore.HandOff(ctx, r, onCallID, handoffAt, now)
+		if err != nil {
+			return fmt.Errorf("rotate on call: rotation_id=%s: %w", r.ID, err)
+		}
+		if !ok {
+			continue
+		}
+		logger.Info(ctx, "rotate on call: handed off",
+			"rotation_id", r.ID,
+			"team_id", r.TeamID,
+			"on_call_id", onCallID,
+			"until", handoffAt,
+		)
+		handed++
+	}
+
+	if handed > 0 {
+		logger.Info(ctx, "rotate on call: done", "count", handed)
+	}
+	return nil
+}
+
+// nextOnCall returns who is on call at now and until when. r has members.
+func nextOnCall(r *rotationstore.Rotation, now time.Time) (uuid.UUID, time.Time) {
+	period := rotationstore.Period(r.Cadence)
+	handoffAt, steps := r.HandoffAt, 0
+	if !now.Before(handoffAt) {
+		steps = int(now.Sub(handoffAt)/period) + 1
+		handoffAt = handoffAt.Add(time.Duration(steps) * period)
+	}
+
+	n := len(r.MemberIDs)
+	pos := -1
+	if r.OnCallID != nil {
+		pos = slices.Index(r.MemberIDs, *r.OnCallID)
+	}
+	if pos < 0 {
+		// nobody is on call; the first member is, as of the last hand-off
+		pos = 0
+		if steps > 0 {
+			steps--
+		}
+	}
+	return r.MemberIDs[(pos+steps)%n], handoffAt
+}
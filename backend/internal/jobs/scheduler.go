@@ -0,0 +1,84 @@
+package jobs
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/diagnosis/interactive-todo/internal/logger"
+)
+
+// Job is a unit of periodic background work
+type Job interface {
+	Name() string
+	Run(ctx context.Context) error
+}
+
+type entry struct {
+	job      Job
+	interval time.Duration
+}
+
+// Scheduler runs registered jobs on fixed intervals until stopped
+type Scheduler struct {
+	mu      sync.Mutex
+	entries []entry
+	cancel  context.CancelFunc
+	wg      sync.WaitGroup
+}
+
+func NewScheduler() *Scheduler {
+	return &Scheduler{}
+}
+
+// Register adds a job; it must be called before Start
+func (s *Scheduler) Register(job Job, interval time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.entries = append(s.entries, entry{job: job, interval: interval})
+}
+
+func (s *Scheduler) Start(ctx context.Context) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	ctx, s.cancel = context.WithCancel(ctx)
+	for _, e := range s.entries {
+		s.wg.Add(1)
+		go s.loop(ctx, e)
+	}
+	logger.Info(ctx, "scheduler started", "jobs", len(s.entries))
+}
+
+// Stop cancels running jobs and waits for them to return
+func (s *Scheduler) Stop() {
+	s.mu.Lock()
+	cancel := s.cancel
+	s.mu.Unlock()
+
+	if cancel != nil {
+		cancel()
+	}
+	s.wg.Wait()
+}
+
+func (s *Scheduler) loop(ctx context.Context, e entry) {
+	defer s.wg.Done()
+
+	ticker := time.NewTicker(e.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			start := time.Now()
+			if err := e.job.Run(ctx); err != nil {
+				logger.Error(ctx, "job failed", "job", e.job.Name(), "err", err)
+				continue
+			}
+			logger.Debug(ctx, "job finished", "job", e.job.Name(), "took", time.Since(start))
+		}
+	}
+}
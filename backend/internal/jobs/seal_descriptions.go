@@ -0,0 +1,34 @@
+package jobs
+
+import (
+	"context"
+
+	"github.com/diagnosis/interactive-todo/internal/logger"
+	taskstore "github.com/diagnosis/interactive-todo/internal/store/tasks"
+)
+
+const sealBatchSize = 500
+
+// SealDescriptionsJob encrypts descriptions that are still plaintext in
+// confidential teams, typically tasks created before the team was marked
+// confidential. Only registered when a field encryption key is configured.
+type SealDescriptionsJob struct {
+	taskStore taskstore.TaskStore
+}
+
+func NewSealDescriptionsJob(ts taskstore.TaskStore) *SealDescriptionsJob {
+	return &SealDescriptionsJob{taskStore: ts}
+}
+
+func (j *SealDescriptionsJob) Name() string { return "seal_descriptions" }
+
+func (j *SealDescriptionsJob) Run(ctx context.Context) error {
+	n, err := j.taskStore.SealConfidentialDescriptions(ctx, sealBatchSize)
+	if err != nil {
+		return err
+	}
+	if n > 0 {
+		logger.Info(ctx, "seal descriptions: sealed", "count", n)
+	}
+	return nil
+}
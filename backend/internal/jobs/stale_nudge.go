@@ -0,0 +1,76 @@
+package jobs
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/diagnosis/interactive-todo/internal/logger"
+	"github.com/diagnosis/interactive-todo/internal/notify"
+	calendarstore "github.com/diagnosis/interactive-todo/internal/store/calendars"
+	taskstore "github.com/diagnosis/interactive-todo/internal/store/tasks"
+	teamstore "github.com/diagnosis/interactive-todo/internal/store/teams"
+)
+
+// StaleNudgeJob nudges assignees of tasks that went stale beyond their
+// team's stale_nudge_days setting, counted in working days. Each task is
+// nudged once per update, and never on the team's days off.
+type StaleNudgeJob struct {
+	taskStore     taskstore.TaskStore
+	teamStore     teamstore.TeamStore
+	calendarStore calendarstore.CalendarStore
+	notifier      notify.Notifier
+}
+
+func NewStaleNudgeJob(ts taskstore.TaskStore, tms teamstore.TeamStore, cs calendarstore.CalendarStore, n notify.Notifier) *StaleNudgeJob {
+	return &StaleNudgeJob{taskStore: ts, teamStore: tms, calendarStore: cs, notifier: n}
+}
+
+func (j *StaleNudgeJob) Name() string { return "stale_nudge" }
+
+func (j *StaleNudgeJob) Run(ctx context.Context) error {
+	now := time.Now().UTC()
+
+	// candidates are stale in calendar days; working days can only be fewer
+	tasks, err := j.taskStore.FindStaleForNudge(ctx, now)
+	if err != nil {
+		return err
+	}
+
+	rules := newTeamRules(j.calendarStore, j.teamStore)
+	sent := 0
+	for _, t := range tasks {
+		cal, err := rules.calendar(ctx, t.TeamID)
+		if err != nil {
+			return fmt.Errorf("stale nudge: team_id=%s: %w", t.TeamID, err)
+		}
+		settings, err := rules.teamSettings(ctx, t.TeamID)
+		if err != nil {
+			return fmt.Errorf("stale nudge: team_id=%s: %w", t.TeamID, err)
+		}
+		if !cal.IsWorkingDay(now) || settings.StaleNudgeDays == nil ||
+			cal.WorkingDaysBetween(t.UpdatedAt, now) < *settings.StaleNudgeDays {
+			continue
+		}
+
+		if err := j.notifier.Notify(ctx, notify.Notification{
+			UserID:  t.AssigneeID,
+			Kind:    notify.KindStaleTask,
+			Subject: fmt.Sprintf("Task %q has not been updated since %s", t.Title, t.UpdatedAt.Format("2006-01-02")),
+			TaskID:  &t.ID,
+			TeamID:  &t.TeamID,
+		}); err != nil {
+			logger.Error(ctx, "stale nudge: notify failed", "task_id", t.ID, "err", err)
+			continue
+		}
+		if err := j.taskStore.MarkStaleNudged(ctx, t.ID, now); err != nil {
+			return fmt.Errorf("stale nudge: mark task_id=%s: %w", t.ID, err)
+		}
+		sent++
+	}
+
+	if sent > 0 {
+		logger.Info(ctx, "stale nudge: sent", "count", sent)
+	}
+	return nil
+}
@@ -0,0 +1,114 @@
+package jobs
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/diagnosis/interactive-todo/internal/logger"
+	"github.com/diagnosis/interactive-todo/internal/notify"
+	reportstore "github.com/diagnosis/interactive-todo/internal/store/reports"
+	"github.com/google/uuid"
+)
+
+const statusReportBatchSize = 200
+
+// StatusReportJob sends the weekly status report to reporters who opted
+// in: the status changes and the comments of others on the tasks they
+// created, since their previous report. A week without any is skipped, but
+// still counts as sent. Each report links to its unsubscribe page under
+// baseURL, the server's public address.
+type StatusReportJob struct {
+	reportStore reportstore.ReportStore
+	notifier    notify.Notifier
+	baseURL     string
+}
+
+func NewStatusReportJob(rs reportstore.ReportStore, n notify.Notifier, baseURL string) *StatusReportJob {
+	return &StatusReportJob{reportStore: rs, notifier: n, baseURL: strings.TrimSuffix(baseURL, "/")}
+}
+
+func (j *StatusReportJob) Name() string { return "status_report" }
+
+func (j *StatusReportJob) Run(ctx context.Context) error {
+	now := time.Now().UTC()
+
+	sent := 0
+	for {
+		subs, err := j.reportStore.ListDue(ctx, now, statusReportBatchSize)
+		if err != nil {
+			return err
+		}
+		for _, sub := range subs {
+			since := sub.CreatedAt
+			if sub.LastSentAt != nil {
+				since = *sub.LastSentAt
+			}
+			activity, err := j.reportStore.Activity(ctx, sub.UserID, since, now)
+			if err != nil {
+				return fmt.Errorf("status report: user_id=%s: %w", sub.UserID, err)
+			}
+
+			if len(activity) > 0 {
+				if err := j.notifier.Notify(ctx, notify.Notification{
+					UserID:  sub.UserID,
+					Kind:    notify.KindStatusReport,
+					Subject: statusReportSubject(activity),
+					Body:    j.statusReportBody(activity, since, sub.UnsubscribeToken),
+				}); err != nil {
+					// left due, so the next run tries again
+					logger.Error(ctx, "status report: notify failed", "user_id", sub.UserID, "err", err)
+					continue
+				}
+				sent++
+			}
+			if err := j.reportStore.MarkSent(ctx, sub.UserID, now); err != nil {
+				return fmt.Errorf("status report: mark user_id=%s: %w", sub.UserID, err)
+			}
+		}
+		if len(subs) < statusReportBatchSize || ctx.Err() != nil {
+			break
+		}
+	}
+
+	if sent > 0 {
+		logger.Info(ctx, "status report: sent", "count", sent)
+	}
+	return nil
+}
+
+func statusReportSubject(activity []reportstore.TaskActivity) string {
+	if len(activity) == 1 {
+		return "Weekly report: 1 of your tasks had activity"
+	}
+	return fmt.Sprintf("Weekly report: %d of your tasks had activity", len(activity))
+}
+
+// statusReportBody lists the tasks by team, in the order the store returns
+// them.
+func (j *StatusReportJob) statusReportBody(activity []reportstore.TaskActivity, since time.Time, token string) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "Activity on the tasks you created since %s:\n", since.Format("2006-01-02"))
+
+	var team uuid.UUID
+	for _, a := range activity {
+		if a.TeamID != team {
+			team = a.TeamID
+			fmt.Fprintf(&b, "\n%s\n", a.TeamName)
+		}
+		fmt.Fprintf(&b, "- %s (%s)\n", a.Title, a.Status)
+		for _, c := range a.StatusChanges {
+			fmt.Fprintf(&b, "    %s: %s -> %s\n", c.At.Format("2006-01-02"), c.From, c.To)
+		}
+		switch {
+		case a.Comments == 1:
+			b.WriteString("    1 new comment\n")
+		case a.Comments > 1:
+			fmt.Fprintf(&b, "    %d new comments\n", a.Comments)
+		}
+	}
+
+	fmt.Fprintf(&b, "\nTo stop these reports: %s/v1/status-reports/unsubscribe/%s\n", j.baseURL, token)
+	return b.String()
+}
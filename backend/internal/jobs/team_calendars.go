@@ -0,0 +1,51 @@
+package jobs
+
+import (
+	"context"
+
+	"github.com/diagnosis/interactive-todo/internal/calendar"
+	calendarstore "github.com/diagnosis/interactive-todo/internal/store/calendars"
+	teamstore "github.com/diagnosis/interactive-todo/internal/store/teams"
+	"github.com/google/uuid"
+)
+
+// teamRules loads each team's calendar and settings once per job run.
+type teamRules struct {
+	calendars calendarstore.CalendarStore
+	teams     teamstore.TeamStore
+	cals      map[uuid.UUID]*calendar.Calendar
+	settings  map[uuid.UUID]*teamstore.TeamSettings
+}
+
+func newTeamRules(cs calendarstore.CalendarStore, ts teamstore.TeamStore) *teamRules {
+	return &teamRules{
+		calendars: cs,
+		teams:     ts,
+		cals:      map[uuid.UUID]*calendar.Calendar{},
+		settings:  map[uuid.UUID]*teamstore.TeamSettings{},
+	}
+}
+
+func (r *teamRules) calendar(ctx context.Context, teamID uuid.UUID) (*calendar.Calendar, error) {
+	if cal, ok := r.cals[teamID]; ok {
+		return cal, nil
+	}
+	cal, err := r.calendars.Load(ctx, teamID)
+	if err != nil {
+		return nil, err
+	}
+	r.cals[teamID] = cal
+	return cal, nil
+}
+
+func (r *teamRules) teamSettings(ctx context.Context, teamID uuid.UUID) (*teamstore.TeamSettings, error) {
+	if s, ok := r.settings[teamID]; ok {
+		return s, nil
+	}
+	s, err := r.teams.GetSettings(ctx, teamID)
+	if err != nil {
+		return nil, err
+	}
+	r.settings[teamID] = s
+	return s, nil
+}
@@ -0,0 +1,37 @@
+package jobs
+
+import (
+	"context"
+	"time"
+
+	"github.com/diagnosis/interactive-todo/internal/logger"
+	auditstore "github.com/diagnosis/interactive-todo/internal/store/audit"
+)
+
+// TaskViewRetention is how long task.viewed entries are kept. Entries of
+// teams or tasks under legal hold are kept regardless.
+const TaskViewRetention = 90 * 24 * time.Hour
+
+// ViewLogRetentionJob purges task view entries older than the retention
+// window; they are high volume and only useful for recent investigations.
+type ViewLogRetentionJob struct {
+	auditStore auditstore.AuditStore
+	retention  time.Duration
+}
+
+func NewViewLogRetentionJob(as auditstore.AuditStore, retention time.Duration) *ViewLogRetentionJob {
+	return &ViewLogRetentionJob{auditStore: as, retention: retention}
+}
+
+func (j *ViewLogRetentionJob) Name() string { return "view_log_retention" }
+
+func (j *ViewLogRetentionJob) Run(ctx context.Context) error {
+	n, err := j.auditStore.PurgeBefore(ctx, auditstore.ActionTaskViewed, time.Now().UTC().Add(-j.retention))
+	if err != nil {
+		return err
+	}
+	if n > 0 {
+		logger.Info(ctx, "view log retention: purged", "count", n)
+	}
+	return nil
+}
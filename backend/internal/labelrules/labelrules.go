@@ -0,0 +1,132 @@
+// Package labelrules applies a team's "title contains X -> add label Y,
+// priority Z" rules to task text. It is pure so the dry-run endpoint can
+// preview exactly what create and edit would do.
+package labelrules
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/diagnosis/interactive-todo/api/types"
+	labelstore "github.com/diagnosis/interactive-todo/internal/store/labels"
+)
+
+type Rule = types.LabelRule
+
+type Priority = types.TaskPriority
+
+const (
+	// MaxContains bounds the match text; rules are for keywords, not
+	// paragraphs.
+	MaxContains = 100
+	// MaxLabels is how many labels one rule may add.
+	MaxLabels = 10
+)
+
+var ErrInvalid = errors.New("invalid label rule")
+
+func ValidPriority(p Priority) bool {
+	return rank(p) > 0
+}
+
+func rank(p Priority) int {
+	switch p {
+	case types.TaskPriorityLow:
+		return 1
+	case types.TaskPriorityNormal:
+		return 2
+	case types.TaskPriorityHigh:
+		return 3
+	case types.TaskPriorityUrgent:
+		return 4
+	default:
+		return 0
+	}
+}
+
+// Normalize trims and checks a rule request in place. Field defaults to
+// title; a rule must add a label or set a priority.
+func Normalize(in *types.LabelRuleRequest) error {
+	in.Name = strings.TrimSpace(in.Name)
+	if in.Name == "" || len(in.Name) > 100 {
+		return fmt.Errorf("%w: name is required (max 100 chars)", ErrInvalid)
+	}
+	switch in.Field {
+	case "":
+		in.Field = types.LabelRuleFieldTitle
+	case types.LabelRuleFieldTitle, types.LabelRuleFieldDescription, types.LabelRuleFieldAny:
+	default:
+		return fmt.Errorf("%w: field must be title, description or any", ErrInvalid)
+	}
+	in.Contains = strings.TrimSpace(in.Contains)
+	if in.Contains == "" || len(in.Contains) > MaxContains {
+		return fmt.Errorf("%w: contains is required (max %d chars)", ErrInvalid, MaxContains)
+	}
+	in.AddLabels = labelstore.NormalizeNames(in.AddLabels)
+	if len(in.AddLabels) > MaxLabels {
+		return fmt.Errorf("%w: at most %d labels per rule", ErrInvalid, MaxLabels)
+	}
+	for _, l := range in.AddLabels {
+		if len(l) > labelstore.MaxNameLength {
+			return fmt.Errorf("%w: labels must be at most %d chars", ErrInvalid, labelstore.MaxNameLength)
+		}
+	}
+	if in.SetPriority != nil && !ValidPriority(*in.SetPriority) {
+		return fmt.Errorf("%w: set_priority must be low, normal, high or urgent", ErrInvalid)
+	}
+	if len(in.AddLabels) == 0 && in.SetPriority == nil {
+		return fmt.Errorf("%w: a rule must add a label or set a priority", ErrInvalid)
+	}
+	return nil
+}
+
+// Matches reports whether r's field contains its text, ignoring case.
+// Disabled rules still match; Evaluate is what skips them.
+func Matches(r Rule, title string, description *string) bool {
+	needle := strings.ToLower(r.Contains)
+	inTitle := strings.Contains(strings.ToLower(title), needle)
+	inDescription := description != nil && strings.Contains(strings.ToLower(*description), needle)
+	switch r.Field {
+	case types.LabelRuleFieldTitle:
+		return inTitle
+	case types.LabelRuleFieldDescription:
+		return inDescription
+	case types.LabelRuleFieldAny:
+		return inTitle || inDescription
+	default:
+		return false
+	}
+}
+
+// Outcome is what the matching rules ask for.
+type Outcome struct {
+	// Rules names the rules that matched, in evaluation order.
+	Rules []string
+	// Labels is the union of their labels, first spelling kept.
+	Labels []string
+	// Priority is the highest priority any of them sets, or nil.
+	Priority *Priority
+}
+
+func (o Outcome) Matched() bool {
+	return len(o.Rules) > 0
+}
+
+// Evaluate runs the enabled rules in order against a task's text.
+func Evaluate(rules []Rule, title string, description *string) Outcome {
+	var out Outcome
+	for _, r := range rules {
+		if !r.Enabled || !Matches(r, title, description) {
+			continue
+		}
+		out.Rules = append(out.Rules, r.Name)
+		out.Labels = append(out.Labels, r.AddLabels...)
+		if r.SetPriority != nil && (out.Priority == nil || rank(*r.SetPriority) > rank(*out.Priority)) {
+			p := *r.SetPriority
+			out.Priority = &p
+		}
+	}
+	out.Labels = labelstore.NormalizeNames(out.Labels)
+	return out
+}
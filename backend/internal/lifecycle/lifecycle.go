@@ -0,0 +1,122 @@
+// Package lifecycle starts and stops the application's subsystems in
+// dependency order.
+package lifecycle
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/diagnosis/interactive-todo/internal/logger"
+)
+
+// DefaultTimeout bounds a hook that sets no Timeout of its own.
+const DefaultTimeout = 15 * time.Second
+
+// Hook is one subsystem. Either func may be nil.
+//
+// OnStart gets the context passed to Start, which lives as long as the
+// application, so it can hand it to goroutines; it must return once the
+// subsystem is running. OnStop gets a context that expires after Timeout.
+type Hook struct {
+	Name    string
+	OnStart func(ctx context.Context) error
+	OnStop  func(ctx context.Context) error
+	// Timeout bounds each of OnStart and OnStop; DefaultTimeout when zero
+	Timeout time.Duration
+}
+
+// Manager runs hooks in the order they were appended and stops them in
+// reverse, so a subsystem is appended after everything it uses.
+type Manager struct {
+	mu      sync.Mutex
+	hooks   []Hook
+	started int
+}
+
+func New() *Manager {
+	return &Manager{}
+}
+
+// Append adds a hook; it must be called before Start.
+func (m *Manager) Append(h Hook) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.hooks = append(m.hooks, h)
+}
+
+// Start runs every OnStart in order. If one fails or overruns its timeout,
+// the hooks already started are stopped again and the error is returned.
+func (m *Manager) Start(ctx context.Context) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for _, h := range m.hooks[m.started:] {
+		if h.OnStart != nil {
+			// the hook keeps ctx; the timeout only bounds the wait
+			if err := wait(h.timeout(), func() error { return h.OnStart(ctx) }); err != nil {
+				err = fmt.Errorf("start %s: %w", h.Name, err)
+				if stopErr := m.stop(ctx); stopErr != nil {
+					err = errors.Join(err, stopErr)
+				}
+				return err
+			}
+		}
+		m.started++
+		logger.Info(ctx, "lifecycle: started", "hook", h.Name)
+	}
+	return nil
+}
+
+// Stop runs OnStop of every started hook, last started first. A failing or
+// overrunning hook is logged and the rest still stop; the errors are
+// joined.
+func (m *Manager) Stop(ctx context.Context) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.stop(ctx)
+}
+
+func (m *Manager) stop(ctx context.Context) error {
+	var errs []error
+	for ; m.started > 0; m.started-- {
+		h := m.hooks[m.started-1]
+		if h.OnStop == nil {
+			continue
+		}
+		stopCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), h.timeout())
+		err := wait(h.timeout(), func() error { return h.OnStop(stopCtx) })
+		cancel()
+		if err != nil {
+			logger.Error(ctx, "lifecycle: stop failed", "hook", h.Name, "err", err)
+			errs = append(errs, fmt.Errorf("stop %s: %w", h.Name, err))
+			continue
+		}
+		logger.Info(ctx, "lifecycle: stopped", "hook", h.Name)
+	}
+	return errors.Join(errs...)
+}
+
+func (h Hook) timeout() time.Duration {
+	if h.Timeout > 0 {
+		return h.Timeout
+	}
+	return DefaultTimeout
+}
+
+// wait runs fn and gives up after d. A hook that overruns is left running:
+// there is no way to interrupt it, only to stop waiting.
+func wait(d time.Duration, fn func() error) error {
+	done := make(chan error, 1)
+	go func() { done <- fn() }()
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case err := <-done:
+		return err
+	case <-t.C:
+		return fmt.Errorf("timed out after %s", d)
+	}
+}
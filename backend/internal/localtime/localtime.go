@@ -0,0 +1,73 @@
+// Package localtime reads and writes times in a user's timezone. Times are
+// stored and compared in UTC; a user's timezone only changes how they type
+// a time in and how it is shown back to them.
+package localtime
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+	// user timezones must resolve on hosts without a zoneinfo database
+	_ "time/tzdata"
+)
+
+// Default is the timezone of users who have not set one.
+const Default = "UTC"
+
+var (
+	ErrUnknownZone = errors.New("unknown timezone")
+	ErrBadInput    = errors.New("invalid local time")
+)
+
+// InputLayouts are the local times users may type, without an offset.
+var InputLayouts = []string{
+	"2006-01-02 15:04",
+	"2006-01-02 15:04:05",
+	"2006-01-02T15:04",
+	"2006-01-02T15:04:05",
+}
+
+// locations caches loaded zones; every task response looks one up.
+var locations sync.Map
+
+// Load returns the IANA zone name (e.g. "Europe/Berlin"). The empty name
+// is Default.
+func Load(name string) (*time.Location, error) {
+	if name == "" {
+		name = Default
+	}
+	if loc, ok := locations.Load(name); ok {
+		return loc.(*time.Location), nil
+	}
+	// "Local" would mean the server's zone, which no user is in
+	if name == "Local" {
+		return nil, fmt.Errorf("%w: %q", ErrUnknownZone, name)
+	}
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %q", ErrUnknownZone, name)
+	}
+	locations.Store(name, loc)
+	return loc, nil
+}
+
+// Parse reads s, one of InputLayouts, as a wall clock time in loc. A time
+// skipped by a daylight saving change is moved forward by the change, as
+// time.Date does; a repeated one is the first of the two.
+func Parse(s string, loc *time.Location) (time.Time, error) {
+	s = strings.TrimSpace(s)
+	for _, layout := range InputLayouts {
+		if t, err := time.ParseInLocation(layout, s, loc); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("%w: %q, use YYYY-MM-DD HH:MM", ErrBadInput, s)
+}
+
+// Format writes t for people reading a notification, in loc with the
+// zone's abbreviation, e.g. "Sat 1 Mar 2025 17:00 CET".
+func Format(t time.Time, loc *time.Location) string {
+	return t.In(loc).Format("Mon 2 Jan 2006 15:04 MST")
+}
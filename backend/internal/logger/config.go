@@ -0,0 +1,74 @@
+package logger
+
+import (
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"strconv"
+	"strings"
+)
+
+const (
+	FormatText = "text"
+	FormatJSON = "json"
+)
+
+// Config controls the global logger. Zero values fall back to defaults via
+// ConfigFromEnv.
+type Config struct {
+	Level  slog.Level
+	Format string
+	// DebugSampleN keeps one in N debug records; 1 keeps them all.
+	DebugSampleN int
+	// Redact masks emails, tokens and IPs outside the safe key allowlist.
+	Redact bool
+	Output io.Writer
+}
+
+// ConfigFromEnv reads LOG_LEVEL (debug|info|warn|error), LOG_FORMAT
+// (text|json), LOG_DEBUG_SAMPLE (keep 1 in N debug logs) and LOG_REDACT
+// (true|false). Production defaults to info level, samples debug 1 in 100 if
+// it is enabled, and redacts.
+func ConfigFromEnv() (Config, error) {
+	prod := os.Getenv("APP_ENV") == "production"
+
+	cfg := Config{
+		Level:        slog.LevelDebug,
+		Format:       FormatText,
+		DebugSampleN: 1,
+		Output:       os.Stdout,
+	}
+	if prod {
+		cfg.Level = slog.LevelInfo
+		cfg.DebugSampleN = 100
+		cfg.Redact = true
+	}
+
+	if v := strings.TrimSpace(os.Getenv("LOG_LEVEL")); v != "" {
+		if err := cfg.Level.UnmarshalText([]byte(v)); err != nil {
+			return cfg, fmt.Errorf("LOG_LEVEL: %w", err)
+		}
+	}
+	if v := strings.ToLower(strings.TrimSpace(os.Getenv("LOG_FORMAT"))); v != "" {
+		if v != FormatText && v != FormatJSON {
+			return cfg, fmt.Errorf("LOG_FORMAT must be %q or %q", FormatText, FormatJSON)
+		}
+		cfg.Format = v
+	}
+	if v := strings.TrimSpace(os.Getenv("LOG_DEBUG_SAMPLE")); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 {
+			return cfg, fmt.Errorf("LOG_DEBUG_SAMPLE must be a positive integer")
+		}
+		cfg.DebugSampleN = n
+	}
+	if v := strings.TrimSpace(os.Getenv("LOG_REDACT")); v != "" {
+		b, err := strconv.ParseBool(v)
+		if err != nil {
+			return cfg, fmt.Errorf("LOG_REDACT must be true or false")
+		}
+		cfg.Redact = b
+	}
+	return cfg, nil
+}
@@ -0,0 +1,87 @@
+package logger
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"sync/atomic"
+	"time"
+
+	"github.com/diagnosis/interactive-todo/internal/deadline"
+	"github.com/diagnosis/interactive-todo/internal/helper"
+	chimiddleware "github.com/go-chi/chi/v5/middleware"
+)
+
+type ctxKey string
+
+const userIDKey ctxKey = "log_user_id"
+
+// WithUserID attaches the authenticated user to every log line written with
+// ctx. The auth middleware calls it once the token is validated.
+func WithUserID(ctx context.Context, userID string) context.Context {
+	return context.WithValue(ctx, userIDKey, userID)
+}
+
+// contextHandler adds per-request fields found in the context and samples
+// debug records.
+type contextHandler struct {
+	next    slog.Handler
+	sampleN uint64
+	counter *atomic.Uint64
+}
+
+func newContextHandler(next slog.Handler, sampleN int) *contextHandler {
+	if sampleN < 1 {
+		sampleN = 1
+	}
+	return &contextHandler{next: next, sampleN: uint64(sampleN), counter: &atomic.Uint64{}}
+}
+
+func (h *contextHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	return h.next.Enabled(ctx, level)
+}
+
+func (h *contextHandler) Handle(ctx context.Context, r slog.Record) error {
+	if r.Level == slog.LevelDebug && h.sampleN > 1 {
+		if h.counter.Add(1)%h.sampleN != 1 {
+			return nil
+		}
+	}
+
+	// errors logged after the client hung up are expected fallout, not
+	// server failures
+	if r.Level >= slog.LevelError && errors.Is(ctx.Err(), context.Canceled) {
+		if !h.next.Enabled(ctx, slog.LevelInfo) {
+			return nil
+		}
+		r.Level = slog.LevelInfo
+		r.AddAttrs(slog.Bool("client_canceled", true))
+	}
+
+	if id := chimiddleware.GetReqID(ctx); id != "" {
+		r.AddAttrs(slog.String("request_id", id))
+	}
+	if id := helper.GetCorrelationID(ctx); id != "" {
+		r.AddAttrs(slog.String("correlation_id", id))
+	}
+	if id, ok := ctx.Value(userIDKey).(string); ok && id != "" {
+		r.AddAttrs(slog.String("user_id", id))
+	}
+	// lines logged after a budget ran out say how much time it had
+	if limit, elapsed, ok := deadline.Exceeded(ctx); ok {
+		r.AddAttrs(
+			slog.Bool("deadline_exceeded", true),
+			slog.Duration("budget", limit),
+			slog.Duration("elapsed", elapsed.Round(time.Millisecond)),
+		)
+	}
+	return h.next.Handle(ctx, r)
+}
+
+func (h *contextHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return &contextHandler{next: h.next.WithAttrs(attrs), sampleN: h.sampleN, counter: h.counter}
+}
+
+func (h *contextHandler) WithGroup(name string) slog.Handler {
+	return &contextHandler{next: h.next.WithGroup(name), sampleN: h.sampleN, counter: h.counter}
+}
@@ -10,20 +10,32 @@ import (
 
 var globalLogger *slog.Logger
 
+// init installs an env-based logger so packages can log before main calls
+// Init (e.g. once .env has been loaded).
 func init() {
-	env := os.Getenv("APP_ENV")
+	cfg, _ := ConfigFromEnv()
+	Init(cfg)
+}
+
+// Init replaces the global logger.
+func Init(cfg Config) {
+	if cfg.Output == nil {
+		cfg.Output = os.Stdout
+	}
+	opts := &slog.HandlerOptions{Level: cfg.Level}
+	if cfg.Redact {
+		opts.ReplaceAttr = redactAttr
+	}
+
 	var handler slog.Handler
-	if env == "production" {
-		handler = slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{
-			Level: slog.LevelInfo,
-		})
+	if cfg.Format == FormatJSON {
+		handler = slog.NewJSONHandler(cfg.Output, opts)
 	} else {
-		handler = slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{
-			Level: slog.LevelDebug,
-		})
+		handler = slog.NewTextHandler(cfg.Output, opts)
 	}
-	globalLogger = slog.New(handler)
+	globalLogger = slog.New(newContextHandler(handler, cfg.DebugSampleN))
 }
+
 func Get() *slog.Logger {
 	return globalLogger
 }
@@ -34,12 +46,11 @@ func WithCorrelationID(ctx context.Context, correlationID string) context.Contex
 func GetCorrelationId(ctx context.Context) string {
 	return helper.GetCorrelationID(ctx)
 }
+
+// FromContext returns the global logger; request fields (request_id,
+// correlation_id, user_id) are added from ctx when a record is written.
 func FromContext(ctx context.Context) *slog.Logger {
-	logger := globalLogger
-	if id := GetCorrelationId(ctx); id != "" {
-		logger = logger.With("correlation_id", id)
-	}
-	return logger
+	return globalLogger
 }
 
 func Info(ctx context.Context, msg string, args ...any) {
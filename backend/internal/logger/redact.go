@@ -0,0 +1,123 @@
+package logger
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"log/slog"
+	"net/netip"
+	"regexp"
+	"strings"
+)
+
+// safeKeys are logged verbatim. Everything else that is a string is scrubbed
+// for emails and tokens; add a key here only if its values can never carry
+// personal data or credentials.
+var safeKeys = map[string]bool{
+	slog.TimeKey:     true,
+	slog.LevelKey:    true,
+	slog.MessageKey:  true,
+	slog.SourceKey:   true,
+	"request_id":     true,
+	"correlation_id": true,
+	"user_id":        true,
+	"admin_id":       true,
+	"task_id":        true,
+	"team_id":        true,
+	"form_id":        true,
+	"hold_id":        true,
+	"triage_item_id": true,
+	"method":         true,
+	"path":           true,
+	"route":          true,
+	"status":         true,
+	"port":           true,
+	"job":            true,
+	"count":          true,
+	"action":         true,
+	"budget":         true,
+	"elapsed":        true,
+	"scope":          true,
+}
+
+// hashedKeys identify a person: they are replaced by a short stable hash so
+// log lines about the same value can still be correlated.
+var hashedKeys = map[string]bool{
+	"email":    true,
+	"username": true,
+}
+
+// secretKeys are dropped entirely.
+var secretKeys = map[string]bool{
+	"password":      true,
+	"token":         true,
+	"access_token":  true,
+	"refresh_token": true,
+	"authorization": true,
+	"cookie":        true,
+	"secret":        true,
+}
+
+var ipKeys = map[string]bool{
+	"ip":        true,
+	"client_ip": true,
+	"remote_ip": true,
+}
+
+var (
+	emailPattern = regexp.MustCompile(`[A-Za-z0-9._%+\-]+@[A-Za-z0-9.\-]+\.[A-Za-z]{2,}`)
+	// JWTs and long opaque tokens
+	tokenPattern = regexp.MustCompile(`eyJ[A-Za-z0-9_\-]+\.[A-Za-z0-9_\-]+\.[A-Za-z0-9_\-]+|[A-Za-z0-9_\-]{40,}`)
+)
+
+const redacted = "[REDACTED]"
+
+// redactAttr is used as slog ReplaceAttr when redaction is on.
+func redactAttr(groups []string, a slog.Attr) slog.Attr {
+	key := strings.ToLower(a.Key)
+	if safeKeys[key] {
+		return a
+	}
+
+	switch {
+	case secretKeys[key]:
+		return slog.String(a.Key, redacted)
+	case hashedKeys[key]:
+		return slog.String(a.Key, hashValue(a.Value.String()))
+	case ipKeys[key]:
+		return slog.String(a.Key, maskIP(a.Value.String()))
+	}
+
+	if a.Value.Kind() == slog.KindString || a.Value.Kind() == slog.KindAny {
+		s := a.Value.String()
+		scrubbed := scrub(s)
+		if scrubbed != s {
+			return slog.String(a.Key, scrubbed)
+		}
+	}
+	return a
+}
+
+func scrub(s string) string {
+	s = emailPattern.ReplaceAllStringFunc(s, hashValue)
+	return tokenPattern.ReplaceAllString(s, redacted)
+}
+
+func hashValue(s string) string {
+	sum := sha256.Sum256([]byte(strings.ToLower(strings.TrimSpace(s))))
+	return "h:" + hex.EncodeToString(sum[:6])
+}
+
+// maskIP keeps the network part: /24 for IPv4, /48 for IPv6.
+func maskIP(s string) string {
+	addr, err := netip.ParseAddr(strings.TrimSpace(s))
+	if err != nil {
+		return redacted
+	}
+	addr = addr.Unmap()
+	bits := 48
+	if addr.Is4() {
+		bits = 24
+	}
+	p, _ := addr.Prefix(bits)
+	return p.String()
+}
@@ -0,0 +1,53 @@
+package middleware
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/diagnosis/interactive-todo/internal/apperror"
+	"github.com/diagnosis/interactive-todo/internal/helper"
+)
+
+// Version describes how one mounted API version is announced to clients.
+type Version struct {
+	// Name is the path segment, e.g. "v1".
+	Name string
+	// Deprecated is when the version was deprecated; zero while it is current.
+	Deprecated time.Time
+	// Sunset is when the version stops being served; zero if not scheduled.
+	Sunset time.Time
+	// Successor is the path prefix clients should move to, e.g. "/v2".
+	Successor string
+}
+
+// Announce sets API-Version on every response and, for a deprecated
+// version, the Deprecation (RFC 9745), Sunset (RFC 8594) and successor Link
+// headers. Past the sunset the version answers 410 Gone.
+func Announce(v Version, now func() time.Time) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			h := w.Header()
+			h.Set("API-Version", v.Name)
+			if !v.Deprecated.IsZero() {
+				h.Set("Deprecation", "@"+strconv.FormatInt(v.Deprecated.Unix(), 10))
+			}
+			if !v.Sunset.IsZero() {
+				h.Set("Sunset", v.Sunset.UTC().Format(http.TimeFormat))
+			}
+			if v.Successor != "" {
+				h.Add("Link", "<"+v.Successor+`>; rel="successor-version"`)
+			}
+
+			if !v.Sunset.IsZero() && !now().Before(v.Sunset) {
+				msg := "API " + v.Name + " was retired"
+				if v.Successor != "" {
+					msg += ", use " + v.Successor
+				}
+				helper.RespondError(w, r, apperror.Gone(msg))
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
@@ -5,11 +5,14 @@ import (
 	"errors"
 	"net/http"
 	"strings"
+	"time"
 
 	"github.com/diagnosis/interactive-todo/internal/apperror"
 	auth "github.com/diagnosis/interactive-todo/internal/auth/jwt"
+	"github.com/diagnosis/interactive-todo/internal/auth/signedurl"
 	"github.com/diagnosis/interactive-todo/internal/helper"
 	"github.com/diagnosis/interactive-todo/internal/logger"
+	denyliststore "github.com/diagnosis/interactive-todo/internal/store/tokendenylist"
 	"github.com/google/uuid"
 )
 
@@ -19,17 +22,28 @@ const claimsKey contextKey = "claims"
 
 type AuthMiddleware struct {
 	jwtManager auth.TokenManager
+	signer     *signedurl.Signer
+	denylist   denyliststore.DenylistStore
 }
 
-func NewAuthMiddleware(jm auth.TokenManager) *AuthMiddleware {
+func NewAuthMiddleware(jm auth.TokenManager, signer *signedurl.Signer, dl denyliststore.DenylistStore) *AuthMiddleware {
 	return &AuthMiddleware{
 		jwtManager: jm,
+		signer:     signer,
+		denylist:   dl,
 	}
 }
 
+// RequireAuth accepts a bearer access token or, for a GET without an
+// Authorization header, a signed URL. A signed URL authenticates as its
+// user with only the scope it was issued for.
 func (m *AuthMiddleware) RequireAuth(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		ctx := r.Context()
+		if r.Method == http.MethodGet && r.Header.Get("Authorization") == "" && r.URL.Query().Has(signedurl.ParamSig) {
+			m.serveSigned(w, r, next)
+			return
+		}
 		accessToken, err := ExtractAccessTokenFromBearer(r.Header.Get("Authorization"))
 		if err != nil {
 			logger.Error(ctx, "failed to extract token", "err", err)
@@ -43,13 +57,62 @@ func (m *AuthMiddleware) RequireAuth(next http.Handler) http.Handler {
 			helper.RespondError(w, r, apperror.Unauthorized("invalid or expired token"))
 			return
 		}
+
+		denied, err := m.denylist.IsDenied(ctx, TokenID(claims), claims.UserID, issuedAt(claims), claims.TokenVersion)
+		if err != nil {
+			logger.Error(ctx, "failed to check token denylist", "err", err)
+			helper.RespondError(w, r, apperror.InternalError("internal error", err))
+			return
+		}
+		if denied {
+			logger.Info(ctx, "revoked token used", "user_id", claims.UserID)
+			helper.RespondError(w, r, apperror.Unauthorized("invalid or expired token"))
+			return
+		}
+
 		ctx = ContextWithClaims(ctx, claims)
+		ctx = logger.WithUserID(ctx, claims.UserID.String())
 
 		next.ServeHTTP(w, r.WithContext(ctx))
 
 	})
 }
 
+func (m *AuthMiddleware) serveSigned(w http.ResponseWriter, r *http.Request, next http.Handler) {
+	ctx := r.Context()
+	grant, err := m.signer.Verify(r.URL.Path, r.URL.Query(), time.Now())
+	if err != nil {
+		logger.Info(ctx, "failed to verify signed url", "err", err)
+		helper.RespondError(w, r, apperror.Unauthorized("invalid or expired link"))
+		return
+	}
+	claims := &auth.Claims{
+		UserID: grant.UserID,
+		Scopes: []auth.Scope{grant.Scope},
+	}
+	ctx = ContextWithClaims(ctx, claims)
+	ctx = logger.WithUserID(ctx, claims.UserID.String())
+
+	next.ServeHTTP(w, r.WithContext(ctx))
+}
+
+// TokenID is the jti of an access token, or uuid.Nil for tokens minted
+// before jti was added; those can only be revoked per user.
+func TokenID(claims *auth.Claims) uuid.UUID {
+	id, err := uuid.Parse(claims.ID)
+	if err != nil {
+		return uuid.Nil
+	}
+	return id
+}
+
+func issuedAt(claims *auth.Claims) time.Time {
+	if claims.IssuedAt == nil {
+		return time.Time{}
+	}
+	return claims.IssuedAt.Time
+}
+
 func ExtractAccessTokenFromBearer(token string) (string, error) {
 	if token == "" {
 		return "", errors.New("no token")
@@ -0,0 +1,56 @@
+package middleware
+
+import (
+	"net/http"
+
+	"github.com/diagnosis/interactive-todo/internal/apperror"
+	auth "github.com/diagnosis/interactive-todo/internal/auth/jwt"
+	"github.com/diagnosis/interactive-todo/internal/helper"
+	"github.com/diagnosis/interactive-todo/internal/logger"
+)
+
+// RequireScope rejects requests whose access token lacks any of scopes.
+// It must run after RequireAuth.
+func RequireScope(scopes ...auth.Scope) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if checkScopes(w, r, scopes) {
+				next.ServeHTTP(w, r)
+			}
+		})
+	}
+}
+
+// RequireScopeByMethod requires read for GET, HEAD and OPTIONS and write for
+// everything else.
+func RequireScopeByMethod(read, write auth.Scope) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			need := write
+			switch r.Method {
+			case http.MethodGet, http.MethodHead, http.MethodOptions:
+				need = read
+			}
+			if checkScopes(w, r, []auth.Scope{need}) {
+				next.ServeHTTP(w, r)
+			}
+		})
+	}
+}
+
+func checkScopes(w http.ResponseWriter, r *http.Request, scopes []auth.Scope) bool {
+	ctx := r.Context()
+	claims, ok := GetClaimsFromContext(ctx)
+	if !ok {
+		helper.RespondError(w, r, apperror.Unauthorized("unauthorized"))
+		return false
+	}
+	for _, s := range scopes {
+		if !claims.HasScope(s) {
+			logger.Info(ctx, "scope check: missing scope", "scope", string(s), "path", r.URL.Path)
+			helper.RespondError(w, r, apperror.Forbidden("token is missing scope "+string(s)))
+			return false
+		}
+	}
+	return true
+}
@@ -18,7 +18,8 @@ func CorsHandler() func(handler http.Handler) http.Handler {
 	return cors.Handler(cors.Options{
 		AllowedOrigins:   allowedOrigins,
 		AllowedMethods:   []string{"GET", "POST", "PUT", "PATCH", "DELETE", "OPTIONS"},
-		AllowedHeaders:   []string{"Content-Type", "Authorization"},
+		AllowedHeaders:   []string{"Content-Type", "Authorization", "Accept-Version", "If-Match", "Last-Event-ID"},
+		ExposedHeaders:   []string{"API-Version", "Deprecation", "Sunset", "Link", "Demo-Mode", "Demo-Reset-At", "ETag"},
 		AllowCredentials: true,
 		MaxAge:           300,
 		Debug:            os.Getenv("APP_ENV") != "production",
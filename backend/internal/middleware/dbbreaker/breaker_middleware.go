@@ -0,0 +1,29 @@
+package middleware
+
+import (
+	"math"
+	"net/http"
+	"strconv"
+
+	"github.com/diagnosis/interactive-todo/internal/apperror"
+	"github.com/diagnosis/interactive-todo/internal/helper"
+	store "github.com/diagnosis/interactive-todo/internal/store/database"
+)
+
+// FailFast rejects requests with 503 while the database breaker is open,
+// instead of letting them queue on a pool that cannot reach Postgres.
+// Probes are let through so they can report the outage themselves.
+func FailFast(b *store.Breaker) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.URL.Path == "/health" || r.URL.Path == "/readyz" || b.Allow() {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			retryAfter := int(math.Ceil(b.RetryAfter().Seconds()))
+			w.Header().Set("Retry-After", strconv.Itoa(max(retryAfter, 1)))
+			helper.RespondError(w, r, apperror.ServiceUnavailable("database unavailable, try again shortly"))
+		})
+	}
+}
@@ -0,0 +1,24 @@
+package middleware
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/diagnosis/interactive-todo/internal/demo"
+)
+
+// Banner marks every response of a demo install, so the frontend can show
+// that data is fake and when it goes away: Demo-Mode is always true, and
+// Demo-Reset-At is the next reset (RFC 3339) once it is known.
+func Banner(sb *demo.Sandbox) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			h := w.Header()
+			h.Set("Demo-Mode", "true")
+			if at := sb.NextReset(); !at.IsZero() {
+				h.Set("Demo-Reset-At", at.Format(time.RFC3339))
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
@@ -0,0 +1,166 @@
+package middleware
+
+import (
+	"context"
+	"crypto/sha256"
+	"crypto/subtle"
+	"net/http"
+	"net/netip"
+	"sync"
+	"time"
+
+	"github.com/diagnosis/interactive-todo/internal/apperror"
+	"github.com/diagnosis/interactive-todo/internal/helper"
+	"github.com/diagnosis/interactive-todo/internal/logger"
+	auditstore "github.com/diagnosis/interactive-todo/internal/store/audit"
+	allowliststore "github.com/diagnosis/interactive-todo/internal/store/ipallowlist"
+)
+
+const (
+	BreakGlassHeader = "X-Break-Glass-Token"
+
+	// allowlist changes take effect within refreshInterval
+	refreshInterval = 30 * time.Second
+	// one blocked audit entry per IP per window, so a scanner cannot flood
+	// the audit log
+	blockAuditWindow = 10 * time.Minute
+)
+
+type IPAllowlist struct {
+	store      allowliststore.IPAllowlistStore
+	auditStore auditstore.AuditStore
+	// sha256 of the break-glass token; zero when none is configured
+	breakGlass [sha256.Size]byte
+	hasBreak   bool
+
+	mu       sync.Mutex
+	prefixes []netip.Prefix
+	loadedAt time.Time
+	loaded   bool
+	// reloading is set while one request reloads the list without the
+	// lock; the others keep using the last one meanwhile
+	reloading bool
+	lastAudit map[string]time.Time
+}
+
+// NewIPAllowlist enforces the org-wide allowlist. breakGlassToken may be
+// empty to disable the bypass.
+func NewIPAllowlist(s allowliststore.IPAllowlistStore, as auditstore.AuditStore, breakGlassToken string) *IPAllowlist {
+	m := &IPAllowlist{
+		store:      s,
+		auditStore: as,
+		lastAudit:  make(map[string]time.Time),
+	}
+	if breakGlassToken != "" {
+		m.breakGlass = sha256.Sum256([]byte(breakGlassToken))
+		m.hasBreak = true
+	}
+	return m
+}
+
+func (m *IPAllowlist) Enforce(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+
+		if r.URL.Path == "/health" {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		ip := helper.GetClientIP(r)
+		if allowliststore.Allows(m.current(ctx), ip) {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		if m.validBreakGlass(r.Header.Get(BreakGlassHeader)) {
+			logger.Info(ctx, "ip allowlist: break-glass bypass", "ip", ip, "path", r.URL.Path)
+			m.record(ctx, auditstore.ActionIPBreakGlass, ip, r)
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		logger.Info(ctx, "ip allowlist: blocked", "ip", ip, "path", r.URL.Path)
+		if m.shouldAuditBlock(ip, time.Now()) {
+			m.record(ctx, auditstore.ActionIPBlocked, ip, r)
+		}
+		helper.RespondError(w, r, apperror.Forbidden("access from this IP address is not allowed"))
+	})
+}
+
+// current returns the cached prefixes, reloading them when stale. The
+// query runs outside the lock and only one request runs it; the rest get
+// the last known list, so a slow database does not hold up every request.
+// If the store is unreachable the last known list is kept; before the
+// first successful load nothing is blocked, so a database hiccup at
+// startup does not lock every user out.
+func (m *IPAllowlist) current(ctx context.Context) []netip.Prefix {
+	m.mu.Lock()
+	prefixes := m.prefixes
+	if (m.loaded && time.Since(m.loadedAt) < refreshInterval) || m.reloading {
+		m.mu.Unlock()
+		return prefixes
+	}
+	m.reloading = true
+	m.mu.Unlock()
+
+	entries, err := m.store.List(ctx)
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.reloading = false
+	if err != nil {
+		logger.Error(ctx, "ip allowlist: reload failed", "err", err)
+		return prefixes
+	}
+	m.prefixes = allowliststore.Prefixes(entries)
+	m.loadedAt = time.Now()
+	m.loaded = true
+	return m.prefixes
+}
+
+func (m *IPAllowlist) validBreakGlass(token string) bool {
+	if !m.hasBreak || token == "" {
+		return false
+	}
+	sum := sha256.Sum256([]byte(token))
+	return subtle.ConstantTimeCompare(sum[:], m.breakGlass[:]) == 1
+}
+
+func (m *IPAllowlist) shouldAuditBlock(ip string, now time.Time) bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if last, ok := m.lastAudit[ip]; ok && now.Sub(last) < blockAuditWindow {
+		return false
+	}
+	// drop expired entries so the map stays bounded by recent offenders
+	for k, t := range m.lastAudit {
+		if now.Sub(t) >= blockAuditWindow {
+			delete(m.lastAudit, k)
+		}
+	}
+	m.lastAudit[ip] = now
+	return true
+}
+
+func (m *IPAllowlist) record(ctx context.Context, action auditstore.Action, ip string, r *http.Request) {
+	metadata := map[string]any{
+		"method": r.Method,
+		"path":   r.URL.Path,
+	}
+	// the audit column is INET; keep unparsable header values as metadata
+	if _, err := netip.ParseAddr(ip); err != nil {
+		metadata["raw_ip"] = ip
+		ip = ""
+	}
+	if err := m.auditStore.Record(ctx, auditstore.Entry{
+		Action:     action,
+		TargetType: auditstore.TargetIPAllowlist,
+		Metadata:   metadata,
+		IP:         ip,
+		CreatedAt:  time.Now().UTC(),
+	}); err != nil {
+		logger.Error(ctx, "ip allowlist: audit failed", "action", action, "err", err)
+	}
+}
@@ -0,0 +1,112 @@
+package middleware
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"net/netip"
+	"os"
+	"strings"
+)
+
+// defaultTrusted covers a proxy on the same host.
+const defaultTrusted = "127.0.0.0/8,::1/128"
+
+// Trusted lists the proxies whose forwarding headers are believed.
+type Trusted []netip.Prefix
+
+// TrustedFromEnv reads TRUSTED_PROXIES, comma-separated CIDRs or single
+// addresses, defaulting to loopback. "none" trusts no proxy, for an API
+// reached directly.
+func TrustedFromEnv() (Trusted, error) {
+	v := strings.TrimSpace(os.Getenv("TRUSTED_PROXIES"))
+	switch v {
+	case "":
+		v = defaultTrusted
+	case "none":
+		return Trusted{}, nil
+	}
+
+	var t Trusted
+	for _, s := range strings.Split(v, ",") {
+		s = strings.TrimSpace(s)
+		if s == "" {
+			continue
+		}
+		if p, err := netip.ParsePrefix(s); err == nil {
+			t = append(t, p.Masked())
+			continue
+		}
+		addr, err := netip.ParseAddr(s)
+		if err != nil {
+			return nil, fmt.Errorf("TRUSTED_PROXIES: %q is not a CIDR or an IP address", s)
+		}
+		t = append(t, netip.PrefixFrom(addr, addr.BitLen()))
+	}
+	return t, nil
+}
+
+func (t Trusted) contains(addr netip.Addr) bool {
+	addr = addr.Unmap()
+	for _, p := range t {
+		if p.Contains(addr) {
+			return true
+		}
+	}
+	return false
+}
+
+// ClientIP returns the address of whoever sent r. Forwarding headers are
+// only read when the connection comes from a trusted proxy, and
+// X-Forwarded-For is walked from the right, past trusted hops, so entries
+// a client prepends are never taken. Connections over a Unix socket come
+// from a local proxy and are trusted.
+func (t Trusted) ClientIP(r *http.Request) string {
+	host := r.RemoteAddr
+	if h, _, err := net.SplitHostPort(host); err == nil {
+		host = h
+	}
+	peer, err := netip.ParseAddr(host)
+	if err == nil && !t.contains(peer) {
+		return peer.Unmap().String()
+	}
+	// unix sockets have no address; what is left is the trusted peer
+	client := ""
+	if err == nil {
+		client = peer.Unmap().String()
+	}
+
+	if xff := r.Header.Values("X-Forwarded-For"); len(xff) > 0 {
+		hops := strings.Split(strings.Join(xff, ","), ",")
+		for i := len(hops) - 1; i >= 0; i-- {
+			hop, err := netip.ParseAddr(strings.TrimSpace(hops[i]))
+			if err != nil {
+				// past a malformed entry nothing can be believed
+				break
+			}
+			client = hop.Unmap().String()
+			if !t.contains(hop) {
+				break
+			}
+		}
+		return client
+	}
+	if v, err := netip.ParseAddr(strings.TrimSpace(r.Header.Get("X-Real-IP"))); err == nil {
+		return v.Unmap().String()
+	}
+	return client
+}
+
+// RealIP sets r.RemoteAddr to ClientIP, so everything after it sees the
+// client rather than the proxy. It replaces chi's RealIP, which believes
+// the headers of any client.
+func RealIP(t Trusted) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if ip := t.ClientIP(r); ip != "" {
+				r.RemoteAddr = ip
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
@@ -0,0 +1,170 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"net/http"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/diagnosis/interactive-todo/internal/apperror"
+	"github.com/diagnosis/interactive-todo/internal/helper"
+	"github.com/diagnosis/interactive-todo/internal/logger"
+	authmw "github.com/diagnosis/interactive-todo/internal/middleware/auth"
+	"github.com/google/uuid"
+)
+
+// Costs of requests in tokens. Reads are cheapest; endpoints that hold a
+// connection for long or scan a whole team cost more, so a user running
+// exports runs out long before interactive traffic is squeezed.
+const (
+	CostRead   = 1
+	CostWrite  = 2
+	CostSearch = 5
+	CostUpload = 20
+	// exports, imports and restores
+	CostBulk = 50
+)
+
+const pruneEvery = time.Minute
+
+// Config sizes the per-user token buckets.
+type Config struct {
+	// Rate is the tokens added per second
+	Rate float64
+	// Burst is the size of a bucket, which starts full
+	Burst float64
+}
+
+// ConfigFromEnv reads THROTTLE_RATE (tokens per minute, default 300) and
+// THROTTLE_BURST (default 100). A rate of 0 turns throttling off and
+// returns nil.
+func ConfigFromEnv() (*Config, error) {
+	perMin, err := strconv.ParseFloat(envOr("THROTTLE_RATE", "300"), 64)
+	if err != nil || !(perMin >= 0) || math.IsInf(perMin, 0) {
+		return nil, errors.New("THROTTLE_RATE must be a non-negative number of tokens per minute")
+	}
+	if perMin == 0 {
+		return nil, nil
+	}
+	burst, err := strconv.Atoi(envOr("THROTTLE_BURST", "100"))
+	if err != nil || burst < CostBulk {
+		return nil, fmt.Errorf("THROTTLE_BURST must be an integer of at least %d, the cost of the heaviest request", CostBulk)
+	}
+	return &Config{Rate: perMin / 60, Burst: float64(burst)}, nil
+}
+
+func envOr(k, def string) string {
+	if v := os.Getenv(k); v != "" {
+		return v
+	}
+	return def
+}
+
+type bucket struct {
+	tokens  float64
+	updated time.Time
+}
+
+// Limiter keeps a token bucket per user in memory. With several instances
+// each has its own buckets, so the effective limit is per instance.
+type Limiter struct {
+	cfg *Config
+
+	mu      sync.Mutex
+	buckets map[uuid.UUID]*bucket
+	pruned  time.Time
+}
+
+// NewLimiter returns a limiter for cfg; a nil cfg lets everything through.
+func NewLimiter(cfg *Config) *Limiter {
+	return &Limiter{cfg: cfg, buckets: make(map[uuid.UUID]*bucket)}
+}
+
+type chargedKey struct{}
+
+// Limit charges CostRead for reads and CostWrite for anything else. It
+// must run after RequireAuth; requests without a user are not throttled
+// here.
+func (l *Limiter) Limit(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		cost := CostWrite
+		switch r.Method {
+		case http.MethodGet, http.MethodHead, http.MethodOptions:
+			cost = CostRead
+		}
+		l.charge(cost, next, w, r)
+	})
+}
+
+// Cost raises the charge of a route to n. Whatever Limit already took is
+// counted, so only the rest is charged.
+func (l *Limiter) Cost(n int) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			l.charge(n, next, w, r)
+		})
+	}
+}
+
+func (l *Limiter) charge(cost int, next http.Handler, w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	userID, ok := authmw.GetUserIDFromContext(ctx)
+	if l.cfg == nil || !ok {
+		next.ServeHTTP(w, r)
+		return
+	}
+
+	charged, _ := ctx.Value(chargedKey{}).(int)
+	if cost <= charged {
+		next.ServeHTTP(w, r)
+		return
+	}
+	if wait, ok := l.take(userID, float64(cost-charged), time.Now()); !ok {
+		logger.Info(ctx, "request throttled", "user_id", userID, "cost", cost, "retry_after", wait)
+		w.Header().Set("Retry-After", strconv.Itoa(max(int(math.Ceil(wait.Seconds())), 1)))
+		helper.RespondError(w, r, apperror.TooManyRequests("too many requests, try again later"))
+		return
+	}
+	next.ServeHTTP(w, r.WithContext(context.WithValue(ctx, chargedKey{}, cost)))
+}
+
+// take removes n tokens from the user's bucket. When there are not enough
+// it takes none and returns how long until there will be.
+func (l *Limiter) take(userID uuid.UUID, n float64, now time.Time) (time.Duration, bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if now.Sub(l.pruned) >= pruneEvery {
+		l.prune(now)
+	}
+
+	b, ok := l.buckets[userID]
+	if !ok {
+		b = &bucket{tokens: l.cfg.Burst, updated: now}
+		l.buckets[userID] = b
+	}
+	b.tokens = min(l.cfg.Burst, b.tokens+now.Sub(b.updated).Seconds()*l.cfg.Rate)
+	b.updated = now
+
+	if b.tokens < n {
+		return time.Duration((n - b.tokens) / l.cfg.Rate * float64(time.Second)), false
+	}
+	b.tokens -= n
+	return 0, true
+}
+
+// prune drops buckets that have filled up again; they are the same as no
+// bucket. Callers hold mu.
+func (l *Limiter) prune(now time.Time) {
+	for id, b := range l.buckets {
+		if b.tokens+now.Sub(b.updated).Seconds()*l.cfg.Rate >= l.cfg.Burst {
+			delete(l.buckets, id)
+		}
+	}
+	l.pruned = now
+}
@@ -0,0 +1,94 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"time"
+
+	authmw "github.com/diagnosis/interactive-todo/internal/middleware/auth"
+	usagestore "github.com/diagnosis/interactive-todo/internal/store/usage"
+	"github.com/google/uuid"
+)
+
+type key struct {
+	userID uuid.UUID
+	day    time.Time
+}
+
+// Tracker counts authenticated requests per user and day in memory. Flush
+// writes the counts out, so a request costs no database write; counts not
+// yet flushed are lost if the process dies.
+type Tracker struct {
+	store usagestore.UsageStore
+
+	mu      sync.Mutex
+	pending map[key]*usagestore.Count
+}
+
+func NewTracker(s usagestore.UsageStore) *Tracker {
+	return &Tracker{store: s, pending: make(map[key]*usagestore.Count)}
+}
+
+// Track counts the request for the authenticated user, so it must run
+// after RequireAuth.
+func (t *Tracker) Track(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if userID, ok := authmw.GetUserIDFromContext(r.Context()); ok {
+			t.record(userID, time.Now().UTC())
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+func (t *Tracker) record(userID uuid.UUID, now time.Time) {
+	k := key{userID: userID, day: now.Truncate(24 * time.Hour)}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	c, ok := t.pending[k]
+	if !ok {
+		c = &usagestore.Count{UserID: userID, Day: k.day}
+		t.pending[k] = c
+	}
+	c.Requests++
+	c.LastSeenAt = now
+}
+
+// Flush writes the pending counts. On failure they are kept for the next
+// flush.
+func (t *Tracker) Flush(ctx context.Context) (int, error) {
+	t.mu.Lock()
+	pending := t.pending
+	t.pending = make(map[key]*usagestore.Count)
+	t.mu.Unlock()
+
+	if len(pending) == 0 {
+		return 0, nil
+	}
+	counts := make([]usagestore.Count, 0, len(pending))
+	for _, c := range pending {
+		counts = append(counts, *c)
+	}
+	if err := t.store.Add(ctx, counts); err != nil {
+		t.restore(pending)
+		return 0, err
+	}
+	return len(counts), nil
+}
+
+func (t *Tracker) restore(counts map[key]*usagestore.Count) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	for k, old := range counts {
+		c, ok := t.pending[k]
+		if !ok {
+			t.pending[k] = old
+			continue
+		}
+		c.Requests += old.Requests
+		if old.LastSeenAt.After(c.LastSeenAt) {
+			c.LastSeenAt = old.LastSeenAt
+		}
+	}
+}
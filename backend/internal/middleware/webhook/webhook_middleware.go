@@ -0,0 +1,68 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/diagnosis/interactive-todo/api/types"
+	"github.com/diagnosis/interactive-todo/internal/apperror"
+	"github.com/diagnosis/interactive-todo/internal/auth/webhooksig"
+	"github.com/diagnosis/interactive-todo/internal/helper"
+	"github.com/diagnosis/interactive-todo/internal/logger"
+	chimiddleware "github.com/go-chi/chi/v5/middleware"
+)
+
+type contextKey string
+
+const deliveryKey contextKey = "webhook_delivery"
+
+// RequireSignature lets only verified, first-seen deliveries through to
+// the integration handler, which reads the delivery with DeliveryFrom.
+//
+// A replayed delivery is answered 200 without reaching the handler, so a
+// provider retrying one that was already handled stops retrying. When the
+// handler answers 5xx the delivery is released, so the retry is handled.
+func RequireSignature(v *webhooksig.Verifier) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			ctx := r.Context()
+			d, err := v.Verify(r, time.Now())
+			switch {
+			case err == nil:
+			case errors.Is(err, webhooksig.ErrReplayed):
+				logger.Info(ctx, "webhook: duplicate delivery", "provider", v.Provider())
+				helper.RespondJSON(w, r, http.StatusOK, types.WebhookDuplicate{Duplicate: true})
+				return
+			case errors.Is(err, webhooksig.ErrTooLarge):
+				helper.RespondError(w, r, apperror.New(apperror.CodeBadRequest, "webhook body too large", http.StatusRequestEntityTooLarge))
+				return
+			case errors.Is(err, webhooksig.ErrMissing), errors.Is(err, webhooksig.ErrMalformed),
+				errors.Is(err, webhooksig.ErrBadSig), errors.Is(err, webhooksig.ErrStale):
+				logger.Warn(ctx, "webhook: rejected", "provider", v.Provider(), "err", err)
+				helper.RespondError(w, r, apperror.Unauthorized("invalid webhook signature"))
+				return
+			default:
+				logger.Error(ctx, "webhook: verify failed", "provider", v.Provider(), "err", err)
+				helper.RespondError(w, r, apperror.InternalError("internal error", err))
+				return
+			}
+
+			ww := chimiddleware.NewWrapResponseWriter(w, r.ProtoMajor)
+			next.ServeHTTP(ww, r.WithContext(context.WithValue(ctx, deliveryKey, d)))
+
+			if ww.Status() >= http.StatusInternalServerError {
+				if err := v.Release(context.WithoutCancel(ctx), d); err != nil {
+					logger.Error(ctx, "webhook: release failed", "provider", v.Provider(), "err", err)
+				}
+			}
+		})
+	}
+}
+
+// DeliveryFrom returns the delivery RequireSignature verified.
+func DeliveryFrom(ctx context.Context) (*webhooksig.Delivery, bool) {
+	d, ok := ctx.Value(deliveryKey).(*webhooksig.Delivery)
+	return d, ok
+}
@@ -0,0 +1,54 @@
+package notify
+
+import (
+	"context"
+
+	"github.com/diagnosis/interactive-todo/internal/logger"
+	"github.com/google/uuid"
+)
+
+type Kind string
+
+const (
+	KindStaleTask      Kind = "stale_task"
+	KindUnacknowledged Kind = "unacknowledged_task"
+	KindAutomation     Kind = "automation"
+	KindStatusReport   Kind = "status_report"
+	KindDueReminder    Kind = "due_reminder"
+)
+
+// Notification is a message addressed to a single user
+type Notification struct {
+	UserID  uuid.UUID
+	Kind    Kind
+	Subject string
+	Body    string
+	TaskID  *uuid.UUID
+	TeamID  *uuid.UUID
+}
+
+// Notifier delivers notifications (email, chat, push...)
+type Notifier interface {
+	Notify(ctx context.Context, n Notification) error
+}
+
+// LogNotifier writes notifications to the application log. It is the
+// default until a real delivery channel is configured.
+type LogNotifier struct{}
+
+func NewLogNotifier() *LogNotifier {
+	return &LogNotifier{}
+}
+
+func (LogNotifier) Notify(ctx context.Context, n Notification) error {
+	logger.Info(ctx, "notification",
+		"user_id", n.UserID,
+		"kind", n.Kind,
+		"subject", n.Subject,
+		"task_id", n.TaskID,
+		"team_id", n.TeamID,
+	)
+	return nil
+}
+
+var _ Notifier = (*LogNotifier)(nil)
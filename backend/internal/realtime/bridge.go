@@ -0,0 +1,117 @@
+package realtime
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/diagnosis/interactive-todo/api/types"
+	"github.com/diagnosis/interactive-todo/internal/events"
+	"github.com/diagnosis/interactive-todo/internal/logger"
+	taskstore "github.com/diagnosis/interactive-todo/internal/store/tasks"
+	teameventstore "github.com/diagnosis/interactive-todo/internal/store/teamevents"
+	"github.com/google/uuid"
+)
+
+// TaskLookup is what the bridge needs to keep private tasks private.
+type TaskLookup interface {
+	GetTaskByID(ctx context.Context, id uuid.UUID) (*taskstore.Task, error)
+	ListViewers(ctx context.Context, taskID uuid.UUID) ([]uuid.UUID, error)
+}
+
+// Bridge forwards task events from the bus to the hub, numbering and
+// storing each one first so reconnecting streams can replay it.
+type Bridge struct {
+	hub     *Hub
+	tasks   TaskLookup
+	history teameventstore.TeamEventStore
+	typing  typingLimiter
+}
+
+func NewBridge(hub *Hub, tasks TaskLookup, history teameventstore.TeamEventStore) *Bridge {
+	return &Bridge{hub: hub, tasks: tasks, history: history}
+}
+
+// Register subscribes to the events a team's members see change, and to
+// in_app reminders. Due-soon events are per automation recipe and stay
+// internal.
+func (b *Bridge) Register(bus *events.Bus) {
+	for _, t := range []events.Type{events.TaskCreated, events.TaskAssigned, events.TaskStatusChanged, events.TaskLabelAdded, events.TaskReminder} {
+		bus.Subscribe(t, b.Handle)
+	}
+}
+
+func (b *Bridge) Handle(ctx context.Context, e events.Event) {
+	audience, err := b.audience(ctx, e)
+	if errors.Is(err, taskstore.ErrTaskNotFound) {
+		// deleted since; nothing left to show
+		return
+	}
+	if err != nil {
+		logger.Error(ctx, "realtime: audience lookup failed", "task_id", e.TaskID, "err", err)
+		return
+	}
+	data, err := json.Marshal(types.RealtimeEvent{
+		Type:    string(e.Type),
+		TeamID:  e.TeamID,
+		TaskID:  e.TaskID,
+		ActorID: e.ActorID,
+		Status:  e.Status,
+		From:    e.From,
+		Label:   e.Label,
+		DueAt:   e.DueAt,
+		At:      e.At,
+	})
+	if err != nil {
+		logger.Error(ctx, "realtime: encode event failed", "err", err)
+		return
+	}
+	seq, err := b.history.Append(ctx, e.TeamID, string(e.Type), audience, data, e.At)
+	if err != nil {
+		// still sent live; only a reconnect would miss it
+		logger.Error(ctx, "realtime: store event failed", "team_id", e.TeamID, "err", err)
+	}
+	b.hub.Publish(e.TeamID, Message{Seq: seq, Type: string(e.Type), Data: data, Audience: audience})
+}
+
+// publishLive sends e about task to the streams of its team that may see
+// the task, without numbering or storing it: a reconnecting stream does
+// not get it back. TeamID and TaskID are filled in from task.
+func (b *Bridge) publishLive(ctx context.Context, task *taskstore.Task, e types.RealtimeEvent) error {
+	audience, err := b.audienceOf(ctx, task)
+	if err != nil {
+		return fmt.Errorf("%s task_id=%s: %w", e.Type, task.ID, err)
+	}
+	e.TeamID, e.TaskID = task.TeamID, task.ID
+	data, err := json.Marshal(e)
+	if err != nil {
+		return fmt.Errorf("%s task_id=%s: encode: %w", e.Type, task.ID, err)
+	}
+	b.hub.Publish(task.TeamID, Message{Type: e.Type, Data: data, Audience: audience})
+	return nil
+}
+
+// audience is nil for a task everyone in the team may see, otherwise the
+// users visibleTo lets through. A reminder is for its user alone.
+func (b *Bridge) audience(ctx context.Context, e events.Event) ([]uuid.UUID, error) {
+	if e.Type == events.TaskReminder && e.UserID != nil {
+		return []uuid.UUID{*e.UserID}, nil
+	}
+	t, err := b.tasks.GetTaskByID(ctx, e.TaskID)
+	if err != nil {
+		return nil, err
+	}
+	return b.audienceOf(ctx, t)
+}
+
+func (b *Bridge) audienceOf(ctx context.Context, t *taskstore.Task) ([]uuid.UUID, error) {
+	if !t.Private {
+		return nil, nil
+	}
+	viewers, err := b.tasks.ListViewers(ctx, t.ID)
+	if err != nil {
+		return nil, err
+	}
+	return append(viewers, t.ReporterID, t.AssigneeID), nil
+}
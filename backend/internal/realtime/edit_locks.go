@@ -0,0 +1,38 @@
+package realtime
+
+import (
+	"context"
+	"time"
+
+	"github.com/diagnosis/interactive-todo/api/types"
+	taskstore "github.com/diagnosis/interactive-todo/internal/store/tasks"
+)
+
+const (
+	// EditLockedEvent is sent when someone takes or renews the lock on a
+	// task's description; Until is when it lapses unless renewed again.
+	EditLockedEvent = "task.edit_locked"
+	// EditUnlockedEvent is sent when the holder releases it.
+	EditUnlockedEvent = "task.edit_unlocked"
+)
+
+// EditLocked tells the task's viewers that lock.UserID is editing its
+// description. Like typing indicators, lock events are live only.
+func (b *Bridge) EditLocked(ctx context.Context, task *taskstore.Task, lock *types.EditLock, now time.Time) error {
+	until := lock.ExpiresAt
+	return b.publishLive(ctx, task, types.RealtimeEvent{
+		Type:    EditLockedEvent,
+		ActorID: &lock.UserID,
+		Until:   &until,
+		At:      now,
+	})
+}
+
+// EditUnlocked tells the task's viewers the description is free again.
+func (b *Bridge) EditUnlocked(ctx context.Context, task *taskstore.Task, lock *types.EditLock, now time.Time) error {
+	return b.publishLive(ctx, task, types.RealtimeEvent{
+		Type:    EditUnlockedEvent,
+		ActorID: &lock.UserID,
+		At:      now,
+	})
+}
@@ -0,0 +1,268 @@
+// Package realtime streams team events to connected clients as
+// server-sent events.
+package realtime
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/diagnosis/interactive-todo/api/types"
+	"github.com/google/uuid"
+)
+
+const (
+	// StreamLifetime ends each stream before the router's request timeout;
+	// clients reconnect after RetryAfter.
+	StreamLifetime = 50 * time.Second
+	// RetryAfter is the reconnect delay sent to clients.
+	RetryAfter = 2 * time.Second
+	// heartbeat keeps idle streams open through proxies.
+	heartbeat = 20 * time.Second
+	// subscriberBuffer is how far a client may fall behind before its
+	// stream is closed; it reconnects and catches up.
+	subscriberBuffer = 64
+)
+
+// ErrDraining: the server is shutting down and takes no new streams.
+var ErrDraining = errors.New("realtime: server is shutting down")
+
+// Message is one event for a team's streams. Seq is its number in the
+// team's history, sent as the SSE id; 0 when it could not be stored, and
+// then it is not replayable.
+type Message struct {
+	Seq  int64
+	Type string
+	Data []byte
+	// Audience limits a message about a private task to these users; nil
+	// means every member streaming the team
+	Audience []uuid.UUID
+}
+
+func (m Message) reaches(userID uuid.UUID) bool {
+	if m.Audience == nil {
+		return true
+	}
+	for _, id := range m.Audience {
+		if id == userID {
+			return true
+		}
+	}
+	return false
+}
+
+type subscriber struct {
+	userID uuid.UUID
+	ch     chan Message
+	// lagged is closed when ch overflowed
+	lagged chan struct{}
+	once   sync.Once
+}
+
+// Hub fans messages out to the open streams of each team.
+type Hub struct {
+	mu       sync.Mutex
+	teams    map[uuid.UUID]map[*subscriber]struct{}
+	draining bool
+	// closing is closed by Drain; every stream then says goodbye
+	closing chan struct{}
+	streams sync.WaitGroup
+}
+
+// CatchUp loads what a reconnecting stream missed. complete is false when
+// the history no longer reaches back that far.
+type CatchUp func(ctx context.Context) (missed []Message, complete bool, err error)
+
+func NewHub() *Hub {
+	return &Hub{
+		teams:   map[uuid.UUID]map[*subscriber]struct{}{},
+		closing: make(chan struct{}),
+	}
+}
+
+// Publish sends m to every stream of the team m may reach. It never
+// blocks: a stream too far behind is closed instead.
+func (h *Hub) Publish(teamID uuid.UUID, m Message) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	for s := range h.teams[teamID] {
+		if !m.reaches(s.userID) {
+			continue
+		}
+		select {
+		case s.ch <- m:
+		default:
+			s.once.Do(func() { close(s.lagged) })
+		}
+	}
+}
+
+func (h *Hub) subscribe(teamID, userID uuid.UUID) (*subscriber, error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.draining {
+		return nil, ErrDraining
+	}
+	s := &subscriber{userID: userID, ch: make(chan Message, subscriberBuffer), lagged: make(chan struct{})}
+	if h.teams[teamID] == nil {
+		h.teams[teamID] = map[*subscriber]struct{}{}
+	}
+	h.teams[teamID][s] = struct{}{}
+	h.streams.Add(1)
+	return s, nil
+}
+
+func (h *Hub) unsubscribe(teamID uuid.UUID, s *subscriber) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	delete(h.teams[teamID], s)
+	if len(h.teams[teamID]) == 0 {
+		delete(h.teams, teamID)
+	}
+	h.streams.Done()
+}
+
+// Serve streams the team's events to userID until the client goes away,
+// StreamLifetime passes or the hub drains. The caller checks membership.
+// With catchUp, the missed events are sent first, or a reset event when
+// they are gone and the client must refetch. Errors are returned before
+// anything is written: ErrDraining once Drain has begun, or catchUp's.
+func (h *Hub) Serve(w http.ResponseWriter, r *http.Request, teamID, userID uuid.UUID, catchUp CatchUp) error {
+	s, err := h.subscribe(teamID, userID)
+	if err != nil {
+		return err
+	}
+	defer h.unsubscribe(teamID, s)
+
+	// loaded after subscribing so nothing falls between history and live;
+	// live events already replayed are skipped below
+	var (
+		missed   []Message
+		complete = true
+	)
+	if catchUp != nil {
+		if missed, complete, err = catchUp(r.Context()); err != nil {
+			return err
+		}
+	}
+
+	// the server's WriteTimeout is meant for ordinary responses
+	rc := http.NewResponseController(w)
+	_ = rc.SetWriteDeadline(time.Now().Add(StreamLifetime + 10*time.Second))
+
+	hdr := w.Header()
+	hdr.Set("Content-Type", "text/event-stream")
+	hdr.Set("Cache-Control", "no-cache")
+	hdr.Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+	if _, err := fmt.Fprintf(w, "retry: %d\n\n", RetryAfter.Milliseconds()); err != nil {
+		return nil
+	}
+	var lastSeq int64
+	if !complete {
+		if err := writeMessage(w, Message{Type: "reset", Data: []byte("{}")}); err != nil {
+			return nil
+		}
+	}
+	for _, m := range missed {
+		if err := writeMessage(w, m); err != nil {
+			return nil
+		}
+		lastSeq = m.Seq
+	}
+	if err := rc.Flush(); err != nil {
+		return err
+	}
+
+	lifetime := time.NewTimer(StreamLifetime)
+	defer lifetime.Stop()
+	ping := time.NewTicker(heartbeat)
+	defer ping.Stop()
+
+	for {
+		select {
+		case m := <-s.ch:
+			if m.Seq != 0 && m.Seq <= lastSeq {
+				continue
+			}
+			if err := writeMessage(w, m); err != nil {
+				return nil
+			}
+			lastSeq = max(lastSeq, m.Seq)
+		case <-ping.C:
+			if _, err := w.Write([]byte(": ping\n\n")); err != nil {
+				return nil
+			}
+		case <-s.lagged:
+			return nil
+		case <-lifetime.C:
+			return nil
+		case <-h.closing:
+			// what is already queued goes out before the goodbye
+		queued:
+			for {
+				select {
+				case m := <-s.ch:
+					if m.Seq != 0 && m.Seq <= lastSeq {
+						continue
+					}
+					if err := writeMessage(w, m); err != nil {
+						return nil
+					}
+					lastSeq = max(lastSeq, m.Seq)
+				default:
+					break queued
+				}
+			}
+			data, _ := json.Marshal(types.RealtimeShutdown{LastEventID: strconv.FormatInt(lastSeq, 10), RetryMS: int(RetryAfter.Milliseconds())})
+			_ = writeMessage(w, Message{Seq: lastSeq, Type: "shutdown", Data: data})
+			_ = rc.Flush()
+			return nil
+		case <-r.Context().Done():
+			return nil
+		}
+		if err := rc.Flush(); err != nil {
+			return nil
+		}
+	}
+}
+
+// Drain stops taking new streams, tells every open one the server is going
+// away, with the id of the last event it got so the client can resume
+// elsewhere, and waits for them to end or for ctx to expire.
+func (h *Hub) Drain(ctx context.Context) error {
+	h.mu.Lock()
+	if !h.draining {
+		h.draining = true
+		close(h.closing)
+	}
+	h.mu.Unlock()
+
+	done := make(chan struct{})
+	go func() {
+		h.streams.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return fmt.Errorf("realtime: streams still open: %w", ctx.Err())
+	}
+}
+
+func writeMessage(w http.ResponseWriter, m Message) error {
+	var err error
+	if m.Seq != 0 {
+		_, err = fmt.Fprintf(w, "id: %d\n", m.Seq)
+	}
+	if err == nil {
+		_, err = fmt.Fprintf(w, "event: %s\ndata: %s\n\n", m.Type, m.Data)
+	}
+	return err
+}
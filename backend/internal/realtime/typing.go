@@ -0,0 +1,74 @@
+package realtime
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/diagnosis/interactive-todo/api/types"
+	taskstore "github.com/diagnosis/interactive-todo/internal/store/tasks"
+	"github.com/google/uuid"
+)
+
+const (
+	// TypingEvent is the SSE event name of typing indicators.
+	TypingEvent = "comment.typing"
+	// TypingInterval is the least time between two indicators of one user
+	// on one task; calls in between are dropped.
+	TypingInterval = 2 * time.Second
+	// TypingTTL is how long clients show an indicator that is not renewed.
+	TypingTTL = 6 * time.Second
+	// typingSweep is the size past which stale limiter entries are dropped.
+	typingSweep = 1024
+)
+
+type typingKey struct {
+	taskID uuid.UUID
+	userID uuid.UUID
+}
+
+// typingLimiter remembers when each user last typed on each task.
+type typingLimiter struct {
+	mu   sync.Mutex
+	last map[typingKey]time.Time
+}
+
+func (l *typingLimiter) allow(k typingKey, now time.Time) bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.last == nil {
+		l.last = map[typingKey]time.Time{}
+	}
+	if t, ok := l.last[k]; ok && now.Sub(t) < TypingInterval {
+		return false
+	}
+	if len(l.last) >= typingSweep {
+		for key, t := range l.last {
+			if now.Sub(t) >= TypingInterval {
+				delete(l.last, key)
+			}
+		}
+	}
+	l.last[k] = now
+	return true
+}
+
+// Typing tells the streams of the task's team that userID is writing a
+// comment on it. Indicators go out live only: they are not numbered,
+// stored or replayed. sent is false when the call came within
+// TypingInterval of the previous one and was dropped.
+func (b *Bridge) Typing(ctx context.Context, task *taskstore.Task, userID uuid.UUID, now time.Time) (sent bool, err error) {
+	if !b.typing.allow(typingKey{taskID: task.ID, userID: userID}, now) {
+		return false, nil
+	}
+	until := now.Add(TypingTTL)
+	if err := b.publishLive(ctx, task, types.RealtimeEvent{
+		Type:    TypingEvent,
+		ActorID: &userID,
+		Until:   &until,
+		At:      now,
+	}); err != nil {
+		return false, err
+	}
+	return true, nil
+}
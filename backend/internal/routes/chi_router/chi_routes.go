@@ -1,12 +1,24 @@
 package routes
 
 import (
+	"context"
 	"net/http"
 	"time"
 
+	"github.com/diagnosis/interactive-todo/api/types"
 	"github.com/diagnosis/interactive-todo/internal/app"
+	jwttoken "github.com/diagnosis/interactive-todo/internal/auth/jwt"
+	"github.com/diagnosis/interactive-todo/internal/helper"
+	"github.com/diagnosis/interactive-todo/internal/logger"
+	authmiddleware "github.com/diagnosis/interactive-todo/internal/middleware/auth"
 	corsmiddleware "github.com/diagnosis/interactive-todo/internal/middleware/cors"
+	dbbreaker "github.com/diagnosis/interactive-todo/internal/middleware/dbbreaker"
+	demomiddleware "github.com/diagnosis/interactive-todo/internal/middleware/demo"
 	middleware "github.com/diagnosis/interactive-todo/internal/middleware/logger"
+	realipmiddleware "github.com/diagnosis/interactive-todo/internal/middleware/realip"
+	throttlemiddleware "github.com/diagnosis/interactive-todo/internal/middleware/throttle"
+	store "github.com/diagnosis/interactive-todo/internal/store/database"
+	"github.com/diagnosis/interactive-todo/internal/webui"
 	"github.com/go-chi/chi/v5"
 	chimiddleware "github.com/go-chi/chi/v5/middleware"
 )
@@ -15,19 +27,63 @@ func SetupRouter(application *app.Application) *chi.Mux {
 	r := chi.NewRouter()
 
 	// ===== Global middleware =====
+	// checked at startup, like the rest of the configuration
+	trusted, err := realipmiddleware.TrustedFromEnv()
+	if err != nil {
+		panic(err)
+	}
 	r.Use(chimiddleware.RequestID)
-	r.Use(chimiddleware.RealIP)
-	r.Use(chimiddleware.Logger)
+	r.Use(realipmiddleware.RealIP(trusted))
+	r.Use(middleware.AccessLog)
 	r.Use(chimiddleware.Recoverer)
 	r.Use(chimiddleware.Timeout(60 * time.Second))
 	r.Use(corsmiddleware.CorsHandler())
+	if application.Demo != nil {
+		r.Use(demomiddleware.Banner(application.Demo))
+	}
+	// the frontend, when built in, carries no data: it is served even while
+	// the database is down or the client IP is not allowed
+	if fsys := webui.Embedded(); fsys != nil {
+		ui, err := webui.New(fsys, append(versionPrefixes(), "/health", "/readyz")...)
+		if err != nil {
+			panic(err)
+		}
+		r.Use(ui.Serve)
+	}
+	r.Use(dbbreaker.FailFast(application.DBBreaker))
+	r.Use(application.IPAllowlist.Enforce)
 
 	// ===== Health check =====
 	r.Get("/health", func(w http.ResponseWriter, r *http.Request) {
 		w.WriteHeader(http.StatusOK)
 		_, _ = w.Write([]byte("ok"))
 	})
+	// readiness: 503 while the database breaker is open or a ping fails
+	r.Get("/readyz", func(w http.ResponseWriter, r *http.Request) {
+		ctx, cancel := context.WithTimeout(r.Context(), 2*time.Second)
+		defer cancel()
 
+		stats := application.DBBreaker.Stats()
+		status := http.StatusOK
+		if stats.State == store.BreakerOpen {
+			status = http.StatusServiceUnavailable
+		} else if err := application.Pool.Ping(ctx); err != nil {
+			status = http.StatusServiceUnavailable
+			logger.Warn(ctx, "readyz: ping failed", "err", err)
+		}
+		helper.RespondJSON(w, r, status, types.Readiness{
+			Ready:     status == http.StatusOK,
+			DBBreaker: stats,
+		})
+	})
+
+	mountVersions(r, application)
+
+	return r
+}
+
+// registerV1 mounts the /v1 API.
+func registerV1(r chi.Router, application *app.Application) {
 	// ===== Auth routes (public + protected) =====
 	r.Route("/auth", func(ar chi.Router) {
 		// Public
@@ -39,42 +95,88 @@ func SetupRouter(application *app.Application) *chi.Mux {
 		// Protected
 		ar.Group(func(par chi.Router) {
 			par.Use(application.AuthMiddleware.RequireAuth)
-			par.Patch("/{user_id}/update-usertype", application.AuthHandler.HandleUpdateUserType)
+			par.Use(application.Usage.Track)
+			par.Use(application.Throttle.Limit)
+			par.With(authmiddleware.RequireScope(jwttoken.ScopeAdmin)).
+				Patch("/{user_id}/update-usertype", application.AuthHandler.HandleUpdateUserType)
 			par.Post("/logout-all", application.AuthHandler.LogoutFromAllDevices)
+
+			// the caller's own profile, including their timezone
+			par.Get("/me", application.AuthHandler.GetProfile)
+			par.Patch("/me", application.AuthHandler.UpdateProfile)
 		})
 	})
 
+	// ===== First-run setup (public, only until the first user exists) =====
+	r.Get("/setup", application.AuthHandler.SetupStatus)
+	r.Post("/setup", application.AuthHandler.Setup)
+
 	// ===== Users (protected) =====
 	r.Route("/users", func(ur chi.Router) {
 		ur.Use(application.AuthMiddleware.RequireAuth)
+		ur.Use(application.Usage.Track)
+		ur.Use(application.Throttle.Limit)
+		ur.Use(authmiddleware.RequireScope(jwttoken.ScopeUsersRead))
 		ur.Get("/", application.AuthHandler.ListUsers)
 	})
 
 	// ===== Teams (protected) =====
 	r.Route("/teams", func(tr chi.Router) {
 		tr.Use(application.AuthMiddleware.RequireAuth)
+		tr.Use(application.Usage.Track)
+		tr.Use(application.Throttle.Limit)
 		tr.Use(middleware.LogUserInfo)
+		teamScope := authmiddleware.RequireScopeByMethod(jwttoken.ScopeTeamsRead, jwttoken.ScopeTeamsWrite)
+		taskScope := authmiddleware.RequireScopeByMethod(jwttoken.ScopeTasksRead, jwttoken.ScopeTasksWrite)
+
 		// Create team, list teams current user belongs to
-		tr.Post("/", application.TeamHandler.CreateTeam)
-		tr.Get("/mine", application.TeamHandler.ListTeamsForUser)
+		tr.With(teamScope).Post("/", application.TeamHandler.CreateTeam)
+		tr.With(teamScope).Get("/mine", application.TeamHandler.ListTeamsForUser)
 
 		// Team-scoped actions
 		tr.Route("/{team_id}", func(tr chi.Router) {
-			// Team members management
-			tr.Get("/members", application.TeamHandler.ListMembers)
-			tr.Post("/members", application.TeamHandler.HandleAddMember)
-			tr.Delete("/members/{user_id}", application.TeamHandler.RemoveMember)
-
-			// Team-scoped task views
-			tr.Get("/tasks", application.TaskHandler.ListTeamTasks)
-			tr.Get("/tasks/assignee", application.TaskHandler.ListAssigneeTasksInTeam)
-			tr.Get("/tasks/reporter", application.TaskHandler.ListReporterTasksInTeam)
+			tr.Group(func(tr chi.Router) {
+				tr.Use(teamScope)
+				registerTeamRoutes(tr, application)
+			})
+			tr.Group(func(tr chi.Router) {
+				tr.Use(taskScope)
+				registerTeamTaskRoutes(tr, application)
+			})
 		})
 	})
 
+	// ===== Public intake forms (no auth, throttled per IP) =====
+	r.Route("/forms/{form_id}", func(fr chi.Router) {
+		fr.Get("/", application.TaskHandler.GetPublicForm)
+		fr.Post("/submit", application.TaskHandler.SubmitPublicForm)
+	})
+
+	// ===== Anonymous feedback (no auth, throttled per IP and per team) =====
+	r.Route("/feedback/{token}", func(fr chi.Router) {
+		// the access log must not tie a submission to an IP
+		fr.Use(middleware.Anonymous)
+		fr.Get("/", application.TaskHandler.GetPublicFeedback)
+		fr.Post("/", application.TaskHandler.SubmitFeedback)
+	})
+
+	// ===== Status report unsubscribe links (no auth) =====
+	r.Route("/status-reports/unsubscribe/{token}", func(sr chi.Router) {
+		sr.Get("/", application.TaskHandler.GetStatusReportUnsubscribe)
+		sr.Post("/", application.TaskHandler.UnsubscribeStatusReportByToken)
+	})
+
+	// ===== Calendar feed (authenticated by its feed token) =====
+	// more specific than the /tasks mount, so chi routes it here and the
+	// auth middleware below never sees it
+	r.Get("/tasks/assignee/calendar.ics", application.TaskHandler.ServeCalendarFeed)
+
 	// ===== Tasks (protected, user-centric) =====
 	r.Route("/tasks", func(tr chi.Router) {
 		tr.Use(application.AuthMiddleware.RequireAuth)
+		tr.Use(application.Usage.Track)
+		tr.Use(application.Throttle.Limit)
+		tr.Use(authmiddleware.RequireScopeByMethod(jwttoken.ScopeTasksRead, jwttoken.ScopeTasksWrite))
 		tr.Use(middleware.LogUserInfo)
 		// Create a task in a given team
 		tr.Post("/", application.TaskHandler.CreateTask)
@@ -83,15 +185,264 @@ func SetupRouter(application *app.Application) *chi.Mux {
 		tr.Get("/reporter", application.TaskHandler.ListTasksAsReporter)
 		tr.Get("/assignee", application.TaskHandler.ListTasksAsAssignee)
 
+		// Out of office window and backup of the user as an assignee
+		tr.Get("/assignee/out-of-office", application.TaskHandler.GetOutOfOffice)
+		tr.Put("/assignee/out-of-office", application.TaskHandler.SetOutOfOffice)
+		tr.Delete("/assignee/out-of-office", application.TaskHandler.ClearOutOfOffice)
+
+		// iCalendar feed of the user's assigned tasks
+		tr.Get("/assignee/calendar-feed", application.TaskHandler.GetCalendarFeed)
+		tr.Post("/assignee/calendar-feed", application.TaskHandler.CreateCalendarFeed)
+		tr.Delete("/assignee/calendar-feed", application.TaskHandler.DeleteCalendarFeed)
+
+		// Weekly status report on the tasks the user created
+		tr.Get("/reporter/status-report", application.TaskHandler.GetStatusReport)
+		tr.Put("/reporter/status-report", application.TaskHandler.SubscribeStatusReport)
+		tr.Delete("/reporter/status-report", application.TaskHandler.UnsubscribeStatusReport)
+
+		// Links between tasks of different teams the user is in
+		tr.Get("/dependencies", application.TaskHandler.ListCrossTeamDependencies)
+
 		// Task-specific operations
 		tr.Route("/{id}", func(tr chi.Router) {
 			tr.Get("/", application.TaskHandler.GetTask)
 			tr.Delete("/", application.TaskHandler.DeleteTask)
 			tr.Patch("/assign", application.TaskHandler.AssignTask)
 			tr.Patch("/status", application.TaskHandler.UpdateStatus)
+			tr.Patch("/state", application.TaskHandler.TransitionTask)
 			tr.Patch("/update-details", application.TaskHandler.HandlePatchTask)
+			tr.Patch("/position", application.TaskHandler.MoveTask)
+
+			// Edit suggestions from non-reporters
+			tr.Get("/suggestions", application.TaskHandler.ListEditSuggestions)
+			tr.Post("/suggestions", application.TaskHandler.SuggestEdit)
+			tr.Post("/suggestions/{suggestion_id}/accept", application.TaskHandler.AcceptEditSuggestion)
+			tr.Post("/suggestions/{suggestion_id}/reject", application.TaskHandler.RejectEditSuggestion)
+
+			// Upvotes, one per member
+			tr.Get("/votes", application.TaskHandler.ListTaskVotes)
+			tr.Post("/vote", application.TaskHandler.VoteTask)
+			tr.Delete("/vote", application.TaskHandler.UnvoteTask)
+
+			// Reminders before the due date
+			tr.Get("/reminders", application.TaskHandler.ListTaskReminders)
+			tr.Post("/reminders", application.TaskHandler.CreateTaskReminder)
+			tr.Delete("/reminders/{reminder_id}", application.TaskHandler.DeleteTaskReminder)
+
+			// Soft lock on the description editor
+			tr.Get("/edit-lock", application.RealtimeHandler.GetEditLock)
+			tr.Post("/edit-lock", application.RealtimeHandler.AcquireEditLock)
+			tr.Post("/edit-lock/heartbeat", application.RealtimeHandler.HeartbeatEditLock)
+			tr.Post("/edit-lock/release", application.RealtimeHandler.ReleaseEditLock)
+			tr.Get("/history", application.TaskHandler.TaskHistory)
+			tr.Post("/restore", application.TaskHandler.RestoreTask)
+			tr.Post("/archive", application.TaskHandler.ArchiveTask)
+			tr.Post("/unarchive", application.TaskHandler.UnarchiveTask)
+
+			// Private tasks
+			tr.Patch("/visibility", application.TaskHandler.SetTaskVisibility)
+			tr.Get("/viewers", application.TaskHandler.ListTaskViewers)
+			tr.Get("/views", application.TaskHandler.ListTaskViews)
+
+			// Labels
+			tr.Post("/labels", application.TaskHandler.AddTaskLabels)
+			tr.Delete("/labels/{label_id}", application.TaskHandler.RemoveTaskLabel)
+
+			// Dependencies, across teams
+			tr.Get("/dependencies", application.TaskHandler.ListTaskDependencies)
+			tr.Post("/dependencies", application.TaskHandler.AddTaskDependency)
+			tr.Delete("/dependencies/{blocker_id}", application.TaskHandler.RemoveTaskDependency)
+
+			// Comments
+			tr.Get("/comments", application.TaskHandler.ListComments)
+			tr.Post("/comments", application.TaskHandler.CreateComment)
+			tr.Post("/comments/typing", application.RealtimeHandler.CommentTyping)
+
+			// Attachments; downloads also take a signed link
+			tr.Get("/attachments", application.TaskHandler.ListAttachments)
+			tr.With(application.Throttle.Cost(throttlemiddleware.CostUpload)).Post("/attachments", application.TaskHandler.UploadAttachment)
+			tr.Get("/attachments/{attachment_id}", application.TaskHandler.DownloadAttachment)
+			tr.Post("/attachments/{attachment_id}/link", application.TaskHandler.CreateAttachmentLink)
+			tr.Delete("/attachments/{attachment_id}", application.TaskHandler.DeleteAttachment)
+
+			// Approval flow (teams with requires_approval)
+			tr.Get("/approvals", application.TaskHandler.ListApprovals)
+			tr.Post("/approve", application.TaskHandler.ApproveTask)
+			tr.Post("/reject", application.TaskHandler.RejectTask)
 		})
 	})
 
-	return r
+	// ===== Comments (protected) =====
+	r.Route("/comments/{id}", func(cr chi.Router) {
+		cr.Use(application.AuthMiddleware.RequireAuth)
+		cr.Use(application.Usage.Track)
+		cr.Use(application.Throttle.Limit)
+		cr.Use(authmiddleware.RequireScopeByMethod(jwttoken.ScopeTasksRead, jwttoken.ScopeTasksWrite))
+		cr.Use(middleware.LogUserInfo)
+		cr.Patch("/", application.TaskHandler.EditComment)
+		cr.Get("/history", application.TaskHandler.CommentHistory)
+	})
+
+	// ===== Admin (protected, admin user_type only) =====
+	r.Route("/admin", func(ar chi.Router) {
+		ar.Use(application.AuthMiddleware.RequireAuth)
+		ar.Use(application.Usage.Track)
+		ar.Use(application.Throttle.Limit)
+		ar.Use(authmiddleware.RequireScope(jwttoken.ScopeAdmin))
+		ar.Use(middleware.LogUserInfo)
+		ar.Get("/audit-log", application.AdminHandler.ListAuditLog)
+		ar.Get("/tasks", application.AdminHandler.ListTasks)
+		ar.Get("/trash", application.AdminHandler.ListDeletedTasks)
+		ar.Post("/trash/{task_id}/restore", application.AdminHandler.RestoreDeletedTask)
+		ar.Get("/metrics", application.AdminHandler.MetricsSummary)
+		ar.Get("/metrics/tasks-per-day", application.AdminHandler.MetricsTasksPerDay)
+		ar.Get("/metrics/top-teams", application.AdminHandler.MetricsTopTeams)
+		ar.Get("/usage", application.AdminHandler.ListAPIUsage)
+		ar.Delete("/users/{user_id}/mute", application.AdminHandler.LiftMute)
+		ar.Get("/legal-holds", application.AdminHandler.ListLegalHolds)
+		ar.Post("/legal-holds", application.AdminHandler.PlaceLegalHold)
+		ar.Delete("/legal-holds/{hold_id}", application.AdminHandler.ReleaseLegalHold)
+		ar.Get("/ip-allowlist", application.AdminHandler.ListIPAllowlist)
+		ar.Post("/ip-allowlist", application.AdminHandler.AddIPAllowlistEntry)
+		ar.Delete("/ip-allowlist/{entry_id}", application.AdminHandler.RemoveIPAllowlistEntry)
+		ar.Get("/backups", application.AdminHandler.ListBackups)
+		ar.With(application.Throttle.Cost(throttlemiddleware.CostBulk)).Post("/backups", application.AdminHandler.RequestBackup)
+		ar.Get("/backups/{export_id}", application.AdminHandler.GetBackup)
+		ar.Get("/announcements", application.AdminHandler.ListAnnouncements)
+		ar.With(application.Throttle.Cost(throttlemiddleware.CostBulk)).Post("/announcements", application.AdminHandler.CreateAnnouncement)
+		ar.Get("/announcements/{announcement_id}", application.AdminHandler.GetAnnouncement)
+		// declarative provisioning for IaC tools, idempotent by external_id
+		ar.Put("/provision/users/{external_id}", application.AdminHandler.PutProvisionedUser)
+		ar.Put("/provision/teams/{external_id}", application.AdminHandler.PutProvisionedTeam)
+	})
+}
+
+// registerTeamRoutes mounts team management under /teams/{team_id}; these
+// need the teams scope.
+func registerTeamRoutes(tr chi.Router, application *app.Application) {
+	// Team members management
+	tr.Get("/members", application.TeamHandler.ListMembers)
+	tr.Post("/members", application.TeamHandler.HandleAddMember)
+	tr.Delete("/members/{user_id}", application.TeamHandler.RemoveMember)
+	tr.With(application.Throttle.Cost(throttlemiddleware.CostBulk)).Post("/members/import", application.TeamHandler.ImportMembers)
+	tr.Get("/invitations", application.TeamHandler.ListInvitations)
+	tr.Delete("/invitations/{invitation_id}", application.TeamHandler.RevokeInvitation)
+	tr.Get("/directory-sync", application.TeamHandler.GetDirectoryLink)
+	tr.Put("/directory-sync", application.TeamHandler.PutDirectoryLink)
+	tr.Delete("/directory-sync", application.TeamHandler.DeleteDirectoryLink)
+	tr.With(application.Throttle.Cost(throttlemiddleware.CostBulk)).Post("/directory-sync/run", application.TeamHandler.RunDirectorySync)
+
+	// Team settings
+	tr.Get("/settings", application.TeamHandler.GetSettings)
+	tr.Patch("/settings", application.TeamHandler.UpdateSettings)
+
+	// Custom workflows (activated via settings.workflow_id)
+	tr.Get("/workflows", application.TeamHandler.ListWorkflows)
+	tr.Post("/workflows", application.TeamHandler.CreateWorkflow)
+	tr.Put("/workflows/{workflow_id}", application.TeamHandler.UpdateWorkflow)
+	tr.Delete("/workflows/{workflow_id}", application.TeamHandler.DeleteWorkflow)
+
+	// Working-day calendar (weekends, holidays, due date policy)
+	tr.Get("/calendar", application.TeamHandler.GetCalendar)
+	tr.Put("/calendar", application.TeamHandler.PutCalendar)
+	tr.Get("/calendar/check", application.TeamHandler.CheckDueDate)
+	tr.Post("/calendar/holidays", application.TeamHandler.AddHoliday)
+	tr.Delete("/calendar/holidays/{day}", application.TeamHandler.DeleteHoliday)
+
+	// Labels
+	tr.Get("/labels", application.TeamHandler.ListLabels)
+	tr.Post("/labels", application.TeamHandler.CreateLabel)
+	tr.Patch("/labels/{label_id}", application.TeamHandler.RenameLabel)
+	tr.Delete("/labels/{label_id}", application.TeamHandler.DeleteLabel)
+
+	// Projects group the team's tasks
+	tr.Get("/projects", application.TeamHandler.ListProjects)
+	tr.Post("/projects", application.TeamHandler.CreateProject)
+	tr.Get("/projects/{project_id}", application.TeamHandler.GetProject)
+	tr.Patch("/projects/{project_id}", application.TeamHandler.UpdateProject)
+	tr.Delete("/projects/{project_id}", application.TeamHandler.DeleteProject)
+
+	// Custom fields, whose values tasks carry in custom_fields
+	tr.Get("/custom-fields", application.TeamHandler.ListCustomFields)
+	tr.Post("/custom-fields", application.TeamHandler.CreateCustomField)
+	tr.Patch("/custom-fields/{field_id}", application.TeamHandler.UpdateCustomField)
+	tr.Delete("/custom-fields/{field_id}", application.TeamHandler.DeleteCustomField)
+
+	// Label routing for auto_assign = label_routing
+	tr.Get("/assignment-routes", application.TeamHandler.ListAssignmentRoutes)
+	tr.Put("/assignment-routes", application.TeamHandler.PutAssignmentRoutes)
+
+	// On-call rotations, whose current member gets tasks with their labels
+	tr.Get("/rotations", application.TeamHandler.ListRotations)
+	tr.Post("/rotations", application.TeamHandler.CreateRotation)
+	tr.Get("/rotations/{rotation_id}", application.TeamHandler.GetRotation)
+	tr.Patch("/rotations/{rotation_id}", application.TeamHandler.UpdateRotation)
+	tr.Delete("/rotations/{rotation_id}", application.TeamHandler.DeleteRotation)
+
+	// Label rules, run on task create and edit
+	tr.Get("/label-rules", application.TeamHandler.ListLabelRules)
+	tr.Post("/label-rules", application.TeamHandler.CreateLabelRule)
+	tr.Put("/label-rules/{rule_id}", application.TeamHandler.UpdateLabelRule)
+	tr.Delete("/label-rules/{rule_id}", application.TeamHandler.DeleteLabelRule)
+
+	// Automation recipes, run from task events
+	tr.Get("/automations", application.TeamHandler.ListAutomations)
+	tr.Post("/automations", application.TeamHandler.CreateAutomation)
+	tr.Put("/automations/{recipe_id}", application.TeamHandler.UpdateAutomation)
+	tr.Delete("/automations/{recipe_id}", application.TeamHandler.DeleteAutomation)
+
+	// Snapshots (owner/admin)
+	tr.Get("/snapshots", application.TeamHandler.ListSnapshots)
+	tr.With(application.Throttle.Cost(throttlemiddleware.CostUpload)).Post("/snapshots", application.TeamHandler.CreateSnapshot)
+	tr.Delete("/snapshots/{snapshot_id}", application.TeamHandler.DeleteSnapshot)
+	tr.With(application.Throttle.Cost(throttlemiddleware.CostBulk)).Post("/snapshots/{snapshot_id}/restore", application.TeamHandler.RestoreSnapshot)
+
+	// Intake forms
+	tr.Get("/forms", application.TeamHandler.ListForms)
+	tr.Post("/forms", application.TeamHandler.CreateForm)
+	tr.Put("/forms/{form_id}", application.TeamHandler.UpdateForm)
+	tr.Delete("/forms/{form_id}", application.TeamHandler.DeleteForm)
+}
+
+// registerTeamTaskRoutes mounts the task views and intake under
+// /teams/{team_id}; these need the tasks scope.
+func registerTeamTaskRoutes(tr chi.Router, application *app.Application) {
+	tr.Post("/forms/{form_id}/submit", application.TaskHandler.SubmitTeamForm)
+
+	// Triage inbox (owner/admin)
+	tr.Get("/triage", application.TaskHandler.ListTriage)
+	tr.Post("/triage/{item_id}/accept", application.TaskHandler.AcceptTriage)
+	tr.Post("/triage/{item_id}/reject", application.TaskHandler.RejectTriage)
+
+	// Anonymous feedback link feeding the triage inbox (owner/admin)
+	tr.Get("/feedback-link", application.TaskHandler.GetFeedbackLink)
+	tr.Post("/feedback-link", application.TaskHandler.CreateFeedbackLink)
+	tr.Delete("/feedback-link", application.TaskHandler.DeleteFeedbackLink)
+
+	// Delegation of a member's powers over the tasks they created
+	tr.Get("/delegations", application.TaskHandler.ListDelegations)
+	tr.Post("/delegations", application.TaskHandler.CreateDelegation)
+	tr.Delete("/delegations/{delegation_id}", application.TaskHandler.DeleteDelegation)
+
+	// Preview label rules against a sample or the team's recent tasks
+	tr.With(application.Throttle.Cost(throttlemiddleware.CostSearch)).Post("/label-rules/dry-run", application.TaskHandler.DryRunLabelRules)
+
+	// Server-sent events for the team's task changes
+	tr.Get("/events", application.RealtimeHandler.StreamTeamEvents)
+
+	// Team-scoped task views
+	tr.Get("/tasks", application.TaskHandler.ListTeamTasks)
+	tr.Get("/tasks/assignee", application.TaskHandler.ListAssigneeTasksInTeam)
+	tr.Get("/tasks/reporter", application.TaskHandler.ListReporterTasksInTeam)
+	tr.Get("/tasks/stale", application.TaskHandler.ListStaleTasks)
+	tr.Get("/tasks/trash", application.TaskHandler.ListTrash)
+	tr.With(application.Throttle.Cost(throttlemiddleware.CostBulk)).Patch("/tasks/bulk/assign", application.TaskHandler.BulkAssignTasks)
+	tr.With(application.Throttle.Cost(throttlemiddleware.CostBulk)).Get("/tasks/export", application.TaskHandler.ExportTeamTasks)
+	tr.Post("/tasks/export/link", application.TaskHandler.CreateExportLink)
+	tr.With(application.Throttle.Cost(throttlemiddleware.CostBulk)).Post("/tasks/import", application.TaskHandler.ImportTeamTasks)
+	tr.With(application.Throttle.Cost(throttlemiddleware.CostSearch)).Get("/tasks/stats", application.TaskHandler.GetTeamTaskStats)
+	tr.With(application.Throttle.Cost(throttlemiddleware.CostSearch)).Get("/tasks/burn-up", application.TaskHandler.GetTeamBurnUp)
+	tr.Get("/tasks/due-date-suggestion", application.TaskHandler.SuggestDueDate)
+	tr.With(application.Throttle.Cost(throttlemiddleware.CostSearch)).Get("/tasks/similar", application.TaskHandler.SearchSimilarTasks)
+	tr.With(application.Throttle.Cost(throttlemiddleware.CostSearch)).Get("/tasks/search", application.TaskHandler.SearchTeamTasks)
 }
@@ -0,0 +1,75 @@
+package routes
+
+import (
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/diagnosis/interactive-todo/internal/app"
+	"github.com/diagnosis/interactive-todo/internal/apperror"
+	"github.com/diagnosis/interactive-todo/internal/helper"
+	apiversion "github.com/diagnosis/interactive-todo/internal/middleware/apiversion"
+	"github.com/go-chi/chi/v5"
+)
+
+// apiVersion is one mounted version of the API. To ship a breaking change,
+// add the next version with its own register func and give the one it
+// replaces a Deprecated date, a Sunset and a Successor.
+type apiVersion struct {
+	apiversion.Version
+	register func(chi.Router, *app.Application)
+}
+
+var apiVersions = []apiVersion{
+	{Version: apiversion.Version{Name: "v1"}, register: registerV1},
+}
+
+// Unprefixed paths predate /v1. They keep serving v1 (or the version named
+// in Accept-Version) until API_LEGACY_SUNSET.
+const defaultLegacyVersion = "v1"
+
+var legacyDeprecated = time.Date(2026, time.October, 17, 0, 0, 0, 0, time.UTC)
+
+// mountVersions mounts every version under /<name> and the unprefixed
+// legacy paths at the root.
+func mountVersions(r chi.Router, application *app.Application) {
+	legacy := make(map[string]http.Handler, len(apiVersions))
+	for _, v := range apiVersions {
+		r.Mount("/"+v.Name, versionRouter(v, v.Version, application))
+		legacy[v.Name] = versionRouter(v, apiversion.Version{
+			Name:       v.Name,
+			Deprecated: legacyDeprecated,
+			Sunset:     application.LegacyAPISunset,
+			Successor:  "/" + v.Name,
+		}, application)
+	}
+
+	r.Mount("/", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		name := strings.TrimSpace(r.Header.Get("Accept-Version"))
+		if name == "" {
+			name = defaultLegacyVersion
+		}
+		h, ok := legacy[name]
+		if !ok {
+			helper.RespondError(w, r, apperror.BadRequest("unsupported API version "+name))
+			return
+		}
+		h.ServeHTTP(w, r)
+	}))
+}
+
+// versionPrefixes returns the path prefix of every mounted version.
+func versionPrefixes() []string {
+	prefixes := make([]string, len(apiVersions))
+	for i, v := range apiVersions {
+		prefixes[i] = "/" + v.Name + "/"
+	}
+	return prefixes
+}
+
+func versionRouter(v apiVersion, announce apiversion.Version, application *app.Application) http.Handler {
+	vr := chi.NewRouter()
+	vr.Use(apiversion.Announce(announce, time.Now))
+	v.register(vr, application)
+	return vr
+}
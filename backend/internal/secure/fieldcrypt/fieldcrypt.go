@@ -0,0 +1,100 @@
+package fieldcrypt
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// Sealed values are stored as "enc:v1:<base64(nonce|ciphertext)>" so they can
+// live in the same TEXT columns as plaintext and be told apart on read.
+const prefix = "enc:v1:"
+
+const keyLen = 32 // AES-256
+
+var (
+	ErrInvalidKey        = errors.New("field encryption key must be 32 bytes")
+	ErrMalformed         = errors.New("malformed encrypted value")
+	ErrDecryptionFailure = errors.New("encrypted value could not be decrypted")
+)
+
+// Cipher seals individual string fields with AES-256-GCM.
+type Cipher struct {
+	aead cipher.AEAD
+}
+
+func NewCipher(key []byte) (*Cipher, error) {
+	if len(key) != keyLen {
+		return nil, ErrInvalidKey
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, fmt.Errorf("new aes cipher: %w", err)
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, fmt.Errorf("new gcm: %w", err)
+	}
+	return &Cipher{aead: aead}, nil
+}
+
+// FromEnv builds a Cipher from a base64 key in the named variable. An unset
+// variable returns nil, nil: encryption is optional. A KMS-managed key is
+// expected to be injected into the environment by the deployment.
+func FromEnv(name string) (*Cipher, error) {
+	v := strings.TrimSpace(os.Getenv(name))
+	if v == "" {
+		return nil, nil
+	}
+	key, err := base64.StdEncoding.DecodeString(v)
+	if err != nil {
+		return nil, fmt.Errorf("%s: decode base64: %w", name, err)
+	}
+	return NewCipher(key)
+}
+
+// Encrypt seals plaintext. aad binds the value to its owner (e.g. the team
+// id) so a sealed value copied onto another row fails to open.
+func (c *Cipher) Encrypt(plaintext string, aad []byte) (string, error) {
+	nonce := make([]byte, c.aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", fmt.Errorf("generate nonce: %w", err)
+	}
+	sealed := c.aead.Seal(nonce, nonce, []byte(plaintext), aad)
+	return prefix + base64.StdEncoding.EncodeToString(sealed), nil
+}
+
+// Decrypt opens a sealed value. Values without the prefix are returned as
+// they are, so plaintext written before encryption was enabled still reads.
+func (c *Cipher) Decrypt(value string, aad []byte) (string, error) {
+	if !IsEncrypted(value) {
+		return value, nil
+	}
+	raw, err := base64.StdEncoding.DecodeString(strings.TrimPrefix(value, prefix))
+	if err != nil {
+		return "", ErrMalformed
+	}
+	n := c.aead.NonceSize()
+	if len(raw) < n {
+		return "", ErrMalformed
+	}
+	plain, err := c.aead.Open(nil, raw[:n], raw[n:], aad)
+	if err != nil {
+		return "", ErrDecryptionFailure
+	}
+	return string(plain), nil
+}
+
+func IsEncrypted(value string) bool {
+	return strings.HasPrefix(value, prefix)
+}
+
+// Prefix is the marker sealed values start with, for SQL filters.
+func Prefix() string {
+	return prefix
+}
@@ -0,0 +1,54 @@
+package spamguard
+
+import (
+	"sync"
+	"time"
+)
+
+// IPLimiter is a fixed-window counter for unauthenticated endpoints, where
+// there is no user to mute. State is in-memory and per process.
+type IPLimiter struct {
+	mu     sync.Mutex
+	limit  int
+	window time.Duration
+	hits   map[string]*ipWindow
+}
+
+type ipWindow struct {
+	start time.Time
+	count int
+}
+
+func NewIPLimiter(limit int, window time.Duration) *IPLimiter {
+	return &IPLimiter{
+		limit:  limit,
+		window: window,
+		hits:   make(map[string]*ipWindow),
+	}
+}
+
+// Allow records a hit for ip and reports whether it is within the limit.
+func (l *IPLimiter) Allow(ip string, now time.Time) bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	// Drop expired windows opportunistically so the map cannot grow unbounded.
+	if len(l.hits) > 10000 {
+		for k, w := range l.hits {
+			if now.Sub(w.start) >= l.window {
+				delete(l.hits, k)
+			}
+		}
+	}
+
+	w, ok := l.hits[ip]
+	if !ok || now.Sub(w.start) >= l.window {
+		l.hits[ip] = &ipWindow{start: now, count: 1}
+		return true
+	}
+	if w.count >= l.limit {
+		return false
+	}
+	w.count++
+	return true
+}
@@ -0,0 +1,268 @@
+package spamguard
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+	"unicode"
+
+	auditstore "github.com/diagnosis/interactive-todo/internal/store/audit"
+	commentstore "github.com/diagnosis/interactive-todo/internal/store/comments"
+	mutestore "github.com/diagnosis/interactive-todo/internal/store/mutes"
+	taskstore "github.com/diagnosis/interactive-todo/internal/store/tasks"
+	"github.com/google/uuid"
+)
+
+var (
+	ErrMuted = errors.New("user is temporarily muted")
+)
+
+// Config holds the per-user heuristics
+type Config struct {
+	Window       time.Duration // look-back window for recent creations
+	MaxSimilar   int           // near-identical titles tolerated inside the window
+	MaxBurst     int           // creations of any kind tolerated inside the window
+	MuteDuration time.Duration // how long a detected spammer is muted
+
+	PublicSubmitLimit  int           // anonymous form submissions per IP inside PublicSubmitWindow
+	PublicSubmitWindow time.Duration // window for PublicSubmitLimit
+
+	FeedbackIPLimit    int           // anonymous feedback per IP inside FeedbackWindow
+	FeedbackTeamLimit  int           // anonymous feedback per team inside FeedbackWindow
+	FeedbackWindow     time.Duration // window for both feedback limits
+	FeedbackMaxLinks   int           // URLs tolerated in one piece of feedback
+	FeedbackDupeWindow time.Duration // look-back for repeated feedback to the same team
+}
+
+// DefaultConfig returns thresholds that normal users never hit
+func DefaultConfig() *Config {
+	return &Config{
+		Window:       10 * time.Minute,
+		MaxSimilar:   10,
+		MaxBurst:     100,
+		MuteDuration: 30 * time.Minute,
+
+		PublicSubmitLimit:  20,
+		PublicSubmitWindow: time.Hour,
+
+		FeedbackIPLimit:    5,
+		FeedbackTeamLimit:  200,
+		FeedbackWindow:     24 * time.Hour,
+		FeedbackMaxLinks:   2,
+		FeedbackDupeWindow: 7 * 24 * time.Hour,
+	}
+}
+
+// Guard blocks pathological clients that create floods of near-identical
+// tasks or comments
+type Guard struct {
+	cfg          *Config
+	taskStore    taskstore.TaskStore
+	commentStore commentstore.CommentStore
+	muteStore    mutestore.MuteStore
+	auditStore   auditstore.AuditStore
+	ipLimiter    *IPLimiter
+	// feedback is throttled per IP and, against floods from many IPs,
+	// per team
+	feedbackIPs   *IPLimiter
+	feedbackTeams *IPLimiter
+}
+
+func NewGuard(cfg *Config, ts taskstore.TaskStore, cs commentstore.CommentStore, ms mutestore.MuteStore, as auditstore.AuditStore) *Guard {
+	if cfg == nil {
+		cfg = DefaultConfig()
+	}
+	return &Guard{
+		cfg:          cfg,
+		taskStore:    ts,
+		commentStore: cs,
+		muteStore:    ms,
+		auditStore:   as,
+		ipLimiter:    NewIPLimiter(cfg.PublicSubmitLimit, cfg.PublicSubmitWindow),
+
+		feedbackIPs:   NewIPLimiter(cfg.FeedbackIPLimit, cfg.FeedbackWindow),
+		feedbackTeams: NewIPLimiter(cfg.FeedbackTeamLimit, cfg.FeedbackWindow),
+	}
+}
+
+// AllowPublicSubmit throttles unauthenticated submissions by client IP.
+func (g *Guard) AllowPublicSubmit(ip string, now time.Time) bool {
+	return g.ipLimiter.Allow(ip, now)
+}
+
+// AllowFeedback throttles anonymous feedback by client IP, then by team.
+// A refused IP does not count against the team.
+func (g *Guard) AllowFeedback(ip string, teamID uuid.UUID, now time.Time) bool {
+	return g.feedbackIPs.Allow(ip, now) && g.feedbackTeams.Allow(teamID.String(), now)
+}
+
+// FeedbackDupeWindow is how far back IsSpamFeedback's recent titles go.
+func (g *Guard) FeedbackDupeWindow() time.Duration {
+	return g.cfg.FeedbackDupeWindow
+}
+
+// IsSpamFeedback flags anonymous feedback with no words, with more links
+// than a person would paste, or repeating one of the team's recent titles.
+func (g *Guard) IsSpamFeedback(title, message string, recent []string) bool {
+	fp := Fingerprint(title)
+	if fp == "" || Fingerprint(message) == "" {
+		return true
+	}
+	lower := strings.ToLower(message)
+	if strings.Count(lower, "http://")+strings.Count(lower, "https://") > g.cfg.FeedbackMaxLinks {
+		return true
+	}
+	for _, t := range recent {
+		if Fingerprint(t) == fp {
+			return true
+		}
+	}
+	return false
+}
+
+// CheckTaskCreate returns ErrMuted (together with the active mute) when the
+// user is muted or when creating title would cross one of the thresholds.
+func (g *Guard) CheckTaskCreate(ctx context.Context, userID uuid.UUID, title string, now time.Time) (*mutestore.Mute, error) {
+	if mute, err := g.activeMute(ctx, userID, now); mute != nil || err != nil {
+		return mute, err
+	}
+
+	titles, err := g.taskStore.ListRecentTitlesByReporter(ctx, userID, now.Add(-g.cfg.Window))
+	if err != nil {
+		return nil, fmt.Errorf("spam guard: recent titles: %w", err)
+	}
+
+	reason := ""
+	if len(titles) >= g.cfg.MaxBurst {
+		reason = fmt.Sprintf("created %d tasks within %s", len(titles), g.cfg.Window)
+	} else {
+		fp := Fingerprint(title)
+		similar := 0
+		for _, t := range titles {
+			if Fingerprint(t) == fp {
+				similar++
+			}
+		}
+		if similar >= g.cfg.MaxSimilar {
+			reason = fmt.Sprintf("created %d near-identical tasks within %s", similar, g.cfg.Window)
+		}
+	}
+	if reason == "" {
+		return nil, nil
+	}
+	return g.mute(ctx, userID, reason, now)
+}
+
+// CheckTaskImport is CheckTaskCreate for a CSV import, before its rows are
+// read: it returns ErrMuted when the user is muted or has already created
+// MaxBurst tasks inside the window. The rows' titles are not compared, as
+// imported titles often differ only by a number; once created, imported
+// tasks count toward both thresholds like any others.
+func (g *Guard) CheckTaskImport(ctx context.Context, userID uuid.UUID, now time.Time) (*mutestore.Mute, error) {
+	if mute, err := g.activeMute(ctx, userID, now); mute != nil || err != nil {
+		return mute, err
+	}
+
+	titles, err := g.taskStore.ListRecentTitlesByReporter(ctx, userID, now.Add(-g.cfg.Window))
+	if err != nil {
+		return nil, fmt.Errorf("spam guard: recent titles: %w", err)
+	}
+	if len(titles) < g.cfg.MaxBurst {
+		return nil, nil
+	}
+	return g.mute(ctx, userID, fmt.Sprintf("created %d tasks within %s", len(titles), g.cfg.Window), now)
+}
+
+// CheckComment is CheckTaskCreate for comments: the user's recent comment
+// bodies count against the same thresholds, and a mute from either stops
+// both.
+func (g *Guard) CheckComment(ctx context.Context, userID uuid.UUID, body string, now time.Time) (*mutestore.Mute, error) {
+	if mute, err := g.activeMute(ctx, userID, now); mute != nil || err != nil {
+		return mute, err
+	}
+
+	bodies, err := g.commentStore.ListRecentBodiesByAuthor(ctx, userID, now.Add(-g.cfg.Window))
+	if err != nil {
+		return nil, fmt.Errorf("spam guard: recent comments: %w", err)
+	}
+
+	reason := ""
+	if len(bodies) >= g.cfg.MaxBurst {
+		reason = fmt.Sprintf("posted %d comments within %s", len(bodies), g.cfg.Window)
+	} else {
+		fp := Fingerprint(body)
+		similar := 0
+		for _, b := range bodies {
+			if Fingerprint(b) == fp {
+				similar++
+			}
+		}
+		if similar >= g.cfg.MaxSimilar {
+			reason = fmt.Sprintf("posted %d near-identical comments within %s", similar, g.cfg.Window)
+		}
+	}
+	if reason == "" {
+		return nil, nil
+	}
+	return g.mute(ctx, userID, reason, now)
+}
+
+// activeMute returns the user's mute with ErrMuted, or nothing when the
+// user is not muted.
+func (g *Guard) activeMute(ctx context.Context, userID uuid.UUID, now time.Time) (*mutestore.Mute, error) {
+	mute, err := g.muteStore.GetActive(ctx, userID, now)
+	if err == nil {
+		return mute, ErrMuted
+	}
+	if !errors.Is(err, mutestore.ErrMuteNotFound) {
+		return nil, fmt.Errorf("spam guard: get mute: %w", err)
+	}
+	return nil, nil
+}
+
+// mute mutes the user for MuteDuration, records why in the audit log and
+// returns the mute with ErrMuted.
+func (g *Guard) mute(ctx context.Context, userID uuid.UUID, reason string, now time.Time) (*mutestore.Mute, error) {
+	mute, err := g.muteStore.Mute(ctx, userID, now.Add(g.cfg.MuteDuration), reason, now)
+	if err != nil {
+		return nil, fmt.Errorf("spam guard: mute: %w", err)
+	}
+
+	if err := g.auditStore.Record(ctx, auditstore.Entry{
+		Action:     auditstore.ActionUserMuted,
+		TargetType: auditstore.TargetUser,
+		TargetID:   &userID,
+		Metadata: map[string]any{
+			"reason":      reason,
+			"muted_until": mute.MutedUntil,
+		},
+		CreatedAt: now,
+	}); err != nil {
+		return nil, fmt.Errorf("spam guard: audit: %w", err)
+	}
+
+	return mute, ErrMuted
+}
+
+// Fingerprint normalizes a title so trivially varied copies ("Buy milk 1",
+// "buy  milk 2!") compare equal: case, digits, punctuation and repeated
+// whitespace are ignored.
+func Fingerprint(title string) string {
+	var b strings.Builder
+	space := false
+	for _, r := range strings.ToLower(title) {
+		switch {
+		case unicode.IsLetter(r):
+			if space && b.Len() > 0 {
+				b.WriteByte(' ')
+			}
+			space = false
+			b.WriteRune(r)
+		case unicode.IsSpace(r):
+			space = true
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,102 @@
+package server
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// listenFDsStart is the first descriptor systemd passes (SD_LISTEN_FDS_START).
+const listenFDsStart = 3
+
+// Listener says where the API accepts connections: a TCP port, a Unix
+// socket, or a socket systemd opened for it. The last two suit a
+// single-host deployment behind nginx, where nothing else should reach
+// the API.
+type Listener struct {
+	// Socket is the path of a Unix socket to listen on; empty means TCP
+	Socket string
+	// Mode is the permission of Socket, e.g. 0660 so nginx's group can
+	// connect
+	Mode os.FileMode
+	// Inherited is set when systemd passed the socket (LISTEN_FDS)
+	Inherited bool
+}
+
+// ListenerFromEnv reads LISTEN_FDS and LISTEN_PID (set by systemd socket
+// activation), or LISTEN_SOCKET and LISTEN_SOCKET_MODE (default 0660). With
+// none of them the API listens on PORT.
+func ListenerFromEnv() (*Listener, error) {
+	l := &Listener{Socket: strings.TrimSpace(os.Getenv("LISTEN_SOCKET"))}
+
+	// sockets passed to another process (LISTEN_PID) are not ours, as
+	// systemd documents
+	if fds := os.Getenv("LISTEN_FDS"); fds != "" && os.Getenv("LISTEN_PID") == strconv.Itoa(os.Getpid()) {
+		if fds != "1" {
+			return nil, fmt.Errorf("LISTEN_FDS: expected exactly one socket, got %q", fds)
+		}
+		if l.Socket != "" {
+			return nil, errors.New("set either LISTEN_SOCKET or a systemd socket unit, not both")
+		}
+		l.Inherited = true
+		return l, nil
+	}
+
+	if l.Socket == "" {
+		return l, nil
+	}
+	mode, err := strconv.ParseUint(envOr("LISTEN_SOCKET_MODE", "0660"), 8, 32)
+	if err != nil || mode > 0o777 {
+		return nil, fmt.Errorf("LISTEN_SOCKET_MODE must be octal permissions like 0660, not %q", os.Getenv("LISTEN_SOCKET_MODE"))
+	}
+	l.Mode = os.FileMode(mode)
+	if fi, err := os.Stat(filepath.Dir(l.Socket)); err != nil || !fi.IsDir() {
+		return nil, fmt.Errorf("LISTEN_SOCKET: directory of %s does not exist", l.Socket)
+	}
+	return l, nil
+}
+
+// Listen opens the listener, on TCP port when neither socket is set.
+func (l *Listener) Listen(port string) (net.Listener, error) {
+	switch {
+	case l.Inherited:
+		f := os.NewFile(listenFDsStart, "LISTEN_FD_3")
+		ln, err := net.FileListener(f)
+		// FileListener keeps a duplicate
+		_ = f.Close()
+		if err != nil {
+			return nil, fmt.Errorf("use systemd socket: %w", err)
+		}
+		// not for processes we start
+		for _, v := range []string{"LISTEN_FDS", "LISTEN_PID", "LISTEN_FDNAMES"} {
+			_ = os.Unsetenv(v)
+		}
+		return ln, nil
+
+	case l.Socket != "":
+		// a socket left by an instance that did not shut down cleanly
+		// would make the listen fail
+		if fi, err := os.Lstat(l.Socket); err == nil {
+			if fi.Mode()&os.ModeSocket == 0 {
+				return nil, fmt.Errorf("%s exists and is not a socket", l.Socket)
+			}
+			if err := os.Remove(l.Socket); err != nil {
+				return nil, fmt.Errorf("remove stale socket: %w", err)
+			}
+		}
+		ln, err := net.Listen("unix", l.Socket)
+		if err != nil {
+			return nil, err
+		}
+		if err := os.Chmod(l.Socket, l.Mode); err != nil {
+			_ = ln.Close()
+			return nil, fmt.Errorf("chmod %s: %w", l.Socket, err)
+		}
+		return ln, nil
+	}
+	return net.Listen("tcp", ":"+port)
+}
@@ -0,0 +1,184 @@
+// Package server holds how the API listens: on a TCP port, a Unix socket or
+// a systemd socket, with plain HTTP behind a proxy or HTTPS terminated by
+// the API itself.
+package server
+
+import (
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/crypto/acme/autocert"
+)
+
+// TLS terminates HTTPS in the API, for deployments without a reverse
+// proxy. Certificates come from files or from Let's Encrypt.
+type TLS struct {
+	// Manager gets and renews certificates from Let's Encrypt; nil when
+	// they are read from files.
+	Manager *autocert.Manager
+	// RedirectAddr answers plain HTTP with a redirect to HTTPS, and ACME
+	// challenges. Empty turns it off.
+	RedirectAddr string
+
+	pair *keyPair
+}
+
+// TLSFromEnv returns nil, nil unless TLS_CERT_FILE and TLS_KEY_FILE or
+// TLS_AUTOCERT_DOMAINS are set. TLS_AUTOCERT_CACHE_DIR keeps issued
+// certificates across restarts (default "certs") and TLS_AUTOCERT_EMAIL
+// gets expiry notices. TLS_REDIRECT_ADDR is the plain HTTP listener
+// (default ":80", "off" for none).
+func TLSFromEnv() (*TLS, error) {
+	certFile := strings.TrimSpace(os.Getenv("TLS_CERT_FILE"))
+	keyFile := strings.TrimSpace(os.Getenv("TLS_KEY_FILE"))
+	domains := strings.TrimSpace(os.Getenv("TLS_AUTOCERT_DOMAINS"))
+	switch {
+	case certFile == "" && keyFile == "" && domains == "":
+		return nil, nil
+	case domains != "" && (certFile != "" || keyFile != ""):
+		return nil, errors.New("set either TLS_CERT_FILE and TLS_KEY_FILE or TLS_AUTOCERT_DOMAINS, not both")
+	case domains == "" && (certFile == "" || keyFile == ""):
+		return nil, errors.New("TLS_CERT_FILE and TLS_KEY_FILE must be set together")
+	}
+
+	t := &TLS{RedirectAddr: envOr("TLS_REDIRECT_ADDR", ":80")}
+	if t.RedirectAddr == "off" {
+		t.RedirectAddr = ""
+	}
+	if domains == "" {
+		t.pair = &keyPair{certFile: certFile, keyFile: keyFile}
+		// a bad pair should stop startup, not fail every handshake
+		if err := t.pair.load(); err != nil {
+			return nil, err
+		}
+		return t, nil
+	}
+
+	var hosts []string
+	for _, d := range strings.Split(domains, ",") {
+		d = strings.ToLower(strings.TrimSpace(d))
+		if d == "" || strings.ContainsAny(d, ":/ ") {
+			return nil, fmt.Errorf("TLS_AUTOCERT_DOMAINS: %q is not a host name", d)
+		}
+		hosts = append(hosts, d)
+	}
+	t.Manager = &autocert.Manager{
+		Prompt:     autocert.AcceptTOS,
+		HostPolicy: autocert.HostWhitelist(hosts...),
+		Cache:      autocert.DirCache(envOr("TLS_AUTOCERT_CACHE_DIR", "certs")),
+		Email:      strings.TrimSpace(os.Getenv("TLS_AUTOCERT_EMAIL")),
+	}
+	return t, nil
+}
+
+// Config returns the server side TLS settings: TLS 1.2 or later, with
+// HTTP/2 offered.
+func (t *TLS) Config() *tls.Config {
+	if t.Manager != nil {
+		// also answers tls-alpn-01 challenges on the HTTPS port
+		c := t.Manager.TLSConfig()
+		c.MinVersion = tls.VersionTLS12
+		return c
+	}
+	return &tls.Config{
+		MinVersion:     tls.VersionTLS12,
+		NextProtos:     []string{"h2", "http/1.1"},
+		GetCertificate: t.pair.get,
+	}
+}
+
+// RedirectServer returns the plain HTTP server sending clients to HTTPS on
+// httpsPort, or nil when RedirectAddr is empty.
+func (t *TLS) RedirectServer(httpsPort string) *http.Server {
+	if t.RedirectAddr == "" {
+		return nil
+	}
+	var h http.Handler = redirectHandler(httpsPort)
+	if t.Manager != nil {
+		h = t.Manager.HTTPHandler(h)
+	}
+	return &http.Server{
+		Addr:         t.RedirectAddr,
+		Handler:      h,
+		ReadTimeout:  5 * time.Second,
+		WriteTimeout: 5 * time.Second,
+		IdleTimeout:  30 * time.Second,
+	}
+}
+
+// redirectHandler moves GET and HEAD requests to HTTPS. Anything else
+// would have sent its body in the clear already, so it is refused rather
+// than repeated.
+func redirectHandler(httpsPort string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet && r.Method != http.MethodHead {
+			http.Error(w, "use HTTPS", http.StatusBadRequest)
+			return
+		}
+		host := r.Host
+		if h, _, err := net.SplitHostPort(host); err == nil {
+			host = h
+		}
+		if httpsPort != "443" {
+			host = net.JoinHostPort(host, httpsPort)
+		}
+		http.Redirect(w, r, "https://"+host+r.URL.RequestURI(), http.StatusMovedPermanently)
+	}
+}
+
+// keyPairCheckInterval is how often the certificate files are checked for
+// a renewal.
+const keyPairCheckInterval = time.Minute
+
+// keyPair serves a certificate from files and picks up a renewed one
+// without a restart.
+type keyPair struct {
+	certFile string
+	keyFile  string
+
+	mu      sync.Mutex
+	cert    *tls.Certificate
+	modTime time.Time
+	checked time.Time
+}
+
+func (k *keyPair) load() error {
+	info, err := os.Stat(k.certFile)
+	if err != nil {
+		return fmt.Errorf("TLS_CERT_FILE: %w", err)
+	}
+	cert, err := tls.LoadX509KeyPair(k.certFile, k.keyFile)
+	if err != nil {
+		return fmt.Errorf("TLS_CERT_FILE and TLS_KEY_FILE: %w", err)
+	}
+	k.cert, k.modTime = &cert, info.ModTime()
+	return nil
+}
+
+func (k *keyPair) get(*tls.ClientHelloInfo) (*tls.Certificate, error) {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+
+	if now := time.Now(); now.Sub(k.checked) >= keyPairCheckInterval {
+		k.checked = now
+		// a half-written renewal fails to load; keep serving the old one
+		if info, err := os.Stat(k.certFile); err == nil && !info.ModTime().Equal(k.modTime) {
+			_ = k.load()
+		}
+	}
+	return k.cert, nil
+}
+
+func envOr(name, def string) string {
+	if v := strings.TrimSpace(os.Getenv(name)); v != "" {
+		return v
+	}
+	return def
+}
@@ -0,0 +1,65 @@
+// Package storage keeps files outside the database, on local disk or in an
+// S3-compatible bucket. Keys are slash-separated paths chosen by the
+// caller.
+package storage
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+)
+
+var ErrNotFound = errors.New("storage: object not found")
+
+type Store interface {
+	// Put writes size bytes from body under key. sha256Hex is the hex
+	// SHA-256 of the body.
+	Put(ctx context.Context, key string, body io.Reader, size int64, sha256Hex string) error
+	// Get opens an object for reading; the caller closes it.
+	Get(ctx context.Context, key string) (io.ReadCloser, error)
+	// Delete removes an object. A missing key is not an error, so a
+	// cleanup can be retried.
+	Delete(ctx context.Context, key string) error
+}
+
+var (
+	_ Store = (*Disk)(nil)
+	_ Store = (*S3)(nil)
+)
+
+// AttachmentsFromEnv returns where task attachments are kept: the bucket
+// in ATTACHMENTS_S3_BUCKET when set (with ATTACHMENTS_S3_REGION,
+// ATTACHMENTS_S3_ENDPOINT and the AWS credentials), otherwise the
+// directory in ATTACHMENTS_DIR (default attachments). The prefix is put in
+// front of every key.
+func AttachmentsFromEnv() (Store, string, error) {
+	bucket := strings.TrimSpace(os.Getenv("ATTACHMENTS_S3_BUCKET"))
+	if bucket == "" {
+		d, err := NewDisk(envOr("ATTACHMENTS_DIR", "attachments"))
+		return d, "", err
+	}
+	s3 := &S3{
+		Bucket:          bucket,
+		Region:          envOr("ATTACHMENTS_S3_REGION", "us-east-1"),
+		Endpoint:        strings.TrimSpace(os.Getenv("ATTACHMENTS_S3_ENDPOINT")),
+		AccessKeyID:     os.Getenv("AWS_ACCESS_KEY_ID"),
+		SecretAccessKey: os.Getenv("AWS_SECRET_ACCESS_KEY"),
+		SessionToken:    os.Getenv("AWS_SESSION_TOKEN"),
+		Client:          &http.Client{Timeout: 5 * time.Minute},
+	}
+	if s3.AccessKeyID == "" || s3.SecretAccessKey == "" {
+		return nil, "", errors.New("AWS_ACCESS_KEY_ID and AWS_SECRET_ACCESS_KEY are required when ATTACHMENTS_S3_BUCKET is set")
+	}
+	return s3, envOr("ATTACHMENTS_S3_PREFIX", "attachments/"), nil
+}
+
+func envOr(name, def string) string {
+	if v := strings.TrimSpace(os.Getenv(name)); v != "" {
+		return v
+	}
+	return def
+}
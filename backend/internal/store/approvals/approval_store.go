@@ -0,0 +1,204 @@
+package store
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/diagnosis/interactive-todo/api/types"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type ApprovalStatus = types.ApprovalStatus
+
+const (
+	StatusPending  ApprovalStatus = "pending"
+	StatusApproved ApprovalStatus = "approved"
+	StatusRejected ApprovalStatus = "rejected"
+)
+
+type Approval = types.Approval
+
+var (
+	ErrApprovalNotFound = errors.New("approval not found")
+	ErrApprovalPending  = errors.New("approval already pending")
+)
+
+type ApprovalStore interface {
+	Request(ctx context.Context, taskID, requesterID uuid.UUID, now time.Time) (*Approval, error)
+	GetPending(ctx context.Context, taskID uuid.UUID) (*Approval, error)
+	ListForTask(ctx context.Context, taskID uuid.UUID) ([]Approval, error)
+	// Decide resolves the pending request and moves the task to done
+	// (approved) or back to in_progress (rejected) in one transaction.
+	Decide(ctx context.Context, taskID, deciderID uuid.UUID, approve bool, reason *string, now time.Time) (*Approval, error)
+}
+
+// NOTE: order must match all Scan calls
+const approvalColumns = `
+    id,
+    task_id,
+    requested_by,
+    status,
+    decided_by,
+    decided_at,
+    reason,
+    created_at
+`
+
+type PGApprovalStore struct {
+	pool *pgxpool.Pool
+}
+
+func NewPGApprovalStore(pool *pgxpool.Pool) *PGApprovalStore {
+	return &PGApprovalStore{pool: pool}
+}
+
+func (s *PGApprovalStore) Request(ctx context.Context, taskID, requesterID uuid.UUID, now time.Time) (*Approval, error) {
+	const q = `
+		INSERT INTO task_approvals (task_id, requested_by, created_at)
+		VALUES ($1, $2, $3)
+		RETURNING ` + approvalColumns
+
+	a, err := scanApproval(s.pool.QueryRow(ctx, q, taskID, requesterID, now.UTC()))
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == "23505" {
+			return nil, ErrApprovalPending
+		}
+		return nil, fmt.Errorf("request approval task_id=%s: %w", taskID, err)
+	}
+	return a, nil
+}
+
+func (s *PGApprovalStore) GetPending(ctx context.Context, taskID uuid.UUID) (*Approval, error) {
+	const q = `
+		SELECT ` + approvalColumns + `
+		FROM task_approvals
+		WHERE task_id = $1
+		  AND status = 'pending'
+	`
+
+	a, err := scanApproval(s.pool.QueryRow(ctx, q, taskID))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrApprovalNotFound
+		}
+		return nil, fmt.Errorf("get pending approval task_id=%s: %w", taskID, err)
+	}
+	return a, nil
+}
+
+func (s *PGApprovalStore) ListForTask(ctx context.Context, taskID uuid.UUID) ([]Approval, error) {
+	const q = `
+		SELECT ` + approvalColumns + `
+		FROM task_approvals
+		WHERE task_id = $1
+		ORDER BY created_at DESC
+	`
+
+	rows, err := s.pool.Query(ctx, q, taskID)
+	if err != nil {
+		return nil, fmt.Errorf("list approvals task_id=%s: %w", taskID, err)
+	}
+	defer rows.Close()
+
+	var out []Approval
+	for rows.Next() {
+		a, err := scanApproval(rows)
+		if err != nil {
+			return nil, fmt.Errorf("list approvals: scan: %w", err)
+		}
+		out = append(out, *a)
+	}
+	return out, rows.Err()
+}
+
+func (s *PGApprovalStore) Decide(
+	ctx context.Context,
+	taskID, deciderID uuid.UUID,
+	approve bool,
+	reason *string,
+	now time.Time,
+) (*Approval, error) {
+	status, taskStatus := StatusRejected, "in_progress"
+	if approve {
+		status, taskStatus = StatusApproved, "done"
+	}
+
+	const decide = `
+		UPDATE task_approvals
+		SET status     = $2,
+		    decided_by = $3,
+		    decided_at = $4,
+		    reason     = $5
+		WHERE task_id = $1
+		  AND status = 'pending'
+		RETURNING ` + approvalColumns
+	const moveTask = `
+		UPDATE tasks t
+		SET status     = $2,
+		    updated_at = $3
+		FROM (SELECT id, status FROM tasks WHERE id = $1 FOR UPDATE) old
+		WHERE t.id = old.id
+		RETURNING t.team_id, old.status
+	`
+	// the same row the tasks store writes for a status change
+	const recordEvent = `
+		INSERT INTO task_events (task_id, team_id, actor_id, kind, changes, created_at)
+		VALUES ($1, $2, $3, 'status_changed',
+		        jsonb_build_object('status', jsonb_build_object('from', $4::text, 'to', $5::text)), $6)
+	`
+
+	tx, err := s.pool.BeginTx(ctx, pgx.TxOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("decide approval: begin tx: %w", err)
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	a, err := scanApproval(tx.QueryRow(ctx, decide, taskID, status, deciderID, now.UTC(), reason))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrApprovalNotFound
+		}
+		return nil, fmt.Errorf("decide approval task_id=%s: %w", taskID, err)
+	}
+
+	var teamID uuid.UUID
+	var from string
+	if err := tx.QueryRow(ctx, moveTask, taskID, taskStatus, now.UTC()).Scan(&teamID, &from); err != nil {
+		return nil, fmt.Errorf("decide approval: move task_id=%s: %w", taskID, err)
+	}
+	if from != taskStatus {
+		if _, err := tx.Exec(ctx, recordEvent, taskID, teamID, deciderID, from, taskStatus, now.UTC()); err != nil {
+			return nil, fmt.Errorf("decide approval: record task event task_id=%s: %w", taskID, err)
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("decide approval: commit: %w", err)
+	}
+	return a, nil
+}
+
+func scanApproval(row pgx.Row) (*Approval, error) {
+	var a Approval
+	if err := row.Scan(
+		&a.ID,
+		&a.TaskID,
+		&a.RequestedBy,
+		&a.Status,
+		&a.DecidedBy,
+		&a.DecidedAt,
+		&a.Reason,
+		&a.CreatedAt,
+	); err != nil {
+		return nil, err
+	}
+	return &a, nil
+}
+
+var _ ApprovalStore = (*PGApprovalStore)(nil)
@@ -0,0 +1,205 @@
+package store
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/diagnosis/interactive-todo/api/types"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type Route = types.AssignmentRoute
+
+// ErrNoMembers means the team has nobody to assign to, or everyone is
+// out of office.
+var ErrNoMembers = errors.New("team has no members")
+
+// AssignmentStore picks assignees for tasks created without one.
+type AssignmentStore interface {
+	// NextRoundRobin returns the member after the previous pick, in join
+	// order, and records it. Members out of office at now are passed over.
+	NextRoundRobin(ctx context.Context, teamID uuid.UUID, now time.Time) (uuid.UUID, error)
+	// LeastLoaded returns the member with the fewest open and in-progress
+	// tasks in the team; ties go to the longest-standing member. Members
+	// out of office at now are passed over.
+	LeastLoaded(ctx context.Context, teamID uuid.UUID, now time.Time) (uuid.UUID, error)
+	// MatchRoute returns the first route for one of labelIDs whose assignee
+	// is still a member, or nil.
+	MatchRoute(ctx context.Context, teamID uuid.UUID, labelIDs []uuid.UUID) (*Route, error)
+	ListRoutes(ctx context.Context, teamID uuid.UUID) ([]Route, error)
+	ReplaceRoutes(ctx context.Context, teamID uuid.UUID, routes []Route) error
+}
+
+type PGAssignmentStore struct {
+	pool *pgxpool.Pool
+}
+
+func NewPGAssignmentStore(pool *pgxpool.Pool) *PGAssignmentStore {
+	return &PGAssignmentStore{pool: pool}
+}
+
+// NextRoundRobin locks the team's state row so concurrent creates take
+// turns instead of picking the same member.
+func (s *PGAssignmentStore) NextRoundRobin(ctx context.Context, teamID uuid.UUID, now time.Time) (uuid.UUID, error) {
+	tx, err := s.pool.BeginTx(ctx, pgx.TxOptions{})
+	if err != nil {
+		return uuid.Nil, fmt.Errorf("round robin team_id=%s: begin: %w", teamID, err)
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	const lock = `
+		INSERT INTO team_assignment_state (team_id, updated_at)
+		VALUES ($1, $2)
+		ON CONFLICT (team_id) DO UPDATE SET updated_at = EXCLUDED.updated_at
+		RETURNING last_assignee_id
+	`
+	var last *uuid.UUID
+	if err := tx.QueryRow(ctx, lock, teamID, now.UTC()).Scan(&last); err != nil {
+		return uuid.Nil, fmt.Errorf("round robin team_id=%s: lock: %w", teamID, err)
+	}
+
+	// members after the last pick first, then wrap around; positions
+	// count everyone so the turn order survives an absence
+	const pick = `
+		WITH members AS (
+			SELECT user_id, row_number() OVER (ORDER BY created_at, user_id) AS pos
+			FROM team_members
+			WHERE team_id = $1
+		)
+		SELECT m.user_id
+		FROM members m
+		WHERE NOT EXISTS (
+			SELECT 1 FROM user_out_of_office o
+			WHERE o.user_id = m.user_id AND o.starts_at <= $3 AND o.ends_at > $3
+		)
+		ORDER BY m.pos <= COALESCE((SELECT pos FROM members WHERE user_id = $2), 0), m.pos
+		LIMIT 1
+	`
+	var next uuid.UUID
+	if err := tx.QueryRow(ctx, pick, teamID, last, now.UTC()).Scan(&next); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return uuid.Nil, ErrNoMembers
+		}
+		return uuid.Nil, fmt.Errorf("round robin team_id=%s: pick: %w", teamID, err)
+	}
+
+	const save = `UPDATE team_assignment_state SET last_assignee_id = $2 WHERE team_id = $1`
+	if _, err := tx.Exec(ctx, save, teamID, next); err != nil {
+		return uuid.Nil, fmt.Errorf("round robin team_id=%s: save: %w", teamID, err)
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return uuid.Nil, fmt.Errorf("round robin team_id=%s: commit: %w", teamID, err)
+	}
+	return next, nil
+}
+
+func (s *PGAssignmentStore) LeastLoaded(ctx context.Context, teamID uuid.UUID, now time.Time) (uuid.UUID, error) {
+	const q = `
+		SELECT m.user_id
+		FROM team_members m
+		LEFT JOIN tasks t
+		       ON t.team_id = m.team_id
+		      AND t.assignee_id = m.user_id
+		      AND t.status IN ('open', 'in_progress')
+		      AND t.deleted_at IS NULL
+		WHERE m.team_id = $1
+		  AND NOT EXISTS (
+		      SELECT 1 FROM user_out_of_office o
+		      WHERE o.user_id = m.user_id AND o.starts_at <= $2 AND o.ends_at > $2
+		  )
+		GROUP BY m.user_id, m.created_at
+		ORDER BY count(t.id), m.created_at, m.user_id
+		LIMIT 1
+	`
+	var id uuid.UUID
+	if err := s.pool.QueryRow(ctx, q, teamID, now.UTC()).Scan(&id); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return uuid.Nil, ErrNoMembers
+		}
+		return uuid.Nil, fmt.Errorf("least loaded team_id=%s: %w", teamID, err)
+	}
+	return id, nil
+}
+
+func (s *PGAssignmentStore) MatchRoute(ctx context.Context, teamID uuid.UUID, labelIDs []uuid.UUID) (*Route, error) {
+	if len(labelIDs) == 0 {
+		return nil, nil
+	}
+	const q = `
+		SELECT r.label_id, l.name, r.assignee_id
+		FROM assignment_routes r
+		JOIN labels l ON l.id = r.label_id
+		JOIN team_members m ON m.team_id = r.team_id AND m.user_id = r.assignee_id
+		WHERE r.team_id = $1 AND r.label_id = ANY($2)
+		ORDER BY r.position
+		LIMIT 1
+	`
+	var route Route
+	err := s.pool.QueryRow(ctx, q, teamID, labelIDs).Scan(&route.LabelID, &route.Label, &route.AssigneeID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("match route team_id=%s: %w", teamID, err)
+	}
+	return &route, nil
+}
+
+func (s *PGAssignmentStore) ListRoutes(ctx context.Context, teamID uuid.UUID) ([]Route, error) {
+	const q = `
+		SELECT r.label_id, l.name, r.assignee_id
+		FROM assignment_routes r
+		JOIN labels l ON l.id = r.label_id
+		WHERE r.team_id = $1
+		ORDER BY r.position
+	`
+	rows, err := s.pool.Query(ctx, q, teamID)
+	if err != nil {
+		return nil, fmt.Errorf("list routes team_id=%s: %w", teamID, err)
+	}
+	defer rows.Close()
+
+	routes := []Route{}
+	for rows.Next() {
+		var route Route
+		if err := rows.Scan(&route.LabelID, &route.Label, &route.AssigneeID); err != nil {
+			return nil, fmt.Errorf("scan route: %w", err)
+		}
+		routes = append(routes, route)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("list routes team_id=%s: %w", teamID, err)
+	}
+	return routes, nil
+}
+
+func (s *PGAssignmentStore) ReplaceRoutes(ctx context.Context, teamID uuid.UUID, routes []Route) error {
+	tx, err := s.pool.BeginTx(ctx, pgx.TxOptions{})
+	if err != nil {
+		return fmt.Errorf("replace routes team_id=%s: begin: %w", teamID, err)
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	if _, err := tx.Exec(ctx, `DELETE FROM assignment_routes WHERE team_id = $1`, teamID); err != nil {
+		return fmt.Errorf("replace routes team_id=%s: delete: %w", teamID, err)
+	}
+	const q = `
+		INSERT INTO assignment_routes (team_id, label_id, assignee_id, position)
+		VALUES ($1, $2, $3, $4)
+	`
+	for i, route := range routes {
+		if _, err := tx.Exec(ctx, q, teamID, route.LabelID, route.AssigneeID, i); err != nil {
+			return fmt.Errorf("replace routes team_id=%s: insert label_id=%s: %w", teamID, route.LabelID, err)
+		}
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("replace routes team_id=%s: commit: %w", teamID, err)
+	}
+	return nil
+}
+
+var _ AssignmentStore = (*PGAssignmentStore)(nil)
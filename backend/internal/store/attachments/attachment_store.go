@@ -0,0 +1,191 @@
+package store
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/diagnosis/interactive-todo/api/types"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type Attachment = types.Attachment
+
+var ErrAttachmentNotFound = errors.New("attachment not found")
+
+type AttachmentStore interface {
+	// Create records an attachment whose bytes are already stored under
+	// a.StorageKey. TeamID is filled in from the task.
+	Create(ctx context.Context, a *Attachment) error
+	// ListForTask returns the task's attachments, oldest first.
+	ListForTask(ctx context.Context, taskID uuid.UUID) ([]Attachment, error)
+	CountForTask(ctx context.Context, taskID uuid.UUID) (int, error)
+	Get(ctx context.Context, taskID, id uuid.UUID) (*Attachment, error)
+	// Delete removes the row; a trigger queues its object for PendingPurges.
+	Delete(ctx context.Context, taskID, id uuid.UUID) error
+	// PendingPurges returns up to limit storage keys whose rows are gone.
+	PendingPurges(ctx context.Context, limit int) ([]string, error)
+	// Purged forgets keys whose objects have been deleted.
+	Purged(ctx context.Context, keys []string) error
+}
+
+// NOTE: order must match scanAttachment
+const attachmentColumns = `
+    id,
+    task_id,
+    team_id,
+    uploader_id,
+    filename,
+    content_type,
+    size_bytes,
+    sha256,
+    storage_key,
+    created_at
+`
+
+type PGAttachmentStore struct {
+	pool *pgxpool.Pool
+}
+
+func NewPGAttachmentStore(pool *pgxpool.Pool) *PGAttachmentStore {
+	return &PGAttachmentStore{pool: pool}
+}
+
+func scanAttachment(row pgx.Row) (*Attachment, error) {
+	var a Attachment
+	err := row.Scan(
+		&a.ID,
+		&a.TaskID,
+		&a.TeamID,
+		&a.UploaderID,
+		&a.Filename,
+		&a.ContentType,
+		&a.SizeBytes,
+		&a.SHA256,
+		&a.StorageKey,
+		&a.CreatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &a, nil
+}
+
+func (s *PGAttachmentStore) Create(ctx context.Context, a *Attachment) error {
+	const q = `
+		INSERT INTO task_attachments (id, task_id, uploader_id, filename, content_type, size_bytes, sha256, storage_key, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+		RETURNING team_id
+	`
+	err := s.pool.QueryRow(ctx, q,
+		a.ID, a.TaskID, a.UploaderID, a.Filename, a.ContentType, a.SizeBytes, a.SHA256, a.StorageKey, a.CreatedAt.UTC(),
+	).Scan(&a.TeamID)
+	if err != nil {
+		return fmt.Errorf("create attachment task_id=%s: %w", a.TaskID, err)
+	}
+	return nil
+}
+
+func (s *PGAttachmentStore) ListForTask(ctx context.Context, taskID uuid.UUID) ([]Attachment, error) {
+	q := `
+		SELECT ` + attachmentColumns + `
+		FROM task_attachments
+		WHERE task_id = $1
+		ORDER BY created_at, id
+	`
+	rows, err := s.pool.Query(ctx, q, taskID)
+	if err != nil {
+		return nil, fmt.Errorf("list attachments task_id=%s: %w", taskID, err)
+	}
+	defer rows.Close()
+
+	out := []Attachment{}
+	for rows.Next() {
+		a, err := scanAttachment(rows)
+		if err != nil {
+			return nil, fmt.Errorf("scan attachment: %w", err)
+		}
+		out = append(out, *a)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("list attachments task_id=%s: %w", taskID, err)
+	}
+	return out, nil
+}
+
+func (s *PGAttachmentStore) CountForTask(ctx context.Context, taskID uuid.UUID) (int, error) {
+	var n int
+	err := s.pool.QueryRow(ctx, `SELECT count(*) FROM task_attachments WHERE task_id = $1`, taskID).Scan(&n)
+	if err != nil {
+		return 0, fmt.Errorf("count attachments task_id=%s: %w", taskID, err)
+	}
+	return n, nil
+}
+
+func (s *PGAttachmentStore) Get(ctx context.Context, taskID, id uuid.UUID) (*Attachment, error) {
+	q := `
+		SELECT ` + attachmentColumns + `
+		FROM task_attachments
+		WHERE id = $1 AND task_id = $2
+	`
+	a, err := scanAttachment(s.pool.QueryRow(ctx, q, id, taskID))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrAttachmentNotFound
+		}
+		return nil, fmt.Errorf("get attachment id=%s: %w", id, err)
+	}
+	return a, nil
+}
+
+func (s *PGAttachmentStore) Delete(ctx context.Context, taskID, id uuid.UUID) error {
+	tag, err := s.pool.Exec(ctx, `DELETE FROM task_attachments WHERE id = $1 AND task_id = $2`, id, taskID)
+	if err != nil {
+		return fmt.Errorf("delete attachment id=%s: %w", id, err)
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrAttachmentNotFound
+	}
+	return nil
+}
+
+func (s *PGAttachmentStore) PendingPurges(ctx context.Context, limit int) ([]string, error) {
+	const q = `
+		SELECT storage_key
+		FROM attachment_purges
+		ORDER BY queued_at
+		LIMIT $1
+	`
+	rows, err := s.pool.Query(ctx, q, limit)
+	if err != nil {
+		return nil, fmt.Errorf("list attachment purges: %w", err)
+	}
+	defer rows.Close()
+
+	var keys []string
+	for rows.Next() {
+		var k string
+		if err := rows.Scan(&k); err != nil {
+			return nil, fmt.Errorf("scan attachment purge: %w", err)
+		}
+		keys = append(keys, k)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("list attachment purges: %w", err)
+	}
+	return keys, nil
+}
+
+func (s *PGAttachmentStore) Purged(ctx context.Context, keys []string) error {
+	if len(keys) == 0 {
+		return nil
+	}
+	if _, err := s.pool.Exec(ctx, `DELETE FROM attachment_purges WHERE storage_key = ANY($1::text[])`, keys); err != nil {
+		return fmt.Errorf("forget %d attachment purges: %w", len(keys), err)
+	}
+	return nil
+}
+
+var _ AttachmentStore = (*PGAttachmentStore)(nil)
@@ -0,0 +1,212 @@
+package store
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/diagnosis/interactive-todo/api/types"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type Action = types.AuditAction
+
+const (
+	ActionUserMuted          Action = "user.muted"
+	ActionUserUnmuted        Action = "user.unmuted"
+	ActionLegalHoldPlaced    Action = "legal_hold.placed"
+	ActionLegalHoldReleased  Action = "legal_hold.released"
+	ActionTaskViewed         Action = "task.viewed"
+	ActionTaskAutoAssigned   Action = "task.auto_assigned"
+	ActionIPAllowlistAdded   Action = "ip_allowlist.added"
+	ActionIPAllowlistRemoved Action = "ip_allowlist.removed"
+	ActionIPBlocked          Action = "ip_allowlist.blocked"
+	ActionIPBreakGlass       Action = "ip_allowlist.break_glass"
+	ActionAdminTasksListed   Action = "admin.tasks_listed"
+	ActionAdminTrashListed   Action = "admin.trash_listed"
+	ActionAdminTaskRestored  Action = "admin.task_restored"
+	ActionBackupRequested    Action = "backup.requested"
+	ActionAnnouncementSent   Action = "announcement.sent"
+	ActionUserProvisioned    Action = "user.provisioned"
+	ActionTeamProvisioned    Action = "team.provisioned"
+	ActionDelegationCreated  Action = "delegation.created"
+	ActionDelegationRevoked  Action = "delegation.revoked"
+	ActionDelegationExpired  Action = "delegation.expired"
+	// ActionDelegationUsed is recorded when a delegate approves, rejects
+	// or assigns a task in the delegator's place.
+	ActionDelegationUsed Action = "delegation.used"
+)
+
+type TargetType = types.AuditTargetType
+
+const (
+	TargetUser TargetType = "user"
+	TargetTeam TargetType = "team"
+	TargetTask TargetType = "task"
+
+	TargetIPAllowlist  TargetType = "ip_allowlist"
+	TargetBackup       TargetType = "backup"
+	TargetAnnouncement TargetType = "announcement"
+	TargetDelegation   TargetType = "delegation"
+)
+
+type Entry = types.AuditEntry
+
+type AuditStore interface {
+	Record(ctx context.Context, e Entry) error
+	List(ctx context.Context, action Action, limit int) ([]Entry, error)
+	ListForTarget(ctx context.Context, action Action, targetType TargetType, targetID uuid.UUID, limit int) ([]Entry, error)
+	PurgeBefore(ctx context.Context, action Action, before time.Time) (int64, error)
+}
+
+// NOTE: order must match all Scan calls
+const entryColumns = `
+    id,
+    actor_id,
+    action,
+    target_type,
+    target_id,
+    team_id,
+    metadata,
+    COALESCE(host(ip), ''),
+    created_at
+`
+
+type PGAuditStore struct {
+	pool *pgxpool.Pool
+}
+
+func NewPGAuditStore(pool *pgxpool.Pool) *PGAuditStore {
+	return &PGAuditStore{pool: pool}
+}
+
+func (s *PGAuditStore) Record(ctx context.Context, e Entry) error {
+	if e.Action == "" || e.TargetType == "" {
+		return fmt.Errorf("record audit entry: action and target_type are required")
+	}
+	if e.Metadata == nil {
+		e.Metadata = map[string]any{}
+	}
+	if e.CreatedAt.IsZero() {
+		e.CreatedAt = time.Now()
+	}
+
+	const q = `
+		INSERT INTO audit_log (actor_id, action, target_type, target_id, team_id, metadata, ip, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, NULLIF($7, '')::inet, $8)
+	`
+
+	if _, err := s.pool.Exec(ctx, q,
+		e.ActorID,
+		string(e.Action),
+		string(e.TargetType),
+		e.TargetID,
+		e.TeamID,
+		e.Metadata,
+		e.IP,
+		e.CreatedAt.UTC(),
+	); err != nil {
+		return fmt.Errorf("record audit entry action=%s: %w", e.Action, err)
+	}
+	return nil
+}
+
+// List returns the newest entries first. An empty action lists every action.
+func (s *PGAuditStore) List(ctx context.Context, action Action, limit int) ([]Entry, error) {
+	if limit <= 0 || limit > 500 {
+		limit = 100
+	}
+
+	const q = `
+		SELECT ` + entryColumns + `
+		FROM audit_log
+		WHERE ($1 = '' OR action = $1)
+		ORDER BY created_at DESC
+		LIMIT $2
+	`
+
+	rows, err := s.pool.Query(ctx, q, string(action), limit)
+	if err != nil {
+		return nil, fmt.Errorf("list audit entries: %w", err)
+	}
+	defer rows.Close()
+
+	return scanEntries(rows)
+}
+
+func (s *PGAuditStore) ListForTarget(
+	ctx context.Context,
+	action Action,
+	targetType TargetType,
+	targetID uuid.UUID,
+	limit int,
+) ([]Entry, error) {
+	if limit <= 0 || limit > 500 {
+		limit = 100
+	}
+
+	const q = `
+		SELECT ` + entryColumns + `
+		FROM audit_log
+		WHERE target_type = $1
+		  AND target_id = $2
+		  AND action = $3
+		ORDER BY created_at DESC
+		LIMIT $4
+	`
+
+	rows, err := s.pool.Query(ctx, q, string(targetType), targetID, string(action), limit)
+	if err != nil {
+		return nil, fmt.Errorf("list audit entries target_id=%s: %w", targetID, err)
+	}
+	defer rows.Close()
+
+	return scanEntries(rows)
+}
+
+// PurgeBefore deletes entries of one action older than before. Entries about
+// a team or task under legal hold are kept.
+func (s *PGAuditStore) PurgeBefore(ctx context.Context, action Action, before time.Time) (int64, error) {
+	const q = `
+		DELETE FROM audit_log a
+		WHERE a.action = $1
+		  AND a.created_at < $2
+		  AND NOT EXISTS (
+		      SELECT 1 FROM legal_holds lh
+		      WHERE (lh.task_id IS NULL AND lh.team_id = a.team_id)
+		         OR (a.target_type = 'task' AND lh.task_id = a.target_id)
+		  )
+	`
+
+	ct, err := s.pool.Exec(ctx, q, string(action), before.UTC())
+	if err != nil {
+		return 0, fmt.Errorf("purge audit entries action=%s: %w", action, err)
+	}
+	return ct.RowsAffected(), nil
+}
+
+func scanEntries(rows pgx.Rows) ([]Entry, error) {
+	var entries []Entry
+	for rows.Next() {
+		var e Entry
+		if err := rows.Scan(
+			&e.ID,
+			&e.ActorID,
+			&e.Action,
+			&e.TargetType,
+			&e.TargetID,
+			&e.TeamID,
+			&e.Metadata,
+			&e.IP,
+			&e.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		entries = append(entries, e)
+	}
+	return entries, rows.Err()
+}
+
+var _ AuditStore = (*PGAuditStore)(nil)
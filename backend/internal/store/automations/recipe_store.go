@@ -0,0 +1,250 @@
+package store
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/diagnosis/interactive-todo/internal/automation"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type Recipe struct {
+	ID         uuid.UUID             `json:"id"`
+	TeamID     uuid.UUID             `json:"team_id"`
+	Name       string                `json:"name"`
+	Definition automation.Definition `json:"definition"`
+	Enabled    bool                  `json:"enabled"`
+	CreatedBy  *uuid.UUID            `json:"created_by,omitempty"`
+	CreatedAt  time.Time             `json:"created_at"`
+	UpdatedAt  time.Time             `json:"updated_at"`
+}
+
+var (
+	ErrRecipeNotFound  = errors.New("automation recipe not found")
+	ErrRecipeNameTaken = errors.New("automation recipe name already used in team")
+)
+
+type RecipeStore interface {
+	Create(ctx context.Context, teamID uuid.UUID, name string, def automation.Definition, enabled bool, createdBy uuid.UUID, now time.Time) (*Recipe, error)
+	Update(ctx context.Context, teamID, id uuid.UUID, name string, def automation.Definition, enabled bool, now time.Time) (*Recipe, error)
+	ListForTeam(ctx context.Context, teamID uuid.UUID) ([]Recipe, error)
+	Delete(ctx context.Context, teamID, id uuid.UUID) error
+	// ListEnabled returns the team's enabled recipes with the given trigger.
+	ListEnabled(ctx context.Context, teamID uuid.UUID, trigger automation.TriggerType) ([]Recipe, error)
+	// ListDueSoon returns every enabled due_soon recipe, across teams.
+	ListDueSoon(ctx context.Context) ([]Recipe, error)
+	// ClaimDueSoon records a firing for each open or in-progress task of the
+	// recipe's team due within its window, and returns the tasks that had
+	// none yet for their current due date.
+	ClaimDueSoon(ctx context.Context, r Recipe, now time.Time) ([]uuid.UUID, error)
+}
+
+// NOTE: order must match scanRecipe
+const recipeColumns = `
+    id,
+    team_id,
+    name,
+    definition,
+    enabled,
+    created_by,
+    created_at,
+    updated_at
+`
+
+type PGRecipeStore struct {
+	pool *pgxpool.Pool
+}
+
+func NewPGRecipeStore(pool *pgxpool.Pool) *PGRecipeStore {
+	return &PGRecipeStore{pool: pool}
+}
+
+func (s *PGRecipeStore) Create(
+	ctx context.Context,
+	teamID uuid.UUID,
+	name string,
+	def automation.Definition,
+	enabled bool,
+	createdBy uuid.UUID,
+	now time.Time,
+) (*Recipe, error) {
+	if err := def.Validate(); err != nil {
+		return nil, err
+	}
+
+	const q = `
+		INSERT INTO automation_recipes (team_id, name, definition, enabled, created_by, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $6)
+		RETURNING ` + recipeColumns
+
+	rec, err := scanRecipe(s.pool.QueryRow(ctx, q, teamID, name, def, enabled, createdBy, now.UTC()))
+	if err != nil {
+		if isUniqueViolation(err) {
+			return nil, ErrRecipeNameTaken
+		}
+		return nil, fmt.Errorf("create recipe team_id=%s: %w", teamID, err)
+	}
+	return rec, nil
+}
+
+func (s *PGRecipeStore) Update(
+	ctx context.Context,
+	teamID, id uuid.UUID,
+	name string,
+	def automation.Definition,
+	enabled bool,
+	now time.Time,
+) (*Recipe, error) {
+	if err := def.Validate(); err != nil {
+		return nil, err
+	}
+
+	const q = `
+		UPDATE automation_recipes
+		SET name       = $3,
+		    definition = $4,
+		    enabled    = $5,
+		    updated_at = $6
+		WHERE team_id = $1 AND id = $2
+		RETURNING ` + recipeColumns
+
+	rec, err := scanRecipe(s.pool.QueryRow(ctx, q, teamID, id, name, def, enabled, now.UTC()))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrRecipeNotFound
+		}
+		if isUniqueViolation(err) {
+			return nil, ErrRecipeNameTaken
+		}
+		return nil, fmt.Errorf("update recipe id=%s: %w", id, err)
+	}
+	return rec, nil
+}
+
+func (s *PGRecipeStore) ListForTeam(ctx context.Context, teamID uuid.UUID) ([]Recipe, error) {
+	const q = `
+		SELECT ` + recipeColumns + `
+		FROM automation_recipes
+		WHERE team_id = $1
+		ORDER BY name
+	`
+	return s.list(ctx, "list recipes", q, teamID)
+}
+
+func (s *PGRecipeStore) ListEnabled(ctx context.Context, teamID uuid.UUID, trigger automation.TriggerType) ([]Recipe, error) {
+	const q = `
+		SELECT ` + recipeColumns + `
+		FROM automation_recipes
+		WHERE team_id = $1
+		  AND enabled
+		  AND definition->'trigger'->>'type' = $2
+		ORDER BY created_at, id
+	`
+	return s.list(ctx, "list enabled recipes", q, teamID, string(trigger))
+}
+
+func (s *PGRecipeStore) ListDueSoon(ctx context.Context) ([]Recipe, error) {
+	const q = `
+		SELECT ` + recipeColumns + `
+		FROM automation_recipes
+		WHERE enabled
+		  AND definition->'trigger'->>'type' = 'due_soon'
+		ORDER BY team_id, created_at
+	`
+	return s.list(ctx, "list due soon recipes", q)
+}
+
+func (s *PGRecipeStore) list(ctx context.Context, op, q string, args ...any) ([]Recipe, error) {
+	rows, err := s.pool.Query(ctx, q, args...)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	defer rows.Close()
+
+	out := []Recipe{}
+	for rows.Next() {
+		rec, err := scanRecipe(rows)
+		if err != nil {
+			return nil, fmt.Errorf("scan recipe: %w", err)
+		}
+		out = append(out, *rec)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	return out, nil
+}
+
+func (s *PGRecipeStore) ClaimDueSoon(ctx context.Context, r Recipe, now time.Time) ([]uuid.UUID, error) {
+	window := time.Duration(r.Definition.Trigger.WithinHours) * time.Hour
+	const q = `
+		INSERT INTO automation_firings (recipe_id, task_id, team_id, due_at, fired_at)
+		SELECT $1, t.id, t.team_id, t.due_at, $3
+		FROM tasks t
+		WHERE t.team_id = $2
+		  AND t.status IN ('open', 'in_progress')
+		  AND t.deleted_at IS NULL
+		  AND t.due_at > $3
+		  AND t.due_at <= $4
+		ON CONFLICT (recipe_id, task_id, due_at) DO NOTHING
+		RETURNING task_id
+	`
+	rows, err := s.pool.Query(ctx, q, r.ID, r.TeamID, now.UTC(), now.Add(window).UTC())
+	if err != nil {
+		return nil, fmt.Errorf("claim due soon recipe_id=%s: %w", r.ID, err)
+	}
+	defer rows.Close()
+
+	var ids []uuid.UUID
+	for rows.Next() {
+		var id uuid.UUID
+		if err := rows.Scan(&id); err != nil {
+			return nil, fmt.Errorf("scan due soon task: %w", err)
+		}
+		ids = append(ids, id)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("claim due soon recipe_id=%s: %w", r.ID, err)
+	}
+	return ids, nil
+}
+
+func (s *PGRecipeStore) Delete(ctx context.Context, teamID, id uuid.UUID) error {
+	ct, err := s.pool.Exec(ctx, `DELETE FROM automation_recipes WHERE team_id = $1 AND id = $2`, teamID, id)
+	if err != nil {
+		return fmt.Errorf("delete recipe id=%s: %w", id, err)
+	}
+	if ct.RowsAffected() == 0 {
+		return ErrRecipeNotFound
+	}
+	return nil
+}
+
+func scanRecipe(row pgx.Row) (*Recipe, error) {
+	var rec Recipe
+	if err := row.Scan(
+		&rec.ID,
+		&rec.TeamID,
+		&rec.Name,
+		&rec.Definition,
+		&rec.Enabled,
+		&rec.CreatedBy,
+		&rec.CreatedAt,
+		&rec.UpdatedAt,
+	); err != nil {
+		return nil, err
+	}
+	return &rec, nil
+}
+
+func isUniqueViolation(err error) bool {
+	var pgErr *pgconn.PgError
+	return errors.As(err, &pgErr) && pgErr.Code == "23505"
+}
+
+var _ RecipeStore = (*PGRecipeStore)(nil)
@@ -0,0 +1,219 @@
+package store
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/diagnosis/interactive-todo/api/types"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type Status = types.BackupStatus
+
+const (
+	StatusPending Status = "pending"
+	StatusRunning Status = "running"
+	StatusDone    Status = "done"
+	StatusFailed  Status = "failed"
+)
+
+type Export = types.BackupExport
+
+var (
+	ErrExportNotFound   = errors.New("backup export not found")
+	ErrExportInProgress = errors.New("an export of this scope is already pending or running")
+	ErrTeamNotFound     = errors.New("team not found")
+)
+
+type BackupStore interface {
+	Request(ctx context.Context, teamID *uuid.UUID, requestedBy uuid.UUID, now time.Time) (*Export, error)
+	Get(ctx context.Context, id uuid.UUID) (*Export, error)
+	List(ctx context.Context, limit, offset int) ([]Export, error)
+	// Claim marks the oldest pending export running and returns it, or
+	// nil when there is none. Concurrent callers get different exports.
+	Claim(ctx context.Context, now time.Time) (*Export, error)
+	Finish(ctx context.Context, id uuid.UUID, objectKey string, sizeBytes, rowCount int64, now time.Time) error
+	Fail(ctx context.Context, id uuid.UUID, reason string, now time.Time) error
+	// FailStale fails exports still running since before cutoff, left
+	// behind by a process that died mid-export.
+	FailStale(ctx context.Context, cutoff, now time.Time) (int64, error)
+}
+
+// NOTE: order must match scanExport
+const exportColumns = `
+    id,
+    team_id,
+    status,
+    object_key,
+    size_bytes,
+    row_count,
+    error,
+    requested_by,
+    created_at,
+    started_at,
+    finished_at
+`
+
+func scanExport(row pgx.Row) (*Export, error) {
+	var e Export
+	if err := row.Scan(
+		&e.ID,
+		&e.TeamID,
+		&e.Status,
+		&e.ObjectKey,
+		&e.SizeBytes,
+		&e.RowCount,
+		&e.Error,
+		&e.RequestedBy,
+		&e.CreatedAt,
+		&e.StartedAt,
+		&e.FinishedAt,
+	); err != nil {
+		return nil, err
+	}
+	return &e, nil
+}
+
+type PGBackupStore struct {
+	pool *pgxpool.Pool
+}
+
+func NewPGBackupStore(pool *pgxpool.Pool) *PGBackupStore {
+	return &PGBackupStore{pool: pool}
+}
+
+var _ BackupStore = (*PGBackupStore)(nil)
+
+func (s *PGBackupStore) Request(ctx context.Context, teamID *uuid.UUID, requestedBy uuid.UUID, now time.Time) (*Export, error) {
+	const q = `
+		INSERT INTO backup_exports (team_id, requested_by, created_at)
+		SELECT $1, $2, $3
+		WHERE $1::uuid IS NULL OR EXISTS (SELECT 1 FROM teams WHERE id = $1)
+		RETURNING ` + exportColumns
+
+	e, err := scanExport(s.pool.QueryRow(ctx, q, teamID, requestedBy, now.UTC()))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrTeamNotFound
+		}
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == "23505" {
+			return nil, ErrExportInProgress
+		}
+		return nil, fmt.Errorf("request backup export: %w", err)
+	}
+	return e, nil
+}
+
+func (s *PGBackupStore) Get(ctx context.Context, id uuid.UUID) (*Export, error) {
+	const q = `SELECT ` + exportColumns + ` FROM backup_exports WHERE id = $1`
+
+	e, err := scanExport(s.pool.QueryRow(ctx, q, id))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrExportNotFound
+		}
+		return nil, fmt.Errorf("get backup export id=%s: %w", id, err)
+	}
+	return e, nil
+}
+
+func (s *PGBackupStore) List(ctx context.Context, limit, offset int) ([]Export, error) {
+	const q = `
+		SELECT ` + exportColumns + `
+		FROM backup_exports
+		ORDER BY created_at DESC, id DESC
+		LIMIT $1 OFFSET $2
+	`
+	rows, err := s.pool.Query(ctx, q, limit, offset)
+	if err != nil {
+		return nil, fmt.Errorf("list backup exports: %w", err)
+	}
+	defer rows.Close()
+
+	out := []Export{}
+	for rows.Next() {
+		e, err := scanExport(rows)
+		if err != nil {
+			return nil, fmt.Errorf("list backup exports: scan: %w", err)
+		}
+		out = append(out, *e)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("list backup exports: %w", err)
+	}
+	return out, nil
+}
+
+func (s *PGBackupStore) Claim(ctx context.Context, now time.Time) (*Export, error) {
+	const q = `
+		UPDATE backup_exports
+		SET status = 'running', started_at = $1
+		WHERE id = (
+			SELECT id FROM backup_exports
+			WHERE status = 'pending'
+			ORDER BY created_at
+			LIMIT 1
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING ` + exportColumns
+
+	e, err := scanExport(s.pool.QueryRow(ctx, q, now.UTC()))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("claim backup export: %w", err)
+	}
+	return e, nil
+}
+
+func (s *PGBackupStore) Finish(ctx context.Context, id uuid.UUID, objectKey string, sizeBytes, rowCount int64, now time.Time) error {
+	const q = `
+		UPDATE backup_exports
+		SET status = 'done', object_key = $2, size_bytes = $3, row_count = $4, finished_at = $5
+		WHERE id = $1 AND status = 'running'
+	`
+	tag, err := s.pool.Exec(ctx, q, id, objectKey, sizeBytes, rowCount, now.UTC())
+	if err != nil {
+		return fmt.Errorf("finish backup export id=%s: %w", id, err)
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrExportNotFound
+	}
+	return nil
+}
+
+func (s *PGBackupStore) Fail(ctx context.Context, id uuid.UUID, reason string, now time.Time) error {
+	const q = `
+		UPDATE backup_exports
+		SET status = 'failed', error = $2, finished_at = $3
+		WHERE id = $1 AND status = 'running'
+	`
+	tag, err := s.pool.Exec(ctx, q, id, reason, now.UTC())
+	if err != nil {
+		return fmt.Errorf("fail backup export id=%s: %w", id, err)
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrExportNotFound
+	}
+	return nil
+}
+
+func (s *PGBackupStore) FailStale(ctx context.Context, cutoff, now time.Time) (int64, error) {
+	const q = `
+		UPDATE backup_exports
+		SET status = 'failed', error = 'interrupted', finished_at = $2
+		WHERE status = 'running' AND started_at < $1
+	`
+	tag, err := s.pool.Exec(ctx, q, cutoff.UTC(), now.UTC())
+	if err != nil {
+		return 0, fmt.Errorf("fail stale backup exports: %w", err)
+	}
+	return tag.RowsAffected(), nil
+}
@@ -0,0 +1,94 @@
+package store
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// Feed is the current token generation of a user's calendar feed.
+type Feed struct {
+	UserID     uuid.UUID
+	Generation int64
+	CreatedAt  time.Time
+}
+
+var ErrFeedNotFound = errors.New("calendar feed not found")
+
+type CalendarFeedStore interface {
+	// Get returns the user's feed if it is enabled.
+	Get(ctx context.Context, userID uuid.UUID) (*Feed, error)
+	// Rotate enables the user's feed under a new generation.
+	Rotate(ctx context.Context, userID uuid.UUID, now time.Time) (*Feed, error)
+	// Disable turns the feed off; it returns ErrFeedNotFound if it was not
+	// on.
+	Disable(ctx context.Context, userID uuid.UUID) error
+}
+
+type PGCalendarFeedStore struct {
+	pool *pgxpool.Pool
+}
+
+func NewPGCalendarFeedStore(pool *pgxpool.Pool) *PGCalendarFeedStore {
+	return &PGCalendarFeedStore{pool: pool}
+}
+
+func (s *PGCalendarFeedStore) Get(ctx context.Context, userID uuid.UUID) (*Feed, error) {
+	const q = `
+		SELECT user_id, generation, created_at
+		FROM calendar_feeds
+		WHERE user_id = $1 AND enabled
+	`
+
+	var f Feed
+	if err := s.pool.QueryRow(ctx, q, userID).Scan(&f.UserID, &f.Generation, &f.CreatedAt); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrFeedNotFound
+		}
+		return nil, fmt.Errorf("get calendar feed user_id=%s: %w", userID, err)
+	}
+	return &f, nil
+}
+
+func (s *PGCalendarFeedStore) Rotate(ctx context.Context, userID uuid.UUID, now time.Time) (*Feed, error) {
+	const q = `
+		INSERT INTO calendar_feeds (user_id, generation, enabled, created_at)
+		VALUES ($1, 1, true, $2)
+		ON CONFLICT (user_id) DO UPDATE
+		SET generation = calendar_feeds.generation + 1,
+		    enabled    = true,
+		    created_at = EXCLUDED.created_at
+		RETURNING user_id, generation, created_at
+	`
+
+	var f Feed
+	if err := s.pool.QueryRow(ctx, q, userID, now.UTC()).Scan(&f.UserID, &f.Generation, &f.CreatedAt); err != nil {
+		return nil, fmt.Errorf("rotate calendar feed user_id=%s: %w", userID, err)
+	}
+	return &f, nil
+}
+
+func (s *PGCalendarFeedStore) Disable(ctx context.Context, userID uuid.UUID) error {
+	const q = `
+		UPDATE calendar_feeds
+		SET enabled = false,
+		    generation = generation + 1
+		WHERE user_id = $1 AND enabled
+	`
+
+	ct, err := s.pool.Exec(ctx, q, userID)
+	if err != nil {
+		return fmt.Errorf("disable calendar feed user_id=%s: %w", userID, err)
+	}
+	if ct.RowsAffected() == 0 {
+		return ErrFeedNotFound
+	}
+	return nil
+}
+
+var _ CalendarFeedStore = (*PGCalendarFeedStore)(nil)
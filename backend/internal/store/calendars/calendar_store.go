@@ -0,0 +1,169 @@
+package store
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/diagnosis/interactive-todo/api/types"
+	"github.com/diagnosis/interactive-todo/internal/calendar"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type TeamCalendar = types.TeamCalendar
+
+type Holiday = types.Holiday
+
+var (
+	ErrHolidayExists   = errors.New("holiday already exists")
+	ErrHolidayNotFound = errors.New("holiday not found")
+)
+
+type CalendarStore interface {
+	// Get returns the defaults for teams that never configured a calendar.
+	Get(ctx context.Context, teamID uuid.UUID) (*TeamCalendar, error)
+	Put(ctx context.Context, teamID uuid.UUID, in types.PutCalendarRequest, now time.Time) (*TeamCalendar, error)
+	AddHoliday(ctx context.Context, teamID uuid.UUID, day time.Time, name string, now time.Time) (*Holiday, error)
+	DeleteHoliday(ctx context.Context, teamID uuid.UUID, day time.Time) error
+	// Load is Get turned into a calendar.Calendar for date arithmetic.
+	Load(ctx context.Context, teamID uuid.UUID) (*calendar.Calendar, error)
+}
+
+type PGCalendarStore struct {
+	pool *pgxpool.Pool
+}
+
+func NewPGCalendarStore(pool *pgxpool.Pool) *PGCalendarStore {
+	return &PGCalendarStore{pool: pool}
+}
+
+func (s *PGCalendarStore) Get(ctx context.Context, teamID uuid.UUID) (*TeamCalendar, error) {
+	const q = `
+		SELECT timezone, weekend_days, due_date_policy, updated_at
+		FROM team_calendars
+		WHERE team_id = $1
+	`
+	tc := TeamCalendar{
+		TeamID:        teamID,
+		Timezone:      "UTC",
+		WeekendDays:   []int{int(time.Sunday), int(time.Saturday)},
+		DueDatePolicy: calendar.PolicyOff,
+	}
+	var updatedAt time.Time
+	err := s.pool.QueryRow(ctx, q, teamID).Scan(&tc.Timezone, &tc.WeekendDays, &tc.DueDatePolicy, &updatedAt)
+	if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+		return nil, fmt.Errorf("get calendar team_id=%s: %w", teamID, err)
+	}
+	if err == nil {
+		tc.UpdatedAt = &updatedAt
+	}
+
+	holidays, err := s.listHolidays(ctx, teamID)
+	if err != nil {
+		return nil, err
+	}
+	tc.Holidays = holidays
+	return &tc, nil
+}
+
+// Put validates through calendar.New so an unusable calendar is never
+// stored; the error wraps calendar.ErrInvalid.
+func (s *PGCalendarStore) Put(ctx context.Context, teamID uuid.UUID, in types.PutCalendarRequest, now time.Time) (*TeamCalendar, error) {
+	if _, err := calendar.New(in.Timezone, in.WeekendDays, in.DueDatePolicy); err != nil {
+		return nil, err
+	}
+	if in.WeekendDays == nil {
+		in.WeekendDays = []int{}
+	}
+
+	const q = `
+		INSERT INTO team_calendars (team_id, timezone, weekend_days, due_date_policy, updated_at)
+		VALUES ($1, $2, $3, $4, $5)
+		ON CONFLICT (team_id) DO UPDATE
+		SET timezone        = EXCLUDED.timezone,
+		    weekend_days    = EXCLUDED.weekend_days,
+		    due_date_policy = EXCLUDED.due_date_policy,
+		    updated_at      = EXCLUDED.updated_at
+	`
+	if _, err := s.pool.Exec(ctx, q, teamID, in.Timezone, in.WeekendDays, in.DueDatePolicy, now.UTC()); err != nil {
+		return nil, fmt.Errorf("put calendar team_id=%s: %w", teamID, err)
+	}
+	return s.Get(ctx, teamID)
+}
+
+func (s *PGCalendarStore) AddHoliday(ctx context.Context, teamID uuid.UUID, day time.Time, name string, now time.Time) (*Holiday, error) {
+	const q = `
+		INSERT INTO team_holidays (team_id, day, name, created_at)
+		VALUES ($1, $2, $3, $4)
+	`
+	d := day.Format(calendar.DateLayout)
+	if _, err := s.pool.Exec(ctx, q, teamID, d, name, now.UTC()); err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == "23505" {
+			return nil, ErrHolidayExists
+		}
+		return nil, fmt.Errorf("add holiday team_id=%s day=%s: %w", teamID, d, err)
+	}
+	return &Holiday{Day: d, Name: name}, nil
+}
+
+func (s *PGCalendarStore) DeleteHoliday(ctx context.Context, teamID uuid.UUID, day time.Time) error {
+	const q = `DELETE FROM team_holidays WHERE team_id = $1 AND day = $2`
+	d := day.Format(calendar.DateLayout)
+	res, err := s.pool.Exec(ctx, q, teamID, d)
+	if err != nil {
+		return fmt.Errorf("delete holiday team_id=%s day=%s: %w", teamID, d, err)
+	}
+	if res.RowsAffected() == 0 {
+		return ErrHolidayNotFound
+	}
+	return nil
+}
+
+func (s *PGCalendarStore) Load(ctx context.Context, teamID uuid.UUID) (*calendar.Calendar, error) {
+	tc, err := s.Get(ctx, teamID)
+	if err != nil {
+		return nil, err
+	}
+	cal, err := calendar.New(tc.Timezone, tc.WeekendDays, tc.DueDatePolicy)
+	if err != nil {
+		return nil, fmt.Errorf("load calendar team_id=%s: %w", teamID, err)
+	}
+	for _, h := range tc.Holidays {
+		cal.Holidays[h.Day] = h.Name
+	}
+	return cal, nil
+}
+
+func (s *PGCalendarStore) listHolidays(ctx context.Context, teamID uuid.UUID) ([]Holiday, error) {
+	const q = `
+		SELECT to_char(day, 'YYYY-MM-DD'), name
+		FROM team_holidays
+		WHERE team_id = $1
+		ORDER BY day
+	`
+	rows, err := s.pool.Query(ctx, q, teamID)
+	if err != nil {
+		return nil, fmt.Errorf("list holidays team_id=%s: %w", teamID, err)
+	}
+	defer rows.Close()
+
+	holidays := []Holiday{}
+	for rows.Next() {
+		var h Holiday
+		if err := rows.Scan(&h.Day, &h.Name); err != nil {
+			return nil, fmt.Errorf("scan holiday team_id=%s: %w", teamID, err)
+		}
+		holidays = append(holidays, h)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("list holidays team_id=%s: %w", teamID, err)
+	}
+	return holidays, nil
+}
+
+var _ CalendarStore = (*PGCalendarStore)(nil)
@@ -0,0 +1,215 @@
+package store
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/diagnosis/interactive-todo/api/types"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type Comment = types.Comment
+type CommentRevision = types.CommentRevision
+
+var ErrCommentNotFound = errors.New("comment not found")
+
+// MaxBodyLength bounds a comment; longer notes belong in the description.
+const MaxBodyLength = 5000
+
+type CommentStore interface {
+	// Create adds a comment by authorID, or by recipeID when authorID is nil.
+	Create(ctx context.Context, taskID uuid.UUID, authorID, recipeID *uuid.UUID, body string, now time.Time) (*Comment, error)
+	// ListForTask returns a task's comments, oldest first.
+	ListForTask(ctx context.Context, taskID uuid.UUID) ([]Comment, error)
+	GetByID(ctx context.Context, id uuid.UUID) (*Comment, error)
+	// Edit replaces the body and keeps the previous one as a revision.
+	Edit(ctx context.Context, id, editorID uuid.UUID, body string, now time.Time) (*Comment, error)
+	// ListRevisions returns a comment's earlier bodies, oldest first.
+	ListRevisions(ctx context.Context, id uuid.UUID) ([]CommentRevision, error)
+	ListRecentBodiesByAuthor(ctx context.Context, authorID uuid.UUID, since time.Time) ([]string, error)
+}
+
+// NOTE: order must match scanComment
+const commentColumns = `
+    id,
+    task_id,
+    team_id,
+    author_id,
+    recipe_id,
+    body,
+    edited_at,
+    created_at
+`
+
+type PGCommentStore struct {
+	pool *pgxpool.Pool
+}
+
+func NewPGCommentStore(pool *pgxpool.Pool) *PGCommentStore {
+	return &PGCommentStore{pool: pool}
+}
+
+func (s *PGCommentStore) Create(ctx context.Context, taskID uuid.UUID, authorID, recipeID *uuid.UUID, body string, now time.Time) (*Comment, error) {
+	const q = `
+		INSERT INTO task_comments (task_id, author_id, recipe_id, body, created_at)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING ` + commentColumns
+
+	c, err := scanComment(s.pool.QueryRow(ctx, q, taskID, authorID, recipeID, body, now.UTC()))
+	if err != nil {
+		return nil, fmt.Errorf("create comment task_id=%s: %w", taskID, err)
+	}
+	return c, nil
+}
+
+func (s *PGCommentStore) ListForTask(ctx context.Context, taskID uuid.UUID) ([]Comment, error) {
+	const q = `
+		SELECT ` + commentColumns + `
+		FROM task_comments
+		WHERE task_id = $1
+		ORDER BY created_at, id
+	`
+	rows, err := s.pool.Query(ctx, q, taskID)
+	if err != nil {
+		return nil, fmt.Errorf("list comments task_id=%s: %w", taskID, err)
+	}
+	defer rows.Close()
+
+	comments := []Comment{}
+	for rows.Next() {
+		c, err := scanComment(rows)
+		if err != nil {
+			return nil, fmt.Errorf("scan comment: %w", err)
+		}
+		comments = append(comments, *c)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("list comments task_id=%s: %w", taskID, err)
+	}
+	return comments, nil
+}
+
+func (s *PGCommentStore) GetByID(ctx context.Context, id uuid.UUID) (*Comment, error) {
+	const q = `
+		SELECT ` + commentColumns + `
+		FROM task_comments
+		WHERE id = $1
+	`
+	c, err := scanComment(s.pool.QueryRow(ctx, q, id))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrCommentNotFound
+		}
+		return nil, fmt.Errorf("get comment id=%s: %w", id, err)
+	}
+	return c, nil
+}
+
+func (s *PGCommentStore) Edit(ctx context.Context, id, editorID uuid.UUID, body string, now time.Time) (*Comment, error) {
+	// The row lock in old makes a concurrent edit wait and then copy the
+	// body this one wrote, so no revision is lost.
+	const q = `
+		WITH old AS (
+			SELECT id, body, COALESCE(edited_at, created_at) AS written_at
+			FROM task_comments
+			WHERE id = $1
+			FOR UPDATE
+		), rev AS (
+			INSERT INTO comment_revisions (comment_id, body, written_at, replaced_at, replaced_by)
+			SELECT id, body, written_at, $3, $4
+			FROM old
+		)
+		UPDATE task_comments
+		SET body      = $2,
+		    edited_at = $3
+		WHERE id = $1
+		RETURNING ` + commentColumns
+
+	c, err := scanComment(s.pool.QueryRow(ctx, q, id, body, now.UTC(), editorID))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrCommentNotFound
+		}
+		return nil, fmt.Errorf("edit comment id=%s: %w", id, err)
+	}
+	return c, nil
+}
+
+func (s *PGCommentStore) ListRevisions(ctx context.Context, id uuid.UUID) ([]CommentRevision, error) {
+	const q = `
+		SELECT id, body, written_at, replaced_at, replaced_by
+		FROM comment_revisions
+		WHERE comment_id = $1
+		ORDER BY replaced_at, id
+	`
+	rows, err := s.pool.Query(ctx, q, id)
+	if err != nil {
+		return nil, fmt.Errorf("list comment revisions id=%s: %w", id, err)
+	}
+	defer rows.Close()
+
+	revs := []CommentRevision{}
+	for rows.Next() {
+		var rev CommentRevision
+		if err := rows.Scan(&rev.ID, &rev.Body, &rev.WrittenAt, &rev.ReplacedAt, &rev.ReplacedBy); err != nil {
+			return nil, fmt.Errorf("scan comment revision: %w", err)
+		}
+		revs = append(revs, rev)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("list comment revisions id=%s: %w", id, err)
+	}
+	return revs, nil
+}
+
+// ListRecentBodiesByAuthor feeds the spam guard; like the task titles it
+// reads, it is capped so the check stays cheap.
+func (s *PGCommentStore) ListRecentBodiesByAuthor(ctx context.Context, authorID uuid.UUID, since time.Time) ([]string, error) {
+	const q = `
+		SELECT body
+		FROM task_comments
+		WHERE author_id = $1
+		  AND created_at >= $2
+		ORDER BY created_at DESC
+		LIMIT 1000
+	`
+	rows, err := s.pool.Query(ctx, q, authorID, since.UTC())
+	if err != nil {
+		return nil, fmt.Errorf("list recent comments author_id=%s: %w", authorID, err)
+	}
+	defer rows.Close()
+
+	var bodies []string
+	for rows.Next() {
+		var b string
+		if err := rows.Scan(&b); err != nil {
+			return nil, fmt.Errorf("scan comment body: %w", err)
+		}
+		bodies = append(bodies, b)
+	}
+	return bodies, rows.Err()
+}
+
+func scanComment(row pgx.Row) (*Comment, error) {
+	var c Comment
+	if err := row.Scan(
+		&c.ID,
+		&c.TaskID,
+		&c.TeamID,
+		&c.AuthorID,
+		&c.RecipeID,
+		&c.Body,
+		&c.EditedAt,
+		&c.CreatedAt,
+	); err != nil {
+		return nil, err
+	}
+	c.Edited = c.EditedAt != nil
+	return &c, nil
+}
+
+var _ CommentStore = (*PGCommentStore)(nil)
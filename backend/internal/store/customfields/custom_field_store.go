@@ -0,0 +1,253 @@
+package store
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/diagnosis/interactive-todo/api/types"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type CustomField = types.CustomField
+
+var (
+	ErrFieldNotFound = errors.New("custom field not found")
+	ErrFieldExists   = errors.New("custom field key already in use")
+	ErrTooManyFields = fmt.Errorf("%w: a team can have at most %d custom fields", ErrInvalidField, maxFieldsTotal)
+)
+
+type CustomFieldStore interface {
+	// List returns the team's fields in the order they were created.
+	List(ctx context.Context, teamID uuid.UUID) ([]CustomField, error)
+	Get(ctx context.Context, teamID, fieldID uuid.UUID) (*CustomField, error)
+	Create(ctx context.Context, teamID uuid.UUID, in types.CreateCustomFieldRequest, createdBy uuid.UUID, now time.Time) (*CustomField, error)
+	// Update changes the parts of in that are set. Values tasks already
+	// hold are kept, even when they are no longer among the options.
+	Update(ctx context.Context, teamID, fieldID uuid.UUID, in types.UpdateCustomFieldRequest, now time.Time) (*CustomField, error)
+	// Delete removes the field and its values from the team's tasks.
+	Delete(ctx context.Context, teamID, fieldID uuid.UUID) error
+}
+
+type PGCustomFieldStore struct {
+	pool *pgxpool.Pool
+}
+
+func NewPGCustomFieldStore(pool *pgxpool.Pool) *PGCustomFieldStore {
+	return &PGCustomFieldStore{pool: pool}
+}
+
+var _ CustomFieldStore = (*PGCustomFieldStore)(nil)
+
+// NOTE: order must match scanField
+const fieldColumns = `
+    id,
+    team_id,
+    key,
+    label,
+    type,
+    options,
+    required,
+    created_by,
+    created_at,
+    updated_at
+`
+
+func scanField(row pgx.Row) (*CustomField, error) {
+	var f CustomField
+	err := row.Scan(
+		&f.ID,
+		&f.TeamID,
+		&f.Key,
+		&f.Label,
+		&f.Type,
+		&f.Options,
+		&f.Required,
+		&f.CreatedBy,
+		&f.CreatedAt,
+		&f.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &f, nil
+}
+
+func isUniqueViolation(err error) bool {
+	var pgErr *pgconn.PgError
+	return errors.As(err, &pgErr) && pgErr.Code == "23505"
+}
+
+func (s *PGCustomFieldStore) List(ctx context.Context, teamID uuid.UUID) ([]CustomField, error) {
+	q := `
+		SELECT ` + fieldColumns + `
+		FROM team_custom_fields
+		WHERE team_id = $1
+		ORDER BY created_at, key
+	`
+	rows, err := s.pool.Query(ctx, q, teamID)
+	if err != nil {
+		return nil, fmt.Errorf("list custom fields team_id=%s: %w", teamID, err)
+	}
+	defer rows.Close()
+
+	fields := []CustomField{}
+	for rows.Next() {
+		f, err := scanField(rows)
+		if err != nil {
+			return nil, fmt.Errorf("scan custom field: %w", err)
+		}
+		fields = append(fields, *f)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("list custom fields team_id=%s: %w", teamID, err)
+	}
+	return fields, nil
+}
+
+func (s *PGCustomFieldStore) Get(ctx context.Context, teamID, fieldID uuid.UUID) (*CustomField, error) {
+	q := `
+		SELECT ` + fieldColumns + `
+		FROM team_custom_fields
+		WHERE id = $1 AND team_id = $2
+	`
+	f, err := scanField(s.pool.QueryRow(ctx, q, fieldID, teamID))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrFieldNotFound
+		}
+		return nil, fmt.Errorf("get custom field id=%s: %w", fieldID, err)
+	}
+	return f, nil
+}
+
+func (s *PGCustomFieldStore) Create(
+	ctx context.Context,
+	teamID uuid.UUID,
+	in types.CreateCustomFieldRequest,
+	createdBy uuid.UUID,
+	now time.Time,
+) (*CustomField, error) {
+	f := CustomField{Key: in.Key, Label: in.Label, Type: in.Type, Options: in.Options, Required: in.Required}
+	if err := ValidateField(&f); err != nil {
+		return nil, err
+	}
+	if f.Options == nil {
+		f.Options = []string{}
+	}
+
+	// the limit is checked by the insert itself; concurrent creates may
+	// take a team a field or two over it, which is harmless
+	q := `
+		INSERT INTO team_custom_fields (team_id, key, label, type, options, required, created_by, created_at, updated_at)
+		SELECT $1, $2, $3, $4, $5, $6, $7, $8, $8
+		WHERE (SELECT count(*) FROM team_custom_fields WHERE team_id = $1) < $9
+		RETURNING ` + fieldColumns
+
+	out, err := scanField(s.pool.QueryRow(ctx, q,
+		teamID, f.Key, f.Label, f.Type, f.Options, f.Required, createdBy, now.UTC(), maxFieldsTotal))
+	if err != nil {
+		switch {
+		case errors.Is(err, pgx.ErrNoRows):
+			return nil, ErrTooManyFields
+		case isUniqueViolation(err):
+			return nil, ErrFieldExists
+		}
+		return nil, fmt.Errorf("create custom field team_id=%s: %w", teamID, err)
+	}
+	return out, nil
+}
+
+func (s *PGCustomFieldStore) Update(
+	ctx context.Context,
+	teamID, fieldID uuid.UUID,
+	in types.UpdateCustomFieldRequest,
+	now time.Time,
+) (*CustomField, error) {
+	tx, err := s.pool.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("update custom field: begin: %w", err)
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	q := `
+		SELECT ` + fieldColumns + `
+		FROM team_custom_fields
+		WHERE id = $1 AND team_id = $2
+		FOR UPDATE
+	`
+	f, err := scanField(tx.QueryRow(ctx, q, fieldID, teamID))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrFieldNotFound
+		}
+		return nil, fmt.Errorf("update custom field id=%s: %w", fieldID, err)
+	}
+
+	if in.Label != nil {
+		f.Label = *in.Label
+	}
+	if in.Options != nil {
+		f.Options = in.Options
+	}
+	if in.Required != nil {
+		f.Required = *in.Required
+	}
+	if err := ValidateField(f); err != nil {
+		return nil, err
+	}
+
+	const upd = `
+		UPDATE team_custom_fields
+		SET label      = $2,
+		    options    = $3,
+		    required   = $4,
+		    updated_at = $5
+		WHERE id = $1
+		RETURNING ` + fieldColumns
+
+	out, err := scanField(tx.QueryRow(ctx, upd, fieldID, f.Label, f.Options, f.Required, now.UTC()))
+	if err != nil {
+		return nil, fmt.Errorf("update custom field id=%s: %w", fieldID, err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("update custom field: commit: %w", err)
+	}
+	return out, nil
+}
+
+func (s *PGCustomFieldStore) Delete(ctx context.Context, teamID, fieldID uuid.UUID) error {
+	tx, err := s.pool.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("delete custom field: begin: %w", err)
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	var key string
+	err = tx.QueryRow(ctx, `DELETE FROM team_custom_fields WHERE id = $1 AND team_id = $2 RETURNING key`, fieldID, teamID).Scan(&key)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return ErrFieldNotFound
+		}
+		return fmt.Errorf("delete custom field id=%s: %w", fieldID, err)
+	}
+
+	const strip = `
+		UPDATE tasks
+		SET custom_fields = custom_fields - $2::text
+		WHERE team_id = $1 AND custom_fields ? $2
+	`
+	if _, err := tx.Exec(ctx, strip, teamID, key); err != nil {
+		return fmt.Errorf("delete custom field id=%s: strip task values: %w", fieldID, err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("delete custom field: commit: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,143 @@
+package store
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/diagnosis/interactive-todo/api/types"
+)
+
+var (
+	ErrInvalidField = errors.New("invalid custom field")
+	ErrInvalidValue = errors.New("invalid custom field value")
+)
+
+const (
+	maxLabelLen    = 100
+	maxOptions     = 50
+	maxOptionLen   = 100
+	maxTextLen     = 500
+	maxFieldsTotal = 50
+)
+
+var fieldKeyRe = regexp.MustCompile(`^[a-z][a-z0-9_]{0,39}$`)
+
+// ValidateField checks a field definition and trims its label and options.
+func ValidateField(f *CustomField) error {
+	if !fieldKeyRe.MatchString(f.Key) {
+		return fmt.Errorf("%w: key %q must be lowercase letters, digits or underscores", ErrInvalidField, f.Key)
+	}
+	f.Label = strings.TrimSpace(f.Label)
+	if f.Label == "" || utf8.RuneCountInString(f.Label) > maxLabelLen {
+		return fmt.Errorf("%w: field %q needs a label (max %d chars)", ErrInvalidField, f.Key, maxLabelLen)
+	}
+
+	switch f.Type {
+	case types.CustomFieldText, types.CustomFieldNumber, types.CustomFieldDate:
+		if len(f.Options) > 0 {
+			return fmt.Errorf("%w: field %q: options are only valid for select", ErrInvalidField, f.Key)
+		}
+	case types.CustomFieldSelect:
+		if len(f.Options) == 0 || len(f.Options) > maxOptions {
+			return fmt.Errorf("%w: field %q needs 1-%d options", ErrInvalidField, f.Key, maxOptions)
+		}
+		for i, o := range f.Options {
+			o = strings.TrimSpace(o)
+			if o == "" || utf8.RuneCountInString(o) > maxOptionLen {
+				return fmt.Errorf("%w: field %q: options must be 1-%d chars", ErrInvalidField, f.Key, maxOptionLen)
+			}
+			if slices.Contains(f.Options[:i], o) {
+				return fmt.Errorf("%w: field %q: duplicate option %q", ErrInvalidField, f.Key, o)
+			}
+			f.Options[i] = o
+		}
+	default:
+		return fmt.Errorf("%w: field %q has unknown type %q", ErrInvalidField, f.Key, f.Type)
+	}
+	return nil
+}
+
+// CheckValues checks task values, as decoded from JSON, against the team's
+// fields and returns them normalized. Keys that are not a field of the team
+// are refused.
+//
+// For a new task (partial false) every required field must have a value and
+// null values are dropped. For an edit (partial true) only the given keys
+// are checked, and null stays in to clear the field unless it is required.
+func CheckValues(fields []CustomField, values map[string]any, partial bool) (map[string]any, error) {
+	byKey := make(map[string]*CustomField, len(fields))
+	for i := range fields {
+		byKey[fields[i].Key] = &fields[i]
+	}
+
+	out := make(map[string]any, len(values))
+	for k, v := range values {
+		f, ok := byKey[k]
+		if !ok {
+			return nil, fmt.Errorf("%w: unknown field %q", ErrInvalidValue, k)
+		}
+		if v == nil {
+			if f.Required {
+				return nil, fmt.Errorf("%w: %s is required", ErrInvalidValue, f.Label)
+			}
+			if partial {
+				out[k] = nil
+			}
+			continue
+		}
+		nv, err := checkValue(f, v)
+		if err != nil {
+			return nil, err
+		}
+		out[k] = nv
+	}
+
+	if !partial {
+		for _, f := range fields {
+			if _, ok := out[f.Key]; f.Required && !ok {
+				return nil, fmt.Errorf("%w: %s is required", ErrInvalidValue, f.Label)
+			}
+		}
+	}
+	return out, nil
+}
+
+func checkValue(f *CustomField, v any) (any, error) {
+	if f.Type == types.CustomFieldNumber {
+		n, ok := v.(float64)
+		if !ok {
+			return nil, fmt.Errorf("%w: %s must be a number", ErrInvalidValue, f.Label)
+		}
+		return n, nil
+	}
+
+	s, ok := v.(string)
+	if !ok {
+		return nil, fmt.Errorf("%w: %s must be a string", ErrInvalidValue, f.Label)
+	}
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return nil, fmt.Errorf("%w: %s cannot be empty; send null to clear it", ErrInvalidValue, f.Label)
+	}
+
+	switch f.Type {
+	case types.CustomFieldText:
+		if utf8.RuneCountInString(s) > maxTextLen {
+			return nil, fmt.Errorf("%w: %s too long (max %d chars)", ErrInvalidValue, f.Label, maxTextLen)
+		}
+	case types.CustomFieldDate:
+		if _, err := time.Parse(time.DateOnly, s); err != nil {
+			return nil, fmt.Errorf("%w: %s must be a YYYY-MM-DD date", ErrInvalidValue, f.Label)
+		}
+	case types.CustomFieldSelect:
+		if !slices.Contains(f.Options, s) {
+			return nil, fmt.Errorf("%w: %s must be one of %s", ErrInvalidValue, f.Label, strings.Join(f.Options, ", "))
+		}
+	}
+	return s, nil
+}
@@ -0,0 +1,167 @@
+package store
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/diagnosis/interactive-todo/api/types"
+	"github.com/diagnosis/interactive-todo/internal/logger"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type BreakerState = types.BreakerState
+
+const (
+	BreakerClosed   BreakerState = "closed"
+	BreakerOpen     BreakerState = "open"
+	BreakerHalfOpen BreakerState = "half_open"
+)
+
+// Breaker trips after consecutive connectivity failures against Postgres
+// and stays open for Cooldown, during which callers should fail fast. After
+// the cooldown it lets traffic through again (half-open): the next success
+// closes it, the next failure re-opens it.
+//
+// It is fed by the pool's tracer, so every store call counts without the
+// stores knowing about it.
+type Breaker struct {
+	threshold int
+	cooldown  time.Duration
+
+	mu          sync.Mutex
+	state       BreakerState
+	failures    int
+	openedAt    time.Time
+	trips       int64
+	lastFailure string
+}
+
+type BreakerStats = types.BreakerStats
+
+func NewBreaker(threshold int, cooldown time.Duration) *Breaker {
+	return &Breaker{
+		threshold: threshold,
+		cooldown:  cooldown,
+		state:     BreakerClosed,
+	}
+}
+
+// Allow reports whether a request may use the database now.
+func (b *Breaker) Allow() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.state == BreakerOpen && time.Since(b.openedAt) >= b.cooldown {
+		b.state = BreakerHalfOpen
+	}
+	return b.state != BreakerOpen
+}
+
+// RetryAfter is how long until the breaker will let traffic through again.
+func (b *Breaker) RetryAfter() time.Duration {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.state != BreakerOpen {
+		return 0
+	}
+	return max(b.cooldown-time.Since(b.openedAt), 0)
+}
+
+func (b *Breaker) Stats() BreakerStats {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	st := BreakerStats{
+		State:       b.state,
+		Failures:    b.failures,
+		Trips:       b.trips,
+		LastFailure: b.lastFailure,
+	}
+	if b.state != BreakerClosed {
+		openedAt := b.openedAt
+		st.OpenedAt = &openedAt
+	}
+	return st
+}
+
+func (b *Breaker) record(err error) {
+	if !countsAsFailure(err) {
+		if err == nil || isServerAnswer(err) {
+			b.success()
+		}
+		return
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.failures++
+	b.lastFailure = err.Error()
+	if b.state == BreakerHalfOpen || (b.state == BreakerClosed && b.failures >= b.threshold) {
+		b.state = BreakerOpen
+		b.openedAt = time.Now()
+		b.trips++
+		logger.Warn(context.Background(), "db breaker: open", "failures", b.failures, "cooldown", b.cooldown, "err", err)
+	}
+}
+
+func (b *Breaker) success() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.state != BreakerClosed {
+		logger.Info(context.Background(), "db breaker: closed")
+	}
+	b.failures = 0
+	b.state = BreakerClosed
+}
+
+// countsAsFailure is true for errors that say the database is unreachable
+// or too slow. Errors Postgres itself returned (constraint violations, no
+// rows) mean it is up; a client hanging up says nothing about its health.
+func countsAsFailure(err error) bool {
+	if err == nil || errors.Is(err, context.Canceled) {
+		return false
+	}
+	return !isServerAnswer(err)
+}
+
+func isServerAnswer(err error) bool {
+	var pgErr *pgconn.PgError
+	return errors.As(err, &pgErr) || errors.Is(err, pgx.ErrNoRows)
+}
+
+// Tracer returns a pgx tracer that feeds the breaker; set it as
+// ConnConfig.Tracer.
+func (b *Breaker) Tracer() pgx.QueryTracer {
+	return breakerTracer{b: b}
+}
+
+type breakerTracer struct {
+	b *Breaker
+}
+
+func (t breakerTracer) TraceQueryStart(ctx context.Context, _ *pgx.Conn, _ pgx.TraceQueryStartData) context.Context {
+	return ctx
+}
+
+func (t breakerTracer) TraceQueryEnd(_ context.Context, _ *pgx.Conn, data pgx.TraceQueryEndData) {
+	t.b.record(data.Err)
+}
+
+func (t breakerTracer) TraceAcquireStart(ctx context.Context, _ *pgxpool.Pool, _ pgxpool.TraceAcquireStartData) context.Context {
+	return ctx
+}
+
+// TraceAcquireEnd only counts failures: a successful acquire has not talked
+// to the server yet.
+func (t breakerTracer) TraceAcquireEnd(_ context.Context, _ *pgxpool.Pool, data pgxpool.TraceAcquireEndData) {
+	if data.Err != nil {
+		t.b.record(data.Err)
+	}
+}
@@ -0,0 +1,81 @@
+package store
+
+import (
+	"context"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/diagnosis/interactive-todo/internal/logger"
+	"github.com/jackc/pgx/v5"
+)
+
+// ExplainTracer logs the query plan of every distinct SELECT the first time
+// it runs, so plans can be checked against staging data. It costs an extra
+// round trip per new statement and is meant to stay off in production.
+type ExplainTracer struct {
+	seen sync.Map // sql -> struct{}
+}
+
+type explainingKey struct{}
+
+func NewExplainTracer() *ExplainTracer {
+	return &ExplainTracer{}
+}
+
+// ExplainFromEnv reports whether DB_EXPLAIN is set to a true value.
+func ExplainFromEnv() bool {
+	on, _ := strconv.ParseBool(strings.TrimSpace(os.Getenv("DB_EXPLAIN")))
+	return on
+}
+
+func (t *ExplainTracer) TraceQueryStart(ctx context.Context, conn *pgx.Conn, data pgx.TraceQueryStartData) context.Context {
+	if ctx.Value(explainingKey{}) != nil || !isSelect(data.SQL) {
+		return ctx
+	}
+	// a failed EXPLAIN would abort the caller's transaction
+	if conn.PgConn().TxStatus() != 'I' {
+		return ctx
+	}
+	if _, loaded := t.seen.LoadOrStore(data.SQL, struct{}{}); loaded {
+		return ctx
+	}
+
+	plan, err := t.explain(context.WithValue(ctx, explainingKey{}, true), conn, data)
+	if err != nil {
+		logger.Warn(ctx, "explain: failed", "sql", compactSQL(data.SQL), "err", err)
+		return ctx
+	}
+	logger.Info(ctx, "explain: query plan", "sql", compactSQL(data.SQL), "plan", plan)
+	return ctx
+}
+
+func (t *ExplainTracer) TraceQueryEnd(context.Context, *pgx.Conn, pgx.TraceQueryEndData) {}
+
+func (t *ExplainTracer) explain(ctx context.Context, conn *pgx.Conn, data pgx.TraceQueryStartData) (string, error) {
+	rows, err := conn.Query(ctx, "EXPLAIN "+data.SQL, data.Args...)
+	if err != nil {
+		return "", err
+	}
+	defer rows.Close()
+
+	var lines []string
+	for rows.Next() {
+		var line string
+		if err := rows.Scan(&line); err != nil {
+			return "", err
+		}
+		lines = append(lines, line)
+	}
+	return strings.Join(lines, "\n"), rows.Err()
+}
+
+func isSelect(sql string) bool {
+	head := strings.ToUpper(strings.TrimSpace(sql))
+	return strings.HasPrefix(head, "SELECT") || strings.HasPrefix(head, "WITH")
+}
+
+func compactSQL(sql string) string {
+	return strings.Join(strings.Fields(sql), " ")
+}
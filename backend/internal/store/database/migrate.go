@@ -0,0 +1,116 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+
+	"github.com/diagnosis/interactive-todo/internal/logger"
+	_ "github.com/jackc/pgx/v5/stdlib"
+	"github.com/pressly/goose/v3"
+	"github.com/pressly/goose/v3/lock"
+)
+
+// migrationLockID is the pg advisory lock key held while migrating, so
+// replicas starting together apply migrations one at a time.
+const migrationLockID int64 = 0x746f646f // "todo"
+
+var (
+	ErrSchemaBehind = errors.New("database schema is behind this binary")
+	ErrSchemaAhead  = errors.New("database schema is ahead of this binary")
+)
+
+// PendingMigration is a migration embedded in the binary but not yet applied.
+type PendingMigration struct {
+	Version int64  `json:"version"`
+	Path    string `json:"path"`
+}
+
+// MigrateFS applies pending migrations under a session advisory lock.
+func MigrateFS(dsn string, migrationFS fs.FS, dir string) error {
+	return withProvider(dsn, migrationFS, dir, func(ctx context.Context, p *goose.Provider) error {
+		results, err := p.Up(ctx)
+		if err != nil {
+			return fmt.Errorf("goose up:%w", err)
+		}
+		for _, r := range results {
+			logger.Info(ctx, "migration applied", "version", r.Source.Version, "path", r.Source.Path, "duration", r.Duration)
+		}
+		return nil
+	})
+}
+
+// Migrate applies migrations from a directory on disk.
+func Migrate(dsn, dir string) error {
+	return MigrateFS(dsn, os.DirFS(dir), "")
+}
+
+// PlanFS lists pending migrations without applying them.
+func PlanFS(dsn string, migrationFS fs.FS, dir string) ([]PendingMigration, error) {
+	var pending []PendingMigration
+	err := withProvider(dsn, migrationFS, dir, func(ctx context.Context, p *goose.Provider) error {
+		statuses, err := p.Status(ctx)
+		if err != nil {
+			return fmt.Errorf("goose status: %w", err)
+		}
+		for _, st := range statuses {
+			if st.State == goose.StatePending {
+				pending = append(pending, PendingMigration{Version: st.Source.Version, Path: st.Source.Path})
+			}
+		}
+		return nil
+	})
+	return pending, err
+}
+
+// CheckSchemaVersionFS fails unless the database is exactly at the newest
+// migration embedded in the binary.
+func CheckSchemaVersionFS(dsn string, migrationFS fs.FS, dir string) error {
+	return withProvider(dsn, migrationFS, dir, func(ctx context.Context, p *goose.Provider) error {
+		current, target, err := p.GetVersions(ctx)
+		if err != nil {
+			return fmt.Errorf("goose versions: %w", err)
+		}
+		switch {
+		case current < target:
+			return fmt.Errorf("%w: db=%d binary=%d", ErrSchemaBehind, current, target)
+		case current > target:
+			return fmt.Errorf("%w: db=%d binary=%d", ErrSchemaAhead, current, target)
+		}
+		return nil
+	})
+}
+
+func withProvider(dsn string, migrationFS fs.FS, dir string, fn func(context.Context, *goose.Provider) error) error {
+	if dir != "" && dir != "." {
+		sub, err := fs.Sub(migrationFS, dir)
+		if err != nil {
+			return fmt.Errorf("migrations dir %q: %w", dir, err)
+		}
+		migrationFS = sub
+	}
+
+	db, err := sql.Open("pgx", dsn)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	db.SetMaxOpenConns(2)
+	db.SetMaxIdleConns(1)
+	db.SetConnMaxLifetime(0)
+
+	locker, err := lock.NewPostgresSessionLocker(lock.WithLockID(migrationLockID))
+	if err != nil {
+		return fmt.Errorf("migration locker: %w", err)
+	}
+	p, err := goose.NewProvider(goose.DialectPostgres, db, migrationFS, goose.WithSessionLocker(locker))
+	if err != nil {
+		return fmt.Errorf("goose provider: %w", err)
+	}
+
+	return fn(context.Background(), p)
+}
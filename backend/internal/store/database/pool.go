@@ -2,18 +2,17 @@ package store
 
 import (
 	"context"
-	"database/sql"
-	"fmt"
-	"io/fs"
 	"time"
 
 	"github.com/diagnosis/interactive-todo/internal/logger"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/multitracer"
 	"github.com/jackc/pgx/v5/pgxpool"
-	_ "github.com/jackc/pgx/v5/stdlib"
-	"github.com/pressly/goose/v3"
 )
 
-func OpenPool(dsn string) (*pgxpool.Pool, error) {
+// OpenPool opens the pool with the given tracers (e.g. Breaker.Tracer,
+// ExplainTracer) observing every query and acquire.
+func OpenPool(dsn string, tracers ...pgx.QueryTracer) (*pgxpool.Pool, error) {
 	cfg, err := pgxpool.ParseConfig(dsn)
 	if err != nil {
 		return nil, err
@@ -24,6 +23,13 @@ func OpenPool(dsn string) (*pgxpool.Pool, error) {
 	cfg.MaxConnIdleTime = 5 * time.Minute
 	cfg.HealthCheckPeriod = 30 * time.Second
 	cfg.ConnConfig.ConnectTimeout = 5 * time.Second
+	switch len(tracers) {
+	case 0:
+	case 1:
+		cfg.ConnConfig.Tracer = tracers[0]
+	default:
+		cfg.ConnConfig.Tracer = multitracer.New(tracers...)
+	}
 
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
@@ -34,30 +40,3 @@ func OpenPool(dsn string) (*pgxpool.Pool, error) {
 	logger.Info(ctx, "Connecting to db...")
 	return pool, nil
 }
-func MigrateFS(dsn string, migrationFS fs.FS, dir string) error {
-	goose.SetBaseFS(migrationFS)
-	defer func() {
-		goose.SetBaseFS(nil)
-	}()
-	return Migrate(dsn, dir)
-}
-
-func Migrate(dsn, dir string) error {
-	db, err := sql.Open("pgx", dsn)
-	if err != nil {
-		return err
-	}
-	defer db.Close()
-
-	db.SetMaxOpenConns(1)
-	db.SetMaxIdleConns(1)
-	db.SetConnMaxLifetime(0)
-	if err = goose.SetDialect("postgres"); err != nil {
-		return err
-	}
-	if err = goose.Up(db, "."); err != nil {
-		return fmt.Errorf("goose up:%w", err)
-	}
-	return nil
-
-}
@@ -0,0 +1,202 @@
+package store
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/diagnosis/interactive-todo/api/types"
+	"github.com/diagnosis/interactive-todo/internal/logger"
+	chimiddleware "github.com/go-chi/chi/v5/middleware"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+const (
+	// poolReportEvery spaces out starvation reports; one per incident is
+	// enough to see who holds the connections
+	poolReportEvery = 30 * time.Second
+	// poolReportTop is how many waiters and holders a report lists
+	poolReportTop = 5
+)
+
+// PoolWatch traces who waits for and who holds pool connections. When an
+// acquire takes longer than the threshold it logs the longest waiters and
+// the queries holding connections the longest, which is what a "5s
+// timeout" incident needs to be explained.
+type PoolWatch struct {
+	threshold time.Duration
+
+	mu         sync.Mutex
+	nextID     uint64
+	waiting    map[uint64]poolClient
+	holding    map[*pgx.Conn]*poolClient
+	slow       int64
+	maxWait    time.Duration
+	lastReport time.Time
+	lastSlow   time.Time
+}
+
+// poolClient is a request or job waiting for or holding a connection.
+type poolClient struct {
+	since     time.Time
+	requestID string
+	sql       string
+	queryAt   time.Time
+}
+
+type waiterKey struct{}
+
+func NewPoolWatch(threshold time.Duration) *PoolWatch {
+	return &PoolWatch{
+		threshold: threshold,
+		waiting:   make(map[uint64]poolClient),
+		holding:   make(map[*pgx.Conn]*poolClient),
+	}
+}
+
+// PoolWatchFromEnv reads DB_ACQUIRE_WARN, the acquire wait (a Go duration,
+// default 1s) after which the watch logs a report.
+func PoolWatchFromEnv() (*PoolWatch, error) {
+	threshold := time.Second
+	if v := strings.TrimSpace(os.Getenv("DB_ACQUIRE_WARN")); v != "" {
+		d, err := time.ParseDuration(v)
+		if err != nil || d <= 0 {
+			return nil, fmt.Errorf("DB_ACQUIRE_WARN must be a positive duration")
+		}
+		threshold = d
+	}
+	return NewPoolWatch(threshold), nil
+}
+
+func (w *PoolWatch) TraceAcquireStart(ctx context.Context, _ *pgxpool.Pool, _ pgxpool.TraceAcquireStartData) context.Context {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	w.nextID++
+	w.waiting[w.nextID] = poolClient{since: time.Now(), requestID: chimiddleware.GetReqID(ctx)}
+	return context.WithValue(ctx, waiterKey{}, w.nextID)
+}
+
+func (w *PoolWatch) TraceAcquireEnd(ctx context.Context, _ *pgxpool.Pool, data pgxpool.TraceAcquireEndData) {
+	id, _ := ctx.Value(waiterKey{}).(uint64)
+	now := time.Now()
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	c, ok := w.waiting[id]
+	if !ok {
+		return
+	}
+	delete(w.waiting, id)
+	wait := now.Sub(c.since)
+	w.maxWait = max(w.maxWait, wait)
+	if data.Err == nil {
+		w.holding[data.Conn] = &poolClient{since: now, requestID: c.requestID}
+	}
+	if wait < w.threshold {
+		return
+	}
+	w.slow++
+	w.lastSlow = now
+	if now.Sub(w.lastReport) >= poolReportEvery {
+		w.lastReport = now
+		w.report(ctx, wait, now)
+	}
+}
+
+func (w *PoolWatch) TraceRelease(_ *pgxpool.Pool, data pgxpool.TraceReleaseData) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	delete(w.holding, data.Conn)
+}
+
+func (w *PoolWatch) TraceQueryStart(ctx context.Context, conn *pgx.Conn, data pgx.TraceQueryStartData) context.Context {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if c, ok := w.holding[conn]; ok {
+		c.sql = data.SQL
+		c.queryAt = time.Now()
+	}
+	return ctx
+}
+
+func (w *PoolWatch) TraceQueryEnd(context.Context, *pgx.Conn, pgx.TraceQueryEndData) {}
+
+// report logs the longest waiters and holders. Callers hold mu.
+func (w *PoolWatch) report(ctx context.Context, wait time.Duration, now time.Time) {
+	waiters := make([]poolClient, 0, len(w.waiting))
+	for _, c := range w.waiting {
+		waiters = append(waiters, c)
+	}
+	holders := make([]poolClient, 0, len(w.holding))
+	for _, c := range w.holding {
+		holders = append(holders, *c)
+	}
+	oldest := func(a, b poolClient) int { return a.since.Compare(b.since) }
+	slices.SortFunc(waiters, oldest)
+	slices.SortFunc(holders, oldest)
+
+	lines := func(cs []poolClient, withSQL bool) []string {
+		out := make([]string, 0, min(len(cs), poolReportTop))
+		for _, c := range cs[:min(len(cs), poolReportTop)] {
+			s := fmt.Sprintf("%s for %s", cmp.Or(c.requestID, "background"), now.Sub(c.since).Round(time.Millisecond))
+			if withSQL && c.sql != "" {
+				s += fmt.Sprintf(", last query %s ago: %s", now.Sub(c.queryAt).Round(time.Millisecond), compactSQL(c.sql))
+			}
+			out = append(out, s)
+		}
+		return out
+	}
+
+	logger.Warn(ctx, "db pool: slow acquire",
+		"wait", wait.Round(time.Millisecond),
+		"threshold", w.threshold,
+		"waiting", len(waiters),
+		"holding", len(holders),
+		"longest_waiting", lines(waiters, false),
+		"longest_holding", lines(holders, true),
+	)
+}
+
+type PoolStats = types.PoolStats
+
+// Stats combines st with the watch's counters; w may be nil.
+func (w *PoolWatch) Stats(st *pgxpool.Stat) PoolStats {
+	ps := PoolStats{
+		MaxConns:          st.MaxConns(),
+		TotalConns:        st.TotalConns(),
+		AcquiredConns:     st.AcquiredConns(),
+		IdleConns:         st.IdleConns(),
+		ConstructingConns: st.ConstructingConns(),
+		AcquireCount:      st.AcquireCount(),
+		WaitedCount:       st.EmptyAcquireCount(),
+		CanceledCount:     st.CanceledAcquireCount(),
+		WaitMS:            st.EmptyAcquireWaitTime().Milliseconds(),
+	}
+	if n := st.AcquireCount(); n > 0 {
+		ps.AvgAcquireMS = float64(st.AcquireDuration().Microseconds()) / float64(n) / 1000
+	}
+	if w == nil {
+		return ps
+	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	ps.Waiting = len(w.waiting)
+	ps.SlowCount = w.slow
+	ps.SlowThreshold = w.threshold.Milliseconds()
+	ps.MaxWaitMS = w.maxWait.Milliseconds()
+	if !w.lastSlow.IsZero() {
+		t := w.lastSlow.UTC()
+		ps.LastSlowAt = &t
+	}
+	return ps
+}
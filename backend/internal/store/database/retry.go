@@ -0,0 +1,95 @@
+package store
+
+import (
+	"context"
+	"fmt"
+	"math/rand/v2"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/diagnosis/interactive-todo/internal/logger"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// RetryConfig controls how long startup waits for the database.
+type RetryConfig struct {
+	Attempts       int
+	InitialBackoff time.Duration
+	MaxBackoff     time.Duration
+}
+
+func DefaultRetryConfig() RetryConfig {
+	return RetryConfig{
+		Attempts:       10,
+		InitialBackoff: 500 * time.Millisecond,
+		MaxBackoff:     30 * time.Second,
+	}
+}
+
+// RetryConfigFromEnv reads DB_CONNECT_ATTEMPTS and DB_CONNECT_MAX_BACKOFF
+// (a Go duration such as "30s") on top of the defaults.
+func RetryConfigFromEnv() (RetryConfig, error) {
+	rc := DefaultRetryConfig()
+	if v := strings.TrimSpace(os.Getenv("DB_CONNECT_ATTEMPTS")); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 {
+			return rc, fmt.Errorf("DB_CONNECT_ATTEMPTS must be a positive integer")
+		}
+		rc.Attempts = n
+	}
+	if v := strings.TrimSpace(os.Getenv("DB_CONNECT_MAX_BACKOFF")); v != "" {
+		d, err := time.ParseDuration(v)
+		if err != nil || d <= 0 {
+			return rc, fmt.Errorf("DB_CONNECT_MAX_BACKOFF must be a positive duration")
+		}
+		rc.MaxBackoff = d
+	}
+	return rc, nil
+}
+
+// OpenPoolWithRetry opens the pool and waits until the database answers a
+// ping, backing off exponentially (with jitter) between attempts. A bad DSN
+// fails immediately; ctx cancellation (e.g. SIGTERM) stops the wait.
+func OpenPoolWithRetry(ctx context.Context, dsn string, rc RetryConfig, tracers ...pgx.QueryTracer) (*pgxpool.Pool, error) {
+	if _, err := pgxpool.ParseConfig(dsn); err != nil {
+		return nil, fmt.Errorf("parse dsn: %w", err)
+	}
+
+	backoff := rc.InitialBackoff
+	var lastErr error
+	for attempt := 1; attempt <= rc.Attempts; attempt++ {
+		pool, err := OpenPool(dsn, tracers...)
+		if err == nil {
+			pingCtx, cancel := context.WithTimeout(ctx, 5*time.Second)
+			err = pool.Ping(pingCtx)
+			cancel()
+			if err == nil {
+				return pool, nil
+			}
+			pool.Close()
+		}
+		lastErr = err
+
+		if attempt == rc.Attempts {
+			break
+		}
+		// jitter keeps replicas from retrying in lockstep
+		wait := backoff/2 + rand.N(backoff/2+1)
+		logger.Warn(ctx, "database not reachable, retrying",
+			"attempt", attempt,
+			"max_attempts", rc.Attempts,
+			"retry_in", wait,
+			"err", err,
+		)
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(wait):
+		}
+		backoff = min(backoff*2, rc.MaxBackoff)
+	}
+	return nil, fmt.Errorf("database not reachable after %d attempts: %w", rc.Attempts, lastErr)
+}
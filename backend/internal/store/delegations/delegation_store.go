@@ -0,0 +1,221 @@
+package store
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/diagnosis/interactive-todo/api/types"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type Delegation = types.Delegation
+
+// MaxDuration bounds how long a delegation can last.
+const MaxDuration = 90 * 24 * time.Hour
+
+var ErrDelegationNotFound = errors.New("delegation not found")
+
+type DelegationStore interface {
+	// List returns the team's delegations that have not ended, by start.
+	List(ctx context.Context, teamID uuid.UUID, now time.Time) ([]Delegation, error)
+	Get(ctx context.Context, teamID, delegationID uuid.UUID, now time.Time) (*Delegation, error)
+	// Set stores the delegator's delegation in the team, replacing the one
+	// they had.
+	Set(ctx context.Context, d Delegation, now time.Time) (*Delegation, error)
+	Delete(ctx context.Context, teamID, delegationID uuid.UUID) error
+	// Covers reports whether delegateID holds delegatorID's powers in the
+	// team at now. The delegate must still be a member.
+	Covers(ctx context.Context, teamID, delegatorID, delegateID uuid.UUID, now time.Time) (bool, error)
+	// DelegatorsOf returns who delegated their powers in the team to
+	// delegateID, at now.
+	DelegatorsOf(ctx context.Context, teamID, delegateID uuid.UUID, now time.Time) ([]uuid.UUID, error)
+	// DeleteExpired removes up to limit delegations that ended by now and
+	// returns them.
+	DeleteExpired(ctx context.Context, now time.Time, limit int) ([]Delegation, error)
+}
+
+type PGDelegationStore struct {
+	pool *pgxpool.Pool
+}
+
+func NewPGDelegationStore(pool *pgxpool.Pool) *PGDelegationStore {
+	return &PGDelegationStore{pool: pool}
+}
+
+var _ DelegationStore = (*PGDelegationStore)(nil)
+
+// NOTE: order must match scanDelegation
+const delegationColumns = `
+    id,
+    team_id,
+    delegator_id,
+    delegate_id,
+    starts_at,
+    ends_at,
+    reason,
+    created_at
+`
+
+func scanDelegation(row pgx.Row, now time.Time) (*Delegation, error) {
+	var d Delegation
+	err := row.Scan(
+		&d.ID,
+		&d.TeamID,
+		&d.DelegatorID,
+		&d.DelegateID,
+		&d.StartsAt,
+		&d.EndsAt,
+		&d.Reason,
+		&d.CreatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	d.Active = !now.Before(d.StartsAt) && now.Before(d.EndsAt)
+	return &d, nil
+}
+
+func (s *PGDelegationStore) List(ctx context.Context, teamID uuid.UUID, now time.Time) ([]Delegation, error) {
+	q := `
+		SELECT ` + delegationColumns + `
+		FROM team_delegations
+		WHERE team_id = $1 AND ends_at > $2
+		ORDER BY starts_at, id
+	`
+	rows, err := s.pool.Query(ctx, q, teamID, now.UTC())
+	if err != nil {
+		return nil, fmt.Errorf("list delegations team_id=%s: %w", teamID, err)
+	}
+	defer rows.Close()
+
+	delegations := []Delegation{}
+	for rows.Next() {
+		d, err := scanDelegation(rows, now)
+		if err != nil {
+			return nil, fmt.Errorf("scan delegation: %w", err)
+		}
+		delegations = append(delegations, *d)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("list delegations team_id=%s: %w", teamID, err)
+	}
+	return delegations, nil
+}
+
+func (s *PGDelegationStore) Get(ctx context.Context, teamID, delegationID uuid.UUID, now time.Time) (*Delegation, error) {
+	q := `
+		SELECT ` + delegationColumns + `
+		FROM team_delegations
+		WHERE id = $1 AND team_id = $2
+	`
+	d, err := scanDelegation(s.pool.QueryRow(ctx, q, delegationID, teamID), now)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrDelegationNotFound
+		}
+		return nil, fmt.Errorf("get delegation id=%s: %w", delegationID, err)
+	}
+	return d, nil
+}
+
+func (s *PGDelegationStore) Set(ctx context.Context, d Delegation, now time.Time) (*Delegation, error) {
+	q := `
+		INSERT INTO team_delegations (team_id, delegator_id, delegate_id, starts_at, ends_at, reason, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		ON CONFLICT (team_id, delegator_id) DO UPDATE
+		SET delegate_id = EXCLUDED.delegate_id,
+		    starts_at   = EXCLUDED.starts_at,
+		    ends_at     = EXCLUDED.ends_at,
+		    reason      = EXCLUDED.reason,
+		    created_at  = EXCLUDED.created_at
+		RETURNING ` + delegationColumns
+	out, err := scanDelegation(s.pool.QueryRow(ctx, q,
+		d.TeamID, d.DelegatorID, d.DelegateID, d.StartsAt.UTC(), d.EndsAt.UTC(), d.Reason, now.UTC(),
+	), now)
+	if err != nil {
+		return nil, fmt.Errorf("set delegation team_id=%s delegator_id=%s: %w", d.TeamID, d.DelegatorID, err)
+	}
+	return out, nil
+}
+
+func (s *PGDelegationStore) Delete(ctx context.Context, teamID, delegationID uuid.UUID) error {
+	ct, err := s.pool.Exec(ctx, `DELETE FROM team_delegations WHERE id = $1 AND team_id = $2`, delegationID, teamID)
+	if err != nil {
+		return fmt.Errorf("delete delegation id=%s: %w", delegationID, err)
+	}
+	if ct.RowsAffected() == 0 {
+		return ErrDelegationNotFound
+	}
+	return nil
+}
+
+func (s *PGDelegationStore) Covers(ctx context.Context, teamID, delegatorID, delegateID uuid.UUID, now time.Time) (bool, error) {
+	const q = `
+		SELECT EXISTS (
+			SELECT 1
+			FROM team_delegations d
+			JOIN team_members m ON m.team_id = d.team_id AND m.user_id = d.delegate_id
+			WHERE d.team_id = $1 AND d.delegator_id = $2 AND d.delegate_id = $3
+			  AND d.starts_at <= $4 AND d.ends_at > $4
+		)
+	`
+	var ok bool
+	if err := s.pool.QueryRow(ctx, q, teamID, delegatorID, delegateID, now.UTC()).Scan(&ok); err != nil {
+		return false, fmt.Errorf("check delegation team_id=%s delegator_id=%s: %w", teamID, delegatorID, err)
+	}
+	return ok, nil
+}
+
+func (s *PGDelegationStore) DelegatorsOf(ctx context.Context, teamID, delegateID uuid.UUID, now time.Time) ([]uuid.UUID, error) {
+	const q = `
+		SELECT d.delegator_id
+		FROM team_delegations d
+		JOIN team_members m ON m.team_id = d.team_id AND m.user_id = d.delegate_id
+		WHERE d.team_id = $1 AND d.delegate_id = $2
+		  AND d.starts_at <= $3 AND d.ends_at > $3
+	`
+	rows, err := s.pool.Query(ctx, q, teamID, delegateID, now.UTC())
+	if err != nil {
+		return nil, fmt.Errorf("list delegators team_id=%s delegate_id=%s: %w", teamID, delegateID, err)
+	}
+	ids, err := pgx.CollectRows(rows, pgx.RowTo[uuid.UUID])
+	if err != nil {
+		return nil, fmt.Errorf("list delegators team_id=%s delegate_id=%s: %w", teamID, delegateID, err)
+	}
+	return ids, nil
+}
+
+func (s *PGDelegationStore) DeleteExpired(ctx context.Context, now time.Time, limit int) ([]Delegation, error) {
+	q := `
+		DELETE FROM team_delegations
+		WHERE id IN (
+			SELECT id FROM team_delegations
+			WHERE ends_at <= $1
+			ORDER BY ends_at
+			LIMIT $2
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING ` + delegationColumns
+	rows, err := s.pool.Query(ctx, q, now.UTC(), limit)
+	if err != nil {
+		return nil, fmt.Errorf("delete expired delegations: %w", err)
+	}
+	defer rows.Close()
+
+	var expired []Delegation
+	for rows.Next() {
+		d, err := scanDelegation(rows, now)
+		if err != nil {
+			return nil, fmt.Errorf("scan delegation: %w", err)
+		}
+		expired = append(expired, *d)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("delete expired delegations: %w", err)
+	}
+	return expired, nil
+}
@@ -0,0 +1,252 @@
+package store
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/diagnosis/interactive-todo/api/types"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type Link = types.DirectoryLink
+type SyncResult = types.DirectorySyncResult
+
+var ErrLinkNotFound = errors.New("directory link not found")
+
+type DirectoryStore interface {
+	Get(ctx context.Context, teamID uuid.UUID) (*Link, error)
+	// Put links the team to a group, or points its link at another one.
+	Put(ctx context.Context, teamID uuid.UUID, provider, groupID string, role types.TeamRole, createdBy uuid.UUID, now time.Time) (*Link, error)
+	// Delete unlinks the team. Members the sync added stay members.
+	Delete(ctx context.Context, teamID uuid.UUID) error
+	// List returns every link, oldest sync first.
+	List(ctx context.Context) ([]Link, error)
+	// Apply makes the team's members match emails, the group's members:
+	// users with one of the emails who are not members are added with the
+	// link's role, and members the sync added earlier whose email is gone
+	// are removed. Owners are never removed.
+	Apply(ctx context.Context, teamID uuid.UUID, emails []string, now time.Time) (*SyncResult, error)
+	// Record stores the outcome of a sync; res is nil when it failed.
+	Record(ctx context.Context, teamID uuid.UUID, res *SyncResult, syncErr string, now time.Time) error
+}
+
+// NOTE: order must match scanLink
+const linkColumns = `
+    team_id,
+    provider,
+    group_id,
+    role,
+    created_by,
+    created_at,
+    updated_at,
+    last_synced_at,
+    last_error,
+    last_added,
+    last_removed,
+    last_unmatched
+`
+
+type PGDirectoryStore struct {
+	pool *pgxpool.Pool
+}
+
+func NewPGDirectoryStore(pool *pgxpool.Pool) *PGDirectoryStore {
+	return &PGDirectoryStore{pool: pool}
+}
+
+func scanLink(row pgx.Row) (*Link, error) {
+	var l Link
+	err := row.Scan(
+		&l.TeamID,
+		&l.Provider,
+		&l.GroupID,
+		&l.Role,
+		&l.CreatedBy,
+		&l.CreatedAt,
+		&l.UpdatedAt,
+		&l.LastSyncedAt,
+		&l.LastError,
+		&l.LastAdded,
+		&l.LastRemoved,
+		&l.LastUnmatched,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &l, nil
+}
+
+func (s *PGDirectoryStore) Get(ctx context.Context, teamID uuid.UUID) (*Link, error) {
+	q := `SELECT ` + linkColumns + ` FROM team_directory_links WHERE team_id = $1`
+	l, err := scanLink(s.pool.QueryRow(ctx, q, teamID))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, ErrLinkNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("get directory link team_id=%s: %w", teamID, err)
+	}
+	return l, nil
+}
+
+func (s *PGDirectoryStore) Put(ctx context.Context, teamID uuid.UUID, provider, groupID string, role types.TeamRole, createdBy uuid.UUID, now time.Time) (*Link, error) {
+	// a different group starts over: the sync results of the old one no
+	// longer apply
+	q := `
+		INSERT INTO team_directory_links (team_id, provider, group_id, role, created_by, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $6)
+		ON CONFLICT (team_id) DO UPDATE SET
+			provider = EXCLUDED.provider,
+			group_id = EXCLUDED.group_id,
+			role = EXCLUDED.role,
+			updated_at = EXCLUDED.updated_at,
+			last_synced_at = CASE WHEN team_directory_links.provider = EXCLUDED.provider
+				AND team_directory_links.group_id = EXCLUDED.group_id
+				THEN team_directory_links.last_synced_at END,
+			last_error = NULL
+		RETURNING ` + linkColumns
+	l, err := scanLink(s.pool.QueryRow(ctx, q, teamID, provider, groupID, role, createdBy, now.UTC()))
+	if err != nil {
+		return nil, fmt.Errorf("put directory link team_id=%s: %w", teamID, err)
+	}
+	return l, nil
+}
+
+func (s *PGDirectoryStore) Delete(ctx context.Context, teamID uuid.UUID) error {
+	tag, err := s.pool.Exec(ctx, `DELETE FROM team_directory_links WHERE team_id = $1`, teamID)
+	if err != nil {
+		return fmt.Errorf("delete directory link team_id=%s: %w", teamID, err)
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrLinkNotFound
+	}
+	return nil
+}
+
+func (s *PGDirectoryStore) List(ctx context.Context) ([]Link, error) {
+	q := `
+		SELECT ` + linkColumns + `
+		FROM team_directory_links
+		ORDER BY last_synced_at NULLS FIRST, team_id
+	`
+	rows, err := s.pool.Query(ctx, q)
+	if err != nil {
+		return nil, fmt.Errorf("list directory links: %w", err)
+	}
+	defer rows.Close()
+
+	out := []Link{}
+	for rows.Next() {
+		l, err := scanLink(rows)
+		if err != nil {
+			return nil, fmt.Errorf("scan directory link: %w", err)
+		}
+		out = append(out, *l)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("list directory links: %w", err)
+	}
+	return out, nil
+}
+
+func (s *PGDirectoryStore) Apply(ctx context.Context, teamID uuid.UUID, emails []string, now time.Time) (*SyncResult, error) {
+	tx, err := s.pool.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("apply directory sync team_id=%s: begin: %w", teamID, err)
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	// the row lock keeps two syncs of the team from interleaving
+	var role types.TeamRole
+	err = tx.QueryRow(ctx, `SELECT role FROM team_directory_links WHERE team_id = $1 FOR UPDATE`, teamID).Scan(&role)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, ErrLinkNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("apply directory sync team_id=%s: lock link: %w", teamID, err)
+	}
+
+	rows, err := tx.Query(ctx, `SELECT id FROM users WHERE email = ANY($1::text[]::citext[])`, emails)
+	if err != nil {
+		return nil, fmt.Errorf("apply directory sync team_id=%s: match users: %w", teamID, err)
+	}
+	// never nil: ANY(NULL) would keep everyone
+	userIDs := []uuid.UUID{}
+	for rows.Next() {
+		var id uuid.UUID
+		if err := rows.Scan(&id); err != nil {
+			rows.Close()
+			return nil, fmt.Errorf("scan matched user: %w", err)
+		}
+		userIDs = append(userIDs, id)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("apply directory sync team_id=%s: match users: %w", teamID, err)
+	}
+
+	const add = `
+		WITH added AS (
+			INSERT INTO team_members (team_id, user_id, role, created_at)
+			SELECT $1, u, $3, $4 FROM unnest($2::uuid[]) AS u
+			ON CONFLICT (team_id, user_id) DO NOTHING
+			RETURNING user_id
+		)
+		INSERT INTO directory_members (team_id, user_id, created_at)
+		SELECT $1, user_id, $4 FROM added
+	`
+	now = now.UTC()
+	added, err := tx.Exec(ctx, add, teamID, userIDs, role, now)
+	if err != nil {
+		return nil, fmt.Errorf("apply directory sync team_id=%s: add members: %w", teamID, err)
+	}
+
+	const remove = `
+		DELETE FROM team_members m
+		USING directory_members d
+		WHERE d.team_id = $1
+		  AND m.team_id = d.team_id
+		  AND m.user_id = d.user_id
+		  AND m.role <> 'owner'
+		  AND NOT (m.user_id = ANY($2::uuid[]))
+	`
+	removed, err := tx.Exec(ctx, remove, teamID, userIDs)
+	if err != nil {
+		return nil, fmt.Errorf("apply directory sync team_id=%s: remove members: %w", teamID, err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("apply directory sync team_id=%s: commit: %w", teamID, err)
+	}
+	return &SyncResult{
+		Added:     int(added.RowsAffected()),
+		Removed:   int(removed.RowsAffected()),
+		Unmatched: len(emails) - len(userIDs),
+	}, nil
+}
+
+func (s *PGDirectoryStore) Record(ctx context.Context, teamID uuid.UUID, res *SyncResult, syncErr string, now time.Time) error {
+	// a failed sync keeps the counts of the last one that worked
+	const q = `
+		UPDATE team_directory_links SET
+			last_synced_at = $2,
+			last_error = NULLIF($3, ''),
+			last_added = COALESCE($4, last_added),
+			last_removed = COALESCE($5, last_removed),
+			last_unmatched = COALESCE($6, last_unmatched)
+		WHERE team_id = $1
+	`
+	var added, removed, unmatched *int
+	if res != nil {
+		added, removed, unmatched = &res.Added, &res.Removed, &res.Unmatched
+	}
+	if _, err := s.pool.Exec(ctx, q, teamID, now.UTC(), syncErr, added, removed, unmatched); err != nil {
+		return fmt.Errorf("record directory sync team_id=%s: %w", teamID, err)
+	}
+	return nil
+}
+
+var _ DirectoryStore = (*PGDirectoryStore)(nil)
@@ -0,0 +1,160 @@
+package store
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/diagnosis/interactive-todo/api/types"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type EditLock = types.EditLock
+
+// TTL is how long a lock lasts without a heartbeat. Clients renew it
+// every HeartbeatEvery.
+const (
+	TTL            = 60 * time.Second
+	HeartbeatEvery = 20 * time.Second
+)
+
+// ErrNotHeld: the lock id is not the task's current lock; someone else
+// took it after it lapsed, or it was released.
+var ErrNotHeld = errors.New("edit lock not held")
+
+// EditLockStore keeps one soft lock per task description. Shared through
+// Postgres so every instance sees the same holder.
+type EditLockStore interface {
+	// Acquire takes the task's lock for userID unless someone holds it.
+	// acquired is false and lock is the holder's when they do. lockID is
+	// the new lock's handle, uuid.Nil when not acquired.
+	Acquire(ctx context.Context, taskID, userID uuid.UUID, now time.Time) (lock *EditLock, lockID uuid.UUID, acquired bool, err error)
+	// Heartbeat extends the lock to now+TTL. A lapsed lock nobody took
+	// since can still be renewed.
+	Heartbeat(ctx context.Context, taskID, lockID uuid.UUID, now time.Time) (*EditLock, error)
+	// Release frees the lock and returns it as it was.
+	Release(ctx context.Context, taskID, lockID uuid.UUID) (*EditLock, error)
+	// Current returns the task's live lock, or nil.
+	Current(ctx context.Context, taskID uuid.UUID, now time.Time) (*EditLock, error)
+	// DeleteExpired removes locks that lapsed before now.
+	DeleteExpired(ctx context.Context, now time.Time) (int, error)
+}
+
+type PGEditLockStore struct {
+	pool *pgxpool.Pool
+}
+
+func NewPGEditLockStore(pool *pgxpool.Pool) *PGEditLockStore {
+	return &PGEditLockStore{pool: pool}
+}
+
+var _ EditLockStore = (*PGEditLockStore)(nil)
+
+const lockColumns = `task_id, user_id, acquired_at, expires_at`
+
+func scanLock(row pgx.Row) (*EditLock, error) {
+	var l EditLock
+	if err := row.Scan(&l.TaskID, &l.UserID, &l.AcquiredAt, &l.ExpiresAt); err != nil {
+		return nil, err
+	}
+	return &l, nil
+}
+
+func (s *PGEditLockStore) Acquire(ctx context.Context, taskID, userID uuid.UUID, now time.Time) (*EditLock, uuid.UUID, bool, error) {
+	// a live lock is left alone and the statement returns nothing
+	const q = `
+		INSERT INTO task_edit_locks (task_id, id, user_id, acquired_at, expires_at)
+		VALUES ($1, $2, $3, $4, $5)
+		ON CONFLICT (task_id) DO UPDATE
+		SET id = EXCLUDED.id,
+		    user_id = EXCLUDED.user_id,
+		    acquired_at = EXCLUDED.acquired_at,
+		    expires_at = EXCLUDED.expires_at
+		WHERE task_edit_locks.expires_at <= EXCLUDED.acquired_at
+		RETURNING ` + lockColumns
+
+	lockID := uuid.New()
+	now = now.UTC()
+	// the holder may release between the two statements; then try again
+	for range 2 {
+		l, err := scanLock(s.pool.QueryRow(ctx, q, taskID, lockID, userID, now, now.Add(TTL)))
+		if err == nil {
+			return l, lockID, true, nil
+		}
+		if !errors.Is(err, pgx.ErrNoRows) {
+			return nil, uuid.Nil, false, fmt.Errorf("acquire edit lock task_id=%s: %w", taskID, err)
+		}
+
+		held, err := s.Current(ctx, taskID, now)
+		if err != nil {
+			return nil, uuid.Nil, false, err
+		}
+		if held != nil {
+			return held, uuid.Nil, false, nil
+		}
+	}
+	return nil, uuid.Nil, false, fmt.Errorf("acquire edit lock task_id=%s: lock keeps changing hands", taskID)
+}
+
+func (s *PGEditLockStore) Heartbeat(ctx context.Context, taskID, lockID uuid.UUID, now time.Time) (*EditLock, error) {
+	const q = `
+		UPDATE task_edit_locks
+		SET expires_at = $3
+		WHERE task_id = $1
+		  AND id = $2
+		RETURNING ` + lockColumns
+
+	l, err := scanLock(s.pool.QueryRow(ctx, q, taskID, lockID, now.UTC().Add(TTL)))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrNotHeld
+		}
+		return nil, fmt.Errorf("renew edit lock task_id=%s: %w", taskID, err)
+	}
+	return l, nil
+}
+
+func (s *PGEditLockStore) Release(ctx context.Context, taskID, lockID uuid.UUID) (*EditLock, error) {
+	const q = `
+		DELETE FROM task_edit_locks
+		WHERE task_id = $1
+		  AND id = $2
+		RETURNING ` + lockColumns
+
+	l, err := scanLock(s.pool.QueryRow(ctx, q, taskID, lockID))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrNotHeld
+		}
+		return nil, fmt.Errorf("release edit lock task_id=%s: %w", taskID, err)
+	}
+	return l, nil
+}
+
+func (s *PGEditLockStore) Current(ctx context.Context, taskID uuid.UUID, now time.Time) (*EditLock, error) {
+	const q = `
+		SELECT ` + lockColumns + `
+		FROM task_edit_locks
+		WHERE task_id = $1
+		  AND expires_at > $2
+	`
+	l, err := scanLock(s.pool.QueryRow(ctx, q, taskID, now.UTC()))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("get edit lock task_id=%s: %w", taskID, err)
+	}
+	return l, nil
+}
+
+func (s *PGEditLockStore) DeleteExpired(ctx context.Context, now time.Time) (int, error) {
+	ct, err := s.pool.Exec(ctx, `DELETE FROM task_edit_locks WHERE expires_at <= $1`, now.UTC())
+	if err != nil {
+		return 0, fmt.Errorf("delete expired edit locks: %w", err)
+	}
+	return int(ct.RowsAffected()), nil
+}
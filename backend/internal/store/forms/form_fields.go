@@ -0,0 +1,185 @@
+package store
+
+import (
+	"errors"
+	"fmt"
+	"net/mail"
+	"regexp"
+	"strings"
+	"time"
+)
+
+type FieldType string
+
+const (
+	FieldText     FieldType = "text"
+	FieldTextarea FieldType = "textarea"
+	FieldDate     FieldType = "date"
+	FieldEmail    FieldType = "email"
+	FieldSelect   FieldType = "select"
+)
+
+// MapsTo names the task attribute a field fills. Fields without a mapping
+// are appended to the description as "Label: value" lines.
+type MapsTo string
+
+const (
+	MapsToNone        MapsTo = ""
+	MapsToTitle       MapsTo = "title"
+	MapsToDescription MapsTo = "description"
+)
+
+var (
+	ErrInvalidFields     = errors.New("invalid form fields")
+	ErrInvalidSubmission = errors.New("invalid form submission")
+)
+
+const (
+	maxFields       = 30
+	maxTextLen      = 500
+	maxTextareaLen  = 5000
+	maxDescription  = 20000
+	maxSelectOption = 50
+)
+
+var fieldKeyRe = regexp.MustCompile(`^[a-z][a-z0-9_]{0,39}$`)
+
+type Field struct {
+	Key      string    `json:"key"`
+	Label    string    `json:"label"`
+	Type     FieldType `json:"type"`
+	Required bool      `json:"required,omitempty"`
+	Options  []string  `json:"options,omitempty"`
+	MapsTo   MapsTo    `json:"maps_to,omitempty"`
+}
+
+// ValidateFields checks a form definition. Exactly one required text field
+// must map to the task title.
+func ValidateFields(fields []Field) error {
+	if len(fields) == 0 || len(fields) > maxFields {
+		return fmt.Errorf("%w: between 1 and %d fields required", ErrInvalidFields, maxFields)
+	}
+
+	seen := make(map[string]bool, len(fields))
+	titles := 0
+	for _, f := range fields {
+		if !fieldKeyRe.MatchString(f.Key) {
+			return fmt.Errorf("%w: field key %q must be lowercase letters, digits or underscores", ErrInvalidFields, f.Key)
+		}
+		if seen[f.Key] {
+			return fmt.Errorf("%w: duplicate field %q", ErrInvalidFields, f.Key)
+		}
+		seen[f.Key] = true
+
+		if strings.TrimSpace(f.Label) == "" || len(f.Label) > 100 {
+			return fmt.Errorf("%w: field %q needs a label (max 100 chars)", ErrInvalidFields, f.Key)
+		}
+
+		switch f.Type {
+		case FieldText, FieldTextarea, FieldDate, FieldEmail:
+			if len(f.Options) > 0 {
+				return fmt.Errorf("%w: field %q: options are only valid for select", ErrInvalidFields, f.Key)
+			}
+		case FieldSelect:
+			if len(f.Options) == 0 || len(f.Options) > maxSelectOption {
+				return fmt.Errorf("%w: field %q needs 1-%d options", ErrInvalidFields, f.Key, maxSelectOption)
+			}
+		default:
+			return fmt.Errorf("%w: field %q has unknown type %q", ErrInvalidFields, f.Key, f.Type)
+		}
+
+		switch f.MapsTo {
+		case MapsToNone, MapsToDescription:
+		case MapsToTitle:
+			if f.Type != FieldText || !f.Required {
+				return fmt.Errorf("%w: title field %q must be a required text field", ErrInvalidFields, f.Key)
+			}
+			titles++
+		default:
+			return fmt.Errorf("%w: field %q has unknown maps_to %q", ErrInvalidFields, f.Key, f.MapsTo)
+		}
+	}
+	if titles != 1 {
+		return fmt.Errorf("%w: exactly one field must map to title", ErrInvalidFields)
+	}
+	return nil
+}
+
+// Render validates submitted values against the form and builds the task
+// title and description from them.
+func (f *Form) Render(values map[string]string) (title string, description *string, err error) {
+	known := make(map[string]bool, len(f.Fields))
+	for _, fd := range f.Fields {
+		known[fd.Key] = true
+	}
+	for k := range values {
+		if !known[k] {
+			return "", nil, fmt.Errorf("%w: unknown field %q", ErrInvalidSubmission, k)
+		}
+	}
+
+	var mapped, extra []string
+	for _, fd := range f.Fields {
+		v := strings.TrimSpace(values[fd.Key])
+		if v == "" {
+			if fd.Required {
+				return "", nil, fmt.Errorf("%w: %s is required", ErrInvalidSubmission, fd.Label)
+			}
+			continue
+		}
+		if err := checkValue(fd, v); err != nil {
+			return "", nil, err
+		}
+
+		switch fd.MapsTo {
+		case MapsToTitle:
+			title = v
+		case MapsToDescription:
+			mapped = append(mapped, v)
+		default:
+			extra = append(extra, fd.Label+": "+v)
+		}
+	}
+
+	parts := mapped
+	if len(extra) > 0 {
+		parts = append(parts, strings.Join(extra, "\n"))
+	}
+	if len(parts) > 0 {
+		d := strings.Join(parts, "\n\n")
+		if len(d) > maxDescription {
+			return "", nil, fmt.Errorf("%w: submission too long", ErrInvalidSubmission)
+		}
+		description = &d
+	}
+	return title, description, nil
+}
+
+func checkValue(fd Field, v string) error {
+	switch fd.Type {
+	case FieldText:
+		if len(v) > maxTextLen {
+			return fmt.Errorf("%w: %s too long (max %d chars)", ErrInvalidSubmission, fd.Label, maxTextLen)
+		}
+	case FieldTextarea:
+		if len(v) > maxTextareaLen {
+			return fmt.Errorf("%w: %s too long (max %d chars)", ErrInvalidSubmission, fd.Label, maxTextareaLen)
+		}
+	case FieldDate:
+		if _, err := time.Parse(time.DateOnly, v); err != nil {
+			return fmt.Errorf("%w: %s must be a YYYY-MM-DD date", ErrInvalidSubmission, fd.Label)
+		}
+	case FieldEmail:
+		if _, err := mail.ParseAddress(v); err != nil || len(v) > maxTextLen {
+			return fmt.Errorf("%w: %s must be an email address", ErrInvalidSubmission, fd.Label)
+		}
+	case FieldSelect:
+		for _, o := range fd.Options {
+			if o == v {
+				return nil
+			}
+		}
+		return fmt.Errorf("%w: %s must be one of %s", ErrInvalidSubmission, fd.Label, strings.Join(fd.Options, ", "))
+	}
+	return nil
+}
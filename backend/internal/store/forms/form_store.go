@@ -0,0 +1,255 @@
+package store
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// Form is a team-defined intake form. Each submission becomes a task, or a
+// triage item for a manager to schedule when Triage is set.
+type Form struct {
+	ID          uuid.UUID  `json:"id"`
+	TeamID      uuid.UUID  `json:"team_id"`
+	Name        string     `json:"name"`
+	Description *string    `json:"description,omitempty"`
+	Fields      []Field    `json:"fields"`
+	IsPublic    bool       `json:"is_public"`
+	Triage      bool       `json:"triage"`
+	AssigneeID  *uuid.UUID `json:"assignee_id,omitempty"`
+	DueInDays   int        `json:"due_in_days"`
+	CreatedBy   uuid.UUID  `json:"created_by"`
+	CreatedAt   time.Time  `json:"created_at"`
+	UpdatedAt   time.Time  `json:"updated_at"`
+}
+
+// FormInput holds the editable parts of a form.
+type FormInput struct {
+	Name        string     `json:"name"`
+	Description *string    `json:"description"`
+	Fields      []Field    `json:"fields"`
+	IsPublic    bool       `json:"is_public"`
+	Triage      bool       `json:"triage"`
+	AssigneeID  *uuid.UUID `json:"assignee_id"`
+	DueInDays   int        `json:"due_in_days"`
+}
+
+var (
+	ErrFormNotFound  = errors.New("form not found")
+	ErrFormNameTaken = errors.New("form name already used in team")
+	ErrInvalidInput  = errors.New("invalid input")
+)
+
+type FormStore interface {
+	Create(ctx context.Context, teamID uuid.UUID, in FormInput, createdBy uuid.UUID, now time.Time) (*Form, error)
+	Update(ctx context.Context, id uuid.UUID, in FormInput, now time.Time) (*Form, error)
+	Get(ctx context.Context, id uuid.UUID) (*Form, error)
+	ListForTeam(ctx context.Context, teamID uuid.UUID) ([]Form, error)
+	Delete(ctx context.Context, id uuid.UUID) error
+}
+
+// NOTE: order must match scanForm
+const formColumns = `
+    id,
+    team_id,
+    name,
+    description,
+    fields,
+    is_public,
+    triage,
+    assignee_id,
+    due_in_days,
+    created_by,
+    created_at,
+    updated_at
+`
+
+type PGFormStore struct {
+	pool *pgxpool.Pool
+}
+
+func NewPGFormStore(pool *pgxpool.Pool) *PGFormStore {
+	return &PGFormStore{pool: pool}
+}
+
+func validateFormInput(in *FormInput) error {
+	in.Name = strings.TrimSpace(in.Name)
+	if in.Name == "" || len(in.Name) > 100 {
+		return fmt.Errorf("%w: name is required (max 100 chars)", ErrInvalidInput)
+	}
+	if in.Description != nil && len(*in.Description) > 2000 {
+		return fmt.Errorf("%w: description too long (max 2000 chars)", ErrInvalidInput)
+	}
+	if in.DueInDays == 0 {
+		in.DueInDays = 7
+	}
+	if in.DueInDays < 1 || in.DueInDays > 365 {
+		return fmt.Errorf("%w: due_in_days must be between 1 and 365", ErrInvalidInput)
+	}
+	return ValidateFields(in.Fields)
+}
+
+func (s *PGFormStore) Create(ctx context.Context, teamID uuid.UUID, in FormInput, createdBy uuid.UUID, now time.Time) (*Form, error) {
+	if err := validateFormInput(&in); err != nil {
+		return nil, err
+	}
+
+	const q = `
+		INSERT INTO intake_forms (
+			team_id, name, description, fields, is_public, triage,
+			assignee_id, due_in_days, created_by, created_at, updated_at
+		)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $10)
+		RETURNING ` + formColumns
+
+	f, err := scanForm(s.pool.QueryRow(ctx, q,
+		teamID,
+		in.Name,
+		in.Description,
+		in.Fields,
+		in.IsPublic,
+		in.Triage,
+		in.AssigneeID,
+		in.DueInDays,
+		createdBy,
+		now.UTC(),
+	))
+	if err != nil {
+		if isUniqueViolation(err) {
+			return nil, ErrFormNameTaken
+		}
+		return nil, fmt.Errorf("create form team_id=%s: %w", teamID, err)
+	}
+	return f, nil
+}
+
+func (s *PGFormStore) Update(ctx context.Context, id uuid.UUID, in FormInput, now time.Time) (*Form, error) {
+	if err := validateFormInput(&in); err != nil {
+		return nil, err
+	}
+
+	const q = `
+		UPDATE intake_forms
+		SET name        = $2,
+		    description = $3,
+		    fields      = $4,
+		    is_public   = $5,
+		    triage      = $6,
+		    assignee_id = $7,
+		    due_in_days = $8,
+		    updated_at  = $9
+		WHERE id = $1
+		RETURNING ` + formColumns
+
+	f, err := scanForm(s.pool.QueryRow(ctx, q,
+		id,
+		in.Name,
+		in.Description,
+		in.Fields,
+		in.IsPublic,
+		in.Triage,
+		in.AssigneeID,
+		in.DueInDays,
+		now.UTC(),
+	))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrFormNotFound
+		}
+		if isUniqueViolation(err) {
+			return nil, ErrFormNameTaken
+		}
+		return nil, fmt.Errorf("update form id=%s: %w", id, err)
+	}
+	return f, nil
+}
+
+func (s *PGFormStore) Get(ctx context.Context, id uuid.UUID) (*Form, error) {
+	const q = `
+		SELECT ` + formColumns + `
+		FROM intake_forms
+		WHERE id = $1
+	`
+
+	f, err := scanForm(s.pool.QueryRow(ctx, q, id))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrFormNotFound
+		}
+		return nil, fmt.Errorf("get form id=%s: %w", id, err)
+	}
+	return f, nil
+}
+
+func (s *PGFormStore) ListForTeam(ctx context.Context, teamID uuid.UUID) ([]Form, error) {
+	const q = `
+		SELECT ` + formColumns + `
+		FROM intake_forms
+		WHERE team_id = $1
+		ORDER BY name
+	`
+
+	rows, err := s.pool.Query(ctx, q, teamID)
+	if err != nil {
+		return nil, fmt.Errorf("list forms team_id=%s: %w", teamID, err)
+	}
+	defer rows.Close()
+
+	var out []Form
+	for rows.Next() {
+		f, err := scanForm(rows)
+		if err != nil {
+			return nil, fmt.Errorf("scan form: %w", err)
+		}
+		out = append(out, *f)
+	}
+	return out, rows.Err()
+}
+
+func (s *PGFormStore) Delete(ctx context.Context, id uuid.UUID) error {
+	const q = `DELETE FROM intake_forms WHERE id = $1`
+
+	ct, err := s.pool.Exec(ctx, q, id)
+	if err != nil {
+		return fmt.Errorf("delete form id=%s: %w", id, err)
+	}
+	if ct.RowsAffected() == 0 {
+		return ErrFormNotFound
+	}
+	return nil
+}
+
+func scanForm(row pgx.Row) (*Form, error) {
+	var f Form
+	if err := row.Scan(
+		&f.ID,
+		&f.TeamID,
+		&f.Name,
+		&f.Description,
+		&f.Fields,
+		&f.IsPublic,
+		&f.Triage,
+		&f.AssigneeID,
+		&f.DueInDays,
+		&f.CreatedBy,
+		&f.CreatedAt,
+		&f.UpdatedAt,
+	); err != nil {
+		return nil, err
+	}
+	return &f, nil
+}
+
+func isUniqueViolation(err error) bool {
+	var pgErr *pgconn.PgError
+	return errors.As(err, &pgErr) && pgErr.Code == "23505"
+}
+
+var _ FormStore = (*PGFormStore)(nil)
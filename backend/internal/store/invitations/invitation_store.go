@@ -0,0 +1,162 @@
+package store
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/diagnosis/interactive-todo/api/types"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type Invitation = types.Invitation
+
+// InvitationTTL is how long an invitation waits for its email to register.
+const InvitationTTL = 14 * 24 * time.Hour
+
+var ErrInvitationNotFound = errors.New("invitation not found")
+
+type InvitationStore interface {
+	// Invite creates an invitation, or renews the team's existing one for
+	// the email with the new role and expiry.
+	Invite(ctx context.Context, teamID uuid.UUID, email string, role types.TeamRole, invitedBy uuid.UUID, now time.Time) (*Invitation, error)
+	// List returns the team's unexpired invitations, newest first.
+	List(ctx context.Context, teamID uuid.UUID, now time.Time) ([]Invitation, error)
+	Delete(ctx context.Context, teamID, id uuid.UUID) error
+	// Accept makes userID a member of every team that invited email and
+	// has not expired, and removes the email's invitations. It returns the
+	// teams joined.
+	Accept(ctx context.Context, userID uuid.UUID, email string, now time.Time) ([]uuid.UUID, error)
+}
+
+// NOTE: order must match scanInvitation
+const invitationColumns = `
+    id,
+    team_id,
+    email,
+    role,
+    invited_by,
+    created_at,
+    expires_at
+`
+
+type PGInvitationStore struct {
+	pool *pgxpool.Pool
+}
+
+func NewPGInvitationStore(pool *pgxpool.Pool) *PGInvitationStore {
+	return &PGInvitationStore{pool: pool}
+}
+
+func scanInvitation(row pgx.Row) (*Invitation, error) {
+	var inv Invitation
+	err := row.Scan(
+		&inv.ID,
+		&inv.TeamID,
+		&inv.Email,
+		&inv.Role,
+		&inv.InvitedBy,
+		&inv.CreatedAt,
+		&inv.ExpiresAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &inv, nil
+}
+
+func (s *PGInvitationStore) Invite(ctx context.Context, teamID uuid.UUID, email string, role types.TeamRole, invitedBy uuid.UUID, now time.Time) (*Invitation, error) {
+	q := `
+		INSERT INTO team_invitations (team_id, email, role, invited_by, created_at, expires_at)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		ON CONFLICT (team_id, email) DO UPDATE SET
+			role = EXCLUDED.role,
+			invited_by = EXCLUDED.invited_by,
+			created_at = EXCLUDED.created_at,
+			expires_at = EXCLUDED.expires_at
+		RETURNING ` + invitationColumns
+	now = now.UTC()
+	inv, err := scanInvitation(s.pool.QueryRow(ctx, q, teamID, email, role, invitedBy, now, now.Add(InvitationTTL)))
+	if err != nil {
+		return nil, fmt.Errorf("invite team_id=%s: %w", teamID, err)
+	}
+	return inv, nil
+}
+
+func (s *PGInvitationStore) List(ctx context.Context, teamID uuid.UUID, now time.Time) ([]Invitation, error) {
+	q := `
+		SELECT ` + invitationColumns + `
+		FROM team_invitations
+		WHERE team_id = $1 AND expires_at > $2
+		ORDER BY created_at DESC, id
+	`
+	rows, err := s.pool.Query(ctx, q, teamID, now.UTC())
+	if err != nil {
+		return nil, fmt.Errorf("list invitations team_id=%s: %w", teamID, err)
+	}
+	defer rows.Close()
+
+	out := []Invitation{}
+	for rows.Next() {
+		inv, err := scanInvitation(rows)
+		if err != nil {
+			return nil, fmt.Errorf("scan invitation: %w", err)
+		}
+		out = append(out, *inv)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("list invitations team_id=%s: %w", teamID, err)
+	}
+	return out, nil
+}
+
+func (s *PGInvitationStore) Delete(ctx context.Context, teamID, id uuid.UUID) error {
+	tag, err := s.pool.Exec(ctx, `DELETE FROM team_invitations WHERE id = $1 AND team_id = $2`, id, teamID)
+	if err != nil {
+		return fmt.Errorf("delete invitation id=%s: %w", id, err)
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrInvitationNotFound
+	}
+	return nil
+}
+
+func (s *PGInvitationStore) Accept(ctx context.Context, userID uuid.UUID, email string, now time.Time) ([]uuid.UUID, error) {
+	// expired invitations are dropped along with the rest
+	const q = `
+		WITH inv AS (
+			DELETE FROM team_invitations
+			WHERE email = $2
+			RETURNING team_id, role, expires_at
+		)
+		INSERT INTO team_members (team_id, user_id, role, created_at)
+		SELECT team_id, $1, role, $3
+		FROM inv
+		WHERE expires_at > $3
+		ON CONFLICT (team_id, user_id) DO NOTHING
+		RETURNING team_id
+	`
+	rows, err := s.pool.Query(ctx, q, userID, email, now.UTC())
+	if err != nil {
+		return nil, fmt.Errorf("accept invitations user_id=%s: %w", userID, err)
+	}
+	defer rows.Close()
+
+	var teamIDs []uuid.UUID
+	for rows.Next() {
+		var id uuid.UUID
+		if err := rows.Scan(&id); err != nil {
+			return nil, fmt.Errorf("scan joined team: %w", err)
+		}
+		teamIDs = append(teamIDs, id)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("accept invitations user_id=%s: %w", userID, err)
+	}
+	return teamIDs, nil
+}
+
+var _ InvitationStore = (*PGInvitationStore)(nil)
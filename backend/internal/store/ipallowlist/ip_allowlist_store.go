@@ -0,0 +1,174 @@
+package store
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/netip"
+	"strings"
+	"time"
+
+	"github.com/diagnosis/interactive-todo/api/types"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type Entry = types.IPAllowlistEntry
+
+var (
+	ErrEntryNotFound = errors.New("allowlist entry not found")
+	ErrEntryExists   = errors.New("allowlist entry already exists")
+	ErrInvalidCIDR   = errors.New("invalid CIDR")
+)
+
+type IPAllowlistStore interface {
+	Add(ctx context.Context, cidr netip.Prefix, note string, createdBy uuid.UUID, now time.Time) (*Entry, error)
+	Remove(ctx context.Context, id uuid.UUID) (*Entry, error)
+	List(ctx context.Context) ([]Entry, error)
+}
+
+// NOTE: order must match scanEntry
+const entryColumns = `
+    id,
+    cidr::text,
+    note,
+    created_by,
+    created_at
+`
+
+func scanEntry(row pgx.Row) (*Entry, error) {
+	var e Entry
+	if err := row.Scan(
+		&e.ID,
+		&e.CIDR,
+		&e.Note,
+		&e.CreatedBy,
+		&e.CreatedAt,
+	); err != nil {
+		return nil, err
+	}
+	return &e, nil
+}
+
+type PGIPAllowlistStore struct {
+	pool *pgxpool.Pool
+}
+
+func NewPGIPAllowlistStore(pool *pgxpool.Pool) *PGIPAllowlistStore {
+	return &PGIPAllowlistStore{pool: pool}
+}
+
+func (s *PGIPAllowlistStore) Add(
+	ctx context.Context,
+	cidr netip.Prefix,
+	note string,
+	createdBy uuid.UUID,
+	now time.Time,
+) (*Entry, error) {
+	const q = `
+		INSERT INTO ip_allowlist (cidr, note, created_by, created_at)
+		VALUES ($1::cidr, $2, $3, $4)
+		RETURNING ` + entryColumns
+
+	e, err := scanEntry(s.pool.QueryRow(ctx, q, cidr.String(), note, createdBy, now.UTC()))
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == "23505" {
+			return nil, ErrEntryExists
+		}
+		return nil, fmt.Errorf("add allowlist entry cidr=%s: %w", cidr, err)
+	}
+	return e, nil
+}
+
+func (s *PGIPAllowlistStore) Remove(ctx context.Context, id uuid.UUID) (*Entry, error) {
+	const q = `
+		DELETE FROM ip_allowlist
+		WHERE id = $1
+		RETURNING ` + entryColumns
+
+	e, err := scanEntry(s.pool.QueryRow(ctx, q, id))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrEntryNotFound
+		}
+		return nil, fmt.Errorf("remove allowlist entry id=%s: %w", id, err)
+	}
+	return e, nil
+}
+
+func (s *PGIPAllowlistStore) List(ctx context.Context) ([]Entry, error) {
+	const q = `
+		SELECT ` + entryColumns + `
+		FROM ip_allowlist
+		ORDER BY created_at
+	`
+
+	rows, err := s.pool.Query(ctx, q)
+	if err != nil {
+		return nil, fmt.Errorf("list allowlist entries: %w", err)
+	}
+	defer rows.Close()
+
+	entries := []Entry{}
+	for rows.Next() {
+		e, err := scanEntry(rows)
+		if err != nil {
+			return nil, err
+		}
+		entries = append(entries, *e)
+	}
+	return entries, rows.Err()
+}
+
+// ParseCIDR accepts a CIDR or a bare address (treated as a single host) and
+// returns it masked, so "10.0.0.7/8" is stored as "10.0.0.0/8".
+func ParseCIDR(s string) (netip.Prefix, error) {
+	s = strings.TrimSpace(s)
+	if !strings.Contains(s, "/") {
+		addr, err := netip.ParseAddr(s)
+		if err != nil {
+			return netip.Prefix{}, ErrInvalidCIDR
+		}
+		return netip.PrefixFrom(addr.Unmap(), addr.Unmap().BitLen()), nil
+	}
+	p, err := netip.ParsePrefix(s)
+	if err != nil {
+		return netip.Prefix{}, ErrInvalidCIDR
+	}
+	return p.Masked(), nil
+}
+
+// Prefixes parses stored entries, skipping any that no longer parse.
+func Prefixes(entries []Entry) []netip.Prefix {
+	out := make([]netip.Prefix, 0, len(entries))
+	for _, e := range entries {
+		if p, err := ParseCIDR(e.CIDR); err == nil {
+			out = append(out, p)
+		}
+	}
+	return out
+}
+
+// Allows reports whether ip falls in one of the prefixes. An empty list
+// allows everything.
+func Allows(prefixes []netip.Prefix, ip string) bool {
+	if len(prefixes) == 0 {
+		return true
+	}
+	addr, err := netip.ParseAddr(strings.TrimSpace(ip))
+	if err != nil {
+		return false
+	}
+	addr = addr.Unmap()
+	for _, p := range prefixes {
+		if p.Contains(addr) {
+			return true
+		}
+	}
+	return false
+}
+
+var _ IPAllowlistStore = (*PGIPAllowlistStore)(nil)
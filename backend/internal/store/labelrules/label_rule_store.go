@@ -0,0 +1,184 @@
+package store
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/diagnosis/interactive-todo/api/types"
+	"github.com/diagnosis/interactive-todo/internal/labelrules"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type LabelRule = types.LabelRule
+
+var ErrRuleNotFound = errors.New("label rule not found")
+
+type LabelRuleStore interface {
+	// List returns the team's rules in evaluation order, disabled ones
+	// included.
+	List(ctx context.Context, teamID uuid.UUID) ([]LabelRule, error)
+	// Create appends a rule after the team's existing ones.
+	Create(ctx context.Context, teamID, createdBy uuid.UUID, in types.LabelRuleRequest, now time.Time) (*LabelRule, error)
+	Update(ctx context.Context, teamID, ruleID uuid.UUID, in types.LabelRuleRequest, now time.Time) (*LabelRule, error)
+	Delete(ctx context.Context, teamID, ruleID uuid.UUID) error
+}
+
+// NOTE: order must match scanRule
+const ruleColumns = `
+    id,
+    team_id,
+    name,
+    field,
+    contains,
+    add_labels,
+    set_priority,
+    enabled,
+    position,
+    created_by,
+    created_at,
+    updated_at
+`
+
+type PGLabelRuleStore struct {
+	pool *pgxpool.Pool
+}
+
+func NewPGLabelRuleStore(pool *pgxpool.Pool) *PGLabelRuleStore {
+	return &PGLabelRuleStore{pool: pool}
+}
+
+func scanRule(row pgx.Row) (*LabelRule, error) {
+	var r LabelRule
+	if err := row.Scan(
+		&r.ID,
+		&r.TeamID,
+		&r.Name,
+		&r.Field,
+		&r.Contains,
+		&r.AddLabels,
+		&r.SetPriority,
+		&r.Enabled,
+		&r.Position,
+		&r.CreatedBy,
+		&r.CreatedAt,
+		&r.UpdatedAt,
+	); err != nil {
+		return nil, err
+	}
+	return &r, nil
+}
+
+func (s *PGLabelRuleStore) List(ctx context.Context, teamID uuid.UUID) ([]LabelRule, error) {
+	q := `
+		SELECT ` + ruleColumns + `
+		FROM label_rules
+		WHERE team_id = $1
+		ORDER BY position, created_at
+	`
+	rows, err := s.pool.Query(ctx, q, teamID)
+	if err != nil {
+		return nil, fmt.Errorf("list label rules team_id=%s: %w", teamID, err)
+	}
+	defer rows.Close()
+
+	rules := []LabelRule{}
+	for rows.Next() {
+		r, err := scanRule(rows)
+		if err != nil {
+			return nil, fmt.Errorf("scan label rule: %w", err)
+		}
+		rules = append(rules, *r)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("list label rules team_id=%s: %w", teamID, err)
+	}
+	return rules, nil
+}
+
+// Create validates through labelrules.Normalize; the error wraps
+// labelrules.ErrInvalid.
+func (s *PGLabelRuleStore) Create(ctx context.Context, teamID, createdBy uuid.UUID, in types.LabelRuleRequest, now time.Time) (*LabelRule, error) {
+	if err := labelrules.Normalize(&in); err != nil {
+		return nil, err
+	}
+	enabled := in.Enabled == nil || *in.Enabled
+
+	q := `
+		INSERT INTO label_rules (team_id, name, field, contains, add_labels, set_priority, enabled, position, created_by, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7,
+		        (SELECT COALESCE(max(position), -1) + 1 FROM label_rules WHERE team_id = $1),
+		        $8, $9, $9)
+		RETURNING ` + ruleColumns
+
+	r, err := scanRule(s.pool.QueryRow(ctx, q,
+		teamID,
+		in.Name,
+		in.Field,
+		in.Contains,
+		in.AddLabels,
+		in.SetPriority,
+		enabled,
+		createdBy,
+		now.UTC(),
+	))
+	if err != nil {
+		return nil, fmt.Errorf("create label rule team_id=%s: %w", teamID, err)
+	}
+	return r, nil
+}
+
+// Update replaces a rule's definition and keeps its position. Enabled nil
+// leaves it as it was.
+func (s *PGLabelRuleStore) Update(ctx context.Context, teamID, ruleID uuid.UUID, in types.LabelRuleRequest, now time.Time) (*LabelRule, error) {
+	if err := labelrules.Normalize(&in); err != nil {
+		return nil, err
+	}
+
+	q := `
+		UPDATE label_rules
+		SET name         = $3,
+		    field        = $4,
+		    contains     = $5,
+		    add_labels   = $6,
+		    set_priority = $7,
+		    enabled      = COALESCE($8, enabled),
+		    updated_at   = $9
+		WHERE team_id = $1 AND id = $2
+		RETURNING ` + ruleColumns
+
+	r, err := scanRule(s.pool.QueryRow(ctx, q,
+		teamID,
+		ruleID,
+		in.Name,
+		in.Field,
+		in.Contains,
+		in.AddLabels,
+		in.SetPriority,
+		in.Enabled,
+		now.UTC(),
+	))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrRuleNotFound
+		}
+		return nil, fmt.Errorf("update label rule id=%s: %w", ruleID, err)
+	}
+	return r, nil
+}
+
+func (s *PGLabelRuleStore) Delete(ctx context.Context, teamID, ruleID uuid.UUID) error {
+	ct, err := s.pool.Exec(ctx, `DELETE FROM label_rules WHERE team_id = $1 AND id = $2`, teamID, ruleID)
+	if err != nil {
+		return fmt.Errorf("delete label rule id=%s: %w", ruleID, err)
+	}
+	if ct.RowsAffected() == 0 {
+		return ErrRuleNotFound
+	}
+	return nil
+}
+
+var _ LabelRuleStore = (*PGLabelRuleStore)(nil)
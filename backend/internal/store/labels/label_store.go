@@ -0,0 +1,311 @@
+package store
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/diagnosis/interactive-todo/api/types"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type Label = types.Label
+
+// MaxNameLength bounds label names.
+const MaxNameLength = 50
+
+var (
+	ErrLabelNotFound = errors.New("label not found")
+	// ErrLabelExists is returned when another label in the team already
+	// has the name, ignoring case.
+	ErrLabelExists = errors.New("label name already in use")
+)
+
+type LabelStore interface {
+	// List returns the team's labels ordered by name.
+	List(ctx context.Context, teamID uuid.UUID) ([]Label, error)
+	Get(ctx context.Context, teamID, labelID uuid.UUID) (*Label, error)
+	Create(ctx context.Context, teamID uuid.UUID, name string, now time.Time) (*Label, error)
+	Rename(ctx context.Context, teamID, labelID uuid.UUID, name string) (*Label, error)
+	// Delete removes the label from the team and from every task it was on.
+	Delete(ctx context.Context, teamID, labelID uuid.UUID) error
+	// Ensure returns the team's labels with these names, creating missing
+	// ones. Names are trimmed, matched ignoring case and deduplicated.
+	Ensure(ctx context.Context, teamID uuid.UUID, names []string, now time.Time) ([]Label, error)
+	// Attach links labels to a task and returns the IDs that were not
+	// already on it.
+	Attach(ctx context.Context, taskID uuid.UUID, labelIDs []uuid.UUID, now time.Time) ([]uuid.UUID, error)
+	// Detach unlinks a label from a task and reports whether it was on it.
+	Detach(ctx context.Context, taskID, labelID uuid.UUID) (bool, error)
+	ListForTask(ctx context.Context, taskID uuid.UUID) ([]Label, error)
+	// ListForTasks returns the labels of each task, keyed by task ID. Tasks
+	// without labels are left out.
+	ListForTasks(ctx context.Context, taskIDs []uuid.UUID) (map[uuid.UUID][]Label, error)
+}
+
+type PGLabelStore struct {
+	pool *pgxpool.Pool
+}
+
+func NewPGLabelStore(pool *pgxpool.Pool) *PGLabelStore {
+	return &PGLabelStore{pool: pool}
+}
+
+// NormalizeNames trims names, drops empty ones and case-insensitive
+// duplicates, keeping the first spelling.
+func NormalizeNames(names []string) []string {
+	seen := make(map[string]bool, len(names))
+	out := make([]string, 0, len(names))
+	for _, n := range names {
+		n = strings.TrimSpace(n)
+		key := strings.ToLower(n)
+		if n == "" || seen[key] {
+			continue
+		}
+		seen[key] = true
+		out = append(out, n)
+	}
+	return out
+}
+
+func isUniqueViolation(err error) bool {
+	var pgErr *pgconn.PgError
+	return errors.As(err, &pgErr) && pgErr.Code == "23505"
+}
+
+func (s *PGLabelStore) List(ctx context.Context, teamID uuid.UUID) ([]Label, error) {
+	const q = `
+		SELECT id, team_id, name, created_at
+		FROM labels
+		WHERE team_id = $1
+		ORDER BY lower(name)
+	`
+	rows, err := s.pool.Query(ctx, q, teamID)
+	if err != nil {
+		return nil, fmt.Errorf("list labels team_id=%s: %w", teamID, err)
+	}
+	defer rows.Close()
+
+	labels := []Label{}
+	for rows.Next() {
+		var l Label
+		if err := rows.Scan(&l.ID, &l.TeamID, &l.Name, &l.CreatedAt); err != nil {
+			return nil, fmt.Errorf("scan label: %w", err)
+		}
+		labels = append(labels, l)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("list labels team_id=%s: %w", teamID, err)
+	}
+	return labels, nil
+}
+
+func (s *PGLabelStore) Get(ctx context.Context, teamID, labelID uuid.UUID) (*Label, error) {
+	const q = `
+		SELECT id, team_id, name, created_at
+		FROM labels
+		WHERE id = $1 AND team_id = $2
+	`
+	var l Label
+	err := s.pool.QueryRow(ctx, q, labelID, teamID).Scan(&l.ID, &l.TeamID, &l.Name, &l.CreatedAt)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrLabelNotFound
+		}
+		return nil, fmt.Errorf("get label id=%s: %w", labelID, err)
+	}
+	return &l, nil
+}
+
+func (s *PGLabelStore) Create(ctx context.Context, teamID uuid.UUID, name string, now time.Time) (*Label, error) {
+	const q = `
+		INSERT INTO labels (team_id, name, created_at)
+		VALUES ($1, $2, $3)
+		RETURNING id, team_id, name, created_at
+	`
+	var l Label
+	err := s.pool.QueryRow(ctx, q, teamID, strings.TrimSpace(name), now.UTC()).Scan(&l.ID, &l.TeamID, &l.Name, &l.CreatedAt)
+	if err != nil {
+		if isUniqueViolation(err) {
+			return nil, ErrLabelExists
+		}
+		return nil, fmt.Errorf("create label team_id=%s: %w", teamID, err)
+	}
+	return &l, nil
+}
+
+// Rename changes the spelling of a label. Changing only its case is
+// allowed.
+func (s *PGLabelStore) Rename(ctx context.Context, teamID, labelID uuid.UUID, name string) (*Label, error) {
+	const q = `
+		UPDATE labels
+		SET name = $3
+		WHERE id = $1 AND team_id = $2
+		RETURNING id, team_id, name, created_at
+	`
+	var l Label
+	err := s.pool.QueryRow(ctx, q, labelID, teamID, strings.TrimSpace(name)).Scan(&l.ID, &l.TeamID, &l.Name, &l.CreatedAt)
+	if err != nil {
+		switch {
+		case errors.Is(err, pgx.ErrNoRows):
+			return nil, ErrLabelNotFound
+		case isUniqueViolation(err):
+			return nil, ErrLabelExists
+		}
+		return nil, fmt.Errorf("rename label id=%s: %w", labelID, err)
+	}
+	return &l, nil
+}
+
+func (s *PGLabelStore) Delete(ctx context.Context, teamID, labelID uuid.UUID) error {
+	const q = `DELETE FROM labels WHERE id = $1 AND team_id = $2`
+	tag, err := s.pool.Exec(ctx, q, labelID, teamID)
+	if err != nil {
+		return fmt.Errorf("delete label id=%s: %w", labelID, err)
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrLabelNotFound
+	}
+	return nil
+}
+
+func (s *PGLabelStore) Ensure(ctx context.Context, teamID uuid.UUID, names []string, now time.Time) ([]Label, error) {
+	names = NormalizeNames(names)
+	if len(names) == 0 {
+		return nil, nil
+	}
+
+	// the no-op update makes RETURNING yield existing rows too
+	const q = `
+		INSERT INTO labels (team_id, name, created_at)
+		SELECT $1, n, $3 FROM unnest($2::text[]) AS n
+		ON CONFLICT (team_id, lower(name)) DO UPDATE SET name = labels.name
+		RETURNING id, team_id, name, created_at
+	`
+	rows, err := s.pool.Query(ctx, q, teamID, names, now.UTC())
+	if err != nil {
+		return nil, fmt.Errorf("ensure labels team_id=%s: %w", teamID, err)
+	}
+	defer rows.Close()
+
+	var labels []Label
+	for rows.Next() {
+		var l Label
+		if err := rows.Scan(&l.ID, &l.TeamID, &l.Name, &l.CreatedAt); err != nil {
+			return nil, fmt.Errorf("scan label: %w", err)
+		}
+		labels = append(labels, l)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("ensure labels team_id=%s: %w", teamID, err)
+	}
+	return labels, nil
+}
+
+func (s *PGLabelStore) Attach(ctx context.Context, taskID uuid.UUID, labelIDs []uuid.UUID, now time.Time) ([]uuid.UUID, error) {
+	if len(labelIDs) == 0 {
+		return nil, nil
+	}
+	const q = `
+		INSERT INTO task_labels (task_id, label_id, created_at)
+		SELECT $1, l, $3 FROM unnest($2::uuid[]) AS l
+		ON CONFLICT (task_id, label_id) DO NOTHING
+		RETURNING label_id
+	`
+	rows, err := s.pool.Query(ctx, q, taskID, labelIDs, now.UTC())
+	if err != nil {
+		return nil, fmt.Errorf("attach labels task_id=%s: %w", taskID, err)
+	}
+	defer rows.Close()
+
+	var added []uuid.UUID
+	for rows.Next() {
+		var id uuid.UUID
+		if err := rows.Scan(&id); err != nil {
+			return nil, fmt.Errorf("scan attached label: %w", err)
+		}
+		added = append(added, id)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("attach labels task_id=%s: %w", taskID, err)
+	}
+	return added, nil
+}
+
+func (s *PGLabelStore) Detach(ctx context.Context, taskID, labelID uuid.UUID) (bool, error) {
+	const q = `DELETE FROM task_labels WHERE task_id = $1 AND label_id = $2`
+	tag, err := s.pool.Exec(ctx, q, taskID, labelID)
+	if err != nil {
+		return false, fmt.Errorf("detach label task_id=%s label_id=%s: %w", taskID, labelID, err)
+	}
+	return tag.RowsAffected() > 0, nil
+}
+
+func (s *PGLabelStore) ListForTask(ctx context.Context, taskID uuid.UUID) ([]Label, error) {
+	const q = `
+		SELECT l.id, l.team_id, l.name, l.created_at
+		FROM task_labels tl
+		JOIN labels l ON l.id = tl.label_id
+		WHERE tl.task_id = $1
+		ORDER BY lower(l.name)
+	`
+	rows, err := s.pool.Query(ctx, q, taskID)
+	if err != nil {
+		return nil, fmt.Errorf("list labels task_id=%s: %w", taskID, err)
+	}
+	defer rows.Close()
+
+	labels := []Label{}
+	for rows.Next() {
+		var l Label
+		if err := rows.Scan(&l.ID, &l.TeamID, &l.Name, &l.CreatedAt); err != nil {
+			return nil, fmt.Errorf("scan label: %w", err)
+		}
+		labels = append(labels, l)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("list labels task_id=%s: %w", taskID, err)
+	}
+	return labels, nil
+}
+
+func (s *PGLabelStore) ListForTasks(ctx context.Context, taskIDs []uuid.UUID) (map[uuid.UUID][]Label, error) {
+	out := make(map[uuid.UUID][]Label)
+	if len(taskIDs) == 0 {
+		return out, nil
+	}
+	const q = `
+		SELECT tl.task_id, l.id, l.team_id, l.name, l.created_at
+		FROM task_labels tl
+		JOIN labels l ON l.id = tl.label_id
+		WHERE tl.task_id = ANY($1::uuid[])
+		ORDER BY tl.task_id, lower(l.name)
+	`
+	rows, err := s.pool.Query(ctx, q, taskIDs)
+	if err != nil {
+		return nil, fmt.Errorf("list labels for %d tasks: %w", len(taskIDs), err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var (
+			taskID uuid.UUID
+			l      Label
+		)
+		if err := rows.Scan(&taskID, &l.ID, &l.TeamID, &l.Name, &l.CreatedAt); err != nil {
+			return nil, fmt.Errorf("scan task label: %w", err)
+		}
+		out[taskID] = append(out[taskID], l)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("list labels for %d tasks: %w", len(taskIDs), err)
+	}
+	return out, nil
+}
+
+var _ LabelStore = (*PGLabelStore)(nil)
@@ -0,0 +1,162 @@
+package store
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/diagnosis/interactive-todo/api/types"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type Hold = types.LegalHold
+
+var (
+	ErrHoldNotFound   = errors.New("legal hold not found")
+	ErrAlreadyHeld    = errors.New("target already under legal hold")
+	ErrTargetNotFound = errors.New("legal hold target not found")
+)
+
+type LegalHoldStore interface {
+	PlaceOnTeam(ctx context.Context, teamID uuid.UUID, reason string, placedBy uuid.UUID, now time.Time) (*Hold, error)
+	PlaceOnTask(ctx context.Context, taskID uuid.UUID, reason string, placedBy uuid.UUID, now time.Time) (*Hold, error)
+	Release(ctx context.Context, id uuid.UUID) (*Hold, error)
+	List(ctx context.Context) ([]Hold, error)
+}
+
+// NOTE: order must match scanHold
+const holdColumns = `
+    id,
+    team_id,
+    task_id,
+    reason,
+    placed_by,
+    placed_at
+`
+
+func scanHold(row pgx.Row) (*Hold, error) {
+	var h Hold
+	if err := row.Scan(
+		&h.ID,
+		&h.TeamID,
+		&h.TaskID,
+		&h.Reason,
+		&h.PlacedBy,
+		&h.PlacedAt,
+	); err != nil {
+		return nil, err
+	}
+	return &h, nil
+}
+
+type PGLegalHoldStore struct {
+	pool *pgxpool.Pool
+}
+
+func NewPGLegalHoldStore(pool *pgxpool.Pool) *PGLegalHoldStore {
+	return &PGLegalHoldStore{pool: pool}
+}
+
+func (s *PGLegalHoldStore) PlaceOnTeam(
+	ctx context.Context,
+	teamID uuid.UUID,
+	reason string,
+	placedBy uuid.UUID,
+	now time.Time,
+) (*Hold, error) {
+	const q = `
+		INSERT INTO legal_holds (team_id, reason, placed_by, placed_at)
+		VALUES ($1, $2, $3, $4)
+		RETURNING ` + holdColumns
+
+	h, err := scanHold(s.pool.QueryRow(ctx, q, teamID, reason, placedBy, now.UTC()))
+	if err != nil {
+		return nil, mapPlaceError(err, "team_id", teamID)
+	}
+	return h, nil
+}
+
+// PlaceOnTask copies the task's team onto the hold so team deletion is
+// refused as well while the task is held.
+func (s *PGLegalHoldStore) PlaceOnTask(
+	ctx context.Context,
+	taskID uuid.UUID,
+	reason string,
+	placedBy uuid.UUID,
+	now time.Time,
+) (*Hold, error) {
+	const q = `
+		INSERT INTO legal_holds (team_id, task_id, reason, placed_by, placed_at)
+		SELECT t.team_id, t.id, $2, $3, $4
+		FROM tasks t
+		WHERE t.id = $1
+		RETURNING ` + holdColumns
+
+	h, err := scanHold(s.pool.QueryRow(ctx, q, taskID, reason, placedBy, now.UTC()))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrTargetNotFound
+		}
+		return nil, mapPlaceError(err, "task_id", taskID)
+	}
+	return h, nil
+}
+
+func (s *PGLegalHoldStore) Release(ctx context.Context, id uuid.UUID) (*Hold, error) {
+	const q = `
+		DELETE FROM legal_holds
+		WHERE id = $1
+		RETURNING ` + holdColumns
+
+	h, err := scanHold(s.pool.QueryRow(ctx, q, id))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrHoldNotFound
+		}
+		return nil, fmt.Errorf("release legal hold id=%s: %w", id, err)
+	}
+	return h, nil
+}
+
+func (s *PGLegalHoldStore) List(ctx context.Context) ([]Hold, error) {
+	const q = `
+		SELECT ` + holdColumns + `
+		FROM legal_holds
+		ORDER BY placed_at DESC
+	`
+
+	rows, err := s.pool.Query(ctx, q)
+	if err != nil {
+		return nil, fmt.Errorf("list legal holds: %w", err)
+	}
+	defer rows.Close()
+
+	holds := []Hold{}
+	for rows.Next() {
+		h, err := scanHold(rows)
+		if err != nil {
+			return nil, err
+		}
+		holds = append(holds, *h)
+	}
+	return holds, rows.Err()
+}
+
+func mapPlaceError(err error, key string, id uuid.UUID) error {
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) {
+		switch pgErr.Code {
+		case "23505":
+			return ErrAlreadyHeld
+		case "23503":
+			return ErrTargetNotFound
+		}
+	}
+	return fmt.Errorf("place legal hold %s=%s: %w", key, id, err)
+}
+
+var _ LegalHoldStore = (*PGLegalHoldStore)(nil)
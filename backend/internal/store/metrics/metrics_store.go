@@ -0,0 +1,181 @@
+package store
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/diagnosis/interactive-todo/api/types"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type (
+	UserCounts     = types.UserCounts
+	DailyTaskCount = types.DailyTaskCount
+	TeamActivity   = types.TeamActivity
+	JobBacklog     = types.JobBacklog
+)
+
+type MetricsStore interface {
+	CountUsers(ctx context.Context) (*UserCounts, error)
+	CountActiveSessions(ctx context.Context, now time.Time) (int, error)
+	TasksPerDay(ctx context.Context, since time.Time) ([]DailyTaskCount, error)
+	TopTeamsByActivity(ctx context.Context, since time.Time, limit int) ([]TeamActivity, error)
+	JobBacklog(ctx context.Context, now time.Time) (*JobBacklog, error)
+}
+
+type PGMetricsStore struct {
+	pool *pgxpool.Pool
+}
+
+func NewPGMetricsStore(pool *pgxpool.Pool) *PGMetricsStore {
+	return &PGMetricsStore{pool: pool}
+}
+
+func (s *PGMetricsStore) CountUsers(ctx context.Context) (*UserCounts, error) {
+	const q = `
+		SELECT user_type, COUNT(*)
+		FROM users
+		GROUP BY user_type
+	`
+
+	rows, err := s.pool.Query(ctx, q)
+	if err != nil {
+		return nil, fmt.Errorf("count users: %w", err)
+	}
+	defer rows.Close()
+
+	out := &UserCounts{ByType: map[string]int{}}
+	for rows.Next() {
+		var (
+			userType string
+			n        int
+		)
+		if err := rows.Scan(&userType, &n); err != nil {
+			return nil, fmt.Errorf("count users: scan: %w", err)
+		}
+		out.ByType[userType] = n
+		out.Total += n
+	}
+	return out, rows.Err()
+}
+
+// CountActiveSessions counts distinct users holding a live refresh token
+func (s *PGMetricsStore) CountActiveSessions(ctx context.Context, now time.Time) (int, error) {
+	const q = `
+		SELECT COUNT(DISTINCT user_id)
+		FROM auth_refresh_tokens
+		WHERE revoked_at IS NULL
+		  AND expires_at > $1
+	`
+
+	var n int
+	if err := s.pool.QueryRow(ctx, q, now.UTC()).Scan(&n); err != nil {
+		return 0, fmt.Errorf("count active sessions: %w", err)
+	}
+	return n, nil
+}
+
+// TasksPerDay returns one row per UTC day since the given time, including
+// empty days. Tasks count as completed on the day of their completed_at.
+func (s *PGMetricsStore) TasksPerDay(ctx context.Context, since time.Time) ([]DailyTaskCount, error) {
+	const q = `
+		WITH days AS (
+			SELECT generate_series(
+				date_trunc('day', $1::timestamptz AT TIME ZONE 'UTC'),
+				date_trunc('day', now() AT TIME ZONE 'UTC'),
+				interval '1 day'
+			) AS day
+		)
+		SELECT
+			d.day,
+			(SELECT COUNT(*) FROM tasks t
+			  WHERE t.deleted_at IS NULL
+			    AND t.created_at >= d.day AT TIME ZONE 'UTC'
+			    AND t.created_at <  (d.day + interval '1 day') AT TIME ZONE 'UTC'),
+			(SELECT COUNT(*) FROM tasks t
+			  WHERE t.deleted_at IS NULL
+			    AND t.completed_at >= d.day AT TIME ZONE 'UTC'
+			    AND t.completed_at <  (d.day + interval '1 day') AT TIME ZONE 'UTC')
+		FROM days d
+		ORDER BY d.day
+	`
+
+	rows, err := s.pool.Query(ctx, q, since.UTC())
+	if err != nil {
+		return nil, fmt.Errorf("tasks per day: %w", err)
+	}
+	defer rows.Close()
+
+	var out []DailyTaskCount
+	for rows.Next() {
+		var c DailyTaskCount
+		if err := rows.Scan(&c.Day, &c.Created, &c.Completed); err != nil {
+			return nil, fmt.Errorf("tasks per day: scan: %w", err)
+		}
+		c.Day = c.Day.UTC()
+		out = append(out, c)
+	}
+	return out, rows.Err()
+}
+
+func (s *PGMetricsStore) TopTeamsByActivity(ctx context.Context, since time.Time, limit int) ([]TeamActivity, error) {
+	if limit <= 0 || limit > 100 {
+		limit = 10
+	}
+
+	const q = `
+		SELECT
+			tm.id,
+			tm.name,
+			COUNT(*) FILTER (WHERE t.created_at >= $1),
+			COUNT(*) FILTER (WHERE t.updated_at >= $1)
+		FROM teams tm
+		JOIN tasks t ON t.team_id = tm.id
+		WHERE t.updated_at >= $1
+		  AND t.deleted_at IS NULL
+		GROUP BY tm.id, tm.name
+		ORDER BY COUNT(*) FILTER (WHERE t.updated_at >= $1) DESC, tm.name
+		LIMIT $2
+	`
+
+	rows, err := s.pool.Query(ctx, q, since.UTC(), limit)
+	if err != nil {
+		return nil, fmt.Errorf("top teams by activity: %w", err)
+	}
+	defer rows.Close()
+
+	var out []TeamActivity
+	for rows.Next() {
+		var a TeamActivity
+		if err := rows.Scan(&a.TeamID, &a.Name, &a.TasksCreated, &a.TasksUpdated); err != nil {
+			return nil, fmt.Errorf("top teams by activity: scan: %w", err)
+		}
+		out = append(out, a)
+	}
+	return out, rows.Err()
+}
+
+func (s *PGMetricsStore) JobBacklog(ctx context.Context, now time.Time) (*JobBacklog, error) {
+	const q = `
+		SELECT
+			(SELECT COUNT(*) FROM task_reminders r
+			  JOIN tasks t ON t.id = r.task_id
+			  WHERE r.sent_at IS NULL
+			    AND t.due_at - make_interval(mins => r.offset_minutes) <= $1
+			    AND t.due_at > $1 - interval '5 minutes'
+			    AND t.status IN ('open', 'in_progress')
+			    AND t.deleted_at IS NULL
+			    AND t.archived_at IS NULL),
+			(SELECT COUNT(*) FROM auth_refresh_tokens
+			  WHERE expires_at < $1)
+	`
+
+	var b JobBacklog
+	if err := s.pool.QueryRow(ctx, q, now.UTC()).Scan(&b.RemindersPending, &b.ExpiredRefreshTokens); err != nil {
+		return nil, fmt.Errorf("job backlog: %w", err)
+	}
+	return &b, nil
+}
+
+var _ MetricsStore = (*PGMetricsStore)(nil)
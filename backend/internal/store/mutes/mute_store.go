@@ -0,0 +1,94 @@
+package store
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type Mute struct {
+	UserID     uuid.UUID `json:"user_id"`
+	Reason     string    `json:"reason"`
+	MutedUntil time.Time `json:"muted_until"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+var (
+	ErrMuteNotFound = errors.New("mute not found")
+)
+
+type MuteStore interface {
+	Mute(ctx context.Context, userID uuid.UUID, until time.Time, reason string, now time.Time) (*Mute, error)
+	GetActive(ctx context.Context, userID uuid.UUID, now time.Time) (*Mute, error)
+	Unmute(ctx context.Context, userID uuid.UUID) (bool, error)
+}
+
+type PGMuteStore struct {
+	pool *pgxpool.Pool
+}
+
+func NewPGMuteStore(pool *pgxpool.Pool) *PGMuteStore {
+	return &PGMuteStore{pool: pool}
+}
+
+// Mute upserts the mute so repeated offences extend the existing window.
+func (s *PGMuteStore) Mute(
+	ctx context.Context,
+	userID uuid.UUID,
+	until time.Time,
+	reason string,
+	now time.Time,
+) (*Mute, error) {
+	const q = `
+		INSERT INTO user_mutes (user_id, reason, muted_until, created_at)
+		VALUES ($1, $2, $3, $4)
+		ON CONFLICT (user_id) DO UPDATE
+		SET reason      = EXCLUDED.reason,
+		    muted_until = GREATEST(user_mutes.muted_until, EXCLUDED.muted_until),
+		    created_at  = EXCLUDED.created_at
+		RETURNING user_id, reason, muted_until, created_at
+	`
+
+	var m Mute
+	if err := s.pool.QueryRow(ctx, q, userID, reason, until.UTC(), now.UTC()).
+		Scan(&m.UserID, &m.Reason, &m.MutedUntil, &m.CreatedAt); err != nil {
+		return nil, fmt.Errorf("mute user_id=%s: %w", userID, err)
+	}
+	return &m, nil
+}
+
+func (s *PGMuteStore) GetActive(ctx context.Context, userID uuid.UUID, now time.Time) (*Mute, error) {
+	const q = `
+		SELECT user_id, reason, muted_until, created_at
+		FROM user_mutes
+		WHERE user_id = $1
+		  AND muted_until > $2
+	`
+
+	var m Mute
+	if err := s.pool.QueryRow(ctx, q, userID, now.UTC()).
+		Scan(&m.UserID, &m.Reason, &m.MutedUntil, &m.CreatedAt); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrMuteNotFound
+		}
+		return nil, fmt.Errorf("get active mute user_id=%s: %w", userID, err)
+	}
+	return &m, nil
+}
+
+func (s *PGMuteStore) Unmute(ctx context.Context, userID uuid.UUID) (bool, error) {
+	const q = `DELETE FROM user_mutes WHERE user_id = $1`
+
+	ct, err := s.pool.Exec(ctx, q, userID)
+	if err != nil {
+		return false, fmt.Errorf("unmute user_id=%s: %w", userID, err)
+	}
+	return ct.RowsAffected() == 1, nil
+}
+
+var _ MuteStore = (*PGMuteStore)(nil)
@@ -0,0 +1,137 @@
+package store
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/diagnosis/interactive-todo/api/types"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type OutOfOffice = types.OutOfOffice
+
+// MaxDuration bounds how long a single absence can last.
+const MaxDuration = 365 * 24 * time.Hour
+
+var (
+	ErrOutOfOfficeNotFound = errors.New("out of office not found")
+	ErrBackupNotFound      = errors.New("backup user not found")
+)
+
+type OutOfOfficeStore interface {
+	// Get returns the user's window unless it has ended by now.
+	Get(ctx context.Context, userID uuid.UUID, now time.Time) (*OutOfOffice, error)
+	// GetActive returns the user's window only if now falls inside it.
+	GetActive(ctx context.Context, userID uuid.UUID, now time.Time) (*OutOfOffice, error)
+	// Set stores the user's window, replacing the one they had.
+	Set(ctx context.Context, o OutOfOffice, now time.Time) (*OutOfOffice, error)
+	Clear(ctx context.Context, userID uuid.UUID) (bool, error)
+}
+
+type PGOutOfOfficeStore struct {
+	pool *pgxpool.Pool
+}
+
+func NewPGOutOfOfficeStore(pool *pgxpool.Pool) *PGOutOfOfficeStore {
+	return &PGOutOfOfficeStore{pool: pool}
+}
+
+var _ OutOfOfficeStore = (*PGOutOfOfficeStore)(nil)
+
+// NOTE: order must match scanOutOfOffice
+const outOfOfficeColumns = `
+    user_id,
+    starts_at,
+    ends_at,
+    backup_id,
+    note,
+    updated_at
+`
+
+func scanOutOfOffice(row pgx.Row, now time.Time) (*OutOfOffice, error) {
+	var o OutOfOffice
+	err := row.Scan(
+		&o.UserID,
+		&o.StartsAt,
+		&o.EndsAt,
+		&o.BackupID,
+		&o.Note,
+		&o.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	o.Active = !now.Before(o.StartsAt) && now.Before(o.EndsAt)
+	return &o, nil
+}
+
+func (s *PGOutOfOfficeStore) Get(ctx context.Context, userID uuid.UUID, now time.Time) (*OutOfOffice, error) {
+	q := `
+		SELECT ` + outOfOfficeColumns + `
+		FROM user_out_of_office
+		WHERE user_id = $1 AND ends_at > $2
+	`
+	o, err := scanOutOfOffice(s.pool.QueryRow(ctx, q, userID, now.UTC()), now)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrOutOfOfficeNotFound
+		}
+		return nil, fmt.Errorf("get out of office user_id=%s: %w", userID, err)
+	}
+	return o, nil
+}
+
+func (s *PGOutOfOfficeStore) GetActive(ctx context.Context, userID uuid.UUID, now time.Time) (*OutOfOffice, error) {
+	q := `
+		SELECT ` + outOfOfficeColumns + `
+		FROM user_out_of_office
+		WHERE user_id = $1 AND starts_at <= $2 AND ends_at > $2
+	`
+	o, err := scanOutOfOffice(s.pool.QueryRow(ctx, q, userID, now.UTC()), now)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrOutOfOfficeNotFound
+		}
+		return nil, fmt.Errorf("get active out of office user_id=%s: %w", userID, err)
+	}
+	return o, nil
+}
+
+func (s *PGOutOfOfficeStore) Set(ctx context.Context, o OutOfOffice, now time.Time) (*OutOfOffice, error) {
+	q := `
+		INSERT INTO user_out_of_office (user_id, starts_at, ends_at, backup_id, note, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		ON CONFLICT (user_id) DO UPDATE
+		SET starts_at  = EXCLUDED.starts_at,
+		    ends_at    = EXCLUDED.ends_at,
+		    backup_id  = EXCLUDED.backup_id,
+		    note       = EXCLUDED.note,
+		    updated_at = EXCLUDED.updated_at
+		RETURNING ` + outOfOfficeColumns
+
+	row := s.pool.QueryRow(ctx, q, o.UserID, o.StartsAt.UTC(), o.EndsAt.UTC(), o.BackupID, o.Note, now.UTC())
+	saved, err := scanOutOfOffice(row, now)
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == "23503" {
+			return nil, ErrBackupNotFound
+		}
+		return nil, fmt.Errorf("set out of office user_id=%s: %w", o.UserID, err)
+	}
+	return saved, nil
+}
+
+func (s *PGOutOfOfficeStore) Clear(ctx context.Context, userID uuid.UUID) (bool, error) {
+	const q = `DELETE FROM user_out_of_office WHERE user_id = $1`
+
+	ct, err := s.pool.Exec(ctx, q, userID)
+	if err != nil {
+		return false, fmt.Errorf("clear out of office user_id=%s: %w", userID, err)
+	}
+	return ct.RowsAffected() == 1, nil
+}
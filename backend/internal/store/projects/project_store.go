@@ -0,0 +1,195 @@
+package store
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/diagnosis/interactive-todo/api/types"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type Project = types.Project
+
+// MaxNameLength bounds project names.
+const MaxNameLength = 100
+
+var (
+	ErrProjectNotFound = errors.New("project not found")
+	// ErrProjectExists is returned when another project in the team
+	// already has the name, ignoring case.
+	ErrProjectExists = errors.New("project name already in use")
+)
+
+type ProjectStore interface {
+	// List returns the team's projects ordered by name.
+	List(ctx context.Context, teamID uuid.UUID) ([]Project, error)
+	Get(ctx context.Context, teamID, projectID uuid.UUID) (*Project, error)
+	Create(ctx context.Context, teamID uuid.UUID, name string, description *string, createdBy uuid.UUID, now time.Time) (*Project, error)
+	// Update changes the fields that are not nil; an empty description
+	// removes it.
+	Update(ctx context.Context, teamID, projectID uuid.UUID, name, description *string, now time.Time) (*Project, error)
+	// Delete removes the project; its tasks stay in the team without one.
+	Delete(ctx context.Context, teamID, projectID uuid.UUID) error
+}
+
+type PGProjectStore struct {
+	pool *pgxpool.Pool
+}
+
+func NewPGProjectStore(pool *pgxpool.Pool) *PGProjectStore {
+	return &PGProjectStore{pool: pool}
+}
+
+var _ ProjectStore = (*PGProjectStore)(nil)
+
+// NOTE: order must match scanProject
+const projectColumns = `
+    p.id,
+    p.team_id,
+    p.name,
+    p.description,
+    p.created_by,
+    p.created_at,
+    p.updated_at,
+    (SELECT count(*) FROM tasks t
+     WHERE t.team_id = p.team_id AND t.project_id = p.id
+       AND t.status IN ('open', 'in_progress')
+       AND t.deleted_at IS NULL AND t.archived_at IS NULL)
+`
+
+func scanProject(row pgx.Row) (*Project, error) {
+	var p Project
+	err := row.Scan(
+		&p.ID,
+		&p.TeamID,
+		&p.Name,
+		&p.Description,
+		&p.CreatedBy,
+		&p.CreatedAt,
+		&p.UpdatedAt,
+		&p.OpenTasks,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &p, nil
+}
+
+func isUniqueViolation(err error) bool {
+	var pgErr *pgconn.PgError
+	return errors.As(err, &pgErr) && pgErr.Code == "23505"
+}
+
+func (s *PGProjectStore) List(ctx context.Context, teamID uuid.UUID) ([]Project, error) {
+	q := `
+		SELECT ` + projectColumns + `
+		FROM projects p
+		WHERE p.team_id = $1
+		ORDER BY lower(p.name)
+	`
+	rows, err := s.pool.Query(ctx, q, teamID)
+	if err != nil {
+		return nil, fmt.Errorf("list projects team_id=%s: %w", teamID, err)
+	}
+	defer rows.Close()
+
+	projects := []Project{}
+	for rows.Next() {
+		p, err := scanProject(rows)
+		if err != nil {
+			return nil, fmt.Errorf("scan project: %w", err)
+		}
+		projects = append(projects, *p)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("list projects team_id=%s: %w", teamID, err)
+	}
+	return projects, nil
+}
+
+func (s *PGProjectStore) Get(ctx context.Context, teamID, projectID uuid.UUID) (*Project, error) {
+	q := `
+		SELECT ` + projectColumns + `
+		FROM projects p
+		WHERE p.id = $1 AND p.team_id = $2
+	`
+	p, err := scanProject(s.pool.QueryRow(ctx, q, projectID, teamID))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrProjectNotFound
+		}
+		return nil, fmt.Errorf("get project id=%s: %w", projectID, err)
+	}
+	return p, nil
+}
+
+func (s *PGProjectStore) Create(
+	ctx context.Context,
+	teamID uuid.UUID,
+	name string,
+	description *string,
+	createdBy uuid.UUID,
+	now time.Time,
+) (*Project, error) {
+	const q = `
+		INSERT INTO projects (team_id, name, description, created_by, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $5)
+		RETURNING id
+	`
+	var id uuid.UUID
+	err := s.pool.QueryRow(ctx, q, teamID, strings.TrimSpace(name), description, createdBy, now.UTC()).Scan(&id)
+	if err != nil {
+		if isUniqueViolation(err) {
+			return nil, ErrProjectExists
+		}
+		return nil, fmt.Errorf("create project team_id=%s: %w", teamID, err)
+	}
+	return s.Get(ctx, teamID, id)
+}
+
+func (s *PGProjectStore) Update(
+	ctx context.Context,
+	teamID, projectID uuid.UUID,
+	name, description *string,
+	now time.Time,
+) (*Project, error) {
+	const q = `
+		UPDATE projects
+		SET name        = COALESCE($3, name),
+		    description = CASE WHEN $4::text IS NULL THEN description ELSE NULLIF($4, '') END,
+		    updated_at  = $5
+		WHERE id = $1 AND team_id = $2
+	`
+	if name != nil {
+		n := strings.TrimSpace(*name)
+		name = &n
+	}
+	ct, err := s.pool.Exec(ctx, q, projectID, teamID, name, description, now.UTC())
+	if err != nil {
+		if isUniqueViolation(err) {
+			return nil, ErrProjectExists
+		}
+		return nil, fmt.Errorf("update project id=%s: %w", projectID, err)
+	}
+	if ct.RowsAffected() == 0 {
+		return nil, ErrProjectNotFound
+	}
+	return s.Get(ctx, teamID, projectID)
+}
+
+func (s *PGProjectStore) Delete(ctx context.Context, teamID, projectID uuid.UUID) error {
+	ct, err := s.pool.Exec(ctx, `DELETE FROM projects WHERE id = $1 AND team_id = $2`, projectID, teamID)
+	if err != nil {
+		return fmt.Errorf("delete project id=%s: %w", projectID, err)
+	}
+	if ct.RowsAffected() == 0 {
+		return ErrProjectNotFound
+	}
+	return nil
+}
@@ -0,0 +1,326 @@
+package store
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/diagnosis/interactive-todo/api/types"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type ProvisionedUser = types.ProvisionedUser
+type ProvisionedTeam = types.ProvisionedTeam
+
+var (
+	// ErrEmailTaken: the email belongs to a user with another external id.
+	ErrEmailTaken = errors.New("email belongs to another provisioned user")
+	// ErrPasswordRequired: a new user needs a password.
+	ErrPasswordRequired = errors.New("password is required to create a user")
+	// ErrTeamNameTaken: another team has the name.
+	ErrTeamNameTaken = errors.New("team name already taken")
+	// ErrUnknownUser: a team names an external id no user has.
+	ErrUnknownUser = errors.New("no user has external id")
+	// ErrOwnUserType: admins cannot change their own user type.
+	ErrOwnUserType = errors.New("cannot change your own user_type")
+	// ErrConcurrentPut: another PUT created the same external id first;
+	// applying again succeeds.
+	ErrConcurrentPut = errors.New("created concurrently, retry")
+)
+
+// UserSpec is the wanted state of a user. PasswordHash is only used on
+// create and may be empty otherwise.
+type UserSpec struct {
+	Email        string
+	UserType     types.UserType
+	PasswordHash string
+	// ActorID is the admin applying the spec, whose own type must stay
+	ActorID uuid.UUID
+}
+
+// TeamSpec is the wanted state of a team. Owner and Members are user
+// external ids; Members must not repeat anyone or name the owner.
+type TeamSpec struct {
+	Name    string
+	Owner   string
+	Members []types.ProvisionedMember
+}
+
+// ProvisioningStore brings users and teams to a wanted state, keyed by an
+// id chosen by the caller's tooling. Each PUT is idempotent.
+type ProvisioningStore interface {
+	// PutUser finds the user by external id, or else adopts the user with
+	// the email if it has no external id yet, or else creates it.
+	PutUser(ctx context.Context, externalID string, in UserSpec, now time.Time) (*ProvisionedUser, error)
+	// PutTeam finds the team by external id, or else adopts the team with
+	// the name if it has no external id yet, or else creates it. The
+	// membership is replaced by in.Owner and in.Members.
+	PutTeam(ctx context.Context, externalID string, in TeamSpec, now time.Time) (*ProvisionedTeam, error)
+}
+
+type PGProvisioningStore struct {
+	pool *pgxpool.Pool
+}
+
+func NewPGProvisioningStore(pool *pgxpool.Pool) *PGProvisioningStore {
+	return &PGProvisioningStore{pool: pool}
+}
+
+var _ ProvisioningStore = (*PGProvisioningStore)(nil)
+
+func (s *PGProvisioningStore) PutUser(ctx context.Context, externalID string, in UserSpec, now time.Time) (*ProvisionedUser, error) {
+	const (
+		find = `
+			SELECT id, external_id, user_type
+			FROM users
+			WHERE external_id = $1 OR email = $2
+			FOR UPDATE
+		`
+		insertUser = `
+			INSERT INTO users (email, password_hash, user_type, external_id, created_at, updated_at)
+			VALUES ($1, $2, $3, $4, $5, $5)
+			RETURNING id, email, user_type, created_at, updated_at
+		`
+		// a changed user type invalidates the user's tokens, as with
+		// PATCH /auth/{user_id}/update-usertype
+		updateUser = `
+			UPDATE users
+			SET token_version = token_version + CASE WHEN user_type <> $3 THEN 1 ELSE 0 END,
+			    updated_at = CASE
+			        WHEN (email, user_type, external_id) IS DISTINCT FROM ($2, $3::user_type, $4) THEN $5
+			        ELSE updated_at
+			    END,
+			    email = $2,
+			    user_type = $3,
+			    external_id = $4
+			WHERE id = $1
+			RETURNING id, email, user_type, created_at, updated_at
+		`
+	)
+	now = now.UTC()
+
+	tx, err := s.pool.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("put user: begin: %w", err)
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	rows, err := tx.Query(ctx, find, externalID, in.Email)
+	if err != nil {
+		return nil, fmt.Errorf("put user external_id=%q: find: %w", externalID, err)
+	}
+	var byExternal, byEmail *uuid.UUID
+	var emailOwner *string
+	for rows.Next() {
+		var (
+			id       uuid.UUID
+			ext      *string
+			userType types.UserType
+		)
+		if err := rows.Scan(&id, &ext, &userType); err != nil {
+			rows.Close()
+			return nil, fmt.Errorf("put user external_id=%q: scan: %w", externalID, err)
+		}
+		if id == in.ActorID && userType != in.UserType {
+			rows.Close()
+			return nil, ErrOwnUserType
+		}
+		if ext != nil && *ext == externalID {
+			byExternal = &id
+		} else {
+			byEmail, emailOwner = &id, ext
+		}
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("put user external_id=%q: find: %w", externalID, err)
+	}
+
+	out := &ProvisionedUser{ExternalID: externalID}
+	var row pgx.Row
+	switch {
+	case byEmail != nil && (byExternal != nil || emailOwner != nil):
+		return nil, ErrEmailTaken
+	case byExternal != nil:
+		row = tx.QueryRow(ctx, updateUser, *byExternal, in.Email, in.UserType, externalID, now)
+	case byEmail != nil:
+		row = tx.QueryRow(ctx, updateUser, *byEmail, in.Email, in.UserType, externalID, now)
+	case in.PasswordHash == "":
+		return nil, ErrPasswordRequired
+	default:
+		out.Created = true
+		row = tx.QueryRow(ctx, insertUser, in.Email, in.PasswordHash, in.UserType, externalID, now)
+	}
+	u := &out.User
+	if err := row.Scan(&u.ID, &u.Email, &u.UserType, &u.CreatedAt, &u.UpdatedAt); err != nil {
+		if isUniqueViolation(err) {
+			return nil, ErrConcurrentPut
+		}
+		return nil, fmt.Errorf("put user external_id=%q: %w", externalID, err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		if isUniqueViolation(err) {
+			return nil, ErrConcurrentPut
+		}
+		return nil, fmt.Errorf("put user external_id=%q: commit: %w", externalID, err)
+	}
+	return out, nil
+}
+
+func (s *PGProvisioningStore) PutTeam(ctx context.Context, externalID string, in TeamSpec, now time.Time) (*ProvisionedTeam, error) {
+	const (
+		resolve = `
+			SELECT external_id, id
+			FROM users
+			WHERE external_id = ANY($1)
+		`
+		find = `
+			SELECT id
+			FROM teams
+			WHERE external_id = $1
+			   OR (external_id IS NULL AND lower(name) = lower($2))
+			ORDER BY external_id NULLS LAST
+			LIMIT 1
+			FOR UPDATE
+		`
+		insertTeam = `
+			INSERT INTO teams (name, owner_id, external_id, created_at, updated_at)
+			VALUES ($1, $2, $3, $4, $4)
+			RETURNING id, name, owner_id, created_at, updated_at
+		`
+		updateTeam = `
+			UPDATE teams
+			SET updated_at = CASE
+			        WHEN (name, owner_id, external_id) IS DISTINCT FROM ($2, $3::uuid, $4) THEN $5
+			        ELSE updated_at
+			    END,
+			    name = $2,
+			    owner_id = $3,
+			    external_id = $4
+			WHERE id = $1
+			RETURNING id, name, owner_id, created_at, updated_at
+		`
+		upsertMembers = `
+			INSERT INTO team_members (team_id, user_id, role, created_at)
+			SELECT $1, m.user_id, m.role::team_role, $4
+			FROM unnest($2::uuid[], $3::text[]) AS m(user_id, role)
+			ON CONFLICT (team_id, user_id) DO UPDATE
+			SET role = EXCLUDED.role
+			WHERE team_members.role <> EXCLUDED.role
+		`
+		removeOthers = `
+			DELETE FROM team_members
+			WHERE team_id = $1
+			  AND NOT (user_id = ANY($2))
+		`
+	)
+	now = now.UTC()
+
+	externalIDs := make([]string, 0, len(in.Members)+1)
+	externalIDs = append(externalIDs, in.Owner)
+	roles := make([]string, 0, len(in.Members)+1)
+	roles = append(roles, string(types.TeamRoleOwner))
+	for _, m := range in.Members {
+		externalIDs = append(externalIDs, m.ExternalID)
+		roles = append(roles, string(m.Role))
+	}
+
+	tx, err := s.pool.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("put team: begin: %w", err)
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	rows, err := tx.Query(ctx, resolve, externalIDs)
+	if err != nil {
+		return nil, fmt.Errorf("put team external_id=%q: resolve users: %w", externalID, err)
+	}
+	ids := make(map[string]uuid.UUID, len(externalIDs))
+	for rows.Next() {
+		var (
+			ext string
+			id  uuid.UUID
+		)
+		if err := rows.Scan(&ext, &id); err != nil {
+			rows.Close()
+			return nil, fmt.Errorf("put team external_id=%q: scan user: %w", externalID, err)
+		}
+		ids[ext] = id
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("put team external_id=%q: resolve users: %w", externalID, err)
+	}
+	var missing []string
+	userIDs := make([]uuid.UUID, len(externalIDs))
+	for i, ext := range externalIDs {
+		id, ok := ids[ext]
+		if !ok {
+			missing = append(missing, ext)
+		}
+		userIDs[i] = id
+	}
+	if len(missing) > 0 {
+		slices.Sort(missing)
+		return nil, fmt.Errorf("%w: %s", ErrUnknownUser, strings.Join(missing, ", "))
+	}
+
+	out := &ProvisionedTeam{
+		ExternalID: externalID,
+		Owner:      in.Owner,
+		Members:    in.Members,
+	}
+	if out.Members == nil {
+		out.Members = []types.ProvisionedMember{}
+	}
+
+	var teamID uuid.UUID
+	var row pgx.Row
+	err = tx.QueryRow(ctx, find, externalID, in.Name).Scan(&teamID)
+	switch {
+	case errors.Is(err, pgx.ErrNoRows):
+		out.Created = true
+		row = tx.QueryRow(ctx, insertTeam, in.Name, userIDs[0], externalID, now)
+	case err != nil:
+		return nil, fmt.Errorf("put team external_id=%q: find: %w", externalID, err)
+	default:
+		row = tx.QueryRow(ctx, updateTeam, teamID, in.Name, userIDs[0], externalID, now)
+	}
+	t := &out.Team
+	if err := row.Scan(&t.ID, &t.Name, &t.OwnerID, &t.CreatedAt, &t.UpdatedAt); err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == "23505" && pgErr.ConstraintName == "idx_teams_external_id" {
+			return nil, ErrConcurrentPut
+		}
+		if isUniqueViolation(err) {
+			return nil, ErrTeamNameTaken
+		}
+		return nil, fmt.Errorf("put team external_id=%q: %w", externalID, err)
+	}
+
+	if _, err := tx.Exec(ctx, upsertMembers, t.ID, userIDs, roles, now); err != nil {
+		return nil, fmt.Errorf("put team id=%s: members: %w", t.ID, err)
+	}
+	ct, err := tx.Exec(ctx, removeOthers, t.ID, userIDs)
+	if err != nil {
+		return nil, fmt.Errorf("put team id=%s: remove members: %w", t.ID, err)
+	}
+	out.Removed = int(ct.RowsAffected())
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("put team id=%s: commit: %w", t.ID, err)
+	}
+	return out, nil
+}
+
+func isUniqueViolation(err error) bool {
+	var pgErr *pgconn.PgError
+	return errors.As(err, &pgErr) && pgErr.Code == "23505"
+}
@@ -0,0 +1,238 @@
+package store
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/diagnosis/interactive-todo/api/types"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type Reminder = types.TaskReminder
+
+type Channel = types.ReminderChannel
+
+const (
+	// MaxOffsetMinutes is the earliest a reminder can come, 30 days ahead.
+	MaxOffsetMinutes = 30 * 24 * 60
+	// MaxPerTask keeps reminders from turning into a stream of messages.
+	MaxPerTask = 10
+	// DefaultOffsetMinutes is the reminder new tasks start with, a day
+	// before they are due.
+	DefaultOffsetMinutes = 24 * 60
+	// LateGrace is how long after its due time a task still gets the
+	// reminders whose time came, such as one at the due time itself found
+	// a little after it.
+	LateGrace = 5 * time.Minute
+)
+
+var (
+	ErrReminderNotFound = errors.New("reminder not found")
+	// ErrReminderExists is returned when the task already has a reminder
+	// with the same offset and channel.
+	ErrReminderExists = errors.New("reminder already exists")
+)
+
+// DueReminder is a reminder whose time has come, with what sending it
+// needs of its task.
+type DueReminder struct {
+	Reminder
+	TeamID     uuid.UUID
+	Title      string
+	AssigneeID uuid.UUID
+	DueAt      time.Time
+}
+
+type ReminderStore interface {
+	// List returns the task's reminders, earliest first.
+	List(ctx context.Context, taskID uuid.UUID) ([]Reminder, error)
+	Create(ctx context.Context, taskID uuid.UUID, offsetMinutes int, channel Channel, createdBy *uuid.UUID, now time.Time) (*Reminder, error)
+	Delete(ctx context.Context, taskID, reminderID uuid.UUID) error
+	// ListDue returns unsent reminders whose time has come, of open and
+	// in-progress tasks. Once a task is due for longer than LateGrace its
+	// unsent reminders are left unsent.
+	ListDue(ctx context.Context, now time.Time, limit int) ([]DueReminder, error)
+	// MarkSent reports false when the reminder was sent or deleted
+	// meanwhile.
+	MarkSent(ctx context.Context, reminderID uuid.UUID, now time.Time) (bool, error)
+}
+
+type PGReminderStore struct {
+	pool *pgxpool.Pool
+}
+
+func NewPGReminderStore(pool *pgxpool.Pool) *PGReminderStore {
+	return &PGReminderStore{pool: pool}
+}
+
+var _ ReminderStore = (*PGReminderStore)(nil)
+
+// AddDefaults gives new tasks the reminder they start with,
+// DefaultOffsetMinutes before they are due by email. It runs in the
+// transaction that inserts the tasks, so no path creates a task without
+// one. Tasks that are not open or in progress are skipped.
+func AddDefaults(ctx context.Context, tx pgx.Tx, taskIDs []uuid.UUID, now time.Time) error {
+	const q = `
+		INSERT INTO task_reminders (task_id, team_id, offset_minutes, channel, created_at)
+		SELECT id, team_id, $2, $3, $4
+		FROM tasks
+		WHERE id = ANY($1)
+		  AND status IN ('open', 'in_progress')
+		ON CONFLICT DO NOTHING
+	`
+	if len(taskIDs) == 0 {
+		return nil
+	}
+	if _, err := tx.Exec(ctx, q, taskIDs, DefaultOffsetMinutes, types.ReminderEmail, now.UTC()); err != nil {
+		return fmt.Errorf("add default reminders: %w", err)
+	}
+	return nil
+}
+
+// NOTE: order must match scanReminder
+const reminderColumns = `
+    r.id,
+    r.task_id,
+    r.offset_minutes,
+    r.channel,
+    t.due_at - make_interval(mins => r.offset_minutes),
+    r.sent_at,
+    r.created_by,
+    r.created_at
+`
+
+func scanReminder(row pgx.Row, extra ...any) (*Reminder, error) {
+	var r Reminder
+	dest := append([]any{
+		&r.ID,
+		&r.TaskID,
+		&r.OffsetMinutes,
+		&r.Channel,
+		&r.RemindAt,
+		&r.SentAt,
+		&r.CreatedBy,
+		&r.CreatedAt,
+	}, extra...)
+	if err := row.Scan(dest...); err != nil {
+		return nil, err
+	}
+	return &r, nil
+}
+
+func (s *PGReminderStore) List(ctx context.Context, taskID uuid.UUID) ([]Reminder, error) {
+	q := `
+		SELECT ` + reminderColumns + `
+		FROM task_reminders r
+		JOIN tasks t ON t.id = r.task_id
+		WHERE r.task_id = $1
+		ORDER BY r.offset_minutes DESC, r.channel
+	`
+	rows, err := s.pool.Query(ctx, q, taskID)
+	if err != nil {
+		return nil, fmt.Errorf("list reminders task_id=%s: %w", taskID, err)
+	}
+	defer rows.Close()
+
+	reminders := []Reminder{}
+	for rows.Next() {
+		r, err := scanReminder(rows)
+		if err != nil {
+			return nil, fmt.Errorf("scan reminder: %w", err)
+		}
+		reminders = append(reminders, *r)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("list reminders task_id=%s: %w", taskID, err)
+	}
+	return reminders, nil
+}
+
+func (s *PGReminderStore) Create(
+	ctx context.Context,
+	taskID uuid.UUID,
+	offsetMinutes int,
+	channel Channel,
+	createdBy *uuid.UUID,
+	now time.Time,
+) (*Reminder, error) {
+	q := `
+		WITH r AS (
+			INSERT INTO task_reminders (task_id, offset_minutes, channel, created_by, created_at)
+			VALUES ($1, $2, $3, $4, $5)
+			RETURNING *
+		)
+		SELECT ` + reminderColumns + `
+		FROM r
+		JOIN tasks t ON t.id = r.task_id
+	`
+	r, err := scanReminder(s.pool.QueryRow(ctx, q, taskID, offsetMinutes, channel, createdBy, now.UTC()))
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == "23505" {
+			return nil, ErrReminderExists
+		}
+		return nil, fmt.Errorf("create reminder task_id=%s: %w", taskID, err)
+	}
+	return r, nil
+}
+
+func (s *PGReminderStore) Delete(ctx context.Context, taskID, reminderID uuid.UUID) error {
+	ct, err := s.pool.Exec(ctx, `DELETE FROM task_reminders WHERE id = $1 AND task_id = $2`, reminderID, taskID)
+	if err != nil {
+		return fmt.Errorf("delete reminder id=%s: %w", reminderID, err)
+	}
+	if ct.RowsAffected() == 0 {
+		return ErrReminderNotFound
+	}
+	return nil
+}
+
+func (s *PGReminderStore) ListDue(ctx context.Context, now time.Time, limit int) ([]DueReminder, error) {
+	q := `
+		SELECT ` + reminderColumns + `, t.team_id, t.title, t.assignee_id, t.due_at
+		FROM task_reminders r
+		JOIN tasks t ON t.id = r.task_id
+		WHERE r.sent_at IS NULL
+		  AND t.due_at - make_interval(mins => r.offset_minutes) <= $1
+		  AND t.due_at > $3
+		  AND t.status IN ('open', 'in_progress')
+		  AND t.deleted_at IS NULL
+		  AND t.archived_at IS NULL
+		ORDER BY t.due_at - make_interval(mins => r.offset_minutes)
+		LIMIT $2
+	`
+	rows, err := s.pool.Query(ctx, q, now.UTC(), limit, now.Add(-LateGrace).UTC())
+	if err != nil {
+		return nil, fmt.Errorf("list due reminders: %w", err)
+	}
+	defer rows.Close()
+
+	var due []DueReminder
+	for rows.Next() {
+		var d DueReminder
+		r, err := scanReminder(rows, &d.TeamID, &d.Title, &d.AssigneeID, &d.DueAt)
+		if err != nil {
+			return nil, fmt.Errorf("scan due reminder: %w", err)
+		}
+		d.Reminder = *r
+		due = append(due, d)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("list due reminders: %w", err)
+	}
+	return due, nil
+}
+
+func (s *PGReminderStore) MarkSent(ctx context.Context, reminderID uuid.UUID, now time.Time) (bool, error) {
+	const q = `UPDATE task_reminders SET sent_at = $2 WHERE id = $1 AND sent_at IS NULL`
+	ct, err := s.pool.Exec(ctx, q, reminderID, now.UTC())
+	if err != nil {
+		return false, fmt.Errorf("mark reminder sent id=%s: %w", reminderID, err)
+	}
+	return ct.RowsAffected() == 1, nil
+}
@@ -0,0 +1,245 @@
+package store
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// ReportInterval is the time between two status reports.
+const ReportInterval = 7 * 24 * time.Hour
+
+var ErrNotSubscribed = errors.New("not subscribed to status reports")
+
+// Subscription is a reporter's opt-in to the weekly status report. The
+// first report covers the week after CreatedAt.
+type Subscription struct {
+	UserID           uuid.UUID
+	UnsubscribeToken string
+	LastSentAt       *time.Time
+	NextReportAt     time.Time
+	CreatedAt        time.Time
+}
+
+// StatusChange is one change of a task's status.
+type StatusChange struct {
+	From string    `json:"from"`
+	To   string    `json:"to"`
+	At   time.Time `json:"at"`
+}
+
+// TaskActivity is what happened to one task during a report's period.
+// Comments counts those of other people.
+type TaskActivity struct {
+	TaskID        uuid.UUID
+	TeamID        uuid.UUID
+	TeamName      string
+	Title         string
+	Status        string
+	StatusChanges []StatusChange
+	Comments      int
+}
+
+type ReportStore interface {
+	Get(ctx context.Context, userID uuid.UUID) (*Subscription, error)
+	// Subscribe opts the user in with the given unsubscribe token. An
+	// existing subscription is returned unchanged.
+	Subscribe(ctx context.Context, userID uuid.UUID, token string, now time.Time) (*Subscription, error)
+	Unsubscribe(ctx context.Context, userID uuid.UUID) error
+	// UnsubscribeByToken removes the subscription the token belongs to and
+	// returns its user.
+	UnsubscribeByToken(ctx context.Context, token string) (uuid.UUID, error)
+	// ListDue returns up to limit subscriptions whose next report is due.
+	ListDue(ctx context.Context, now time.Time, limit int) ([]Subscription, error)
+	// Activity returns the tasks reported by the user that changed status
+	// or got comments in (since, until], in teams the user is still a
+	// member of.
+	Activity(ctx context.Context, userID uuid.UUID, since, until time.Time) ([]TaskActivity, error)
+	// MarkSent records a report and schedules the next one.
+	MarkSent(ctx context.Context, userID uuid.UUID, sentAt time.Time) error
+}
+
+type PGReportStore struct {
+	pool *pgxpool.Pool
+}
+
+func NewPGReportStore(pool *pgxpool.Pool) *PGReportStore {
+	return &PGReportStore{pool: pool}
+}
+
+var _ ReportStore = (*PGReportStore)(nil)
+
+// NOTE: order must match scanSubscription
+const subscriptionColumns = `
+    user_id,
+    unsubscribe_token,
+    last_sent_at,
+    next_report_at,
+    created_at
+`
+
+func scanSubscription(row pgx.Row) (*Subscription, error) {
+	var s Subscription
+	err := row.Scan(
+		&s.UserID,
+		&s.UnsubscribeToken,
+		&s.LastSentAt,
+		&s.NextReportAt,
+		&s.CreatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &s, nil
+}
+
+func (s *PGReportStore) Get(ctx context.Context, userID uuid.UUID) (*Subscription, error) {
+	q := `SELECT ` + subscriptionColumns + ` FROM status_report_subscriptions WHERE user_id = $1`
+	sub, err := scanSubscription(s.pool.QueryRow(ctx, q, userID))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrNotSubscribed
+		}
+		return nil, fmt.Errorf("get status report subscription user_id=%s: %w", userID, err)
+	}
+	return sub, nil
+}
+
+func (s *PGReportStore) Subscribe(ctx context.Context, userID uuid.UUID, token string, now time.Time) (*Subscription, error) {
+	const q = `
+		INSERT INTO status_report_subscriptions (user_id, unsubscribe_token, next_report_at, created_at)
+		VALUES ($1, $2, $3, $4)
+		ON CONFLICT (user_id) DO NOTHING
+	`
+	now = now.UTC()
+	if _, err := s.pool.Exec(ctx, q, userID, token, now.Add(ReportInterval), now); err != nil {
+		return nil, fmt.Errorf("subscribe to status reports user_id=%s: %w", userID, err)
+	}
+	return s.Get(ctx, userID)
+}
+
+func (s *PGReportStore) Unsubscribe(ctx context.Context, userID uuid.UUID) error {
+	ct, err := s.pool.Exec(ctx, `DELETE FROM status_report_subscriptions WHERE user_id = $1`, userID)
+	if err != nil {
+		return fmt.Errorf("unsubscribe from status reports user_id=%s: %w", userID, err)
+	}
+	if ct.RowsAffected() == 0 {
+		return ErrNotSubscribed
+	}
+	return nil
+}
+
+func (s *PGReportStore) UnsubscribeByToken(ctx context.Context, token string) (uuid.UUID, error) {
+	const q = `
+		DELETE FROM status_report_subscriptions
+		WHERE unsubscribe_token = $1
+		RETURNING user_id
+	`
+	var userID uuid.UUID
+	if err := s.pool.QueryRow(ctx, q, token).Scan(&userID); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return uuid.Nil, ErrNotSubscribed
+		}
+		return uuid.Nil, fmt.Errorf("unsubscribe from status reports by token: %w", err)
+	}
+	return userID, nil
+}
+
+func (s *PGReportStore) ListDue(ctx context.Context, now time.Time, limit int) ([]Subscription, error) {
+	q := `
+		SELECT ` + subscriptionColumns + `
+		FROM status_report_subscriptions
+		WHERE next_report_at <= $1
+		ORDER BY next_report_at
+		LIMIT $2
+	`
+	rows, err := s.pool.Query(ctx, q, now.UTC(), limit)
+	if err != nil {
+		return nil, fmt.Errorf("list due status reports: %w", err)
+	}
+	defer rows.Close()
+
+	var subs []Subscription
+	for rows.Next() {
+		sub, err := scanSubscription(rows)
+		if err != nil {
+			return nil, fmt.Errorf("scan status report subscription: %w", err)
+		}
+		subs = append(subs, *sub)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("list due status reports: %w", err)
+	}
+	return subs, nil
+}
+
+func (s *PGReportStore) Activity(ctx context.Context, userID uuid.UUID, since, until time.Time) ([]TaskActivity, error) {
+	// status changes are read from the history, whatever changed them: a
+	// status update, a workflow transition or an approval
+	const q = `
+		SELECT task_id, team_id, team_name, title, status, changes, comments
+		FROM (
+			SELECT t.id AS task_id, t.team_id, tm.name AS team_name, t.title, t.status::text AS status,
+			       COALESCE((
+			           SELECT jsonb_agg(jsonb_build_object(
+			                      'from', e.changes->'status'->>'from',
+			                      'to',   e.changes->'status'->>'to',
+			                      'at',   e.created_at
+			                  ) ORDER BY e.created_at)
+			           FROM task_events e
+			           WHERE e.task_id = t.id AND e.team_id = t.team_id
+			             AND e.kind <> 'created' AND e.changes ? 'status'
+			             AND e.created_at > $2 AND e.created_at <= $3
+			       ), '[]'::jsonb) AS changes,
+			       (SELECT count(*)
+			        FROM task_comments c
+			        WHERE c.task_id = t.id AND c.team_id = t.team_id
+			          AND c.author_id IS DISTINCT FROM $1
+			          AND c.created_at > $2 AND c.created_at <= $3) AS comments
+			FROM tasks t
+			JOIN teams tm ON tm.id = t.team_id
+			JOIN team_members m ON m.team_id = t.team_id AND m.user_id = $1
+			WHERE t.reporter_id = $1
+			  AND t.deleted_at IS NULL
+		) a
+		WHERE jsonb_array_length(changes) > 0 OR comments > 0
+		ORDER BY lower(team_name), team_id, lower(title), task_id
+	`
+	rows, err := s.pool.Query(ctx, q, userID, since.UTC(), until.UTC())
+	if err != nil {
+		return nil, fmt.Errorf("status report activity user_id=%s: %w", userID, err)
+	}
+	defer rows.Close()
+
+	var out []TaskActivity
+	for rows.Next() {
+		var a TaskActivity
+		if err := rows.Scan(&a.TaskID, &a.TeamID, &a.TeamName, &a.Title, &a.Status, &a.StatusChanges, &a.Comments); err != nil {
+			return nil, fmt.Errorf("scan status report activity: %w", err)
+		}
+		out = append(out, a)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("status report activity user_id=%s: %w", userID, err)
+	}
+	return out, nil
+}
+
+func (s *PGReportStore) MarkSent(ctx context.Context, userID uuid.UUID, sentAt time.Time) error {
+	const q = `
+		UPDATE status_report_subscriptions
+		SET last_sent_at   = $2,
+		    next_report_at = $3
+		WHERE user_id = $1
+	`
+	sentAt = sentAt.UTC()
+	if _, err := s.pool.Exec(ctx, q, userID, sentAt, sentAt.Add(ReportInterval)); err != nil {
+		return fmt.Errorf("mark status report sent user_id=%s: %w", userID, err)
+	}
+	return nil
+}
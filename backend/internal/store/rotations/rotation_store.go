@@ -0,0 +1,373 @@
+package store
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/diagnosis/interactive-todo/api/types"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type Rotation = types.Rotation
+
+type Cadence = types.RotationCadence
+
+const (
+	// MaxNameLength bounds rotation names.
+	MaxNameLength = 100
+	// MaxMembers and MaxLabels keep a rotation small enough to read.
+	MaxMembers = 50
+	MaxLabels  = 20
+)
+
+var (
+	ErrRotationNotFound = errors.New("rotation not found")
+	// ErrRotationExists is returned when another rotation in the team
+	// already has the name, ignoring case.
+	ErrRotationExists = errors.New("rotation name already in use")
+	// ErrNotMember is returned when a member is not in the team.
+	ErrNotMember = errors.New("rotation member is not in the team")
+)
+
+// Period is how long each member stays on call.
+func Period(c Cadence) time.Duration {
+	if c == types.RotationWeekly {
+		return 7 * 24 * time.Hour
+	}
+	return 24 * time.Hour
+}
+
+// OnCall is the member a rotation puts on tasks with Label.
+type OnCall struct {
+	RotationID uuid.UUID
+	Rotation   string
+	Label      string
+	UserID     uuid.UUID
+}
+
+type RotationStore interface {
+	// List returns the team's rotations, oldest first.
+	List(ctx context.Context, teamID uuid.UUID) ([]Rotation, error)
+	Get(ctx context.Context, teamID, rotationID uuid.UUID) (*Rotation, error)
+	// Create puts the first member on call until in.HandoffAt, which must
+	// be set.
+	Create(ctx context.Context, teamID uuid.UUID, in types.CreateRotationRequest, createdBy uuid.UUID, now time.Time) (*Rotation, error)
+	// Update changes the fields that are set. The member on call stays on
+	// call if they are still in the new member list; otherwise the first
+	// member takes over.
+	Update(ctx context.Context, teamID, rotationID uuid.UUID, in types.UpdateRotationRequest, now time.Time) (*Rotation, error)
+	Delete(ctx context.Context, teamID, rotationID uuid.UUID) error
+	// OnCall returns who is on call for the oldest rotation having one of
+	// labels, compared ignoring case, or nil.
+	OnCall(ctx context.Context, teamID uuid.UUID, labels []string) (*OnCall, error)
+	// ListDue returns rotations with members whose hand-off time has come,
+	// or whose member on call has left.
+	ListDue(ctx context.Context, now time.Time, limit int) ([]Rotation, error)
+	// HandOff puts onCallID on call until handoffAt. It reports false,
+	// handing off nothing, when r was edited since it was read.
+	HandOff(ctx context.Context, r *Rotation, onCallID uuid.UUID, handoffAt, now time.Time) (bool, error)
+}
+
+type PGRotationStore struct {
+	pool *pgxpool.Pool
+}
+
+func NewPGRotationStore(pool *pgxpool.Pool) *PGRotationStore {
+	return &PGRotationStore{pool: pool}
+}
+
+var _ RotationStore = (*PGRotationStore)(nil)
+
+// NOTE: order must match scanRotation. A member on call who has left the
+// team reads as nobody.
+const rotationColumns = `
+    r.id,
+    r.team_id,
+    r.name,
+    r.cadence,
+    COALESCE((SELECT array_agg(m.user_id ORDER BY m.position)
+              FROM rotation_members m WHERE m.rotation_id = r.id), '{}'),
+    r.labels,
+    (SELECT m.user_id FROM rotation_members m
+     WHERE m.rotation_id = r.id AND m.user_id = r.on_call_id),
+    r.handoff_at,
+    r.created_by,
+    r.created_at,
+    r.updated_at
+`
+
+func scanRotation(row pgx.Row) (*Rotation, error) {
+	var r Rotation
+	err := row.Scan(
+		&r.ID,
+		&r.TeamID,
+		&r.Name,
+		&r.Cadence,
+		&r.MemberIDs,
+		&r.Labels,
+		&r.OnCallID,
+		&r.HandoffAt,
+		&r.CreatedBy,
+		&r.CreatedAt,
+		&r.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &r, nil
+}
+
+func scanRotations(rows pgx.Rows) ([]Rotation, error) {
+	defer rows.Close()
+
+	rotations := []Rotation{}
+	for rows.Next() {
+		r, err := scanRotation(rows)
+		if err != nil {
+			return nil, fmt.Errorf("scan rotation: %w", err)
+		}
+		rotations = append(rotations, *r)
+	}
+	return rotations, rows.Err()
+}
+
+func isUniqueViolation(err error) bool {
+	var pgErr *pgconn.PgError
+	return errors.As(err, &pgErr) && pgErr.Code == "23505"
+}
+
+func isNotMember(err error) bool {
+	var pgErr *pgconn.PgError
+	return errors.As(err, &pgErr) && pgErr.Code == "23503" && pgErr.ConstraintName == "fk_rotation_members_member"
+}
+
+func (s *PGRotationStore) List(ctx context.Context, teamID uuid.UUID) ([]Rotation, error) {
+	q := `
+		SELECT ` + rotationColumns + `
+		FROM team_rotations r
+		WHERE r.team_id = $1
+		ORDER BY r.created_at, r.id
+	`
+	rows, err := s.pool.Query(ctx, q, teamID)
+	if err != nil {
+		return nil, fmt.Errorf("list rotations team_id=%s: %w", teamID, err)
+	}
+	rotations, err := scanRotations(rows)
+	if err != nil {
+		return nil, fmt.Errorf("list rotations team_id=%s: %w", teamID, err)
+	}
+	return rotations, nil
+}
+
+func (s *PGRotationStore) Get(ctx context.Context, teamID, rotationID uuid.UUID) (*Rotation, error) {
+	q := `
+		SELECT ` + rotationColumns + `
+		FROM team_rotations r
+		WHERE r.id = $1 AND r.team_id = $2
+	`
+	r, err := scanRotation(s.pool.QueryRow(ctx, q, rotationID, teamID))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrRotationNotFound
+		}
+		return nil, fmt.Errorf("get rotation id=%s: %w", rotationID, err)
+	}
+	return r, nil
+}
+
+func (s *PGRotationStore) Create(
+	ctx context.Context,
+	teamID uuid.UUID,
+	in types.CreateRotationRequest,
+	createdBy uuid.UUID,
+	now time.Time,
+) (*Rotation, error) {
+	tx, err := s.pool.BeginTx(ctx, pgx.TxOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("create rotation team_id=%s: begin: %w", teamID, err)
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	const q = `
+		INSERT INTO team_rotations (team_id, name, cadence, labels, on_call_id, handoff_at, created_by, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $8)
+		RETURNING id
+	`
+	labels := in.Labels
+	if labels == nil {
+		labels = []string{}
+	}
+	var id uuid.UUID
+	err = tx.QueryRow(ctx, q, teamID, strings.TrimSpace(in.Name), in.Cadence, labels, in.MemberIDs[0], in.HandoffAt.UTC(), createdBy, now.UTC()).Scan(&id)
+	if err != nil {
+		if isUniqueViolation(err) {
+			return nil, ErrRotationExists
+		}
+		return nil, fmt.Errorf("create rotation team_id=%s: %w", teamID, err)
+	}
+	if err := replaceMembers(ctx, tx, teamID, id, in.MemberIDs); err != nil {
+		return nil, err
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("create rotation team_id=%s: commit: %w", teamID, err)
+	}
+	return s.Get(ctx, teamID, id)
+}
+
+func (s *PGRotationStore) Update(
+	ctx context.Context,
+	teamID, rotationID uuid.UUID,
+	in types.UpdateRotationRequest,
+	now time.Time,
+) (*Rotation, error) {
+	tx, err := s.pool.BeginTx(ctx, pgx.TxOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("update rotation id=%s: begin: %w", rotationID, err)
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	const q = `
+		UPDATE team_rotations
+		SET name       = COALESCE($3, name),
+		    cadence    = COALESCE($4, cadence),
+		    labels     = COALESCE($5, labels),
+		    handoff_at = COALESCE($6, handoff_at),
+		    on_call_id = CASE WHEN $7::uuid[] IS NULL OR on_call_id = ANY($7) THEN on_call_id ELSE ($7::uuid[])[1] END,
+		    updated_at = $8
+		WHERE id = $1 AND team_id = $2
+	`
+	if in.Name != nil {
+		n := strings.TrimSpace(*in.Name)
+		in.Name = &n
+	}
+	var handoffAt *time.Time
+	if in.HandoffAt != nil {
+		t := in.HandoffAt.UTC()
+		handoffAt = &t
+	}
+	ct, err := tx.Exec(ctx, q, rotationID, teamID, in.Name, in.Cadence, in.Labels, handoffAt, in.MemberIDs, now.UTC())
+	if err != nil {
+		if isUniqueViolation(err) {
+			return nil, ErrRotationExists
+		}
+		return nil, fmt.Errorf("update rotation id=%s: %w", rotationID, err)
+	}
+	if ct.RowsAffected() == 0 {
+		return nil, ErrRotationNotFound
+	}
+	if in.MemberIDs != nil {
+		if err := replaceMembers(ctx, tx, teamID, rotationID, in.MemberIDs); err != nil {
+			return nil, err
+		}
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("update rotation id=%s: commit: %w", rotationID, err)
+	}
+	return s.Get(ctx, teamID, rotationID)
+}
+
+func replaceMembers(ctx context.Context, tx pgx.Tx, teamID, rotationID uuid.UUID, memberIDs []uuid.UUID) error {
+	if _, err := tx.Exec(ctx, `DELETE FROM rotation_members WHERE rotation_id = $1`, rotationID); err != nil {
+		return fmt.Errorf("replace rotation members id=%s: delete: %w", rotationID, err)
+	}
+	const q = `
+		INSERT INTO rotation_members (rotation_id, team_id, user_id, position)
+		SELECT $1, $2, m.user_id, m.position
+		FROM unnest($3::uuid[]) WITH ORDINALITY AS m(user_id, position)
+	`
+	if _, err := tx.Exec(ctx, q, rotationID, teamID, memberIDs); err != nil {
+		if isNotMember(err) {
+			return ErrNotMember
+		}
+		return fmt.Errorf("replace rotation members id=%s: insert: %w", rotationID, err)
+	}
+	return nil
+}
+
+func (s *PGRotationStore) Delete(ctx context.Context, teamID, rotationID uuid.UUID) error {
+	ct, err := s.pool.Exec(ctx, `DELETE FROM team_rotations WHERE id = $1 AND team_id = $2`, rotationID, teamID)
+	if err != nil {
+		return fmt.Errorf("delete rotation id=%s: %w", rotationID, err)
+	}
+	if ct.RowsAffected() == 0 {
+		return ErrRotationNotFound
+	}
+	return nil
+}
+
+func (s *PGRotationStore) OnCall(ctx context.Context, teamID uuid.UUID, labels []string) (*OnCall, error) {
+	if len(labels) == 0 {
+		return nil, nil
+	}
+	lower := make([]string, len(labels))
+	for i, l := range labels {
+		lower[i] = strings.ToLower(l)
+	}
+	const q = `
+		SELECT r.id, r.name, l.label, m.user_id
+		FROM team_rotations r
+		JOIN rotation_members m ON m.rotation_id = r.id AND m.user_id = r.on_call_id
+		CROSS JOIN LATERAL (
+			SELECT label FROM unnest(r.labels) AS label
+			WHERE lower(label) = ANY($2)
+			LIMIT 1
+		) l
+		WHERE r.team_id = $1
+		ORDER BY r.created_at, r.id
+		LIMIT 1
+	`
+	var oc OnCall
+	err := s.pool.QueryRow(ctx, q, teamID, lower).Scan(&oc.RotationID, &oc.Rotation, &oc.Label, &oc.UserID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("on call team_id=%s: %w", teamID, err)
+	}
+	return &oc, nil
+}
+
+func (s *PGRotationStore) ListDue(ctx context.Context, now time.Time, limit int) ([]Rotation, error) {
+	q := `
+		SELECT ` + rotationColumns + `
+		FROM team_rotations r
+		WHERE EXISTS (SELECT 1 FROM rotation_members m WHERE m.rotation_id = r.id)
+		  AND (r.handoff_at <= $1
+		       OR NOT EXISTS (SELECT 1 FROM rotation_members m
+		                      WHERE m.rotation_id = r.id AND m.user_id = r.on_call_id))
+		ORDER BY r.handoff_at
+		LIMIT $2
+	`
+	rows, err := s.pool.Query(ctx, q, now.UTC(), limit)
+	if err != nil {
+		return nil, fmt.Errorf("list due rotations: %w", err)
+	}
+	rotations, err := scanRotations(rows)
+	if err != nil {
+		return nil, fmt.Errorf("list due rotations: %w", err)
+	}
+	return rotations, nil
+}
+
+// HandOff compares updated_at so a concurrent edit or hand-off is not
+// overwritten with what was read before it.
+func (s *PGRotationStore) HandOff(ctx context.Context, r *Rotation, onCallID uuid.UUID, handoffAt, now time.Time) (bool, error) {
+	const q = `
+		UPDATE team_rotations
+		SET on_call_id = $2,
+		    handoff_at = $3,
+		    updated_at = $4
+		WHERE id = $1 AND updated_at = $5
+	`
+	ct, err := s.pool.Exec(ctx, q, r.ID, onCallID, handoffAt.UTC(), now.UTC(), r.UpdatedAt)
+	if err != nil {
+		return false, fmt.Errorf("hand off rotation id=%s: %w", r.ID, err)
+	}
+	return ct.RowsAffected() == 1, nil
+}
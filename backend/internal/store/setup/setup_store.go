@@ -0,0 +1,134 @@
+package store
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/diagnosis/interactive-todo/api/types"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// ErrAlreadySetUp is returned by Bootstrap once the install has a user,
+// whether it came from an earlier setup or from registration.
+var ErrAlreadySetUp = errors.New("setup already completed")
+
+// Bootstrap is what the first-run setup creates: the install's name, its
+// first admin and a team they own.
+type Bootstrap struct {
+	OrgName      string
+	Email        string
+	PasswordHash string
+	TeamName     string
+}
+
+type SetupStore interface {
+	Status(ctx context.Context) (*types.SetupStatus, error)
+	// Bootstrap writes everything in one transaction, or returns
+	// ErrAlreadySetUp and writes nothing.
+	Bootstrap(ctx context.Context, in Bootstrap, now time.Time) (*types.SetupResponse, error)
+}
+
+type PGSetupStore struct {
+	pool *pgxpool.Pool
+}
+
+func NewPGSetupStore(pool *pgxpool.Pool) *PGSetupStore {
+	return &PGSetupStore{pool: pool}
+}
+
+var _ SetupStore = (*PGSetupStore)(nil)
+
+func (s *PGSetupStore) Status(ctx context.Context) (*types.SetupStatus, error) {
+	const q = `
+		SELECT NOT EXISTS (SELECT 1 FROM users),
+		       (SELECT org_name FROM instance_settings)
+	`
+
+	var st types.SetupStatus
+	if err := s.pool.QueryRow(ctx, q).Scan(&st.Required, &st.OrgName); err != nil {
+		return nil, fmt.Errorf("setup status: %w", err)
+	}
+	return &st, nil
+}
+
+func (s *PGSetupStore) Bootstrap(ctx context.Context, in Bootstrap, now time.Time) (*types.SetupResponse, error) {
+	// a concurrent setup waits on the primary key here and then conflicts
+	const claim = `
+		INSERT INTO instance_settings (id, org_name, setup_at, updated_at)
+		VALUES (TRUE, $1, $2, $2)
+		ON CONFLICT (id) DO NOTHING
+	`
+	const insertUser = `
+		INSERT INTO users (email, password_hash, user_type, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $4)
+		RETURNING id
+	`
+	const insertTeam = `
+		INSERT INTO teams (name, owner_id, created_at, updated_at)
+		VALUES ($1, $2, $3, $3)
+		RETURNING id
+	`
+	const insertOwner = `
+		INSERT INTO team_members (team_id, user_id, role, created_at)
+		VALUES ($1, $2, $3, $4)
+	`
+	const setupBy = `UPDATE instance_settings SET setup_by = $1`
+
+	now = now.UTC()
+	tx, err := s.pool.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("setup: begin: %w", err)
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	ct, err := tx.Exec(ctx, claim, in.OrgName, now)
+	if err != nil {
+		return nil, fmt.Errorf("setup: claim: %w", err)
+	}
+	if ct.RowsAffected() == 0 {
+		return nil, ErrAlreadySetUp
+	}
+	// installs that predate setup have users but no settings row
+	var hasUsers bool
+	if err := tx.QueryRow(ctx, `SELECT EXISTS (SELECT 1 FROM users)`).Scan(&hasUsers); err != nil {
+		return nil, fmt.Errorf("setup: check users: %w", err)
+	}
+	if hasUsers {
+		return nil, ErrAlreadySetUp
+	}
+
+	out := &types.SetupResponse{
+		OrgName: in.OrgName,
+		Admin: types.User{
+			Email:     in.Email,
+			UserType:  types.UserTypeAdmin,
+			CreatedAt: now,
+			UpdatedAt: now,
+		},
+		Team: types.Team{
+			Name:      in.TeamName,
+			CreatedAt: now,
+			UpdatedAt: now,
+		},
+	}
+	if err := tx.QueryRow(ctx, insertUser, in.Email, in.PasswordHash, types.UserTypeAdmin, now).Scan(&out.Admin.ID); err != nil {
+		return nil, fmt.Errorf("setup: insert admin: %w", err)
+	}
+	out.Team.OwnerID = out.Admin.ID
+	if err := tx.QueryRow(ctx, insertTeam, in.TeamName, out.Admin.ID, now).Scan(&out.Team.ID); err != nil {
+		return nil, fmt.Errorf("setup: insert team name=%q: %w", in.TeamName, err)
+	}
+	if _, err := tx.Exec(ctx, insertOwner, out.Team.ID, out.Admin.ID, types.TeamRoleOwner, now); err != nil {
+		return nil, fmt.Errorf("setup: insert owner team_id=%s: %w", out.Team.ID, err)
+	}
+	if _, err := tx.Exec(ctx, setupBy, out.Admin.ID); err != nil {
+		return nil, fmt.Errorf("setup: record admin: %w", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("setup: commit: %w", err)
+	}
+	return out, nil
+}
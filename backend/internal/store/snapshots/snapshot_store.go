@@ -0,0 +1,382 @@
+package store
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/diagnosis/interactive-todo/api/types"
+	"github.com/diagnosis/interactive-todo/internal/secure/fieldcrypt"
+	reminderstore "github.com/diagnosis/interactive-todo/internal/store/reminders"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type Snapshot = types.Snapshot
+
+type Restored = types.SnapshotRestored
+
+var (
+	ErrSnapshotNotFound      = errors.New("snapshot not found")
+	ErrTeamNameTaken         = errors.New("team name already in use")
+	ErrEncryptionUnavailable = errors.New("field encryption key not configured")
+)
+
+type SnapshotStore interface {
+	// Create copies the team's tasks, members and labels as they are now.
+	Create(ctx context.Context, teamID uuid.UUID, name string, createdBy uuid.UUID, now time.Time) (*Snapshot, error)
+	ListForTeam(ctx context.Context, teamID uuid.UUID) ([]Snapshot, error)
+	Delete(ctx context.Context, teamID, id uuid.UUID) error
+	// Restore creates a team named teamName owned by ownerID and fills it
+	// from the snapshot, in one transaction.
+	Restore(ctx context.Context, teamID, id uuid.UUID, teamName string, ownerID uuid.UUID, now time.Time) (*Restored, error)
+}
+
+// NOTE: order must match scanSnapshot
+const snapshotColumns = `
+    id,
+    team_id,
+    name,
+    task_count,
+    created_by,
+    created_at
+`
+
+type PGSnapshotStore struct {
+	pool *pgxpool.Pool
+	// cipher opens descriptions sealed while the team was confidential; nil
+	// when no key is configured.
+	cipher *fieldcrypt.Cipher
+}
+
+func NewPGSnapshotStore(pool *pgxpool.Pool, cipher *fieldcrypt.Cipher) *PGSnapshotStore {
+	return &PGSnapshotStore{pool: pool, cipher: cipher}
+}
+
+// snapshotData is the JSON stored in team_snapshots.data.
+type snapshotData struct {
+	Members []snapshotMember `json:"members"`
+	Labels  []string         `json:"labels"`
+	Tasks   []snapshotTask   `json:"tasks"`
+}
+
+type snapshotMember struct {
+	UserID uuid.UUID `json:"user_id"`
+	Role   string    `json:"role"`
+}
+
+type snapshotTask struct {
+	ID            uuid.UUID   `json:"id"`
+	Title         string      `json:"title"`
+	Description   *string     `json:"description"`
+	ReporterID    uuid.UUID   `json:"reporter_id"`
+	AssigneeID    uuid.UUID   `json:"assignee_id"`
+	StartAt       *time.Time  `json:"start_at"`
+	DueAt         time.Time   `json:"due_at"`
+	Status        string      `json:"status"`
+	Priority      string      `json:"priority"`
+	EstimateHours *float64    `json:"estimate_hours"`
+	IsPrivate     bool        `json:"is_private"`
+	CreatedAt     time.Time   `json:"created_at"`
+	Labels        []string    `json:"labels"`
+	Viewers       []uuid.UUID `json:"viewers"`
+}
+
+func (s *PGSnapshotStore) Create(ctx context.Context, teamID uuid.UUID, name string, createdBy uuid.UUID, now time.Time) (*Snapshot, error) {
+	// One statement, so the copy is consistent without locking the team.
+	const q = `
+		INSERT INTO team_snapshots (team_id, name, data, task_count, created_by, created_at)
+		SELECT $1, $2,
+		       jsonb_build_object(
+		           'members', COALESCE((
+		               SELECT jsonb_agg(jsonb_build_object('user_id', m.user_id, 'role', m.role))
+		               FROM team_members m
+		               WHERE m.team_id = $1
+		           ), '[]'::jsonb),
+		           'labels', COALESCE((
+		               SELECT jsonb_agg(l.name ORDER BY l.name)
+		               FROM labels l
+		               WHERE l.team_id = $1
+		           ), '[]'::jsonb),
+		           'tasks', COALESCE((
+		               SELECT jsonb_agg(jsonb_build_object(
+		                   'id', t.id,
+		                   'title', t.title,
+		                   'description', t.description,
+		                   'reporter_id', t.reporter_id,
+		                   'assignee_id', t.assignee_id,
+		                   'start_at', t.start_at,
+		                   'due_at', t.due_at,
+		                   'status', t.status,
+		                   'priority', t.priority,
+		                   'estimate_hours', t.estimate_hours,
+		                   'is_private', t.is_private,
+		                   'created_at', t.created_at,
+		                   'labels', COALESCE((
+		                       SELECT jsonb_agg(l.name)
+		                       FROM task_labels tl
+		                       JOIN labels l ON l.id = tl.label_id
+		                       WHERE tl.task_id = t.id
+		                   ), '[]'::jsonb),
+		                   'viewers', COALESCE((
+		                       SELECT jsonb_agg(v.user_id)
+		                       FROM task_viewers v
+		                       WHERE v.task_id = t.id
+		                   ), '[]'::jsonb)
+		               ) ORDER BY t.created_at, t.id)
+		               FROM tasks t
+		               WHERE t.team_id = $1
+		                 AND t.deleted_at IS NULL
+		           ), '[]'::jsonb)
+		       ),
+		       (SELECT count(*) FROM tasks WHERE team_id = $1 AND deleted_at IS NULL),
+		       $3, $4
+		RETURNING ` + snapshotColumns
+
+	snap, err := scanSnapshot(s.pool.QueryRow(ctx, q, teamID, name, createdBy, now.UTC()))
+	if err != nil {
+		return nil, fmt.Errorf("create snapshot team_id=%s: %w", teamID, err)
+	}
+	return snap, nil
+}
+
+func (s *PGSnapshotStore) ListForTeam(ctx context.Context, teamID uuid.UUID) ([]Snapshot, error) {
+	const q = `
+		SELECT ` + snapshotColumns + `
+		FROM team_snapshots
+		WHERE team_id = $1
+		ORDER BY created_at DESC
+	`
+	rows, err := s.pool.Query(ctx, q, teamID)
+	if err != nil {
+		return nil, fmt.Errorf("list snapshots team_id=%s: %w", teamID, err)
+	}
+	defer rows.Close()
+
+	out := []Snapshot{}
+	for rows.Next() {
+		snap, err := scanSnapshot(rows)
+		if err != nil {
+			return nil, fmt.Errorf("scan snapshot: %w", err)
+		}
+		out = append(out, *snap)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("list snapshots team_id=%s: %w", teamID, err)
+	}
+	return out, nil
+}
+
+func (s *PGSnapshotStore) Delete(ctx context.Context, teamID, id uuid.UUID) error {
+	ct, err := s.pool.Exec(ctx, `DELETE FROM team_snapshots WHERE team_id = $1 AND id = $2`, teamID, id)
+	if err != nil {
+		return fmt.Errorf("delete snapshot id=%s: %w", id, err)
+	}
+	if ct.RowsAffected() == 0 {
+		return ErrSnapshotNotFound
+	}
+	return nil
+}
+
+func (s *PGSnapshotStore) Restore(ctx context.Context, teamID, id uuid.UUID, teamName string, ownerID uuid.UUID, now time.Time) (*Restored, error) {
+	const (
+		insertTeam = `
+			INSERT INTO teams (name, owner_id, created_at, updated_at)
+			VALUES ($1, $2, $3, $3)
+			RETURNING id
+		`
+		insertOwner = `
+			INSERT INTO team_members (team_id, user_id, role, created_at)
+			VALUES ($1, $2, 'owner', $3)
+		`
+		// the snapshot's owner becomes an admin; deleted users are skipped
+		insertMembers = `
+			INSERT INTO team_members (team_id, user_id, role, created_at)
+			SELECT $1, m.user_id, (CASE WHEN m.role = 'owner' THEN 'admin' ELSE m.role END)::team_role, $4
+			FROM unnest($2::uuid[], $3::text[]) AS m(user_id, role)
+			JOIN users u ON u.id = m.user_id
+			ON CONFLICT DO NOTHING
+		`
+		insertLabels = `
+			INSERT INTO labels (team_id, name, created_at)
+			SELECT $1, n, $3 FROM unnest($2::text[]) AS n
+			ON CONFLICT DO NOTHING
+		`
+		insertTask = `
+			INSERT INTO tasks (id, team_id, title, description, reporter_id, assignee_id, start_at, due_at,
+			                   status, priority, estimate_hours, is_private, created_at, updated_at)
+			VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14)
+		`
+		insertTaskLabels = `
+			INSERT INTO task_labels (task_id, team_id, label_id, created_at)
+			SELECT $1, $2, l.id, $4
+			FROM labels l
+			WHERE l.team_id = $2 AND lower(l.name) = ANY($3::text[])
+		`
+		insertViewers = `
+			INSERT INTO task_viewers (task_id, team_id, user_id, created_at)
+			SELECT $1, $2, v, $4 FROM unnest($3::uuid[]) AS v
+		`
+	)
+	now = now.UTC()
+
+	tx, err := s.pool.BeginTx(ctx, pgx.TxOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("restore snapshot: begin tx: %w", err)
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	var raw []byte
+	err = tx.QueryRow(ctx, `SELECT data FROM team_snapshots WHERE team_id = $1 AND id = $2`, teamID, id).Scan(&raw)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrSnapshotNotFound
+		}
+		return nil, fmt.Errorf("restore snapshot id=%s: %w", id, err)
+	}
+	var data snapshotData
+	if err := json.Unmarshal(raw, &data); err != nil {
+		return nil, fmt.Errorf("restore snapshot id=%s: decode: %w", id, err)
+	}
+
+	out := &Restored{Name: teamName}
+	if err := tx.QueryRow(ctx, insertTeam, teamName, ownerID, now).Scan(&out.TeamID); err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == "23505" {
+			return nil, ErrTeamNameTaken
+		}
+		return nil, fmt.Errorf("restore snapshot: insert team: %w", err)
+	}
+	if _, err := tx.Exec(ctx, insertOwner, out.TeamID, ownerID, now); err != nil {
+		return nil, fmt.Errorf("restore snapshot: insert owner: %w", err)
+	}
+
+	userIDs := make([]uuid.UUID, len(data.Members))
+	roles := make([]string, len(data.Members))
+	for i, m := range data.Members {
+		userIDs[i], roles[i] = m.UserID, m.Role
+	}
+	if _, err := tx.Exec(ctx, insertMembers, out.TeamID, userIDs, roles, now); err != nil {
+		return nil, fmt.Errorf("restore snapshot: insert members: %w", err)
+	}
+	members, err := memberSet(ctx, tx, out.TeamID)
+	if err != nil {
+		return nil, err
+	}
+	out.Members = len(members)
+
+	ct, err := tx.Exec(ctx, insertLabels, out.TeamID, data.Labels, now)
+	if err != nil {
+		return nil, fmt.Errorf("restore snapshot: insert labels: %w", err)
+	}
+	out.Labels = int(ct.RowsAffected())
+
+	// People who left the team or were deleted since the snapshot hand
+	// their tasks to the new owner.
+	member := func(id uuid.UUID) uuid.UUID {
+		if members[id] {
+			return id
+		}
+		return ownerID
+	}
+
+	batch := &pgx.Batch{}
+	taskIDs := make([]uuid.UUID, 0, len(data.Tasks))
+	for _, t := range data.Tasks {
+		desc, err := s.openDescription(teamID, t.Description)
+		if err != nil {
+			return nil, fmt.Errorf("restore snapshot: task_id=%s: %w", t.ID, err)
+		}
+		newID := uuid.New()
+		taskIDs = append(taskIDs, newID)
+		batch.Queue(insertTask, newID, out.TeamID, t.Title, desc, member(t.ReporterID), member(t.AssigneeID),
+			t.StartAt, t.DueAt, t.Status, t.Priority, t.EstimateHours, t.IsPrivate, t.CreatedAt, now)
+
+		if len(t.Labels) > 0 {
+			names := make([]string, len(t.Labels))
+			for i, n := range t.Labels {
+				names[i] = strings.ToLower(n)
+			}
+			batch.Queue(insertTaskLabels, newID, out.TeamID, names, now)
+		}
+		var viewers []uuid.UUID
+		for _, v := range t.Viewers {
+			if members[v] {
+				viewers = append(viewers, v)
+			}
+		}
+		if len(viewers) > 0 {
+			batch.Queue(insertViewers, newID, out.TeamID, viewers, now)
+		}
+	}
+	if batch.Len() > 0 {
+		if err := tx.SendBatch(ctx, batch).Close(); err != nil {
+			return nil, fmt.Errorf("restore snapshot: insert tasks: %w", err)
+		}
+	}
+	if err := reminderstore.AddDefaults(ctx, tx, taskIDs, now); err != nil {
+		return nil, fmt.Errorf("restore snapshot: %w", err)
+	}
+	out.Tasks = len(data.Tasks)
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("restore snapshot: commit: %w", err)
+	}
+	return out, nil
+}
+
+// openDescription opens a description sealed under the source team, since
+// the copy belongs to another team.
+func (s *PGSnapshotStore) openDescription(sourceTeamID uuid.UUID, desc *string) (*string, error) {
+	if desc == nil || !fieldcrypt.IsEncrypted(*desc) {
+		return desc, nil
+	}
+	if s.cipher == nil {
+		return nil, ErrEncryptionUnavailable
+	}
+	plain, err := s.cipher.Decrypt(*desc, sourceTeamID[:])
+	if err != nil {
+		return nil, err
+	}
+	return &plain, nil
+}
+
+func memberSet(ctx context.Context, tx pgx.Tx, teamID uuid.UUID) (map[uuid.UUID]bool, error) {
+	rows, err := tx.Query(ctx, `SELECT user_id FROM team_members WHERE team_id = $1`, teamID)
+	if err != nil {
+		return nil, fmt.Errorf("restore snapshot: list members: %w", err)
+	}
+	defer rows.Close()
+
+	set := map[uuid.UUID]bool{}
+	for rows.Next() {
+		var id uuid.UUID
+		if err := rows.Scan(&id); err != nil {
+			return nil, fmt.Errorf("restore snapshot: scan member: %w", err)
+		}
+		set[id] = true
+	}
+	return set, rows.Err()
+}
+
+func scanSnapshot(row pgx.Row) (*Snapshot, error) {
+	var snap Snapshot
+	if err := row.Scan(
+		&snap.ID,
+		&snap.TeamID,
+		&snap.Name,
+		&snap.TaskCount,
+		&snap.CreatedBy,
+		&snap.CreatedAt,
+	); err != nil {
+		return nil, err
+	}
+	return &snap, nil
+}
+
+var _ SnapshotStore = (*PGSnapshotStore)(nil)
@@ -0,0 +1,311 @@
+package store
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/diagnosis/interactive-todo/api/types"
+	reminderstore "github.com/diagnosis/interactive-todo/internal/store/reminders"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+// An announcement is one task an org admin sends to every team at once,
+// e.g. "complete security training". Each team gets its own copy, assigned
+// to the team owner, that points back with announcement_id; the teams'
+// copies together are the announcement's progress.
+
+type Announcement = types.Announcement
+type AnnouncementTeam = types.AnnouncementTeam
+
+var ErrAnnouncementNotFound = errors.New("announcement not found")
+
+type NewAnnouncement struct {
+	Title       string
+	Description *string
+	DueAt       time.Time
+	Priority    TaskPriority
+}
+
+// NOTE: order must match scanAnnouncement
+const announcementColumns = `
+    a.id,
+    a.title,
+    a.description,
+    a.due_at,
+    a.priority,
+    a.created_by,
+    a.created_at,
+    a.team_count
+`
+
+// announcementCopies is every copy of announcement $1, archived ones too.
+const announcementCopies = `
+	SELECT id, team_id, assignee_id, status::text AS status, due_at, updated_at
+	FROM tasks WHERE announcement_id = $1 AND deleted_at IS NULL
+	UNION ALL
+	SELECT id, team_id, assignee_id, status::text, due_at, updated_at
+	FROM tasks_archive WHERE announcement_id = $1
+`
+
+func scanAnnouncement(row pgx.Row, extra ...any) (*Announcement, error) {
+	var a Announcement
+	dest := []any{
+		&a.ID,
+		&a.Title,
+		&a.Description,
+		&a.DueAt,
+		&a.Priority,
+		&a.CreatedBy,
+		&a.CreatedAt,
+		&a.Teams,
+	}
+	if err := row.Scan(append(dest, extra...)...); err != nil {
+		return nil, err
+	}
+	return &a, nil
+}
+
+// CreateAnnouncement gives every team a copy of the task, reported by
+// createdBy, in one transaction. A team with a custom workflow gets its
+// copy in the workflow's initial state. It returns the copies so their
+// creation can be published.
+func (s *PGTaskStore) CreateAnnouncement(ctx context.Context, in NewAnnouncement, createdBy uuid.UUID, now time.Time) (*Announcement, []Task, error) {
+	title := strings.TrimSpace(in.Title)
+	switch {
+	case title == "":
+		return nil, nil, fmt.Errorf("%w: title cannot be empty", ErrInvalidInput)
+	case len(title) > 500:
+		return nil, nil, fmt.Errorf("%w: title too long (max 500 chars)", ErrInvalidInput)
+	case in.DueAt.Before(now):
+		return nil, nil, fmt.Errorf("%w: due_at must be in the future", ErrInvalidInput)
+	}
+
+	const insertAnnouncement = `
+		INSERT INTO announcements AS a (title, description, due_at, priority, created_by, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		RETURNING ` + announcementColumns
+	const listTeams = `
+		SELECT t.id,
+		       t.owner_id,
+		       COALESCE(ts.confidential, false),
+		       w.definition->>'initial',
+		       (SELECT st->>'category'
+		        FROM jsonb_array_elements(w.definition->'states') st
+		        WHERE st->>'key' = w.definition->>'initial')
+		FROM teams t
+		LEFT JOIN team_settings ts ON ts.team_id = t.id
+		LEFT JOIN workflows w ON w.id = ts.workflow_id
+		ORDER BY t.created_at, t.id
+	`
+	const insertTask = `
+		INSERT INTO tasks (
+			team_id,
+			title,
+			description,
+			reporter_id,
+			assignee_id,
+			due_at,
+			status,
+			workflow_state,
+			priority,
+			announcement_id,
+			assigned_at,
+			acknowledged_at,
+			created_at,
+			updated_at
+		)
+		VALUES ($1, $2, $3, $4, $5, $6, COALESCE($7, 'open')::task_status, $8, $9, $10, $11,
+		        CASE WHEN $4::uuid = $5::uuid THEN $11::timestamptz END,
+		        $11, $11)
+		` + taskReturning
+	const setTeamCount = `UPDATE announcements SET team_count = $2 WHERE id = $1`
+
+	tx, err := s.pool.Begin(ctx)
+	if err != nil {
+		return nil, nil, fmt.Errorf("create announcement: begin: %w", err)
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	a, err := scanAnnouncement(tx.QueryRow(ctx, insertAnnouncement, title, in.Description, in.DueAt.UTC(), in.Priority, createdBy, now.UTC()))
+	if err != nil {
+		return nil, nil, fmt.Errorf("create announcement: %w", err)
+	}
+
+	type target struct {
+		teamID, ownerID uuid.UUID
+		confidential    bool
+		state, category *string
+	}
+	rows, err := tx.Query(ctx, listTeams)
+	if err != nil {
+		return nil, nil, fmt.Errorf("create announcement: list teams: %w", err)
+	}
+	targets, err := pgx.CollectRows(rows, func(row pgx.CollectableRow) (target, error) {
+		var t target
+		err := row.Scan(&t.teamID, &t.ownerID, &t.confidential, &t.state, &t.category)
+		return t, err
+	})
+	if err != nil {
+		return nil, nil, fmt.Errorf("create announcement: list teams: %w", err)
+	}
+
+	tasks := make([]Task, 0, len(targets))
+	for _, t := range targets {
+		description := in.Description
+		if t.confidential && description != nil && *description != "" {
+			if s.cipher == nil {
+				return nil, nil, ErrEncryptionUnavailable
+			}
+			sealed, err := s.cipher.Encrypt(*description, t.teamID[:])
+			if err != nil {
+				return nil, nil, fmt.Errorf("seal description team_id=%s: %w", t.teamID, err)
+			}
+			description = &sealed
+		}
+
+		task, err := s.scanTaskRow(tx.QueryRow(ctx, insertTask,
+			t.teamID,
+			title,
+			description,
+			createdBy,
+			t.ownerID,
+			in.DueAt.UTC(),
+			t.category,
+			t.state,
+			in.Priority,
+			a.ID,
+			now.UTC(),
+		))
+		if err != nil {
+			return nil, nil, fmt.Errorf("create announcement: task team_id=%s: %w", t.teamID, err)
+		}
+		if err := recordEvent(ctx, tx, nil, task, types.TaskEventCreated, &createdBy, now); err != nil {
+			return nil, nil, err
+		}
+		tasks = append(tasks, *task)
+
+		switch task.Status {
+		case DoneStatus:
+			a.Progress.Done++
+		case CanceledStatus:
+			a.Progress.Canceled++
+		default:
+			a.Progress.Open++
+		}
+	}
+
+	ids := make([]uuid.UUID, len(tasks))
+	for i, t := range tasks {
+		ids[i] = t.ID
+	}
+	if err := reminderstore.AddDefaults(ctx, tx, ids, now); err != nil {
+		return nil, nil, fmt.Errorf("create announcement: %w", err)
+	}
+	if _, err := tx.Exec(ctx, setTeamCount, a.ID, len(tasks)); err != nil {
+		return nil, nil, fmt.Errorf("create announcement: %w", err)
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return nil, nil, fmt.Errorf("create announcement: commit: %w", err)
+	}
+	a.Teams = len(tasks)
+	return a, tasks, nil
+}
+
+// ListAnnouncements returns announcements newest first with their progress
+// as of now.
+func (s *PGTaskStore) ListAnnouncements(ctx context.Context, limit, offset int, now time.Time) ([]Announcement, error) {
+	q := `
+		SELECT ` + announcementColumns + `,
+		       p.open, p.overdue, p.done, p.canceled
+		FROM (
+			SELECT * FROM announcements
+			ORDER BY created_at DESC, id
+			LIMIT $1 OFFSET $2
+		) a
+		CROSS JOIN LATERAL (
+			SELECT COUNT(*) FILTER (WHERE c.status IN ('open', 'in_progress')) AS open,
+			       COUNT(*) FILTER (WHERE c.status IN ('open', 'in_progress') AND c.due_at < $3) AS overdue,
+			       COUNT(*) FILTER (WHERE c.status = 'done') AS done,
+			       COUNT(*) FILTER (WHERE c.status = 'canceled') AS canceled
+			FROM (` + strings.ReplaceAll(announcementCopies, "$1", "a.id") + `) c
+		) p
+		ORDER BY a.created_at DESC, a.id
+	`
+	rows, err := s.pool.Query(ctx, q, limit, offset, now.UTC())
+	if err != nil {
+		return nil, fmt.Errorf("list announcements: %w", err)
+	}
+	defer rows.Close()
+
+	out := []Announcement{}
+	for rows.Next() {
+		var p types.AnnouncementProgress
+		a, err := scanAnnouncement(rows, &p.Open, &p.Overdue, &p.Done, &p.Canceled)
+		if err != nil {
+			return nil, fmt.Errorf("list announcements: scan: %w", err)
+		}
+		p.Deleted = max(a.Teams-p.Open-p.Done-p.Canceled, 0)
+		a.Progress = p
+		out = append(out, *a)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("list announcements: %w", err)
+	}
+	return out, nil
+}
+
+// GetAnnouncement returns the announcement and every team's copy, by team
+// name. Teams whose copy was deleted are only counted.
+func (s *PGTaskStore) GetAnnouncement(ctx context.Context, id uuid.UUID, now time.Time) (*Announcement, []AnnouncementTeam, error) {
+	q := `SELECT ` + announcementColumns + ` FROM announcements a WHERE a.id = $1`
+	a, err := scanAnnouncement(s.pool.QueryRow(ctx, q, id))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, nil, ErrAnnouncementNotFound
+		}
+		return nil, nil, fmt.Errorf("get announcement id=%s: %w", id, err)
+	}
+
+	q = `
+		SELECT c.team_id, tm.name, c.id, c.assignee_id, c.status, c.due_at, c.updated_at
+		FROM (` + announcementCopies + `) c
+		JOIN teams tm ON tm.id = c.team_id
+		ORDER BY tm.name, c.id
+	`
+	rows, err := s.pool.Query(ctx, q, id)
+	if err != nil {
+		return nil, nil, fmt.Errorf("get announcement teams id=%s: %w", id, err)
+	}
+	defer rows.Close()
+
+	teams := []AnnouncementTeam{}
+	for rows.Next() {
+		var t AnnouncementTeam
+		var dueAt time.Time
+		if err := rows.Scan(&t.TeamID, &t.TeamName, &t.TaskID, &t.AssigneeID, &t.Status, &dueAt, &t.UpdatedAt); err != nil {
+			return nil, nil, fmt.Errorf("get announcement teams id=%s: scan: %w", id, err)
+		}
+		switch t.Status {
+		case DoneStatus:
+			a.Progress.Done++
+		case CanceledStatus:
+			a.Progress.Canceled++
+		default:
+			a.Progress.Open++
+			if dueAt.Before(now) {
+				t.Overdue = true
+				a.Progress.Overdue++
+			}
+		}
+		teams = append(teams, t)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, nil, fmt.Errorf("get announcement teams id=%s: %w", id, err)
+	}
+	a.Progress.Deleted = max(a.Teams-len(teams), 0)
+	return a, teams, nil
+}
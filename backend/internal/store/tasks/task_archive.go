@@ -0,0 +1,95 @@
+package store
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ArchiveFinished moves up to limit done or canceled tasks last updated
+// before olderThan into tasks_archive, oldest first. Tasks under legal hold
+// stay put. Their approvals and extension requests are dropped with them;
+// extra viewers are kept on the archived row.
+func (s *PGTaskStore) ArchiveFinished(ctx context.Context, olderThan time.Time, limit int, now time.Time) (int, error) {
+	// every sub-statement sees the same snapshot, so task_viewers still
+	// holds the rows the DELETE cascades away
+	const q = `
+		WITH doomed AS (
+			SELECT t.id
+			FROM tasks t
+			WHERE t.status IN ('done', 'canceled')
+			  AND t.updated_at < $1
+			  AND t.deleted_at IS NULL
+			  AND NOT EXISTS (
+				SELECT 1 FROM legal_holds lh
+				WHERE lh.task_id = t.id
+				   OR (lh.task_id IS NULL AND lh.team_id = t.team_id)
+			  )
+			ORDER BY t.updated_at
+			LIMIT $2
+			FOR UPDATE SKIP LOCKED
+		), moved AS (
+			DELETE FROM tasks t
+			USING doomed d
+			WHERE t.id = d.id
+			RETURNING t.*
+		)
+		INSERT INTO tasks_archive (` + taskColumns + `, viewer_ids, moved_at)
+		SELECT ` + taskColumns + `,
+			COALESCE((SELECT array_agg(tv.user_id) FROM task_viewers tv WHERE tv.task_id = moved.id), '{}'),
+			$3
+		FROM moved
+	`
+
+	ct, err := s.pool.Exec(ctx, q, olderThan.UTC(), limit, now.UTC())
+	if err != nil {
+		return 0, fmt.Errorf("archive finished tasks: %w", err)
+	}
+	return int(ct.RowsAffected()), nil
+}
+
+// StreamArchivedTeamTasks is StreamTeamTasks over tasks_archive. Tasks are
+// passed with Archived set and without labels, which are not archived.
+func (s *PGTaskStore) StreamArchivedTeamTasks(ctx context.Context, teamID, viewerID uuid.UUID, fn func(*ExportedTask) error) error {
+	if teamID == uuid.Nil {
+		return fmt.Errorf("%w: team_id cannot be nil", ErrInvalidInput)
+	}
+
+	const q = `
+		SELECT ` + taskColumns + `,
+		       (SELECT email::text FROM users WHERE id = a.reporter_id),
+		       (SELECT email::text FROM users WHERE id = a.assignee_id)
+		FROM tasks_archive a
+		WHERE a.team_id = $1
+		  AND (NOT a.is_private
+			OR a.reporter_id = $2
+			OR a.assignee_id = $2
+			OR $2 = ANY(a.viewer_ids))
+		ORDER BY a.created_at, a.id;
+	`
+
+	rows, err := s.pool.Query(ctx, q, teamID, viewerID)
+	if err != nil {
+		return fmt.Errorf("stream archived team tasks team_id=%s: %w", teamID, err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var e ExportedTask
+		t, err := s.scanTaskRow(rows, &e.ReporterEmail, &e.AssigneeEmail)
+		if err != nil {
+			return fmt.Errorf("stream archived team tasks team_id=%s: scan: %w", teamID, err)
+		}
+		t.Archived = true
+		e.Task = *t
+		if err := fn(&e); err != nil {
+			return err
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("stream archived team tasks team_id=%s: %w", teamID, err)
+	}
+	return nil
+}
@@ -0,0 +1,68 @@
+package store
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/diagnosis/interactive-todo/api/types"
+	"github.com/google/uuid"
+)
+
+// Archiving a task only sets archived_at: the task leaves the default
+// lists (TaskFilter.IncludeArchived brings it back) and gets no reminders
+// or nudges, but can still be read, changed and unarchived. Not to be
+// confused with ArchiveFinished, which moves old tasks to tasks_archive.
+
+// ArchiveTask archives a task. An archived task is returned as it is.
+func (s *PGTaskStore) ArchiveTask(ctx context.Context, id uuid.UUID, actorID *uuid.UUID, now time.Time) (*Task, error) {
+	return s.setArchived(ctx, id, true, actorID, now)
+}
+
+// UnarchiveTask puts an archived task back in the lists. A task that is
+// not archived is returned as it is.
+func (s *PGTaskStore) UnarchiveTask(ctx context.Context, id uuid.UUID, actorID *uuid.UUID, now time.Time) (*Task, error) {
+	return s.setArchived(ctx, id, false, actorID, now)
+}
+
+func (s *PGTaskStore) setArchived(ctx context.Context, id uuid.UUID, archive bool, actorID *uuid.UUID, now time.Time) (*Task, error) {
+	const q = `
+		UPDATE tasks
+		SET archived_at = CASE WHEN $2 THEN $3::timestamptz END,
+		    archived_by = CASE WHEN $2 THEN $4::uuid END,
+		    updated_at = $3
+		WHERE id = $1
+		` + taskReturning
+
+	tx, err := s.pool.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("archive task: begin: %w", err)
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	old, err := s.lockTask(ctx, tx, id)
+	if err != nil {
+		return nil, err
+	}
+	if (old.ArchivedAt != nil) == archive {
+		return old, nil
+	}
+
+	t, err := s.scanTaskRow(tx.QueryRow(ctx, q, id, archive, now.UTC(), actorID))
+	if err != nil {
+		return nil, fmt.Errorf("archive task id=%s archive=%t: %w", id, archive, err)
+	}
+	kind := types.TaskEventArchived
+	if !archive {
+		kind = types.TaskEventUnarchived
+	}
+	changes := map[string]FieldChange{"archived": {From: !archive, To: archive}}
+	if err := insertTaskEvent(ctx, tx, t.ID, t.TeamID, kind, changes, actorID, now); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("archive task: commit: %w", err)
+	}
+	return t, nil
+}
@@ -0,0 +1,118 @@
+package store
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/diagnosis/interactive-todo/api/types"
+	"github.com/google/uuid"
+)
+
+// MaxBulkTasks caps the tasks of one bulk change, which holds a lock on
+// each of them until it commits.
+const MaxBulkTasks = 200
+
+// ErrNotReporter is returned by bulk changes limited to the caller's own
+// tasks when a task was reported by someone else.
+var ErrNotReporter = errors.New("task was reported by someone else")
+
+type BulkAssignment struct {
+	TeamID     uuid.UUID
+	TaskIDs    []uuid.UUID
+	AssigneeID uuid.UUID
+	// ViewerID must be able to see every task; private tasks hidden from
+	// them count as missing.
+	ViewerID uuid.UUID
+	// ReportedOnly limits the change to tasks ViewerID reported, or one
+	// of Delegators, who delegated their powers to ViewerID.
+	ReportedOnly bool
+	Delegators   []uuid.UUID
+}
+
+// BulkAssign gives every task of in.TaskIDs to in.AssigneeID in one
+// transaction: either all of them change or none does. A task that is not
+// in the team returns ErrTaskNotFound, and one reported by someone else
+// under ReportedOnly ErrNotReporter, both naming the task. Tasks the
+// assignee already has are left untouched. It returns the tasks that
+// changed.
+func (s *PGTaskStore) BulkAssign(ctx context.Context, in BulkAssignment, actorID *uuid.UUID, now time.Time) ([]Task, error) {
+	switch {
+	case in.AssigneeID == uuid.Nil:
+		return nil, fmt.Errorf("%w: assignee_id cannot be nil", ErrInvalidInput)
+	case len(in.TaskIDs) == 0:
+		return nil, fmt.Errorf("%w: task_ids cannot be empty", ErrInvalidInput)
+	case len(in.TaskIDs) > MaxBulkTasks:
+		return nil, fmt.Errorf("%w: at most %d tasks at once", ErrInvalidInput, MaxBulkTasks)
+	}
+
+	// locked in id order, so two bulk changes over the same tasks cannot
+	// deadlock
+	q := `
+		SELECT ` + taskColumns + `
+		FROM tasks
+		WHERE team_id = $1
+		  AND id = ANY($2)
+		  AND deleted_at IS NULL
+		  AND ` + visibleTo("tasks", "$3") + `
+		ORDER BY id
+		FOR UPDATE
+	`
+
+	tx, err := s.pool.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("bulk assign: begin: %w", err)
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	rows, err := tx.Query(ctx, q, in.TeamID, in.TaskIDs, in.ViewerID)
+	if err != nil {
+		return nil, fmt.Errorf("bulk assign team_id=%s: lock tasks: %w", in.TeamID, err)
+	}
+	locked := make(map[uuid.UUID]*Task, len(in.TaskIDs))
+	for rows.Next() {
+		t, err := s.scanTaskRow(rows)
+		if err != nil {
+			rows.Close()
+			return nil, fmt.Errorf("bulk assign team_id=%s: scan: %w", in.TeamID, err)
+		}
+		locked[t.ID] = t
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("bulk assign team_id=%s: lock tasks: %w", in.TeamID, err)
+	}
+
+	ids := slices.Clone(in.TaskIDs)
+	slices.SortFunc(ids, func(a, b uuid.UUID) int { return slices.Compare(a[:], b[:]) })
+	ids = slices.Compact(ids)
+
+	changed := make([]Task, 0, len(ids))
+	for _, id := range ids {
+		before, ok := locked[id]
+		switch {
+		case !ok:
+			return nil, fmt.Errorf("%w: id=%s", ErrTaskNotFound, id)
+		case in.ReportedOnly && before.ReporterID != in.ViewerID && !slices.Contains(in.Delegators, before.ReporterID):
+			return nil, fmt.Errorf("%w: id=%s", ErrNotReporter, id)
+		case before.AssigneeID == in.AssigneeID:
+			continue
+		}
+
+		o, err := s.scanTaskRow(tx.QueryRow(ctx, assignTask, id, in.AssigneeID, now.UTC()))
+		if err != nil {
+			return nil, fmt.Errorf("bulk assign id=%s: %w", id, err)
+		}
+		if err := recordEvent(ctx, tx, before, o, types.TaskEventAssigned, actorID, now); err != nil {
+			return nil, err
+		}
+		changed = append(changed, *o)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("bulk assign: commit: %w", err)
+	}
+	return changed, nil
+}
@@ -0,0 +1,96 @@
+package store
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/diagnosis/interactive-todo/api/types"
+	"github.com/google/uuid"
+)
+
+type BurnUpDay = types.BurnUpDay
+
+// MaxBurnUpDays bounds the range of one burn-up chart.
+const MaxBurnUpDays = 366
+
+// BurnUp returns one row per day from from to to inclusive, both dates in
+// timezone, for the tasks of the team viewerID can see, archived ones
+// included. Canceled and trashed tasks are not part of the scope. A task
+// counts as completed from its completed_at.
+func (s *PGTaskStore) BurnUp(ctx context.Context, teamID, viewerID uuid.UUID, from, to time.Time, timezone string) ([]BurnUpDay, error) {
+	q := `
+		WITH scope AS (
+			SELECT t.id, t.created_at, t.status, t.completed_at, t.estimate_hours
+			FROM tasks t
+			WHERE t.team_id = $1
+			  AND t.deleted_at IS NULL
+			  AND ` + visibleTo("t", "$2") + `
+			UNION ALL
+			SELECT a.id, a.created_at, a.status, a.completed_at, a.estimate_hours
+			FROM tasks_archive a
+			WHERE a.team_id = $1
+			  AND (NOT a.is_private
+				OR a.reporter_id = $2
+				OR a.assignee_id = $2
+				OR $2 = ANY(a.viewer_ids))
+		), work AS (
+			SELECT (s.created_at AT TIME ZONE $5)::date AS created_day,
+			       (s.completed_at AT TIME ZONE $5)::date AS done_day,
+			       COALESCE(s.estimate_hours, 0) AS hours
+			FROM scope s
+			WHERE s.status <> 'canceled'
+		), days AS (
+			SELECT generate_series($3::date, $4::date, interval '1 day')::date AS day
+		), created AS (
+			SELECT GREATEST(created_day, $3::date) AS day, SUM(hours) AS hours, COUNT(*) AS tasks
+			FROM work
+			WHERE created_day <= $4::date
+			GROUP BY 1
+		), completed AS (
+			SELECT GREATEST(done_day, $3::date) AS day, SUM(hours) AS hours, COUNT(*) AS tasks
+			FROM work
+			WHERE done_day <= $4::date
+			GROUP BY 1
+		)
+		SELECT to_char(d.day, 'YYYY-MM-DD'),
+		       (SUM(COALESCE(c.hours, 0)) OVER w)::float8,
+		       (SUM(COALESCE(x.hours, 0)) OVER w)::float8,
+		       (SUM(COALESCE(c.tasks, 0)) OVER w)::int,
+		       (SUM(COALESCE(x.tasks, 0)) OVER w)::int
+		FROM days d
+		LEFT JOIN created c ON c.day = d.day
+		LEFT JOIN completed x ON x.day = d.day
+		WINDOW w AS (ORDER BY d.day)
+		ORDER BY d.day
+	`
+
+	switch {
+	case teamID == uuid.Nil:
+		return nil, fmt.Errorf("%w: team_id cannot be nil", ErrInvalidInput)
+	case to.Before(from):
+		return nil, fmt.Errorf("%w: from must not be after to", ErrInvalidInput)
+	case to.Sub(from) >= MaxBurnUpDays*24*time.Hour:
+		return nil, fmt.Errorf("%w: at most %d days at once", ErrInvalidInput, MaxBurnUpDays)
+	}
+
+	rows, err := s.pool.Query(ctx, q, teamID, viewerID,
+		from.Format(time.DateOnly), to.Format(time.DateOnly), timezone)
+	if err != nil {
+		return nil, fmt.Errorf("burn-up team_id=%s: %w", teamID, err)
+	}
+	defer rows.Close()
+
+	out := make([]BurnUpDay, 0, int(to.Sub(from).Hours()/24)+1)
+	for rows.Next() {
+		var d BurnUpDay
+		if err := rows.Scan(&d.Day, &d.ScopeHours, &d.CompletedHours, &d.ScopeTasks, &d.CompletedTasks); err != nil {
+			return nil, fmt.Errorf("burn-up team_id=%s: scan: %w", teamID, err)
+		}
+		out = append(out, d)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("burn-up team_id=%s: %w", teamID, err)
+	}
+	return out, nil
+}
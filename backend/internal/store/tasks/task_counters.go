@@ -0,0 +1,96 @@
+package store
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+// TeamTaskCounts are read from team_task_counters, which a trigger on
+// tasks keeps in step with every insert, status change and delete.
+type TeamTaskCounts struct {
+	TeamID     uuid.UUID `json:"team_id"`
+	Open       int64     `json:"open"`
+	InProgress int64     `json:"in_progress"`
+	Done       int64     `json:"done"`
+	Canceled   int64     `json:"canceled"`
+	// Overdue changes with the clock, so it is counted on read from the
+	// (team_id, status, due_at) index.
+	Overdue int64 `json:"overdue"`
+}
+
+func (s *PGTaskStore) GetTeamTaskCounts(ctx context.Context, teamID uuid.UUID, now time.Time) (*TeamTaskCounts, error) {
+	const q = `
+		SELECT open_count, in_progress_count, done_count, canceled_count
+		FROM team_task_counters
+		WHERE team_id = $1
+	`
+	const overdue = `
+		SELECT COUNT(*)
+		FROM tasks
+		WHERE team_id = $1
+		  AND status IN ('open', 'in_progress')
+		  AND due_at < $2
+		  AND deleted_at IS NULL
+	`
+
+	c := TeamTaskCounts{TeamID: teamID}
+	err := s.pool.QueryRow(ctx, q, teamID).Scan(&c.Open, &c.InProgress, &c.Done, &c.Canceled)
+	if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+		return nil, fmt.Errorf("get team task counts team_id=%s: %w", teamID, err)
+	}
+	// no row means the team never had a task
+	if errors.Is(err, pgx.ErrNoRows) {
+		return &c, nil
+	}
+
+	if err := s.pool.QueryRow(ctx, overdue, teamID, now.UTC()).Scan(&c.Overdue); err != nil {
+		return nil, fmt.Errorf("count overdue tasks team_id=%s: %w", teamID, err)
+	}
+	return &c, nil
+}
+
+// ReconcileTaskCounters recounts every team from tasks and overwrites the
+// counters that drifted, returning how many were corrected. A task changed
+// while it runs can leave a fresh discrepancy, which the next run fixes.
+func (s *PGTaskStore) ReconcileTaskCounters(ctx context.Context, now time.Time) (int, error) {
+	const q = `
+		WITH actual AS (
+			SELECT tm.id AS team_id,
+			       COUNT(t.id) FILTER (WHERE t.status = 'open')        AS open_count,
+			       COUNT(t.id) FILTER (WHERE t.status = 'in_progress') AS in_progress_count,
+			       COUNT(t.id) FILTER (WHERE t.status = 'done')        AS done_count,
+			       COUNT(t.id) FILTER (WHERE t.status = 'canceled')    AS canceled_count
+			FROM teams tm
+			LEFT JOIN tasks t ON t.team_id = tm.id AND t.deleted_at IS NULL
+			GROUP BY tm.id
+		)
+		INSERT INTO team_task_counters AS c
+			(team_id, open_count, in_progress_count, done_count, canceled_count, updated_at)
+		SELECT a.team_id, a.open_count, a.in_progress_count, a.done_count, a.canceled_count, $1
+		FROM actual a
+		LEFT JOIN team_task_counters cur ON cur.team_id = a.team_id
+		WHERE CASE
+			WHEN cur.team_id IS NULL
+				THEN a.open_count + a.in_progress_count + a.done_count + a.canceled_count > 0
+			ELSE (cur.open_count, cur.in_progress_count, cur.done_count, cur.canceled_count)
+				IS DISTINCT FROM (a.open_count, a.in_progress_count, a.done_count, a.canceled_count)
+		END
+		ON CONFLICT (team_id) DO UPDATE SET
+			open_count        = EXCLUDED.open_count,
+			in_progress_count = EXCLUDED.in_progress_count,
+			done_count        = EXCLUDED.done_count,
+			canceled_count    = EXCLUDED.canceled_count,
+			updated_at        = EXCLUDED.updated_at
+	`
+
+	ct, err := s.pool.Exec(ctx, q, now.UTC())
+	if err != nil {
+		return 0, fmt.Errorf("reconcile task counters: %w", err)
+	}
+	return int(ct.RowsAffected()), nil
+}
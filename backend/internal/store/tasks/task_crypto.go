@@ -0,0 +1,140 @@
+package store
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/diagnosis/interactive-todo/internal/secure/fieldcrypt"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+// Descriptions of tasks in confidential teams are sealed with the team id as
+// associated data. Reads open any sealed value regardless of the team's
+// current setting, so turning confidentiality off never hides old data.
+
+func (s *PGTaskStore) openDescription(t *Task) error {
+	plain, err := s.openSealed(t.TeamID, t.Description)
+	if err != nil {
+		return fmt.Errorf("open description task_id=%s: %w", t.ID, err)
+	}
+	t.Description = plain
+	return nil
+}
+
+// openSealed opens a description sealed by sealDescription, and returns
+// anything else as it is.
+func (s *PGTaskStore) openSealed(teamID uuid.UUID, v *string) (*string, error) {
+	if v == nil || !fieldcrypt.IsEncrypted(*v) {
+		return v, nil
+	}
+	if s.cipher == nil {
+		return nil, ErrEncryptionUnavailable
+	}
+	plain, err := s.cipher.Decrypt(*v, teamID[:])
+	if err != nil {
+		return nil, err
+	}
+	return &plain, nil
+}
+
+func (s *PGTaskStore) sealDescription(ctx context.Context, teamID uuid.UUID, description *string) (*string, error) {
+	if description == nil || *description == "" {
+		return description, nil
+	}
+
+	confidential, err := s.isConfidential(ctx, teamID)
+	if err != nil {
+		return nil, err
+	}
+	if !confidential {
+		return description, nil
+	}
+	if s.cipher == nil {
+		return nil, ErrEncryptionUnavailable
+	}
+
+	sealed, err := s.cipher.Encrypt(*description, teamID[:])
+	if err != nil {
+		return nil, fmt.Errorf("seal description team_id=%s: %w", teamID, err)
+	}
+	return &sealed, nil
+}
+
+func (s *PGTaskStore) isConfidential(ctx context.Context, teamID uuid.UUID) (bool, error) {
+	const q = `SELECT confidential FROM team_settings WHERE team_id = $1`
+
+	var confidential bool
+	if err := s.pool.QueryRow(ctx, q, teamID).Scan(&confidential); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return false, nil
+		}
+		return false, fmt.Errorf("check confidential team_id=%s: %w", teamID, err)
+	}
+	return confidential, nil
+}
+
+// SealConfidentialDescriptions encrypts up to limit plaintext descriptions
+// left in confidential teams, e.g. tasks created before the team was marked
+// confidential. updated_at is left alone: the content did not change.
+func (s *PGTaskStore) SealConfidentialDescriptions(ctx context.Context, limit int) (int, error) {
+	if s.cipher == nil {
+		return 0, ErrEncryptionUnavailable
+	}
+
+	const sel = `
+		SELECT t.id, t.team_id, t.description
+		FROM tasks t
+		JOIN team_settings ts ON ts.team_id = t.team_id
+		WHERE ts.confidential
+		  AND t.description IS NOT NULL
+		  AND t.description <> ''
+		  AND NOT starts_with(t.description, $1)
+		LIMIT $2
+	`
+
+	rows, err := s.pool.Query(ctx, sel, fieldcrypt.Prefix(), limit)
+	if err != nil {
+		return 0, fmt.Errorf("seal descriptions: query: %w", err)
+	}
+	type pending struct {
+		id, teamID  uuid.UUID
+		description string
+	}
+	var todo []pending
+	for rows.Next() {
+		var p pending
+		if err := rows.Scan(&p.id, &p.teamID, &p.description); err != nil {
+			rows.Close()
+			return 0, fmt.Errorf("seal descriptions: scan: %w", err)
+		}
+		todo = append(todo, p)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return 0, fmt.Errorf("seal descriptions: rows: %w", err)
+	}
+
+	// the WHERE on the old value skips rows edited since they were read
+	const upd = `
+		UPDATE tasks
+		SET description = $2
+		WHERE id = $1
+		  AND description = $3
+	`
+
+	sealed := 0
+	for _, p := range todo {
+		enc, err := s.cipher.Encrypt(p.description, p.teamID[:])
+		if err != nil {
+			return sealed, fmt.Errorf("seal descriptions task_id=%s: %w", p.id, err)
+		}
+		ct, err := s.pool.Exec(ctx, upd, p.id, enc, p.description)
+		if err != nil {
+			return sealed, fmt.Errorf("seal descriptions task_id=%s: %w", p.id, err)
+		}
+		sealed += int(ct.RowsAffected())
+	}
+	return sealed, nil
+}
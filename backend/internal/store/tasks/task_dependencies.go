@@ -0,0 +1,320 @@
+package store
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/diagnosis/interactive-todo/api/types"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+type TaskDependency = types.TaskDependency
+type DependencyTask = types.DependencyTask
+type TaskDependencyChain = types.TaskDependenciesResponse
+type CrossTeamDependency = types.CrossTeamDependency
+
+var (
+	ErrDependencyNotFound = errors.New("dependency not found")
+	ErrDependencyExists   = errors.New("dependency already exists")
+	ErrDependencyCycle    = errors.New("dependency would create a cycle")
+)
+
+// readableBy is visibleTo for views that span teams: the task must also
+// be out of the trash and in a team viewerParam belongs to.
+func readableBy(alias, viewerParam string) string {
+	return `(` + alias + `.deleted_at IS NULL
+		AND EXISTS (
+			SELECT 1 FROM team_members tm
+			WHERE tm.team_id = ` + alias + `.team_id
+			  AND tm.user_id = ` + viewerParam + `
+		)
+		AND ` + visibleTo(alias, viewerParam) + `)`
+}
+
+// AddDependency records that taskID is blocked by blockerID. The tasks may
+// be in different teams. A link that would close a loop, directly or
+// through other tasks, returns ErrDependencyCycle.
+func (s *PGTaskStore) AddDependency(ctx context.Context, taskID, blockerID uuid.UUID, createdBy *uuid.UUID, now time.Time) (*TaskDependency, error) {
+	if taskID == blockerID {
+		return nil, fmt.Errorf("%w: a task cannot block itself", ErrInvalidInput)
+	}
+
+	// everything blockerID waits on; taskID among them means a loop
+	const reachesTask = `
+		WITH RECURSIVE up(id) AS (
+			SELECT blocker_id FROM task_dependencies WHERE task_id = $1
+			UNION
+			SELECT d.blocker_id
+			FROM task_dependencies d
+			JOIN up ON d.task_id = up.id
+		)
+		SELECT EXISTS (SELECT 1 FROM up WHERE id = $2)
+	`
+	const insert = `
+		INSERT INTO task_dependencies (task_id, team_id, blocker_id, blocker_team_id, created_by, created_at)
+		SELECT t.id, t.team_id, b.id, b.team_id, $3, $4
+		FROM tasks t, tasks b
+		WHERE t.id = $1 AND t.deleted_at IS NULL
+		  AND b.id = $2 AND b.deleted_at IS NULL
+		ON CONFLICT (task_id, blocker_id) DO NOTHING
+		RETURNING task_id, blocker_id, created_by, created_at
+	`
+	const exists = `SELECT EXISTS (SELECT 1 FROM task_dependencies WHERE task_id = $1 AND blocker_id = $2)`
+
+	tx, err := s.pool.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("add dependency: begin: %w", err)
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	// two links added at once could close a loop neither sees; writers
+	// take turns, readers are not blocked
+	if _, err := tx.Exec(ctx, `LOCK TABLE task_dependencies IN SHARE ROW EXCLUSIVE MODE`); err != nil {
+		return nil, fmt.Errorf("add dependency: lock: %w", err)
+	}
+
+	var cycle bool
+	if err := tx.QueryRow(ctx, reachesTask, blockerID, taskID).Scan(&cycle); err != nil {
+		return nil, fmt.Errorf("add dependency task_id=%s blocker_id=%s: cycle check: %w", taskID, blockerID, err)
+	}
+	if cycle {
+		return nil, ErrDependencyCycle
+	}
+
+	var d TaskDependency
+	err = tx.QueryRow(ctx, insert, taskID, blockerID, createdBy, now.UTC()).Scan(&d.TaskID, &d.BlockerID, &d.CreatedBy, &d.CreatedAt)
+	if errors.Is(err, pgx.ErrNoRows) {
+		var found bool
+		if err := tx.QueryRow(ctx, exists, taskID, blockerID).Scan(&found); err != nil {
+			return nil, fmt.Errorf("add dependency task_id=%s blocker_id=%s: %w", taskID, blockerID, err)
+		}
+		if found {
+			return nil, ErrDependencyExists
+		}
+		return nil, ErrTaskNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("add dependency task_id=%s blocker_id=%s: %w", taskID, blockerID, err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("add dependency: commit: %w", err)
+	}
+	return &d, nil
+}
+
+func (s *PGTaskStore) RemoveDependency(ctx context.Context, taskID, blockerID uuid.UUID) error {
+	const q = `DELETE FROM task_dependencies WHERE task_id = $1 AND blocker_id = $2`
+	tag, err := s.pool.Exec(ctx, q, taskID, blockerID)
+	if err != nil {
+		return fmt.Errorf("remove dependency task_id=%s blocker_id=%s: %w", taskID, blockerID, err)
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrDependencyNotFound
+	}
+	return nil
+}
+
+// DependencyChain follows the links of taskID up to maxDepth away in both
+// directions, across teams. The walk goes on only through tasks viewerID
+// can read; the others end it and are counted in Hidden. Trashed tasks are
+// left out.
+func (s *PGTaskStore) DependencyChain(ctx context.Context, taskID, viewerID uuid.UUID, maxDepth int) (*TaskDependencyChain, error) {
+	q := `
+		WITH RECURSIVE
+		up(id, depth) AS (
+			SELECT d.blocker_id, 1 FROM task_dependencies d WHERE d.task_id = $1
+			UNION
+			SELECT d.blocker_id, u.depth + 1
+			FROM up u
+			JOIN tasks t ON t.id = u.id
+			JOIN task_dependencies d ON d.task_id = u.id
+			WHERE u.depth < $3 AND ` + readableBy("t", "$2") + `
+		),
+		down(id, depth) AS (
+			SELECT d.task_id, 1 FROM task_dependencies d WHERE d.blocker_id = $1
+			UNION
+			SELECT d.task_id, w.depth + 1
+			FROM down w
+			JOIN tasks t ON t.id = w.id
+			JOIN task_dependencies d ON d.blocker_id = w.id
+			WHERE w.depth < $3 AND ` + readableBy("t", "$2") + `
+		)
+		SELECT false, up.id, MIN(up.depth)
+		FROM up JOIN tasks t ON t.id = up.id AND t.deleted_at IS NULL
+		GROUP BY up.id
+		UNION ALL
+		SELECT true, down.id, MIN(down.depth)
+		FROM down JOIN tasks t ON t.id = down.id AND t.deleted_at IS NULL
+		GROUP BY down.id
+	`
+	type node struct {
+		blocking bool
+		id       uuid.UUID
+		depth    int
+	}
+	rows, err := s.pool.Query(ctx, q, taskID, viewerID, maxDepth)
+	if err != nil {
+		return nil, fmt.Errorf("dependency chain task_id=%s: %w", taskID, err)
+	}
+	nodes, err := pgx.CollectRows(rows, func(row pgx.CollectableRow) (node, error) {
+		var n node
+		err := row.Scan(&n.blocking, &n.id, &n.depth)
+		return n, err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("dependency chain task_id=%s: %w", taskID, err)
+	}
+
+	ids := make([]uuid.UUID, 0, len(nodes))
+	for _, n := range nodes {
+		ids = append(ids, n.id)
+	}
+	tasks, err := s.readableDependencyTasks(ctx, viewerID, ids)
+	if err != nil {
+		return nil, err
+	}
+
+	out := &TaskDependencyChain{
+		TaskID:    taskID,
+		BlockedBy: []DependencyTask{},
+		Blocking:  []DependencyTask{},
+		Links:     []TaskDependency{},
+	}
+	shown := []uuid.UUID{taskID}
+	for _, n := range nodes {
+		t, ok := tasks[n.id]
+		if !ok {
+			out.Hidden++
+			continue
+		}
+		t.Depth = n.depth
+		if n.blocking {
+			out.Blocking = append(out.Blocking, t)
+		} else {
+			out.BlockedBy = append(out.BlockedBy, t)
+		}
+		shown = append(shown, n.id)
+	}
+	byDepth := func(a, b DependencyTask) int {
+		return cmp.Or(cmp.Compare(a.Depth, b.Depth), a.DueAt.Compare(b.DueAt), slices.Compare(a.ID[:], b.ID[:]))
+	}
+	slices.SortFunc(out.BlockedBy, byDepth)
+	slices.SortFunc(out.Blocking, byDepth)
+
+	// only the links between tasks in the response
+	const links = `
+		SELECT task_id, blocker_id, created_by, created_at
+		FROM task_dependencies
+		WHERE task_id = ANY($1) AND blocker_id = ANY($1)
+		ORDER BY created_at, task_id, blocker_id
+	`
+	rows, err = s.pool.Query(ctx, links, shown)
+	if err != nil {
+		return nil, fmt.Errorf("dependency chain task_id=%s: links: %w", taskID, err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var d TaskDependency
+		if err := rows.Scan(&d.TaskID, &d.BlockerID, &d.CreatedBy, &d.CreatedAt); err != nil {
+			return nil, fmt.Errorf("dependency chain task_id=%s: scan link: %w", taskID, err)
+		}
+		out.Links = append(out.Links, d)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("dependency chain task_id=%s: links: %w", taskID, err)
+	}
+	return out, nil
+}
+
+// ListCrossTeamDependencies returns the links between tasks of different
+// teams where viewerID can read both ends, newest first. Unless
+// includeResolved is set, only links whose two tasks are still open or in
+// progress are listed.
+func (s *PGTaskStore) ListCrossTeamDependencies(ctx context.Context, viewerID uuid.UUID, includeResolved bool, limit int) ([]CrossTeamDependency, error) {
+	q := `
+		SELECT d.task_id, d.blocker_id, d.created_by, d.created_at
+		FROM task_dependencies d
+		JOIN tasks t ON t.id = d.task_id
+		JOIN tasks b ON b.id = d.blocker_id
+		WHERE d.team_id <> d.blocker_team_id
+		  AND ($2 OR (t.status IN ('open', 'in_progress') AND b.status IN ('open', 'in_progress')))
+		  AND ` + readableBy("t", "$1") + `
+		  AND ` + readableBy("b", "$1") + `
+		ORDER BY d.created_at DESC, d.task_id, d.blocker_id
+		LIMIT $3
+	`
+	rows, err := s.pool.Query(ctx, q, viewerID, includeResolved, limit)
+	if err != nil {
+		return nil, fmt.Errorf("list cross-team dependencies user_id=%s: %w", viewerID, err)
+	}
+	deps, err := pgx.CollectRows(rows, func(row pgx.CollectableRow) (TaskDependency, error) {
+		var d TaskDependency
+		err := row.Scan(&d.TaskID, &d.BlockerID, &d.CreatedBy, &d.CreatedAt)
+		return d, err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("list cross-team dependencies user_id=%s: %w", viewerID, err)
+	}
+
+	ids := make([]uuid.UUID, 0, 2*len(deps))
+	for _, d := range deps {
+		ids = append(ids, d.TaskID, d.BlockerID)
+	}
+	tasks, err := s.readableDependencyTasks(ctx, viewerID, ids)
+	if err != nil {
+		return nil, err
+	}
+
+	out := make([]CrossTeamDependency, 0, len(deps))
+	for _, d := range deps {
+		t, ok1 := tasks[d.TaskID]
+		b, ok2 := tasks[d.BlockerID]
+		if !ok1 || !ok2 {
+			// changed between the two queries
+			continue
+		}
+		out = append(out, CrossTeamDependency{Task: t, Blocker: b, CreatedBy: d.CreatedBy, CreatedAt: d.CreatedAt})
+	}
+	return out, nil
+}
+
+// readableDependencyTasks loads the tasks of ids that viewerID can read,
+// with their team names.
+func (s *PGTaskStore) readableDependencyTasks(ctx context.Context, viewerID uuid.UUID, ids []uuid.UUID) (map[uuid.UUID]DependencyTask, error) {
+	out := make(map[uuid.UUID]DependencyTask, len(ids))
+	if len(ids) == 0 {
+		return out, nil
+	}
+
+	q := `
+		SELECT ` + taskColumns + `,
+		       (SELECT tn.name FROM teams tn WHERE tn.id = tasks.team_id)
+		FROM tasks
+		WHERE tasks.id = ANY($1)
+		  AND ` + readableBy("tasks", "$2") + `
+	`
+	rows, err := s.pool.Query(ctx, q, ids, viewerID)
+	if err != nil {
+		return nil, fmt.Errorf("load dependency tasks: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var teamName string
+		t, err := s.scanTaskRow(rows, &teamName)
+		if err != nil {
+			return nil, fmt.Errorf("load dependency tasks: scan: %w", err)
+		}
+		out[t.ID] = DependencyTask{Task: *t, TeamName: teamName}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("load dependency tasks: %w", err)
+	}
+	return out, nil
+}
@@ -0,0 +1,184 @@
+package store
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/diagnosis/interactive-todo/api/types"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+// task_embeddings only exists where migrations/optional/task_embeddings.sql
+// was applied; nothing here is called unless EMBEDDINGS_PROVIDER is set.
+
+type SimilarTask = types.SimilarTask
+
+// EmbeddingSource is a task whose stored embedding is missing or older
+// than its last update. StoredHash and StoredModel are empty when there is
+// none.
+type EmbeddingSource struct {
+	TaskID      uuid.UUID
+	TeamID      uuid.UUID
+	Title       string
+	Description *string
+	StoredHash  string
+	StoredModel string
+}
+
+// EmbeddingDimensions returns the size of task_embeddings.embedding, or 0
+// when the table does not exist.
+func (s *PGTaskStore) EmbeddingDimensions(ctx context.Context) (int, error) {
+	const q = `
+		SELECT a.atttypmod
+		FROM pg_attribute a
+		WHERE a.attrelid = to_regclass('task_embeddings')
+		  AND a.attname = 'embedding'
+	`
+	var dims int
+	if err := s.pool.QueryRow(ctx, q).Scan(&dims); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return 0, nil
+		}
+		return 0, fmt.Errorf("embedding dimensions: %w", err)
+	}
+	return dims, nil
+}
+
+// ListEmbeddingBacklog returns up to limit tasks to (re-)embed, most
+// recently updated first. Confidential teams are never sent to a provider.
+func (s *PGTaskStore) ListEmbeddingBacklog(ctx context.Context, model string, limit int) ([]EmbeddingSource, error) {
+	const q = `
+		SELECT t.id, t.team_id, t.title, t.description,
+		       COALESCE(te.content_hash, ''), COALESCE(te.model, '')
+		FROM tasks t
+		LEFT JOIN team_settings ts ON ts.team_id = t.team_id
+		LEFT JOIN task_embeddings te ON te.task_id = t.id
+		WHERE NOT COALESCE(ts.confidential, false)
+		  AND t.deleted_at IS NULL
+		  AND (te.task_id IS NULL OR te.model <> $1 OR te.updated_at < t.updated_at)
+		ORDER BY t.updated_at DESC
+		LIMIT $2
+	`
+	rows, err := s.pool.Query(ctx, q, model, limit)
+	if err != nil {
+		return nil, fmt.Errorf("list embedding backlog: %w", err)
+	}
+	defer rows.Close()
+
+	var out []EmbeddingSource
+	for rows.Next() {
+		var e EmbeddingSource
+		if err := rows.Scan(&e.TaskID, &e.TeamID, &e.Title, &e.Description, &e.StoredHash, &e.StoredModel); err != nil {
+			return nil, fmt.Errorf("scan embedding backlog: %w", err)
+		}
+		// left over from a team that was confidential once
+		t := Task{ID: e.TaskID, TeamID: e.TeamID, Description: e.Description}
+		if err := s.openDescription(&t); err != nil {
+			return nil, err
+		}
+		e.Description = t.Description
+		out = append(out, e)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("list embedding backlog: %w", err)
+	}
+	return out, nil
+}
+
+// SaveEmbedding stores a task's vector. A nil vec only marks the stored
+// one as current, for edits that did not change the text.
+func (s *PGTaskStore) SaveEmbedding(ctx context.Context, taskID uuid.UUID, model, contentHash string, vec []float32, now time.Time) error {
+	if vec == nil {
+		const touch = `UPDATE task_embeddings SET updated_at = $2 WHERE task_id = $1`
+		if _, err := s.pool.Exec(ctx, touch, taskID, now.UTC()); err != nil {
+			return fmt.Errorf("touch embedding task_id=%s: %w", taskID, err)
+		}
+		return nil
+	}
+
+	const q = `
+		INSERT INTO task_embeddings (task_id, model, content_hash, embedding, updated_at)
+		VALUES ($1, $2, $3, $4::vector, $5)
+		ON CONFLICT (task_id) DO UPDATE
+		SET model        = EXCLUDED.model,
+		    content_hash = EXCLUDED.content_hash,
+		    embedding    = EXCLUDED.embedding,
+		    updated_at   = EXCLUDED.updated_at
+	`
+	if _, err := s.pool.Exec(ctx, q, taskID, model, contentHash, vectorLiteral(vec), now.UTC()); err != nil {
+		return fmt.Errorf("save embedding task_id=%s: %w", taskID, err)
+	}
+	return nil
+}
+
+// SimilarTasks returns the team's tasks nearest to vec that viewerID may
+// see, best first, leaving out excludeID and anything scoring below
+// minScore.
+func (s *PGTaskStore) SimilarTasks(
+	ctx context.Context,
+	teamID, viewerID uuid.UUID,
+	model string,
+	vec []float32,
+	excludeID uuid.UUID,
+	minScore float64,
+	limit int,
+) ([]SimilarTask, error) {
+	// over-fetch so private tasks filtered out below do not starve the list
+	q := `
+		WITH nearest AS (
+			SELECT task_id, embedding <=> $3::vector AS distance
+			FROM task_embeddings
+			WHERE team_id = $1
+			  AND model = $4
+			ORDER BY distance
+			LIMIT $6 * 4
+		)
+		SELECT ` + taskColumns + `, 1 - nearest.distance
+		FROM tasks
+		JOIN nearest ON nearest.task_id = tasks.id
+		WHERE tasks.id <> $5
+		  AND tasks.deleted_at IS NULL
+		  AND 1 - nearest.distance >= $7
+		  AND ` + visibleTo("tasks", "$2") + `
+		ORDER BY nearest.distance
+		LIMIT $6
+	`
+	rows, err := s.pool.Query(ctx, q, teamID, viewerID, vectorLiteral(vec), model, excludeID, limit, minScore)
+	if err != nil {
+		return nil, fmt.Errorf("similar tasks team_id=%s: %w", teamID, err)
+	}
+	defer rows.Close()
+
+	out := []SimilarTask{}
+	for rows.Next() {
+		var score float64
+		t, err := s.scanTaskRow(rows, &score)
+		if err != nil {
+			return nil, fmt.Errorf("scan similar task: %w", err)
+		}
+		out = append(out, SimilarTask{Task: *t, Score: score})
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("similar tasks team_id=%s: %w", teamID, err)
+	}
+	return out, nil
+}
+
+// vectorLiteral formats vec as pgvector's text input, '[1,2,3]'.
+func vectorLiteral(vec []float32) string {
+	var b strings.Builder
+	b.WriteByte('[')
+	for i, v := range vec {
+		if i > 0 {
+			b.WriteByte(',')
+		}
+		b.WriteString(strconv.FormatFloat(float64(v), 'g', -1, 32))
+	}
+	b.WriteByte(']')
+	return b.String()
+}
@@ -0,0 +1,225 @@
+package store
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+var ErrUnknownField = errors.New("unknown task field")
+
+// taskField maps a JSON field name to its column and a scan destination.
+// Destinations are pointers to pointers so NULLs come out as JSON null.
+type taskField struct {
+	column  string
+	newDest func() any
+}
+
+func uuidDest() any   { return new(*uuid.UUID) }
+func textDest() any   { return new(*string) }
+func timeDest() any   { return new(*time.Time) }
+func boolDest() any   { return new(*bool) }
+func floatDest() any  { return new(*float64) }
+func statusDest() any { return new(*TaskStatus) }
+func intDest() any    { return new(*int64) }
+func mapDest() any    { return new(map[string]any) }
+
+var taskFields = map[string]taskField{
+	"id":              {"id", uuidDest},
+	"team_id":         {"team_id", uuidDest},
+	"title":           {"title", textDest},
+	"description":     {"description", textDest},
+	"reporter_id":     {"reporter_id", uuidDest},
+	"assignee_id":     {"assignee_id", uuidDest},
+	"start_at":        {"start_at", timeDest},
+	"due_at":          {"due_at", timeDest},
+	"status":          {"status", statusDest},
+	"priority":        {"priority", textDest},
+	"estimate_hours":  {"estimate_hours", floatDest},
+	"workflow_state":  {"workflow_state", textDest},
+	"private":         {"is_private", boolDest},
+	"assigned_at":     {"assigned_at", timeDest},
+	"acknowledged_at": {"acknowledged_at", timeDest},
+	"announcement_id": {"announcement_id", uuidDest},
+	"created_at":      {"created_at", timeDest},
+	"updated_at":      {"updated_at", timeDest},
+	"version":         {"version", intDest},
+	"completed_at":    {"completed_at", timeDest},
+	"canceled_at":     {"canceled_at", timeDest},
+	"archived_at":     {"archived_at", timeDest},
+	"position":        {"position", floatDest},
+	"vote_count":      {"vote_count", intDest},
+	"project_id":      {"project_id", uuidDest},
+	"custom_fields":   {"custom_fields", mapDest},
+}
+
+// ParseTaskFields parses a comma-separated ?fields= value. Duplicates are
+// dropped; an empty value returns nil, meaning every field.
+func ParseTaskFields(raw string) ([]string, error) {
+	if strings.TrimSpace(raw) == "" {
+		return nil, nil
+	}
+	seen := make(map[string]bool)
+	var fields []string
+	for _, f := range strings.Split(raw, ",") {
+		f = strings.TrimSpace(f)
+		if _, ok := taskFields[f]; !ok {
+			return nil, fmt.Errorf("%w: %q", ErrUnknownField, f)
+		}
+		if !seen[f] {
+			seen[f] = true
+			fields = append(fields, f)
+		}
+	}
+	return fields, nil
+}
+
+// TaskScope selects the tasks of a projected list. Zero IDs are ignored,
+// but at least one of TeamID, ReporterID and AssigneeID must be set. A
+// ViewerID hides private tasks that viewer may not see.
+type TaskScope struct {
+	TeamID     uuid.UUID
+	ReporterID uuid.UUID
+	AssigneeID uuid.UUID
+	ViewerID   uuid.UUID
+	// ByDueDate orders by due_at instead of newest first unless Sort
+	// is set
+	ByDueDate bool
+	Filter    TaskFilter
+	Sort      SortSpec
+}
+
+// ListTaskFields returns a page of the tasks in scope with only the
+// requested fields, keyed by their JSON names, and the list around it.
+// Only the matching columns are read.
+func (s *PGTaskStore) ListTaskFields(ctx context.Context, scope TaskScope, fields []string, page Page) ([]map[string]any, PageInfo, error) {
+	if len(fields) == 0 {
+		return nil, PageInfo{}, fmt.Errorf("%w: no fields requested", ErrInvalidInput)
+	}
+	if scope.TeamID == uuid.Nil && scope.ReporterID == uuid.Nil && scope.AssigneeID == uuid.Nil {
+		return nil, PageInfo{}, fmt.Errorf("%w: task scope is empty", ErrInvalidInput)
+	}
+
+	var (
+		where []string
+		args  []any
+	)
+	param := func(v any) string {
+		args = append(args, v)
+		return "$" + strconv.Itoa(len(args))
+	}
+	where = append(where, "t.deleted_at IS NULL")
+	if scope.TeamID != uuid.Nil {
+		where = append(where, "t.team_id = "+param(scope.TeamID))
+	}
+	if scope.ReporterID != uuid.Nil {
+		where = append(where, "t.reporter_id = "+param(scope.ReporterID))
+	}
+	if scope.AssigneeID != uuid.Nil {
+		where = append(where, "t.assignee_id = "+param(scope.AssigneeID))
+	}
+	if scope.ViewerID != uuid.Nil {
+		where = append(where, visibleTo("t", param(scope.ViewerID)))
+	}
+	def := newestFirst
+	if scope.ByDueDate {
+		def = soonestDue
+	}
+	sort := scope.Sort.or(def)
+	b, err := buildList("t", args, scope.Filter, sort, page)
+	if err != nil {
+		return nil, PageInfo{}, err
+	}
+
+	// team_id is always read: sealed descriptions are bound to it. The
+	// sort column and id follow for the next cursor.
+	cols := make([]string, 0, len(fields)+3)
+	for _, f := range fields {
+		cols = append(cols, "t."+taskFields[f].column)
+	}
+	cols = append(cols, "t.team_id", "t."+sort.column(), "t.id")
+	selected := strings.Join(cols, ", ")
+	if page.After == nil {
+		selected += totalColumn
+	}
+
+	q := `
+		SELECT ` + selected + `
+		FROM tasks t
+		WHERE ` + strings.Join(where, " AND ") + b.where + `
+		ORDER BY ` + b.order
+
+	rows, err := s.pool.Query(ctx, q+b.limit, append(b.args, b.limitArgs...)...)
+	if err != nil {
+		return nil, PageInfo{}, fmt.Errorf("list task fields: %w", err)
+	}
+	defer rows.Close()
+
+	out := []map[string]any{}
+	var (
+		total int
+		keys  []any
+		ids   []uuid.UUID
+	)
+	for rows.Next() {
+		var (
+			teamID, id uuid.UUID
+			key        any
+		)
+		dests := make([]any, len(fields), len(fields)+4)
+		for i, f := range fields {
+			dests[i] = taskFields[f].newDest()
+		}
+		dests = append(dests, &teamID, &key, &id)
+		if page.After == nil {
+			dests = append(dests, &total)
+		}
+		if err := rows.Scan(dests...); err != nil {
+			return nil, PageInfo{}, fmt.Errorf("list task fields: scan: %w", err)
+		}
+
+		row := make(map[string]any, len(fields))
+		for i, f := range fields {
+			row[f] = dests[i]
+		}
+		if d, ok := row["description"].(**string); ok && *d != nil {
+			t := Task{TeamID: teamID, Description: *d}
+			if err := s.openDescription(&t); err != nil {
+				return nil, PageInfo{}, err
+			}
+			*d = t.Description
+		}
+		out = append(out, row)
+		keys = append(keys, key)
+		ids = append(ids, id)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, PageInfo{}, fmt.Errorf("list task fields: %w", err)
+	}
+
+	var info PageInfo
+	if page.After != nil {
+		if page.Limit > 0 && len(out) > page.Limit {
+			out = out[:page.Limit]
+			info.Next = cursorAt(sort, keys[page.Limit-1], ids[page.Limit-1])
+		}
+		return out, info, nil
+	}
+
+	if len(out) == 0 && page.Offset > 0 {
+		total, err = s.countAll(ctx, q, b.args...)
+		if err != nil {
+			return nil, PageInfo{}, fmt.Errorf("list task fields: %w", err)
+		}
+	}
+	info.Total = &total
+	if len(out) > 0 && page.Offset+len(out) < total {
+		info.Next = cursorAt(sort, keys[len(out)-1], ids[len(out)-1])
+	}
+	return out, info, nil
+}
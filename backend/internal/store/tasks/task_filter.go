@@ -0,0 +1,88 @@
+package store
+
+import (
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// TaskFilter narrows a task list. Zero fields match every task that is
+// not archived.
+type TaskFilter struct {
+	Statuses []TaskStatus
+	// DueAfter is inclusive and DueBefore exclusive, so two dates select
+	// the days from the first up to the second.
+	DueAfter  *time.Time
+	DueBefore *time.Time
+	// StartAfter and StartBefore work the same on start_at; tasks without
+	// a start date never match them
+	StartAfter  *time.Time
+	StartBefore *time.Time
+	// Labels are lowercased label names; a task must have all of them
+	Labels []string
+	// IncludeArchived lists archived tasks too; they are left out by default
+	IncludeArchived bool
+	// ProjectID selects the tasks of one project; NoProject those in none
+	ProjectID *uuid.UUID
+	NoProject bool
+}
+
+// clause returns the filter as AND conditions on the columns of alias
+// (none when empty) for a query that already uses n parameters, and the
+// arguments to append.
+func (f TaskFilter) clause(alias string, n int) (string, []any) {
+	col := func(c string) string { return column(alias, c) }
+
+	var (
+		b    strings.Builder
+		args []any
+	)
+	param := func(v any) string {
+		args = append(args, v)
+		return "$" + strconv.Itoa(n+len(args))
+	}
+	if !f.IncludeArchived {
+		b.WriteString(" AND " + col("archived_at") + " IS NULL")
+	}
+	if len(f.Statuses) > 0 {
+		statuses := make([]string, len(f.Statuses))
+		for i, s := range f.Statuses {
+			statuses[i] = string(s)
+		}
+		b.WriteString(" AND " + col("status") + " = ANY(" + param(statuses) + "::task_status[])")
+	}
+	switch {
+	case f.NoProject:
+		b.WriteString(" AND " + col("project_id") + " IS NULL")
+	case f.ProjectID != nil:
+		b.WriteString(" AND " + col("project_id") + " = " + param(*f.ProjectID))
+	}
+	if f.DueAfter != nil {
+		b.WriteString(" AND " + col("due_at") + " >= " + param(f.DueAfter.UTC()))
+	}
+	if f.DueBefore != nil {
+		b.WriteString(" AND " + col("due_at") + " < " + param(f.DueBefore.UTC()))
+	}
+	if f.StartAfter != nil {
+		b.WriteString(" AND " + col("start_at") + " >= " + param(f.StartAfter.UTC()))
+	}
+	if f.StartBefore != nil {
+		b.WriteString(" AND " + col("start_at") + " < " + param(f.StartBefore.UTC()))
+	}
+	if len(f.Labels) > 0 {
+		// qualified even without an alias, or id would resolve to labels.id
+		task := alias
+		if task == "" {
+			task = "tasks"
+		}
+		names := param(f.Labels)
+		b.WriteString(` AND (
+			SELECT count(*) FROM task_labels tl
+			JOIN labels l ON l.id = tl.label_id
+			WHERE tl.task_id = ` + task + `.id AND lower(l.name) = ANY(` + names + `::text[])
+		) = cardinality(` + names + `::text[])`)
+	}
+	return b.String(), args
+}
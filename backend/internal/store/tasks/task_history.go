@@ -0,0 +1,249 @@
+package store
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/diagnosis/interactive-todo/api/types"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+// Every mutation of a task writes a task_events row in its own
+// transaction, so the history cannot miss a change that committed or
+// record one that did not. The actor is nil for automations.
+
+type TaskEvent = types.TaskEvent
+type FieldChange = types.FieldChange
+
+// NOTE: order must match scanTaskEvent
+const taskEventColumns = `
+    id,
+    task_id,
+    team_id,
+    actor_id,
+    kind,
+    changes,
+    created_at
+`
+
+func scanTaskEvent(row pgx.Row) (*TaskEvent, error) {
+	var e TaskEvent
+	err := row.Scan(
+		&e.ID,
+		&e.TaskID,
+		&e.TeamID,
+		&e.ActorID,
+		&e.Kind,
+		&e.Changes,
+		&e.CreatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &e, nil
+}
+
+// historyFields are the fields the history tracks, under their JSON names.
+// Custom field values are tracked one by one as "custom_fields.<key>". The
+// description is compared separately; see FieldChange.
+func historyFields(t *Task) map[string]any {
+	f := map[string]any{
+		"title":          t.Title,
+		"assignee_id":    t.AssigneeID.String(),
+		"due_at":         t.DueAt.UTC().Format(time.RFC3339),
+		"status":         string(t.Status),
+		"priority":       string(t.Priority),
+		"private":        t.Private,
+		"estimate_hours": nil,
+		"workflow_state": nil,
+		"start_at":       nil,
+		"project_id":     nil,
+	}
+	if t.EstimateHours != nil {
+		f["estimate_hours"] = *t.EstimateHours
+	}
+	if t.WorkflowState != nil {
+		f["workflow_state"] = *t.WorkflowState
+	}
+	if t.StartAt != nil {
+		f["start_at"] = t.StartAt.UTC().Format(time.RFC3339)
+	}
+	if t.ProjectID != nil {
+		f["project_id"] = t.ProjectID.String()
+	}
+	for k, v := range t.CustomFields {
+		f["custom_fields."+k] = v
+	}
+	return f
+}
+
+// taskChanges compares two versions of a task; before nil is a created
+// task and after nil a deleted one.
+func taskChanges(before, after *Task) map[string]FieldChange {
+	changes := make(map[string]FieldChange)
+	switch {
+	case before == nil:
+		for k, v := range historyFields(after) {
+			changes[k] = FieldChange{To: v}
+		}
+		return changes
+	case after == nil:
+		for k, v := range historyFields(before) {
+			changes[k] = FieldChange{From: v}
+		}
+		return changes
+	}
+
+	to := historyFields(after)
+	from := historyFields(before)
+	for k, v := range from {
+		if v != to[k] {
+			changes[k] = FieldChange{From: v, To: to[k]}
+		}
+	}
+	// custom field values may be set only after
+	for k, v := range to {
+		if _, ok := from[k]; !ok {
+			changes[k] = FieldChange{To: v}
+		}
+	}
+	if !equalDescriptions(before.Description, after.Description) {
+		changes["description"] = FieldChange{}
+	}
+	return changes
+}
+
+func equalDescriptions(a, b *string) bool {
+	switch {
+	case a == nil || *a == "":
+		return b == nil || *b == ""
+	case b == nil:
+		return false
+	}
+	return *a == *b
+}
+
+// recordEvent writes an event for the change from before to after, unless
+// nothing tracked changed.
+func recordEvent(ctx context.Context, tx pgx.Tx, before, after *Task, kind types.TaskEventKind, actorID *uuid.UUID, now time.Time) error {
+	changes := taskChanges(before, after)
+	if len(changes) == 0 {
+		return nil
+	}
+	t := after
+	if t == nil {
+		t = before
+	}
+	return insertTaskEvent(ctx, tx, t.ID, t.TeamID, kind, changes, actorID, now)
+}
+
+func insertTaskEvent(ctx context.Context, tx pgx.Tx, taskID, teamID uuid.UUID, kind types.TaskEventKind, changes map[string]FieldChange, actorID *uuid.UUID, now time.Time) error {
+	const q = `
+		INSERT INTO task_events (task_id, team_id, actor_id, kind, changes, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6)
+	`
+	if _, err := tx.Exec(ctx, q, taskID, teamID, actorID, kind, changes, now.UTC()); err != nil {
+		return fmt.Errorf("record task event task_id=%s kind=%s: %w", taskID, kind, err)
+	}
+	return nil
+}
+
+// lockTask reads a task for a change in tx and keeps it locked until the
+// transaction ends.
+func (s *PGTaskStore) lockTask(ctx context.Context, tx pgx.Tx, taskID uuid.UUID) (*Task, error) {
+	q := `SELECT ` + taskColumns + ` FROM tasks WHERE id = $1 AND deleted_at IS NULL FOR UPDATE`
+	t, err := s.scanTaskRow(tx.QueryRow(ctx, q, taskID))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrTaskNotFound
+		}
+		return nil, fmt.Errorf("lock task id=%s: %w", taskID, err)
+	}
+	return t, nil
+}
+
+// checkVersion refuses a change made against another version of t; the
+// lock taken by lockTask keeps the version from moving until commit.
+func checkVersion(t *Task, ifVersion int64) error {
+	if ifVersion != 0 && t.Version != ifVersion {
+		return fmt.Errorf("%w: task is at version %d", ErrVersionConflict, t.Version)
+	}
+	return nil
+}
+
+func listViewersTx(ctx context.Context, tx pgx.Tx, taskID uuid.UUID) ([]string, error) {
+	rows, err := tx.Query(ctx, `SELECT user_id FROM task_viewers WHERE task_id = $1 ORDER BY user_id`, taskID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	ids := []string{}
+	for rows.Next() {
+		var id uuid.UUID
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		ids = append(ids, id.String())
+	}
+	return ids, rows.Err()
+}
+
+// ListEvents returns up to limit of the task's events, newest first. It
+// works for deleted tasks too.
+func (s *PGTaskStore) ListEvents(ctx context.Context, taskID uuid.UUID, limit int) ([]TaskEvent, error) {
+	q := `
+		SELECT ` + taskEventColumns + `
+		FROM task_events
+		WHERE task_id = $1
+		ORDER BY created_at DESC, id
+		LIMIT $2
+	`
+	rows, err := s.pool.Query(ctx, q, taskID, limit)
+	if err != nil {
+		return nil, fmt.Errorf("list task events task_id=%s: %w", taskID, err)
+	}
+	defer rows.Close()
+
+	out := []TaskEvent{}
+	for rows.Next() {
+		e, err := scanTaskEvent(rows)
+		if err != nil {
+			return nil, fmt.Errorf("scan task event: %w", err)
+		}
+		out = append(out, *e)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("list task events task_id=%s: %w", taskID, err)
+	}
+	return out, nil
+}
+
+// DeletedTask tells which team a deleted task was in and whether it was
+// private, from its deleted event. It returns ErrTaskNotFound when the
+// task has no such event, or was restored since.
+func (s *PGTaskStore) DeletedTask(ctx context.Context, taskID uuid.UUID) (uuid.UUID, bool, error) {
+	const q = `
+		SELECT team_id, private
+		FROM (
+			SELECT team_id, kind, COALESCE((changes->'private'->>'from')::boolean, false) AS private
+			FROM task_events
+			WHERE task_id = $1 AND kind IN ('deleted', 'restored')
+			ORDER BY created_at DESC
+			LIMIT 1
+		) e
+		WHERE kind = 'deleted'
+	`
+	var teamID uuid.UUID
+	var private bool
+	if err := s.pool.QueryRow(ctx, q, taskID).Scan(&teamID, &private); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return uuid.Nil, false, ErrTaskNotFound
+		}
+		return uuid.Nil, false, fmt.Errorf("deleted task id=%s: %w", taskID, err)
+	}
+	return teamID, private, nil
+}
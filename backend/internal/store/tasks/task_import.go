@@ -0,0 +1,164 @@
+package store
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/diagnosis/interactive-todo/api/types"
+	reminderstore "github.com/diagnosis/interactive-todo/internal/store/reminders"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+// importBatch is the number of rows sent per COPY.
+const importBatch = 1000
+
+// ImportedTask is one task of a bulk import, checked by the caller.
+type ImportedTask struct {
+	Title         string
+	Description   *string
+	AssigneeID    uuid.UUID
+	StartAt       *time.Time
+	DueAt         time.Time
+	Priority      TaskPriority
+	EstimateHours *float64
+	// CustomFields are checked against the team's fields; never nil
+	CustomFields map[string]any
+}
+
+// WorkflowStart is the initial state of a team's custom workflow, which
+// imported tasks start in.
+type WorkflowStart struct {
+	State    string
+	Category TaskStatus
+}
+
+// ImportTasks creates tasks in the team with COPY, all in one transaction:
+// either every task is created or none is. Each gets a created event in
+// its history under reporterID. The tasks are returned in input order.
+func (s *PGTaskStore) ImportTasks(
+	ctx context.Context,
+	teamID, reporterID uuid.UUID,
+	in []ImportedTask,
+	start *WorkflowStart,
+	now time.Time,
+) ([]Task, error) {
+	if len(in) == 0 {
+		return nil, nil
+	}
+	now = now.UTC()
+	for i, t := range in {
+		if err := validateTask(t.Title, reporterID, t.AssigneeID, t.DueAt, now); err != nil {
+			return nil, fmt.Errorf("import tasks row %d: %w", i+1, err)
+		}
+		if err := validateWindow(t.StartAt, t.DueAt); err != nil {
+			return nil, fmt.Errorf("import tasks row %d: %w", i+1, err)
+		}
+		if err := validateEstimate(t.EstimateHours); err != nil {
+			return nil, fmt.Errorf("import tasks row %d: %w", i+1, err)
+		}
+	}
+
+	// sealed up front: sealDescription would look the team up per row
+	confidential, err := s.isConfidential(ctx, teamID)
+	if err != nil {
+		return nil, err
+	}
+	if confidential && s.cipher == nil {
+		return nil, ErrEncryptionUnavailable
+	}
+	descriptions := make([]*string, len(in))
+	for i, t := range in {
+		descriptions[i] = t.Description
+		if confidential && t.Description != nil && *t.Description != "" {
+			sealed, err := s.cipher.Encrypt(*t.Description, teamID[:])
+			if err != nil {
+				return nil, fmt.Errorf("seal description team_id=%s: %w", teamID, err)
+			}
+			descriptions[i] = &sealed
+		}
+	}
+
+	ids := make([]uuid.UUID, len(in))
+	for i := range ids {
+		ids[i] = uuid.New()
+	}
+
+	tx, err := s.pool.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("import tasks: begin: %w", err)
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	columns := []string{
+		"id", "team_id", "title", "description", "reporter_id", "assignee_id", "start_at", "due_at",
+		"priority", "estimate_hours", "custom_fields", "assigned_at", "acknowledged_at", "created_at", "updated_at",
+	}
+	for from := 0; from < len(in); from += importBatch {
+		to := min(from+importBatch, len(in))
+		_, err := tx.CopyFrom(ctx, pgx.Identifier{"tasks"}, columns, pgx.CopyFromSlice(to-from, func(i int) ([]any, error) {
+			t := in[from+i]
+			// self-assigned tasks need no acknowledgement
+			var ackAt *time.Time
+			if t.AssigneeID == reporterID {
+				ackAt = &now
+			}
+			return []any{
+				ids[from+i], teamID, t.Title, descriptions[from+i], reporterID, t.AssigneeID,
+				utcOrNil(t.StartAt), t.DueAt.UTC(), string(t.Priority), t.EstimateHours, t.CustomFields, now, ackAt, now, now,
+			}, nil
+		}))
+		if err != nil {
+			return nil, fmt.Errorf("import tasks team_id=%s: copy: %w", teamID, err)
+		}
+	}
+
+	if start != nil {
+		const q = `
+			UPDATE tasks
+			SET workflow_state = $2,
+			    status         = $3
+			WHERE id = ANY($1)
+		`
+		if _, err := tx.Exec(ctx, q, ids, start.State, start.Category); err != nil {
+			return nil, fmt.Errorf("import tasks team_id=%s: set workflow state: %w", teamID, err)
+		}
+	}
+
+	if err := reminderstore.AddDefaults(ctx, tx, ids, now); err != nil {
+		return nil, fmt.Errorf("import tasks team_id=%s: %w", teamID, err)
+	}
+
+	// read back for what the triggers filled in, such as position
+	rows, err := tx.Query(ctx, `SELECT `+taskColumns+` FROM tasks WHERE id = ANY($1)`, ids)
+	if err != nil {
+		return nil, fmt.Errorf("import tasks team_id=%s: read back: %w", teamID, err)
+	}
+	created, err := s.scanTask(rows)
+	rows.Close()
+	if err != nil {
+		return nil, fmt.Errorf("import tasks team_id=%s: read back: %w", teamID, err)
+	}
+	byID := make(map[uuid.UUID]Task, len(created))
+	for _, t := range created {
+		byID[t.ID] = t
+	}
+	out := make([]Task, len(ids))
+	for i, id := range ids {
+		out[i] = byID[id]
+	}
+
+	eventColumns := []string{"task_id", "team_id", "actor_id", "kind", "changes", "created_at"}
+	_, err = tx.CopyFrom(ctx, pgx.Identifier{"task_events"}, eventColumns, pgx.CopyFromSlice(len(out), func(i int) ([]any, error) {
+		return []any{out[i].ID, teamID, reporterID, string(types.TaskEventCreated), taskChanges(nil, &out[i]), now}, nil
+	}))
+	if err != nil {
+		return nil, fmt.Errorf("import tasks team_id=%s: record events: %w", teamID, err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("import tasks: commit: %w", err)
+	}
+	return out, nil
+}
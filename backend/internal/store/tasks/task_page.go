@@ -0,0 +1,253 @@
+package store
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/diagnosis/interactive-todo/api/types"
+	"github.com/google/uuid"
+)
+
+var ErrInvalidCursor = errors.New("invalid cursor")
+
+// Page selects a slice of a task list. A zero Limit returns every task from
+// Offset on. With After set the page starts behind that cursor instead of
+// at Offset, which stays fast however deep the list goes.
+type Page struct {
+	Limit  int
+	Offset int
+	After  *Cursor
+}
+
+// PageInfo describes the list around a page. Total is nil for pages read
+// after a cursor: counting would scan the whole list. Next continues the
+// list and is empty on the last page.
+type PageInfo struct {
+	Total *int
+	Next  string
+}
+
+// Cursor is the sort key and id of the last task on a page. It is handed
+// to clients as an opaque string and only valid for the same sort.
+type Cursor struct {
+	Field SortField `json:"f"`
+	Desc  bool      `json:"d"`
+	Value string    `json:"v"`
+	ID    uuid.UUID `json:"id"`
+}
+
+// ParseCursor decodes a cursor returned as PageInfo.Next.
+func ParseCursor(s string) (*Cursor, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+	var c Cursor
+	if err := json.Unmarshal(raw, &c); err != nil || c.ID == uuid.Nil {
+		return nil, ErrInvalidCursor
+	}
+	switch c.Field {
+	case SortDueAt, SortCreatedAt:
+		if _, err := time.Parse(time.RFC3339Nano, c.Value); err != nil {
+			return nil, ErrInvalidCursor
+		}
+	case SortPriority:
+		switch TaskPriority(c.Value) {
+		case types.TaskPriorityLow, types.TaskPriorityNormal, types.TaskPriorityHigh, types.TaskPriorityUrgent:
+		default:
+			return nil, ErrInvalidCursor
+		}
+	case SortPosition:
+		if _, err := strconv.ParseFloat(c.Value, 64); err != nil {
+			return nil, ErrInvalidCursor
+		}
+	case SortVotes:
+		if _, err := strconv.Atoi(c.Value); err != nil {
+			return nil, ErrInvalidCursor
+		}
+	default:
+		return nil, ErrInvalidCursor
+	}
+	return &c, nil
+}
+
+func (c Cursor) encode() string {
+	raw, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(raw)
+}
+
+// cursorAt returns the cursor behind a row whose sort column holds key.
+func cursorAt(sort SortSpec, key any, id uuid.UUID) string {
+	c := Cursor{Field: sort.Field, Desc: sort.Desc, ID: id}
+	switch v := key.(type) {
+	case time.Time:
+		c.Value = v.UTC().Format(time.RFC3339Nano)
+	case string:
+		c.Value = v
+	case TaskPriority:
+		c.Value = string(v)
+	case float64:
+		c.Value = strconv.FormatFloat(v, 'g', -1, 64)
+	case int:
+		c.Value = strconv.Itoa(v)
+	case int32:
+		c.Value = strconv.FormatInt(int64(v), 10)
+	}
+	return c.encode()
+}
+
+func taskSortKey(t *Task, field SortField) any {
+	switch field {
+	case SortDueAt:
+		return t.DueAt
+	case SortPriority:
+		return t.Priority
+	case SortPosition:
+		return t.Position
+	case SortVotes:
+		return t.VoteCount
+	default:
+		return t.CreatedAt
+	}
+}
+
+// clause returns LIMIT/OFFSET for a query that already uses n parameters,
+// and the arguments to append.
+func (p Page) clause(n int) (string, []any) {
+	if p.Limit <= 0 {
+		if p.Offset <= 0 {
+			return "", nil
+		}
+		return " OFFSET $" + strconv.Itoa(n+1), []any{p.Offset}
+	}
+	return " LIMIT $" + strconv.Itoa(n+1) + " OFFSET $" + strconv.Itoa(n+2), []any{p.Limit, p.Offset}
+}
+
+// totalColumn is selected after the task columns by offset pages, so the
+// size of the whole list comes back with the page.
+const totalColumn = `, count(*) OVER () AS total`
+
+// listSQL is the part of a paged task list query after the scope: the
+// filter and cursor conditions, ORDER BY and LIMIT, with their arguments.
+type listSQL struct {
+	where     string
+	args      []any
+	order     string
+	limit     string
+	limitArgs []any
+}
+
+// buildList completes a list whose scope conditions use args. sort must
+// already be resolved. A cursor page reads one extra row to tell whether
+// another page follows.
+func buildList(alias string, args []any, filter TaskFilter, sort SortSpec, page Page) (listSQL, error) {
+	where, filterArgs := filter.clause(alias, len(args))
+	args = append(args, filterArgs...)
+
+	limit := page
+	if page.After != nil {
+		if page.After.Field != sort.Field || page.After.Desc != sort.Desc {
+			return listSQL{}, fmt.Errorf("%w: cursor is for a different sort", ErrInvalidCursor)
+		}
+		after, afterArgs := sort.after(alias, *page.After, len(args))
+		where += after
+		args = append(args, afterArgs...)
+		limit = Page{}
+		if page.Limit > 0 {
+			limit.Limit = page.Limit + 1
+		}
+	}
+
+	l := listSQL{where: where, args: args, order: sort.orderBy(alias)}
+	l.limit, l.limitArgs = limit.clause(len(args))
+	return l, nil
+}
+
+// taskList is a paged list of the tasks matching where, conditions on
+// tasks using args, in def order unless the caller sorts it.
+type taskList struct {
+	op    string
+	where string
+	args  []any
+	def   SortSpec
+}
+
+// listPage reads one page of l with the list around it.
+func (s *PGTaskStore) listPage(ctx context.Context, l taskList, filter TaskFilter, sort SortSpec, page Page) ([]Task, PageInfo, error) {
+	sort = sort.or(l.def)
+	b, err := buildList("", l.args, filter, sort, page)
+	if err != nil {
+		return nil, PageInfo{}, err
+	}
+
+	cols := taskColumns
+	if page.After == nil {
+		cols += totalColumn
+	}
+	q := `
+		SELECT ` + cols + `
+		FROM tasks
+		WHERE deleted_at IS NULL AND ` + l.where + b.where + `
+		ORDER BY ` + b.order
+
+	rows, err := s.pool.Query(ctx, q+b.limit, append(b.args, b.limitArgs...)...)
+	if err != nil {
+		return nil, PageInfo{}, fmt.Errorf("%s: %w", l.op, err)
+	}
+	defer rows.Close()
+
+	tasks := []Task{}
+	var total int
+	for rows.Next() {
+		var extra []any
+		if page.After == nil {
+			extra = append(extra, &total)
+		}
+		t, err := s.scanTaskRow(rows, extra...)
+		if err != nil {
+			return nil, PageInfo{}, fmt.Errorf("%s: scan: %w", l.op, err)
+		}
+		tasks = append(tasks, *t)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, PageInfo{}, fmt.Errorf("%s: %w", l.op, err)
+	}
+
+	var info PageInfo
+	if page.After != nil {
+		if page.Limit > 0 && len(tasks) > page.Limit {
+			tasks = tasks[:page.Limit]
+			last := &tasks[len(tasks)-1]
+			info.Next = cursorAt(sort, taskSortKey(last, sort.Field), last.ID)
+		}
+		return tasks, info, nil
+	}
+
+	if len(tasks) == 0 && page.Offset > 0 {
+		total, err = s.countAll(ctx, q, b.args...)
+		if err != nil {
+			return nil, PageInfo{}, fmt.Errorf("%s: %w", l.op, err)
+		}
+	}
+	info.Total = &total
+	if len(tasks) > 0 && page.Offset+len(tasks) < total {
+		last := &tasks[len(tasks)-1]
+		info.Next = cursorAt(sort, taskSortKey(last, sort.Field), last.ID)
+	}
+	return tasks, info, nil
+}
+
+// countAll counts the rows of q. A page past the end carries no window
+// count, so it is asked for separately.
+func (s *PGTaskStore) countAll(ctx context.Context, q string, args ...any) (int, error) {
+	var n int
+	if err := s.pool.QueryRow(ctx, `SELECT count(*) FROM (`+q+`) AS list`, args...).Scan(&n); err != nil {
+		return 0, fmt.Errorf("count: %w", err)
+	}
+	return n, nil
+}
@@ -0,0 +1,264 @@
+package store
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+// Board order: position sorts the cards of a column (one team, one
+// status). A moved card gets the midpoint of its new neighbours, so most
+// moves write one row; when two neighbours get closer than minPositionGap
+// the column is renumbered PositionStep apart. Moves and renumbering in a
+// column hold a transaction advisory lock on it, so concurrent drags see
+// each other's result instead of picking the same midpoint.
+
+const (
+	// PositionStep is the gap between cards after a renumber, and how far
+	// past the first or last card a card dropped at an end goes.
+	PositionStep = 1024
+
+	// minPositionGap is the closest two neighbours may get before the
+	// column is renumbered; float64 halving runs out well below it.
+	minPositionGap = 1e-6
+)
+
+// MoveTask drops a task between two cards of its status column: after
+// afterID and before beforeID. Either may be nil at the ends of the
+// column, but not both. Moving a card is not an edit: updated_at and the
+// version stay as they are.
+func (s *PGTaskStore) MoveTask(ctx context.Context, id uuid.UUID, afterID, beforeID *uuid.UUID) (*Task, error) {
+	if afterID == nil && beforeID == nil {
+		return nil, fmt.Errorf("%w: after_id or before_id is required", ErrInvalidInput)
+	}
+	if (afterID != nil && *afterID == id) || (beforeID != nil && *beforeID == id) {
+		return nil, fmt.Errorf("%w: a task cannot be moved next to itself", ErrInvalidInput)
+	}
+
+	tx, err := s.pool.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("move task: begin: %w", err)
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	// the column lock comes before the row lock, the same order the
+	// rebalance job takes them in
+	var teamID uuid.UUID
+	var status TaskStatus
+	err = tx.QueryRow(ctx, `SELECT team_id, status FROM tasks WHERE id = $1 AND deleted_at IS NULL`, id).Scan(&teamID, &status)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrTaskNotFound
+		}
+		return nil, fmt.Errorf("move task id=%s: %w", id, err)
+	}
+	if err := lockColumn(ctx, tx, teamID, status); err != nil {
+		return nil, err
+	}
+	t, err := s.lockTask(ctx, tx, id)
+	if err != nil {
+		return nil, err
+	}
+	if t.TeamID != teamID || t.Status != status {
+		return nil, fmt.Errorf("%w: task changed status while being moved", ErrVersionConflict)
+	}
+
+	pos, err := slotBetween(ctx, tx, t, afterID, beforeID)
+	if err != nil {
+		return nil, err
+	}
+	if pos.crowded() {
+		if _, err := renumberColumn(ctx, tx, teamID, status); err != nil {
+			return nil, err
+		}
+		if pos, err = slotBetween(ctx, tx, t, afterID, beforeID); err != nil {
+			return nil, err
+		}
+	}
+
+	q := `UPDATE tasks SET position = $2 WHERE id = $1 ` + taskReturning
+	moved, err := s.scanTaskRow(tx.QueryRow(ctx, q, id, pos.mid()))
+	if err != nil {
+		return nil, fmt.Errorf("move task id=%s: %w", id, err)
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("move task: commit: %w", err)
+	}
+	return moved, nil
+}
+
+// RebalancePositions renumbers up to limit columns whose cards got closer
+// than minPositionGap, and reports how many it renumbered. MoveTask does
+// the same for the column it writes to; this catches columns crowded by
+// other writers, such as tasks created at the same moment.
+func (s *PGTaskStore) RebalancePositions(ctx context.Context, limit int) (int, error) {
+	const q = `
+		SELECT team_id, status
+		FROM (
+			SELECT team_id, status,
+			       position - lag(position) OVER (PARTITION BY team_id, status ORDER BY position) AS gap
+			FROM tasks
+		) g
+		WHERE gap < $1
+		GROUP BY team_id, status
+		LIMIT $2`
+
+	rows, err := s.pool.Query(ctx, q, minPositionGap, limit)
+	if err != nil {
+		return 0, fmt.Errorf("find crowded columns: %w", err)
+	}
+	type column struct {
+		teamID uuid.UUID
+		status TaskStatus
+	}
+	var cols []column
+	for rows.Next() {
+		var c column
+		if err := rows.Scan(&c.teamID, &c.status); err != nil {
+			rows.Close()
+			return 0, fmt.Errorf("scan crowded column: %w", err)
+		}
+		cols = append(cols, c)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return 0, fmt.Errorf("find crowded columns: %w", err)
+	}
+
+	done := 0
+	for _, c := range cols {
+		if err := s.rebalanceColumn(ctx, c.teamID, c.status); err != nil {
+			return done, err
+		}
+		done++
+	}
+	return done, nil
+}
+
+func (s *PGTaskStore) rebalanceColumn(ctx context.Context, teamID uuid.UUID, status TaskStatus) error {
+	tx, err := s.pool.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("rebalance column: begin: %w", err)
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	if err := lockColumn(ctx, tx, teamID, status); err != nil {
+		return err
+	}
+	if _, err := renumberColumn(ctx, tx, teamID, status); err != nil {
+		return err
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("rebalance column: commit: %w", err)
+	}
+	return nil
+}
+
+// lockColumn holds the column's advisory lock until tx ends.
+func lockColumn(ctx context.Context, tx pgx.Tx, teamID uuid.UUID, status TaskStatus) error {
+	_, err := tx.Exec(ctx, `SELECT pg_advisory_xact_lock(hashtextextended($1::text || '/' || $2::text, 0))`, teamID, status)
+	if err != nil {
+		return fmt.Errorf("lock column team_id=%s status=%s: %w", teamID, status, err)
+	}
+	return nil
+}
+
+// renumberColumn spaces the column's cards PositionStep apart, keeping
+// their order. Deleted tasks keep their place too, for a restore.
+func renumberColumn(ctx context.Context, tx pgx.Tx, teamID uuid.UUID, status TaskStatus) (int64, error) {
+	const q = `
+		UPDATE tasks t
+		SET position = r.rn * $3
+		FROM (
+			SELECT id, row_number() OVER (ORDER BY position, created_at, id) AS rn
+			FROM tasks
+			WHERE team_id = $1 AND status = $2
+		) r
+		WHERE t.id = r.id
+		  AND t.position IS DISTINCT FROM r.rn * $3`
+
+	tag, err := tx.Exec(ctx, q, teamID, status, float64(PositionStep))
+	if err != nil {
+		return 0, fmt.Errorf("renumber column team_id=%s status=%s: %w", teamID, status, err)
+	}
+	return tag.RowsAffected(), nil
+}
+
+// slot is the gap a card is dropped into.
+type slot struct {
+	lo, hi float64
+}
+
+func (p slot) mid() float64  { return p.lo + (p.hi-p.lo)/2 }
+func (p slot) crowded() bool { return p.hi-p.lo < minPositionGap }
+
+// slotBetween finds the gap between the requested neighbours of t. A
+// missing neighbour is taken from the column: the card next to the other
+// one, or a step past it at the ends. Neighbours sent out of order, from a
+// board that was stale, fall back to "right after afterID".
+func slotBetween(ctx context.Context, tx pgx.Tx, t *Task, afterID, beforeID *uuid.UUID) (slot, error) {
+	var after, before *float64
+	var err error
+	if afterID != nil {
+		if after, err = neighbourPosition(ctx, tx, t, *afterID); err != nil {
+			return slot{}, err
+		}
+	}
+	if beforeID != nil {
+		if before, err = neighbourPosition(ctx, tx, t, *beforeID); err != nil {
+			return slot{}, err
+		}
+	}
+	if after != nil && before != nil && *after >= *before {
+		before = nil
+	}
+
+	const next = `
+		SELECT min(position) FROM tasks
+		WHERE team_id = $1 AND status = $2 AND deleted_at IS NULL AND id <> $3 AND position > $4`
+	const prev = `
+		SELECT max(position) FROM tasks
+		WHERE team_id = $1 AND status = $2 AND deleted_at IS NULL AND id <> $3 AND position < $4`
+
+	switch {
+	case after != nil && before != nil:
+		return slot{lo: *after, hi: *before}, nil
+	case after != nil:
+		var hi *float64
+		if err := tx.QueryRow(ctx, next, t.TeamID, t.Status, t.ID, *after).Scan(&hi); err != nil {
+			return slot{}, fmt.Errorf("move task id=%s: find next card: %w", t.ID, err)
+		}
+		if hi == nil {
+			return slot{lo: *after, hi: *after + 2*PositionStep}, nil
+		}
+		return slot{lo: *after, hi: *hi}, nil
+	default:
+		var lo *float64
+		if err := tx.QueryRow(ctx, prev, t.TeamID, t.Status, t.ID, *before).Scan(&lo); err != nil {
+			return slot{}, fmt.Errorf("move task id=%s: find previous card: %w", t.ID, err)
+		}
+		if lo == nil {
+			return slot{lo: *before - 2*PositionStep, hi: *before}, nil
+		}
+		return slot{lo: *lo, hi: *before}, nil
+	}
+}
+
+// neighbourPosition reads the position of a card in t's column.
+func neighbourPosition(ctx context.Context, tx pgx.Tx, t *Task, id uuid.UUID) (*float64, error) {
+	const q = `
+		SELECT position FROM tasks
+		WHERE id = $1 AND team_id = $2 AND status = $3 AND deleted_at IS NULL`
+
+	var pos float64
+	if err := tx.QueryRow(ctx, q, id, t.TeamID, t.Status).Scan(&pos); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, fmt.Errorf("%w: task %s is not in the same column", ErrInvalidInput, id)
+		}
+		return nil, fmt.Errorf("move task id=%s: read neighbour %s: %w", t.ID, id, err)
+	}
+	return &pos, nil
+}
@@ -0,0 +1,52 @@
+package store
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/diagnosis/interactive-todo/api/types"
+	"github.com/google/uuid"
+)
+
+type TaskSearchResult = types.TaskSearchResult
+
+// SearchInTeam returns the team's tasks matching the words of query that
+// viewerID may see, most relevant first. query takes web search syntax:
+// "quoted phrases", -excluded words and OR. Title matches outrank
+// description matches; descriptions of confidential teams are not
+// searched.
+func (s *PGTaskStore) SearchInTeam(ctx context.Context, teamID, viewerID uuid.UUID, query string, limit int) ([]TaskSearchResult, error) {
+	if teamID == uuid.Nil {
+		return nil, fmt.Errorf("%w: team_id cannot be nil", ErrInvalidInput)
+	}
+
+	q := `
+		SELECT ` + taskColumns + `, ts_rank_cd(tasks.search_vector, query) AS rank
+		FROM tasks, websearch_to_tsquery('english', $3) AS query
+		WHERE tasks.team_id = $1
+		  AND tasks.deleted_at IS NULL
+		  AND tasks.search_vector @@ query
+		  AND ` + visibleTo("tasks", "$2") + `
+		ORDER BY rank DESC, tasks.created_at DESC, tasks.id
+		LIMIT $4
+	`
+	rows, err := s.pool.Query(ctx, q, teamID, viewerID, query, limit)
+	if err != nil {
+		return nil, fmt.Errorf("search tasks team_id=%s: %w", teamID, err)
+	}
+	defer rows.Close()
+
+	out := []TaskSearchResult{}
+	for rows.Next() {
+		var rank float64
+		t, err := s.scanTaskRow(rows, &rank)
+		if err != nil {
+			return nil, fmt.Errorf("scan task search result: %w", err)
+		}
+		out = append(out, TaskSearchResult{Task: *t, Rank: rank})
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("search tasks team_id=%s: %w", teamID, err)
+	}
+	return out, nil
+}
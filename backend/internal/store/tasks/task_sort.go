@@ -0,0 +1,126 @@
+package store
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+)
+
+var ErrInvalidSort = errors.New("invalid sort")
+
+type SortField string
+
+const (
+	SortDueAt     SortField = "due_at"
+	SortCreatedAt SortField = "created_at"
+	SortPriority  SortField = "priority"
+	SortPosition  SortField = "position"
+	SortVotes     SortField = "votes"
+)
+
+// SortSpec orders a task list. The zero value keeps the list's own order.
+type SortSpec struct {
+	Field SortField
+	Desc  bool
+}
+
+// The default orders of the task lists.
+var (
+	newestFirst = SortSpec{Field: SortCreatedAt, Desc: true}
+	soonestDue  = SortSpec{Field: SortDueAt}
+)
+
+// ParseSortSpec reads ?sort= and ?order= (asc or desc). Without order,
+// due dates sort soonest first, positions in board order and the others
+// newest, most urgent or most voted first.
+func ParseSortSpec(field, order string) (SortSpec, error) {
+	if field == "" {
+		if order != "" {
+			return SortSpec{}, fmt.Errorf("%w: order needs sort", ErrInvalidSort)
+		}
+		return SortSpec{}, nil
+	}
+
+	s := SortSpec{Field: SortField(field)}
+	switch s.Field {
+	case SortDueAt, SortPosition:
+	case SortCreatedAt, SortPriority, SortVotes:
+		s.Desc = true
+	default:
+		return SortSpec{}, fmt.Errorf("%w %q: use due_at, created_at, priority, position or votes", ErrInvalidSort, field)
+	}
+	switch order {
+	case "":
+	case "asc":
+		s.Desc = false
+	case "desc":
+		s.Desc = true
+	default:
+		return SortSpec{}, fmt.Errorf("%w: order must be asc or desc", ErrInvalidSort)
+	}
+	return s, nil
+}
+
+// or returns def for the zero spec.
+func (s SortSpec) or(def SortSpec) SortSpec {
+	if s.Field == "" {
+		return def
+	}
+	return s
+}
+
+// priorityRank ranks the text priority from low to urgent.
+const priorityRank = "array_position(ARRAY['low', 'normal', 'high', 'urgent'], %s)"
+
+func (s SortSpec) expr(alias string) string {
+	if s.Field == SortPriority {
+		return fmt.Sprintf(priorityRank, column(alias, "priority"))
+	}
+	return column(alias, s.column())
+}
+
+// column is the task column the list is sorted on.
+func (s SortSpec) column() string {
+	if s.Field == SortVotes {
+		return "vote_count"
+	}
+	return string(s.Field)
+}
+
+// orderBy returns the ORDER BY list for the columns of alias (none when
+// empty). id breaks ties so pages do not overlap.
+func (s SortSpec) orderBy(alias string) string {
+	dir := " ASC"
+	if s.Desc {
+		dir = " DESC"
+	}
+	return s.expr(alias) + dir + ", " + column(alias, "id") + dir
+}
+
+// after returns the condition for the rows behind c in this order, for a
+// query that already uses n parameters, and the arguments to append.
+func (s SortSpec) after(alias string, c Cursor, n int) (string, []any) {
+	op := " > "
+	if s.Desc {
+		op = " < "
+	}
+	value := "$" + strconv.Itoa(n+1) + "::timestamptz"
+	switch s.Field {
+	case SortPriority:
+		value = fmt.Sprintf(priorityRank, "$"+strconv.Itoa(n+1)+"::text")
+	case SortPosition:
+		value = "$" + strconv.Itoa(n+1) + "::float8"
+	case SortVotes:
+		value = "$" + strconv.Itoa(n+1) + "::int"
+	}
+	return " AND (" + s.expr(alias) + ", " + column(alias, "id") + ")" + op +
+		"(" + value + ", $" + strconv.Itoa(n+2) + "::uuid)", []any{c.Value, c.ID}
+}
+
+// column qualifies a column with alias, if any.
+func column(alias, name string) string {
+	if alias == "" {
+		return name
+	}
+	return alias + "." + name
+}
@@ -0,0 +1,314 @@
+package store
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/diagnosis/interactive-todo/api/types"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+// Edit suggestions let members who are not the reporter propose a new
+// title or description. Each keeps the values it was made against, so an
+// accept is refused once the reporter changed the same field; a change to
+// another field does not get in the way. Suggested descriptions are
+// sealed like the task's own in confidential teams.
+
+type (
+	EditSuggestion       = types.EditSuggestion
+	EditSuggestionStatus = types.EditSuggestionStatus
+)
+
+var (
+	ErrSuggestionNotFound = errors.New("edit suggestion not found")
+	// ErrSuggestionPending: the author already has an open suggestion on
+	// the task.
+	ErrSuggestionPending = errors.New("an edit suggestion is already pending")
+	// ErrSuggestionResolved: the suggestion was already accepted or
+	// rejected.
+	ErrSuggestionResolved = errors.New("edit suggestion was already resolved")
+)
+
+// NOTE: order must match scanSuggestion
+const suggestionColumns = `
+    id,
+    task_id,
+    team_id,
+    author_id,
+    title,
+    description,
+    status,
+    resolved_by,
+    resolved_at,
+    created_at
+`
+
+// SuggestEdit proposes a new title and/or description for a task. Fields
+// that match the task as it is are dropped; a suggestion that changes
+// nothing is refused.
+func (s *PGTaskStore) SuggestEdit(
+	ctx context.Context,
+	taskID, authorID uuid.UUID,
+	title, description *string,
+	now time.Time,
+) (*EditSuggestion, error) {
+	if err := validateTaskUpdate(TaskUpdate{Title: title}, now); err != nil {
+		return nil, err
+	}
+
+	const cur = `SELECT team_id, title, description FROM tasks WHERE id = $1 AND deleted_at IS NULL`
+	var teamID uuid.UUID
+	var baseTitle string
+	var baseDescription *string
+	if err := s.pool.QueryRow(ctx, cur, taskID).Scan(&teamID, &baseTitle, &baseDescription); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrTaskNotFound
+		}
+		return nil, fmt.Errorf("suggest edit task_id=%s: %w", taskID, err)
+	}
+	plainBase, err := s.openSealed(teamID, baseDescription)
+	if err != nil {
+		return nil, fmt.Errorf("suggest edit task_id=%s: %w", taskID, err)
+	}
+
+	if title != nil {
+		t := strings.TrimSpace(*title)
+		title = &t
+		if t == baseTitle {
+			title = nil
+		}
+	}
+	if description != nil && *description == deref(plainBase) {
+		description = nil
+	}
+	if title == nil && description == nil {
+		return nil, fmt.Errorf("%w: the suggestion does not change the task", ErrInvalidInput)
+	}
+
+	sealed, err := s.sealDescription(ctx, teamID, description)
+	if err != nil {
+		return nil, err
+	}
+
+	q := `
+		INSERT INTO task_edit_suggestions (task_id, author_id, title, description, base_title, base_description, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		RETURNING ` + suggestionColumns
+
+	sg, err := s.scanSuggestion(s.pool.QueryRow(ctx, q, taskID, authorID, title, sealed, baseTitle, baseDescription, now.UTC()))
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == "23505" {
+			return nil, ErrSuggestionPending
+		}
+		return nil, fmt.Errorf("suggest edit task_id=%s: %w", taskID, err)
+	}
+	return sg, nil
+}
+
+// ListEditSuggestions returns a task's suggestions, newest first.
+func (s *PGTaskStore) ListEditSuggestions(ctx context.Context, taskID uuid.UUID) ([]EditSuggestion, error) {
+	q := `
+		SELECT ` + suggestionColumns + `
+		FROM task_edit_suggestions
+		WHERE task_id = $1
+		ORDER BY created_at DESC, id
+		LIMIT 200`
+
+	rows, err := s.pool.Query(ctx, q, taskID)
+	if err != nil {
+		return nil, fmt.Errorf("list edit suggestions task_id=%s: %w", taskID, err)
+	}
+	defer rows.Close()
+
+	out := []EditSuggestion{}
+	for rows.Next() {
+		sg, err := s.scanSuggestion(rows)
+		if err != nil {
+			return nil, fmt.Errorf("scan edit suggestion: %w", err)
+		}
+		out = append(out, *sg)
+	}
+	return out, rows.Err()
+}
+
+// AcceptEditSuggestion applies a pending suggestion to the task as an
+// update by actorID, and marks it accepted.
+func (s *PGTaskStore) AcceptEditSuggestion(
+	ctx context.Context,
+	taskID, suggestionID, actorID uuid.UUID,
+	now time.Time,
+) (*EditSuggestion, *Task, error) {
+	tx, err := s.pool.Begin(ctx)
+	if err != nil {
+		return nil, nil, fmt.Errorf("accept edit suggestion: begin: %w", err)
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	q := `
+		SELECT ` + suggestionColumns + `, base_title, base_description
+		FROM task_edit_suggestions
+		WHERE id = $1 AND task_id = $2
+		FOR UPDATE`
+
+	var baseTitle string
+	var baseDescription *string
+	sg, err := s.scanSuggestion(tx.QueryRow(ctx, q, suggestionID, taskID), &baseTitle, &baseDescription)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, nil, ErrSuggestionNotFound
+		}
+		return nil, nil, fmt.Errorf("accept edit suggestion id=%s: %w", suggestionID, err)
+	}
+	if sg.Status != types.EditSuggestionPending {
+		return nil, nil, ErrSuggestionResolved
+	}
+	if baseDescription, err = s.openSealed(sg.TeamID, baseDescription); err != nil {
+		return nil, nil, fmt.Errorf("accept edit suggestion id=%s: %w", suggestionID, err)
+	}
+
+	existing, err := s.lockTask(ctx, tx, taskID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if sg.Title != nil && existing.Title != baseTitle {
+		return nil, nil, fmt.Errorf("%w: the title was changed since the suggestion was made", ErrVersionConflict)
+	}
+	if sg.Description != nil && deref(existing.Description) != deref(baseDescription) {
+		return nil, nil, fmt.Errorf("%w: the description was changed since the suggestion was made", ErrVersionConflict)
+	}
+	before := *existing
+
+	if sg.Title != nil {
+		existing.Title = *sg.Title
+	}
+	if sg.Description != nil {
+		existing.Description = sg.Description
+	}
+	description, err := s.sealDescription(ctx, existing.TeamID, existing.Description)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	update := `
+		UPDATE tasks
+		SET title       = $2,
+		    description = $3,
+		    updated_at  = $4
+		WHERE id = $1
+		` + taskReturning
+
+	t, err := s.scanTaskRow(tx.QueryRow(ctx, update, taskID, existing.Title, description, now.UTC()))
+	if err != nil {
+		return nil, nil, fmt.Errorf("accept edit suggestion id=%s: update task: %w", suggestionID, err)
+	}
+	if err := recordEvent(ctx, tx, &before, t, types.TaskEventUpdated, &actorID, now); err != nil {
+		return nil, nil, err
+	}
+
+	if sg, err = s.resolveSuggestion(ctx, tx, suggestionID, types.EditSuggestionAccepted, actorID, now); err != nil {
+		return nil, nil, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, nil, fmt.Errorf("accept edit suggestion: commit: %w", err)
+	}
+	return sg, t, nil
+}
+
+// RejectEditSuggestion marks a pending suggestion rejected; the task is
+// left as it is.
+func (s *PGTaskStore) RejectEditSuggestion(
+	ctx context.Context,
+	taskID, suggestionID, actorID uuid.UUID,
+	now time.Time,
+) (*EditSuggestion, error) {
+	tx, err := s.pool.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("reject edit suggestion: begin: %w", err)
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	var status EditSuggestionStatus
+	err = tx.QueryRow(ctx,
+		`SELECT status FROM task_edit_suggestions WHERE id = $1 AND task_id = $2 FOR UPDATE`,
+		suggestionID, taskID,
+	).Scan(&status)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrSuggestionNotFound
+		}
+		return nil, fmt.Errorf("reject edit suggestion id=%s: %w", suggestionID, err)
+	}
+	if status != types.EditSuggestionPending {
+		return nil, ErrSuggestionResolved
+	}
+
+	sg, err := s.resolveSuggestion(ctx, tx, suggestionID, types.EditSuggestionRejected, actorID, now)
+	if err != nil {
+		return nil, err
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("reject edit suggestion: commit: %w", err)
+	}
+	return sg, nil
+}
+
+func (s *PGTaskStore) resolveSuggestion(
+	ctx context.Context,
+	tx pgx.Tx,
+	id uuid.UUID,
+	status EditSuggestionStatus,
+	actorID uuid.UUID,
+	now time.Time,
+) (*EditSuggestion, error) {
+	q := `
+		UPDATE task_edit_suggestions
+		SET status = $2, resolved_by = $3, resolved_at = $4
+		WHERE id = $1
+		RETURNING ` + suggestionColumns
+
+	sg, err := s.scanSuggestion(tx.QueryRow(ctx, q, id, status, actorID, now.UTC()))
+	if err != nil {
+		return nil, fmt.Errorf("resolve edit suggestion id=%s: %w", id, err)
+	}
+	return sg, nil
+}
+
+func (s *PGTaskStore) scanSuggestion(row pgx.Row, extra ...any) (*EditSuggestion, error) {
+	var sg EditSuggestion
+	dest := []any{
+		&sg.ID,
+		&sg.TaskID,
+		&sg.TeamID,
+		&sg.AuthorID,
+		&sg.Title,
+		&sg.Description,
+		&sg.Status,
+		&sg.ResolvedBy,
+		&sg.ResolvedAt,
+		&sg.CreatedAt,
+	}
+	if err := row.Scan(append(dest, extra...)...); err != nil {
+		return nil, err
+	}
+	plain, err := s.openSealed(sg.TeamID, sg.Description)
+	if err != nil {
+		return nil, fmt.Errorf("open suggested description id=%s: %w", sg.ID, err)
+	}
+	sg.Description = plain
+	return &sg, nil
+}
+
+// deref reads an optional text field, with no value read as empty.
+func deref(v *string) string {
+	if v == nil {
+		return ""
+	}
+	return *v
+}
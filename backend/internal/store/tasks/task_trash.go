@@ -0,0 +1,197 @@
+package store
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/diagnosis/interactive-todo/api/types"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+// Deleting a task only sets deleted_at. The task then sits in its team's
+// trash, invisible to every other read, until it is restored or purged
+// TrashRetention later. Its comments, labels and attachments stay with it.
+
+type TrashedTask = types.TrashedTask
+
+const TrashRetention = 30 * 24 * time.Hour
+
+func (s *PGTaskStore) scanTrashedTask(row pgx.Row) (*TrashedTask, error) {
+	var t TrashedTask
+	task, err := s.scanTaskRow(row, &t.DeletedAt, &t.DeletedBy)
+	if err != nil {
+		return nil, err
+	}
+	t.Task = *task
+	t.PurgeAt = t.DeletedAt.Add(TrashRetention)
+	return &t, nil
+}
+
+// ListTrash returns up to limit of the team's deleted tasks that viewerID
+// could see, most recently deleted first.
+func (s *PGTaskStore) ListTrash(ctx context.Context, teamID, viewerID uuid.UUID, limit int) ([]TrashedTask, error) {
+	q := `
+		SELECT ` + taskColumns + `, deleted_at, deleted_by
+		FROM tasks
+		WHERE team_id = $1
+		  AND deleted_at IS NOT NULL
+		  AND ` + visibleTo("tasks", "$2") + `
+		ORDER BY deleted_at DESC, id
+		LIMIT $3
+	`
+	rows, err := s.pool.Query(ctx, q, teamID, viewerID, limit)
+	if err != nil {
+		return nil, fmt.Errorf("list trash team_id=%s: %w", teamID, err)
+	}
+	defer rows.Close()
+
+	out := []TrashedTask{}
+	for rows.Next() {
+		t, err := s.scanTrashedTask(rows)
+		if err != nil {
+			return nil, fmt.Errorf("list trash team_id=%s: scan: %w", teamID, err)
+		}
+		out = append(out, *t)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("list trash team_id=%s: %w", teamID, err)
+	}
+	return out, nil
+}
+
+// AdminTrashFilter narrows ListTrashForAdmin. Only Limit is required;
+// zero values of the other fields match everything. Results are most
+// recently deleted first; pass the last task's DeletedAt and ID as the
+// Before pair to get the next page.
+type AdminTrashFilter struct {
+	TeamID    uuid.UUID
+	DeletedBy uuid.UUID
+	BeforeAt  time.Time
+	BeforeID  uuid.UUID
+	Limit     int
+}
+
+// ListTrashForAdmin lists deleted tasks of every team, private ones
+// included, for the admin restore console.
+func (s *PGTaskStore) ListTrashForAdmin(ctx context.Context, f AdminTrashFilter) ([]TrashedTask, error) {
+	if f.Limit < 1 {
+		return nil, fmt.Errorf("%w: limit is required", ErrInvalidInput)
+	}
+
+	const q = `
+		SELECT ` + taskColumns + `, deleted_at, deleted_by
+		FROM tasks
+		WHERE deleted_at IS NOT NULL
+		  AND ($1 = '00000000-0000-0000-0000-000000000000'::uuid OR team_id = $1)
+		  AND ($2 = '00000000-0000-0000-0000-000000000000'::uuid OR deleted_by = $2)
+		  AND ($3::timestamptz IS NULL OR (deleted_at, id) < ($3, $4))
+		ORDER BY deleted_at DESC, id DESC
+		LIMIT $5
+	`
+
+	var beforeAt *time.Time
+	if !f.BeforeAt.IsZero() {
+		t := f.BeforeAt.UTC()
+		beforeAt = &t
+	}
+
+	rows, err := s.pool.Query(ctx, q, f.TeamID, f.DeletedBy, beforeAt, f.BeforeID, f.Limit)
+	if err != nil {
+		return nil, fmt.Errorf("list trash for admin: %w", err)
+	}
+	defer rows.Close()
+
+	out := []TrashedTask{}
+	for rows.Next() {
+		t, err := s.scanTrashedTask(rows)
+		if err != nil {
+			return nil, fmt.Errorf("list trash for admin: scan: %w", err)
+		}
+		out = append(out, *t)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("list trash for admin: %w", err)
+	}
+	return out, nil
+}
+
+// GetTrashedTask returns ErrTaskNotFound unless the task is in the trash.
+func (s *PGTaskStore) GetTrashedTask(ctx context.Context, id uuid.UUID) (*TrashedTask, error) {
+	const q = `
+		SELECT ` + taskColumns + `, deleted_at, deleted_by
+		FROM tasks
+		WHERE id = $1
+		  AND deleted_at IS NOT NULL
+	`
+	t, err := s.scanTrashedTask(s.pool.QueryRow(ctx, q, id))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrTaskNotFound
+		}
+		return nil, fmt.Errorf("get trashed task id=%s: %w", id, err)
+	}
+	return t, nil
+}
+
+// RestoreTask takes the task out of the trash as it was deleted.
+func (s *PGTaskStore) RestoreTask(ctx context.Context, id uuid.UUID, actorID *uuid.UUID, now time.Time) (*Task, error) {
+	const q = `
+		UPDATE tasks
+		SET deleted_at = NULL,
+		    deleted_by = NULL,
+		    updated_at = $2
+		WHERE id = $1
+		  AND deleted_at IS NOT NULL
+		` + taskReturning
+
+	tx, err := s.pool.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("restore task: begin: %w", err)
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	o, err := s.scanTaskRow(tx.QueryRow(ctx, q, id, now.UTC()))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrTaskNotFound
+		}
+		return nil, fmt.Errorf("restore task id=%s: %w", id, err)
+	}
+	if err := recordEvent(ctx, tx, nil, o, types.TaskEventRestored, actorID, now); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("restore task: commit: %w", err)
+	}
+	return o, nil
+}
+
+// PurgeTrash deletes up to limit tasks trashed before deletedBefore, for
+// good. Tasks under legal hold stay in the trash. Their history is kept.
+func (s *PGTaskStore) PurgeTrash(ctx context.Context, deletedBefore time.Time, limit int) (int, error) {
+	const q = `
+		DELETE FROM tasks
+		WHERE id IN (
+			SELECT t.id
+			FROM tasks t
+			WHERE t.deleted_at < $1
+			  AND NOT EXISTS (
+				SELECT 1 FROM legal_holds lh
+				WHERE lh.task_id = t.id
+				   OR (lh.task_id IS NULL AND lh.team_id = t.team_id)
+			  )
+			ORDER BY t.deleted_at
+			LIMIT $2
+			FOR UPDATE SKIP LOCKED
+		)
+	`
+	ct, err := s.pool.Exec(ctx, q, deletedBefore.UTC(), limit)
+	if err != nil {
+		return 0, fmt.Errorf("purge trash: %w", err)
+	}
+	return int(ct.RowsAffected()), nil
+}
@@ -0,0 +1,86 @@
+package store
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/diagnosis/interactive-todo/api/types"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+// Votes: each member can upvote a task once. tasks.vote_count follows
+// task_votes through a trigger, so both calls below return the count as
+// their own change left it.
+
+type TaskVoter = types.TaskVoter
+
+// Vote upvotes a task for userID and returns its vote count. Voting again
+// changes nothing.
+func (s *PGTaskStore) Vote(ctx context.Context, taskID, userID uuid.UUID, now time.Time) (int, error) {
+	const q = `
+		INSERT INTO task_votes (task_id, user_id, created_at)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (task_id, user_id) DO NOTHING`
+
+	return s.changeVote(ctx, taskID, "vote", q, taskID, userID, now.UTC())
+}
+
+// Unvote takes userID's vote back and returns the task's vote count.
+func (s *PGTaskStore) Unvote(ctx context.Context, taskID, userID uuid.UUID) (int, error) {
+	const q = `DELETE FROM task_votes WHERE task_id = $1 AND user_id = $2`
+
+	return s.changeVote(ctx, taskID, "unvote", q, taskID, userID)
+}
+
+func (s *PGTaskStore) changeVote(ctx context.Context, taskID uuid.UUID, op, q string, args ...any) (int, error) {
+	tx, err := s.pool.Begin(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("%s: begin: %w", op, err)
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	if _, err := tx.Exec(ctx, q, args...); err != nil {
+		return 0, fmt.Errorf("%s task_id=%s: %w", op, taskID, err)
+	}
+	var count int
+	err = tx.QueryRow(ctx, `SELECT vote_count FROM tasks WHERE id = $1 AND deleted_at IS NULL`, taskID).Scan(&count)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return 0, ErrTaskNotFound
+		}
+		return 0, fmt.Errorf("%s task_id=%s: read count: %w", op, taskID, err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return 0, fmt.Errorf("%s: commit: %w", op, err)
+	}
+	return count, nil
+}
+
+// ListVoters returns who voted for a task, earliest first.
+func (s *PGTaskStore) ListVoters(ctx context.Context, taskID uuid.UUID) ([]TaskVoter, error) {
+	const q = `
+		SELECT user_id, created_at
+		FROM task_votes
+		WHERE task_id = $1
+		ORDER BY created_at, user_id`
+
+	rows, err := s.pool.Query(ctx, q, taskID)
+	if err != nil {
+		return nil, fmt.Errorf("list voters task_id=%s: %w", taskID, err)
+	}
+	defer rows.Close()
+
+	out := []TaskVoter{}
+	for rows.Next() {
+		var v TaskVoter
+		if err := rows.Scan(&v.UserID, &v.VotedAt); err != nil {
+			return nil, fmt.Errorf("scan voter: %w", err)
+		}
+		out = append(out, v)
+	}
+	return out, rows.Err()
+}
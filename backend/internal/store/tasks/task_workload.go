@@ -0,0 +1,37 @@
+package store
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/diagnosis/interactive-todo/api/types"
+	"github.com/google/uuid"
+)
+
+type AssigneeWorkload = types.AssigneeWorkload
+
+// GetAssigneeWorkload sums the open and in-progress tasks assigned to a
+// user in every team, since one person's time is shared between them.
+func (s *PGTaskStore) GetAssigneeWorkload(ctx context.Context, assigneeID uuid.UUID, now time.Time) (*AssigneeWorkload, error) {
+	const q = `
+		SELECT COUNT(*),
+		       COALESCE(SUM(estimate_hours), 0)::float8,
+		       COUNT(*) FILTER (WHERE estimate_hours IS NULL),
+		       COUNT(*) FILTER (WHERE due_at < $2)
+		FROM tasks
+		WHERE assignee_id = $1
+		  AND status IN ('open', 'in_progress')
+		  AND deleted_at IS NULL
+	`
+	var w AssigneeWorkload
+	if err := s.pool.QueryRow(ctx, q, assigneeID, now.UTC()).Scan(
+		&w.OpenTasks,
+		&w.EstimatedHours,
+		&w.UnestimatedTasks,
+		&w.Overdue,
+	); err != nil {
+		return nil, fmt.Errorf("get workload assignee_id=%s: %w", assigneeID, err)
+	}
+	return &w, nil
+}
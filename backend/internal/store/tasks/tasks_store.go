@@ -4,15 +4,21 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"maps"
+	"slices"
 	"strings"
 	"time"
 
+	"github.com/diagnosis/interactive-todo/api/types"
+	"github.com/diagnosis/interactive-todo/internal/secure/fieldcrypt"
+	reminderstore "github.com/diagnosis/interactive-todo/internal/store/reminders"
 	"github.com/google/uuid"
 	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
 	"github.com/jackc/pgx/v5/pgxpool"
 )
 
-type TaskStatus string
+type TaskStatus = types.TaskStatus
 
 const (
 	OpenStatus       TaskStatus = "open"
@@ -25,26 +31,43 @@ var (
 	ErrTaskNotFound  = errors.New("task not found")
 	ErrInvalidStatus = errors.New("invalid task status")
 	ErrInvalidInput  = errors.New("invalid input")
+	ErrLegalHold     = errors.New("task is under legal hold")
+	// ErrVersionConflict: the task is no longer at the version the caller
+	// read; the wrapped message has the current one.
+	ErrVersionConflict = errors.New("task was changed by someone else")
+	// ErrProjectNotInTeam is an ErrInvalidInput: the project does not exist
+	// or belongs to another team.
+	ErrProjectNotInTeam = fmt.Errorf("%w: project not found in the task's team", ErrInvalidInput)
+
+	ErrEncryptionUnavailable = errors.New("field encryption key not configured")
 )
 
-type Task struct {
-	ID             uuid.UUID  `json:"id"`
-	TeamID         uuid.UUID  `json:"team_id"`
-	Title          string     `json:"title"`
-	Description    *string    `json:"description,omitempty"`
-	ReporterID     uuid.UUID  `json:"reporter_id"`
-	AssigneeID     uuid.UUID  `json:"assignee_id"`
-	DueAt          time.Time  `json:"due_at"`
-	ReminderSentAt *time.Time `json:"reminder_sent_at,omitempty"`
-	Status         TaskStatus `json:"status"`
-	CreatedAt      time.Time  `json:"created_at"`
-	UpdatedAt      time.Time  `json:"updated_at"`
-}
+// Task is also the API's wire type, so store and responses cannot drift.
+type Task = types.Task
+type ExportedTask = types.ExportedTask
+
+type TaskPriority = types.TaskPriority
+
+// MaxEstimateHours bounds a task's estimate; anything larger should be
+// split up.
+const MaxEstimateHours = 1000
 
 type TaskUpdate struct {
-	Title       *string    `json:"title"`
-	Description *string    `json:"description"`
-	DueAt       *time.Time `json:"due_at"`
+	Title         *string       `json:"title"`
+	Description   *string       `json:"description"`
+	StartAt       *time.Time    `json:"start_at"`
+	DueAt         *time.Time    `json:"due_at"`
+	Priority      *TaskPriority `json:"priority"`
+	EstimateHours *float64      `json:"estimate_hours"`
+	// ClearStartAt removes the start date
+	ClearStartAt bool `json:"clear_start_at"`
+	// ProjectID must be a project of the task's team; ClearProject takes
+	// the task out of its project
+	ProjectID    *uuid.UUID `json:"project_id"`
+	ClearProject bool       `json:"clear_project"`
+	// CustomFields are merged into the task's values, which the caller
+	// has checked against the team's fields; a nil value removes the key
+	CustomFields map[string]any `json:"custom_fields"`
 }
 
 type TaskStore interface {
@@ -55,21 +78,40 @@ type TaskStore interface {
 		description *string,
 		reporterID uuid.UUID,
 		assigneeID uuid.UUID,
+		startAt *time.Time,
 		dueAt time.Time,
+		private bool,
+		priority TaskPriority,
+		estimateHours *float64,
+		projectID *uuid.UUID,
+		customFields map[string]any,
+		actorID *uuid.UUID,
 		now time.Time,
 	) (*Task, error)
 
+	// ImportTasks creates many tasks at once; see task_import.go.
+	ImportTasks(ctx context.Context, teamID, reporterID uuid.UUID, in []ImportedTask, start *WorkflowStart, now time.Time) ([]Task, error)
+
+	// The methods that change a task record the change in its history
+	// (task_events) as actorID; nil is an automation. Those taking
+	// ifVersion return ErrVersionConflict unless the task is at that
+	// version; 0 skips the check.
 	Assign(
 		ctx context.Context,
 		taskID uuid.UUID,
 		newAssigneeID uuid.UUID,
+		ifVersion int64,
+		actorID *uuid.UUID,
 		now time.Time,
 	) (*Task, error)
+	BulkAssign(ctx context.Context, in BulkAssignment, actorID *uuid.UUID, now time.Time) ([]Task, error)
 
 	UpdateStatus(
 		ctx context.Context,
 		taskID uuid.UUID,
 		newStatus TaskStatus,
+		ifVersion int64,
+		actorID *uuid.UUID,
 		now time.Time,
 	) (*Task, error)
 
@@ -77,24 +119,116 @@ type TaskStore interface {
 		ctx context.Context,
 		taskID uuid.UUID,
 		patch TaskUpdate,
+		ifVersion int64,
+		actorID *uuid.UUID,
+		now time.Time,
+	) (*Task, error)
+
+	SetWorkflowState(
+		ctx context.Context,
+		taskID uuid.UUID,
+		state string,
+		category TaskStatus,
+		actorID *uuid.UUID,
 		now time.Time,
 	) (*Task, error)
 
 	GetTaskByID(ctx context.Context, id uuid.UUID) (*Task, error)
-	GetTasksByAssigneeID(ctx context.Context, assigneeID uuid.UUID) ([]Task, error)
-	GetTasksByReporterID(ctx context.Context, reporterID uuid.UUID) ([]Task, error)
-	GetAllTasks(ctx context.Context) ([]Task, error)
-	DeleteTask(ctx context.Context, id uuid.UUID) error
+	// GetVisibleTaskByID behaves like GetTaskByID but reports private tasks
+	// the viewer may not see as ErrTaskNotFound.
+	GetVisibleTaskByID(ctx context.Context, id, viewerID uuid.UUID) (*Task, error)
+	// Task lists return one page and where the next one starts; see PageInfo.
+	GetTasksByAssigneeID(ctx context.Context, assigneeID uuid.UUID, filter TaskFilter, sort SortSpec, page Page) ([]Task, PageInfo, error)
+	GetTasksByReporterID(ctx context.Context, reporterID uuid.UUID, filter TaskFilter, sort SortSpec, page Page) ([]Task, PageInfo, error)
+	// ListCalendarTasks returns up to limit tasks assigned to the user in
+	// teams they are still in, soonest due first: the open and in-progress
+	// ones, and those done since doneSince. Tasks of confidential teams
+	// come without their description.
+	ListCalendarTasks(ctx context.Context, assigneeID uuid.UUID, doneSince time.Time, limit int) ([]Task, error)
+	// ListTasksForAdmin and ListTrashForAdmin are the only cross-user
+	// listings; both are bounded by f.Limit, and the first is always scoped
+	// to one team.
+	ListTasksForAdmin(ctx context.Context, f AdminTaskFilter) ([]Task, error)
+	DeleteTask(ctx context.Context, id uuid.UUID, actorID *uuid.UUID, now time.Time) error
+	// the trash holds deleted tasks until they are purged, see task_trash.go
+	ListTrash(ctx context.Context, teamID, viewerID uuid.UUID, limit int) ([]TrashedTask, error)
+	GetTrashedTask(ctx context.Context, id uuid.UUID) (*TrashedTask, error)
+	RestoreTask(ctx context.Context, id uuid.UUID, actorID *uuid.UUID, now time.Time) (*Task, error)
+	PurgeTrash(ctx context.Context, deletedBefore time.Time, limit int) (int, error)
+	ListTrashForAdmin(ctx context.Context, f AdminTrashFilter) ([]TrashedTask, error)
+	// archived tasks stay out of lists by default, see task_archiving.go
+	ArchiveTask(ctx context.Context, id uuid.UUID, actorID *uuid.UUID, now time.Time) (*Task, error)
+	UnarchiveTask(ctx context.Context, id uuid.UUID, actorID *uuid.UUID, now time.Time) (*Task, error)
+	// board order within a status column, see task_position.go
+	MoveTask(ctx context.Context, id uuid.UUID, afterID, beforeID *uuid.UUID) (*Task, error)
+	RebalancePositions(ctx context.Context, limit int) (int, error)
+	// title and description edits proposed to the reporter, see
+	// task_suggestions.go
+	SuggestEdit(ctx context.Context, taskID, authorID uuid.UUID, title, description *string, now time.Time) (*EditSuggestion, error)
+	ListEditSuggestions(ctx context.Context, taskID uuid.UUID) ([]EditSuggestion, error)
+	AcceptEditSuggestion(ctx context.Context, taskID, suggestionID, actorID uuid.UUID, now time.Time) (*EditSuggestion, *Task, error)
+	RejectEditSuggestion(ctx context.Context, taskID, suggestionID, actorID uuid.UUID, now time.Time) (*EditSuggestion, error)
+	// one upvote per member, see task_votes.go
+	Vote(ctx context.Context, taskID, userID uuid.UUID, now time.Time) (int, error)
+	Unvote(ctx context.Context, taskID, userID uuid.UUID) (int, error)
+	ListVoters(ctx context.Context, taskID uuid.UUID) ([]TaskVoter, error)
+	// ListEvents and DeletedTask read the history, see task_history.go.
+	ListEvents(ctx context.Context, taskID uuid.UUID, limit int) ([]TaskEvent, error)
+	DeletedTask(ctx context.Context, taskID uuid.UUID) (teamID uuid.UUID, private bool, err error)
+	// Dependencies between tasks, possibly of different teams; see
+	// task_dependencies.go.
+	AddDependency(ctx context.Context, taskID, blockerID uuid.UUID, createdBy *uuid.UUID, now time.Time) (*TaskDependency, error)
+	RemoveDependency(ctx context.Context, taskID, blockerID uuid.UUID) error
+	DependencyChain(ctx context.Context, taskID, viewerID uuid.UUID, maxDepth int) (*TaskDependencyChain, error)
+	ListCrossTeamDependencies(ctx context.Context, viewerID uuid.UUID, includeResolved bool, limit int) ([]CrossTeamDependency, error)
 	//team member actions
-	ListTeamTasks(ctx context.Context, userID uuid.UUID) ([]Task, error)
-	ListAssigneeTasksInTeam(ctx context.Context, teamID, userID uuid.UUID) ([]Task, error)
-	ListReporterTasksInTeam(ctx context.Context, teamID uuid.UUID, userID uuid.UUID) ([]Task, error)
-
-	FindDueForReminder(ctx context.Context, from, before time.Time) ([]Task, error)
-	MarkReminderSent(ctx context.Context, taskID uuid.UUID, when time.Time) error
+	ListTeamTasks(ctx context.Context, teamID, viewerID uuid.UUID, filter TaskFilter, sort SortSpec, page Page) ([]Task, PageInfo, error)
+	// StreamTeamTasks calls fn for each task visible to viewerID as rows are
+	// read, without holding the whole team in memory. An error from fn stops
+	// the scan and is returned as is. Archived tasks are skipped unless
+	// includeArchived.
+	StreamTeamTasks(ctx context.Context, teamID, viewerID uuid.UUID, includeArchived bool, fn func(*ExportedTask) error) error
+	ListTaskFields(ctx context.Context, scope TaskScope, fields []string, page Page) ([]map[string]any, PageInfo, error)
+
+	ArchiveFinished(ctx context.Context, olderThan time.Time, limit int, now time.Time) (int, error)
+	StreamArchivedTeamTasks(ctx context.Context, teamID, viewerID uuid.UUID, fn func(*ExportedTask) error) error
+
+	GetTeamTaskCounts(ctx context.Context, teamID uuid.UUID, now time.Time) (*TeamTaskCounts, error)
+	GetAssigneeWorkload(ctx context.Context, assigneeID uuid.UUID, now time.Time) (*AssigneeWorkload, error)
+	BurnUp(ctx context.Context, teamID, viewerID uuid.UUID, from, to time.Time, timezone string) ([]BurnUpDay, error)
+	ReconcileTaskCounters(ctx context.Context, now time.Time) (int, error)
+	ListAssigneeTasksInTeam(ctx context.Context, teamID, userID uuid.UUID, filter TaskFilter, sort SortSpec, page Page) ([]Task, PageInfo, error)
+	ListReporterTasksInTeam(ctx context.Context, teamID uuid.UUID, userID uuid.UUID, filter TaskFilter, sort SortSpec, page Page) ([]Task, PageInfo, error)
+
+	ListRecentTitlesByReporter(ctx context.Context, reporterID uuid.UUID, since time.Time) ([]string, error)
+
+	ListStaleTasksInTeam(ctx context.Context, teamID, viewerID uuid.UUID, notUpdatedSince time.Time) ([]Task, error)
+	FindStaleForNudge(ctx context.Context, now time.Time) ([]Task, error)
+	MarkStaleNudged(ctx context.Context, taskID uuid.UUID, when time.Time) error
+
+	Acknowledge(ctx context.Context, taskID, assigneeID uuid.UUID, when time.Time) (bool, error)
+	FindUnacknowledgedForNudge(ctx context.Context, now time.Time) ([]Task, error)
+	MarkAckNudged(ctx context.Context, taskID uuid.UUID, when time.Time) error
+
+	SetVisibility(ctx context.Context, taskID uuid.UUID, private bool, viewerIDs []uuid.UUID, actorID *uuid.UUID, now time.Time) (*Task, error)
+	ListViewers(ctx context.Context, taskID uuid.UUID) ([]uuid.UUID, error)
+
+	SealConfidentialDescriptions(ctx context.Context, limit int) (int, error)
+
+	// embeddings, used only when EMBEDDINGS_PROVIDER is set
+	EmbeddingDimensions(ctx context.Context) (int, error)
+	ListEmbeddingBacklog(ctx context.Context, model string, limit int) ([]EmbeddingSource, error)
+	SaveEmbedding(ctx context.Context, taskID uuid.UUID, model, contentHash string, vec []float32, now time.Time) error
+	SimilarTasks(ctx context.Context, teamID, viewerID uuid.UUID, model string, vec []float32, excludeID uuid.UUID, minScore float64, limit int) ([]SimilarTask, error)
+	SearchInTeam(ctx context.Context, teamID, viewerID uuid.UUID, query string, limit int) ([]TaskSearchResult, error)
+
+	// org-wide announcements, see task_announcements.go
+	CreateAnnouncement(ctx context.Context, in NewAnnouncement, createdBy uuid.UUID, now time.Time) (*Announcement, []Task, error)
+	ListAnnouncements(ctx context.Context, limit, offset int, now time.Time) ([]Announcement, error)
+	GetAnnouncement(ctx context.Context, id uuid.UUID, now time.Time) (*Announcement, []AnnouncementTeam, error)
 }
 
-// NOTE: order must match table + all Scan calls
+// NOTE: order must match scanTaskRow
 const taskColumns = `
     id,
     team_id,
@@ -102,86 +236,134 @@ const taskColumns = `
     description,
     reporter_id,
     assignee_id,
+    start_at,
     due_at,
-    reminder_sent_at,
     status,
+    priority,
+    estimate_hours,
+    workflow_state,
+    is_private,
+    assigned_at,
+    acknowledged_at,
+    announcement_id,
     created_at,
-    updated_at
+    updated_at,
+    version,
+    completed_at,
+    canceled_at,
+    archived_at,
+    position,
+    vote_count,
+    project_id,
+    custom_fields
 `
 
 const taskReturning = "RETURNING " + taskColumns
 
 type PGTaskStore struct {
 	pool *pgxpool.Pool
+	// cipher seals descriptions of confidential teams; nil when no key is
+	// configured.
+	cipher *fieldcrypt.Cipher
 }
 
-func NewPGTaskStore(pool *pgxpool.Pool) *PGTaskStore {
-	return &PGTaskStore{pool: pool}
+func NewPGTaskStore(pool *pgxpool.Pool, cipher *fieldcrypt.Cipher) *PGTaskStore {
+	return &PGTaskStore{pool: pool, cipher: cipher}
 }
 func (s *PGTaskStore) ListReporterTasksInTeam(
 	ctx context.Context,
 	teamID uuid.UUID,
 	userID uuid.UUID,
-) ([]Task, error) {
+	filter TaskFilter,
+	sort SortSpec,
+	page Page,
+) ([]Task, PageInfo, error) {
 	if teamID == uuid.Nil || userID == uuid.Nil {
-		return nil, fmt.Errorf("%w: team_id and user_id cannot be nil", ErrInvalidInput)
+		return nil, PageInfo{}, fmt.Errorf("%w: team_id and user_id cannot be nil", ErrInvalidInput)
 	}
 
-	const q = `
-		SELECT ` + taskColumns + `
-		FROM tasks
-		WHERE team_id = $1
-		  AND reporter_id = $2
-		ORDER BY created_at DESC;
-	`
-
-	rows, err := s.pool.Query(ctx, q, teamID, userID)
-	if err != nil {
-		return nil, fmt.Errorf("list reporter tasks in team: %w", err)
-	}
-	defer rows.Close()
-
-	return scanTask(rows)
+	return s.listPage(ctx, taskList{
+		op:    "list reporter tasks in team",
+		where: "team_id = $1 AND reporter_id = $2",
+		args:  []any{teamID, userID},
+		def:   newestFirst,
+	}, filter, sort, page)
 }
-func (s *PGTaskStore) ListAssigneeTasksInTeam(ctx context.Context, teamID, userID uuid.UUID) ([]Task, error) {
+func (s *PGTaskStore) ListAssigneeTasksInTeam(ctx context.Context, teamID, userID uuid.UUID, filter TaskFilter, sort SortSpec, page Page) ([]Task, PageInfo, error) {
 	if teamID == uuid.Nil || userID == uuid.Nil {
-		return nil, fmt.Errorf("%w: team_id and user_id cannot be nil", ErrInvalidInput)
+		return nil, PageInfo{}, fmt.Errorf("%w: team_id and user_id cannot be nil", ErrInvalidInput)
 	}
 
-	const q = `
-		SELECT ` + taskColumns + ` 
-		FROM tasks
-		WHERE team_id = $1
-			AND assignee_id = $2
-		ORDER BY due_at;
-`
-	rows, err := s.pool.Query(ctx, q, teamID, userID)
-	if err != nil {
-		return nil, fmt.Errorf("list assignee tasks in team: %w", err)
+	return s.listPage(ctx, taskList{
+		op:    "list assignee tasks in team",
+		where: "team_id = $1 AND assignee_id = $2",
+		args:  []any{teamID, userID},
+		def:   soonestDue,
+	}, filter, sort, page)
+}
+func (s *PGTaskStore) ListTeamTasks(ctx context.Context, teamID, viewerID uuid.UUID, filter TaskFilter, sort SortSpec, page Page) ([]Task, PageInfo, error) {
+	if teamID == uuid.Nil {
+		return nil, PageInfo{}, fmt.Errorf("%w: team_id cannot be nil", ErrInvalidInput)
 	}
-	defer rows.Close()
 
-	return scanTask(rows)
+	return s.listPage(ctx, taskList{
+		op:    "list team tasks",
+		where: "team_id = $1 AND " + visibleTo("tasks", "$2"),
+		args:  []any{teamID, viewerID},
+		def:   newestFirst,
+	}, filter, sort, page)
 }
-func (s *PGTaskStore) ListTeamTasks(ctx context.Context, teamID uuid.UUID) ([]Task, error) {
+
+func (s *PGTaskStore) StreamTeamTasks(ctx context.Context, teamID, viewerID uuid.UUID, includeArchived bool, fn func(*ExportedTask) error) error {
 	if teamID == uuid.Nil {
-		return nil, fmt.Errorf("%w: team_id cannot be nil", ErrInvalidInput)
+		return fmt.Errorf("%w: team_id cannot be nil", ErrInvalidInput)
 	}
 
-	const q = `
-		SELECT ` + taskColumns + `
+	q := `
+		SELECT ` + taskColumns + `,
+		       (SELECT email::text FROM users WHERE id = tasks.reporter_id),
+		       (SELECT email::text FROM users WHERE id = tasks.assignee_id),
+		       COALESCE((
+		           SELECT jsonb_agg(jsonb_build_object(
+		                      'id', l.id, 'team_id', l.team_id, 'name', l.name, 'created_at', l.created_at
+		                  ) ORDER BY lower(l.name))
+		           FROM task_labels tl
+		           JOIN labels l ON l.id = tl.label_id
+		           WHERE tl.task_id = tasks.id AND tl.team_id = tasks.team_id
+		       ), '[]'::jsonb)
 		FROM tasks
 		WHERE team_id = $1
-		ORDER BY created_at DESC;
+		  AND deleted_at IS NULL
+		  AND ($3 OR archived_at IS NULL)
+		  AND ` + visibleTo("tasks", "$2") + `
+		ORDER BY created_at, id;
 	`
 
-	rows, err := s.pool.Query(ctx, q, teamID)
+	rows, err := s.pool.Query(ctx, q, teamID, viewerID, includeArchived)
 	if err != nil {
-		return nil, fmt.Errorf("list team tasks: %w", err)
+		return fmt.Errorf("stream team tasks team_id=%s: %w", teamID, err)
 	}
 	defer rows.Close()
 
-	return scanTask(rows)
+	for rows.Next() {
+		var (
+			e      ExportedTask
+			labels []types.Label
+		)
+		t, err := s.scanTaskRow(rows, &e.ReporterEmail, &e.AssigneeEmail, &labels)
+		if err != nil {
+			return fmt.Errorf("stream team tasks team_id=%s: scan: %w", teamID, err)
+		}
+		t.Labels = labels
+		e.Task = *t
+		if err := fn(&e); err != nil {
+			return err
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("stream team tasks team_id=%s: %w", teamID, err)
+	}
+	return nil
 }
 
 // validateTask performs input validation
@@ -219,6 +401,41 @@ func validateTaskUpdate(upd TaskUpdate, now time.Time) error {
 			return fmt.Errorf("%w: due_at must be at least 8 hours in future from now", ErrInvalidInput)
 		}
 	}
+	if upd.ClearStartAt && upd.StartAt != nil {
+		return fmt.Errorf("%w: start_at cannot be set and cleared at once", ErrInvalidInput)
+	}
+	if upd.ClearProject && upd.ProjectID != nil {
+		return fmt.Errorf("%w: project_id cannot be set and cleared at once", ErrInvalidInput)
+	}
+	return validateEstimate(upd.EstimateHours)
+}
+
+// isProjectViolation reports a project_id that fk_tasks_project refused.
+func isProjectViolation(err error) bool {
+	var pgErr *pgconn.PgError
+	return errors.As(err, &pgErr) && pgErr.Code == "23503" && pgErr.ConstraintName == "fk_tasks_project"
+}
+
+func utcOrNil(t *time.Time) *time.Time {
+	if t == nil {
+		return nil
+	}
+	u := t.UTC()
+	return &u
+}
+
+// validateWindow checks that a task starts before it is due.
+func validateWindow(startAt *time.Time, dueAt time.Time) error {
+	if startAt != nil && !startAt.Before(dueAt) {
+		return fmt.Errorf("%w: start_at must be before due_at", ErrInvalidInput)
+	}
+	return nil
+}
+
+func validateEstimate(hours *float64) error {
+	if hours != nil && (*hours <= 0 || *hours > MaxEstimateHours) {
+		return fmt.Errorf("%w: estimate_hours must be above 0 and at most %d", ErrInvalidInput, MaxEstimateHours)
+	}
 	return nil
 }
 
@@ -229,7 +446,14 @@ func (s *PGTaskStore) Create(
 	description *string,
 	reporterID uuid.UUID,
 	assigneeID uuid.UUID,
+	startAt *time.Time,
 	dueAt time.Time,
+	private bool,
+	priority TaskPriority,
+	estimateHours *float64,
+	projectID *uuid.UUID,
+	customFields map[string]any,
+	actorID *uuid.UUID,
 	now time.Time,
 ) (*Task, error) {
 	if teamID == uuid.Nil {
@@ -238,6 +462,20 @@ func (s *PGTaskStore) Create(
 	if err := validateTask(title, reporterID, assigneeID, dueAt, now); err != nil {
 		return nil, err
 	}
+	if err := validateWindow(startAt, dueAt); err != nil {
+		return nil, err
+	}
+	if err := validateEstimate(estimateHours); err != nil {
+		return nil, err
+	}
+
+	description, err := s.sealDescription(ctx, teamID, description)
+	if err != nil {
+		return nil, err
+	}
+	if customFields == nil {
+		customFields = map[string]any{}
+	}
 
 	const q = `
 		INSERT INTO tasks (
@@ -247,88 +485,127 @@ func (s *PGTaskStore) Create(
 			reporter_id,
 			assignee_id,
 			due_at,
+			is_private,
+			priority,
+			estimate_hours,
+			start_at,
+			project_id,
+			custom_fields,
+			assigned_at,
+			acknowledged_at,
 			created_at,
 			updated_at
 		)
-		VALUES ($1, $2, $3, $4, $5, $6, $7, $7)
+		-- self-assigned tasks need no acknowledgement
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $12, $13, $11,
+		        CASE WHEN $4::uuid = $5::uuid THEN $11::timestamptz END,
+		        $11, $11)
 		` + taskReturning
 
-	var o Task
-	if err := s.pool.QueryRow(ctx, q,
+	tx, err := s.pool.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("create task: begin: %w", err)
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	o, err := s.scanTaskRow(tx.QueryRow(ctx, q,
 		teamID,
 		title,
 		description,
 		reporterID,
 		assigneeID,
 		dueAt.UTC(),
+		private,
+		priority,
+		estimateHours,
+		utcOrNil(startAt),
 		now.UTC(),
-	).Scan(
-		&o.ID,
-		&o.TeamID,
-		&o.Title,
-		&o.Description,
-		&o.ReporterID,
-		&o.AssigneeID,
-		&o.DueAt,
-		&o.ReminderSentAt,
-		&o.Status,
-		&o.CreatedAt,
-		&o.UpdatedAt,
-	); err != nil {
+		projectID,
+		customFields,
+	))
+	if err != nil {
+		if isProjectViolation(err) {
+			return nil, ErrProjectNotInTeam
+		}
+		return nil, fmt.Errorf("create task: %w", err)
+	}
+	if err := recordEvent(ctx, tx, nil, o, types.TaskEventCreated, actorID, now); err != nil {
+		return nil, err
+	}
+	if err := reminderstore.AddDefaults(ctx, tx, []uuid.UUID{o.ID}, now); err != nil {
 		return nil, fmt.Errorf("create task: %w", err)
 	}
 
-	return &o, nil
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("create task: commit: %w", err)
+	}
+	return o, nil
 }
 
+// assignTask gives task $1 to $2 at $3; Assign and BulkAssign share it.
+const assignTask = `
+	UPDATE tasks
+	SET assignee_id     = $2,
+	    updated_at      = $3,
+	    -- a new assignee has to acknowledge again, unless they are the reporter
+	    assigned_at     = CASE WHEN assignee_id = $2 THEN assigned_at ELSE $3 END,
+	    acknowledged_at = CASE
+	                          WHEN assignee_id = $2 THEN acknowledged_at
+	                          WHEN reporter_id = $2 THEN $3
+	                      END,
+	    ack_nudged_at   = CASE WHEN assignee_id = $2 THEN ack_nudged_at END
+	WHERE id = $1
+	` + taskReturning
+
 func (s *PGTaskStore) Assign(
 	ctx context.Context,
 	taskID uuid.UUID,
 	newAssigneeID uuid.UUID,
+	ifVersion int64,
+	actorID *uuid.UUID,
 	now time.Time,
 ) (*Task, error) {
 	if newAssigneeID == uuid.Nil {
 		return nil, fmt.Errorf("%w: assignee_id cannot be nil", ErrInvalidInput)
 	}
 
-	const q = `
-		UPDATE tasks
-		SET assignee_id = $2,
-		    updated_at  = $3
-		WHERE id = $1
-		` + taskReturning
+	tx, err := s.pool.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("assign task: begin: %w", err)
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
 
-	var o Task
-	if err := s.pool.QueryRow(ctx, q,
+	before, err := s.lockTask(ctx, tx, taskID)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkVersion(before, ifVersion); err != nil {
+		return nil, err
+	}
+	o, err := s.scanTaskRow(tx.QueryRow(ctx, assignTask,
 		taskID,
 		newAssigneeID,
 		now.UTC(),
-	).Scan(
-		&o.ID,
-		&o.TeamID,
-		&o.Title,
-		&o.Description,
-		&o.ReporterID,
-		&o.AssigneeID,
-		&o.DueAt,
-		&o.ReminderSentAt,
-		&o.Status,
-		&o.CreatedAt,
-		&o.UpdatedAt,
-	); err != nil {
-		if errors.Is(err, pgx.ErrNoRows) {
-			return nil, ErrTaskNotFound
-		}
+	))
+	if err != nil {
 		return nil, fmt.Errorf("assign task: %w", err)
 	}
+	if err := recordEvent(ctx, tx, before, o, types.TaskEventAssigned, actorID, now); err != nil {
+		return nil, err
+	}
 
-	return &o, nil
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("assign task: commit: %w", err)
+	}
+	return o, nil
 }
 
 func (s *PGTaskStore) UpdateStatus(
 	ctx context.Context,
 	taskID uuid.UUID,
 	newStatus TaskStatus,
+	ifVersion int64,
+	actorID *uuid.UUID,
 	now time.Time,
 ) (*Task, error) {
 	switch newStatus {
@@ -344,31 +621,92 @@ func (s *PGTaskStore) UpdateStatus(
 		WHERE id = $1
 		` + taskReturning
 
-	var o Task
-	if err := s.pool.QueryRow(ctx, q,
+	tx, err := s.pool.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("update task status: begin: %w", err)
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	before, err := s.lockTask(ctx, tx, taskID)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkVersion(before, ifVersion); err != nil {
+		return nil, err
+	}
+	o, err := s.scanTaskRow(tx.QueryRow(ctx, q,
 		taskID,
 		string(newStatus),
 		now.UTC(),
-	).Scan(
-		&o.ID,
-		&o.TeamID,
-		&o.Title,
-		&o.Description,
-		&o.ReporterID,
-		&o.AssigneeID,
-		&o.DueAt,
-		&o.ReminderSentAt,
-		&o.Status,
-		&o.CreatedAt,
-		&o.UpdatedAt,
-	); err != nil {
-		if errors.Is(err, pgx.ErrNoRows) {
-			return nil, ErrTaskNotFound
-		}
+	))
+	if err != nil {
 		return nil, fmt.Errorf("update task status: %w", err)
 	}
+	if err := recordEvent(ctx, tx, before, o, types.TaskEventStatusChanged, actorID, now); err != nil {
+		return nil, err
+	}
 
-	return &o, nil
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("update task status: commit: %w", err)
+	}
+	return o, nil
+}
+
+// SetWorkflowState moves a task to a custom workflow state. status is kept
+// in sync with the state's category so reminders, stale reports and metrics
+// keep working for teams on a custom workflow.
+func (s *PGTaskStore) SetWorkflowState(
+	ctx context.Context,
+	taskID uuid.UUID,
+	state string,
+	category TaskStatus,
+	actorID *uuid.UUID,
+	now time.Time,
+) (*Task, error) {
+	switch category {
+	case OpenStatus, InProgressStatus, DoneStatus, CanceledStatus:
+	default:
+		return nil, ErrInvalidStatus
+	}
+	if strings.TrimSpace(state) == "" {
+		return nil, fmt.Errorf("%w: workflow state cannot be empty", ErrInvalidInput)
+	}
+
+	const q = `
+		UPDATE tasks
+		SET workflow_state = $2,
+		    status         = $3,
+		    updated_at     = $4
+		WHERE id = $1
+		` + taskReturning
+
+	tx, err := s.pool.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("set workflow state: begin: %w", err)
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	before, err := s.lockTask(ctx, tx, taskID)
+	if err != nil {
+		return nil, err
+	}
+	o, err := s.scanTaskRow(tx.QueryRow(ctx, q,
+		taskID,
+		state,
+		string(category),
+		now.UTC(),
+	))
+	if err != nil {
+		return nil, fmt.Errorf("set workflow state: %w", err)
+	}
+	if err := recordEvent(ctx, tx, before, o, types.TaskEventStatusChanged, actorID, now); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("set workflow state: commit: %w", err)
+	}
+	return o, nil
 }
 
 func (s *PGTaskStore) GetTaskByID(ctx context.Context, id uuid.UUID) (*Task, error) {
@@ -376,144 +714,474 @@ func (s *PGTaskStore) GetTaskByID(ctx context.Context, id uuid.UUID) (*Task, err
 		SELECT ` + taskColumns + `
 		FROM tasks
 		WHERE id = $1
+		  AND deleted_at IS NULL
 	`
 
-	var o Task
-	if err := s.pool.QueryRow(ctx, q, id).Scan(
-		&o.ID,
-		&o.TeamID,
-		&o.Title,
-		&o.Description,
-		&o.ReporterID,
-		&o.AssigneeID,
-		&o.DueAt,
-		&o.ReminderSentAt,
-		&o.Status,
-		&o.CreatedAt,
-		&o.UpdatedAt,
-	); err != nil {
+	o, err := s.scanTaskRow(s.pool.QueryRow(ctx, q, id))
+	if err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
 			return nil, ErrTaskNotFound
 		}
 		return nil, fmt.Errorf("get task by id: %w", err)
 	}
 
-	return &o, nil
+	return o, nil
 }
 
-func scanTask(rows pgx.Rows) ([]Task, error) {
+// GetVisibleTaskByID hides private tasks viewerID may not see behind
+// ErrTaskNotFound.
+func (s *PGTaskStore) GetVisibleTaskByID(ctx context.Context, id, viewerID uuid.UUID) (*Task, error) {
+	q := `
+		SELECT ` + taskColumns + `
+		FROM tasks
+		WHERE id = $1
+		  AND deleted_at IS NULL
+		  AND ` + visibleTo("tasks", "$2") + `
+	`
+
+	o, err := s.scanTaskRow(s.pool.QueryRow(ctx, q, id, viewerID))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrTaskNotFound
+		}
+		return nil, fmt.Errorf("get visible task by id: %w", err)
+	}
+
+	return o, nil
+}
+
+// visibleTo is the SQL predicate that hides private tasks from everyone but
+// their reporter, assignee and listed viewers. Queries scoped to the viewer
+// as reporter or assignee do not need it.
+func visibleTo(alias, viewerParam string) string {
+	return `(NOT ` + alias + `.is_private
+		OR ` + alias + `.reporter_id = ` + viewerParam + `
+		OR ` + alias + `.assignee_id = ` + viewerParam + `
+		OR EXISTS (
+			SELECT 1 FROM task_viewers tv
+			WHERE tv.task_id = ` + alias + `.id
+			  AND tv.user_id = ` + viewerParam + `
+		))`
+}
+
+// scanTaskRow is the single place that knows the taskColumns order. It
+// reads taskColumns, then any extra columns selected after them into extra.
+func (s *PGTaskStore) scanTaskRow(row pgx.Row, extra ...any) (*Task, error) {
+	var t Task
+	dest := []any{
+		&t.ID,
+		&t.TeamID,
+		&t.Title,
+		&t.Description,
+		&t.ReporterID,
+		&t.AssigneeID,
+		&t.StartAt,
+		&t.DueAt,
+		&t.Status,
+		&t.Priority,
+		&t.EstimateHours,
+		&t.WorkflowState,
+		&t.Private,
+		&t.AssignedAt,
+		&t.AcknowledgedAt,
+		&t.AnnouncementID,
+		&t.CreatedAt,
+		&t.UpdatedAt,
+		&t.Version,
+		&t.CompletedAt,
+		&t.CanceledAt,
+		&t.ArchivedAt,
+		&t.Position,
+		&t.VoteCount,
+		&t.ProjectID,
+		&t.CustomFields,
+	}
+	if err := row.Scan(append(dest, extra...)...); err != nil {
+		return nil, err
+	}
+	if err := s.openDescription(&t); err != nil {
+		return nil, err
+	}
+	return &t, nil
+}
+
+func (s *PGTaskStore) scanTask(rows pgx.Rows) ([]Task, error) {
 	var tasks []Task
 	for rows.Next() {
-		var t Task
-		if err := rows.Scan(
-			&t.ID,
-			&t.TeamID,
-			&t.Title,
-			&t.Description,
-			&t.ReporterID,
-			&t.AssigneeID,
-			&t.DueAt,
-			&t.ReminderSentAt,
-			&t.Status,
-			&t.CreatedAt,
-			&t.UpdatedAt,
-		); err != nil {
+		t, err := s.scanTaskRow(rows)
+		if err != nil {
 			return nil, err
 		}
-		tasks = append(tasks, t)
+		tasks = append(tasks, *t)
 	}
 	return tasks, rows.Err()
 }
 
-func (s *PGTaskStore) GetTasksByAssigneeID(ctx context.Context, assigneeID uuid.UUID) ([]Task, error) {
+func (s *PGTaskStore) GetTasksByAssigneeID(ctx context.Context, assigneeID uuid.UUID, filter TaskFilter, sort SortSpec, page Page) ([]Task, PageInfo, error) {
+	return s.listPage(ctx, taskList{
+		op:    "get tasks by assignee",
+		where: "assignee_id = $1",
+		args:  []any{assigneeID},
+		def:   soonestDue,
+	}, filter, sort, page)
+}
+
+func (s *PGTaskStore) GetTasksByReporterID(ctx context.Context, reporterID uuid.UUID, filter TaskFilter, sort SortSpec, page Page) ([]Task, PageInfo, error) {
+	return s.listPage(ctx, taskList{
+		op:    "get tasks by reporter",
+		where: "reporter_id = $1",
+		args:  []any{reporterID},
+		def:   newestFirst,
+	}, filter, sort, page)
+}
+
+// AdminTaskFilter narrows ListTasksForAdmin. TeamID and Limit are
+// required; zero values of the other fields match everything. Results are
+// newest first; pass the last task's CreatedAt and ID as the Before pair to
+// get the next page.
+type AdminTaskFilter struct {
+	TeamID     uuid.UUID
+	Status     TaskStatus
+	AssigneeID uuid.UUID
+	ReporterID uuid.UUID
+	BeforeAt   time.Time
+	BeforeID   uuid.UUID
+	Limit      int
+}
+
+func (s *PGTaskStore) ListTasksForAdmin(ctx context.Context, f AdminTaskFilter) ([]Task, error) {
+	if f.TeamID == uuid.Nil {
+		return nil, fmt.Errorf("%w: team_id is required", ErrInvalidInput)
+	}
+	if f.Limit < 1 {
+		return nil, fmt.Errorf("%w: limit is required", ErrInvalidInput)
+	}
+
 	const q = `
 		SELECT ` + taskColumns + `
 		FROM tasks
-		WHERE assignee_id = $1
-		ORDER BY due_at
+		WHERE team_id = $1
+		  AND deleted_at IS NULL
+		  AND ($2 = '' OR status::text = $2)
+		  AND ($3 = '00000000-0000-0000-0000-000000000000'::uuid OR assignee_id = $3)
+		  AND ($4 = '00000000-0000-0000-0000-000000000000'::uuid OR reporter_id = $4)
+		  AND ($5::timestamptz IS NULL OR (created_at, id) < ($5, $6))
+		ORDER BY created_at DESC, id DESC
+		LIMIT $7
 	`
 
-	rows, err := s.pool.Query(ctx, q, assigneeID)
+	var beforeAt *time.Time
+	if !f.BeforeAt.IsZero() {
+		t := f.BeforeAt.UTC()
+		beforeAt = &t
+	}
+
+	rows, err := s.pool.Query(ctx, q,
+		f.TeamID, string(f.Status), f.AssigneeID, f.ReporterID, beforeAt, f.BeforeID, f.Limit)
 	if err != nil {
-		return nil, fmt.Errorf("get tasks by assignee: %w", err)
+		return nil, fmt.Errorf("list tasks for admin team_id=%s: %w", f.TeamID, err)
 	}
 	defer rows.Close()
 
-	return scanTask(rows)
+	return s.scanTask(rows)
 }
 
-func (s *PGTaskStore) GetTasksByReporterID(ctx context.Context, reporterID uuid.UUID) ([]Task, error) {
-	const q = `
-		SELECT ` + taskColumns + `
-		FROM tasks
-		WHERE reporter_id = $1
-		ORDER BY created_at DESC
+func (s *PGTaskStore) ListCalendarTasks(ctx context.Context, assigneeID uuid.UUID, doneSince time.Time, limit int) ([]Task, error) {
+	// the feed goes to calendar providers on the strength of a URL token,
+	// so confidential descriptions are never read for it
+	columns := strings.Replace(prefixedTaskColumns("t"), "t.description,",
+		"CASE WHEN COALESCE(ts.confidential, false) THEN NULL ELSE t.description END,", 1)
+	q := `
+		SELECT ` + columns + `
+		FROM tasks t
+		JOIN team_members m ON m.team_id = t.team_id AND m.user_id = t.assignee_id
+		LEFT JOIN team_settings ts ON ts.team_id = t.team_id
+		WHERE t.assignee_id = $1
+		  AND (t.status IN ('open', 'in_progress')
+		       OR (t.status = 'done' AND t.completed_at >= $2))
+		  AND t.deleted_at IS NULL
+		  AND t.archived_at IS NULL
+		ORDER BY t.due_at, t.id
+		LIMIT $3
 	`
 
-	rows, err := s.pool.Query(ctx, q, reporterID)
+	rows, err := s.pool.Query(ctx, q, assigneeID, doneSince.UTC(), limit)
 	if err != nil {
-		return nil, fmt.Errorf("get tasks by reporter: %w", err)
+		return nil, fmt.Errorf("list calendar tasks assignee_id=%s: %w", assigneeID, err)
 	}
 	defer rows.Close()
 
-	return scanTask(rows)
+	return s.scanTask(rows)
 }
 
-func (s *PGTaskStore) GetAllTasks(ctx context.Context) ([]Task, error) {
+// DeleteTask moves the task to its team's trash; see task_trash.go. Held
+// tasks are refused like a hard delete would be.
+func (s *PGTaskStore) DeleteTask(ctx context.Context, id uuid.UUID, actorID *uuid.UUID, now time.Time) error {
 	const q = `
-		SELECT ` + taskColumns + `
+		UPDATE tasks
+		SET deleted_at = $2,
+		    deleted_by = $3
+		WHERE id = $1
+		  AND NOT EXISTS (
+			SELECT 1 FROM legal_holds lh
+			WHERE lh.task_id = tasks.id
+			   OR (lh.task_id IS NULL AND lh.team_id = tasks.team_id)
+		  )
+	`
+
+	tx, err := s.pool.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("delete task: begin: %w", err)
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	before, err := s.lockTask(ctx, tx, id)
+	if err != nil {
+		return err
+	}
+	ct, err := tx.Exec(ctx, q, id, now.UTC(), actorID)
+	if err != nil {
+		return fmt.Errorf("delete task: %w", err)
+	}
+	if ct.RowsAffected() == 0 {
+		return ErrLegalHold
+	}
+	if err := recordEvent(ctx, tx, before, nil, types.TaskEventDeleted, actorID, now); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("delete task: commit: %w", err)
+	}
+	return nil
+}
+
+func (s *PGTaskStore) UpdateDetails(
+	ctx context.Context,
+	taskID uuid.UUID,
+	patch TaskUpdate,
+	ifVersion int64,
+	actorID *uuid.UUID,
+	now time.Time,
+) (*Task, error) {
+	if err := validateTaskUpdate(patch, now); err != nil {
+		return nil, err
+	}
+
+	tx, err := s.pool.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("update task details: begin: %w", err)
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	existing, err := s.lockTask(ctx, tx, taskID)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkVersion(existing, ifVersion); err != nil {
+		return nil, err
+	}
+	before := *existing
+
+	if patch.Title != nil {
+		existing.Title = strings.TrimSpace(*patch.Title)
+	}
+	if patch.Description != nil {
+		existing.Description = patch.Description
+	}
+	if patch.DueAt != nil {
+		existing.DueAt = patch.DueAt.UTC()
+	}
+	switch {
+	case patch.ClearStartAt:
+		existing.StartAt = nil
+	case patch.StartAt != nil:
+		existing.StartAt = utcOrNil(patch.StartAt)
+	}
+	// either end may have moved
+	if err := validateWindow(existing.StartAt, existing.DueAt); err != nil {
+		return nil, err
+	}
+	if patch.Priority != nil {
+		existing.Priority = *patch.Priority
+	}
+	if patch.EstimateHours != nil {
+		existing.EstimateHours = patch.EstimateHours
+	}
+	switch {
+	case patch.ClearProject:
+		existing.ProjectID = nil
+	case patch.ProjectID != nil:
+		existing.ProjectID = patch.ProjectID
+	}
+	if len(patch.CustomFields) > 0 {
+		// copied, as before still points at the old map
+		existing.CustomFields = maps.Clone(existing.CustomFields)
+		if existing.CustomFields == nil {
+			existing.CustomFields = map[string]any{}
+		}
+		for k, v := range patch.CustomFields {
+			if v == nil {
+				delete(existing.CustomFields, k)
+			} else {
+				existing.CustomFields[k] = v
+			}
+		}
+	}
+	existing.UpdatedAt = now.UTC()
+
+	// re-sealed on every write so a team's current confidentiality applies
+	description, err := s.sealDescription(ctx, existing.TeamID, existing.Description)
+	if err != nil {
+		return nil, err
+	}
+
+	const q = `
+		UPDATE tasks
+		SET title          = $2,
+		    description    = $3,
+		    due_at         = $4,
+		    priority       = $5,
+		    estimate_hours = $6,
+		    start_at       = $7,
+		    project_id     = $8,
+		    custom_fields  = $9,
+		    updated_at     = $10
+		WHERE id = $1
+		` + taskReturning
+
+	o, err := s.scanTaskRow(tx.QueryRow(ctx, q,
+		existing.ID,
+		existing.Title,
+		description,
+		existing.DueAt,
+		existing.Priority,
+		existing.EstimateHours,
+		existing.StartAt,
+		existing.ProjectID,
+		existing.CustomFields,
+		existing.UpdatedAt,
+	))
+	if err != nil {
+		if isProjectViolation(err) {
+			return nil, ErrProjectNotInTeam
+		}
+		return nil, fmt.Errorf("update task details: %w", err)
+	}
+	if err := recordEvent(ctx, tx, &before, o, types.TaskEventUpdated, actorID, now); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("update task details: commit: %w", err)
+	}
+	return o, nil
+}
+
+// ListRecentTitlesByReporter feeds the spam guard; it is capped so a runaway
+// client cannot make the check itself expensive.
+func (s *PGTaskStore) ListRecentTitlesByReporter(
+	ctx context.Context,
+	reporterID uuid.UUID,
+	since time.Time,
+) ([]string, error) {
+	const q = `
+		SELECT title
 		FROM tasks
+		WHERE reporter_id = $1
+		  AND created_at >= $2
 		ORDER BY created_at DESC
+		LIMIT 1000
 	`
 
-	rows, err := s.pool.Query(ctx, q)
+	rows, err := s.pool.Query(ctx, q, reporterID, since.UTC())
 	if err != nil {
-		return nil, fmt.Errorf("get all tasks: %w", err)
+		return nil, fmt.Errorf("list recent titles by reporter: %w", err)
 	}
 	defer rows.Close()
 
-	return scanTask(rows)
+	var titles []string
+	for rows.Next() {
+		var t string
+		if err := rows.Scan(&t); err != nil {
+			return nil, err
+		}
+		titles = append(titles, t)
+	}
+	return titles, rows.Err()
 }
 
-func (s *PGTaskStore) FindDueForReminder(
+// ListStaleTasksInTeam returns active tasks untouched since the cutoff,
+// ordered so callers can group them by assignee in one pass.
+func (s *PGTaskStore) ListStaleTasksInTeam(
 	ctx context.Context,
-	from time.Time,
-	before time.Time,
+	teamID uuid.UUID,
+	viewerID uuid.UUID,
+	notUpdatedSince time.Time,
 ) ([]Task, error) {
-	const q = `
+	if teamID == uuid.Nil {
+		return nil, fmt.Errorf("%w: team_id cannot be nil", ErrInvalidInput)
+	}
+
+	q := `
 		SELECT ` + taskColumns + `
 		FROM tasks
-		WHERE due_at > $1
-		  AND due_at <= $2
-		  AND reminder_sent_at IS NULL
+		WHERE team_id = $1
 		  AND status IN ('open', 'in_progress')
-		ORDER BY due_at
+		  AND updated_at < $2
+		  AND deleted_at IS NULL
+		  AND archived_at IS NULL
+		  AND ` + visibleTo("tasks", "$3") + `
+		ORDER BY assignee_id, updated_at
 	`
 
-	rows, err := s.pool.Query(ctx, q, from.UTC(), before.UTC())
+	rows, err := s.pool.Query(ctx, q, teamID, notUpdatedSince.UTC(), viewerID)
 	if err != nil {
-		return nil, fmt.Errorf("find due for reminder: %w", err)
+		return nil, fmt.Errorf("list stale tasks in team: %w", err)
 	}
 	defer rows.Close()
 
-	return scanTask(rows)
+	return s.scanTask(rows)
 }
 
-func (s *PGTaskStore) MarkReminderSent(
-	ctx context.Context,
-	taskID uuid.UUID,
-	when time.Time,
-) error {
+// FindStaleForNudge returns tasks stale beyond their team's configured
+// threshold that have not been nudged since their last update.
+func (s *PGTaskStore) FindStaleForNudge(ctx context.Context, now time.Time) ([]Task, error) {
+	q := `
+		SELECT ` + prefixedTaskColumns("t") + `
+		FROM tasks t
+		JOIN team_settings ts ON ts.team_id = t.team_id
+		WHERE ts.stale_nudge_days IS NOT NULL
+		  AND t.status IN ('open', 'in_progress')
+		  AND t.deleted_at IS NULL
+		  AND t.archived_at IS NULL
+		  AND t.updated_at < $1 - make_interval(days => ts.stale_nudge_days)
+		  AND (t.stale_nudged_at IS NULL OR t.stale_nudged_at < t.updated_at)
+		ORDER BY t.updated_at
+		LIMIT 500
+	`
+
+	rows, err := s.pool.Query(ctx, q, now.UTC())
+	if err != nil {
+		return nil, fmt.Errorf("find stale for nudge: %w", err)
+	}
+	defer rows.Close()
+
+	return s.scanTask(rows)
+}
+
+// MarkStaleNudged deliberately leaves updated_at alone; touching it would
+// reset the staleness clock the nudge is reporting on.
+func (s *PGTaskStore) MarkStaleNudged(ctx context.Context, taskID uuid.UUID, when time.Time) error {
 	const q = `
 		UPDATE tasks
-		SET reminder_sent_at = $2,
-		    updated_at       = $2
+		SET stale_nudged_at = $2
 		WHERE id = $1
 	`
 
 	res, err := s.pool.Exec(ctx, q, taskID, when.UTC())
 	if err != nil {
-		return fmt.Errorf("mark reminder sent: %w", err)
+		return fmt.Errorf("mark stale nudged: %w", err)
 	}
 	if res.RowsAffected() == 0 {
 		return ErrTaskNotFound
@@ -521,81 +1189,176 @@ func (s *PGTaskStore) MarkReminderSent(
 	return nil
 }
 
-func (s *PGTaskStore) DeleteTask(ctx context.Context, id uuid.UUID) error {
-	const q = `DELETE FROM tasks WHERE id = $1`
+// Acknowledge records the assignee's first view of the task. Like
+// MarkStaleNudged it leaves updated_at alone: viewing is not an update.
+// It reports whether this call was the first acknowledgement.
+func (s *PGTaskStore) Acknowledge(ctx context.Context, taskID, assigneeID uuid.UUID, when time.Time) (bool, error) {
+	const q = `
+		UPDATE tasks
+		SET acknowledged_at = $3
+		WHERE id = $1
+		  AND assignee_id = $2
+		  AND acknowledged_at IS NULL
+	`
 
-	ct, err := s.pool.Exec(ctx, q, id)
+	res, err := s.pool.Exec(ctx, q, taskID, assigneeID, when.UTC())
 	if err != nil {
-		return fmt.Errorf("delete task: %w", err)
+		return false, fmt.Errorf("acknowledge task: %w", err)
 	}
-	if ct.RowsAffected() == 0 {
+	return res.RowsAffected() > 0, nil
+}
+
+// FindUnacknowledgedForNudge returns active tasks whose assignee has not
+// opened them within their team's ack_nudge_hours. Each assignment is
+// nudged at most once.
+func (s *PGTaskStore) FindUnacknowledgedForNudge(ctx context.Context, now time.Time) ([]Task, error) {
+	q := `
+		SELECT ` + prefixedTaskColumns("t") + `
+		FROM tasks t
+		JOIN team_settings ts ON ts.team_id = t.team_id
+		WHERE ts.ack_nudge_hours IS NOT NULL
+		  AND t.acknowledged_at IS NULL
+		  AND t.ack_nudged_at IS NULL
+		  AND t.status IN ('open', 'in_progress')
+		  AND t.deleted_at IS NULL
+		  AND t.archived_at IS NULL
+		  AND t.assigned_at < $1 - make_interval(hours => ts.ack_nudge_hours)
+		ORDER BY t.assigned_at
+		LIMIT 500
+	`
+
+	rows, err := s.pool.Query(ctx, q, now.UTC())
+	if err != nil {
+		return nil, fmt.Errorf("find unacknowledged for nudge: %w", err)
+	}
+	defer rows.Close()
+
+	return s.scanTask(rows)
+}
+
+func (s *PGTaskStore) MarkAckNudged(ctx context.Context, taskID uuid.UUID, when time.Time) error {
+	const q = `
+		UPDATE tasks
+		SET ack_nudged_at = $2
+		WHERE id = $1
+	`
+
+	res, err := s.pool.Exec(ctx, q, taskID, when.UTC())
+	if err != nil {
+		return fmt.Errorf("mark ack nudged: %w", err)
+	}
+	if res.RowsAffected() == 0 {
 		return ErrTaskNotFound
 	}
 	return nil
 }
 
-func (s *PGTaskStore) UpdateDetails(
+// SetVisibility replaces the private flag and the viewer list in one
+// transaction. Viewers are kept when a task is made public again so toggling
+// back does not lose them.
+func (s *PGTaskStore) SetVisibility(
 	ctx context.Context,
 	taskID uuid.UUID,
-	patch TaskUpdate,
+	private bool,
+	viewerIDs []uuid.UUID,
+	actorID *uuid.UUID,
 	now time.Time,
 ) (*Task, error) {
-	if err := validateTaskUpdate(patch, now); err != nil {
-		return nil, err
+	tx, err := s.pool.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("set visibility: begin: %w", err)
 	}
+	defer func() { _ = tx.Rollback(ctx) }()
 
-	existing, err := s.GetTaskByID(ctx, taskID)
+	before, err := s.lockTask(ctx, tx, taskID)
 	if err != nil {
 		return nil, err
 	}
-
-	if patch.Title != nil {
-		existing.Title = strings.TrimSpace(*patch.Title)
-	}
-	if patch.Description != nil {
-		existing.Description = patch.Description
-	}
-	if patch.DueAt != nil {
-		existing.DueAt = patch.DueAt.UTC()
+	viewersBefore, err := listViewersTx(ctx, tx, taskID)
+	if err != nil {
+		return nil, fmt.Errorf("set visibility: list viewers: %w", err)
 	}
-	existing.UpdatedAt = now.UTC()
 
 	const q = `
 		UPDATE tasks
-		SET title       = $2,
-		    description = $3,
-		    due_at      = $4,
-		    updated_at  = $5
+		SET is_private = $2,
+		    updated_at = $3
 		WHERE id = $1
 		` + taskReturning
 
-	var o Task
-	if err := s.pool.QueryRow(ctx, q,
-		existing.ID,
-		existing.Title,
-		existing.Description,
-		existing.DueAt,
-		existing.UpdatedAt,
-	).Scan(
-		&o.ID,
-		&o.TeamID,
-		&o.Title,
-		&o.Description,
-		&o.ReporterID,
-		&o.AssigneeID,
-		&o.DueAt,
-		&o.ReminderSentAt,
-		&o.Status,
-		&o.CreatedAt,
-		&o.UpdatedAt,
-	); err != nil {
-		if errors.Is(err, pgx.ErrNoRows) {
-			return nil, ErrTaskNotFound
+	o, err := s.scanTaskRow(tx.QueryRow(ctx, q, taskID, private, now.UTC()))
+	if err != nil {
+		return nil, fmt.Errorf("set visibility: update task: %w", err)
+	}
+
+	if viewerIDs != nil {
+		if _, err := tx.Exec(ctx, `DELETE FROM task_viewers WHERE task_id = $1`, taskID); err != nil {
+			return nil, fmt.Errorf("set visibility: clear viewers: %w", err)
+		}
+		const ins = `
+			INSERT INTO task_viewers (task_id, user_id, created_at)
+			SELECT $1, unnest($2::uuid[]), $3
+			ON CONFLICT DO NOTHING
+		`
+		if _, err := tx.Exec(ctx, ins, taskID, viewerIDs, now.UTC()); err != nil {
+			return nil, fmt.Errorf("set visibility: insert viewers: %w", err)
 		}
-		return nil, fmt.Errorf("update task details: %w", err)
 	}
 
-	return &o, nil
+	changes := taskChanges(before, o)
+	if viewerIDs != nil {
+		viewersAfter, err := listViewersTx(ctx, tx, taskID)
+		if err != nil {
+			return nil, fmt.Errorf("set visibility: list viewers: %w", err)
+		}
+		if !slices.Equal(viewersBefore, viewersAfter) {
+			changes["viewer_ids"] = FieldChange{From: viewersBefore, To: viewersAfter}
+		}
+	}
+	if len(changes) > 0 {
+		if err := insertTaskEvent(ctx, tx, o.ID, o.TeamID, types.TaskEventVisibilityChanged, changes, actorID, now); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("set visibility: commit: %w", err)
+	}
+	return o, nil
+}
+
+func (s *PGTaskStore) ListViewers(ctx context.Context, taskID uuid.UUID) ([]uuid.UUID, error) {
+	const q = `
+		SELECT user_id
+		FROM task_viewers
+		WHERE task_id = $1
+		ORDER BY created_at
+	`
+
+	rows, err := s.pool.Query(ctx, q, taskID)
+	if err != nil {
+		return nil, fmt.Errorf("list task viewers: %w", err)
+	}
+	defer rows.Close()
+
+	ids := []uuid.UUID{}
+	for rows.Next() {
+		var id uuid.UUID
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+	return ids, rows.Err()
+}
+
+// prefixedTaskColumns qualifies taskColumns for queries that join tasks
+func prefixedTaskColumns(alias string) string {
+	cols := strings.Split(taskColumns, ",")
+	for i, c := range cols {
+		cols[i] = alias + "." + strings.TrimSpace(c)
+	}
+	return strings.Join(cols, ", ")
 }
 
 var _ TaskStore = (*PGTaskStore)(nil)
@@ -0,0 +1,135 @@
+package store
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// KeepPerTeam is how many of a team's newest events are kept for replay.
+const KeepPerTeam = 1000
+
+// TeamEvent is one stored realtime event; Data is its JSON payload.
+type TeamEvent struct {
+	Seq  int64
+	Type string
+	Data []byte
+}
+
+// TeamEventStore keeps the newest realtime events of each team, numbered
+// 1, 2, 3... per team, so a stream can resume where it left off. Shared
+// through Postgres so a client may reconnect to any instance.
+type TeamEventStore interface {
+	// Append numbers and stores an event. audience is nil for an event
+	// every member may see.
+	Append(ctx context.Context, teamID uuid.UUID, typ string, audience []uuid.UUID, data []byte, now time.Time) (int64, error)
+	// Since returns the events after afterSeq that userID may see, oldest
+	// first. complete is false when some were already pruned, or afterSeq
+	// is not one of this team's numbers; the client must then refetch.
+	Since(ctx context.Context, teamID, userID uuid.UUID, afterSeq int64) (events []TeamEvent, complete bool, err error)
+	// Prune drops all but the newest keep events of every team.
+	Prune(ctx context.Context, keep int) (int, error)
+}
+
+type PGTeamEventStore struct {
+	pool *pgxpool.Pool
+}
+
+func NewPGTeamEventStore(pool *pgxpool.Pool) *PGTeamEventStore {
+	return &PGTeamEventStore{pool: pool}
+}
+
+var _ TeamEventStore = (*PGTeamEventStore)(nil)
+
+func (s *PGTeamEventStore) Append(ctx context.Context, teamID uuid.UUID, typ string, audience []uuid.UUID, data []byte, now time.Time) (int64, error) {
+	// the counter row serializes appends to one team across instances
+	const q = `
+		WITH c AS (
+			INSERT INTO team_event_counters (team_id, last_seq)
+			VALUES ($1, 1)
+			ON CONFLICT (team_id) DO UPDATE
+			SET last_seq = team_event_counters.last_seq + 1
+			RETURNING last_seq
+		)
+		INSERT INTO team_events (team_id, seq, type, audience, data, created_at)
+		SELECT $1, c.last_seq, $2, $3, $4, $5
+		FROM c
+		RETURNING seq
+	`
+	var seq int64
+	if err := s.pool.QueryRow(ctx, q, teamID, typ, audience, data, now.UTC()).Scan(&seq); err != nil {
+		return 0, fmt.Errorf("append team event team_id=%s: %w", teamID, err)
+	}
+	return seq, nil
+}
+
+func (s *PGTeamEventStore) Since(ctx context.Context, teamID, userID uuid.UUID, afterSeq int64) ([]TeamEvent, bool, error) {
+	const bounds = `
+		SELECT c.last_seq,
+		       (SELECT MIN(e.seq) FROM team_events e WHERE e.team_id = c.team_id)
+		FROM team_event_counters c
+		WHERE c.team_id = $1
+	`
+	var (
+		last   int64
+		oldest *int64
+	)
+	err := s.pool.QueryRow(ctx, bounds, teamID).Scan(&last, &oldest)
+	if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+		return nil, false, fmt.Errorf("team event bounds team_id=%s: %w", teamID, err)
+	}
+	switch {
+	case afterSeq < 0 || afterSeq > last:
+		return nil, false, nil
+	case afterSeq == last:
+		return nil, true, nil
+	case oldest == nil || *oldest > afterSeq+1:
+		return nil, false, nil
+	}
+
+	const q = `
+		SELECT seq, type, data
+		FROM team_events
+		WHERE team_id = $1
+		  AND seq > $2
+		  AND (audience IS NULL OR $3 = ANY(audience))
+		ORDER BY seq
+	`
+	rows, err := s.pool.Query(ctx, q, teamID, afterSeq, userID)
+	if err != nil {
+		return nil, false, fmt.Errorf("list team events team_id=%s: %w", teamID, err)
+	}
+	defer rows.Close()
+
+	var out []TeamEvent
+	for rows.Next() {
+		var e TeamEvent
+		if err := rows.Scan(&e.Seq, &e.Type, &e.Data); err != nil {
+			return nil, false, fmt.Errorf("scan team event: %w", err)
+		}
+		out = append(out, e)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, false, fmt.Errorf("list team events team_id=%s: %w", teamID, err)
+	}
+	return out, true, nil
+}
+
+func (s *PGTeamEventStore) Prune(ctx context.Context, keep int) (int, error) {
+	const q = `
+		DELETE FROM team_events e
+		USING team_event_counters c
+		WHERE e.team_id = c.team_id
+		  AND e.seq <= c.last_seq - $1
+	`
+	tag, err := s.pool.Exec(ctx, q, keep)
+	if err != nil {
+		return 0, fmt.Errorf("prune team events: %w", err)
+	}
+	return int(tag.RowsAffected()), nil
+}
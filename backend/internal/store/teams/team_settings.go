@@ -0,0 +1,126 @@
+package store
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+// AutoAssignStrategy picks the assignee of tasks created without one.
+type AutoAssignStrategy string
+
+const (
+	AutoAssignRoundRobin   AutoAssignStrategy = "round_robin"
+	AutoAssignLeastLoaded  AutoAssignStrategy = "least_loaded"
+	AutoAssignLabelRouting AutoAssignStrategy = "label_routing"
+)
+
+func (s AutoAssignStrategy) Valid() bool {
+	switch s {
+	case AutoAssignRoundRobin, AutoAssignLeastLoaded, AutoAssignLabelRouting:
+		return true
+	default:
+		return false
+	}
+}
+
+// TeamSettings holds per-team feature toggles. A team without a row gets the
+// zero value, which keeps every optional feature switched off.
+type TeamSettings struct {
+	TeamID           uuid.UUID  `json:"team_id"`
+	StaleNudgeDays   *int       `json:"stale_nudge_days"`
+	RequiresApproval bool       `json:"requires_approval"`
+	WorkflowID       *uuid.UUID `json:"workflow_id"`
+	AckNudgeHours    *int       `json:"ack_nudge_hours"`
+	Confidential     bool       `json:"confidential"`
+	// AutoAssign nil keeps the old default: the reporter gets the task
+	AutoAssign *AutoAssignStrategy `json:"auto_assign"`
+	UpdatedAt  time.Time           `json:"updated_at"`
+}
+
+// NOTE: order must match scanTeamSettings
+const teamSettingsColumns = `
+    team_id,
+    stale_nudge_days,
+    requires_approval,
+    workflow_id,
+    ack_nudge_hours,
+    confidential,
+    auto_assign,
+    updated_at
+`
+
+func scanTeamSettings(row pgx.Row, ts *TeamSettings) error {
+	return row.Scan(
+		&ts.TeamID,
+		&ts.StaleNudgeDays,
+		&ts.RequiresApproval,
+		&ts.WorkflowID,
+		&ts.AckNudgeHours,
+		&ts.Confidential,
+		&ts.AutoAssign,
+		&ts.UpdatedAt,
+	)
+}
+
+func (s *PGTeamStore) GetSettings(ctx context.Context, teamID uuid.UUID) (*TeamSettings, error) {
+	const q = `
+		SELECT ` + teamSettingsColumns + `
+		FROM team_settings
+		WHERE team_id = $1
+	`
+
+	var ts TeamSettings
+	err := scanTeamSettings(s.pool.QueryRow(ctx, q, teamID), &ts)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return &TeamSettings{TeamID: teamID}, nil
+		}
+		return nil, fmt.Errorf("GetSettings: query team_id=%s: %w", teamID, err)
+	}
+	return &ts, nil
+}
+
+func (s *PGTeamStore) UpdateSettings(ctx context.Context, ts TeamSettings, now time.Time) (*TeamSettings, error) {
+	if ts.StaleNudgeDays != nil && *ts.StaleNudgeDays < 1 {
+		return nil, fmt.Errorf("UpdateSettings: stale_nudge_days must be positive")
+	}
+	if ts.AckNudgeHours != nil && *ts.AckNudgeHours < 1 {
+		return nil, fmt.Errorf("UpdateSettings: ack_nudge_hours must be positive")
+	}
+	if ts.AutoAssign != nil && !ts.AutoAssign.Valid() {
+		return nil, fmt.Errorf("UpdateSettings: unknown auto_assign %q", *ts.AutoAssign)
+	}
+
+	const q = `
+		INSERT INTO team_settings (team_id, stale_nudge_days, requires_approval, workflow_id, ack_nudge_hours, confidential, auto_assign, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		ON CONFLICT (team_id) DO UPDATE
+		SET stale_nudge_days  = EXCLUDED.stale_nudge_days,
+		    requires_approval = EXCLUDED.requires_approval,
+		    workflow_id       = EXCLUDED.workflow_id,
+		    ack_nudge_hours   = EXCLUDED.ack_nudge_hours,
+		    confidential      = EXCLUDED.confidential,
+		    auto_assign       = EXCLUDED.auto_assign,
+		    updated_at        = EXCLUDED.updated_at
+		RETURNING ` + teamSettingsColumns
+
+	var out TeamSettings
+	if err := scanTeamSettings(s.pool.QueryRow(ctx, q,
+		ts.TeamID,
+		ts.StaleNudgeDays,
+		ts.RequiresApproval,
+		ts.WorkflowID,
+		ts.AckNudgeHours,
+		ts.Confidential,
+		ts.AutoAssign,
+		now.UTC(),
+	), &out); err != nil {
+		return nil, fmt.Errorf("UpdateSettings: upsert team_id=%s: %w", ts.TeamID, err)
+	}
+	return &out, nil
+}
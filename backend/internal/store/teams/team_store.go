@@ -6,13 +6,14 @@ import (
 	"fmt"
 	"time"
 
+	"github.com/diagnosis/interactive-todo/api/types"
 	"github.com/google/uuid"
 	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgconn"
 	"github.com/jackc/pgx/v5/pgxpool"
 )
 
-type TeamRole string
+type TeamRole = types.TeamRole
 
 const (
 	RoleOwner  TeamRole = "owner"
@@ -20,20 +21,9 @@ const (
 	RoleMember TeamRole = "member"
 )
 
-type Team struct {
-	ID        uuid.UUID `json:"id"`
-	Name      string    `json:"name"`
-	OwnerID   uuid.UUID `json:"owner_id"`
-	CreatedAt time.Time `json:"created_at"`
-	UpdatedAt time.Time `json:"updated_at"`
-}
+type Team = types.Team
 
-type TeamMember struct {
-	TeamID    uuid.UUID `json:"team_id"`
-	UserID    uuid.UUID `json:"user_id"`
-	Role      TeamRole  `json:"role"`
-	CreatedAt time.Time `json:"created_at"`
-}
+type TeamMember = types.TeamMember
 
 var (
 	ErrTeamNameTaken = errors.New("team name already taken")
@@ -44,9 +34,16 @@ type TeamStore interface {
 	AddMember(ctx context.Context, teamID, inviterID, userID uuid.UUID, role TeamRole, now time.Time) error
 	IsMember(ctx context.Context, teamID, userID uuid.UUID) (bool, error)
 	IsOwnerOrAdmin(ctx context.Context, teamID, userID uuid.UUID) (bool, error)
+	IsOwner(ctx context.Context, teamID, userID uuid.UUID) (bool, error)
 	RemoveMemberFromTeam(ctx context.Context, teamID uuid.UUID, userID uuid.UUID) (bool, error)
 	ListMembersInTeam(ctx context.Context, teamID uuid.UUID) ([]TeamMember, error)
+	// MemberIDsByEmail maps the lowercased email of every member to their
+	// user id.
+	MemberIDsByEmail(ctx context.Context, teamID uuid.UUID) (map[string]uuid.UUID, error)
 	ListTeamsForUser(ctx context.Context, userID uuid.UUID) ([]Team, error)
+
+	GetSettings(ctx context.Context, teamID uuid.UUID) (*TeamSettings, error)
+	UpdateSettings(ctx context.Context, settings TeamSettings, now time.Time) (*TeamSettings, error)
 }
 
 type PGTeamStore struct {
@@ -136,6 +133,37 @@ func (s *PGTeamStore) ListMembersInTeam(ctx context.Context, teamID uuid.UUID) (
 	return members, nil
 }
 
+func (s *PGTeamStore) MemberIDsByEmail(ctx context.Context, teamID uuid.UUID) (map[string]uuid.UUID, error) {
+	const q = `
+		SELECT lower(u.email), u.id
+		FROM team_members tm
+		JOIN users u ON u.id = tm.user_id
+		WHERE tm.team_id = $1
+	`
+
+	rows, err := s.pool.Query(ctx, q, teamID)
+	if err != nil {
+		return nil, fmt.Errorf("MemberIDsByEmail: query for team_id=%s: %w", teamID, err)
+	}
+	defer rows.Close()
+
+	ids := make(map[string]uuid.UUID)
+	for rows.Next() {
+		var (
+			email string
+			id    uuid.UUID
+		)
+		if err := rows.Scan(&email, &id); err != nil {
+			return nil, fmt.Errorf("MemberIDsByEmail: scan row for team_id=%s: %w", teamID, err)
+		}
+		ids[email] = id
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("MemberIDsByEmail: rows error for team_id=%s: %w", teamID, err)
+	}
+	return ids, nil
+}
+
 func (s *PGTeamStore) CreateTeam(ctx context.Context, ownerID uuid.UUID, name string, now time.Time) (*Team, error) {
 	const insertTeam = `
 		INSERT INTO teams (name, owner_id, created_at, updated_at)
@@ -223,6 +251,25 @@ func (s *PGTeamStore) IsOwnerOrAdmin(ctx context.Context, teamID, userID uuid.UU
 	return true, nil
 }
 
+func (s *PGTeamStore) IsOwner(ctx context.Context, teamID, userID uuid.UUID) (bool, error) {
+	const q = `
+		SELECT 1 FROM team_members
+		WHERE team_id = $1 AND user_id = $2
+		  AND role = 'owner'
+		LIMIT 1;
+	`
+
+	var dummy int
+	err := s.pool.QueryRow(ctx, q, teamID, userID).Scan(&dummy)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return false, nil
+		}
+		return false, fmt.Errorf("IsOwner: query team_id=%s user_id=%s: %w", teamID, userID, err)
+	}
+	return true, nil
+}
+
 func (s *PGTeamStore) AddMember(
 	ctx context.Context,
 	teamID, inviterID, userID uuid.UUID,
@@ -0,0 +1,98 @@
+package store
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// DenylistStore blocks access tokens before they expire. Rows only need to
+// live as long as the tokens they block, so every row carries expires_at
+// and Purge drops the rest.
+type DenylistStore interface {
+	// Deny blocks one token by its jti.
+	Deny(ctx context.Context, jti, userID uuid.UUID, expiresAt time.Time) error
+	// DenyAllForUser blocks every token of userID issued before
+	// revokedBefore. expiresAt is when the last of them expires.
+	DenyAllForUser(ctx context.Context, userID uuid.UUID, revokedBefore, expiresAt time.Time) error
+	// IsDenied also rejects tokens whose version no longer matches
+	// users.token_version, and tokens of deleted users.
+	IsDenied(ctx context.Context, jti, userID uuid.UUID, issuedAt time.Time, tokenVersion int) (bool, error)
+	Purge(ctx context.Context, now time.Time) (int, error)
+}
+
+type PGDenylistStore struct {
+	pool *pgxpool.Pool
+}
+
+func NewPGDenylistStore(pool *pgxpool.Pool) *PGDenylistStore {
+	return &PGDenylistStore{pool: pool}
+}
+
+func (s *PGDenylistStore) Deny(ctx context.Context, jti, userID uuid.UUID, expiresAt time.Time) error {
+	const q = `
+		INSERT INTO access_token_denylist (jti, user_id, expires_at)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (jti) DO NOTHING
+	`
+	if _, err := s.pool.Exec(ctx, q, jti, userID, expiresAt.UTC()); err != nil {
+		return fmt.Errorf("deny access token jti=%s: %w", jti, err)
+	}
+	return nil
+}
+
+// DenyAllForUser only ever moves the cutoff forward.
+func (s *PGDenylistStore) DenyAllForUser(ctx context.Context, userID uuid.UUID, revokedBefore, expiresAt time.Time) error {
+	const q = `
+		INSERT INTO access_token_user_cutoffs (user_id, revoked_before, expires_at)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (user_id) DO UPDATE
+		SET revoked_before = GREATEST(access_token_user_cutoffs.revoked_before, EXCLUDED.revoked_before),
+		    expires_at     = GREATEST(access_token_user_cutoffs.expires_at, EXCLUDED.expires_at)
+	`
+	if _, err := s.pool.Exec(ctx, q, userID, revokedBefore.UTC(), expiresAt.UTC()); err != nil {
+		return fmt.Errorf("deny access tokens user_id=%s: %w", userID, err)
+	}
+	return nil
+}
+
+// IsDenied runs on every authenticated request, so all checks share one
+// round trip.
+func (s *PGDenylistStore) IsDenied(ctx context.Context, jti, userID uuid.UUID, issuedAt time.Time, tokenVersion int) (bool, error) {
+	const q = `
+		SELECT EXISTS (SELECT 1 FROM access_token_denylist WHERE jti = $1)
+		    OR EXISTS (
+		        SELECT 1 FROM access_token_user_cutoffs
+		        WHERE user_id = $2 AND revoked_before > $3
+		    )
+		    OR NOT EXISTS (
+		        SELECT 1 FROM users WHERE id = $2 AND token_version = $4
+		    )
+	`
+	var denied bool
+	if err := s.pool.QueryRow(ctx, q, jti, userID, issuedAt.UTC(), tokenVersion).Scan(&denied); err != nil {
+		return false, fmt.Errorf("check access token jti=%s: %w", jti, err)
+	}
+	return denied, nil
+}
+
+func (s *PGDenylistStore) Purge(ctx context.Context, now time.Time) (int, error) {
+	const q = `
+		WITH tokens AS (
+			DELETE FROM access_token_denylist WHERE expires_at <= $1 RETURNING 1
+		), cutoffs AS (
+			DELETE FROM access_token_user_cutoffs WHERE expires_at <= $1 RETURNING 1
+		)
+		SELECT (SELECT count(*) FROM tokens) + (SELECT count(*) FROM cutoffs)
+	`
+	var n int
+	if err := s.pool.QueryRow(ctx, q, now.UTC()).Scan(&n); err != nil {
+		return 0, fmt.Errorf("purge access token denylist: %w", err)
+	}
+	return n, nil
+}
+
+var _ DenylistStore = (*PGDenylistStore)(nil)
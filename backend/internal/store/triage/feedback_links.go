@@ -0,0 +1,107 @@
+package store
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/diagnosis/interactive-todo/api/types"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+var ErrFeedbackLinkNotFound = errors.New("feedback link not found")
+
+type FeedbackLink = types.FeedbackLink
+
+// NOTE: order must match scanFeedbackLink
+const feedbackLinkColumns = `
+    l.team_id,
+    t.name,
+    l.prompt,
+    l.created_by,
+    l.created_at
+`
+
+// SetFeedbackLink gives the team a link with tokenHash, replacing its
+// previous one, which stops working.
+func (s *PGTriageStore) SetFeedbackLink(
+	ctx context.Context,
+	teamID uuid.UUID,
+	tokenHash string,
+	prompt *string,
+	createdBy uuid.UUID,
+	now time.Time,
+) (*FeedbackLink, error) {
+	const q = `
+		INSERT INTO team_feedback_links (team_id, token_hash, prompt, created_by, created_at)
+		VALUES ($1, $2, $3, $4, $5)
+		ON CONFLICT (team_id) DO UPDATE
+		SET token_hash = EXCLUDED.token_hash,
+		    prompt     = EXCLUDED.prompt,
+		    created_by = EXCLUDED.created_by,
+		    created_at = EXCLUDED.created_at
+	`
+
+	if _, err := s.pool.Exec(ctx, q, teamID, tokenHash, prompt, createdBy, now.UTC()); err != nil {
+		return nil, fmt.Errorf("set feedback link team_id=%s: %w", teamID, err)
+	}
+	return s.GetFeedbackLink(ctx, teamID)
+}
+
+func (s *PGTriageStore) GetFeedbackLink(ctx context.Context, teamID uuid.UUID) (*FeedbackLink, error) {
+	q := `
+		SELECT ` + feedbackLinkColumns + `
+		FROM team_feedback_links l
+		JOIN teams t ON t.id = l.team_id
+		WHERE l.team_id = $1
+	`
+
+	link, err := scanFeedbackLink(s.pool.QueryRow(ctx, q, teamID))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrFeedbackLinkNotFound
+		}
+		return nil, fmt.Errorf("get feedback link team_id=%s: %w", teamID, err)
+	}
+	return link, nil
+}
+
+// FeedbackLinkByToken finds the link a public submission was sent to.
+func (s *PGTriageStore) FeedbackLinkByToken(ctx context.Context, tokenHash string) (*FeedbackLink, error) {
+	q := `
+		SELECT ` + feedbackLinkColumns + `
+		FROM team_feedback_links l
+		JOIN teams t ON t.id = l.team_id
+		WHERE l.token_hash = $1
+	`
+
+	link, err := scanFeedbackLink(s.pool.QueryRow(ctx, q, tokenHash))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrFeedbackLinkNotFound
+		}
+		return nil, fmt.Errorf("get feedback link by token: %w", err)
+	}
+	return link, nil
+}
+
+func (s *PGTriageStore) DeleteFeedbackLink(ctx context.Context, teamID uuid.UUID) error {
+	ct, err := s.pool.Exec(ctx, `DELETE FROM team_feedback_links WHERE team_id = $1`, teamID)
+	if err != nil {
+		return fmt.Errorf("delete feedback link team_id=%s: %w", teamID, err)
+	}
+	if ct.RowsAffected() == 0 {
+		return ErrFeedbackLinkNotFound
+	}
+	return nil
+}
+
+func scanFeedbackLink(row pgx.Row) (*FeedbackLink, error) {
+	var l FeedbackLink
+	if err := row.Scan(&l.TeamID, &l.TeamName, &l.Prompt, &l.CreatedBy, &l.CreatedAt); err != nil {
+		return nil, err
+	}
+	return &l, nil
+}
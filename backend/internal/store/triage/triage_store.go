@@ -0,0 +1,280 @@
+package store
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/diagnosis/interactive-todo/api/types"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type Status = types.TriageStatus
+
+type Source = types.TriageSource
+
+const (
+	SourceForm     Source = "form"
+	SourceFeedback Source = "feedback"
+)
+
+const (
+	StatusPending  Status = "pending"
+	StatusAccepted Status = "accepted"
+	StatusRejected Status = "rejected"
+)
+
+type Item = types.TriageItem
+
+var (
+	ErrItemNotFound   = errors.New("triage item not found")
+	ErrItemNotPending = errors.New("triage item already decided")
+)
+
+type TriageStore interface {
+	Create(ctx context.Context, item Item, now time.Time) (*Item, error)
+	Get(ctx context.Context, id uuid.UUID) (*Item, error)
+	ListForTeam(ctx context.Context, teamID uuid.UUID, status Status) ([]Item, error)
+	// MarkAccepted claims a pending item so concurrent accepts cannot both
+	// create a task. Callers link the created task with LinkTask, or Reopen
+	// the item if task creation fails.
+	MarkAccepted(ctx context.Context, id, deciderID uuid.UUID, now time.Time) (*Item, error)
+	LinkTask(ctx context.Context, id, taskID uuid.UUID) (*Item, error)
+	Reopen(ctx context.Context, id uuid.UUID) error
+	Reject(ctx context.Context, id, deciderID uuid.UUID, reason string, now time.Time) (*Item, error)
+	// RecentFeedbackTitles feeds the spam check of anonymous feedback.
+	RecentFeedbackTitles(ctx context.Context, teamID uuid.UUID, since time.Time) ([]string, error)
+
+	// Anonymous feedback links, see feedback_links.go.
+	SetFeedbackLink(ctx context.Context, teamID uuid.UUID, tokenHash string, prompt *string, createdBy uuid.UUID, now time.Time) (*FeedbackLink, error)
+	GetFeedbackLink(ctx context.Context, teamID uuid.UUID) (*FeedbackLink, error)
+	FeedbackLinkByToken(ctx context.Context, tokenHash string) (*FeedbackLink, error)
+	DeleteFeedbackLink(ctx context.Context, teamID uuid.UUID) error
+}
+
+// NOTE: order must match scanItem
+const itemColumns = `
+    id,
+    team_id,
+    form_id,
+    title,
+    description,
+    submitted_by,
+    source,
+    status,
+    task_id,
+    decided_by,
+    decided_at,
+    reason,
+    created_at
+`
+
+type PGTriageStore struct {
+	pool *pgxpool.Pool
+}
+
+func NewPGTriageStore(pool *pgxpool.Pool) *PGTriageStore {
+	return &PGTriageStore{pool: pool}
+}
+
+// Create adds a pending item; Source defaults to form.
+func (s *PGTriageStore) Create(ctx context.Context, item Item, now time.Time) (*Item, error) {
+	const q = `
+		INSERT INTO triage_items (team_id, form_id, title, description, submitted_by, source, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		RETURNING ` + itemColumns
+
+	if item.Source == "" {
+		item.Source = SourceForm
+	}
+	out, err := scanItem(s.pool.QueryRow(ctx, q,
+		item.TeamID,
+		item.FormID,
+		item.Title,
+		item.Description,
+		item.SubmittedBy,
+		string(item.Source),
+		now.UTC(),
+	))
+	if err != nil {
+		return nil, fmt.Errorf("create triage item team_id=%s: %w", item.TeamID, err)
+	}
+	return out, nil
+}
+
+func (s *PGTriageStore) Get(ctx context.Context, id uuid.UUID) (*Item, error) {
+	const q = `
+		SELECT ` + itemColumns + `
+		FROM triage_items
+		WHERE id = $1
+	`
+
+	out, err := scanItem(s.pool.QueryRow(ctx, q, id))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrItemNotFound
+		}
+		return nil, fmt.Errorf("get triage item id=%s: %w", id, err)
+	}
+	return out, nil
+}
+
+// ListForTeam returns the oldest submissions first so the inbox is worked
+// in arrival order.
+func (s *PGTriageStore) ListForTeam(ctx context.Context, teamID uuid.UUID, status Status) ([]Item, error) {
+	const q = `
+		SELECT ` + itemColumns + `
+		FROM triage_items
+		WHERE team_id = $1
+		  AND status = $2
+		ORDER BY created_at
+		LIMIT 500
+	`
+
+	rows, err := s.pool.Query(ctx, q, teamID, string(status))
+	if err != nil {
+		return nil, fmt.Errorf("list triage items team_id=%s: %w", teamID, err)
+	}
+	defer rows.Close()
+
+	var out []Item
+	for rows.Next() {
+		it, err := scanItem(rows)
+		if err != nil {
+			return nil, fmt.Errorf("scan triage item: %w", err)
+		}
+		out = append(out, *it)
+	}
+	return out, rows.Err()
+}
+
+func (s *PGTriageStore) MarkAccepted(ctx context.Context, id, deciderID uuid.UUID, now time.Time) (*Item, error) {
+	const q = `
+		UPDATE triage_items
+		SET status     = 'accepted',
+		    decided_by = $2,
+		    decided_at = $3
+		WHERE id = $1
+		  AND status = 'pending'
+		RETURNING ` + itemColumns
+
+	return s.decide(ctx, id, "accept", q, id, deciderID, now.UTC())
+}
+
+func (s *PGTriageStore) Reject(ctx context.Context, id, deciderID uuid.UUID, reason string, now time.Time) (*Item, error) {
+	const q = `
+		UPDATE triage_items
+		SET status     = 'rejected',
+		    decided_by = $2,
+		    decided_at = $3,
+		    reason     = $4
+		WHERE id = $1
+		  AND status = 'pending'
+		RETURNING ` + itemColumns
+
+	return s.decide(ctx, id, "reject", q, id, deciderID, now.UTC(), reason)
+}
+
+// decide runs a pending-only update and tells "gone" apart from "already
+// decided" when no row matched.
+func (s *PGTriageStore) decide(ctx context.Context, id uuid.UUID, op, q string, args ...any) (*Item, error) {
+	out, err := scanItem(s.pool.QueryRow(ctx, q, args...))
+	if err == nil {
+		return out, nil
+	}
+	if !errors.Is(err, pgx.ErrNoRows) {
+		return nil, fmt.Errorf("%s triage item id=%s: %w", op, id, err)
+	}
+	if _, err := s.Get(ctx, id); err != nil {
+		return nil, err
+	}
+	return nil, ErrItemNotPending
+}
+
+func (s *PGTriageStore) LinkTask(ctx context.Context, id, taskID uuid.UUID) (*Item, error) {
+	const q = `
+		UPDATE triage_items
+		SET task_id = $2
+		WHERE id = $1
+		RETURNING ` + itemColumns
+
+	out, err := scanItem(s.pool.QueryRow(ctx, q, id, taskID))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrItemNotFound
+		}
+		return nil, fmt.Errorf("link triage item id=%s: %w", id, err)
+	}
+	return out, nil
+}
+
+func (s *PGTriageStore) Reopen(ctx context.Context, id uuid.UUID) error {
+	const q = `
+		UPDATE triage_items
+		SET status     = 'pending',
+		    decided_by = NULL,
+		    decided_at = NULL
+		WHERE id = $1
+		  AND task_id IS NULL
+	`
+
+	if _, err := s.pool.Exec(ctx, q, id); err != nil {
+		return fmt.Errorf("reopen triage item id=%s: %w", id, err)
+	}
+	return nil
+}
+
+func (s *PGTriageStore) RecentFeedbackTitles(ctx context.Context, teamID uuid.UUID, since time.Time) ([]string, error) {
+	const q = `
+		SELECT title
+		FROM triage_items
+		WHERE team_id = $1
+		  AND source = 'feedback'
+		  AND created_at >= $2
+		ORDER BY created_at DESC
+		LIMIT 500
+	`
+
+	rows, err := s.pool.Query(ctx, q, teamID, since.UTC())
+	if err != nil {
+		return nil, fmt.Errorf("recent feedback titles team_id=%s: %w", teamID, err)
+	}
+	defer rows.Close()
+
+	var titles []string
+	for rows.Next() {
+		var t string
+		if err := rows.Scan(&t); err != nil {
+			return nil, err
+		}
+		titles = append(titles, t)
+	}
+	return titles, rows.Err()
+}
+
+func scanItem(row pgx.Row) (*Item, error) {
+	var it Item
+	if err := row.Scan(
+		&it.ID,
+		&it.TeamID,
+		&it.FormID,
+		&it.Title,
+		&it.Description,
+		&it.SubmittedBy,
+		&it.Source,
+		&it.Status,
+		&it.TaskID,
+		&it.DecidedBy,
+		&it.DecidedAt,
+		&it.Reason,
+		&it.CreatedAt,
+	); err != nil {
+		return nil, err
+	}
+	return &it, nil
+}
+
+var _ TriageStore = (*PGTriageStore)(nil)
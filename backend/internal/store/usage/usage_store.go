@@ -0,0 +1,143 @@
+package store
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/diagnosis/interactive-todo/api/types"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// Count is the requests one user made on one UTC day since the last flush.
+type Count struct {
+	UserID     uuid.UUID
+	Day        time.Time
+	Requests   int64
+	LastSeenAt time.Time
+}
+
+type UserUsage = types.UserUsage
+
+// UsageFilter selects users for ListUsers. Requests are summed from Since.
+// With InactiveSince set, only users not seen since then (or never) are
+// listed, longest idle first; otherwise the busiest come first.
+type UsageFilter struct {
+	Since         time.Time
+	InactiveSince *time.Time
+	Limit         int
+	Offset        int
+}
+
+type UsageStore interface {
+	// Add adds counts to the stored totals.
+	Add(ctx context.Context, counts []Count) error
+	ListUsers(ctx context.Context, f UsageFilter) ([]UserUsage, error)
+	// PurgeBefore deletes the days before day.
+	PurgeBefore(ctx context.Context, day time.Time) (int64, error)
+}
+
+type PGUsageStore struct {
+	pool *pgxpool.Pool
+}
+
+func NewPGUsageStore(pool *pgxpool.Pool) *PGUsageStore {
+	return &PGUsageStore{pool: pool}
+}
+
+func (s *PGUsageStore) Add(ctx context.Context, counts []Count) error {
+	if len(counts) == 0 {
+		return nil
+	}
+	// The join drops users deleted since their requests were counted.
+	const q = `
+		INSERT INTO user_api_usage (user_id, day, request_count, last_seen_at)
+		SELECT c.user_id, c.day, c.requests, c.last_seen_at
+		FROM unnest($1::uuid[], $2::date[], $3::bigint[], $4::timestamptz[])
+		     AS c(user_id, day, requests, last_seen_at)
+		JOIN users u ON u.id = c.user_id
+		ON CONFLICT (user_id, day) DO UPDATE
+		SET request_count = user_api_usage.request_count + EXCLUDED.request_count,
+		    last_seen_at  = GREATEST(user_api_usage.last_seen_at, EXCLUDED.last_seen_at)
+	`
+	var (
+		userIDs  = make([]uuid.UUID, len(counts))
+		days     = make([]time.Time, len(counts))
+		requests = make([]int64, len(counts))
+		seen     = make([]time.Time, len(counts))
+	)
+	for i, c := range counts {
+		userIDs[i] = c.UserID
+		days[i] = c.Day.UTC()
+		requests[i] = c.Requests
+		seen[i] = c.LastSeenAt.UTC()
+	}
+	if _, err := s.pool.Exec(ctx, q, userIDs, days, requests, seen); err != nil {
+		return fmt.Errorf("add api usage: %w", err)
+	}
+	return nil
+}
+
+func (s *PGUsageStore) ListUsers(ctx context.Context, f UsageFilter) ([]UserUsage, error) {
+	if f.Limit <= 0 {
+		return nil, fmt.Errorf("list api usage: limit is required")
+	}
+
+	q := `
+		SELECT u.id, u.email, u.user_type, COALESCE(w.requests, 0), s.last_seen_at, u.created_at
+		FROM users u
+		LEFT JOIN (
+			SELECT user_id, SUM(request_count)::bigint AS requests
+			FROM user_api_usage
+			WHERE day >= $1
+			GROUP BY user_id
+		) w ON w.user_id = u.id
+		LEFT JOIN (
+			SELECT user_id, MAX(last_seen_at) AS last_seen_at
+			FROM user_api_usage
+			GROUP BY user_id
+		) s ON s.user_id = u.id
+	`
+	args := []any{f.Since.UTC()}
+	if f.InactiveSince != nil {
+		args = append(args, f.InactiveSince.UTC())
+		q += `
+		WHERE s.last_seen_at IS NULL OR s.last_seen_at < $2
+		ORDER BY s.last_seen_at NULLS FIRST, u.email`
+	} else {
+		q += `
+		ORDER BY w.requests DESC NULLS LAST, u.email`
+	}
+	args = append(args, f.Limit, f.Offset)
+	q += fmt.Sprintf(" LIMIT $%d OFFSET $%d", len(args)-1, len(args))
+
+	rows, err := s.pool.Query(ctx, q, args...)
+	if err != nil {
+		return nil, fmt.Errorf("list api usage: %w", err)
+	}
+	defer rows.Close()
+
+	out := []UserUsage{}
+	for rows.Next() {
+		var u UserUsage
+		if err := rows.Scan(&u.UserID, &u.Email, &u.UserType, &u.Requests, &u.LastSeenAt, &u.CreatedAt); err != nil {
+			return nil, fmt.Errorf("list api usage: scan: %w", err)
+		}
+		out = append(out, u)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("list api usage: %w", err)
+	}
+	return out, nil
+}
+
+func (s *PGUsageStore) PurgeBefore(ctx context.Context, day time.Time) (int64, error) {
+	ct, err := s.pool.Exec(ctx, `DELETE FROM user_api_usage WHERE day < $1`, day.UTC())
+	if err != nil {
+		return 0, fmt.Errorf("purge api usage: %w", err)
+	}
+	return ct.RowsAffected(), nil
+}
+
+var _ UsageStore = (*PGUsageStore)(nil)
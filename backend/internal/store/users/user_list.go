@@ -0,0 +1,114 @@
+package store
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/google/uuid"
+)
+
+// UserSort names an allowed ordering for ListUsers; "-" prefix is
+// descending.
+type UserSort string
+
+const (
+	SortEmail         UserSort = "email"
+	SortEmailDesc     UserSort = "-email"
+	SortCreatedAt     UserSort = "created_at"
+	SortCreatedAtDesc UserSort = "-created_at"
+)
+
+// user ids break ties so offsets stay stable between pages
+var userSortSQL = map[UserSort]string{
+	SortEmail:         "u.email ASC, u.id ASC",
+	SortEmailDesc:     "u.email DESC, u.id DESC",
+	SortCreatedAt:     "u.created_at ASC, u.id ASC",
+	SortCreatedAtDesc: "u.created_at DESC, u.id DESC",
+}
+
+var ErrInvalidSort = errors.New("invalid sort")
+
+func ParseUserSort(raw string) (UserSort, error) {
+	if raw == "" {
+		return SortEmail, nil
+	}
+	s := UserSort(raw)
+	if _, ok := userSortSQL[s]; !ok {
+		return "", ErrInvalidSort
+	}
+	return s, nil
+}
+
+// UserFilter selects a page of users. Limit is required.
+type UserFilter struct {
+	UserType *UserType
+	// SharesTeamWith limits the list to users in at least one team with
+	// this user (and the user themself); nil lists everyone
+	SharesTeamWith *uuid.UUID
+	Sort           UserSort
+	Limit          int
+	Offset         int
+}
+
+func (s *PGUserStore) ListUsers(ctx context.Context, f UserFilter) ([]User, error) {
+	if f.Limit <= 0 {
+		return nil, errors.New("list users: limit is required")
+	}
+	order, ok := userSortSQL[f.Sort]
+	if !ok {
+		return nil, ErrInvalidSort
+	}
+
+	var (
+		where []string
+		args  []any
+	)
+	if f.UserType != nil {
+		args = append(args, *f.UserType)
+		where = append(where, fmt.Sprintf("u.user_type = $%d", len(args)))
+	}
+	if f.SharesTeamWith != nil {
+		args = append(args, *f.SharesTeamWith)
+		n := len(args)
+		where = append(where, fmt.Sprintf(`(u.id = $%d OR EXISTS (
+			SELECT 1
+			FROM team_members mine
+			JOIN team_members theirs ON theirs.team_id = mine.team_id
+			WHERE mine.user_id = $%d AND theirs.user_id = u.id
+		))`, n, n))
+	}
+
+	q := `SELECT u.id, u.email, u.password_hash, u.user_type, u.token_version, u.created_at, u.updated_at
+		FROM users u`
+	if len(where) > 0 {
+		q += " WHERE " + strings.Join(where, " AND ")
+	}
+	args = append(args, f.Limit, f.Offset)
+	q += fmt.Sprintf(" ORDER BY %s LIMIT $%d OFFSET $%d", order, len(args)-1, len(args))
+
+	rows, err := s.Pool.Query(ctx, q, args...)
+	if err != nil {
+		return nil, fmt.Errorf("list users: %w", err)
+	}
+	defer rows.Close()
+
+	var users []User
+	for rows.Next() {
+		var user User
+		if err := rows.Scan(
+			&user.ID,
+			&user.Email,
+			&user.PasswordHash,
+			&user.UserType,
+			&user.TokenVersion,
+			&user.CreatedAt,
+			&user.UpdatedAt,
+		); err != nil {
+			return nil, fmt.Errorf("list users: scan: %w", err)
+		}
+		users = append(users, user)
+	}
+	return users, rows.Err()
+}
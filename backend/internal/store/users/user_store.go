@@ -5,13 +5,14 @@ import (
 	"errors"
 	"time"
 
+	"github.com/diagnosis/interactive-todo/api/types"
 	"github.com/google/uuid"
 	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgconn"
 	"github.com/jackc/pgx/v5/pgxpool"
 )
 
-type UserType string
+type UserType = types.UserType
 
 const (
 	TypeEmployee    UserType = "employee"
@@ -23,9 +24,14 @@ type User struct {
 	ID           uuid.UUID `json:"id"`
 	Email        string    `json:"email"`
 	PasswordHash string    `json:"-"`
-	UserType     UserType  `json:"user_type"`
-	CreatedAt    time.Time `json:"created_at"`
-	UpdatedAt    time.Time `json:"updated_at"`
+	// TokenVersion goes up on every role or password change; access tokens
+	// minted with an older one are rejected
+	TokenVersion int      `json:"-"`
+	UserType     UserType `json:"user_type"`
+	// Timezone is an IANA zone name, "UTC" until the user sets one
+	Timezone  string    `json:"timezone"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
 }
 
 type UserStore interface {
@@ -33,8 +39,12 @@ type UserStore interface {
 	GetUserByID(ctx context.Context, id uuid.UUID) (*User, error)
 	GetUserByEmail(ctx context.Context, email string) (*User, error)
 	UpdatePassword(ctx context.Context, id uuid.UUID, newPassword string, now time.Time) error
-	ListAll(ctx context.Context) ([]User, error)
+	ListUsers(ctx context.Context, f UserFilter) ([]User, error)
 	UpdateUserType(ctx context.Context, userID uuid.UUID, userType UserType) (*User, error)
+	// SetTimezone stores the user's timezone; callers check the name.
+	SetTimezone(ctx context.Context, id uuid.UUID, timezone string, now time.Time) (*User, error)
+	// Timezone returns the user's timezone name.
+	Timezone(ctx context.Context, id uuid.UUID) (string, error)
 }
 type PGUserStore struct {
 	Pool *pgxpool.Pool
@@ -49,19 +59,22 @@ var (
 	ErrNotFound        = errors.New("not found")
 )
 
+// UpdateUserType bumps token_version when the type actually changes, so
+// tokens carrying the old role stop working.
 func (s *PGUserStore) UpdateUserType(ctx context.Context, userID uuid.UUID, userType UserType) (*User, error) {
 	const q = `
         UPDATE users
-        SET user_type = $2
+        SET token_version = token_version + CASE WHEN user_type <> $2 THEN 1 ELSE 0 END,
+            user_type = $2
         WHERE id = $1
-        RETURNING email, updated_at;
+        RETURNING email, token_version, updated_at;
     `
 	var out User
 	out.ID = userID
 	out.UserType = userType
 
 	err := s.Pool.QueryRow(ctx, q, userID, userType).
-		Scan(&out.Email, &out.UpdatedAt)
+		Scan(&out.Email, &out.TokenVersion, &out.UpdatedAt)
 	if err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
 			return nil, ErrNotFound
@@ -82,6 +95,7 @@ VALUES ($1,$2,$3,$4,$4) RETURNING id;`
 	out.UpdatedAt = now.UTC()
 	out.UserType = userType
 	out.Email = email
+	out.Timezone = "UTC"
 	if err := s.Pool.QueryRow(ctx, q, email, hashedPassword, userType, now.UTC()).
 		Scan(&out.ID); err != nil {
 		var pgErr *pgconn.PgError
@@ -94,11 +108,11 @@ VALUES ($1,$2,$3,$4,$4) RETURNING id;`
 }
 
 func (s *PGUserStore) GetUserByID(ctx context.Context, id uuid.UUID) (*User, error) {
-	q := `Select id, email, password_hash, user_type, created_at, updated_at
+	q := `Select id, email, password_hash, user_type, token_version, timezone, created_at, updated_at
 FROM users WHERE id = $1;`
 	var u User
 	if err := s.Pool.QueryRow(ctx, q, id).
-		Scan(&u.ID, &u.Email, &u.PasswordHash, &u.UserType, &u.CreatedAt, &u.UpdatedAt); err != nil {
+		Scan(&u.ID, &u.Email, &u.PasswordHash, &u.UserType, &u.TokenVersion, &u.Timezone, &u.CreatedAt, &u.UpdatedAt); err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
 			return nil, ErrNotFound
 		}
@@ -107,11 +121,11 @@ FROM users WHERE id = $1;`
 	return &u, nil
 }
 func (s *PGUserStore) GetUserByEmail(ctx context.Context, email string) (*User, error) {
-	q := `Select id, email, password_hash, user_type, created_at, updated_at
+	q := `Select id, email, password_hash, user_type, token_version, timezone, created_at, updated_at
 FROM users WHERE email = $1;`
 	var u User
 	if err := s.Pool.QueryRow(ctx, q, email).
-		Scan(&u.ID, &u.Email, &u.PasswordHash, &u.UserType, &u.CreatedAt, &u.UpdatedAt); err != nil {
+		Scan(&u.ID, &u.Email, &u.PasswordHash, &u.UserType, &u.TokenVersion, &u.Timezone, &u.CreatedAt, &u.UpdatedAt); err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
 			return nil, ErrNotFound
 		}
@@ -120,7 +134,7 @@ FROM users WHERE email = $1;`
 	return &u, nil
 }
 func (s *PGUserStore) UpdatePassword(ctx context.Context, id uuid.UUID, newHashedPassword string, now time.Time) error {
-	q := `UPDATE users SET password_hash = $2, updated_at = $3 WHERE id = $1;`
+	q := `UPDATE users SET password_hash = $2, token_version = token_version + 1, updated_at = $3 WHERE id = $1;`
 
 	ct, err := s.Pool.Exec(ctx, q, id, newHashedPassword, now.UTC())
 	if err != nil {
@@ -131,32 +145,35 @@ func (s *PGUserStore) UpdatePassword(ctx context.Context, id uuid.UUID, newHashe
 	}
 	return nil
 }
-func (s *PGUserStore) ListAll(ctx context.Context) ([]User, error) {
-	q := `SELECT id, email, password_hash, user_type, created_at, updated_at
-			FROM users ORDER BY email`
-	rows, err := s.Pool.Query(ctx, q)
-	if err != nil {
+
+func (s *PGUserStore) SetTimezone(ctx context.Context, id uuid.UUID, timezone string, now time.Time) (*User, error) {
+	const q = `
+        UPDATE users
+        SET timezone = $2,
+            updated_at = $3
+        WHERE id = $1
+        RETURNING id, email, user_type, timezone, created_at, updated_at;
+    `
+	var u User
+	if err := s.Pool.QueryRow(ctx, q, id, timezone, now.UTC()).
+		Scan(&u.ID, &u.Email, &u.UserType, &u.Timezone, &u.CreatedAt, &u.UpdatedAt); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrNotFound
+		}
 		return nil, err
 	}
-	defer rows.Close()
-	var users []User
-
-	for rows.Next() {
-		var user User
-		err = rows.Scan(
-			&user.ID,
-			&user.Email,
-			&user.PasswordHash,
-			&user.UserType,
-			&user.CreatedAt,
-			&user.UpdatedAt,
-		)
-		if err != nil {
-			return nil, err
+	return &u, nil
+}
+
+func (s *PGUserStore) Timezone(ctx context.Context, id uuid.UUID) (string, error) {
+	var tz string
+	if err := s.Pool.QueryRow(ctx, `SELECT timezone FROM users WHERE id = $1`, id).Scan(&tz); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return "", ErrNotFound
 		}
-		users = append(users, user)
+		return "", err
 	}
-	return users, rows.Err()
+	return tz, nil
 }
 
 var _ UserStore = (*PGUserStore)(nil)
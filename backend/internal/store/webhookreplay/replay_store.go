@@ -0,0 +1,60 @@
+package store
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// ReplayStore remembers inbound webhook deliveries so a captured request
+// cannot be replayed while its signature is still fresh. Shared through
+// Postgres so every API instance sees the same deliveries.
+type ReplayStore interface {
+	// Claim records a delivery. It returns false when the delivery was
+	// already claimed.
+	Claim(ctx context.Context, provider, deliveryID string, expiresAt time.Time) (bool, error)
+	// Release forgets a delivery so a retry of it is accepted.
+	Release(ctx context.Context, provider, deliveryID string) error
+	Purge(ctx context.Context, now time.Time) (int, error)
+}
+
+type PGReplayStore struct {
+	pool *pgxpool.Pool
+}
+
+func NewPGReplayStore(pool *pgxpool.Pool) *PGReplayStore {
+	return &PGReplayStore{pool: pool}
+}
+
+func (s *PGReplayStore) Claim(ctx context.Context, provider, deliveryID string, expiresAt time.Time) (bool, error) {
+	const q = `
+		INSERT INTO webhook_replay (provider, delivery_id, expires_at)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (provider, delivery_id) DO NOTHING
+	`
+	tag, err := s.pool.Exec(ctx, q, provider, deliveryID, expiresAt.UTC())
+	if err != nil {
+		return false, fmt.Errorf("claim webhook delivery provider=%s: %w", provider, err)
+	}
+	return tag.RowsAffected() == 1, nil
+}
+
+func (s *PGReplayStore) Release(ctx context.Context, provider, deliveryID string) error {
+	const q = `DELETE FROM webhook_replay WHERE provider = $1 AND delivery_id = $2`
+	if _, err := s.pool.Exec(ctx, q, provider, deliveryID); err != nil {
+		return fmt.Errorf("release webhook delivery provider=%s: %w", provider, err)
+	}
+	return nil
+}
+
+func (s *PGReplayStore) Purge(ctx context.Context, now time.Time) (int, error) {
+	tag, err := s.pool.Exec(ctx, `DELETE FROM webhook_replay WHERE expires_at <= $1`, now.UTC())
+	if err != nil {
+		return 0, fmt.Errorf("purge webhook replay: %w", err)
+	}
+	return int(tag.RowsAffected()), nil
+}
+
+var _ ReplayStore = (*PGReplayStore)(nil)
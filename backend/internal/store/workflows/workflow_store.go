@@ -0,0 +1,200 @@
+package store
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/diagnosis/interactive-todo/internal/workflow"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type Workflow struct {
+	ID         uuid.UUID           `json:"id"`
+	TeamID     uuid.UUID           `json:"team_id"`
+	Name       string              `json:"name"`
+	Definition workflow.Definition `json:"definition"`
+	CreatedBy  *uuid.UUID          `json:"created_by,omitempty"`
+	CreatedAt  time.Time           `json:"created_at"`
+	UpdatedAt  time.Time           `json:"updated_at"`
+}
+
+var (
+	ErrWorkflowNotFound  = errors.New("workflow not found")
+	ErrWorkflowNameTaken = errors.New("workflow name already used in team")
+	ErrWorkflowInUse     = errors.New("workflow is active for its team")
+)
+
+type WorkflowStore interface {
+	Create(ctx context.Context, teamID uuid.UUID, name string, def workflow.Definition, createdBy uuid.UUID, now time.Time) (*Workflow, error)
+	Update(ctx context.Context, id uuid.UUID, name string, def workflow.Definition, now time.Time) (*Workflow, error)
+	Get(ctx context.Context, id uuid.UUID) (*Workflow, error)
+	ListForTeam(ctx context.Context, teamID uuid.UUID) ([]Workflow, error)
+	Delete(ctx context.Context, id uuid.UUID) error
+}
+
+// NOTE: order must match scanWorkflow
+const workflowColumns = `
+    id,
+    team_id,
+    name,
+    definition,
+    created_by,
+    created_at,
+    updated_at
+`
+
+type PGWorkflowStore struct {
+	pool *pgxpool.Pool
+}
+
+func NewPGWorkflowStore(pool *pgxpool.Pool) *PGWorkflowStore {
+	return &PGWorkflowStore{pool: pool}
+}
+
+func (s *PGWorkflowStore) Create(
+	ctx context.Context,
+	teamID uuid.UUID,
+	name string,
+	def workflow.Definition,
+	createdBy uuid.UUID,
+	now time.Time,
+) (*Workflow, error) {
+	if err := def.Validate(); err != nil {
+		return nil, err
+	}
+
+	const q = `
+		INSERT INTO workflows (team_id, name, definition, created_by, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $5)
+		RETURNING ` + workflowColumns
+
+	wf, err := scanWorkflow(s.pool.QueryRow(ctx, q, teamID, name, def, createdBy, now.UTC()))
+	if err != nil {
+		if isUniqueViolation(err) {
+			return nil, ErrWorkflowNameTaken
+		}
+		return nil, fmt.Errorf("create workflow team_id=%s: %w", teamID, err)
+	}
+	return wf, nil
+}
+
+// Update replaces the definition wholesale. Tasks sitting in a state that no
+// longer exists fall back to the first state of their status category.
+func (s *PGWorkflowStore) Update(
+	ctx context.Context,
+	id uuid.UUID,
+	name string,
+	def workflow.Definition,
+	now time.Time,
+) (*Workflow, error) {
+	if err := def.Validate(); err != nil {
+		return nil, err
+	}
+
+	const q = `
+		UPDATE workflows
+		SET name       = $2,
+		    definition = $3,
+		    updated_at = $4
+		WHERE id = $1
+		RETURNING ` + workflowColumns
+
+	wf, err := scanWorkflow(s.pool.QueryRow(ctx, q, id, name, def, now.UTC()))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrWorkflowNotFound
+		}
+		if isUniqueViolation(err) {
+			return nil, ErrWorkflowNameTaken
+		}
+		return nil, fmt.Errorf("update workflow id=%s: %w", id, err)
+	}
+	return wf, nil
+}
+
+func (s *PGWorkflowStore) Get(ctx context.Context, id uuid.UUID) (*Workflow, error) {
+	const q = `
+		SELECT ` + workflowColumns + `
+		FROM workflows
+		WHERE id = $1
+	`
+
+	wf, err := scanWorkflow(s.pool.QueryRow(ctx, q, id))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrWorkflowNotFound
+		}
+		return nil, fmt.Errorf("get workflow id=%s: %w", id, err)
+	}
+	return wf, nil
+}
+
+func (s *PGWorkflowStore) ListForTeam(ctx context.Context, teamID uuid.UUID) ([]Workflow, error) {
+	const q = `
+		SELECT ` + workflowColumns + `
+		FROM workflows
+		WHERE team_id = $1
+		ORDER BY name
+	`
+
+	rows, err := s.pool.Query(ctx, q, teamID)
+	if err != nil {
+		return nil, fmt.Errorf("list workflows team_id=%s: %w", teamID, err)
+	}
+	defer rows.Close()
+
+	var out []Workflow
+	for rows.Next() {
+		wf, err := scanWorkflow(rows)
+		if err != nil {
+			return nil, fmt.Errorf("scan workflow: %w", err)
+		}
+		out = append(out, *wf)
+	}
+	return out, rows.Err()
+}
+
+func (s *PGWorkflowStore) Delete(ctx context.Context, id uuid.UUID) error {
+	const q = `DELETE FROM workflows WHERE id = $1`
+
+	ct, err := s.pool.Exec(ctx, q, id)
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == "23503" {
+			return ErrWorkflowInUse
+		}
+		return fmt.Errorf("delete workflow id=%s: %w", id, err)
+	}
+	if ct.RowsAffected() == 0 {
+		return ErrWorkflowNotFound
+	}
+	return nil
+}
+
+func scanWorkflow(row pgx.Row) (*Workflow, error) {
+	var wf Workflow
+	if err := row.Scan(
+		&wf.ID,
+		&wf.TeamID,
+		&wf.Name,
+		&wf.Definition,
+		&wf.CreatedBy,
+		&wf.CreatedAt,
+		&wf.UpdatedAt,
+	); err != nil {
+		return nil, err
+	}
+	return &wf, nil
+}
+
+func isUniqueViolation(err error) bool {
+	var pgErr *pgconn.PgError
+	return errors.As(err, &pgErr) && pgErr.Code == "23505"
+}
+
+var _ WorkflowStore = (*PGWorkflowStore)(nil)
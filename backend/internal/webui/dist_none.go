@@ -0,0 +1,11 @@
+//go:build !embedui
+
+package webui
+
+import "io/fs"
+
+// Embedded returns nil: this binary was built without the frontend. Build
+// with -tags embedui to include it.
+func Embedded() fs.FS {
+	return nil
+}
@@ -0,0 +1,107 @@
+// Package webui serves the built frontend from the API binary, so a small
+// deployment needs a single container.
+package webui
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io/fs"
+	"net/http"
+	"path"
+	"strings"
+	"time"
+)
+
+const (
+	// Vite puts content-hashed files under assets/; a new build gets new
+	// names, so they never need revalidating.
+	assetsDir       = "assets/"
+	immutableAssets = "public, max-age=31536000, immutable"
+	// everything else keeps its name across builds
+	revalidate = "no-cache"
+)
+
+type file struct {
+	data []byte
+	etag string
+}
+
+// UI serves the files of a single-page app and answers page loads of its
+// client-side routes with index.html.
+type UI struct {
+	files map[string]file
+	skip  []string
+}
+
+// New reads every file of fsys into memory. Requests under the skip
+// prefixes are always left to the API.
+func New(fsys fs.FS, skip ...string) (*UI, error) {
+	u := &UI{files: make(map[string]file), skip: skip}
+	err := fs.WalkDir(fsys, ".", func(name string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() || strings.HasPrefix(d.Name(), ".") {
+			return err
+		}
+		data, err := fs.ReadFile(fsys, name)
+		if err != nil {
+			return err
+		}
+		sum := sha256.Sum256(data)
+		u.files[name] = file{data: data, etag: `"` + hex.EncodeToString(sum[:16]) + `"`}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("read frontend: %w", err)
+	}
+	if _, ok := u.files["index.html"]; !ok {
+		return nil, errors.New("read frontend: index.html is missing; build the frontend before compiling with -tags embedui")
+	}
+	return u, nil
+}
+
+// Serve answers GET and HEAD requests for files of the app, and page loads
+// (requests accepting text/html) of paths without an extension with
+// index.html. Anything else goes to next, so API calls on the same paths
+// are unaffected.
+func (u *UI) Serve(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if (r.Method != http.MethodGet && r.Method != http.MethodHead) || u.skipped(r.URL.Path) {
+			next.ServeHTTP(w, r)
+			return
+		}
+		name := strings.TrimPrefix(path.Clean(r.URL.Path), "/")
+		if _, ok := u.files[name]; ok {
+			u.serveFile(w, r, name)
+			return
+		}
+		if path.Ext(name) == "" && strings.Contains(r.Header.Get("Accept"), "text/html") {
+			u.serveFile(w, r, "index.html")
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+func (u *UI) skipped(p string) bool {
+	for _, prefix := range u.skip {
+		if p == strings.TrimSuffix(prefix, "/") || strings.HasPrefix(p, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+func (u *UI) serveFile(w http.ResponseWriter, r *http.Request, name string) {
+	f := u.files[name]
+	cache := revalidate
+	if strings.HasPrefix(name, assetsDir) {
+		cache = immutableAssets
+	}
+	w.Header().Set("Cache-Control", cache)
+	w.Header().Set("ETag", f.etag)
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	// embedded files have no modification time; the ETag is enough
+	http.ServeContent(w, r, name, time.Time{}, bytes.NewReader(f.data))
+}
@@ -0,0 +1,212 @@
+// Package workflow validates team-defined task workflows and the transitions
+// between their states. Every state maps onto one of the built-in task
+// statuses (its category), so code that only understands the global status
+// set keeps working for teams that opt into a custom workflow.
+package workflow
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+
+	taskstore "github.com/diagnosis/interactive-todo/internal/store/tasks"
+)
+
+var (
+	ErrInvalidDefinition    = errors.New("invalid workflow definition")
+	ErrUnknownState         = errors.New("unknown workflow state")
+	ErrTransitionNotAllowed = errors.New("transition not allowed")
+	ErrActorNotAllowed      = errors.New("actor not allowed for transition")
+	ErrMissingField         = errors.New("required field missing")
+)
+
+// Actor is a role a user plays relative to a task.
+type Actor string
+
+const (
+	ActorAssignee  Actor = "assignee"
+	ActorReporter  Actor = "reporter"
+	ActorTeamAdmin Actor = "team_admin"
+)
+
+// FieldDescription is the only task field a transition can require today.
+const FieldDescription = "description"
+
+var knownFields = map[string]bool{
+	FieldDescription: true,
+}
+
+const (
+	maxStates      = 30
+	maxTransitions = 200
+)
+
+var stateKeyRe = regexp.MustCompile(`^[a-z][a-z0-9_]{0,39}$`)
+
+type State struct {
+	Key      string               `json:"key"`
+	Name     string               `json:"name,omitempty"`
+	Category taskstore.TaskStatus `json:"category"`
+}
+
+type Transition struct {
+	From           string   `json:"from"`
+	To             string   `json:"to"`
+	RequiredFields []string `json:"required_fields,omitempty"`
+	// AllowedActors defaults to the assignee, matching the global status rules.
+	AllowedActors []Actor `json:"allowed_actors,omitempty"`
+}
+
+// Definition is stored as JSONB on the workflows table.
+type Definition struct {
+	States      []State      `json:"states"`
+	Initial     string       `json:"initial"`
+	Transitions []Transition `json:"transitions"`
+}
+
+func (d *Definition) Validate() error {
+	if len(d.States) == 0 {
+		return fmt.Errorf("%w: at least one state is required", ErrInvalidDefinition)
+	}
+	if len(d.States) > maxStates {
+		return fmt.Errorf("%w: too many states (max %d)", ErrInvalidDefinition, maxStates)
+	}
+	if len(d.Transitions) > maxTransitions {
+		return fmt.Errorf("%w: too many transitions (max %d)", ErrInvalidDefinition, maxTransitions)
+	}
+
+	seen := make(map[string]bool, len(d.States))
+	for _, st := range d.States {
+		if !stateKeyRe.MatchString(st.Key) {
+			return fmt.Errorf("%w: state key %q must be lowercase letters, digits or underscores", ErrInvalidDefinition, st.Key)
+		}
+		if seen[st.Key] {
+			return fmt.Errorf("%w: duplicate state %q", ErrInvalidDefinition, st.Key)
+		}
+		seen[st.Key] = true
+
+		switch st.Category {
+		case taskstore.OpenStatus, taskstore.InProgressStatus, taskstore.DoneStatus, taskstore.CanceledStatus:
+		default:
+			return fmt.Errorf("%w: state %q has invalid category %q", ErrInvalidDefinition, st.Key, st.Category)
+		}
+		if len(st.Name) > 100 {
+			return fmt.Errorf("%w: state %q name too long (max 100 chars)", ErrInvalidDefinition, st.Key)
+		}
+	}
+
+	if !seen[d.Initial] {
+		return fmt.Errorf("%w: initial state %q is not defined", ErrInvalidDefinition, d.Initial)
+	}
+
+	pairs := make(map[[2]string]bool, len(d.Transitions))
+	for _, tr := range d.Transitions {
+		if !seen[tr.From] || !seen[tr.To] {
+			return fmt.Errorf("%w: transition %s -> %s references an unknown state", ErrInvalidDefinition, tr.From, tr.To)
+		}
+		if tr.From == tr.To {
+			return fmt.Errorf("%w: transition %s -> %s is a no-op", ErrInvalidDefinition, tr.From, tr.To)
+		}
+		key := [2]string{tr.From, tr.To}
+		if pairs[key] {
+			return fmt.Errorf("%w: duplicate transition %s -> %s", ErrInvalidDefinition, tr.From, tr.To)
+		}
+		pairs[key] = true
+
+		for _, f := range tr.RequiredFields {
+			if !knownFields[f] {
+				return fmt.Errorf("%w: unknown required field %q", ErrInvalidDefinition, f)
+			}
+		}
+		for _, a := range tr.AllowedActors {
+			switch a {
+			case ActorAssignee, ActorReporter, ActorTeamAdmin:
+			default:
+				return fmt.Errorf("%w: unknown actor %q", ErrInvalidDefinition, a)
+			}
+		}
+	}
+	return nil
+}
+
+func (d *Definition) State(key string) (State, bool) {
+	for _, st := range d.States {
+		if st.Key == key {
+			return st, true
+		}
+	}
+	return State{}, false
+}
+
+// CurrentState resolves the state a task is in. Tasks created before the team
+// adopted the workflow have no state yet and are placed in the first state of
+// their status category, falling back to the initial state.
+func (d *Definition) CurrentState(task *taskstore.Task) string {
+	if task.WorkflowState != nil {
+		if _, ok := d.State(*task.WorkflowState); ok {
+			return *task.WorkflowState
+		}
+	}
+	for _, st := range d.States {
+		if st.Category == task.Status {
+			return st.Key
+		}
+	}
+	return d.Initial
+}
+
+// CheckTransition validates moving task to the state `to` on behalf of a user
+// acting as actors. It returns the target state on success.
+func (d *Definition) CheckTransition(task *taskstore.Task, to string, actors []Actor) (State, error) {
+	target, ok := d.State(to)
+	if !ok {
+		return State{}, fmt.Errorf("%w: %q", ErrUnknownState, to)
+	}
+
+	from := d.CurrentState(task)
+	var tr *Transition
+	for i := range d.Transitions {
+		if d.Transitions[i].From == from && d.Transitions[i].To == to {
+			tr = &d.Transitions[i]
+			break
+		}
+	}
+	if tr == nil {
+		return State{}, fmt.Errorf("%w: %s -> %s", ErrTransitionNotAllowed, from, to)
+	}
+
+	allowed := tr.AllowedActors
+	if len(allowed) == 0 {
+		allowed = []Actor{ActorAssignee}
+	}
+	if !hasAnyActor(allowed, actors) {
+		return State{}, fmt.Errorf("%w: %s -> %s requires one of %v", ErrActorNotAllowed, from, to, allowed)
+	}
+
+	for _, f := range tr.RequiredFields {
+		if !fieldPresent(task, f) {
+			return State{}, fmt.Errorf("%w: %s", ErrMissingField, f)
+		}
+	}
+	return target, nil
+}
+
+func hasAnyActor(allowed, actors []Actor) bool {
+	for _, a := range allowed {
+		for _, b := range actors {
+			if a == b {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func fieldPresent(task *taskstore.Task, field string) bool {
+	switch field {
+	case FieldDescription:
+		return task.Description != nil && strings.TrimSpace(*task.Description) != ""
+	}
+	return false
+}
@@ -0,0 +1,23 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE IF NOT EXISTS audit_log (
+    id          UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    actor_id    UUID REFERENCES users(id) ON DELETE SET NULL,
+    action      TEXT        NOT NULL,
+    target_type TEXT        NOT NULL,
+    target_id   UUID,
+    team_id     UUID REFERENCES teams(id) ON DELETE SET NULL,
+    metadata    JSONB       NOT NULL DEFAULT '{}'::jsonb,
+    ip          INET,
+    created_at  TIMESTAMPTZ NOT NULL DEFAULT now()
+    );
+
+CREATE INDEX IF NOT EXISTS idx_audit_log_created_at ON audit_log(created_at DESC);
+CREATE INDEX IF NOT EXISTS idx_audit_log_action     ON audit_log(action, created_at DESC);
+CREATE INDEX IF NOT EXISTS idx_audit_log_target     ON audit_log(target_type, target_id);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS audit_log;
+-- +goose StatementEnd
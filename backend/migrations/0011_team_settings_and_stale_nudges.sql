@@ -0,0 +1,22 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE IF NOT EXISTS team_settings (
+    team_id          UUID PRIMARY KEY REFERENCES teams(id) ON DELETE CASCADE,
+    stale_nudge_days INT CHECK (stale_nudge_days IS NULL OR stale_nudge_days > 0),
+    updated_at       TIMESTAMPTZ NOT NULL DEFAULT now()
+    );
+
+ALTER TABLE tasks
+    ADD COLUMN IF NOT EXISTS stale_nudged_at TIMESTAMPTZ;
+
+CREATE INDEX IF NOT EXISTS idx_tasks_team_updated_at
+    ON tasks(team_id, updated_at)
+    WHERE status IN ('open', 'in_progress');
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS idx_tasks_team_updated_at;
+ALTER TABLE tasks DROP COLUMN IF EXISTS stale_nudged_at;
+DROP TABLE IF EXISTS team_settings;
+-- +goose StatementEnd
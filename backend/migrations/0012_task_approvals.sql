@@ -0,0 +1,37 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE team_settings
+    ADD COLUMN IF NOT EXISTS requires_approval BOOLEAN NOT NULL DEFAULT false;
+
+DO $$
+BEGIN
+    IF NOT EXISTS (SELECT 1 FROM pg_type WHERE typname = 'approval_status') THEN
+CREATE TYPE approval_status AS ENUM ('pending', 'approved', 'rejected');
+END IF;
+END
+$$ LANGUAGE plpgsql;
+
+CREATE TABLE IF NOT EXISTS task_approvals (
+    id           UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    task_id      UUID            NOT NULL REFERENCES tasks(id) ON DELETE CASCADE,
+    requested_by UUID            NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    status       approval_status NOT NULL DEFAULT 'pending',
+    decided_by   UUID REFERENCES users(id) ON DELETE SET NULL,
+    decided_at   TIMESTAMPTZ,
+    reason       TEXT,
+    created_at   TIMESTAMPTZ     NOT NULL DEFAULT now()
+    );
+
+-- at most one open approval request per task
+CREATE UNIQUE INDEX IF NOT EXISTS idx_task_approvals_one_pending
+    ON task_approvals(task_id) WHERE status = 'pending';
+CREATE INDEX IF NOT EXISTS idx_task_approvals_task_id
+    ON task_approvals(task_id, created_at DESC);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS task_approvals;
+DROP TYPE IF EXISTS approval_status;
+ALTER TABLE team_settings DROP COLUMN IF EXISTS requires_approval;
+-- +goose StatementEnd
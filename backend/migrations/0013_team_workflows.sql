@@ -0,0 +1,28 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE IF NOT EXISTS workflows (
+    id         UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    team_id    UUID        NOT NULL REFERENCES teams(id) ON DELETE CASCADE,
+    name       TEXT        NOT NULL,
+    definition JSONB       NOT NULL,
+    created_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    UNIQUE (team_id, name)
+    );
+
+-- a workflow in use by its team cannot be deleted
+ALTER TABLE team_settings
+    ADD COLUMN IF NOT EXISTS workflow_id UUID REFERENCES workflows(id) ON DELETE RESTRICT;
+
+-- custom state key; status keeps the state's category
+ALTER TABLE tasks
+    ADD COLUMN IF NOT EXISTS workflow_state TEXT;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE tasks DROP COLUMN IF EXISTS workflow_state;
+ALTER TABLE team_settings DROP COLUMN IF EXISTS workflow_id;
+DROP TABLE IF EXISTS workflows;
+-- +goose StatementEnd
@@ -0,0 +1,25 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE IF NOT EXISTS intake_forms (
+    id          UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    team_id     UUID        NOT NULL REFERENCES teams(id) ON DELETE CASCADE,
+    name        TEXT        NOT NULL,
+    description TEXT,
+    fields      JSONB       NOT NULL,
+    is_public   BOOLEAN     NOT NULL DEFAULT false,
+    assignee_id UUID REFERENCES users(id) ON DELETE SET NULL,
+    due_in_days INT         NOT NULL DEFAULT 7 CHECK (due_in_days BETWEEN 1 AND 365),
+    created_by  UUID        NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    created_at  TIMESTAMPTZ NOT NULL DEFAULT now(),
+    updated_at  TIMESTAMPTZ NOT NULL DEFAULT now(),
+    UNIQUE (team_id, name)
+    );
+
+CREATE INDEX IF NOT EXISTS idx_intake_forms_team_id
+    ON intake_forms(team_id);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS intake_forms;
+-- +goose StatementEnd
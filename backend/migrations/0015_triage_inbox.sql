@@ -0,0 +1,38 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE intake_forms
+    ADD COLUMN IF NOT EXISTS triage BOOLEAN NOT NULL DEFAULT false;
+
+DO $$
+BEGIN
+    IF NOT EXISTS (SELECT 1 FROM pg_type WHERE typname = 'triage_status') THEN
+CREATE TYPE triage_status AS ENUM ('pending', 'accepted', 'rejected');
+END IF;
+END
+$$ LANGUAGE plpgsql;
+
+CREATE TABLE IF NOT EXISTS triage_items (
+    id           UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    team_id      UUID          NOT NULL REFERENCES teams(id) ON DELETE CASCADE,
+    form_id      UUID REFERENCES intake_forms(id) ON DELETE SET NULL,
+    title        TEXT          NOT NULL,
+    description  TEXT,
+    submitted_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    status       triage_status NOT NULL DEFAULT 'pending',
+    task_id      UUID REFERENCES tasks(id) ON DELETE SET NULL,
+    decided_by   UUID REFERENCES users(id) ON DELETE SET NULL,
+    decided_at   TIMESTAMPTZ,
+    reason       TEXT,
+    created_at   TIMESTAMPTZ   NOT NULL DEFAULT now()
+    );
+
+CREATE INDEX IF NOT EXISTS idx_triage_items_team_status
+    ON triage_items(team_id, status, created_at);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS triage_items;
+DROP TYPE IF EXISTS triage_status;
+ALTER TABLE intake_forms DROP COLUMN IF EXISTS triage;
+-- +goose StatementEnd
@@ -0,0 +1,34 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE team_settings
+    ADD COLUMN IF NOT EXISTS ack_nudge_hours INT CHECK (ack_nudge_hours IS NULL OR ack_nudge_hours > 0);
+
+ALTER TABLE tasks
+    ADD COLUMN IF NOT EXISTS assigned_at     TIMESTAMPTZ,
+    ADD COLUMN IF NOT EXISTS acknowledged_at TIMESTAMPTZ,
+    ADD COLUMN IF NOT EXISTS ack_nudged_at   TIMESTAMPTZ;
+
+-- existing assignments count as seen so nobody gets nudged about old tasks
+UPDATE tasks
+SET assigned_at     = created_at,
+    acknowledged_at = created_at
+WHERE assigned_at IS NULL;
+
+ALTER TABLE tasks
+    ALTER COLUMN assigned_at SET NOT NULL,
+    ALTER COLUMN assigned_at SET DEFAULT now();
+
+CREATE INDEX IF NOT EXISTS idx_tasks_unacknowledged
+    ON tasks(assigned_at)
+    WHERE acknowledged_at IS NULL;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS idx_tasks_unacknowledged;
+ALTER TABLE tasks
+    DROP COLUMN IF EXISTS ack_nudged_at,
+    DROP COLUMN IF EXISTS acknowledged_at,
+    DROP COLUMN IF EXISTS assigned_at;
+ALTER TABLE team_settings DROP COLUMN IF EXISTS ack_nudge_hours;
+-- +goose StatementEnd
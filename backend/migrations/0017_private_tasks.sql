@@ -0,0 +1,22 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE tasks
+    ADD COLUMN IF NOT EXISTS is_private BOOLEAN NOT NULL DEFAULT false;
+
+-- extra members allowed to see a private task besides reporter and assignee
+CREATE TABLE IF NOT EXISTS task_viewers (
+    task_id    UUID        NOT NULL REFERENCES tasks(id) ON DELETE CASCADE,
+    user_id    UUID        NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    PRIMARY KEY (task_id, user_id)
+    );
+
+CREATE INDEX IF NOT EXISTS idx_task_viewers_user_id
+    ON task_viewers(user_id);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS task_viewers;
+ALTER TABLE tasks DROP COLUMN IF EXISTS is_private;
+-- +goose StatementEnd
@@ -0,0 +1,62 @@
+-- +goose Up
+-- +goose StatementBegin
+-- task_id NULL means the whole team is held
+CREATE TABLE IF NOT EXISTS legal_holds (
+    id         UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    team_id    UUID        NOT NULL REFERENCES teams(id) ON DELETE RESTRICT,
+    task_id    UUID REFERENCES tasks(id) ON DELETE RESTRICT,
+    reason     TEXT        NOT NULL,
+    placed_by  UUID REFERENCES users(id) ON DELETE SET NULL,
+    placed_at  TIMESTAMPTZ NOT NULL DEFAULT now()
+    );
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_legal_holds_team
+    ON legal_holds(team_id) WHERE task_id IS NULL;
+CREATE UNIQUE INDEX IF NOT EXISTS idx_legal_holds_task
+    ON legal_holds(task_id) WHERE task_id IS NOT NULL;
+
+-- Deletes are refused in the database so cascades and future purge jobs
+-- cannot bypass a hold either.
+CREATE OR REPLACE FUNCTION refuse_held_task_delete() RETURNS trigger AS $$
+BEGIN
+    IF EXISTS (
+        SELECT 1 FROM legal_holds lh
+        WHERE lh.task_id = OLD.id
+           OR (lh.task_id IS NULL AND lh.team_id = OLD.team_id)
+    ) THEN
+        RAISE EXCEPTION 'task % is under legal hold', OLD.id
+            USING ERRCODE = 'restrict_violation';
+    END IF;
+    RETURN OLD;
+END
+$$ LANGUAGE plpgsql;
+
+CREATE OR REPLACE FUNCTION refuse_held_team_delete() RETURNS trigger AS $$
+BEGIN
+    IF EXISTS (SELECT 1 FROM legal_holds WHERE team_id = OLD.id) THEN
+        RAISE EXCEPTION 'team % is under legal hold', OLD.id
+            USING ERRCODE = 'restrict_violation';
+    END IF;
+    RETURN OLD;
+END
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS trg_tasks_legal_hold ON tasks;
+CREATE TRIGGER trg_tasks_legal_hold
+    BEFORE DELETE ON tasks
+    FOR EACH ROW EXECUTE FUNCTION refuse_held_task_delete();
+
+DROP TRIGGER IF EXISTS trg_teams_legal_hold ON teams;
+CREATE TRIGGER trg_teams_legal_hold
+    BEFORE DELETE ON teams
+    FOR EACH ROW EXECUTE FUNCTION refuse_held_team_delete();
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TRIGGER IF EXISTS trg_teams_legal_hold ON teams;
+DROP TRIGGER IF EXISTS trg_tasks_legal_hold ON tasks;
+DROP FUNCTION IF EXISTS refuse_held_team_delete();
+DROP FUNCTION IF EXISTS refuse_held_task_delete();
+DROP TABLE IF EXISTS legal_holds;
+-- +goose StatementEnd
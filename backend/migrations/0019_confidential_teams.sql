@@ -0,0 +1,11 @@
+-- +goose Up
+-- +goose StatementBegin
+-- descriptions of tasks in confidential teams are stored AES-GCM sealed
+ALTER TABLE team_settings
+    ADD COLUMN IF NOT EXISTS confidential BOOLEAN NOT NULL DEFAULT false;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE team_settings DROP COLUMN IF EXISTS confidential;
+-- +goose StatementEnd
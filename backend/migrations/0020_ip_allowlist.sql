@@ -0,0 +1,16 @@
+-- +goose Up
+-- +goose StatementBegin
+-- an empty table means API access is not restricted by IP
+CREATE TABLE IF NOT EXISTS ip_allowlist (
+    id         UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    cidr       CIDR        NOT NULL UNIQUE,
+    note       TEXT        NOT NULL DEFAULT '',
+    created_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+    );
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS ip_allowlist;
+-- +goose StatementEnd
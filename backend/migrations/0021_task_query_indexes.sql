@@ -0,0 +1,20 @@
+-- +goose NO TRANSACTION
+-- built concurrently so large task tables stay writable during the migration
+
+-- +goose Up
+-- team boards filtered by status and sorted by due date
+CREATE INDEX CONCURRENTLY IF NOT EXISTS idx_tasks_team_status_due
+    ON tasks(team_id, status, due_at);
+
+-- "assigned to me" views filtered by status
+CREATE INDEX CONCURRENTLY IF NOT EXISTS idx_tasks_assignee_status
+    ON tasks(assignee_id, status);
+
+-- "reported by me" views, newest first
+CREATE INDEX CONCURRENTLY IF NOT EXISTS idx_tasks_reporter_created
+    ON tasks(reporter_id, created_at DESC);
+
+-- +goose Down
+DROP INDEX CONCURRENTLY IF EXISTS idx_tasks_reporter_created;
+DROP INDEX CONCURRENTLY IF EXISTS idx_tasks_assignee_status;
+DROP INDEX CONCURRENTLY IF EXISTS idx_tasks_team_status_due;
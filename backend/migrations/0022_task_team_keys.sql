@@ -0,0 +1,106 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Tables hanging off tasks carry the task's team_id and reference
+-- tasks(id, team_id), so they stay valid if tasks is partitioned by team
+-- (migrations/optional/partition_tasks_by_team.sql). New tables referencing
+-- tasks should do the same.
+ALTER TABLE tasks
+    ADD CONSTRAINT tasks_id_team_id_key UNIQUE (id, team_id);
+
+-- copies team_id from the task on insert, so stores need not pass it
+CREATE OR REPLACE FUNCTION fill_task_team_id() RETURNS trigger AS $$
+BEGIN
+    IF NEW.team_id IS NULL THEN
+        SELECT team_id INTO NEW.team_id FROM tasks WHERE id = NEW.task_id;
+        IF NEW.team_id IS NULL THEN
+            RAISE EXCEPTION 'task % does not exist', NEW.task_id
+                USING ERRCODE = 'foreign_key_violation';
+        END IF;
+    END IF;
+    RETURN NEW;
+END
+$$ LANGUAGE plpgsql;
+
+-- task_viewers
+ALTER TABLE task_viewers ADD COLUMN IF NOT EXISTS team_id UUID;
+UPDATE task_viewers c SET team_id = t.team_id
+FROM tasks t
+WHERE t.id = c.task_id AND c.team_id IS NULL;
+ALTER TABLE task_viewers
+    ALTER COLUMN team_id SET NOT NULL,
+    DROP CONSTRAINT IF EXISTS task_viewers_task_id_fkey,
+    ADD CONSTRAINT fk_task_viewers_task
+        FOREIGN KEY (task_id, team_id) REFERENCES tasks(id, team_id) ON DELETE CASCADE;
+DROP TRIGGER IF EXISTS trg_task_viewers_team ON task_viewers;
+CREATE TRIGGER trg_task_viewers_team
+    BEFORE INSERT ON task_viewers
+    FOR EACH ROW EXECUTE FUNCTION fill_task_team_id();
+
+-- task_approvals
+ALTER TABLE task_approvals ADD COLUMN IF NOT EXISTS team_id UUID;
+UPDATE task_approvals c SET team_id = t.team_id
+FROM tasks t
+WHERE t.id = c.task_id AND c.team_id IS NULL;
+ALTER TABLE task_approvals
+    ALTER COLUMN team_id SET NOT NULL,
+    DROP CONSTRAINT IF EXISTS task_approvals_task_id_fkey,
+    ADD CONSTRAINT fk_task_approvals_task
+        FOREIGN KEY (task_id, team_id) REFERENCES tasks(id, team_id) ON DELETE CASCADE;
+DROP TRIGGER IF EXISTS trg_task_approvals_team ON task_approvals;
+CREATE TRIGGER trg_task_approvals_team
+    BEFORE INSERT ON task_approvals
+    FOR EACH ROW EXECUTE FUNCTION fill_task_team_id();
+
+-- task_extension_requests
+ALTER TABLE task_extension_requests ADD COLUMN IF NOT EXISTS team_id UUID;
+UPDATE task_extension_requests c SET team_id = t.team_id
+FROM tasks t
+WHERE t.id = c.task_id AND c.team_id IS NULL;
+ALTER TABLE task_extension_requests
+    ALTER COLUMN team_id SET NOT NULL,
+    DROP CONSTRAINT IF EXISTS task_extension_requests_task_id_fkey,
+    ADD CONSTRAINT fk_task_ext_task
+        FOREIGN KEY (task_id, team_id) REFERENCES tasks(id, team_id) ON DELETE CASCADE;
+DROP TRIGGER IF EXISTS trg_task_ext_team ON task_extension_requests;
+CREATE TRIGGER trg_task_ext_team
+    BEFORE INSERT ON task_extension_requests
+    FOR EACH ROW EXECUTE FUNCTION fill_task_team_id();
+
+-- legal_holds already records the task's team
+ALTER TABLE legal_holds
+    DROP CONSTRAINT IF EXISTS legal_holds_task_id_fkey,
+    ADD CONSTRAINT fk_legal_holds_task
+        FOREIGN KEY (task_id, team_id) REFERENCES tasks(id, team_id) ON DELETE RESTRICT;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE legal_holds
+    DROP CONSTRAINT IF EXISTS fk_legal_holds_task,
+    ADD CONSTRAINT legal_holds_task_id_fkey
+        FOREIGN KEY (task_id) REFERENCES tasks(id) ON DELETE RESTRICT;
+
+DROP TRIGGER IF EXISTS trg_task_ext_team ON task_extension_requests;
+ALTER TABLE task_extension_requests
+    DROP CONSTRAINT IF EXISTS fk_task_ext_task,
+    ADD CONSTRAINT task_extension_requests_task_id_fkey
+        FOREIGN KEY (task_id) REFERENCES tasks(id) ON DELETE CASCADE,
+    DROP COLUMN IF EXISTS team_id;
+
+DROP TRIGGER IF EXISTS trg_task_approvals_team ON task_approvals;
+ALTER TABLE task_approvals
+    DROP CONSTRAINT IF EXISTS fk_task_approvals_task,
+    ADD CONSTRAINT task_approvals_task_id_fkey
+        FOREIGN KEY (task_id) REFERENCES tasks(id) ON DELETE CASCADE,
+    DROP COLUMN IF EXISTS team_id;
+
+DROP TRIGGER IF EXISTS trg_task_viewers_team ON task_viewers;
+ALTER TABLE task_viewers
+    DROP CONSTRAINT IF EXISTS fk_task_viewers_task,
+    ADD CONSTRAINT task_viewers_task_id_fkey
+        FOREIGN KEY (task_id) REFERENCES tasks(id) ON DELETE CASCADE,
+    DROP COLUMN IF EXISTS team_id;
+
+DROP FUNCTION IF EXISTS fill_task_team_id();
+ALTER TABLE tasks DROP CONSTRAINT IF EXISTS tasks_id_team_id_key;
+-- +goose StatementEnd
@@ -0,0 +1,39 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Cold storage for long-finished tasks, moved here by the archive job.
+-- Columns mirror the task columns the API reads; add new ones here too.
+-- viewer_ids keeps private tasks visible to their extra viewers.
+CREATE TABLE IF NOT EXISTS tasks_archive (
+    id              UUID PRIMARY KEY,
+    team_id         UUID        NOT NULL REFERENCES teams(id) ON DELETE CASCADE,
+    title           TEXT        NOT NULL,
+    description     TEXT,
+    reporter_id     UUID        NOT NULL,
+    assignee_id     UUID        NOT NULL,
+    due_at          TIMESTAMPTZ NOT NULL,
+    reminder_sent_at TIMESTAMPTZ,
+    status          task_status NOT NULL,
+    workflow_state  TEXT,
+    is_private      BOOLEAN     NOT NULL DEFAULT false,
+    assigned_at     TIMESTAMPTZ NOT NULL,
+    acknowledged_at TIMESTAMPTZ,
+    created_at      TIMESTAMPTZ NOT NULL,
+    updated_at      TIMESTAMPTZ NOT NULL,
+    viewer_ids      UUID[]      NOT NULL DEFAULT '{}',
+    archived_at     TIMESTAMPTZ NOT NULL DEFAULT now()
+    );
+
+CREATE INDEX IF NOT EXISTS idx_tasks_archive_team_created
+    ON tasks_archive(team_id, created_at);
+
+-- finds archive candidates without scanning active tasks
+CREATE INDEX IF NOT EXISTS idx_tasks_finished_updated_at
+    ON tasks(updated_at)
+    WHERE status IN ('done', 'canceled');
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS idx_tasks_finished_updated_at;
+DROP TABLE IF EXISTS tasks_archive;
+-- +goose StatementEnd
@@ -0,0 +1,84 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Per-team task counts by status, kept current by a trigger so team stats
+-- never scan tasks. Overdue depends on the clock and is counted on read.
+CREATE TABLE IF NOT EXISTS team_task_counters (
+    team_id           UUID PRIMARY KEY REFERENCES teams(id) ON DELETE CASCADE,
+    open_count        BIGINT      NOT NULL DEFAULT 0,
+    in_progress_count BIGINT      NOT NULL DEFAULT 0,
+    done_count        BIGINT      NOT NULL DEFAULT 0,
+    canceled_count    BIGINT      NOT NULL DEFAULT 0,
+    updated_at        TIMESTAMPTZ NOT NULL DEFAULT now()
+    );
+
+-- Decrements only update: when a team is deleted its counter row may be
+-- gone before the cascaded task deletes fire.
+CREATE OR REPLACE FUNCTION bump_team_task_counter(team UUID, st task_status, delta INT) RETURNS void AS $$
+BEGIN
+    IF delta > 0 THEN
+        INSERT INTO team_task_counters AS c
+            (team_id, open_count, in_progress_count, done_count, canceled_count)
+        VALUES (
+            team,
+            CASE WHEN st = 'open' THEN delta ELSE 0 END,
+            CASE WHEN st = 'in_progress' THEN delta ELSE 0 END,
+            CASE WHEN st = 'done' THEN delta ELSE 0 END,
+            CASE WHEN st = 'canceled' THEN delta ELSE 0 END
+        )
+        ON CONFLICT (team_id) DO UPDATE SET
+            open_count        = c.open_count + EXCLUDED.open_count,
+            in_progress_count = c.in_progress_count + EXCLUDED.in_progress_count,
+            done_count        = c.done_count + EXCLUDED.done_count,
+            canceled_count    = c.canceled_count + EXCLUDED.canceled_count,
+            updated_at        = now();
+    ELSE
+        UPDATE team_task_counters SET
+            open_count        = open_count + CASE WHEN st = 'open' THEN delta ELSE 0 END,
+            in_progress_count = in_progress_count + CASE WHEN st = 'in_progress' THEN delta ELSE 0 END,
+            done_count        = done_count + CASE WHEN st = 'done' THEN delta ELSE 0 END,
+            canceled_count    = canceled_count + CASE WHEN st = 'canceled' THEN delta ELSE 0 END,
+            updated_at        = now()
+        WHERE team_id = team;
+    END IF;
+END
+$$ LANGUAGE plpgsql;
+
+CREATE OR REPLACE FUNCTION track_team_task_counters() RETURNS trigger AS $$
+BEGIN
+    IF TG_OP IN ('UPDATE', 'DELETE') THEN
+        IF TG_OP = 'DELETE' OR OLD.status <> NEW.status OR OLD.team_id <> NEW.team_id THEN
+            PERFORM bump_team_task_counter(OLD.team_id, OLD.status, -1);
+        END IF;
+    END IF;
+    IF TG_OP IN ('INSERT', 'UPDATE') THEN
+        IF TG_OP = 'INSERT' OR OLD.status <> NEW.status OR OLD.team_id <> NEW.team_id THEN
+            PERFORM bump_team_task_counter(NEW.team_id, NEW.status, 1);
+        END IF;
+    END IF;
+    RETURN NULL;
+END
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS trg_tasks_team_counters ON tasks;
+CREATE TRIGGER trg_tasks_team_counters
+    AFTER INSERT OR UPDATE OF status, team_id OR DELETE ON tasks
+    FOR EACH ROW EXECUTE FUNCTION track_team_task_counters();
+
+INSERT INTO team_task_counters (team_id, open_count, in_progress_count, done_count, canceled_count)
+SELECT team_id,
+       COUNT(*) FILTER (WHERE status = 'open'),
+       COUNT(*) FILTER (WHERE status = 'in_progress'),
+       COUNT(*) FILTER (WHERE status = 'done'),
+       COUNT(*) FILTER (WHERE status = 'canceled')
+FROM tasks
+GROUP BY team_id
+ON CONFLICT (team_id) DO NOTHING;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TRIGGER IF EXISTS trg_tasks_team_counters ON tasks;
+DROP FUNCTION IF EXISTS track_team_task_counters();
+DROP FUNCTION IF EXISTS bump_team_task_counter(UUID, task_status, INT);
+DROP TABLE IF EXISTS team_task_counters;
+-- +goose StatementEnd
@@ -0,0 +1,27 @@
+-- +goose Up
+-- +goose StatementBegin
+-- single revoked access tokens, kept until they would have expired anyway
+CREATE TABLE IF NOT EXISTS access_token_denylist (
+    jti        UUID PRIMARY KEY,
+    user_id    UUID        NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    expires_at TIMESTAMPTZ NOT NULL,
+    revoked_at TIMESTAMPTZ NOT NULL DEFAULT now()
+    );
+
+CREATE INDEX IF NOT EXISTS idx_access_token_denylist_expires ON access_token_denylist(expires_at);
+
+-- logout-all: every access token of the user issued before revoked_before
+CREATE TABLE IF NOT EXISTS access_token_user_cutoffs (
+    user_id        UUID PRIMARY KEY REFERENCES users(id) ON DELETE CASCADE,
+    revoked_before TIMESTAMPTZ NOT NULL,
+    expires_at     TIMESTAMPTZ NOT NULL
+    );
+
+CREATE INDEX IF NOT EXISTS idx_access_token_user_cutoffs_expires ON access_token_user_cutoffs(expires_at);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS access_token_user_cutoffs;
+DROP TABLE IF EXISTS access_token_denylist;
+-- +goose StatementEnd
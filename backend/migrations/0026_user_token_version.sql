@@ -0,0 +1,11 @@
+-- +goose Up
+-- +goose StatementBegin
+-- bumped whenever a user's permissions or credentials change; access tokens
+-- carrying an older version are rejected
+ALTER TABLE users ADD COLUMN IF NOT EXISTS token_version INT NOT NULL DEFAULT 0;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE users DROP COLUMN IF EXISTS token_version;
+-- +goose StatementEnd
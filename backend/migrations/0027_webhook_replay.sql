@@ -0,0 +1,19 @@
+-- +goose Up
+-- +goose StatementBegin
+-- inbound webhook deliveries already accepted, kept until their signature
+-- timestamp falls outside the tolerance window
+CREATE TABLE IF NOT EXISTS webhook_replay (
+    provider    TEXT        NOT NULL,
+    delivery_id TEXT        NOT NULL,
+    expires_at  TIMESTAMPTZ NOT NULL,
+    received_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    PRIMARY KEY (provider, delivery_id)
+    );
+
+CREATE INDEX IF NOT EXISTS idx_webhook_replay_expires ON webhook_replay(expires_at);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS webhook_replay;
+-- +goose StatementEnd
@@ -0,0 +1,26 @@
+-- +goose Up
+-- +goose StatementBegin
+-- working-day calendar per team; teams without a row work Monday to Friday in UTC
+CREATE TABLE IF NOT EXISTS team_calendars (
+    team_id         UUID PRIMARY KEY REFERENCES teams(id) ON DELETE CASCADE,
+    timezone        TEXT        NOT NULL DEFAULT 'UTC',
+    weekend_days    SMALLINT[]  NOT NULL DEFAULT '{0,6}',
+    due_date_policy TEXT        NOT NULL DEFAULT 'off'
+        CHECK (due_date_policy IN ('off', 'warn', 'shift')),
+    updated_at      TIMESTAMPTZ NOT NULL DEFAULT now()
+    );
+
+CREATE TABLE IF NOT EXISTS team_holidays (
+    team_id    UUID        NOT NULL REFERENCES teams(id) ON DELETE CASCADE,
+    day        DATE        NOT NULL,
+    name       TEXT        NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    PRIMARY KEY (team_id, day)
+    );
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS team_holidays;
+DROP TABLE IF EXISTS team_calendars;
+-- +goose StatementEnd
@@ -0,0 +1,59 @@
+-- +goose Up
+-- +goose StatementBegin
+-- team-scoped labels, created by name the first time a task uses them
+CREATE TABLE IF NOT EXISTS labels (
+    id         UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    team_id    UUID        NOT NULL REFERENCES teams(id) ON DELETE CASCADE,
+    name       TEXT        NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+    );
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_labels_team_name ON labels(team_id, lower(name));
+
+CREATE TABLE IF NOT EXISTS task_labels (
+    task_id    UUID        NOT NULL,
+    team_id    UUID        NOT NULL,
+    label_id   UUID        NOT NULL REFERENCES labels(id) ON DELETE CASCADE,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    PRIMARY KEY (task_id, label_id),
+    CONSTRAINT fk_task_labels_task
+        FOREIGN KEY (task_id, team_id) REFERENCES tasks(id, team_id) ON DELETE CASCADE
+    );
+
+CREATE INDEX IF NOT EXISTS idx_task_labels_label ON task_labels(label_id);
+
+DROP TRIGGER IF EXISTS trg_task_labels_team ON task_labels;
+CREATE TRIGGER trg_task_labels_team
+    BEFORE INSERT ON task_labels
+    FOR EACH ROW EXECUTE FUNCTION fill_task_team_id();
+
+-- who gets tasks created without an assignee (NULL = the reporter, as before)
+ALTER TABLE team_settings
+    ADD COLUMN IF NOT EXISTS auto_assign TEXT
+        CHECK (auto_assign IS NULL OR auto_assign IN ('round_robin', 'least_loaded', 'label_routing'));
+
+-- round-robin position per team
+CREATE TABLE IF NOT EXISTS team_assignment_state (
+    team_id          UUID PRIMARY KEY REFERENCES teams(id) ON DELETE CASCADE,
+    last_assignee_id UUID REFERENCES users(id) ON DELETE SET NULL,
+    updated_at       TIMESTAMPTZ NOT NULL DEFAULT now()
+    );
+
+-- label_routing: the first route (by position) whose label is on the task wins
+CREATE TABLE IF NOT EXISTS assignment_routes (
+    team_id     UUID NOT NULL REFERENCES teams(id) ON DELETE CASCADE,
+    label_id    UUID NOT NULL REFERENCES labels(id) ON DELETE CASCADE,
+    assignee_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    position    INT  NOT NULL,
+    PRIMARY KEY (team_id, label_id)
+    );
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS assignment_routes;
+DROP TABLE IF EXISTS team_assignment_state;
+ALTER TABLE team_settings DROP COLUMN IF EXISTS auto_assign;
+DROP TABLE IF EXISTS task_labels;
+DROP TABLE IF EXISTS labels;
+-- +goose StatementEnd
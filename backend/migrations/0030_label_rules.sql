@@ -0,0 +1,35 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE tasks
+    ADD COLUMN IF NOT EXISTS priority TEXT NOT NULL DEFAULT 'normal'
+        CHECK (priority IN ('low', 'normal', 'high', 'urgent'));
+
+ALTER TABLE tasks_archive
+    ADD COLUMN IF NOT EXISTS priority TEXT NOT NULL DEFAULT 'normal';
+
+-- "title contains 'bug' -> add label Bug, priority high", evaluated in
+-- position order when a task is created or its text edited
+CREATE TABLE IF NOT EXISTS label_rules (
+    id           UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    team_id      UUID        NOT NULL REFERENCES teams(id) ON DELETE CASCADE,
+    name         TEXT        NOT NULL,
+    field        TEXT        NOT NULL CHECK (field IN ('title', 'description', 'any')),
+    contains     TEXT        NOT NULL,
+    add_labels   TEXT[]      NOT NULL DEFAULT '{}',
+    set_priority TEXT CHECK (set_priority IS NULL OR set_priority IN ('low', 'normal', 'high', 'urgent')),
+    enabled      BOOLEAN     NOT NULL DEFAULT true,
+    position     INT         NOT NULL,
+    created_by   UUID REFERENCES users(id) ON DELETE SET NULL,
+    created_at   TIMESTAMPTZ NOT NULL DEFAULT now(),
+    updated_at   TIMESTAMPTZ NOT NULL DEFAULT now()
+    );
+
+CREATE INDEX IF NOT EXISTS idx_label_rules_team_position ON label_rules(team_id, position);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS label_rules;
+ALTER TABLE tasks_archive DROP COLUMN IF EXISTS priority;
+ALTER TABLE tasks DROP COLUMN IF EXISTS priority;
+-- +goose StatementEnd
@@ -0,0 +1,63 @@
+-- +goose Up
+-- +goose StatementBegin
+-- team automation recipes: one trigger, a list of actions, stored as JSONB
+CREATE TABLE IF NOT EXISTS automation_recipes (
+    id         UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    team_id    UUID        NOT NULL REFERENCES teams(id) ON DELETE CASCADE,
+    name       TEXT        NOT NULL,
+    definition JSONB       NOT NULL,
+    enabled    BOOLEAN     NOT NULL DEFAULT true,
+    created_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    UNIQUE (team_id, name)
+    );
+
+CREATE INDEX IF NOT EXISTS idx_automation_recipes_trigger
+    ON automation_recipes(team_id, (definition->'trigger'->>'type'))
+    WHERE enabled;
+
+-- comments on tasks; author_id is NULL for comments posted by a recipe
+CREATE TABLE IF NOT EXISTS task_comments (
+    id         UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    task_id    UUID        NOT NULL,
+    team_id    UUID        NOT NULL,
+    author_id  UUID REFERENCES users(id) ON DELETE SET NULL,
+    recipe_id  UUID REFERENCES automation_recipes(id) ON DELETE SET NULL,
+    body       TEXT        NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    CONSTRAINT fk_task_comments_task
+        FOREIGN KEY (task_id, team_id) REFERENCES tasks(id, team_id) ON DELETE CASCADE
+    );
+
+CREATE INDEX IF NOT EXISTS idx_task_comments_task_created ON task_comments(task_id, created_at);
+
+DROP TRIGGER IF EXISTS trg_task_comments_team ON task_comments;
+CREATE TRIGGER trg_task_comments_team
+    BEFORE INSERT ON task_comments
+    FOR EACH ROW EXECUTE FUNCTION fill_task_team_id();
+
+-- due_soon recipes fire once per task and due date
+CREATE TABLE IF NOT EXISTS automation_firings (
+    recipe_id UUID        NOT NULL REFERENCES automation_recipes(id) ON DELETE CASCADE,
+    task_id   UUID        NOT NULL,
+    team_id   UUID        NOT NULL,
+    due_at    TIMESTAMPTZ NOT NULL,
+    fired_at  TIMESTAMPTZ NOT NULL DEFAULT now(),
+    PRIMARY KEY (recipe_id, task_id, due_at),
+    CONSTRAINT fk_automation_firings_task
+        FOREIGN KEY (task_id, team_id) REFERENCES tasks(id, team_id) ON DELETE CASCADE
+    );
+
+DROP TRIGGER IF EXISTS trg_automation_firings_team ON automation_firings;
+CREATE TRIGGER trg_automation_firings_team
+    BEFORE INSERT ON automation_firings
+    FOR EACH ROW EXECUTE FUNCTION fill_task_team_id();
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS automation_firings;
+DROP TABLE IF EXISTS task_comments;
+DROP TABLE IF EXISTS automation_recipes;
+-- +goose StatementEnd
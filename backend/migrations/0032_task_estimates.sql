@@ -0,0 +1,15 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE tasks
+    ADD COLUMN IF NOT EXISTS estimate_hours NUMERIC(5, 1)
+        CHECK (estimate_hours IS NULL OR estimate_hours > 0);
+
+ALTER TABLE tasks_archive
+    ADD COLUMN IF NOT EXISTS estimate_hours NUMERIC(5, 1);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE tasks_archive DROP COLUMN IF EXISTS estimate_hours;
+ALTER TABLE tasks DROP COLUMN IF EXISTS estimate_hours;
+-- +goose StatementEnd
@@ -0,0 +1,23 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE task_comments ADD COLUMN IF NOT EXISTS edited_at TIMESTAMPTZ;
+
+-- previous bodies of edited comments; written_at is when that body was
+-- posted or last edited, replaced_at when the next edit replaced it
+CREATE TABLE IF NOT EXISTS comment_revisions (
+    id          UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    comment_id  UUID        NOT NULL REFERENCES task_comments(id) ON DELETE CASCADE,
+    body        TEXT        NOT NULL,
+    written_at  TIMESTAMPTZ NOT NULL,
+    replaced_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    replaced_by UUID REFERENCES users(id) ON DELETE SET NULL
+    );
+
+CREATE INDEX IF NOT EXISTS idx_comment_revisions_comment ON comment_revisions(comment_id, replaced_at);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS comment_revisions;
+ALTER TABLE task_comments DROP COLUMN IF EXISTS edited_at;
+-- +goose StatementEnd
@@ -0,0 +1,18 @@
+-- +goose Up
+-- +goose StatementBegin
+-- authenticated API requests per user and UTC day, flushed from memory
+CREATE TABLE IF NOT EXISTS user_api_usage (
+    user_id       UUID        NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    day           DATE        NOT NULL,
+    request_count BIGINT      NOT NULL DEFAULT 0,
+    last_seen_at  TIMESTAMPTZ NOT NULL,
+    PRIMARY KEY (user_id, day)
+    );
+
+CREATE INDEX IF NOT EXISTS idx_user_api_usage_day ON user_api_usage(day);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS user_api_usage;
+-- +goose StatementEnd
@@ -0,0 +1,20 @@
+-- +goose Up
+-- +goose StatementBegin
+-- point-in-time copies of a team's tasks, members and labels
+CREATE TABLE IF NOT EXISTS team_snapshots (
+    id         UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    team_id    UUID        NOT NULL REFERENCES teams(id) ON DELETE CASCADE,
+    name       TEXT        NOT NULL,
+    data       JSONB       NOT NULL,
+    task_count INT         NOT NULL,
+    created_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+    );
+
+CREATE INDEX IF NOT EXISTS idx_team_snapshots_team_created ON team_snapshots(team_id, created_at DESC);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS team_snapshots;
+-- +goose StatementEnd
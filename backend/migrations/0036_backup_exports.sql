@@ -0,0 +1,30 @@
+-- +goose Up
+-- +goose StatementBegin
+-- admin-requested logical exports, written encrypted to object storage.
+-- team_id has no foreign key: the record outlives a deleted team.
+CREATE TABLE IF NOT EXISTS backup_exports (
+    id           UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    team_id      UUID,
+    status       TEXT        NOT NULL DEFAULT 'pending'
+        CHECK (status IN ('pending', 'running', 'done', 'failed')),
+    object_key   TEXT,
+    size_bytes   BIGINT,
+    row_count    BIGINT,
+    error        TEXT,
+    requested_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    created_at   TIMESTAMPTZ NOT NULL DEFAULT now(),
+    started_at   TIMESTAMPTZ,
+    finished_at  TIMESTAMPTZ
+    );
+
+CREATE INDEX IF NOT EXISTS idx_backup_exports_created ON backup_exports(created_at DESC);
+-- one unfinished export per scope (a team, or the whole install)
+CREATE UNIQUE INDEX IF NOT EXISTS idx_backup_exports_active
+    ON backup_exports ((COALESCE(team_id, '00000000-0000-0000-0000-000000000000'::uuid)))
+    WHERE status IN ('pending', 'running');
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS backup_exports;
+-- +goose StatementEnd
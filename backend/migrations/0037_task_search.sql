@@ -0,0 +1,38 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Full-text search over title (weight A) and description (weight B).
+-- Sealed descriptions of confidential teams are ciphertext and are left
+-- out, so nothing readable about them ends up in the index.
+ALTER TABLE tasks ADD COLUMN IF NOT EXISTS search_vector tsvector;
+
+CREATE OR REPLACE FUNCTION tasks_search_vector() RETURNS trigger AS $$
+BEGIN
+    NEW.search_vector :=
+        setweight(to_tsvector('english', NEW.title), 'A') ||
+        setweight(to_tsvector('english',
+            CASE WHEN NEW.description LIKE 'enc:v1:%' THEN '' ELSE COALESCE(NEW.description, '') END), 'B');
+    RETURN NEW;
+END
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS trg_tasks_search_vector ON tasks;
+CREATE TRIGGER trg_tasks_search_vector
+    BEFORE INSERT OR UPDATE OF title, description ON tasks
+    FOR EACH ROW EXECUTE FUNCTION tasks_search_vector();
+
+UPDATE tasks SET search_vector =
+    setweight(to_tsvector('english', title), 'A') ||
+    setweight(to_tsvector('english',
+        CASE WHEN description LIKE 'enc:v1:%' THEN '' ELSE COALESCE(description, '') END), 'B');
+
+CREATE INDEX IF NOT EXISTS idx_tasks_search_vector
+    ON tasks USING GIN (search_vector);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS idx_tasks_search_vector;
+DROP TRIGGER IF EXISTS trg_tasks_search_vector ON tasks;
+DROP FUNCTION IF EXISTS tasks_search_vector();
+ALTER TABLE tasks DROP COLUMN IF EXISTS search_vector;
+-- +goose StatementEnd
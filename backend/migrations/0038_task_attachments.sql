@@ -0,0 +1,52 @@
+-- +goose Up
+-- +goose StatementBegin
+-- files on tasks; the bytes live in object storage under storage_key
+CREATE TABLE IF NOT EXISTS task_attachments (
+    id           UUID PRIMARY KEY,
+    task_id      UUID        NOT NULL,
+    team_id      UUID        NOT NULL,
+    uploader_id  UUID        REFERENCES users(id) ON DELETE SET NULL,
+    filename     TEXT        NOT NULL,
+    content_type TEXT        NOT NULL,
+    size_bytes   BIGINT      NOT NULL CHECK (size_bytes >= 0),
+    sha256       TEXT        NOT NULL,
+    storage_key  TEXT        NOT NULL UNIQUE,
+    created_at   TIMESTAMPTZ NOT NULL DEFAULT now(),
+    CONSTRAINT fk_task_attachments_task
+        FOREIGN KEY (task_id, team_id) REFERENCES tasks(id, team_id) ON DELETE CASCADE
+    );
+
+CREATE INDEX IF NOT EXISTS idx_task_attachments_task ON task_attachments(task_id, created_at);
+
+DROP TRIGGER IF EXISTS trg_task_attachments_team ON task_attachments;
+CREATE TRIGGER trg_task_attachments_team
+    BEFORE INSERT ON task_attachments
+    FOR EACH ROW EXECUTE FUNCTION fill_task_team_id();
+
+-- Objects whose row is gone, however it went (attachment, task or team
+-- deleted, task archived). The purge job deletes them from storage.
+CREATE TABLE IF NOT EXISTS attachment_purges (
+    storage_key TEXT PRIMARY KEY,
+    queued_at   TIMESTAMPTZ NOT NULL DEFAULT now()
+    );
+
+CREATE OR REPLACE FUNCTION queue_attachment_purge() RETURNS trigger AS $$
+BEGIN
+    INSERT INTO attachment_purges (storage_key) VALUES (OLD.storage_key)
+    ON CONFLICT DO NOTHING;
+    RETURN OLD;
+END
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS trg_task_attachments_purge ON task_attachments;
+CREATE TRIGGER trg_task_attachments_purge
+    AFTER DELETE ON task_attachments
+    FOR EACH ROW EXECUTE FUNCTION queue_attachment_purge();
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS task_attachments;
+DROP FUNCTION IF EXISTS queue_attachment_purge();
+DROP TABLE IF EXISTS attachment_purges;
+-- +goose StatementEnd
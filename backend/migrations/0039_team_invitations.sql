@@ -0,0 +1,22 @@
+-- +goose Up
+-- +goose StatementBegin
+-- members invited by email before they have an account; registering with
+-- the address turns an unexpired invitation into a membership.
+CREATE TABLE IF NOT EXISTS team_invitations (
+    id         UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    team_id    UUID        NOT NULL REFERENCES teams(id) ON DELETE CASCADE,
+    email      CITEXT      NOT NULL,
+    role       team_role   NOT NULL DEFAULT 'member' CHECK (role <> 'owner'),
+    invited_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    expires_at TIMESTAMPTZ NOT NULL,
+    UNIQUE (team_id, email)
+    );
+
+CREATE INDEX IF NOT EXISTS idx_team_invitations_email ON team_invitations(email);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS team_invitations;
+-- +goose StatementEnd
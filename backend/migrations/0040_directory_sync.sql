@@ -0,0 +1,36 @@
+-- +goose Up
+-- +goose StatementBegin
+-- a team whose membership follows a Google Workspace or Azure AD group;
+-- the directory sync job adds and removes members to match it.
+CREATE TABLE IF NOT EXISTS team_directory_links (
+    team_id        UUID PRIMARY KEY REFERENCES teams(id) ON DELETE CASCADE,
+    provider       TEXT        NOT NULL CHECK (provider IN ('google', 'azure')),
+    group_id       TEXT        NOT NULL,
+    role           team_role   NOT NULL DEFAULT 'member' CHECK (role <> 'owner'),
+    created_by     UUID REFERENCES users(id) ON DELETE SET NULL,
+    created_at     TIMESTAMPTZ NOT NULL DEFAULT now(),
+    updated_at     TIMESTAMPTZ NOT NULL DEFAULT now(),
+    last_synced_at TIMESTAMPTZ,
+    last_error     TEXT,
+    last_added     INT         NOT NULL DEFAULT 0,
+    last_removed   INT         NOT NULL DEFAULT 0,
+    last_unmatched INT         NOT NULL DEFAULT 0
+    );
+
+-- members the sync added. Only these are removed when they leave the
+-- group; people added by hand are left alone. Removing the member by hand
+-- or unlinking the team drops the row.
+CREATE TABLE IF NOT EXISTS directory_members (
+    team_id    UUID        NOT NULL REFERENCES team_directory_links(team_id) ON DELETE CASCADE,
+    user_id    UUID        NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    PRIMARY KEY (team_id, user_id),
+    FOREIGN KEY (team_id, user_id) REFERENCES team_members(team_id, user_id) ON DELETE CASCADE
+    );
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS directory_members;
+DROP TABLE IF EXISTS team_directory_links;
+-- +goose StatementEnd
@@ -0,0 +1,23 @@
+-- +goose Up
+-- +goose StatementBegin
+-- every change to a task, written in the same transaction as the change.
+-- There is no foreign key to tasks: the history outlives the task, ending
+-- with its deleted event, and goes with the team.
+CREATE TABLE IF NOT EXISTS task_events (
+    id         UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    task_id    UUID        NOT NULL,
+    team_id    UUID        NOT NULL REFERENCES teams(id) ON DELETE CASCADE,
+    actor_id   UUID REFERENCES users(id) ON DELETE SET NULL,
+    kind       TEXT        NOT NULL CHECK (kind IN ('created', 'assigned', 'status_changed', 'updated', 'visibility_changed', 'deleted')),
+    changes    JSONB       NOT NULL DEFAULT '{}'::jsonb,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+    );
+
+CREATE INDEX IF NOT EXISTS idx_task_events_task_created ON task_events(task_id, created_at DESC);
+CREATE INDEX IF NOT EXISTS idx_task_events_team ON task_events(team_id);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS task_events;
+-- +goose StatementEnd
@@ -0,0 +1,38 @@
+-- +goose Up
+-- +goose StatementBegin
+-- a task template an org admin sent to every team; each team got its own
+-- copy, which points back here
+CREATE TABLE IF NOT EXISTS announcements (
+    id          UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    title       TEXT        NOT NULL,
+    description TEXT,
+    due_at      TIMESTAMPTZ NOT NULL,
+    priority    TEXT        NOT NULL DEFAULT 'normal'
+        CHECK (priority IN ('low', 'normal', 'high', 'urgent')),
+    created_by  UUID REFERENCES users(id) ON DELETE SET NULL,
+    -- teams that got a task; tasks deleted since still count
+    team_count  INT         NOT NULL DEFAULT 0,
+    created_at  TIMESTAMPTZ NOT NULL DEFAULT now()
+    );
+
+CREATE INDEX IF NOT EXISTS idx_announcements_created ON announcements(created_at DESC);
+
+ALTER TABLE tasks
+    ADD COLUMN IF NOT EXISTS announcement_id UUID REFERENCES announcements(id) ON DELETE SET NULL;
+
+CREATE INDEX IF NOT EXISTS idx_tasks_announcement
+    ON tasks(announcement_id) WHERE announcement_id IS NOT NULL;
+
+ALTER TABLE tasks_archive
+    ADD COLUMN IF NOT EXISTS announcement_id UUID;
+
+CREATE INDEX IF NOT EXISTS idx_tasks_archive_announcement
+    ON tasks_archive(announcement_id) WHERE announcement_id IS NOT NULL;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE tasks_archive DROP COLUMN IF EXISTS announcement_id;
+ALTER TABLE tasks DROP COLUMN IF EXISTS announcement_id;
+DROP TABLE IF EXISTS announcements;
+-- +goose StatementEnd
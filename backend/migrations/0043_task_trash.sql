@@ -0,0 +1,76 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Deleted tasks go to the team's trash first; a job purges them after 30
+-- days. Every read of tasks skips trashed rows.
+ALTER TABLE tasks
+    ADD COLUMN IF NOT EXISTS deleted_at TIMESTAMPTZ,
+    ADD COLUMN IF NOT EXISTS deleted_by UUID REFERENCES users(id) ON DELETE SET NULL;
+
+CREATE INDEX IF NOT EXISTS idx_tasks_trash
+    ON tasks(team_id, deleted_at) WHERE deleted_at IS NOT NULL;
+
+ALTER TABLE task_events DROP CONSTRAINT IF EXISTS task_events_kind_check;
+ALTER TABLE task_events ADD CONSTRAINT task_events_kind_check
+    CHECK (kind IN ('created', 'assigned', 'status_changed', 'updated', 'visibility_changed', 'deleted', 'restored'));
+
+-- trashed tasks leave the counters and come back on restore
+CREATE OR REPLACE FUNCTION track_team_task_counters() RETURNS trigger AS $$
+BEGIN
+    IF TG_OP IN ('UPDATE', 'DELETE') THEN
+        IF OLD.deleted_at IS NULL AND (TG_OP = 'DELETE' OR NEW.deleted_at IS NOT NULL
+            OR OLD.status <> NEW.status OR OLD.team_id <> NEW.team_id) THEN
+            PERFORM bump_team_task_counter(OLD.team_id, OLD.status, -1);
+        END IF;
+    END IF;
+    IF TG_OP IN ('INSERT', 'UPDATE') THEN
+        IF NEW.deleted_at IS NULL AND (TG_OP = 'INSERT' OR OLD.deleted_at IS NOT NULL
+            OR OLD.status <> NEW.status OR OLD.team_id <> NEW.team_id) THEN
+            PERFORM bump_team_task_counter(NEW.team_id, NEW.status, 1);
+        END IF;
+    END IF;
+    RETURN NULL;
+END
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS trg_tasks_team_counters ON tasks;
+CREATE TRIGGER trg_tasks_team_counters
+    AFTER INSERT OR UPDATE OF status, team_id, deleted_at OR DELETE ON tasks
+    FOR EACH ROW EXECUTE FUNCTION track_team_task_counters();
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+-- trashed tasks were deleted before this migration
+DELETE FROM tasks WHERE deleted_at IS NOT NULL;
+
+CREATE OR REPLACE FUNCTION track_team_task_counters() RETURNS trigger AS $$
+BEGIN
+    IF TG_OP IN ('UPDATE', 'DELETE') THEN
+        IF TG_OP = 'DELETE' OR OLD.status <> NEW.status OR OLD.team_id <> NEW.team_id THEN
+            PERFORM bump_team_task_counter(OLD.team_id, OLD.status, -1);
+        END IF;
+    END IF;
+    IF TG_OP IN ('INSERT', 'UPDATE') THEN
+        IF TG_OP = 'INSERT' OR OLD.status <> NEW.status OR OLD.team_id <> NEW.team_id THEN
+            PERFORM bump_team_task_counter(NEW.team_id, NEW.status, 1);
+        END IF;
+    END IF;
+    RETURN NULL;
+END
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS trg_tasks_team_counters ON tasks;
+CREATE TRIGGER trg_tasks_team_counters
+    AFTER INSERT OR UPDATE OF status, team_id OR DELETE ON tasks
+    FOR EACH ROW EXECUTE FUNCTION track_team_task_counters();
+
+DELETE FROM task_events WHERE kind = 'restored';
+ALTER TABLE task_events DROP CONSTRAINT IF EXISTS task_events_kind_check;
+ALTER TABLE task_events ADD CONSTRAINT task_events_kind_check
+    CHECK (kind IN ('created', 'assigned', 'status_changed', 'updated', 'visibility_changed', 'deleted'));
+
+DROP INDEX IF EXISTS idx_tasks_trash;
+ALTER TABLE tasks
+    DROP COLUMN IF EXISTS deleted_by,
+    DROP COLUMN IF EXISTS deleted_at;
+-- +goose StatementEnd
@@ -0,0 +1,34 @@
+-- +goose Up
+-- +goose StatementBegin
+-- task_id is blocked by blocker_id. The two tasks may be in different
+-- teams, so each end carries its own team for the composite keys.
+CREATE TABLE IF NOT EXISTS task_dependencies (
+    task_id         UUID        NOT NULL,
+    team_id         UUID        NOT NULL,
+    blocker_id      UUID        NOT NULL,
+    blocker_team_id UUID        NOT NULL,
+    created_by      UUID        REFERENCES users(id) ON DELETE SET NULL,
+    created_at      TIMESTAMPTZ NOT NULL DEFAULT now(),
+    PRIMARY KEY (task_id, blocker_id),
+    CONSTRAINT task_dependencies_not_self CHECK (task_id <> blocker_id),
+    CONSTRAINT fk_task_dependencies_task
+        FOREIGN KEY (task_id, team_id) REFERENCES tasks(id, team_id) ON DELETE CASCADE,
+    CONSTRAINT fk_task_dependencies_blocker
+        FOREIGN KEY (blocker_id, blocker_team_id) REFERENCES tasks(id, team_id) ON DELETE CASCADE
+    );
+
+CREATE INDEX IF NOT EXISTS idx_task_dependencies_blocker ON task_dependencies(blocker_id);
+-- the cross-team view reads the newest links first
+CREATE INDEX IF NOT EXISTS idx_task_dependencies_cross_team
+    ON task_dependencies(created_at DESC) WHERE team_id <> blocker_team_id;
+
+DROP TRIGGER IF EXISTS trg_task_dependencies_team ON task_dependencies;
+CREATE TRIGGER trg_task_dependencies_team
+    BEFORE INSERT ON task_dependencies
+    FOR EACH ROW EXECUTE FUNCTION fill_task_team_id();
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS task_dependencies;
+-- +goose StatementEnd
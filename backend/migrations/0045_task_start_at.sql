@@ -0,0 +1,23 @@
+-- +goose Up
+-- +goose StatementBegin
+-- start_at is when work on a task is planned to begin; with due_at it
+-- makes the task's scheduling window.
+ALTER TABLE tasks
+    ADD COLUMN IF NOT EXISTS start_at TIMESTAMPTZ,
+    ADD CONSTRAINT tasks_start_before_due CHECK (start_at IS NULL OR start_at < due_at);
+
+CREATE INDEX IF NOT EXISTS idx_tasks_team_start
+    ON tasks(team_id, start_at) WHERE start_at IS NOT NULL;
+
+ALTER TABLE tasks_archive
+    ADD COLUMN IF NOT EXISTS start_at TIMESTAMPTZ;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE tasks_archive DROP COLUMN IF EXISTS start_at;
+DROP INDEX IF EXISTS idx_tasks_team_start;
+ALTER TABLE tasks
+    DROP CONSTRAINT IF EXISTS tasks_start_before_due,
+    DROP COLUMN IF EXISTS start_at;
+-- +goose StatementEnd
@@ -0,0 +1,17 @@
+-- +goose Up
+-- +goose StatementBegin
+-- one row, written by the first-run setup (POST /setup). The primary key
+-- only takes true, so a second setup conflicts instead of adding a row.
+CREATE TABLE IF NOT EXISTS instance_settings (
+    id         BOOLEAN PRIMARY KEY DEFAULT TRUE CHECK (id),
+    org_name   TEXT        NOT NULL CHECK (length(org_name) BETWEEN 1 AND 100),
+    setup_by   UUID REFERENCES users(id) ON DELETE SET NULL,
+    setup_at   TIMESTAMPTZ NOT NULL DEFAULT now(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT now()
+    );
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS instance_settings;
+-- +goose StatementEnd
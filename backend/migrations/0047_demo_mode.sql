@@ -0,0 +1,12 @@
+-- +goose Up
+-- +goose StatementBegin
+-- set on installs seeded by DEMO_MODE; only those may be wiped by its
+-- hourly reset
+ALTER TABLE instance_settings
+    ADD COLUMN IF NOT EXISTS demo BOOLEAN NOT NULL DEFAULT FALSE;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE instance_settings DROP COLUMN IF EXISTS demo;
+-- +goose StatementEnd
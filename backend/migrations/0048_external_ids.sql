@@ -0,0 +1,22 @@
+-- +goose Up
+-- +goose StatementBegin
+-- ids given by provisioning tools (PUT /admin/provision/...), so applying
+-- the same definition twice finds the same user or team
+ALTER TABLE users
+    ADD COLUMN IF NOT EXISTS external_id TEXT
+        CHECK (external_id IS NULL OR length(external_id) BETWEEN 1 AND 200);
+ALTER TABLE teams
+    ADD COLUMN IF NOT EXISTS external_id TEXT
+        CHECK (external_id IS NULL OR length(external_id) BETWEEN 1 AND 200);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_users_external_id ON users(external_id) WHERE external_id IS NOT NULL;
+CREATE UNIQUE INDEX IF NOT EXISTS idx_teams_external_id ON teams(external_id) WHERE external_id IS NOT NULL;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS idx_teams_external_id;
+DROP INDEX IF EXISTS idx_users_external_id;
+ALTER TABLE teams DROP COLUMN IF EXISTS external_id;
+ALTER TABLE users DROP COLUMN IF EXISTS external_id;
+-- +goose StatementEnd
@@ -0,0 +1,32 @@
+-- +goose Up
+-- +goose StatementBegin
+-- version counts the changes to a task so clients can send back the
+-- version they read (If-Match) and get 409 instead of overwriting someone
+-- else's change. Every change sets updated_at, so the trigger bumps on
+-- that; bookkeeping writes such as reminders and nudges leave it alone
+-- and keep the version.
+ALTER TABLE tasks ADD COLUMN IF NOT EXISTS version BIGINT NOT NULL DEFAULT 1;
+ALTER TABLE tasks_archive ADD COLUMN IF NOT EXISTS version BIGINT NOT NULL DEFAULT 1;
+
+CREATE OR REPLACE FUNCTION bump_task_version() RETURNS trigger AS $$
+BEGIN
+    NEW.version := OLD.version + 1;
+    RETURN NEW;
+END
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS trg_tasks_version ON tasks;
+CREATE TRIGGER trg_tasks_version
+    BEFORE UPDATE ON tasks
+    FOR EACH ROW
+    WHEN (NEW.updated_at IS DISTINCT FROM OLD.updated_at)
+    EXECUTE FUNCTION bump_task_version();
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TRIGGER IF EXISTS trg_tasks_version ON tasks;
+DROP FUNCTION IF EXISTS bump_task_version();
+ALTER TABLE tasks_archive DROP COLUMN IF EXISTS version;
+ALTER TABLE tasks DROP COLUMN IF EXISTS version;
+-- +goose StatementEnd
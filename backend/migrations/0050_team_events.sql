@@ -0,0 +1,27 @@
+-- +goose Up
+-- +goose StatementBegin
+-- recent realtime events per team, numbered per team, so a stream that
+-- reconnects with Last-Event-ID gets what it missed. Only the newest are
+-- kept; a job prunes the rest.
+CREATE TABLE IF NOT EXISTS team_event_counters (
+    team_id  UUID PRIMARY KEY REFERENCES teams(id) ON DELETE CASCADE,
+    last_seq BIGINT NOT NULL
+    );
+
+CREATE TABLE IF NOT EXISTS team_events (
+    team_id    UUID        NOT NULL REFERENCES teams(id) ON DELETE CASCADE,
+    seq        BIGINT      NOT NULL,
+    type       TEXT        NOT NULL,
+    -- NULL: every member; otherwise the users who may see the task
+    audience   UUID[],
+    data       JSONB       NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    PRIMARY KEY (team_id, seq)
+    );
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS team_events;
+DROP TABLE IF EXISTS team_event_counters;
+-- +goose StatementEnd
@@ -0,0 +1,97 @@
+-- +goose Up
+-- +goose StatementBegin
+-- completed_at and canceled_at record when a task last reached done or
+-- canceled, so reports no longer read it from updated_at (which any later
+-- edit moves). The trigger stamps them on the status change itself, with
+-- the updated_at of that write, for every path that changes status:
+-- status updates, workflow states, approvals and restores. Moving a task
+-- out of done or canceled clears them.
+ALTER TABLE tasks
+    ADD COLUMN IF NOT EXISTS completed_at TIMESTAMPTZ,
+    ADD COLUMN IF NOT EXISTS canceled_at TIMESTAMPTZ;
+ALTER TABLE tasks_archive
+    ADD COLUMN IF NOT EXISTS completed_at TIMESTAMPTZ,
+    ADD COLUMN IF NOT EXISTS canceled_at TIMESTAMPTZ;
+
+CREATE OR REPLACE FUNCTION stamp_task_finished_at() RETURNS trigger AS $$
+BEGIN
+    IF NEW.status <> 'done' THEN
+        NEW.completed_at := NULL;
+    ELSIF TG_OP = 'INSERT' THEN
+        NEW.completed_at := COALESCE(NEW.completed_at, NEW.updated_at);
+    ELSIF OLD.status <> 'done' THEN
+        NEW.completed_at := NEW.updated_at;
+    END IF;
+
+    IF NEW.status <> 'canceled' THEN
+        NEW.canceled_at := NULL;
+    ELSIF TG_OP = 'INSERT' THEN
+        NEW.canceled_at := COALESCE(NEW.canceled_at, NEW.updated_at);
+    ELSIF OLD.status <> 'canceled' THEN
+        NEW.canceled_at := NEW.updated_at;
+    END IF;
+    RETURN NEW;
+END
+$$ LANGUAGE plpgsql;
+
+-- backfill from the last matching status change in the history, or
+-- updated_at for tasks finished before the history was kept. Runs before
+-- the trigger exists so updated_at and version stay as they are.
+UPDATE tasks t
+SET completed_at = COALESCE((
+        SELECT max(e.created_at)
+        FROM task_events e
+        WHERE e.task_id = t.id
+          AND e.kind = 'status_changed'
+          AND e.changes->'status'->>'to' = 'done'),
+    t.updated_at)
+WHERE t.status = 'done';
+UPDATE tasks t
+SET canceled_at = COALESCE((
+        SELECT max(e.created_at)
+        FROM task_events e
+        WHERE e.task_id = t.id
+          AND e.kind = 'status_changed'
+          AND e.changes->'status'->>'to' = 'canceled'),
+    t.updated_at)
+WHERE t.status = 'canceled';
+UPDATE tasks_archive a
+SET completed_at = COALESCE((
+        SELECT max(e.created_at)
+        FROM task_events e
+        WHERE e.task_id = a.id
+          AND e.kind = 'status_changed'
+          AND e.changes->'status'->>'to' = 'done'),
+    a.updated_at)
+WHERE a.status = 'done';
+UPDATE tasks_archive a
+SET canceled_at = COALESCE((
+        SELECT max(e.created_at)
+        FROM task_events e
+        WHERE e.task_id = a.id
+          AND e.kind = 'status_changed'
+          AND e.changes->'status'->>'to' = 'canceled'),
+    a.updated_at)
+WHERE a.status = 'canceled';
+
+DROP TRIGGER IF EXISTS trg_tasks_finished_at ON tasks;
+CREATE TRIGGER trg_tasks_finished_at
+    BEFORE INSERT OR UPDATE OF status ON tasks
+    FOR EACH ROW EXECUTE FUNCTION stamp_task_finished_at();
+
+CREATE INDEX IF NOT EXISTS idx_tasks_completed_at ON tasks(completed_at)
+    WHERE completed_at IS NOT NULL;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS idx_tasks_completed_at;
+DROP TRIGGER IF EXISTS trg_tasks_finished_at ON tasks;
+DROP FUNCTION IF EXISTS stamp_task_finished_at();
+ALTER TABLE tasks_archive
+    DROP COLUMN IF EXISTS canceled_at,
+    DROP COLUMN IF EXISTS completed_at;
+ALTER TABLE tasks
+    DROP COLUMN IF EXISTS canceled_at,
+    DROP COLUMN IF EXISTS completed_at;
+-- +goose StatementEnd
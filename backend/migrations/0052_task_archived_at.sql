@@ -0,0 +1,34 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Archiving puts a task away without deleting it: it leaves the default
+-- lists (?include_archived=true brings it back) but is otherwise an
+-- ordinary task. This is not tasks_archive, the cold storage the archive
+-- job moves long-finished tasks into; that table's own timestamp becomes
+-- moved_at so archived_at can mirror the task column.
+ALTER TABLE tasks
+    ADD COLUMN IF NOT EXISTS archived_at TIMESTAMPTZ,
+    ADD COLUMN IF NOT EXISTS archived_by UUID REFERENCES users(id) ON DELETE SET NULL;
+
+ALTER TABLE tasks_archive RENAME COLUMN archived_at TO moved_at;
+ALTER TABLE tasks_archive ADD COLUMN IF NOT EXISTS archived_at TIMESTAMPTZ;
+
+ALTER TABLE task_events DROP CONSTRAINT IF EXISTS task_events_kind_check;
+ALTER TABLE task_events ADD CONSTRAINT task_events_kind_check
+    CHECK (kind IN ('created', 'assigned', 'status_changed', 'updated', 'visibility_changed', 'deleted', 'restored',
+                    'archived', 'unarchived'));
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DELETE FROM task_events WHERE kind IN ('archived', 'unarchived');
+ALTER TABLE task_events DROP CONSTRAINT IF EXISTS task_events_kind_check;
+ALTER TABLE task_events ADD CONSTRAINT task_events_kind_check
+    CHECK (kind IN ('created', 'assigned', 'status_changed', 'updated', 'visibility_changed', 'deleted', 'restored'));
+
+ALTER TABLE tasks_archive DROP COLUMN IF EXISTS archived_at;
+ALTER TABLE tasks_archive RENAME COLUMN moved_at TO archived_at;
+
+ALTER TABLE tasks
+    DROP COLUMN IF EXISTS archived_by,
+    DROP COLUMN IF EXISTS archived_at;
+-- +goose StatementEnd
@@ -0,0 +1,29 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Soft locks on task descriptions: whoever opens the description editor
+-- takes the lock and renews it while editing; others are warned, not
+-- blocked. A lock past expires_at counts as free. id is the holder's
+-- handle for renewing and releasing it and is only shown to them.
+CREATE TABLE IF NOT EXISTS task_edit_locks (
+    task_id     UUID        PRIMARY KEY,
+    team_id     UUID        NOT NULL,
+    id          UUID        NOT NULL,
+    user_id     UUID        NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    acquired_at TIMESTAMPTZ NOT NULL,
+    expires_at  TIMESTAMPTZ NOT NULL,
+    CONSTRAINT fk_task_edit_locks_task
+        FOREIGN KEY (task_id, team_id) REFERENCES tasks(id, team_id) ON DELETE CASCADE
+    );
+
+CREATE INDEX IF NOT EXISTS idx_task_edit_locks_expires ON task_edit_locks(expires_at);
+
+DROP TRIGGER IF EXISTS trg_task_edit_locks_team ON task_edit_locks;
+CREATE TRIGGER trg_task_edit_locks_team
+    BEFORE INSERT ON task_edit_locks
+    FOR EACH ROW EXECUTE FUNCTION fill_task_team_id();
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS task_edit_locks;
+-- +goose StatementEnd
@@ -0,0 +1,53 @@
+-- +goose Up
+-- +goose StatementBegin
+-- position orders the cards of a kanban column, one column per team and
+-- status, lowest first. Moves write the midpoint of the two neighbours, so
+-- positions are fractional; when neighbours get too close the column is
+-- renumbered in steps of 1024. New tasks, and tasks moved to another
+-- status or team, go to the end of their column.
+ALTER TABLE tasks ADD COLUMN IF NOT EXISTS position DOUBLE PRECISION;
+ALTER TABLE tasks_archive ADD COLUMN IF NOT EXISTS position DOUBLE PRECISION;
+
+UPDATE tasks t
+SET position = r.rn * 1024
+FROM (
+    SELECT id, row_number() OVER (PARTITION BY team_id, status ORDER BY created_at, id) AS rn
+    FROM tasks
+) r
+WHERE r.id = t.id;
+
+ALTER TABLE tasks ALTER COLUMN position SET NOT NULL;
+
+CREATE OR REPLACE FUNCTION place_task_last() RETURNS trigger AS $$
+BEGIN
+    IF TG_OP = 'INSERT' AND NEW.position IS NOT NULL THEN
+        RETURN NEW;
+    END IF;
+    IF TG_OP = 'UPDATE' AND NEW.status = OLD.status AND NEW.team_id = OLD.team_id THEN
+        RETURN NEW;
+    END IF;
+    SELECT COALESCE(max(position), 0) + 1024 INTO NEW.position
+    FROM tasks
+    WHERE team_id = NEW.team_id
+      AND status = NEW.status
+      AND id <> NEW.id;
+    RETURN NEW;
+END
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS trg_tasks_position ON tasks;
+CREATE TRIGGER trg_tasks_position
+    BEFORE INSERT OR UPDATE OF status, team_id ON tasks
+    FOR EACH ROW EXECUTE FUNCTION place_task_last();
+
+CREATE INDEX IF NOT EXISTS idx_tasks_team_status_position ON tasks(team_id, status, position);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS idx_tasks_team_status_position;
+DROP TRIGGER IF EXISTS trg_tasks_position ON tasks;
+DROP FUNCTION IF EXISTS place_task_last();
+ALTER TABLE tasks_archive DROP COLUMN IF EXISTS position;
+ALTER TABLE tasks DROP COLUMN IF EXISTS position;
+-- +goose StatementEnd
@@ -0,0 +1,46 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Edits to a task's title or description proposed by members other than
+-- the reporter. A null title or description is not part of the
+-- suggestion. base_title and base_description are what the suggestion
+-- was made against: accepting it is refused once the reporter changed
+-- the same field. Descriptions are sealed like tasks.description in
+-- confidential teams.
+CREATE TABLE IF NOT EXISTS task_edit_suggestions (
+    id               UUID        PRIMARY KEY DEFAULT uuid_generate_v4(),
+    task_id          UUID        NOT NULL,
+    team_id          UUID        NOT NULL,
+    author_id        UUID        NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    title            TEXT,
+    description      TEXT,
+    base_title       TEXT        NOT NULL,
+    base_description TEXT,
+    status           TEXT        NOT NULL DEFAULT 'pending'
+        CHECK (status IN ('pending', 'accepted', 'rejected')),
+    resolved_by      UUID        REFERENCES users(id) ON DELETE SET NULL,
+    resolved_at      TIMESTAMPTZ,
+    created_at       TIMESTAMPTZ NOT NULL DEFAULT now(),
+    CHECK (title IS NOT NULL OR description IS NOT NULL),
+    CONSTRAINT fk_task_edit_suggestions_task
+        FOREIGN KEY (task_id, team_id) REFERENCES tasks(id, team_id) ON DELETE CASCADE
+    );
+
+-- one open suggestion per author and task; they can be resolved and
+-- suggest again
+CREATE UNIQUE INDEX IF NOT EXISTS idx_task_edit_suggestions_pending
+    ON task_edit_suggestions(task_id, author_id)
+    WHERE status = 'pending';
+
+CREATE INDEX IF NOT EXISTS idx_task_edit_suggestions_task
+    ON task_edit_suggestions(task_id, created_at DESC);
+
+DROP TRIGGER IF EXISTS trg_task_edit_suggestions_team ON task_edit_suggestions;
+CREATE TRIGGER trg_task_edit_suggestions_team
+    BEFORE INSERT ON task_edit_suggestions
+    FOR EACH ROW EXECUTE FUNCTION fill_task_team_id();
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS task_edit_suggestions;
+-- +goose StatementEnd
@@ -0,0 +1,67 @@
+-- +goose Up
+-- +goose StatementBegin
+-- One upvote per member and task. tasks.vote_count is kept by the trigger
+-- on task_votes so lists can show and sort by it without counting. A task
+-- inserted into tasks starts at zero whatever it carries: a restore or a
+-- move back from tasks_archive inserts its votes again after it.
+CREATE TABLE IF NOT EXISTS task_votes (
+    task_id    UUID        NOT NULL,
+    team_id    UUID        NOT NULL,
+    user_id    UUID        NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    PRIMARY KEY (task_id, user_id),
+    CONSTRAINT fk_task_votes_task
+        FOREIGN KEY (task_id, team_id) REFERENCES tasks(id, team_id) ON DELETE CASCADE
+    );
+
+CREATE INDEX IF NOT EXISTS idx_task_votes_user ON task_votes(user_id);
+
+DROP TRIGGER IF EXISTS trg_task_votes_team ON task_votes;
+CREATE TRIGGER trg_task_votes_team
+    BEFORE INSERT ON task_votes
+    FOR EACH ROW EXECUTE FUNCTION fill_task_team_id();
+
+ALTER TABLE tasks ADD COLUMN IF NOT EXISTS vote_count INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE tasks_archive ADD COLUMN IF NOT EXISTS vote_count INTEGER NOT NULL DEFAULT 0;
+
+CREATE OR REPLACE FUNCTION count_task_votes() RETURNS trigger AS $$
+BEGIN
+    IF TG_OP = 'INSERT' THEN
+        UPDATE tasks SET vote_count = vote_count + 1 WHERE id = NEW.task_id;
+        RETURN NEW;
+    END IF;
+    UPDATE tasks SET vote_count = vote_count - 1 WHERE id = OLD.task_id;
+    RETURN OLD;
+END
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS trg_task_votes_count ON task_votes;
+CREATE TRIGGER trg_task_votes_count
+    AFTER INSERT OR DELETE ON task_votes
+    FOR EACH ROW EXECUTE FUNCTION count_task_votes();
+
+CREATE OR REPLACE FUNCTION reset_task_vote_count() RETURNS trigger AS $$
+BEGIN
+    NEW.vote_count := 0;
+    RETURN NEW;
+END
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS trg_tasks_vote_count ON tasks;
+CREATE TRIGGER trg_tasks_vote_count
+    BEFORE INSERT ON tasks
+    FOR EACH ROW EXECUTE FUNCTION reset_task_vote_count();
+
+CREATE INDEX IF NOT EXISTS idx_tasks_team_votes ON tasks(team_id, vote_count);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS idx_tasks_team_votes;
+DROP TRIGGER IF EXISTS trg_tasks_vote_count ON tasks;
+DROP FUNCTION IF EXISTS reset_task_vote_count();
+DROP TABLE IF EXISTS task_votes;
+DROP FUNCTION IF EXISTS count_task_votes();
+ALTER TABLE tasks_archive DROP COLUMN IF EXISTS vote_count;
+ALTER TABLE tasks DROP COLUMN IF EXISTS vote_count;
+-- +goose StatementEnd
@@ -0,0 +1,29 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Anonymous feedback: each team can have one secret link that anyone can
+-- post to without an account, e.g. for retrospectives. Only the sha256 of
+-- the link's token is kept; rotating replaces it. Submissions land in the
+-- triage inbox with source 'feedback' and nothing about the submitter.
+CREATE TABLE IF NOT EXISTS team_feedback_links (
+    team_id    UUID        PRIMARY KEY REFERENCES teams(id) ON DELETE CASCADE,
+    token_hash TEXT        NOT NULL UNIQUE,
+    prompt     TEXT,
+    created_by UUID        REFERENCES users(id) ON DELETE SET NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+    );
+
+ALTER TABLE triage_items
+    ADD COLUMN IF NOT EXISTS source TEXT NOT NULL DEFAULT 'form'
+        CHECK (source IN ('form', 'feedback'));
+
+CREATE INDEX IF NOT EXISTS idx_triage_items_team_feedback
+    ON triage_items(team_id, created_at)
+    WHERE source = 'feedback';
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS idx_triage_items_team_feedback;
+ALTER TABLE triage_items DROP COLUMN IF EXISTS source;
+DROP TABLE IF EXISTS team_feedback_links;
+-- +goose StatementEnd
@@ -0,0 +1,38 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Projects group a team's tasks. A task has at most one project, and only
+-- one of its own team: the foreign key runs over (project_id, team_id).
+-- Deleting a project leaves its tasks in the team without one.
+CREATE TABLE IF NOT EXISTS projects (
+    id          UUID        PRIMARY KEY DEFAULT uuid_generate_v4(),
+    team_id     UUID        NOT NULL REFERENCES teams(id) ON DELETE CASCADE,
+    name        TEXT        NOT NULL,
+    description TEXT,
+    created_by  UUID        REFERENCES users(id) ON DELETE SET NULL,
+    created_at  TIMESTAMPTZ NOT NULL DEFAULT now(),
+    updated_at  TIMESTAMPTZ NOT NULL DEFAULT now(),
+    CONSTRAINT uq_projects_id_team UNIQUE (id, team_id)
+    );
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_projects_team_name ON projects(team_id, lower(name));
+
+ALTER TABLE tasks ADD COLUMN IF NOT EXISTS project_id UUID;
+ALTER TABLE tasks_archive ADD COLUMN IF NOT EXISTS project_id UUID;
+
+ALTER TABLE tasks DROP CONSTRAINT IF EXISTS fk_tasks_project;
+ALTER TABLE tasks
+    ADD CONSTRAINT fk_tasks_project
+        FOREIGN KEY (project_id, team_id) REFERENCES projects(id, team_id) ON DELETE SET NULL (project_id);
+
+CREATE INDEX IF NOT EXISTS idx_tasks_team_project ON tasks(team_id, project_id)
+    WHERE project_id IS NOT NULL;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS idx_tasks_team_project;
+ALTER TABLE tasks DROP CONSTRAINT IF EXISTS fk_tasks_project;
+ALTER TABLE tasks_archive DROP COLUMN IF EXISTS project_id;
+ALTER TABLE tasks DROP COLUMN IF EXISTS project_id;
+DROP TABLE IF EXISTS projects;
+-- +goose StatementEnd
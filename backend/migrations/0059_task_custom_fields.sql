@@ -0,0 +1,29 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Custom fields are typed task attributes a team defines for itself. The
+-- values live on the task, keyed by field key, and are checked against
+-- these definitions by the API on create and edit.
+CREATE TABLE IF NOT EXISTS team_custom_fields (
+    id         UUID        PRIMARY KEY DEFAULT uuid_generate_v4(),
+    team_id    UUID        NOT NULL REFERENCES teams(id) ON DELETE CASCADE,
+    key        TEXT        NOT NULL,
+    label      TEXT        NOT NULL,
+    type       TEXT        NOT NULL CHECK (type IN ('text', 'number', 'date', 'select')),
+    options    TEXT[]      NOT NULL DEFAULT '{}',
+    required   BOOLEAN     NOT NULL DEFAULT FALSE,
+    created_by UUID        REFERENCES users(id) ON DELETE SET NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    CONSTRAINT uq_team_custom_fields_key UNIQUE (team_id, key)
+    );
+
+ALTER TABLE tasks ADD COLUMN IF NOT EXISTS custom_fields JSONB NOT NULL DEFAULT '{}';
+ALTER TABLE tasks_archive ADD COLUMN IF NOT EXISTS custom_fields JSONB NOT NULL DEFAULT '{}';
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE tasks_archive DROP COLUMN IF EXISTS custom_fields;
+ALTER TABLE tasks DROP COLUMN IF EXISTS custom_fields;
+DROP TABLE IF EXISTS team_custom_fields;
+-- +goose StatementEnd
@@ -0,0 +1,22 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Weekly status reports: a reporter who opts in gets a roll-up of the
+-- status changes and comments on the tasks they created. The unsubscribe
+-- token is kept in clear, as every report carries it in its link; all it
+-- allows is turning the report off.
+CREATE TABLE IF NOT EXISTS status_report_subscriptions (
+    user_id           UUID        PRIMARY KEY REFERENCES users(id) ON DELETE CASCADE,
+    unsubscribe_token TEXT        NOT NULL UNIQUE,
+    last_sent_at      TIMESTAMPTZ,
+    next_report_at    TIMESTAMPTZ NOT NULL,
+    created_at        TIMESTAMPTZ NOT NULL DEFAULT now()
+    );
+
+CREATE INDEX IF NOT EXISTS idx_status_report_subscriptions_next
+    ON status_report_subscriptions(next_report_at);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS status_report_subscriptions;
+-- +goose StatementEnd
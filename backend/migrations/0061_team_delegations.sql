@@ -0,0 +1,29 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Delegation: a member hands the powers they hold over the tasks they
+-- created (approving or rejecting them, assigning them) to another member
+-- of the team for a date range, e.g. while on vacation. A member has at
+-- most one delegation per team; the expiry job deletes it once ends_at has
+-- passed.
+CREATE TABLE IF NOT EXISTS team_delegations (
+    id           UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    team_id      UUID        NOT NULL REFERENCES teams(id) ON DELETE CASCADE,
+    delegator_id UUID        NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    delegate_id  UUID        NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    starts_at    TIMESTAMPTZ NOT NULL,
+    ends_at      TIMESTAMPTZ NOT NULL,
+    reason       TEXT,
+    created_at   TIMESTAMPTZ NOT NULL DEFAULT now(),
+    CONSTRAINT uq_team_delegations_delegator UNIQUE (team_id, delegator_id),
+    CONSTRAINT chk_team_delegations_self CHECK (delegator_id <> delegate_id),
+    CONSTRAINT chk_team_delegations_range CHECK (ends_at > starts_at)
+    );
+
+CREATE INDEX IF NOT EXISTS idx_team_delegations_delegate ON team_delegations(team_id, delegate_id);
+CREATE INDEX IF NOT EXISTS idx_team_delegations_ends ON team_delegations(ends_at);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS team_delegations;
+-- +goose StatementEnd
@@ -0,0 +1,21 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Out-of-office windows: assigning a user during theirs needs the caller
+-- to confirm, and their backup, if any, is offered instead. A user has at
+-- most one window; setting a new one replaces it.
+CREATE TABLE IF NOT EXISTS user_out_of_office (
+    user_id    UUID        PRIMARY KEY REFERENCES users(id) ON DELETE CASCADE,
+    starts_at  TIMESTAMPTZ NOT NULL,
+    ends_at    TIMESTAMPTZ NOT NULL,
+    backup_id  UUID        REFERENCES users(id) ON DELETE SET NULL,
+    note       TEXT,
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    CONSTRAINT chk_out_of_office_window CHECK (ends_at > starts_at),
+    CONSTRAINT chk_out_of_office_backup CHECK (backup_id <> user_id)
+    );
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS user_out_of_office;
+-- +goose StatementEnd
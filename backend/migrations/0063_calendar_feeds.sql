@@ -0,0 +1,19 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Calendar feeds: a user's assigned tasks as an iCalendar subscription.
+-- The feed token is signed, not stored; it carries the generation, and
+-- only the current one of an enabled feed is served. Every new token and
+-- turning the feed off move to the next generation, so the row is kept
+-- rather than deleted and older tokens never work again.
+CREATE TABLE IF NOT EXISTS calendar_feeds (
+    user_id    UUID        PRIMARY KEY REFERENCES users(id) ON DELETE CASCADE,
+    generation BIGINT      NOT NULL DEFAULT 1,
+    enabled    BOOLEAN     NOT NULL DEFAULT true,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+    );
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS calendar_feeds;
+-- +goose StatementEnd
@@ -0,0 +1,12 @@
+-- +goose Up
+-- +goose StatementBegin
+-- A user's IANA timezone. Times stay stored in UTC; the zone is how the
+-- user types due dates in and how they are shown back, in responses and
+-- reminders. The API checks the name; the database keeps whatever it got.
+ALTER TABLE users ADD COLUMN IF NOT EXISTS timezone TEXT NOT NULL DEFAULT 'UTC';
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE users DROP COLUMN IF EXISTS timezone;
+-- +goose StatementEnd
@@ -0,0 +1,42 @@
+-- +goose Up
+-- +goose StatementBegin
+-- On-call rotations: an ordered list of team members taking turns. The
+-- rotation job hands off to the next member at handoff_at, every day or
+-- week. Tasks created without an assignee that carry one of the
+-- rotation's labels go to whoever is on call.
+CREATE TABLE IF NOT EXISTS team_rotations (
+    id         UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    team_id    UUID        NOT NULL REFERENCES teams(id) ON DELETE CASCADE,
+    name       TEXT        NOT NULL,
+    cadence    TEXT        NOT NULL CHECK (cadence IN ('daily', 'weekly')),
+    labels     TEXT[]      NOT NULL DEFAULT '{}',
+    on_call_id UUID        REFERENCES users(id) ON DELETE SET NULL,
+    handoff_at TIMESTAMPTZ NOT NULL,
+    created_by UUID        REFERENCES users(id) ON DELETE SET NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    UNIQUE (id, team_id)
+    );
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_team_rotations_team_name ON team_rotations(team_id, lower(name));
+CREATE INDEX IF NOT EXISTS idx_team_rotations_handoff ON team_rotations(handoff_at);
+
+-- members leave the rotation when they leave the team
+CREATE TABLE IF NOT EXISTS rotation_members (
+    rotation_id UUID NOT NULL,
+    team_id     UUID NOT NULL,
+    user_id     UUID NOT NULL,
+    position    INT  NOT NULL,
+    PRIMARY KEY (rotation_id, user_id),
+    CONSTRAINT fk_rotation_members_rotation
+        FOREIGN KEY (rotation_id, team_id) REFERENCES team_rotations(id, team_id) ON DELETE CASCADE,
+    CONSTRAINT fk_rotation_members_member
+        FOREIGN KEY (team_id, user_id) REFERENCES team_members(team_id, user_id) ON DELETE CASCADE
+    );
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS rotation_members;
+DROP TABLE IF EXISTS team_rotations;
+-- +goose StatementEnd
@@ -0,0 +1,69 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Reminders before a task's due date, each sent once on its channel. They
+-- replace tasks.reminder_sent_at, the single reminder a day before: open
+-- tasks keep that one as an email reminder, sent if it was.
+CREATE TABLE IF NOT EXISTS task_reminders (
+    id             UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    task_id        UUID        NOT NULL,
+    team_id        UUID        NOT NULL,
+    -- minutes before due_at; 0 reminds at the due time
+    offset_minutes INTEGER     NOT NULL CHECK (offset_minutes BETWEEN 0 AND 43200),
+    channel        TEXT        NOT NULL CHECK (channel IN ('email', 'in_app')),
+    sent_at        TIMESTAMPTZ,
+    created_by     UUID        REFERENCES users(id) ON DELETE SET NULL,
+    created_at     TIMESTAMPTZ NOT NULL DEFAULT now(),
+    UNIQUE (task_id, offset_minutes, channel),
+    CONSTRAINT fk_task_reminders_task
+        FOREIGN KEY (task_id, team_id) REFERENCES tasks(id, team_id) ON DELETE CASCADE
+    );
+
+CREATE INDEX IF NOT EXISTS idx_task_reminders_unsent ON task_reminders(task_id) WHERE sent_at IS NULL;
+
+DROP TRIGGER IF EXISTS trg_task_reminders_team ON task_reminders;
+CREATE TRIGGER trg_task_reminders_team
+    BEFORE INSERT ON task_reminders
+    FOR EACH ROW EXECUTE FUNCTION fill_task_team_id();
+
+-- a new due date means every reminder is due again
+CREATE OR REPLACE FUNCTION rearm_task_reminders() RETURNS trigger AS $$
+BEGIN
+    UPDATE task_reminders SET sent_at = NULL WHERE task_id = NEW.id AND sent_at IS NOT NULL;
+    RETURN NEW;
+END
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS trg_tasks_rearm_reminders ON tasks;
+CREATE TRIGGER trg_tasks_rearm_reminders
+    AFTER UPDATE OF due_at ON tasks
+    FOR EACH ROW
+    WHEN (OLD.due_at IS DISTINCT FROM NEW.due_at)
+    EXECUTE FUNCTION rearm_task_reminders();
+
+INSERT INTO task_reminders (task_id, team_id, offset_minutes, channel, sent_at)
+SELECT id, team_id, 1440, 'email', reminder_sent_at
+FROM tasks
+WHERE status IN ('open', 'in_progress')
+  AND deleted_at IS NULL
+ON CONFLICT DO NOTHING;
+
+ALTER TABLE tasks DROP COLUMN IF EXISTS reminder_sent_at;
+ALTER TABLE tasks_archive DROP COLUMN IF EXISTS reminder_sent_at;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE tasks ADD COLUMN IF NOT EXISTS reminder_sent_at TIMESTAMPTZ;
+ALTER TABLE tasks_archive ADD COLUMN IF NOT EXISTS reminder_sent_at TIMESTAMPTZ;
+
+UPDATE tasks t
+SET reminder_sent_at = r.sent_at
+FROM task_reminders r
+WHERE r.task_id = t.id
+  AND r.offset_minutes = 1440
+  AND r.channel = 'email';
+
+DROP TRIGGER IF EXISTS trg_tasks_rearm_reminders ON tasks;
+DROP FUNCTION IF EXISTS rearm_task_reminders();
+DROP TABLE IF EXISTS task_reminders;
+-- +goose StatementEnd
@@ -0,0 +1,10 @@
+-- +goose NO TRANSACTION
+-- built concurrently so the comments table stays writable during the migration
+
+-- +goose Up
+-- the spam guard's look at a user's recent comments
+CREATE INDEX CONCURRENTLY IF NOT EXISTS idx_task_comments_author_created
+    ON task_comments(author_id, created_at DESC);
+
+-- +goose Down
+DROP INDEX CONCURRENTLY IF EXISTS idx_task_comments_author_created;
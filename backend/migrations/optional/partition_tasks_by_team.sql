@@ -0,0 +1,198 @@
+-- Converts tasks into a table hash-partitioned by team_id (16 partitions).
+--
+-- This is NOT run by the migrator. Apply it by hand, once, during a
+-- maintenance window:
+--
+--     psql "$DATABASE_URL" -v ON_ERROR_STOP=1 -f partition_tasks_by_team.sql
+--
+-- It takes an exclusive lock on tasks and copies every row, so the API
+-- should be stopped while it runs. Everything happens in one transaction;
+-- any failure leaves the database as it was.
+--
+-- Requires PostgreSQL 15+ and migration 0022. Written against the schema
+-- as of migration 0066: indexes added to tasks by later migrations must be
+-- added below as well.
+
+BEGIN;
+
+LOCK TABLE tasks IN ACCESS EXCLUSIVE MODE;
+
+ALTER TABLE tasks RENAME TO tasks_unpartitioned;
+
+-- foreign keys follow the rename; drop them and add them back below
+ALTER TABLE task_viewers            DROP CONSTRAINT fk_task_viewers_task;
+ALTER TABLE task_approvals          DROP CONSTRAINT fk_task_approvals_task;
+ALTER TABLE task_extension_requests DROP CONSTRAINT fk_task_ext_task;
+ALTER TABLE legal_holds             DROP CONSTRAINT fk_legal_holds_task;
+ALTER TABLE task_labels             DROP CONSTRAINT fk_task_labels_task;
+ALTER TABLE task_comments           DROP CONSTRAINT fk_task_comments_task;
+ALTER TABLE automation_firings      DROP CONSTRAINT fk_automation_firings_task;
+ALTER TABLE triage_items            DROP CONSTRAINT IF EXISTS triage_items_task_id_fkey;
+ALTER TABLE task_attachments        DROP CONSTRAINT fk_task_attachments_task;
+ALTER TABLE task_dependencies       DROP CONSTRAINT fk_task_dependencies_task;
+ALTER TABLE task_dependencies       DROP CONSTRAINT fk_task_dependencies_blocker;
+ALTER TABLE task_edit_locks         DROP CONSTRAINT fk_task_edit_locks_task;
+ALTER TABLE task_edit_suggestions   DROP CONSTRAINT fk_task_edit_suggestions_task;
+ALTER TABLE task_votes              DROP CONSTRAINT fk_task_votes_task;
+ALTER TABLE task_reminders          DROP CONSTRAINT fk_task_reminders_task;
+-- only present where task_embeddings.sql was applied
+ALTER TABLE IF EXISTS task_embeddings DROP CONSTRAINT IF EXISTS fk_task_embeddings_task;
+
+CREATE TABLE tasks (LIKE tasks_unpartitioned INCLUDING DEFAULTS INCLUDING CONSTRAINTS)
+    PARTITION BY HASH (team_id);
+
+DO $$
+BEGIN
+    FOR i IN 0..15 LOOP
+        EXECUTE format(
+            'CREATE TABLE tasks_p%s PARTITION OF tasks FOR VALUES WITH (MODULUS 16, REMAINDER %s)',
+            i, i);
+    END LOOP;
+END
+$$;
+
+INSERT INTO tasks SELECT * FROM tasks_unpartitioned;
+
+DROP TABLE tasks_unpartitioned;
+
+-- the key must include the partition column; id alone stays unique in
+-- practice (random UUIDs) but is no longer enforced
+ALTER TABLE tasks ADD PRIMARY KEY (id, team_id);
+
+ALTER TABLE tasks
+    ADD CONSTRAINT fk_tasks_team
+        FOREIGN KEY (team_id) REFERENCES teams(id) ON DELETE CASCADE,
+    ADD CONSTRAINT fk_tasks_reporter
+        FOREIGN KEY (reporter_id) REFERENCES users(id) ON DELETE CASCADE,
+    ADD CONSTRAINT fk_tasks_assignee
+        FOREIGN KEY (assignee_id) REFERENCES users(id) ON DELETE CASCADE,
+    ADD CONSTRAINT fk_tasks_announcement
+        FOREIGN KEY (announcement_id) REFERENCES announcements(id) ON DELETE SET NULL,
+    ADD CONSTRAINT fk_tasks_deleted_by
+        FOREIGN KEY (deleted_by) REFERENCES users(id) ON DELETE SET NULL,
+    ADD CONSTRAINT fk_tasks_archived_by
+        FOREIGN KEY (archived_by) REFERENCES users(id) ON DELETE SET NULL,
+    ADD CONSTRAINT fk_tasks_project
+        FOREIGN KEY (project_id, team_id) REFERENCES projects(id, team_id) ON DELETE SET NULL (project_id);
+
+-- id lookups without a team probe every partition's primary key
+CREATE INDEX idx_tasks_due_at ON tasks(due_at);
+CREATE INDEX idx_tasks_assignee_due ON tasks(assignee_id, due_at);
+CREATE INDEX idx_tasks_team_updated_at ON tasks(team_id, updated_at)
+    WHERE status IN ('open', 'in_progress');
+CREATE INDEX idx_tasks_unacknowledged ON tasks(assigned_at)
+    WHERE acknowledged_at IS NULL;
+CREATE INDEX idx_tasks_team_status_due ON tasks(team_id, status, due_at);
+CREATE INDEX idx_tasks_assignee_status ON tasks(assignee_id, status);
+CREATE INDEX idx_tasks_reporter_created ON tasks(reporter_id, created_at DESC);
+CREATE INDEX idx_tasks_finished_updated_at ON tasks(updated_at)
+    WHERE status IN ('done', 'canceled');
+CREATE INDEX idx_tasks_search_vector ON tasks USING GIN (search_vector);
+CREATE INDEX idx_tasks_announcement ON tasks(announcement_id)
+    WHERE announcement_id IS NOT NULL;
+CREATE INDEX idx_tasks_trash ON tasks(team_id, deleted_at)
+    WHERE deleted_at IS NOT NULL;
+CREATE INDEX idx_tasks_team_start ON tasks(team_id, start_at)
+    WHERE start_at IS NOT NULL;
+CREATE INDEX idx_tasks_completed_at ON tasks(completed_at)
+    WHERE completed_at IS NOT NULL;
+CREATE INDEX idx_tasks_team_status_position ON tasks(team_id, status, position);
+CREATE INDEX idx_tasks_team_votes ON tasks(team_id, vote_count);
+CREATE INDEX idx_tasks_team_project ON tasks(team_id, project_id)
+    WHERE project_id IS NOT NULL;
+
+CREATE TRIGGER trg_tasks_legal_hold
+    BEFORE DELETE ON tasks
+    FOR EACH ROW EXECUTE FUNCTION refuse_held_task_delete();
+
+CREATE TRIGGER trg_tasks_team_counters
+    AFTER INSERT OR UPDATE OF status, team_id, deleted_at OR DELETE ON tasks
+    FOR EACH ROW EXECUTE FUNCTION track_team_task_counters();
+
+CREATE TRIGGER trg_tasks_search_vector
+    BEFORE INSERT OR UPDATE OF title, description ON tasks
+    FOR EACH ROW EXECUTE FUNCTION tasks_search_vector();
+
+CREATE TRIGGER trg_tasks_version
+    BEFORE UPDATE ON tasks
+    FOR EACH ROW
+    WHEN (NEW.updated_at IS DISTINCT FROM OLD.updated_at)
+    EXECUTE FUNCTION bump_task_version();
+
+CREATE TRIGGER trg_tasks_finished_at
+    BEFORE INSERT OR UPDATE OF status ON tasks
+    FOR EACH ROW EXECUTE FUNCTION stamp_task_finished_at();
+
+CREATE TRIGGER trg_tasks_position
+    BEFORE INSERT OR UPDATE OF status, team_id ON tasks
+    FOR EACH ROW EXECUTE FUNCTION place_task_last();
+
+CREATE TRIGGER trg_tasks_vote_count
+    BEFORE INSERT ON tasks
+    FOR EACH ROW EXECUTE FUNCTION reset_task_vote_count();
+
+CREATE TRIGGER trg_tasks_rearm_reminders
+    AFTER UPDATE OF due_at ON tasks
+    FOR EACH ROW
+    WHEN (OLD.due_at IS DISTINCT FROM NEW.due_at)
+    EXECUTE FUNCTION rearm_task_reminders();
+
+ALTER TABLE task_viewers
+    ADD CONSTRAINT fk_task_viewers_task
+        FOREIGN KEY (task_id, team_id) REFERENCES tasks(id, team_id) ON DELETE CASCADE;
+ALTER TABLE task_approvals
+    ADD CONSTRAINT fk_task_approvals_task
+        FOREIGN KEY (task_id, team_id) REFERENCES tasks(id, team_id) ON DELETE CASCADE;
+ALTER TABLE task_extension_requests
+    ADD CONSTRAINT fk_task_ext_task
+        FOREIGN KEY (task_id, team_id) REFERENCES tasks(id, team_id) ON DELETE CASCADE;
+ALTER TABLE legal_holds
+    ADD CONSTRAINT fk_legal_holds_task
+        FOREIGN KEY (task_id, team_id) REFERENCES tasks(id, team_id) ON DELETE RESTRICT;
+ALTER TABLE task_labels
+    ADD CONSTRAINT fk_task_labels_task
+        FOREIGN KEY (task_id, team_id) REFERENCES tasks(id, team_id) ON DELETE CASCADE;
+ALTER TABLE task_comments
+    ADD CONSTRAINT fk_task_comments_task
+        FOREIGN KEY (task_id, team_id) REFERENCES tasks(id, team_id) ON DELETE CASCADE;
+ALTER TABLE automation_firings
+    ADD CONSTRAINT fk_automation_firings_task
+        FOREIGN KEY (task_id, team_id) REFERENCES tasks(id, team_id) ON DELETE CASCADE;
+-- accepted triage items point at a task in their own team
+ALTER TABLE triage_items
+    ADD CONSTRAINT fk_triage_items_task
+        FOREIGN KEY (task_id, team_id) REFERENCES tasks(id, team_id) ON DELETE SET NULL (task_id);
+ALTER TABLE task_attachments
+    ADD CONSTRAINT fk_task_attachments_task
+        FOREIGN KEY (task_id, team_id) REFERENCES tasks(id, team_id) ON DELETE CASCADE;
+ALTER TABLE task_dependencies
+    ADD CONSTRAINT fk_task_dependencies_task
+        FOREIGN KEY (task_id, team_id) REFERENCES tasks(id, team_id) ON DELETE CASCADE,
+    ADD CONSTRAINT fk_task_dependencies_blocker
+        FOREIGN KEY (blocker_id, blocker_team_id) REFERENCES tasks(id, team_id) ON DELETE CASCADE;
+ALTER TABLE task_edit_locks
+    ADD CONSTRAINT fk_task_edit_locks_task
+        FOREIGN KEY (task_id, team_id) REFERENCES tasks(id, team_id) ON DELETE CASCADE;
+ALTER TABLE task_edit_suggestions
+    ADD CONSTRAINT fk_task_edit_suggestions_task
+        FOREIGN KEY (task_id, team_id) REFERENCES tasks(id, team_id) ON DELETE CASCADE;
+ALTER TABLE task_votes
+    ADD CONSTRAINT fk_task_votes_task
+        FOREIGN KEY (task_id, team_id) REFERENCES tasks(id, team_id) ON DELETE CASCADE;
+ALTER TABLE task_reminders
+    ADD CONSTRAINT fk_task_reminders_task
+        FOREIGN KEY (task_id, team_id) REFERENCES tasks(id, team_id) ON DELETE CASCADE;
+
+DO $$
+BEGIN
+    IF to_regclass('task_embeddings') IS NOT NULL THEN
+        ALTER TABLE task_embeddings
+            ADD CONSTRAINT fk_task_embeddings_task
+                FOREIGN KEY (task_id, team_id) REFERENCES tasks(id, team_id) ON DELETE CASCADE;
+    END IF;
+END
+$$;
+
+COMMIT;
+
+ANALYZE tasks;
@@ -0,0 +1,41 @@
+-- Vector index of task titles and descriptions for similarity search and
+-- duplicate suggestions (EMBEDDINGS_PROVIDER).
+--
+-- This is NOT run by the migrator, because it needs the pgvector extension
+-- (0.5+), which not every installation has. Apply it by hand, once:
+--
+--     psql "$DATABASE_URL" -v ON_ERROR_STOP=1 -f task_embeddings.sql
+--
+-- The vector size must match EMBEDDINGS_DIMENSIONS (default 1536); the API
+-- refuses to start when they differ. To change models or sizes, drop the
+-- table, edit the size below and apply this file again: the embedding job
+-- refills it.
+--
+-- Requires migration 0032.
+
+BEGIN;
+
+CREATE EXTENSION IF NOT EXISTS vector;
+
+CREATE TABLE IF NOT EXISTS task_embeddings (
+    task_id      UUID PRIMARY KEY,
+    team_id      UUID         NOT NULL,
+    model        TEXT         NOT NULL,
+    -- md5 of the embedded text; an edit that leaves it alone is not re-sent
+    content_hash TEXT         NOT NULL,
+    embedding    vector(1536) NOT NULL,
+    updated_at   TIMESTAMPTZ  NOT NULL DEFAULT now(),
+    CONSTRAINT fk_task_embeddings_task
+        FOREIGN KEY (task_id, team_id) REFERENCES tasks(id, team_id) ON DELETE CASCADE
+    );
+
+CREATE INDEX IF NOT EXISTS idx_task_embeddings_team ON task_embeddings(team_id, model);
+CREATE INDEX IF NOT EXISTS idx_task_embeddings_hnsw
+    ON task_embeddings USING hnsw (embedding vector_cosine_ops);
+
+DROP TRIGGER IF EXISTS trg_task_embeddings_team ON task_embeddings;
+CREATE TRIGGER trg_task_embeddings_team
+    BEFORE INSERT ON task_embeddings
+    FOR EACH ROW EXECUTE FUNCTION fill_task_team_id();
+
+COMMIT;
@@ -19,8 +19,8 @@ export const authApi = {
         await apiClient.post("/auth/logout")
     },
     listUsers: async () => {
-        const response = await apiClient.get('/users')
-        return response.data.data
+        const response = await apiClient.get('/users', { params: { limit: 100 } })
+        return response.data.data.users
     },
 }
 
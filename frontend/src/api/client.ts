@@ -1,7 +1,8 @@
 import axios from "axios"
 
 export const apiClient = axios.create({
-    baseURL : "http://localhost:8080",
+    // "/v1" when the backend serves this build itself
+    baseURL : import.meta.env.VITE_API_URL ?? "http://localhost:8080/v1",
     headers : {
         "Content-Type": "application/json",
     },